@@ -0,0 +1,142 @@
+// Command ema-repl is a terminal harness for trying out orchestrator
+// configurations.
+//
+// It wires a chosen LLM, speech-to-text and text-to-speech client together
+// with either the local microphone and speakers or a text-only mode, reads
+// prompts from stdin and prints the orchestration event stream.
+//
+// Usage:
+//
+//	go run ./cmd/ema-repl -llm groq:openai/gpt-oss-20b -audio none
+//	go run ./cmd/ema-repl -llm openai:gpt-4.1 -stt deepgram -tts deepgram -audio local
+//
+// Lines typed on stdin are sent as prompts. Lines starting with "/" are
+// commands, see "/help".
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "ema-repl:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var cfg config
+	flag.StringVar(&cfg.llm, "llm", "openai:gpt-4.1", "LLM to use (openai:<gpt-4o|gpt-4.1|gpt-5-nano>, groq:<model>, anthropic:<model>)")
+	flag.StringVar(&cfg.stt, "stt", "none", "speech-to-text client to use (deepgram, whisper, azure, none)")
+	flag.StringVar(&cfg.tts, "tts", "none", "text-to-speech client to use (deepgram, openai, azure, cartesia, none)")
+	flag.StringVar(&cfg.voice, "voice", "", "text-to-speech voice (defaults to the provider default)")
+	flag.StringVar(&cfg.audio, "audio", "none", "audio devices to use (local, none)")
+	flag.StringVar(&cfg.systemPrompt, "system", "", "system prompt passed to the LLM")
+	flag.BoolVar(&cfg.showFrames, "frames", false, "print audio frame events")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts, cleanup, err := cfg.orchestratorOptions(ctx)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	o := orchestration.NewOrchestrator(opts...)
+	defer o.Close()
+
+	printer := newEventPrinter(os.Stdout, cfg.showFrames)
+	if err := o.Orchestrate(ctx, orchestration.WithEventCallback(printer.Print)); err != nil {
+		return fmt.Errorf("failed to start conversation: %w", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "ema-repl ready (llm=%s stt=%s tts=%s audio=%s), type /help for commands\n",
+		cfg.llm, cfg.stt, cfg.tts, cfg.audio)
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-lines:
+			if !ok {
+				return nil
+			}
+			if quit := handleLine(o, strings.TrimSpace(line)); quit {
+				return nil
+			}
+		}
+	}
+}
+
+func handleLine(o *orchestration.Orchestrator, line string) (quit bool) {
+	if line == "" {
+		return false
+	}
+
+	if !strings.HasPrefix(line, "/") {
+		o.SendPrompt(line)
+		return false
+	}
+
+	switch line {
+	case "/quit", "/exit":
+		return true
+	case "/cancel":
+		o.CancelTurn()
+	case "/pause":
+		o.PauseTurn()
+	case "/unpause":
+		o.UnpauseTurn()
+	case "/mute":
+		o.Mute()
+	case "/unmute":
+		o.Unmute()
+	case "/capture":
+		if err := o.RequestToCaptureAudio(); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to start capture:", err)
+		}
+	case "/stop":
+		if err := o.StopRequestingToCaptureAudio(); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to stop capture:", err)
+		}
+	case "/history":
+		printHistory(os.Stdout, o.ConversationV1())
+	case "/help":
+		fmt.Fprintln(os.Stdout, helpText)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, type /help for commands\n", line)
+	}
+	return false
+}
+
+const helpText = `commands:
+  /cancel    cancel the active turn
+  /pause     pause the active turn
+  /unpause   resume the paused turn
+  /mute      stop speaking responses
+  /unmute    resume speaking responses
+  /capture   request audio capture
+  /stop      stop requesting audio capture
+  /history   print the conversation history
+  /quit      exit
+anything else is sent as a prompt`
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// eventPrinter writes one line per orchestration event.
+type eventPrinter struct {
+	mu         sync.Mutex
+	out        io.Writer
+	showFrames bool
+}
+
+func newEventPrinter(out io.Writer, showFrames bool) *eventPrinter {
+	return &eventPrinter{out: out, showFrames: showFrames}
+}
+
+func (p *eventPrinter) Print(event events.Event) {
+	details, ok := p.describe(event)
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if details == "" {
+		fmt.Fprintf(p.out, "%s %s\n", event.Timestamp().Format("15:04:05.000"), event.Kind())
+		return
+	}
+	fmt.Fprintf(p.out, "%s %s %s\n", event.Timestamp().Format("15:04:05.000"), event.Kind(), details)
+}
+
+func (p *eventPrinter) describe(event events.Event) (string, bool) {
+	switch e := event.(type) {
+	case events.UserAudioFrame:
+		return fmt.Sprintf("bytes=%d", len(e.Audio)), p.showFrames
+	case events.AssistantSpeechFrame:
+		return fmt.Sprintf("bytes=%d", len(e.Audio)), p.showFrames
+	case events.AssistantPlaybackFrame:
+		return fmt.Sprintf("bytes=%d", len(e.Audio)), p.showFrames
+	case events.UserTranscriptInterimSegmentUpdated:
+		return fmt.Sprintf("%q", e.Segment), true
+	case events.UserTranscriptInterimUpdated:
+		return fmt.Sprintf("%q", e.Transcript), true
+	case events.UserTranscriptSegment:
+		return fmt.Sprintf("%q", e.Segment), true
+	case events.UserTranscriptFinal:
+		return fmt.Sprintf("%q", e.Transcript), true
+	case events.UserMessageTyped:
+		return fmt.Sprintf("%q", e.Message), true
+	case events.UserWakeWordDetected:
+		return fmt.Sprintf("%q", e.Keyword), true
+	case events.AudioCaptureStarted:
+		return fmt.Sprintf("always=%t", e.AlwaysCapture), true
+	case events.AudioCaptureStopped:
+		return "", true
+	case events.AudioCaptureFailed:
+		return fmt.Sprintf("reason=%s error=%q", e.Reason, e.Error), true
+	case events.AudioAlwaysCaptureChanged:
+		return fmt.Sprintf("always=%t", e.AlwaysCapture), true
+	case events.MicrophoneMuted:
+		return fmt.Sprintf("recording=%t", e.KeepRecording), true
+	case events.AssistantResponseSegment:
+		return fmt.Sprintf("%q", e.Segment), true
+	case events.AssistantResponseSegmentReplaced:
+		return fmt.Sprintf("%q -> %q", e.Replaced, e.Segment), true
+	case events.AssistantResponseFinalized:
+		return fmt.Sprintf("%q", e.Response), true
+	case events.AssistantSpeechMarkGenerated:
+		return fmt.Sprintf("%q", e.Transcript), true
+	case events.AssistantSpeechTimestamps:
+		words := make([]string, 0, len(e.Words))
+		for _, word := range e.Words {
+			words = append(words, fmt.Sprintf("%q@%v", word.Word, word.End.Round(time.Millisecond)))
+		}
+		return strings.Join(words, " "), true
+	case events.AssistantPlaybackMarkPlayed:
+		return fmt.Sprintf("mark=%s %q", e.Mark, e.Transcript), true
+	case events.AssistantPlaybackTranscriptUpdated:
+		return fmt.Sprintf("%q", e.Transcript), true
+	case events.AssistantPlaybackTranscriptSegment:
+		return fmt.Sprintf("%q", e.Segment), true
+	case events.AssistantPlaybackEnded:
+		return fmt.Sprintf("%q", e.Transcript), true
+	case events.ToolCallStarted:
+		return fmt.Sprintf("id=%s name=%s args=%s", e.ID, e.Name, e.Arguments), true
+	case events.ToolCallApprovalRequested:
+		return fmt.Sprintf("id=%s name=%s args=%s", e.ID, e.Name, e.Arguments), true
+	case events.ToolCallCompleted:
+		return fmt.Sprintf("id=%s name=%s response=%q", e.ID, e.Name, e.Response), true
+	case events.ToolCallProgress:
+		if e.Status != "" {
+			return fmt.Sprintf("id=%s name=%s status=%q", e.ID, e.Name, e.Status), true
+		}
+		return fmt.Sprintf("id=%s name=%s message=%q", e.ID, e.Name, e.Message), true
+	case events.ToolCallOutputFlagged:
+		return fmt.Sprintf("id=%s name=%s reason=%q", e.ID, e.Name, e.Reason), true
+	case events.ToolCallFailed:
+		return fmt.Sprintf("id=%s name=%s error=%q", e.ID, e.Name, e.Error), true
+	case events.TurnStarted:
+		return fmt.Sprintf("turn=%s trigger=%q input=%s", e.TurnID, e.Trigger, e.InputModality), true
+	case events.TurnCompleted:
+		l := e.Latency
+		return fmt.Sprintf("turn=%s queue=%v first_token=%v llm=%v first_audio=%v playback=%v-%v", e.TurnID,
+			l.QueueWait.Round(time.Millisecond), l.LLMFirstToken.Round(time.Millisecond), l.LLMTotal.Round(time.Millisecond),
+			l.TTSFirstAudio.Round(time.Millisecond), l.PlaybackStart.Round(time.Millisecond), l.PlaybackEnd.Round(time.Millisecond)), true
+	case events.TurnFailed:
+		return fmt.Sprintf("turn=%s error=%q", e.TurnID, e.Error), true
+	case events.TurnStateChanged:
+		return fmt.Sprintf("turn=%s %s->%s", e.TurnID, e.From, e.To), true
+	case events.TurnStalled:
+		workers := make([]string, 0, len(e.Workers))
+		for _, worker := range e.Workers {
+			workers = append(workers, fmt.Sprintf("%s=%q/%v", worker.Name, worker.State, worker.Idle.Round(time.Millisecond)))
+		}
+		return fmt.Sprintf("turn=%s cancelled=%t %s", e.TurnID, e.Cancelled, strings.Join(workers, " ")), true
+	case events.CompliancePauseStarted:
+		return fmt.Sprintf("reason=%q", e.Reason), true
+	case events.CompliancePauseEnded:
+		return fmt.Sprintf("reason=%q duration=%v dropped_frames=%d timed_out=%t", e.Reason, e.Duration.Round(time.Millisecond), e.DroppedFrames, e.TimedOut), true
+	case events.TenantLimitExceeded:
+		return fmt.Sprintf("tenant=%s session=%s limit=%s max=%d", e.Tenant, e.SessionID, e.Limit, e.Max), true
+	case events.BudgetExceeded:
+		return fmt.Sprintf("limit=%s tokens=%d cost=%.4f turns=%d fallback=%t", e.Limit, e.Tokens, e.Cost, e.Turns, e.Fallback), true
+	case events.DegradedToTextOnly:
+		return fmt.Sprintf("turn=%s component=%s error=%q", e.TurnID, e.Component, e.Error), true
+	case events.DegradationRecovered:
+		return fmt.Sprintf("turn=%s component=%s", e.TurnID, e.Component), true
+	case events.PlaybackMarksTimedOut:
+		return fmt.Sprintf("pending=%d", e.Pending), true
+	case events.PanicRecovered:
+		return fmt.Sprintf("turn=%s worker=%q panic=%q", e.TurnID, e.Worker, e.Value), true
+	case events.ConnectionLost:
+		return fmt.Sprintf("component=%s error=%q", e.Component, e.Error), true
+	case events.ConnectionRestored:
+		return fmt.Sprintf("component=%s", e.Component), true
+	case events.BackchannelPlayed:
+		return fmt.Sprintf("phrase=%q reason=%s", e.Phrase, e.Reason), true
+	case events.TextTranslated:
+		return fmt.Sprintf("%s %s->%s %q -> %q", e.Direction, e.SourceLanguage, e.TargetLanguage, e.Original, e.Translation), true
+	case events.ConversationAnalyzed:
+		return fmt.Sprintf("resolution=%s sentiment=%s summary=%q", e.Resolution, e.Sentiment, e.Summary), true
+	case events.ChatMessageUpdated:
+		return fmt.Sprintf("id=%s role=%s %q", e.MessageID, e.Role, e.Text), true
+	case events.ChatMessageFinalized:
+		return fmt.Sprintf("id=%s role=%s %q", e.MessageID, e.Role, e.Text), true
+	case events.OutcomeLabeled:
+		return fmt.Sprintf("turn=%s label=%s by=%s notes=%q", e.TurnID, e.Label, e.LabeledBy, e.Notes), true
+	case events.ConversationEnded:
+		return fmt.Sprintf("session=%s reason=%s idle=%v", e.SessionID, e.Reason, e.Idle.Round(time.Millisecond)), true
+	default:
+		return "", true
+	}
+}
+
+func printHistory(out io.Writer, conversation orchestration.ConversationV1) {
+	for _, turn := range conversation.History {
+		fmt.Fprintf(out, "[%s] %v\n", turn.ID, turn.Trigger)
+		for _, response := range turn.Responses {
+			fmt.Fprintf(out, "  assistant: %s\n", response.Message)
+		}
+	}
+	if conversation.ActiveTurn != nil {
+		fmt.Fprintf(out, "[%s] %v (active)\n", conversation.ActiveTurn.ID, conversation.ActiveTurn.Trigger)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/audio/miniaudio"
+	"github.com/koscakluka/ema-core/core/llms/anthropic"
+	"github.com/koscakluka/ema-core/core/llms/groq"
+	"github.com/koscakluka/ema-core/core/llms/openai"
+	azurestt "github.com/koscakluka/ema-core/core/speechtotext/azure"
+	deepgramstt "github.com/koscakluka/ema-core/core/speechtotext/deepgram"
+	"github.com/koscakluka/ema-core/core/speechtotext/whisper"
+	azuretts "github.com/koscakluka/ema-core/core/texttospeech/azure"
+	"github.com/koscakluka/ema-core/core/texttospeech/cartesia"
+	deepgramtts "github.com/koscakluka/ema-core/core/texttospeech/deepgram"
+	openaitts "github.com/koscakluka/ema-core/core/texttospeech/openai"
+)
+
+type config struct {
+	llm          string
+	stt          string
+	tts          string
+	voice        string
+	audio        string
+	systemPrompt string
+	showFrames   bool
+}
+
+// orchestratorOptions builds the orchestrator options for the configured
+// clients. The returned cleanup func is always safe to call, even on error.
+func (c config) orchestratorOptions(ctx context.Context) ([]orchestration.OrchestratorOption, func(), error) {
+	cleanups := []func(){}
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+
+	opts := []orchestration.OrchestratorOption{}
+
+	llm, err := c.newLLM()
+	if err != nil {
+		return nil, cleanup, err
+	}
+	opts = append(opts, orchestration.WithStreamingLLM(llm))
+
+	switch c.stt {
+	case "none", "":
+	case "deepgram":
+		opts = append(opts, orchestration.WithSpeechToTextClient(deepgramstt.NewClient(ctx)))
+	case "whisper":
+		opts = append(opts, orchestration.WithSpeechToTextClient(whisper.NewClient(ctx)))
+	case "azure":
+		opts = append(opts, orchestration.WithSpeechToTextClient(azurestt.NewClient(ctx)))
+	default:
+		return nil, cleanup, fmt.Errorf("unknown speech-to-text client %q", c.stt)
+	}
+
+	switch c.tts {
+	case "none", "":
+	case "deepgram":
+		voice := deepgramtts.VoiceAura2Asteria
+		if c.voice != "" {
+			found := false
+			for _, v := range deepgramtts.GetAvailableVoices() {
+				if string(v) == c.voice {
+					voice, found = v, true
+					break
+				}
+			}
+			if !found {
+				return nil, cleanup, fmt.Errorf("unknown deepgram voice %q", c.voice)
+			}
+		}
+
+		client, err := deepgramtts.NewTextToSpeechClient(ctx, voice)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to create deepgram text-to-speech client: %w", err)
+		}
+		cleanups = append(cleanups, func() { client.Close(context.Background()) })
+		opts = append(opts, orchestration.WithTextToSpeechClientV1(client))
+	case "openai":
+		voice := openaitts.VoiceAlloy
+		if c.voice != "" {
+			found := false
+			for _, v := range openaitts.GetAvailableVoices() {
+				if string(v) == c.voice {
+					voice, found = v, true
+					break
+				}
+			}
+			if !found {
+				return nil, cleanup, fmt.Errorf("unknown openai voice %q", c.voice)
+			}
+		}
+
+		client, err := openaitts.NewTextToSpeechClient(ctx, voice)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to create openai text-to-speech client: %w", err)
+		}
+		opts = append(opts, orchestration.WithTextToSpeechClientV1(client))
+	case "azure":
+		voice := azuretts.DefaultVoice
+		if c.voice != "" {
+			voice = c.voice
+		}
+
+		client, err := azuretts.NewTextToSpeechClient(ctx, voice)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to create azure text-to-speech client: %w", err)
+		}
+		opts = append(opts, orchestration.WithTextToSpeechClientV1(client))
+	case "cartesia":
+		if c.voice == "" {
+			return nil, cleanup, fmt.Errorf("cartesia requires the id of a voice (-voice)")
+		}
+
+		client, err := cartesia.NewTextToSpeechClient(ctx, c.voice)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to create cartesia text-to-speech client: %w", err)
+		}
+		opts = append(opts, orchestration.WithTextToSpeechClientV1(client))
+	default:
+		return nil, cleanup, fmt.Errorf("unknown text-to-speech client %q", c.tts)
+	}
+
+	switch c.audio {
+	case "none", "":
+	case "local":
+		client, err := miniaudio.NewClient()
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to open local audio devices: %w", err)
+		}
+		cleanups = append(cleanups, client.Close)
+		opts = append(opts,
+			orchestration.WithAudioInput(client),
+			orchestration.WithAudioOutputV0(client),
+		)
+	default:
+		return nil, cleanup, fmt.Errorf("unknown audio mode %q", c.audio)
+	}
+
+	return opts, cleanup, nil
+}
+
+func (c config) newLLM() (orchestration.LLMWithStream, error) {
+	provider, model, _ := strings.Cut(c.llm, ":")
+	switch provider {
+	case "openai":
+		switch model {
+		case "gpt-4o":
+			return openai.NewGPT4oClient(openaiOptions[openai.GPT4oVersion](c.systemPrompt)...)
+		case "gpt-4.1", "":
+			return openai.NewGPT41Client(openaiOptions[openai.GPT41Version](c.systemPrompt)...)
+		case "gpt-5-nano":
+			return openai.NewGPT5NanoClient(openaiOptions[openai.GPT5NanoVersion](c.systemPrompt)...)
+		default:
+			return nil, fmt.Errorf("unknown openai model %q", model)
+		}
+	case "groq":
+		opts := []groq.ClientOption{}
+		if c.systemPrompt != "" {
+			opts = append(opts, groq.WithSystemPrompt(c.systemPrompt))
+		}
+		switch groq.ChatModel(model) {
+		case groq.ModelLlama3370BVersatile, "":
+			return groq.NewLlama3370BVersatileClient(opts...)
+		case groq.ModelLlama318BInstant:
+			return groq.NewLlama318BInstructClient(opts...)
+		case groq.ModelGPTOSS20B:
+			return groq.NewGPTOSS20BClient(opts...)
+		case groq.ModelGPTOSS120B:
+			return groq.NewGPTOSS120BClient(opts...)
+		case groq.ModelLlama4Maverick17BInstruct:
+			return groq.NewLlama4Maverick17BInstructClient(opts...)
+		case groq.ModelLlama4Scout17BInstruct:
+			return groq.NewLlama4Scout17BInstructClient(opts...)
+		case groq.ModelKimiK2Instruct0905:
+			return groq.NewKimiK2Instruct0905Client(opts...)
+		case groq.ModelQwen332B:
+			return groq.NewQwen332BClient(opts...)
+		default:
+			return nil, fmt.Errorf("unknown groq model %q", model)
+		}
+	case "anthropic":
+		opts := []anthropic.ClientOption{}
+		if c.systemPrompt != "" {
+			opts = append(opts, anthropic.WithSystemPrompt(c.systemPrompt))
+		}
+		switch anthropic.ChatModel(model) {
+		case anthropic.ModelClaudeSonnet45, "":
+			return anthropic.NewClaudeSonnet45Client(opts...)
+		case anthropic.ModelClaudeHaiku45:
+			return anthropic.NewClaudeHaiku45Client(opts...)
+		case anthropic.ModelClaudeOpus41:
+			return anthropic.NewClaudeOpus41Client(opts...)
+		default:
+			return nil, fmt.Errorf("unknown anthropic model %q", model)
+		}
+	default:
+		return nil, fmt.Errorf("unknown llm provider %q", provider)
+	}
+}
+
+func openaiOptions[T any](systemPrompt string) []openai.BaseOption[T] {
+	if systemPrompt == "" {
+		return nil
+	}
+	return []openai.BaseOption[T]{openai.WithSystemPrompt[T](systemPrompt)}
+}
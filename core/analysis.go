@@ -0,0 +1,83 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// ConversationAnalysisV0 is a post-call report of a conversation, see
+// [Orchestrator.Analyze].
+type ConversationAnalysisV0 struct {
+	Summary string `json:"summary"`
+	// Resolution is whether the user got what they came for: "resolved",
+	// "unresolved" or "escalated".
+	Resolution string `json:"resolution"`
+	// Sentiment is the overall sentiment of the user: "positive", "neutral"
+	// or "negative".
+	Sentiment   string   `json:"sentiment"`
+	ActionItems []string `json:"action_items"`
+	Topics      []string `json:"topics"`
+}
+
+const analysisSystemPrompt = `You analyze transcripts of conversations between users and an assistant.
+Reply with a single JSON object, without any other text, with the fields:
+- "summary": a few sentences summarizing the conversation;
+- "resolution": "resolved", "unresolved" or "escalated";
+- "sentiment": the overall sentiment of the user, "positive", "neutral" or "negative";
+- "action_items": follow-ups promised or requested in the conversation;
+- "topics": short names of the topics discussed.`
+
+// Analyze has the configured LLM produce a report of the conversation so
+// far, typically once it ended. The report is emitted as a
+// [events.ConversationAnalyzed] event and included in the
+// [SessionStateV0] returned by [Orchestrator.Drain].
+func (o *Orchestrator) Analyze(ctx context.Context) (ConversationAnalysisV0, error) {
+	ctx, span := tracer.Start(ctx, "analyze conversation")
+	defer span.End()
+
+	response, err := o.llm.prompt(ctx, formatTranscript(o.conversation.History()), analysisSystemPrompt)
+	if err != nil {
+		return ConversationAnalysisV0{}, fmt.Errorf("failed to analyze conversation: %w", err)
+	}
+
+	start, end := strings.Index(response, "{"), strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		return ConversationAnalysisV0{}, fmt.Errorf("failed to analyze conversation: no report in response %q", response)
+	}
+	var analysis ConversationAnalysisV0
+	if err := json.Unmarshal([]byte(response[start:end+1]), &analysis); err != nil {
+		return ConversationAnalysisV0{}, fmt.Errorf("failed to decode conversation report: %w", err)
+	}
+
+	o.analysis.Store(&analysis)
+	o.emitEvent(events.NewConversationAnalyzed(analysis.Summary, analysis.Resolution, analysis.Sentiment, analysis.ActionItems, analysis.Topics))
+	return analysis, nil
+}
+
+// formatTranscript writes history as a plain text transcript.
+func formatTranscript(history []llms.TurnV1) string {
+	var transcript strings.Builder
+	for _, turn := range history {
+		if turn.Trigger != nil {
+			if turn.SpeakerID != "" {
+				fmt.Fprintf(&transcript, "User (%s): %s\n", turn.SpeakerID, turn.Trigger.String())
+			} else {
+				fmt.Fprintf(&transcript, "User: %s\n", turn.Trigger.String())
+			}
+		}
+		for _, toolCall := range turn.ToolCalls {
+			fmt.Fprintf(&transcript, "Assistant called %s: %s\n", toolCall.Name, toolCall.Response)
+		}
+		for _, response := range turn.Responses {
+			if response.Message != "" {
+				fmt.Fprintf(&transcript, "Assistant: %s\n", response.Message)
+			}
+		}
+	}
+	return transcript.String()
+}
@@ -0,0 +1,73 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+type analysisLLMStub struct {
+	mu     sync.Mutex
+	prompt string
+}
+
+func (stub *analysisLLMStub) Prompt(_ context.Context, prompt string, _ ...llms.PromptOption) ([]llms.Message, error) {
+	stub.mu.Lock()
+	stub.prompt = prompt
+	stub.mu.Unlock()
+
+	return []llms.Message{{Content: "```json\n" + `{"summary": "The user booked a table.", "resolution": "resolved", "sentiment": "positive", "action_items": ["send confirmation"], "topics": ["booking"]}` + "\n```"}}, nil
+}
+
+func TestAnalyzeReportsConversation(t *testing.T) {
+	llm := &analysisLLMStub{}
+	o := NewOrchestrator(WithLLM(llm), WithSessionStateV0(SessionStateV0{History: []llms.TurnV1{{
+		ID:        "turn-1",
+		Trigger:   triggers.NewUserPromptTrigger("book a table for two"),
+		Responses: []llms.TurnResponseV0{{Message: "Booked for 8pm."}},
+	}}}))
+	var analyzed []events.ConversationAnalyzed
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if typedEvent, ok := event.(events.ConversationAnalyzed); ok {
+			analyzed = append(analyzed, typedEvent)
+		}
+	}))
+
+	analysis, err := o.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if analysis.Resolution != "resolved" || analysis.Sentiment != "positive" || len(analysis.ActionItems) != 1 || analysis.Topics[0] != "booking" {
+		t.Fatalf("unexpected analysis %+v", analysis)
+	}
+	if llm.prompt != "User: book a table for two\nAssistant: Booked for 8pm.\n" {
+		t.Fatalf("unexpected transcript %q", llm.prompt)
+	}
+	if len(analyzed) != 1 || analyzed[0].Summary != "The user booked a table." {
+		t.Fatalf("expected a conversation analyzed event, got %+v", analyzed)
+	}
+
+	state, err := o.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected drain error %v", err)
+	}
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("unexpected encoding error %v", err)
+	}
+	var decoded SessionStateV0
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("unexpected decoding error %v", err)
+	}
+	if decoded.Analysis == nil || !strings.HasPrefix(decoded.Analysis.Summary, "The user booked") {
+		t.Fatalf("expected the analysis in the exported state, got %s", encoded)
+	}
+}
@@ -0,0 +1,62 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// ErrToolCallRejected is the error of tool calls the approval handler
+// rejected, see [WithToolApprovalHandler].
+var ErrToolCallRejected = errors.New("tool call rejected")
+
+// ToolApprovalHandler decides whether a call of a sensitive tool is executed,
+// it may block until e.g. the user confirms the call or ctx is done.
+type ToolApprovalHandler func(ctx context.Context, call llms.ToolCall) (approve bool, err error)
+
+// WithToolApprovalHandler holds calls of tools marked with
+// [llms.WithApprovalRequired] until handler approves them. Each held call is
+// announced with a [events.ToolCallApprovalRequested] event, rejected calls
+// are not executed and the LLM is told the call was rejected. A handler
+// error fails the call like an error of the tool.
+//
+// Without a handler calls of such tools are always rejected.
+func WithToolApprovalHandler(handler func(ctx context.Context, call llms.ToolCall) (approve bool, err error)) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if handler == nil {
+			o.validation.errs = append(o.validation.errs, fmt.Errorf("WithToolApprovalHandler: %w", ErrNilClient))
+			return
+		}
+		o.llm.approveToolCall = handler
+	}
+}
+
+// awaitApproval holds the call of tool until it is approved. It returns
+// ErrToolCallRejected for a rejected call.
+func (runtime *llm) awaitApproval(ctx context.Context, tool llms.Tool, call llms.ToolCall) error {
+	if !tool.RequiresApproval {
+		return nil
+	}
+
+	runtime.emitEvent(events.NewToolCallApprovalRequested(call.ID, call.Name, call.Arguments))
+	if runtime.approveToolCall == nil {
+		return ErrToolCallRejected
+	}
+	// Waiting for a decision is not a stall of the turn.
+	if runtime.pauseProgress != nil {
+		runtime.pauseProgress(true)
+		defer runtime.pauseProgress(false)
+	}
+
+	approved, err := runtime.approveToolCall(ctx, call)
+	if err != nil {
+		return fmt.Errorf("failed to get approval for tool %q: %w", call.Name, err)
+	}
+	if !approved {
+		return ErrToolCallRejected
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestToolApprovalHandlerGatesSensitiveTools(t *testing.T) {
+	var deleted, listed atomic.Int32
+	deleteFile := llms.NewTool("delete_file", "deletes a file", map[string]llms.ParameterBase{
+		"path": {Type: "string", Description: "The file"},
+	}, func(struct{ Path string }) (string, error) {
+		deleted.Add(1)
+		return "deleted", nil
+	}, llms.WithApprovalRequired())
+	listFiles := llms.NewTool("list_files", "lists files", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+		listed.Add(1)
+		return "notes.txt", nil
+	})
+
+	tests := []struct {
+		name             string
+		approve          bool
+		expectedDeleted  int32
+		expectedResponse string
+	}{
+		{name: "approved", approve: true, expectedDeleted: 1, expectedResponse: "deleted"},
+		{name: "rejected", approve: false, expectedDeleted: 0, expectedResponse: "The call was rejected by the user and not executed."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted.Store(0)
+			listed.Store(0)
+			llm := &toolCallingLLMStub{toolCalls: []llms.ToolCall{
+				{ID: "call_1", Name: "list_files", Arguments: "{}"},
+				{ID: "call_2", Name: "delete_file", Arguments: `{"path":"notes.txt"}`},
+			}}
+			var mu sync.Mutex
+			var asked []llms.ToolCall
+			o := NewOrchestrator(WithStreamingLLM(llm), WithTools(listFiles, deleteFile), WithToolApprovalHandler(func(ctx context.Context, call llms.ToolCall) (bool, error) {
+				mu.Lock()
+				asked = append(asked, call)
+				mu.Unlock()
+				return tt.approve, nil
+			}))
+			t.Cleanup(o.Close)
+			var requested atomic.Value
+			var completed atomic.Bool
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+				switch event := event.(type) {
+				case events.ToolCallApprovalRequested:
+					requested.Store(event)
+				case events.TurnCompleted:
+					completed.Store(true)
+				}
+			}))
+
+			o.HandleTrigger(triggers.NewUserPromptTrigger("clean up"))
+			waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(asked) != 1 || asked[0].ID != "call_2" || asked[0].Name != "delete_file" || asked[0].Arguments != `{"path":"notes.txt"}` {
+				t.Fatalf("expected approval to be asked for the sensitive call only, got %+v", asked)
+			}
+			if event, ok := requested.Load().(events.ToolCallApprovalRequested); !ok || event.ID != "call_2" || event.Name != "delete_file" {
+				t.Fatalf("expected an approval requested event, got %+v", requested.Load())
+			}
+			if listed.Load() != 1 || deleted.Load() != tt.expectedDeleted {
+				t.Fatalf("unexpected executions, listed %d deleted %d", listed.Load(), deleted.Load())
+			}
+			returned := llm.returnedToolCalls()
+			if len(returned) != 2 || returned[1].Response != tt.expectedResponse {
+				t.Fatalf("unexpected tool calls returned to the LLM %+v", returned)
+			}
+		})
+	}
+}
+
+func TestToolApprovalRejectsSensitiveToolsWithoutHandler(t *testing.T) {
+	var deleted atomic.Int32
+	deleteFile := llms.NewTool("delete_file", "deletes a file", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+		deleted.Add(1)
+		return "deleted", nil
+	}, llms.WithApprovalRequired())
+
+	runtime := newLLM()
+	runtime.setTools(deleteFile)
+	call, err := runtime.callTool(context.Background(), llms.ToolCall{ID: "call_1", Name: "delete_file", Arguments: "{}"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted.Load() != 0 || call.Response == "deleted" {
+		t.Fatalf("expected the call to be rejected, got %+v", call)
+	}
+}
+
+func TestToolApprovalHandlerErrorFailsTheCall(t *testing.T) {
+	deleteFile := llms.NewTool("delete_file", "deletes a file", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+		return "deleted", nil
+	}, llms.WithApprovalRequired())
+	unavailable := errors.New("approval service unavailable")
+
+	runtime := newLLM()
+	runtime.setTools(deleteFile)
+	runtime.approveToolCall = func(context.Context, llms.ToolCall) (bool, error) { return false, unavailable }
+	if _, err := runtime.callTool(context.Background(), llms.ToolCall{ID: "call_1", Name: "delete_file", Arguments: "{}"}); !errors.Is(err, unavailable) {
+		t.Fatalf("expected the approval error, got %v", err)
+	}
+}
@@ -0,0 +1,295 @@
+package daily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+const defaultFrameDuration = 20 * time.Millisecond
+
+// ErrBufferCleared is the error of [Bridge.AwaitMark] when the audio it
+// awaits is dropped by [Bridge.ClearBuffer].
+var ErrBufferCleared = errors.New("daily bridge buffer cleared")
+
+// Bridge exposes a joined Daily room as orchestrator audio input and output.
+//
+// Audio sent to the bridge is buffered and written to the room one frame at a
+// time in real time, so marks are reported once the audio preceding them has
+// been published to the room.
+type Bridge struct {
+	call          Call
+	encoding      audio.EncodingInfo
+	channels      int
+	frameDuration time.Duration
+
+	onAudio   func(audio []byte)
+	capturing bool
+	captureMu sync.Mutex
+
+	buffer   audio.FrameQueue
+	marks    []playbackMark
+	bufferMu sync.Mutex
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type BridgeOptions struct {
+	token         string
+	encoding      audio.EncodingInfo
+	channels      int
+	frameDuration time.Duration
+}
+
+type BridgeOption func(*BridgeOptions)
+
+// WithMeetingToken sets the meeting token used to join the room, required
+// for private rooms.
+func WithMeetingToken(token string) BridgeOption {
+	return func(o *BridgeOptions) {
+		o.token = token
+	}
+}
+
+// WithEncodingInfo sets the encoding of audio exchanged with the room,
+// defaults to [audio.GetDefaultEncodingInfo].
+func WithEncodingInfo(encoding audio.EncodingInfo) BridgeOption {
+	return func(o *BridgeOptions) {
+		o.encoding = encoding
+	}
+}
+
+// WithChannels sets the number of interleaved channels of audio exchanged
+// with the room, defaults to 1. The transport must exchange audio with as
+// many channels.
+func WithChannels(channels int) BridgeOption {
+	return func(o *BridgeOptions) {
+		o.channels = channels
+	}
+}
+
+// WithFrameDuration sets the duration of audio written to the room at once,
+// defaults to 20ms.
+func WithFrameDuration(duration time.Duration) BridgeOption {
+	return func(o *BridgeOptions) {
+		o.frameDuration = duration
+	}
+}
+
+// NewBridge joins the room at roomURL using transport.
+func NewBridge(ctx context.Context, transport Transport, roomURL string, opts ...BridgeOption) (*Bridge, error) {
+	options := BridgeOptions{
+		encoding:      audio.GetDefaultEncodingInfo(),
+		channels:      1,
+		frameDuration: defaultFrameDuration,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if transport == nil {
+		return nil, fmt.Errorf("transport is required")
+	}
+	if options.encoding.IsZero() || options.encoding.Format.ByteSize() <= 0 {
+		return nil, fmt.Errorf("invalid encoding: %+v", options.encoding)
+	}
+	if options.channels <= 0 {
+		return nil, fmt.Errorf("channel count must be positive")
+	}
+	if options.frameDuration <= 0 {
+		return nil, fmt.Errorf("frame duration must be positive")
+	}
+
+	call, err := transport.Join(ctx, roomURL, options.token, options.encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join daily room: %w", err)
+	}
+
+	b := &Bridge{
+		call:          call,
+		encoding:      options.encoding,
+		channels:      options.channels,
+		frameDuration: options.frameDuration,
+		done:          make(chan struct{}),
+	}
+	call.OnAudio(b.receiveAudio)
+	go b.publishLoop()
+
+	return b, nil
+}
+
+func (b *Bridge) EncodingInfo() audio.EncodingInfo {
+	return b.encoding
+}
+
+// Stream starts forwarding room audio to onAudio.
+func (b *Bridge) Stream(ctx context.Context, onAudio func(audio []byte)) error {
+	return b.StartCapture(ctx, onAudio)
+}
+
+func (b *Bridge) StartCapture(_ context.Context, onAudio func(audio []byte)) error {
+	b.captureMu.Lock()
+	defer b.captureMu.Unlock()
+	b.onAudio = onAudio
+	b.capturing = true
+	return nil
+}
+
+func (b *Bridge) StopCapture() error {
+	b.captureMu.Lock()
+	defer b.captureMu.Unlock()
+	b.capturing = false
+	return nil
+}
+
+// Close leaves the room and stops publishing audio.
+func (b *Bridge) Close() {
+	b.closeOnce.Do(func() {
+		close(b.done)
+		b.call.OnAudio(nil)
+		if err := b.call.Leave(context.Background()); err != nil {
+			logger.Error("failed to leave daily room", "error", err)
+		}
+	})
+}
+
+func (b *Bridge) receiveAudio(audio []byte) {
+	b.captureMu.Lock()
+	onAudio, capturing := b.onAudio, b.capturing
+	b.captureMu.Unlock()
+
+	if capturing && onAudio != nil {
+		onAudio(audio)
+	}
+}
+
+// SendAudio queues audio for publishing to the room.
+func (b *Bridge) SendAudio(audio []byte) error {
+	select {
+	case <-b.done:
+		return fmt.Errorf("bridge closed")
+	default:
+	}
+
+	b.bufferMu.Lock()
+	defer b.bufferMu.Unlock()
+	b.buffer.Write(audio)
+	return nil
+}
+
+// ClearBuffer drops queued audio together with its pending marks, whose
+// callbacks are not called.
+func (b *Bridge) ClearBuffer() {
+	b.bufferMu.Lock()
+	b.buffer.Reset()
+	dropped := b.marks
+	b.marks = nil
+	b.bufferMu.Unlock()
+
+	for _, mark := range dropped {
+		if mark.cleared != nil {
+			mark.cleared()
+		}
+	}
+}
+
+// Mark calls callback once all audio queued before it has been published.
+func (b *Bridge) Mark(mark string, callback func(string)) error {
+	b.addMark(playbackMark{name: mark, callback: callback})
+	return nil
+}
+
+// AwaitMark blocks until all currently queued audio has been published. It
+// fails with [ErrBufferCleared] if the audio is dropped by
+// [Bridge.ClearBuffer] first.
+func (b *Bridge) AwaitMark() error {
+	played := make(chan struct{})
+	cleared := make(chan struct{})
+	b.addMark(playbackMark{
+		callback: func(string) { close(played) },
+		cleared:  func() { close(cleared) },
+	})
+
+	select {
+	case <-played:
+		return nil
+	case <-cleared:
+		return ErrBufferCleared
+	case <-b.done:
+		return fmt.Errorf("bridge closed")
+	}
+}
+
+func (b *Bridge) addMark(mark playbackMark) {
+	b.bufferMu.Lock()
+	defer b.bufferMu.Unlock()
+	mark.position = b.buffer.Len()
+	b.marks = append(b.marks, mark)
+}
+
+type playbackMark struct {
+	name     string
+	position int
+	callback func(string)
+	// cleared is called instead of callback when the mark is dropped by
+	// [Bridge.ClearBuffer].
+	cleared func()
+}
+
+func (b *Bridge) publishLoop() {
+	frameSize := b.frameSize()
+	ticker := time.NewTicker(b.frameDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+		}
+
+		frame, passedMarks := b.nextFrame(frameSize)
+		if len(frame) > 0 {
+			if err := b.call.WriteAudio(frame); err != nil {
+				logger.Error("failed to write audio to daily room", "error", err)
+			}
+		}
+		audio.PutFrame(frame)
+		for _, mark := range passedMarks {
+			mark.callback(mark.name)
+		}
+	}
+}
+
+// frameSize returns the size in bytes of the audio written to the room at
+// once, a whole number of samples of every channel.
+func (b *Bridge) frameSize() int {
+	samples := b.encoding.SampleRate * int(b.frameDuration) / int(time.Second)
+	return samples * b.encoding.Format.ByteSize() * b.channels
+}
+
+func (b *Bridge) nextFrame(frameSize int) ([]byte, []playbackMark) {
+	b.bufferMu.Lock()
+	defer b.bufferMu.Unlock()
+
+	n := min(frameSize, b.buffer.Len())
+	frame := audio.GetFrame(n)
+	b.buffer.Read(frame)
+
+	passed := 0
+	for i := range b.marks {
+		b.marks[i].position -= n
+		if b.marks[i].position <= 0 {
+			passed++
+		}
+	}
+	passedMarks := b.marks[:passed:passed]
+	b.marks = b.marks[passed:]
+
+	return frame, passedMarks
+}
@@ -0,0 +1,193 @@
+package daily
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+func TestBridgePublishesAudioBeforeReportingMarks(t *testing.T) {
+	call := &fakeCall{}
+	bridge, err := NewBridge(context.Background(), fakeTransport{call: call}, "https://example.daily.co/room",
+		WithFrameDuration(time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer bridge.Close()
+
+	// 10ms of default encoded audio
+	frame := make([]byte, audio.DefaultSampleRate*2/100)
+	if err := bridge.SendAudio(frame); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	marked := make(chan int, 1)
+	if err := bridge.Mark("first", func(string) { marked <- call.written() }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case written := <-marked:
+		if written != len(frame) {
+			t.Fatalf("expected %d bytes published before mark, got %d", len(frame), written)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for mark")
+	}
+}
+
+func TestBridgeClearBufferDropsQueuedAudio(t *testing.T) {
+	call := &fakeCall{}
+	bridge, err := NewBridge(context.Background(), fakeTransport{call: call}, "https://example.daily.co/room",
+		WithFrameDuration(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer bridge.Close()
+
+	_ = bridge.SendAudio(make([]byte, 1024))
+	_ = bridge.Mark("dropped", func(string) { t.Errorf("mark should have been dropped") })
+	bridge.ClearBuffer()
+
+	frame, marks := bridge.nextFrame(640)
+	if len(frame) != 0 || len(marks) != 0 {
+		t.Fatalf("expected empty buffer after clear, got %d bytes and %d marks", len(frame), len(marks))
+	}
+}
+
+func TestBridgeAwaitMarkReturnsWhenTheBufferIsCleared(t *testing.T) {
+	bridge, err := NewBridge(context.Background(), fakeTransport{call: &fakeCall{}}, "https://example.daily.co/room",
+		WithFrameDuration(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer bridge.Close()
+
+	_ = bridge.SendAudio(make([]byte, 1024))
+	awaited := make(chan error, 1)
+	go func() { awaited <- bridge.AwaitMark() }()
+	// The mark is set once the bridge holds it.
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(time.Millisecond) {
+		bridge.bufferMu.Lock()
+		marks := len(bridge.marks)
+		bridge.bufferMu.Unlock()
+		if marks == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for the mark")
+		}
+	}
+	bridge.ClearBuffer()
+
+	select {
+	case err := <-awaited:
+		if !errors.Is(err, ErrBufferCleared) {
+			t.Fatalf("expected ErrBufferCleared, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("AwaitMark kept waiting after the buffer was cleared")
+	}
+}
+
+func TestBridgeFramesHoldEveryChannel(t *testing.T) {
+	bridge, err := NewBridge(context.Background(), fakeTransport{call: &fakeCall{}}, "https://example.daily.co/room",
+		WithEncodingInfo(audio.EncodingInfo{SampleRate: 48000, Format: audio.EncodingLinear16}),
+		WithChannels(2), WithFrameDuration(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer bridge.Close()
+
+	// 20ms of 48kHz 16-bit stereo
+	if size := bridge.frameSize(); size != 960*2*2 {
+		t.Fatalf("expected stereo frames of %d bytes, got %d", 960*2*2, size)
+	}
+}
+
+func TestBridgeForwardsRoomAudioOnlyWhileCapturing(t *testing.T) {
+	call := &fakeCall{}
+	bridge, err := NewBridge(context.Background(), fakeTransport{call: call}, "https://example.daily.co/room")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer bridge.Close()
+
+	received := 0
+	if err := bridge.Stream(context.Background(), func(audio []byte) { received += len(audio) }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	call.receive([]byte{1, 2})
+	_ = bridge.StopCapture()
+	call.receive([]byte{3, 4})
+
+	if received != 2 {
+		t.Fatalf("expected 2 bytes forwarded, got %d", received)
+	}
+}
+
+type fakeTransport struct {
+	call *fakeCall
+}
+
+func (t fakeTransport) Join(context.Context, string, string, audio.EncodingInfo) (Call, error) {
+	return t.call, nil
+}
+
+type fakeCall struct {
+	mu      sync.Mutex
+	onAudio func(audio []byte)
+	audio   []byte
+}
+
+func (c *fakeCall) OnAudio(onAudio func(audio []byte)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onAudio = onAudio
+}
+
+func (c *fakeCall) WriteAudio(audio []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.audio = append(c.audio, audio...)
+	return nil
+}
+
+func (c *fakeCall) Leave(context.Context) error { return nil }
+
+func (c *fakeCall) written() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.audio)
+}
+
+func (c *fakeCall) receive(audio []byte) {
+	c.mu.Lock()
+	onAudio := c.onAudio
+	c.mu.Unlock()
+	if onAudio != nil {
+		onAudio(audio)
+	}
+}
+
+func BenchmarkBridgeBuffering(b *testing.B) {
+	bridge, err := NewBridge(context.Background(), fakeTransport{call: &fakeCall{}}, "https://example.daily.co/room",
+		WithFrameDuration(time.Hour))
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+	defer bridge.Close()
+	audioChunk := make([]byte, 640)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(audioChunk)))
+	for b.Loop() {
+		_ = bridge.SendAudio(audioChunk)
+		frame, _ := bridge.nextFrame(len(audioChunk))
+		audio.PutFrame(frame)
+	}
+}
@@ -0,0 +1,14 @@
+// Package daily bridges orchestrator audio to Daily (https://daily.co) rooms.
+//
+// Daily media is carried over WebRTC, which this package does not implement
+// and for which it ships no [Transport]. The application provides the
+// transport joining rooms and exchanging raw PCM frames with them, e.g. an
+// adapter around daily-go or a pion based client, and the [Bridge] takes care
+// of the parts the orchestrator cares about: it exposes the room as an audio
+// input and a marked audio output, paces outgoing speech in real time so
+// playback marks fire when the audio has actually been sent to the room, and
+// drops buffered speech on interruption.
+//
+// [RoomsClient] covers the REST side needed to run an agent: creating rooms
+// and meeting tokens with the account API key.
+package daily
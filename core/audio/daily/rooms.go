@@ -0,0 +1,232 @@
+package daily
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/credentials"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	defaultAPIURL    = "https://api.daily.co/v1"
+	envVarApiKeyName = "DAILY_API_KEY"
+)
+
+// RoomsClient manages Daily rooms and meeting tokens through the REST API.
+type RoomsClient struct {
+	credentials credentials.Credentials
+	apiURL      string
+	httpClient  *http.Client
+}
+
+type RoomsClientOptions struct {
+	credentials credentials.Credentials
+	httpClient  *http.Client
+}
+
+type RoomsClientOption func(*RoomsClientOptions)
+
+// WithAPIKey sets the Daily API key, by default it is read from the
+// DAILY_API_KEY environment variable.
+func WithAPIKey(apiKey string) RoomsClientOption {
+	return func(o *RoomsClientOptions) {
+		o.credentials = credentials.Static{envVarApiKeyName: apiKey}
+	}
+}
+
+// WithCredentials sets the credentials the DAILY_API_KEY is resolved from on
+// each request, defaults to [credentials.Default].
+func WithCredentials(source credentials.Credentials) RoomsClientOption {
+	return func(o *RoomsClientOptions) {
+		o.credentials = source
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for API requests.
+func WithHTTPClient(client *http.Client) RoomsClientOption {
+	return func(o *RoomsClientOptions) {
+		o.httpClient = client
+	}
+}
+
+func NewRoomsClient(opts ...RoomsClientOption) (*RoomsClient, error) {
+	options := RoomsClientOptions{
+		credentials: credentials.Default(),
+		httpClient:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if _, ok := options.credentials.(credentials.Env); ok {
+		if _, err := options.credentials.Credential(context.Background(), envVarApiKeyName); err != nil {
+			return nil, fmt.Errorf("daily api key not found")
+		}
+	}
+
+	return &RoomsClient{
+		credentials: options.credentials,
+		apiURL:      defaultAPIURL,
+		httpClient:  options.httpClient,
+	}, nil
+}
+
+// Room is a Daily room as returned by the REST API.
+type Room struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Privacy string `json:"privacy"`
+}
+
+type CreateRoomOptions struct {
+	// Name of the room, Daily generates one if empty.
+	Name string
+	// Private rooms require a meeting token to join.
+	Private bool
+	// ExpiresAt removes the room after the given time, zero keeps it.
+	ExpiresAt time.Time
+}
+
+// CreateRoom creates a new Daily room.
+func (c *RoomsClient) CreateRoom(ctx context.Context, opts CreateRoomOptions) (Room, error) {
+	ctx, span := tracer.Start(ctx, "create daily room")
+	defer span.End()
+
+	body := createRoomRequest{Name: opts.Name, Privacy: "public"}
+	if opts.Private {
+		body.Privacy = "private"
+	}
+	if !opts.ExpiresAt.IsZero() {
+		body.Properties.Exp = opts.ExpiresAt.Unix()
+	}
+
+	var room Room
+	if err := c.do(ctx, http.MethodPost, "/rooms", body, &room); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create room")
+		return Room{}, err
+	}
+	span.SetAttributes(attribute.String("daily.room", room.Name))
+
+	return room, nil
+}
+
+// DeleteRoom deletes the room with the given name.
+func (c *RoomsClient) DeleteRoom(ctx context.Context, name string) error {
+	ctx, span := tracer.Start(ctx, "delete daily room")
+	defer span.End()
+	span.SetAttributes(attribute.String("daily.room", name))
+
+	if err := c.do(ctx, http.MethodDelete, "/rooms/"+name, nil, nil); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to delete room")
+		return err
+	}
+	return nil
+}
+
+type MeetingTokenOptions struct {
+	// UserName is the display name of the participant joining with the token.
+	UserName string
+	// IsOwner grants owner privileges in the room.
+	IsOwner bool
+	// ExpiresAt invalidates the token after the given time, zero keeps it.
+	ExpiresAt time.Time
+}
+
+// CreateMeetingToken creates a token for joining the named room.
+func (c *RoomsClient) CreateMeetingToken(ctx context.Context, roomName string, opts MeetingTokenOptions) (string, error) {
+	ctx, span := tracer.Start(ctx, "create daily meeting token")
+	defer span.End()
+	span.SetAttributes(attribute.String("daily.room", roomName))
+
+	body := meetingTokenRequest{}
+	body.Properties.RoomName = roomName
+	body.Properties.UserName = opts.UserName
+	body.Properties.IsOwner = opts.IsOwner
+	if !opts.ExpiresAt.IsZero() {
+		body.Properties.Exp = opts.ExpiresAt.Unix()
+	}
+
+	var response meetingTokenResponse
+	if err := c.do(ctx, http.MethodPost, "/meeting-tokens", body, &response); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to create meeting token")
+		return "", err
+	}
+
+	return response.Token, nil
+}
+
+func (c *RoomsClient) do(ctx context.Context, method string, path string, body any, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error marshalling JSON: %w", err)
+		}
+		reqBody = bytes.NewReader(bodyBytes)
+	}
+
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return fmt.Errorf("daily api key not found: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("daily api returned %s: %s", resp.Status, bytes.TrimSpace(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
+
+type createRoomRequest struct {
+	Name       string `json:"name,omitempty"`
+	Privacy    string `json:"privacy"`
+	Properties struct {
+		Exp int64 `json:"exp,omitempty"`
+	} `json:"properties"`
+}
+
+type meetingTokenRequest struct {
+	Properties struct {
+		RoomName string `json:"room_name"`
+		UserName string `json:"user_name,omitempty"`
+		IsOwner  bool   `json:"is_owner,omitempty"`
+		Exp      int64  `json:"exp,omitempty"`
+	} `json:"properties"`
+}
+
+type meetingTokenResponse struct {
+	Token string `json:"token"`
+}
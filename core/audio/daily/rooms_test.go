@@ -0,0 +1,97 @@
+package daily
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/credentials"
+)
+
+func TestRoomsClientCreatesMeetingToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/meeting-tokens" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected authorization header %q", got)
+		}
+
+		var body meetingTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		if body.Properties.RoomName != "support" {
+			t.Errorf("unexpected room name %q", body.Properties.RoomName)
+		}
+
+		_, _ = w.Write([]byte(`{"token":"abc"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewRoomsClient(WithAPIKey("secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.apiURL = server.URL
+
+	token, err := client.CreateMeetingToken(context.Background(), "support", MeetingTokenOptions{UserName: "ema"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "abc" {
+		t.Fatalf("expected token abc, got %q", token)
+	}
+}
+
+func TestRoomsClientReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid-request-error"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := NewRoomsClient(WithAPIKey("secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.apiURL = server.URL
+
+	if _, err := client.CreateRoom(context.Background(), CreateRoomOptions{Name: "support"}); err == nil {
+		t.Fatalf("expected error for failed request")
+	}
+}
+
+func TestRoomsClientResolvesCredentialsPerRequest(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	keys := []string{"first", "second"}
+	client, err := NewRoomsClient(WithCredentials(credentials.Func(func(ctx context.Context, name string) (string, error) {
+		if name != "DAILY_API_KEY" {
+			return "", credentials.ErrNotFound
+		}
+		key := keys[0]
+		keys = keys[1:]
+		return key, nil
+	})))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.apiURL = server.URL
+
+	for range 2 {
+		if err := client.DeleteRoom(context.Background(), "support"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(authorizations) != 2 || authorizations[0] != "Bearer first" || authorizations[1] != "Bearer second" {
+		t.Fatalf("expected rotated keys, got %v", authorizations)
+	}
+}
@@ -0,0 +1,27 @@
+package daily
+
+import (
+	"context"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+// Transport joins Daily rooms and exchanges raw audio with them.
+type Transport interface {
+	// Join joins the room at roomURL as a participant. Audio exchanged with the
+	// returned call uses the requested encoding.
+	Join(ctx context.Context, roomURL string, token string, encoding audio.EncodingInfo) (Call, error)
+}
+
+// Call is a joined Daily room.
+type Call interface {
+	// OnAudio registers the callback receiving audio of remote participants,
+	// mixed into a single track. Registering a new callback replaces the
+	// previous one, nil stops delivery.
+	OnAudio(onAudio func(audio []byte))
+	// WriteAudio publishes audio to the room as the call's microphone track.
+	// The frame is reused once WriteAudio returns, so it must not be retained.
+	WriteAudio(audio []byte) error
+	// Leave leaves the room and releases the call.
+	Leave(ctx context.Context) error
+}
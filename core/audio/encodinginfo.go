@@ -51,4 +51,7 @@ const (
 	EncodingMulaw    encodingFormat = "mulaw"
 	EncodingALaw     encodingFormat = "alaw"
 	EncodingLinear16 encodingFormat = "linear16"
+	// EncodingOpus is audio in Opus packets, one packet per chunk, e.g. from
+	// browsers and WebRTC, see the opus package.
+	EncodingOpus encodingFormat = "opus"
 )
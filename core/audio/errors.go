@@ -0,0 +1,15 @@
+package audio
+
+import "errors"
+
+// Audio input clients wrap these errors when capture fails for a known
+// reason, so the orchestrator can report it, e.g. to show the microphone state
+// in a UI.
+var (
+	// ErrPermissionDenied reports that the application may not use the
+	// capture device.
+	ErrPermissionDenied = errors.New("audio device permission denied")
+	// ErrDeviceBusy reports that the capture device is held by another
+	// application.
+	ErrDeviceBusy = errors.New("audio device busy")
+)
@@ -13,7 +13,7 @@ type playbackClient struct {
 	device       *malgo.Device
 	config       malgo.DeviceConfig
 
-	leftoverAudio []byte
+	leftoverAudio audio.FrameQueue
 	marks         []playbackMark
 
 	mu      sync.Mutex
@@ -90,7 +90,7 @@ func (c *playbackClient) SendAudio(audio []byte) error {
 
 	c.audioMu.Lock()
 	defer c.audioMu.Unlock()
-	c.leftoverAudio = append(c.leftoverAudio, audio...)
+	c.leftoverAudio.Write(audio)
 	return nil
 }
 
@@ -99,7 +99,7 @@ func (c *playbackClient) ClearBuffer() {
 	c.marksMu.Lock()
 	defer c.audioMu.Unlock()
 	defer c.marksMu.Unlock()
-	c.leftoverAudio = make([]byte, 0)
+	c.leftoverAudio.Reset()
 	c.marks = nil
 
 }
@@ -117,7 +117,7 @@ func (c *playbackClient) Mark(mark string, callback func(string)) error {
 	defer c.marksMu.Unlock()
 	c.marks = append(c.marks, playbackMark{
 		name:     mark,
-		position: len(c.leftoverAudio),
+		position: c.leftoverAudio.Len(),
 		callback: callback,
 	})
 	return nil
@@ -148,24 +148,11 @@ func (c *playbackClient) processAudio(bytesPerFrame int) malgo.DataProc {
 		need := int(frameCount) * bytesPerFrame
 		c.processMarks(need)
 
-		if len(c.leftoverAudio) == 0 {
-			// TODO: Process all marks, but there probably shouldn't be any
-			return
-		}
-
-		if len(c.leftoverAudio) < need {
-			// TODO: Maybe we need to fill it until the end here
-			_ = copy(pOutput, c.leftoverAudio)
-			c.audioMu.Lock()
-			c.leftoverAudio = nil
-			c.audioMu.Unlock()
-			return
-		}
-
-		_ = copy(pOutput, c.leftoverAudio[:need])
 		c.audioMu.Lock()
-		c.leftoverAudio = c.leftoverAudio[need:]
-		c.audioMu.Unlock()
+		defer c.audioMu.Unlock()
+		// TODO: Maybe we need to fill it until the end if there is less audio
+		// than needed
+		_ = c.leftoverAudio.Read(pOutput[:min(need, len(pOutput))])
 	}
 }
 
@@ -0,0 +1,248 @@
+//go:build opus
+
+package opus
+
+/*
+#cgo pkg-config: opus
+#include <opus.h>
+
+static int ema_opus_set_bitrate(OpusEncoder *st, opus_int32 bitrate) {
+	return opus_encoder_ctl(st, OPUS_SET_BITRATE(bitrate));
+}
+
+static int ema_opus_reset_encoder(OpusEncoder *st) {
+	return opus_encoder_ctl(st, OPUS_RESET_STATE);
+}
+*/
+import "C"
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// maxPacketSize is the size libopus recommends for encoded packets.
+const maxPacketSize = 4000
+
+// The Application constants must be the values of libopus.
+var (
+	_ = [1]struct{}{}[ApplicationVoIP-C.OPUS_APPLICATION_VOIP]
+	_ = [1]struct{}{}[ApplicationAudio-C.OPUS_APPLICATION_AUDIO]
+	_ = [1]struct{}{}[ApplicationLowDelay-C.OPUS_APPLICATION_RESTRICTED_LOWDELAY]
+)
+
+// Encoder encodes linear16 audio to Opus packets of a fixed frame duration.
+type Encoder struct {
+	EncoderOptions
+	sampleRate int
+	channels   int
+	// frameSize is the number of samples per channel of each packet.
+	frameSize int
+
+	mu      sync.Mutex
+	encoder *C.OpusEncoder
+	pending []int16
+	packet  []byte
+}
+
+// NewEncoder creates an encoder of audio with sampleRate, one of 8000,
+// 12000, 16000, 24000 or 48000, and 1 or 2 interleaved channels.
+func NewEncoder(sampleRate, channels int, opts ...EncoderOption) (*Encoder, error) {
+	options := EncoderOptions{frameDuration: DefaultFrameDuration, application: ApplicationVoIP}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if err := validateFormat(sampleRate, channels); err != nil {
+		return nil, err
+	}
+	frameSize, err := FrameSize(sampleRate, options.frameDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	var status C.int
+	encoder := C.opus_encoder_create(C.opus_int32(sampleRate), C.int(channels), C.int(options.application), &status)
+	if status != C.OPUS_OK {
+		return nil, fmt.Errorf("failed to create opus encoder: %w", opusError(status))
+	}
+	if options.bitrate > 0 {
+		if status := C.ema_opus_set_bitrate(encoder, C.opus_int32(options.bitrate)); status != C.OPUS_OK {
+			C.opus_encoder_destroy(encoder)
+			return nil, fmt.Errorf("failed to set opus bitrate: %w", opusError(status))
+		}
+	}
+
+	return &Encoder{
+		EncoderOptions: options,
+		sampleRate:     sampleRate,
+		channels:       channels,
+		frameSize:      frameSize,
+		encoder:        encoder,
+		packet:         make([]byte, maxPacketSize),
+	}, nil
+}
+
+// Encode buffers pcm, linear16 audio, and returns a packet for each full
+// frame buffered. Audio short of a frame is kept for the next call.
+func (e *Encoder) Encode(pcm []byte) ([][]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.encoder == nil {
+		return nil, ErrClosed
+	}
+	for i := 0; i+1 < len(pcm); i += 2 {
+		e.pending = append(e.pending, int16(binary.LittleEndian.Uint16(pcm[i:])))
+	}
+	return e.encodePending()
+}
+
+// Flush pads the buffered audio with silence to a full frame and returns its
+// packet, if any audio was buffered.
+func (e *Encoder) Flush() ([][]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.encoder == nil {
+		return nil, ErrClosed
+	}
+	samples := e.frameSize * e.channels
+	if remainder := len(e.pending) % samples; remainder != 0 {
+		e.pending = append(e.pending, make([]int16, samples-remainder)...)
+	}
+	return e.encodePending()
+}
+
+// Reset drops the buffered audio and the state of the encoder, e.g. once
+// playback was cleared.
+func (e *Encoder) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.pending = nil
+	if e.encoder != nil {
+		C.ema_opus_reset_encoder(e.encoder)
+	}
+}
+
+// Close frees the encoder, it cannot be used afterwards.
+func (e *Encoder) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.encoder != nil {
+		C.opus_encoder_destroy(e.encoder)
+		e.encoder = nil
+	}
+}
+
+// SampleRate returns the sample rate of the encoded audio.
+func (e *Encoder) SampleRate() int { return e.sampleRate }
+
+func (e *Encoder) encodePending() ([][]byte, error) {
+	samples := e.frameSize * e.channels
+	var packets [][]byte
+	for len(e.pending) >= samples {
+		n := C.opus_encode(e.encoder,
+			(*C.opus_int16)(unsafe.Pointer(&e.pending[0])), C.int(e.frameSize),
+			(*C.uchar)(unsafe.Pointer(&e.packet[0])), C.opus_int32(len(e.packet)))
+		e.pending = e.pending[samples:]
+		if n < 0 {
+			return packets, fmt.Errorf("failed to encode opus frame: %w", opusError(C.int(n)))
+		}
+		packets = append(packets, append([]byte(nil), e.packet[:n]...))
+	}
+	e.pending = append(e.pending[:0:0], e.pending...)
+	return packets, nil
+}
+
+// Decoder decodes Opus packets to linear16 audio.
+type Decoder struct {
+	sampleRate int
+	channels   int
+
+	mu      sync.Mutex
+	decoder *C.OpusDecoder
+	pcm     []int16
+	// lastFrameSize is the number of samples per channel of the last packet,
+	// concealed lost packets are assumed to be as long.
+	lastFrameSize int
+}
+
+// NewDecoder creates a decoder of audio with sampleRate, one of 8000, 12000,
+// 16000, 24000 or 48000, and 1 or 2 interleaved channels. Packets encoded at
+// other sample rates or channel counts are converted.
+func NewDecoder(sampleRate, channels int) (*Decoder, error) {
+	if err := validateFormat(sampleRate, channels); err != nil {
+		return nil, err
+	}
+	frameSize, err := FrameSize(sampleRate, DefaultFrameDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	var status C.int
+	decoder := C.opus_decoder_create(C.opus_int32(sampleRate), C.int(channels), &status)
+	if status != C.OPUS_OK {
+		return nil, fmt.Errorf("failed to create opus decoder: %w", opusError(status))
+	}
+	return &Decoder{
+		sampleRate:    sampleRate,
+		channels:      channels,
+		decoder:       decoder,
+		pcm:           make([]int16, maxFrameSize(sampleRate)*channels),
+		lastFrameSize: frameSize,
+	}, nil
+}
+
+// Decode returns the linear16 audio of packet. An empty packet stands for a
+// lost one, whose audio is concealed from the packets before it.
+func (d *Decoder) Decode(packet []byte) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.decoder == nil {
+		return nil, ErrClosed
+	}
+	var n C.int
+	if len(packet) == 0 {
+		n = C.opus_decode(d.decoder, nil, 0,
+			(*C.opus_int16)(unsafe.Pointer(&d.pcm[0])), C.int(d.lastFrameSize), 0)
+	} else {
+		n = C.opus_decode(d.decoder,
+			(*C.uchar)(unsafe.Pointer(&packet[0])), C.opus_int32(len(packet)),
+			(*C.opus_int16)(unsafe.Pointer(&d.pcm[0])), C.int(len(d.pcm)/d.channels), 0)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("failed to decode opus packet: %w", opusError(n))
+	}
+	if len(packet) > 0 {
+		d.lastFrameSize = int(n)
+	}
+
+	pcm := make([]byte, 0, 2*int(n)*d.channels)
+	for _, sample := range d.pcm[:int(n)*d.channels] {
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(sample))
+	}
+	return pcm, nil
+}
+
+// Close frees the decoder, it cannot be used afterwards.
+func (d *Decoder) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.decoder != nil {
+		C.opus_decoder_destroy(d.decoder)
+		d.decoder = nil
+	}
+}
+
+// SampleRate returns the sample rate of the decoded audio.
+func (d *Decoder) SampleRate() int { return d.sampleRate }
+
+func opusError(status C.int) error {
+	return fmt.Errorf("%w: %s", ErrCodec, C.GoString(C.opus_strerror(status)))
+}
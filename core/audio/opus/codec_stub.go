@@ -0,0 +1,51 @@
+//go:build !opus
+
+package opus
+
+// Encoder encodes linear16 audio to Opus packets of a fixed frame duration,
+// it requires the opus build tag.
+type Encoder struct {
+	EncoderOptions
+	sampleRate int
+}
+
+// NewEncoder fails with [ErrUnsupported], or [ErrUnsupportedFormat] for
+// formats Opus does not support, without the opus build tag.
+func NewEncoder(sampleRate, channels int, opts ...EncoderOption) (*Encoder, error) {
+	options := EncoderOptions{frameDuration: DefaultFrameDuration}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if err := validateFormat(sampleRate, channels); err != nil {
+		return nil, err
+	}
+	if _, err := FrameSize(sampleRate, options.frameDuration); err != nil {
+		return nil, err
+	}
+	return nil, ErrUnsupported
+}
+
+func (e *Encoder) Encode(pcm []byte) ([][]byte, error) { return nil, ErrUnsupported }
+func (e *Encoder) Flush() ([][]byte, error)            { return nil, ErrUnsupported }
+func (e *Encoder) Reset()                              {}
+func (e *Encoder) Close()                              {}
+func (e *Encoder) SampleRate() int                     { return e.sampleRate }
+
+// Decoder decodes Opus packets to linear16 audio, it requires the opus build
+// tag.
+type Decoder struct {
+	sampleRate int
+}
+
+// NewDecoder fails with [ErrUnsupported], or [ErrUnsupportedFormat] for
+// formats Opus does not support, without the opus build tag.
+func NewDecoder(sampleRate, channels int) (*Decoder, error) {
+	if err := validateFormat(sampleRate, channels); err != nil {
+		return nil, err
+	}
+	return nil, ErrUnsupported
+}
+
+func (d *Decoder) Decode(packet []byte) ([]byte, error) { return nil, ErrUnsupported }
+func (d *Decoder) Close()                               {}
+func (d *Decoder) SampleRate() int                      { return d.sampleRate }
@@ -0,0 +1,17 @@
+//go:build !opus
+
+package opus
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCodecIsUnsupportedWithoutTheBuildTag(t *testing.T) {
+	if _, err := NewEncoder(16000, 1); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected the encoder to be unsupported, got %v", err)
+	}
+	if _, err := NewDecoder(16000, 1); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected the decoder to be unsupported, got %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+//go:build opus
+
+package opus
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+// tone returns d of a 16-bit sine tone at 16kHz.
+func tone(d time.Duration) []byte {
+	samples := int(d.Seconds() * 16000)
+	pcm := make([]byte, 0, 2*samples)
+	for i := range samples {
+		sample := int16(8000 * math.Sin(2*math.Pi*220*float64(i)/16000))
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(sample))
+	}
+	return pcm
+}
+
+func TestEncoderBuffersPartialFrames(t *testing.T) {
+	encoder, err := NewEncoder(16000, 1, WithFrameDuration(10*time.Millisecond), WithBitrate(24000))
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+
+	pcm := tone(25 * time.Millisecond)
+	if packets, err := encoder.Encode(pcm[:200]); err != nil || len(packets) != 0 {
+		t.Fatalf("expected no packets short of a frame, got %d, %v", len(packets), err)
+	}
+	if packets, err := encoder.Encode(pcm[200:]); err != nil || len(packets) != 2 {
+		t.Fatalf("expected 2 packets of 10ms, got %d, %v", len(packets), err)
+	}
+	if packets, err := encoder.Flush(); err != nil || len(packets) != 1 {
+		t.Fatalf("expected the remaining 5ms to be flushed, got %d, %v", len(packets), err)
+	}
+	if packets, err := encoder.Flush(); err != nil || len(packets) != 0 {
+		t.Fatalf("expected nothing left to flush, got %d, %v", len(packets), err)
+	}
+
+	encoder.Encode(pcm[:200])
+	encoder.Reset()
+	if packets, err := encoder.Flush(); err != nil || len(packets) != 0 {
+		t.Fatalf("expected reset to drop buffered audio, got %d, %v", len(packets), err)
+	}
+}
+
+func TestDecoderDecodesAndConcealsLostPackets(t *testing.T) {
+	encoder, err := NewEncoder(16000, 1)
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	decoder, err := NewDecoder(16000, 1)
+	if err != nil {
+		t.Fatalf("failed to create decoder: %v", err)
+	}
+	defer decoder.Close()
+
+	packets, err := encoder.Encode(tone(40 * time.Millisecond))
+	if err != nil || len(packets) != 2 {
+		t.Fatalf("expected 2 packets of 20ms, got %d, %v", len(packets), err)
+	}
+	for _, packet := range packets {
+		pcm, err := decoder.Decode(packet)
+		if err != nil || len(pcm) != 640 {
+			t.Fatalf("expected 20ms of linear16, got %d bytes, %v", len(pcm), err)
+		}
+	}
+	if pcm, err := decoder.Decode(nil); err != nil || len(pcm) != 640 {
+		t.Fatalf("expected a lost packet to be concealed with 20ms, got %d bytes, %v", len(pcm), err)
+	}
+
+	decoder.Close()
+	if _, err := decoder.Decode(packets[0]); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected a closed decoder to fail, got %v", err)
+	}
+}
+
+type inputStub struct {
+	packets [][]byte
+}
+
+func (i *inputStub) EncodingInfo() audio.EncodingInfo {
+	return audio.EncodingInfo{SampleRate: 16000, Format: audio.EncodingOpus}
+}
+
+func (i *inputStub) Stream(_ context.Context, onAudio func([]byte)) error {
+	for _, packet := range i.packets {
+		onAudio(packet)
+	}
+	return nil
+}
+
+func (i *inputStub) Close() {}
+
+type outputStub struct {
+	sent []string
+}
+
+func (o *outputStub) EncodingInfo() audio.EncodingInfo {
+	return audio.EncodingInfo{SampleRate: 16000, Format: audio.EncodingOpus}
+}
+
+func (o *outputStub) SendAudio([]byte) error {
+	o.sent = append(o.sent, "packet")
+	return nil
+}
+
+func (o *outputStub) ClearBuffer() {
+	o.sent = append(o.sent, "clear")
+}
+
+func (o *outputStub) Mark(mark string, _ func(string)) error {
+	o.sent = append(o.sent, "mark "+mark)
+	return nil
+}
+
+func TestDecodingInputStreamsLinear16(t *testing.T) {
+	encoder, err := NewEncoder(16000, 1)
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	defer encoder.Close()
+	packets, _ := encoder.Encode(tone(60 * time.Millisecond))
+
+	input, err := NewDecodingInput(&inputStub{packets: packets})
+	if err != nil {
+		t.Fatalf("failed to create input: %v", err)
+	}
+	defer input.Close()
+	if encoding := input.EncodingInfo(); encoding.Format != audio.EncodingLinear16 || encoding.SampleRate != 16000 {
+		t.Fatalf("expected linear16 at 16kHz, got %+v", encoding)
+	}
+
+	var chunks []int
+	input.Stream(context.Background(), func(pcm []byte) {
+		chunks = append(chunks, len(pcm))
+	})
+	if len(chunks) != 3 || chunks[0] != 640 {
+		t.Fatalf("expected 3 chunks of 20ms, got %v", chunks)
+	}
+}
+
+func TestEncodingOutputFlushesBeforeMarks(t *testing.T) {
+	stub := &outputStub{}
+	output, err := NewEncodingOutput(stub)
+	if err != nil {
+		t.Fatalf("failed to create output: %v", err)
+	}
+	defer output.Close()
+
+	output.SendAudio(tone(30 * time.Millisecond))
+	output.Mark("sentence", nil)
+	output.SendAudio(tone(10 * time.Millisecond))
+	output.ClearBuffer()
+	output.Mark("cleared", nil)
+
+	expected := []string{"packet", "packet", "mark sentence", "clear", "mark cleared"}
+	if len(stub.sent) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, stub.sent)
+	}
+	for i := range expected {
+		if stub.sent[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, stub.sent)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Package opus encodes and decodes Opus audio, the codec of browsers and
+// WebRTC, with libopus.
+//
+// [Encoder] and [Decoder] convert between Opus packets and linear16 audio.
+// [NewDecodingInput] and [NewEncodingOutput] wrap audio inputs delivering
+// Opus and audio outputs expecting Opus, so they can be passed to the
+// orchestrator, which works with linear16 audio:
+//
+//	input, err := opus.NewDecodingInput(webrtcTrack)
+//	output, err := opus.NewEncodingOutput(webClient, opus.WithFrameDuration(20*time.Millisecond))
+//	o := orchestration.NewOrchestrator(
+//		orchestration.WithAudioInput(input),
+//		orchestration.WithAudioOutputV1(output),
+//	)
+//
+// The codec requires libopus and its pkg-config file, e.g. the libopus-dev
+// package on Debian and Ubuntu or opus on Homebrew, and is only built with
+// the opus build tag, e.g. go build -tags opus. Without it the package builds
+// without cgo, and [NewEncoder] and [NewDecoder] fail with [ErrUnsupported].
+package opus
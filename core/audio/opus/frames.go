@@ -0,0 +1,56 @@
+package opus
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrCodec is the error libopus reports, wrapped with its description.
+	ErrCodec = errors.New("opus codec error")
+	// ErrUnsupportedFormat is the error of sample rates, channel counts and
+	// frame durations Opus does not support.
+	ErrUnsupportedFormat = errors.New("unsupported opus format")
+	// ErrClosed is the error of using a closed encoder or decoder.
+	ErrClosed = errors.New("opus codec closed")
+	// ErrUnsupported is the error of creating encoders and decoders in
+	// builds without the opus build tag.
+	ErrUnsupported = errors.New("opus codec requires the opus build tag")
+)
+
+// DefaultFrameDuration is the frame duration of encoded packets by default,
+// the usual duration for WebRTC.
+const DefaultFrameDuration = 20 * time.Millisecond
+
+// maxFrameDuration is the longest audio an Opus packet holds.
+const maxFrameDuration = 120 * time.Millisecond
+
+// FrameSize returns the number of samples per channel of a frame of d at
+// sampleRate. It fails for durations Opus cannot encode, any but 2.5, 5, 10,
+// 20, 40 and 60ms.
+func FrameSize(sampleRate int, d time.Duration) (int, error) {
+	switch d {
+	case 2500 * time.Microsecond, 5 * time.Millisecond, 10 * time.Millisecond,
+		20 * time.Millisecond, 40 * time.Millisecond, 60 * time.Millisecond:
+	default:
+		return 0, fmt.Errorf("%w: frame duration %v", ErrUnsupportedFormat, d)
+	}
+	return int(time.Duration(sampleRate) * d / time.Second), nil
+}
+
+func maxFrameSize(sampleRate int) int {
+	return int(time.Duration(sampleRate) * maxFrameDuration / time.Second)
+}
+
+func validateFormat(sampleRate, channels int) error {
+	switch sampleRate {
+	case 8000, 12000, 16000, 24000, 48000:
+	default:
+		return fmt.Errorf("%w: sample rate %d", ErrUnsupportedFormat, sampleRate)
+	}
+	if channels != 1 && channels != 2 {
+		return fmt.Errorf("%w: %d channels", ErrUnsupportedFormat, channels)
+	}
+	return nil
+}
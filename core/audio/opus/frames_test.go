@@ -0,0 +1,31 @@
+package opus
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFrameSize(t *testing.T) {
+	if size, err := FrameSize(48000, 2500*time.Microsecond); err != nil || size != 120 {
+		t.Fatalf("expected 120 samples for 2.5ms at 48kHz, got %d, %v", size, err)
+	}
+	if size, err := FrameSize(16000, 60*time.Millisecond); err != nil || size != 960 {
+		t.Fatalf("expected 960 samples for 60ms at 16kHz, got %d, %v", size, err)
+	}
+	if _, err := FrameSize(16000, 30*time.Millisecond); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected 30ms frames to be unsupported, got %v", err)
+	}
+}
+
+func TestNewEncoderRejectsUnsupportedFormats(t *testing.T) {
+	if _, err := NewEncoder(44100, 1); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected 44.1kHz to be unsupported, got %v", err)
+	}
+	if _, err := NewEncoder(16000, 3); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected 3 channels to be unsupported, got %v", err)
+	}
+	if _, err := NewEncoder(16000, 1, WithFrameDuration(15*time.Millisecond)); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected 15ms frames to be unsupported, got %v", err)
+	}
+}
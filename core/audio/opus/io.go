@@ -0,0 +1,148 @@
+package opus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+// Input is an audio input, like the ones the orchestrator takes.
+type Input interface {
+	EncodingInfo() audio.EncodingInfo
+	Stream(ctx context.Context, onAudio func(audio []byte)) error
+	Close()
+}
+
+// Output is an audio output with marks, like the ones the orchestrator
+// takes.
+type Output interface {
+	EncodingInfo() audio.EncodingInfo
+	SendAudio(audio []byte) error
+	ClearBuffer()
+	Mark(string, func(string)) error
+}
+
+// DecodingInput decodes the Opus packets of an input to linear16 audio.
+type DecodingInput struct {
+	input   Input
+	decoder *Decoder
+}
+
+// NewDecodingInput wraps input, whose audio chunks are single Opus packets,
+// with an input of the linear16 audio at the sample rate of input.
+func NewDecodingInput(input Input) (*DecodingInput, error) {
+	if input == nil {
+		return nil, errors.New("opus input is nil")
+	}
+	encoding := input.EncodingInfo()
+	decoder, err := NewDecoder(encoding.SampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &DecodingInput{input: input, decoder: decoder}, nil
+}
+
+func (i *DecodingInput) EncodingInfo() audio.EncodingInfo {
+	return audio.EncodingInfo{SampleRate: i.decoder.SampleRate(), Format: audio.EncodingLinear16}
+}
+
+// Stream streams input, passing the decoded audio of each packet to onAudio.
+// Packets that fail to decode are concealed.
+func (i *DecodingInput) Stream(ctx context.Context, onAudio func(audio []byte)) error {
+	return i.input.Stream(ctx, func(packet []byte) {
+		pcm, err := i.decoder.Decode(packet)
+		if err != nil {
+			if pcm, err = i.decoder.Decode(nil); err != nil {
+				return
+			}
+		}
+		onAudio(pcm)
+	})
+}
+
+// Close closes input and frees the decoder.
+func (i *DecodingInput) Close() {
+	i.input.Close()
+	i.decoder.Close()
+}
+
+// EncodingOutput encodes linear16 audio to the Opus packets of an output.
+type EncodingOutput struct {
+	output  Output
+	encoder *Encoder
+
+	mu sync.Mutex
+}
+
+// NewEncodingOutput wraps output, which takes single Opus packets, with an
+// output of linear16 audio at the sample rate of output.
+func NewEncodingOutput(output Output, opts ...EncoderOption) (*EncodingOutput, error) {
+	if output == nil {
+		return nil, errors.New("opus output is nil")
+	}
+	encoding := output.EncodingInfo()
+	encoder, err := NewEncoder(encoding.SampleRate, 1, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &EncodingOutput{output: output, encoder: encoder}, nil
+}
+
+func (o *EncodingOutput) EncodingInfo() audio.EncodingInfo {
+	return audio.EncodingInfo{SampleRate: o.encoder.SampleRate(), Format: audio.EncodingLinear16}
+}
+
+// SendAudio encodes audio and sends each full packet to output, the rest is
+// sent with the following audio or the next mark.
+func (o *EncodingOutput) SendAudio(audio []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	packets, err := o.encoder.Encode(audio)
+	if err != nil {
+		return err
+	}
+	return o.send(packets)
+}
+
+// ClearBuffer drops the audio not yet encoded and clears output.
+func (o *EncodingOutput) ClearBuffer() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.encoder.Reset()
+	o.output.ClearBuffer()
+}
+
+// Mark sends the audio not yet encoded, padded with silence, and marks
+// output, so the mark follows all audio sent before it.
+func (o *EncodingOutput) Mark(mark string, onMarked func(string)) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	packets, err := o.encoder.Flush()
+	if err != nil {
+		return err
+	}
+	if err := o.send(packets); err != nil {
+		return err
+	}
+	return o.output.Mark(mark, onMarked)
+}
+
+// Close frees the encoder, output is left open.
+func (o *EncodingOutput) Close() {
+	o.encoder.Close()
+}
+
+func (o *EncodingOutput) send(packets [][]byte) error {
+	for _, packet := range packets {
+		if err := o.output.SendAudio(packet); err != nil {
+			return fmt.Errorf("failed to send opus packet: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package opus
+
+import "time"
+
+// Application tunes the encoder for the kind of audio it encodes, its values
+// are those of libopus.
+type Application int
+
+const (
+	// ApplicationVoIP favors speech intelligibility, it is the default.
+	ApplicationVoIP Application = 2048
+	// ApplicationAudio favors faithfulness to the input, e.g. for music.
+	ApplicationAudio Application = 2049
+	// ApplicationLowDelay minimizes the coding delay.
+	ApplicationLowDelay Application = 2051
+)
+
+type EncoderOptions struct {
+	frameDuration time.Duration
+	bitrate       int
+	application   Application
+}
+
+type EncoderOption func(*EncoderOptions)
+
+// WithFrameDuration sets the duration of the audio in each packet, one of
+// 2.5, 5, 10, 20, 40 or 60ms, 20ms by default. Longer frames compress better
+// but add latency.
+func WithFrameDuration(d time.Duration) EncoderOption {
+	return func(o *EncoderOptions) {
+		o.frameDuration = d
+	}
+}
+
+// WithBitrate sets the target bitrate in bits per second, libopus picks one
+// for the sample rate and channels by default.
+func WithBitrate(bitrate int) EncoderOption {
+	return func(o *EncoderOptions) {
+		o.bitrate = bitrate
+	}
+}
+
+// WithApplication tunes the encoder for the audio it encodes, defaults to
+// [ApplicationVoIP].
+func WithApplication(application Application) EncoderOption {
+	return func(o *EncoderOptions) {
+		o.application = application
+	}
+}
@@ -0,0 +1,111 @@
+package audio
+
+import "sync"
+
+// FramePool reuses audio frame buffers so that steady streams of audio do
+// not allocate a new buffer for every frame. The zero value is ready to use
+// and safe for concurrent use.
+type FramePool struct {
+	pool sync.Pool
+}
+
+// Get returns a frame of length size. Its contents are undefined.
+func (p *FramePool) Get(size int) []byte {
+	if frame, ok := p.pool.Get().(*[]byte); ok {
+		if cap(*frame) >= size {
+			return (*frame)[:size]
+		}
+	}
+	return make([]byte, size)
+}
+
+// Put returns frame to the pool. The frame must not be used afterwards.
+func (p *FramePool) Put(frame []byte) {
+	if cap(frame) == 0 {
+		return
+	}
+	frame = frame[:0]
+	p.pool.Put(&frame)
+}
+
+var framePool FramePool
+
+// GetFrame returns a frame of length size from the shared frame pool.
+func GetFrame(size int) []byte { return framePool.Get(size) }
+
+// PutFrame returns frame to the shared frame pool. The frame must not be
+// used afterwards.
+func PutFrame(frame []byte) { framePool.Put(frame) }
+
+// FrameQueue is a FIFO of audio bytes stored in pooled frames, used by
+// playback clients to buffer audio without reallocating as the buffer grows
+// and drains. The zero value is an empty queue. It is not safe for
+// concurrent use.
+type FrameQueue struct {
+	frames [][]byte
+	// first is the index of the oldest unread frame and head the number of
+	// bytes already read from it.
+	first  int
+	head   int
+	length int
+}
+
+// Write copies audio to the end of the queue.
+func (q *FrameQueue) Write(audio []byte) {
+	if len(audio) == 0 {
+		return
+	}
+
+	frame := GetFrame(len(audio))
+	copy(frame, audio)
+	q.frames = append(q.frames, frame)
+	q.length += len(audio)
+}
+
+// Read moves up to len(p) bytes from the front of the queue into p and
+// returns how many were moved.
+func (q *FrameQueue) Read(p []byte) int {
+	n := 0
+	for n < len(p) && q.first < len(q.frames) {
+		frame := q.frames[q.first]
+		copied := copy(p[n:], frame[q.head:])
+		n += copied
+		q.head += copied
+		if q.head == len(frame) {
+			PutFrame(frame)
+			q.frames[q.first] = nil
+			q.first++
+			q.head = 0
+		}
+	}
+	q.length -= n
+
+	if q.first == len(q.frames) {
+		// Drained, reuse the frame list from the start
+		q.frames = q.frames[:0]
+		q.first = 0
+	} else if q.first >= 16 && q.first*2 >= len(q.frames) {
+		// Never fully drained, move the unread frames to the front so the
+		// frame list does not keep growing
+		unread := copy(q.frames, q.frames[q.first:])
+		clear(q.frames[unread:])
+		q.frames = q.frames[:unread]
+		q.first = 0
+	}
+	return n
+}
+
+// Len returns the number of queued bytes.
+func (q *FrameQueue) Len() int { return q.length }
+
+// Reset drops all queued audio.
+func (q *FrameQueue) Reset() {
+	for _, frame := range q.frames[q.first:] {
+		PutFrame(frame)
+	}
+	clear(q.frames)
+	q.frames = q.frames[:0]
+	q.first = 0
+	q.head = 0
+	q.length = 0
+}
@@ -0,0 +1,104 @@
+package audio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFrameQueueReadsAcrossFrames(t *testing.T) {
+	var q FrameQueue
+	q.Write([]byte{1, 2, 3})
+	q.Write([]byte{4, 5})
+	q.Write(nil)
+
+	if q.Len() != 5 {
+		t.Fatalf("expected 5 queued bytes, got %d", q.Len())
+	}
+
+	p := make([]byte, 4)
+	if n := q.Read(p); n != 4 || !bytes.Equal(p, []byte{1, 2, 3, 4}) {
+		t.Fatalf("expected to read [1 2 3 4], got %v (%d bytes)", p[:n], n)
+	}
+	if n := q.Read(p); n != 1 || p[0] != 5 {
+		t.Fatalf("expected to read the remaining byte 5, got %v (%d bytes)", p[:n], n)
+	}
+	if n := q.Read(p); n != 0 || q.Len() != 0 {
+		t.Fatalf("expected drained queue, read %d bytes with %d queued", n, q.Len())
+	}
+}
+
+func TestFrameQueueCopiesWrittenAudio(t *testing.T) {
+	var q FrameQueue
+	audio := []byte{1, 2}
+	q.Write(audio)
+	audio[0] = 9
+
+	p := make([]byte, 2)
+	q.Read(p)
+	if !bytes.Equal(p, []byte{1, 2}) {
+		t.Fatalf("expected queued audio to be unaffected by later writes to the source, got %v", p)
+	}
+}
+
+func TestFrameQueueResetDropsAudio(t *testing.T) {
+	var q FrameQueue
+	q.Write([]byte{1, 2, 3})
+	q.Read(make([]byte, 1))
+	q.Reset()
+
+	if q.Len() != 0 {
+		t.Fatalf("expected empty queue after reset, got %d bytes", q.Len())
+	}
+	q.Write([]byte{4})
+	p := make([]byte, 2)
+	if n := q.Read(p); n != 1 || p[0] != 4 {
+		t.Fatalf("expected only audio written after reset, got %v", p[:n])
+	}
+}
+
+func TestFrameQueueStaysBoundedWhenNeverDrained(t *testing.T) {
+	var q FrameQueue
+	frame := make([]byte, 4)
+	p := make([]byte, 4)
+	q.Write(frame)
+	for range 1000 {
+		q.Write(frame)
+		q.Read(p)
+	}
+
+	if len(q.frames) > 64 {
+		t.Fatalf("expected frame list to be compacted, got %d entries", len(q.frames))
+	}
+}
+
+// 20ms of 16kHz linear16 audio
+const benchmarkFrameSize = 640
+
+func BenchmarkFrameQueue(b *testing.B) {
+	var q FrameQueue
+	frame := make([]byte, benchmarkFrameSize)
+	out := make([]byte, benchmarkFrameSize)
+
+	b.ReportAllocs()
+	b.SetBytes(benchmarkFrameSize)
+	for b.Loop() {
+		q.Write(frame)
+		q.Read(out)
+	}
+}
+
+// BenchmarkAppendBuffer is the append and reslice buffering FrameQueue
+// replaces, kept for comparison.
+func BenchmarkAppendBuffer(b *testing.B) {
+	var buffer []byte
+	frame := make([]byte, benchmarkFrameSize)
+	out := make([]byte, benchmarkFrameSize)
+
+	b.ReportAllocs()
+	b.SetBytes(benchmarkFrameSize)
+	for b.Loop() {
+		buffer = append(buffer, frame...)
+		n := copy(out, buffer)
+		buffer = buffer[n:]
+	}
+}
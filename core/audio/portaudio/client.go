@@ -5,10 +5,10 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
-	"log"
 
 	"github.com/gordonklaus/portaudio"
 	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/logging"
 )
 
 type Client struct {
@@ -43,7 +43,7 @@ func NewClient(bufferSize int) (*Client, error) {
 }
 
 func (c *Client) Stream(ctx context.Context, onAudio func(audio []byte)) error {
-	log.Println("Starting microphone capture. Speak now...")
+	logging.Default().Info("starting microphone capture")
 	if err := c.stream.Start(); err != nil {
 		return fmt.Errorf("failed to start portaudio stream: %w", err)
 	}
@@ -54,7 +54,7 @@ func (c *Client) Stream(ctx context.Context, onAudio func(audio []byte)) error {
 			return nil
 		default:
 			if err := c.stream.Read(); err != nil {
-				log.Printf("Failed to read from PortAudio stream: %v", err)
+				logging.Default().Error("failed to read from portaudio stream", "error", err)
 			}
 
 			audioBuffer := bytes.Buffer{}
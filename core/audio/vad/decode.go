@@ -0,0 +1,62 @@
+package vad
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+// decode returns the samples of frame as values between -1 and 1.
+func decode(frame []byte, encoding audio.EncodingInfo) ([]float32, error) {
+	switch encoding.Format {
+	case audio.EncodingLinear16:
+		samples := make([]float32, len(frame)/2)
+		for i := range samples {
+			samples[i] = float32(int16(binary.LittleEndian.Uint16(frame[2*i:]))) / -math.MinInt16
+		}
+		return samples, nil
+	case audio.EncodingMulaw:
+		return decodeCompanded(frame, mulawToLinear), nil
+	case audio.EncodingALaw:
+		return decodeCompanded(frame, alawToLinear), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding.Format.Name())
+}
+
+func decodeCompanded(frame []byte, toLinear func(byte) int16) []float32 {
+	samples := make([]float32, len(frame))
+	for i, b := range frame {
+		samples[i] = float32(toLinear(b)) / -math.MinInt16
+	}
+	return samples
+}
+
+// mulawToLinear decodes a G.711 mu-law sample.
+func mulawToLinear(b byte) int16 {
+	b = ^b
+	magnitude := (int16(b&0x0F)<<3 + 0x84) << ((b & 0x70) >> 4)
+	if b&0x80 != 0 {
+		return 0x84 - magnitude
+	}
+	return magnitude - 0x84
+}
+
+// alawToLinear decodes a G.711 A-law sample.
+func alawToLinear(b byte) int16 {
+	b ^= 0x55
+	magnitude := int16(b&0x0F) << 4
+	switch exponent := (b & 0x70) >> 4; exponent {
+	case 0:
+		magnitude += 8
+	case 1:
+		magnitude += 0x108
+	default:
+		magnitude = (magnitude + 0x108) << (exponent - 1)
+	}
+	if b&0x80 != 0 {
+		return magnitude
+	}
+	return -magnitude
+}
@@ -0,0 +1,207 @@
+package vad
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+// ErrUnsupportedEncoding is the error of audio the detector cannot decode or
+// its backend cannot score.
+var ErrUnsupportedEncoding = errors.New("unsupported encoding")
+
+// Backend scores windows of audio.
+type Backend interface {
+	// WindowSize returns how many samples at sampleRate each scored window
+	// has, it fails for sample rates the backend does not support.
+	WindowSize(sampleRate int) (int, error)
+	// SpeechProbability returns how likely window, samples between -1 and 1,
+	// contains speech.
+	SpeechProbability(window []float32, sampleRate int) (float64, error)
+	// Reset forgets the audio scored so far.
+	Reset()
+}
+
+type DetectorOptions struct {
+	backend    Backend
+	threshold  float64
+	minSpeech  time.Duration
+	minSilence time.Duration
+	logger     logging.Logger
+}
+
+type DetectorOption func(*DetectorOptions)
+
+// WithBackend sets the backend scoring the audio, defaults to an energy
+// backend, see [NewEnergyBackend].
+func WithBackend(backend Backend) DetectorOption {
+	return func(o *DetectorOptions) {
+		o.backend = backend
+	}
+}
+
+// WithThreshold sets the speech probability above which a window counts as
+// speech, 0.5 by default.
+func WithThreshold(probability float64) DetectorOption {
+	return func(o *DetectorOptions) {
+		o.threshold = probability
+	}
+}
+
+// WithMinSpeech sets how long speech lasts before it is reported as started,
+// 100ms by default. Shorter durations react faster but report clicks and
+// coughs as speech.
+func WithMinSpeech(d time.Duration) DetectorOption {
+	return func(o *DetectorOptions) {
+		o.minSpeech = d
+	}
+}
+
+// WithMinSilence sets how long silence lasts before speech is reported as
+// ended, 500ms by default.
+func WithMinSilence(d time.Duration) DetectorOption {
+	return func(o *DetectorOptions) {
+		o.minSilence = d
+	}
+}
+
+// WithLogger sets the logger of the detector, defaults to
+// [logging.Default].
+func WithLogger(logger logging.Logger) DetectorOption {
+	return func(o *DetectorOptions) {
+		o.logger = logger
+	}
+}
+
+// Detector reports when speech starts and ends in a stream of audio frames.
+// It is safe for concurrent use, but frames must be processed in order.
+type Detector struct {
+	DetectorOptions
+
+	mu       sync.Mutex
+	encoding audio.EncodingInfo
+	window   int
+	pending  []float32
+	speaking bool
+	// speech and silence are how long the current run of speech or silence
+	// windows lasts.
+	speech  time.Duration
+	silence time.Duration
+	// failing is set once the audio could not be scored, so a stream of
+	// unsupported audio is only logged once.
+	failing bool
+}
+
+// NewDetector creates a detector with the energy backend, unless another is
+// set with [WithBackend].
+func NewDetector(opts ...DetectorOption) *Detector {
+	options := DetectorOptions{
+		threshold:  0.5,
+		minSpeech:  100 * time.Millisecond,
+		minSilence: 500 * time.Millisecond,
+		logger:     logging.Default(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.backend == nil {
+		options.backend = NewEnergyBackend(DefaultEnergyThreshold)
+	}
+	return &Detector{DetectorOptions: options}
+}
+
+// ProcessFrame consumes frame of audio in encoding and returns the speech
+// started and ended events it completes, if any. Audio the detector cannot
+// score is ignored.
+func (d *Detector) ProcessFrame(frame events.UserAudioFrame, encoding audio.EncodingInfo) []events.Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if encoding != d.encoding {
+		d.reset()
+		d.encoding = encoding
+		window, err := d.backend.WindowSize(encoding.SampleRate)
+		if err != nil {
+			d.fail(err)
+			return nil
+		}
+		d.window = window
+	}
+	if d.window <= 0 {
+		return nil
+	}
+
+	samples, err := decode(frame.Audio, encoding)
+	if err != nil {
+		d.fail(err)
+		return nil
+	}
+	d.pending = append(d.pending, samples...)
+
+	var detected []events.Event
+	windowDuration := time.Duration(d.window) * time.Second / time.Duration(encoding.SampleRate)
+	for len(d.pending) >= d.window {
+		probability, err := d.backend.SpeechProbability(d.pending[:d.window], encoding.SampleRate)
+		d.pending = d.pending[d.window:]
+		if err != nil {
+			d.fail(err)
+			continue
+		}
+		d.failing = false
+
+		if probability >= d.threshold {
+			d.speech += windowDuration
+			d.silence = 0
+			if !d.speaking && d.speech >= d.minSpeech {
+				d.speaking = true
+				detected = append(detected, events.NewUserSpeechStarted())
+			}
+		} else {
+			d.silence += windowDuration
+			d.speech = 0
+			if d.speaking && d.silence >= d.minSilence {
+				d.speaking = false
+				detected = append(detected, events.NewUserSpeechEnded())
+			}
+		}
+	}
+	// The scored samples are dropped from the front, copying the rest keeps
+	// the buffer from growing.
+	d.pending = append(d.pending[:0:0], d.pending...)
+	return detected
+}
+
+// IsSpeaking reports whether speech started and has not ended yet.
+func (d *Detector) IsSpeaking() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.speaking
+}
+
+// Reset forgets the audio processed so far, e.g. once the stream of audio
+// was interrupted. Speech in progress is dropped without being reported as
+// ended.
+func (d *Detector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reset()
+}
+
+func (d *Detector) reset() {
+	d.backend.Reset()
+	d.pending = nil
+	d.speaking = false
+	d.speech = 0
+	d.silence = 0
+}
+
+func (d *Detector) fail(err error) {
+	if !d.failing {
+		d.logger.Warn("failed to detect voice activity", "error", err)
+	}
+	d.failing = true
+}
@@ -0,0 +1,155 @@
+package vad
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+// tone returns d of a 16-bit sine tone at 16kHz with amplitude.
+func tone(d time.Duration, amplitude float64) []byte {
+	samples := int(d.Seconds() * 16000)
+	pcm := make([]byte, 0, 2*samples)
+	for i := range samples {
+		sample := int16(amplitude * math.Sin(2*math.Pi*220*float64(i)/16000))
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(sample))
+	}
+	return pcm
+}
+
+func process(d *Detector, pcm []byte) []events.Event {
+	var detected []events.Event
+	// 10ms frames, smaller than the windows of the backends.
+	for frame := range frames(pcm, 320) {
+		detected = append(detected, d.ProcessFrame(events.NewUserAudioFrame(frame), audio.GetDefaultEncodingInfo())...)
+	}
+	return detected
+}
+
+func frames(pcm []byte, size int) func(func([]byte) bool) {
+	return func(yield func([]byte) bool) {
+		for len(pcm) > 0 {
+			n := min(size, len(pcm))
+			if !yield(pcm[:n]) {
+				return
+			}
+			pcm = pcm[n:]
+		}
+	}
+}
+
+func TestEnergyDetectorReportsSpeechStartAndEnd(t *testing.T) {
+	d := NewDetector(WithMinSpeech(60*time.Millisecond), WithMinSilence(200*time.Millisecond), WithLogger(logging.Discard()))
+
+	if detected := process(d, tone(300*time.Millisecond, 100)); len(detected) != 0 {
+		t.Fatalf("expected quiet noise not to be speech, got %+v", detected)
+	}
+	if detected := process(d, tone(40*time.Millisecond, 8000)); len(detected) != 0 {
+		t.Fatalf("expected a short click not to be speech, got %+v", detected)
+	}
+	process(d, tone(100*time.Millisecond, 0))
+
+	detected := process(d, tone(200*time.Millisecond, 8000))
+	if len(detected) != 1 || detected[0].Kind() != events.KindUserSpeechStarted || !d.IsSpeaking() {
+		t.Fatalf("expected speech to start, got %+v", detected)
+	}
+	if detected := process(d, tone(100*time.Millisecond, 0)); len(detected) != 0 {
+		t.Fatalf("expected a short pause not to end speech, got %+v", detected)
+	}
+	process(d, tone(100*time.Millisecond, 8000))
+
+	detected = process(d, tone(300*time.Millisecond, 0))
+	if len(detected) != 1 || detected[0].Kind() != events.KindUserSpeechEnded || d.IsSpeaking() {
+		t.Fatalf("expected speech to end, got %+v", detected)
+	}
+}
+
+// sileroModelStub reports speech for windows with any loud sample, and
+// counts the windows in its state.
+type sileroModelStub struct {
+	windows []int
+	err     error
+}
+
+func (m *sileroModelStub) Infer(window []float32, state []float32, sampleRate int) (float32, []float32, error) {
+	if m.err != nil {
+		return 0, nil, m.err
+	}
+	m.windows = append(m.windows, len(window))
+	next := append([]float32(nil), state...)
+	next[0]++
+	for _, sample := range window {
+		if math.Abs(float64(sample)) > 0.1 {
+			return 0.9, next, nil
+		}
+	}
+	return 0.1, next, nil
+}
+
+func TestSileroBackendScoresWindowsWithState(t *testing.T) {
+	model := &sileroModelStub{}
+	backend := NewSileroBackend(model)
+	d := NewDetector(WithBackend(backend), WithMinSpeech(32*time.Millisecond), WithLogger(logging.Discard()))
+
+	detected := process(d, tone(100*time.Millisecond, 8000))
+	if len(detected) != 1 || detected[0].Kind() != events.KindUserSpeechStarted {
+		t.Fatalf("expected speech to start, got %+v", detected)
+	}
+	if len(model.windows) != 3 || model.windows[0] != 512 {
+		t.Fatalf("expected three windows of 512 samples, got %v", model.windows)
+	}
+	if state := backend.(*sileroBackend).state; state[0] != 3 {
+		t.Fatalf("expected the state to be carried between windows, got %v", state[0])
+	}
+
+	d.Reset()
+	if state := backend.(*sileroBackend).state; state[0] != 0 || d.IsSpeaking() {
+		t.Fatal("expected reset to forget the state and speech")
+	}
+	if _, err := backend.WindowSize(44100); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Fatalf("expected unsupported sample rate error, got %v", err)
+	}
+}
+
+func TestDetectorIgnoresAudioItCannotScore(t *testing.T) {
+	d := NewDetector(WithBackend(NewSileroBackend(&sileroModelStub{err: errors.New("no model")})), WithLogger(logging.Discard()))
+	if detected := process(d, tone(100*time.Millisecond, 8000)); len(detected) != 0 {
+		t.Fatalf("expected no speech, got %+v", detected)
+	}
+
+	frame := events.NewUserAudioFrame(tone(100*time.Millisecond, 8000))
+	if detected := NewDetector().ProcessFrame(frame, audio.EncodingInfo{SampleRate: 16000, Format: "opus"}); len(detected) != 0 {
+		t.Fatalf("expected no speech for an unsupported format, got %+v", detected)
+	}
+}
+
+func TestDecodeCompandedAudio(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding audio.EncodingInfo
+		frame    []byte
+		expected []int16
+	}{
+		{name: "mulaw", encoding: audio.EncodingInfo{SampleRate: 8000, Format: audio.EncodingMulaw}, frame: []byte{0xFF, 0x7F, 0x00, 0x80}, expected: []int16{0, 0, -32124, 32124}},
+		{name: "alaw", encoding: audio.EncodingInfo{SampleRate: 8000, Format: audio.EncodingALaw}, frame: []byte{0xD5, 0x55, 0xAA, 0x2A}, expected: []int16{8, -8, 32256, -32256}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples, err := decode(tt.frame, tt.encoding)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, sample := range samples {
+				if got := int16(sample * 32768); got != tt.expected[i] {
+					t.Fatalf("sample %d: expected %d, got %d", i, tt.expected[i], got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+// Package vad detects speech in user audio locally, independent of the
+// voice activity detection of the speech-to-text provider.
+//
+// A [Detector] consumes user audio frames and reports when speech starts and
+// ends with [events.UserSpeechStarted] and [events.UserSpeechEnded] events.
+// Passed to the orchestrator, it replaces the speech events of the
+// speech-to-text client, so barge-in reacts within a few frames even when
+// the provider is slow to report speech or does not report it at all:
+//
+//	o := orchestration.NewOrchestrator(
+//		orchestration.WithSpeechToTextClient(stt),
+//		orchestration.WithVoiceActivityDetector(vad.NewDetector()),
+//	)
+//
+// The detector scores audio with a [Backend]: an energy threshold by default
+// (see [NewEnergyBackend]), or the Silero VAD model (see [NewSileroBackend]),
+// which tells speech apart from noise far better. The Silero backend runs
+// the model through a [SileroModel], so the package does not depend on an
+// ONNX runtime.
+package vad
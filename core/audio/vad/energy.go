@@ -0,0 +1,48 @@
+package vad
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultEnergyThreshold is the RMS amplitude of 16-bit samples above which
+// the energy backend reports speech by default.
+const DefaultEnergyThreshold = 500
+
+type energyBackend struct {
+	threshold float64
+}
+
+// NewEnergyBackend creates a backend reporting windows whose RMS amplitude,
+// on the scale of 16-bit samples, is above threshold as speech. It needs no
+// model but mistakes loud noise for speech, raise threshold for noisy
+// microphones.
+func NewEnergyBackend(threshold float64) Backend {
+	return &energyBackend{threshold: threshold}
+}
+
+// WindowSize returns 20ms of samples.
+func (b *energyBackend) WindowSize(sampleRate int) (int, error) {
+	if sampleRate < 50 {
+		return 0, fmt.Errorf("%w: sample rate %d", ErrUnsupportedEncoding, sampleRate)
+	}
+	return sampleRate / 50, nil
+}
+
+// SpeechProbability maps the RMS amplitude of window linearly, so a window
+// at the threshold has a probability of 0.5.
+func (b *energyBackend) SpeechProbability(window []float32, _ int) (float64, error) {
+	if len(window) == 0 || b.threshold <= 0 {
+		return 0, nil
+	}
+
+	sum := 0.0
+	for _, sample := range window {
+		scaled := float64(sample) * math.MaxInt16
+		sum += scaled * scaled
+	}
+	rms := math.Sqrt(sum / float64(len(window)))
+	return min(rms/(2*b.threshold), 1), nil
+}
+
+func (b *energyBackend) Reset() {}
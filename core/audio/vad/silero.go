@@ -0,0 +1,63 @@
+package vad
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SileroStateSize is the size of the recurrent state of the Silero VAD
+// model, its 2x1x128 state tensor flattened.
+const SileroStateSize = 2 * 1 * 128
+
+// SileroModel runs the Silero VAD ONNX model (v5), e.g. with an ONNX runtime
+// binding of choice.
+type SileroModel interface {
+	// Infer runs the model on window, 512 samples at 16kHz or 256 at 8kHz,
+	// with the state the previous window returned, zeros for the first. It
+	// returns the speech probability of the window and the next state.
+	Infer(window []float32, state []float32, sampleRate int) (probability float32, next []float32, err error)
+}
+
+type sileroBackend struct {
+	model SileroModel
+
+	mu    sync.Mutex
+	state []float32
+}
+
+// NewSileroBackend creates a backend scoring audio with the Silero VAD
+// model, which supports 8kHz and 16kHz audio.
+func NewSileroBackend(model SileroModel) Backend {
+	return &sileroBackend{model: model, state: make([]float32, SileroStateSize)}
+}
+
+func (b *sileroBackend) WindowSize(sampleRate int) (int, error) {
+	switch sampleRate {
+	case 16000:
+		return 512, nil
+	case 8000:
+		return 256, nil
+	}
+	return 0, fmt.Errorf("%w: silero supports 8kHz and 16kHz, got %dHz", ErrUnsupportedEncoding, sampleRate)
+}
+
+func (b *sileroBackend) SpeechProbability(window []float32, sampleRate int) (float64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	probability, next, err := b.model.Infer(window, b.state, sampleRate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run silero model: %w", err)
+	}
+	if len(next) != SileroStateSize {
+		return 0, fmt.Errorf("silero model returned a state of %d values, expected %d", len(next), SileroStateSize)
+	}
+	b.state = next
+	return float64(probability), nil
+}
+
+func (b *sileroBackend) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = make([]float32, SileroStateSize)
+}
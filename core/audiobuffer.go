@@ -1,19 +1,27 @@
 package orchestration
 
 import (
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
 )
 
 const defaultApproximateUpdateDelay = 120 * time.Millisecond
 
+// markTimeoutGrace is how long marks are waited for past the expected end of
+// the audio before they are considered lost.
+const markTimeoutGrace = 2 * time.Second
+
 type audioBuffer struct {
 	mu sync.Mutex
 
 	encodingInfo audio.EncodingInfo
+	// clock drives the playhead approximation, see [WithClock].
+	clock clock.Clock
 
 	audio                [][]byte
 	allAudioLoaded       bool
@@ -22,6 +30,13 @@ type audioBuffer struct {
 
 	internalPlayhead int
 	externalPlayhead int
+	// released is the number of leading chunks dropped by
+	// [audioBuffer.Release] and releasedLen their length.
+	released    int
+	releasedLen int
+	// segmentStartLen is the length of the audio before the last confirmed
+	// mark, including released audio.
+	segmentStartLen int
 
 	lastMarkTimestamp time.Time
 
@@ -31,6 +46,9 @@ type audioBuffer struct {
 	paused  bool
 
 	updateSignal chan struct{}
+	// onMarksTimedOut is called with the number of marks given up on when
+	// the output stopped confirming them.
+	onMarksTimedOut func(pending int)
 }
 
 type audioBufferMark struct {
@@ -39,11 +57,13 @@ type audioBufferMark struct {
 	terminal    bool
 	broadcasted bool
 	confirmed   bool
+	confirmedAt time.Time
 }
 
 func newAudioBuffer(encodingInfo audio.EncodingInfo) *audioBuffer {
 	return &audioBuffer{
 		encodingInfo: encodingInfo,
+		clock:        clock.Real(),
 		updateSignal: make(chan struct{}, 1),
 	}
 }
@@ -69,7 +89,7 @@ func (b *audioBuffer) Audio(yield func(audio audioOrMark) bool) {
 			}
 
 			firstStart.Do(func() {
-				time.Sleep(50 * time.Millisecond)
+				<-b.clock.After(50 * time.Millisecond)
 				b.StartedPlaying()
 			})
 
@@ -157,7 +177,18 @@ func (b *audioBuffer) waitForNextAudio(yield func(audioOrMark) bool) (ok bool) {
 			return false
 		}
 
-		<-b.updateSignal
+		if timeout, ok := b.markTimeout(); ok {
+			select {
+			case <-b.updateSignal:
+			case <-b.clock.After(timeout):
+				if b.expireMarks() {
+					return false
+				}
+				continue
+			}
+		} else {
+			<-b.updateSignal
+		}
 		// HACK: This is only here because sometimes the mark arrives after the
 		// audio has been fully played and it will make this an infinite
 		// waiting loop
@@ -167,27 +198,82 @@ func (b *audioBuffer) waitForNextAudio(yield func(audioOrMark) bool) (ok bool) {
 	}
 }
 
+// markTimeout returns how long to wait for the marks of audio handed over
+// to the output, if all audio was.
+func (b *audioBuffer) markTimeout() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	expectedEnd, ok := b.expectedEndLocked()
+	if !ok {
+		return 0, false
+	}
+	return max(expectedEnd.Sub(b.clock.Now()), 0), true
+}
+
+// expectedEndLocked is when marks of all audio should have been confirmed,
+// it is only known once all audio was handed over to the output.
+func (b *audioBuffer) expectedEndLocked() (time.Time, bool) {
+	if !b.allAudioLoaded || b.paused || b.stopped || b.lastMarkTimestamp.IsZero() ||
+		b.internalPlayhead < len(b.audio) || b.externalPlayhead >= len(b.audio) {
+		return time.Time{}, false
+	}
+
+	remaining := samplesDuration(audioLen(b.audio[b.externalPlayhead:]), b.encodingInfo)
+	return b.lastMarkTimestamp.Add(remaining + markTimeoutGrace), true
+}
+
+// expireMarks gives up on the marks the output did not confirm by the
+// expected end of the audio and ends playback, it reports whether it did.
+func (b *audioBuffer) expireMarks() bool {
+	b.mu.Lock()
+	expectedEnd, ok := b.expectedEndLocked()
+	if !ok || b.clock.Now().Before(expectedEnd) {
+		b.mu.Unlock()
+		return false
+	}
+
+	pending := 0
+	for i, mark := range b.marks {
+		if !mark.confirmed {
+			b.marks[i].confirmed = true
+			pending++
+		}
+	}
+	b.externalPlayhead = len(b.audio)
+	onMarksTimedOut := b.onMarksTimedOut
+	b.mu.Unlock()
+
+	if onMarksTimedOut != nil {
+		onMarksTimedOut(pending)
+	}
+	return true
+}
+
 func (b *audioBuffer) ApproximateCurrentSegmentProgress() float64 {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	return b.approximateCurrentSegmentProgressLocked(time.Now())
+	return b.approximateCurrentSegmentProgressLocked(b.clock.Now())
 }
 
 func (b *audioBuffer) ApproximateCurrentSegmentProgressAndNextUpdate() (float64, time.Duration) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	return b.approximateCurrentSegmentProgressAndNextUpdateLocked(time.Now())
+	return b.approximateCurrentSegmentProgressAndNextUpdateLocked(b.clock.Now())
 }
 
-func (b *audioBuffer) ApproximateProgressAndPlaybackDelta(lastEmittedPlayhead int) (float64, []byte, int, time.Duration) {
+// ApproximateProgressAndPlaybackDelta returns the current segment progress
+// and the audio played since lastEmittedPlayhead. Deltas spanning several
+// chunks are assembled in a buffer from newFrame, or a new slice if it is nil.
+func (b *audioBuffer) ApproximateProgressAndPlaybackDelta(lastEmittedPlayhead int, newFrame func(size int) []byte) (float64, []byte, int, time.Duration) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	now := time.Now()
+	now := b.clock.Now()
 	progress, nextUpdate := b.approximateCurrentSegmentProgressAndNextUpdateLocked(now)
-	delta, approxPlayhead := b.approximatePlaybackDeltaLocked(lastEmittedPlayhead, now)
+	delta, approxPlayhead := b.approximatePlaybackDeltaLocked(lastEmittedPlayhead, now, newFrame)
 
 	return progress, delta, approxPlayhead, nextUpdate
 }
@@ -196,14 +282,14 @@ func (b *audioBuffer) ApproximatePlaybackDelta(lastEmittedPlayhead int) ([]byte,
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	now := time.Now()
+	now := b.clock.Now()
 	nextUpdate := b.approximateNextPlayheadStepDelayLocked(now)
-	delta, approxPlayhead := b.approximatePlaybackDeltaLocked(lastEmittedPlayhead, now)
+	delta, approxPlayhead := b.approximatePlaybackDeltaLocked(lastEmittedPlayhead, now, nil)
 
 	return delta, approxPlayhead, nextUpdate
 }
 
-func (b *audioBuffer) approximatePlaybackDeltaLocked(lastEmittedPlayhead int, now time.Time) ([]byte, int) {
+func (b *audioBuffer) approximatePlaybackDeltaLocked(lastEmittedPlayhead int, now time.Time, newFrame func(size int) []byte) ([]byte, int) {
 	if lastEmittedPlayhead < 0 {
 		lastEmittedPlayhead = 0
 	}
@@ -228,7 +314,20 @@ func (b *audioBuffer) approximatePlaybackDeltaLocked(lastEmittedPlayhead int, no
 		return nil, approxPlayhead
 	}
 
-	delta := make([]byte, 0, deltaLen)
+	if approxPlayhead-lastEmittedPlayhead == 1 {
+		// Chunks are never modified once added, so a single chunk delta (the
+		// usual case between updates) is shared instead of copied. Capacity
+		// is capped so appending to the delta can't write into the buffer.
+		chunk := b.audio[lastEmittedPlayhead]
+		return chunk[:len(chunk):len(chunk)], approxPlayhead
+	}
+
+	var delta []byte
+	if newFrame != nil {
+		delta = newFrame(deltaLen)[:0]
+	} else {
+		delta = make([]byte, 0, deltaLen)
+	}
 	for _, chunk := range b.audio[lastEmittedPlayhead:approxPlayhead] {
 		delta = append(delta, chunk...)
 	}
@@ -275,8 +374,10 @@ func (b *audioBuffer) ConfirmMark(id string) bool {
 			// "duration", audioDuration(b.audio[b.audioPlayed:mark.position], b.sampleRate),
 			// "actual_duration", time.Since(b.audioPlayingStarted),
 			b.marks[i].confirmed = true
+			b.marks[i].confirmedAt = b.clock.Now()
 			confirmed = true
 			b.externalPlayhead = mark.position
+			b.segmentStartLen = b.releasedLen + audioLen(b.audio[:mark.position])
 			b.startedPlayingLocked()
 			if (b.allAudioLoaded ||
 				// HACK: Following condition is purely for using old tts interface
@@ -298,6 +399,32 @@ func (b *audioBuffer) ConfirmMark(id string) bool {
 	return confirmed
 }
 
+// Release drops the audio of the marks confirmed at least retention ago, up
+// to the chunk upTo, e.g. the end of the audio emitted as playback frames.
+// Released chunks are left empty, keeping the positions of the rest.
+func (b *audioBuffer) Release(upTo int, retention time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cutoff := b.clock.Now().Add(-retention)
+	end, marks := b.released, 0
+	for _, mark := range b.marks {
+		if !mark.confirmed || mark.confirmedAt.After(cutoff) || mark.position > upTo {
+			break
+		}
+		end = max(end, mark.position)
+		marks++
+	}
+	end = min(end, b.externalPlayhead, len(b.audio))
+
+	for i := b.released; i < end; i++ {
+		b.releasedLen += len(b.audio[i])
+		b.audio[i] = nil
+	}
+	b.released = max(b.released, end)
+	b.marks = slices.Delete(b.marks, 0, marks)
+}
+
 func (b *audioBuffer) StartedPlaying() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -307,13 +434,7 @@ func (b *audioBuffer) StartedPlaying() {
 // startedPlayingLocked is a version of [audioBuffer.StartedPlaying] that is safe to call from
 // a locked context.
 func (b *audioBuffer) startedPlayingLocked() {
-	b.lastMarkTimestamp = time.Now()
-	// TODO: It would also be good to trigger a timer in case marks fail and
-	// we have to terminate the loop when we think the audio was supposed to end
-	// this seems to sometimes happen
-	// Account for latency and pausing + all audio needs to be loaded to trigger
-	// this, it needs to be run here because we are using this after the
-	// audio resumes
+	b.lastMarkTimestamp = b.clock.Now()
 }
 
 func (b *audioBuffer) AllAudioLoaded() {
@@ -321,8 +442,6 @@ func (b *audioBuffer) AllAudioLoaded() {
 	b.allAudioLoaded = true
 	b.mu.Unlock()
 	b.signalUpdate()
-	// TODO: Start timer to automatically terminate playing after audio is
-	// supposed to have ended
 }
 
 func (b *audioBuffer) SetUsingLegacyTTSMode() {
@@ -350,7 +469,7 @@ func (b *audioBuffer) rewindLocked() {
 	// it takes for use to receive the information that the audio was played)
 	// TODO: Consider identifying silences in the audio so we can continue from
 	// there and make the unpausing seem smoother (as a human would do)
-	b.externalPlayhead = b.approximatePlayheadLocked(time.Now())
+	b.externalPlayhead = b.approximatePlayheadLocked(b.clock.Now())
 	b.internalPlayhead = b.externalPlayhead
 	for i, mark := range b.marks {
 		if mark.position > b.internalPlayhead {
@@ -359,6 +478,51 @@ func (b *audioBuffer) rewindLocked() {
 	}
 }
 
+// Progress returns the approximate progress of the segment being played, how
+// much of the received audio is left to play and whether playback is paused
+// and all audio was received.
+func (b *audioBuffer) Progress() (segmentProgress float64, remaining time.Duration, paused, allAudioLoaded bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	return b.approximateCurrentSegmentProgressLocked(now), b.remainingLocked(now), b.paused,
+		b.allAudioLoaded || (b.usingWithLegacyTTS && b.legacyAllAudioLoaded)
+}
+
+// PlaybackPosition returns how far into the audio the segment being played
+// starts and how much of the audio was played, estimated like
+// [audioBuffer.Progress]. Both include released audio.
+func (b *audioBuffer) PlaybackPosition() (segmentStart, played time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.playbackPositionLocked(b.clock.Now())
+}
+
+func (b *audioBuffer) playbackPositionLocked(now time.Time) (segmentStart, played time.Duration) {
+	return samplesDuration(b.segmentStartLen, b.encodingInfo),
+		samplesDuration(b.releasedLen+b.playedLenLocked(now), b.encodingInfo)
+}
+
+// remainingLocked estimates how much of the received audio is left to play,
+// interpolating from the last confirmed mark like the playhead.
+func (b *audioBuffer) remainingLocked(now time.Time) time.Duration {
+	return samplesDuration(audioLen(b.audio)-b.playedLenLocked(now), b.encodingInfo)
+}
+
+// playedLenLocked estimates the length of the unreleased audio played,
+// interpolating from the last confirmed mark like the playhead.
+func (b *audioBuffer) playedLenLocked(now time.Time) int {
+	confirmed := min(b.externalPlayhead, len(b.audio))
+	played := audioLen(b.audio[:confirmed])
+	if !b.paused && !b.stopped && !b.lastMarkTimestamp.IsZero() && confirmed < b.internalPlayhead {
+		sent := audioLen(b.audio[confirmed:min(b.internalPlayhead, len(b.audio))])
+		played += min(max(audioSamples(now.Sub(b.lastMarkTimestamp), b.encodingInfo), 0), sent)
+	}
+	return played
+}
+
 // approximatePlayheadLocked estimates the currently played chunk index using
 // external marks as the source of truth and elapsed time since the last
 // confirmation as interpolation.
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
 )
 
 func TestApproximatePlayheadLockedInterpolatesFromExternalPlayhead(t *testing.T) {
@@ -256,3 +257,111 @@ func TestConfirmMarkLegacyModeFinishesForTerminalMark(t *testing.T) {
 		t.Fatalf("expected legacy completion to become true for terminal mark")
 	}
 }
+
+func TestApproximatePlaybackDeltaSharesSingleChunk(t *testing.T) {
+	b := newAudioBuffer(audio.EncodingInfo{SampleRate: 10, Format: audio.EncodingLinear16})
+	chunk := []byte{1, 2, 3, 4}
+	b.AddAudio(chunk)
+	b.AddAudio([]byte{5, 6})
+
+	b.mu.Lock()
+	b.externalPlayhead = 0
+	b.internalPlayhead = 1
+	b.lastMarkTimestamp = time.Now().Add(-2 * time.Second)
+	b.mu.Unlock()
+
+	delta, playhead, _ := b.ApproximatePlaybackDelta(0)
+	if playhead != 1 || !bytes.Equal(delta, chunk) {
+		t.Fatalf("expected single chunk delta %v at playhead 1, got %v at %d", chunk, delta, playhead)
+	}
+	if &delta[0] != &chunk[0] {
+		t.Fatal("expected single chunk delta to share the buffered chunk")
+	}
+
+	_ = append(delta, 9)
+	b.mu.Lock()
+	next := b.audio[1]
+	b.mu.Unlock()
+	if !bytes.Equal(next, []byte{5, 6}) {
+		t.Fatalf("expected appending to the delta to leave buffered audio intact, got %v", next)
+	}
+}
+
+func TestApproximatePlaybackDeltaFollowsClock(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	b := newAudioBuffer(audio.EncodingInfo{SampleRate: 1, Format: audio.EncodingLinear16})
+	b.clock = fakeClock
+	b.AddAudio([]byte{1, 2})
+	b.AddAudio([]byte{3, 4})
+
+	b.mu.Lock()
+	b.internalPlayhead = 2
+	b.lastMarkTimestamp = fakeClock.Now()
+	b.mu.Unlock()
+
+	if delta, _, _ := b.ApproximatePlaybackDelta(0); len(delta) != 0 {
+		t.Fatalf("expected no playback before the clock advanced, got %v", delta)
+	}
+
+	fakeClock.Advance(time.Second)
+	delta, playhead, _ := b.ApproximatePlaybackDelta(0)
+	if !bytes.Equal(delta, []byte{1, 2}) {
+		t.Fatalf("expected first chunk after one second, got %v", delta)
+	}
+
+	fakeClock.Advance(time.Second)
+	if delta, _, _ := b.ApproximatePlaybackDelta(playhead); !bytes.Equal(delta, []byte{3, 4}) {
+		t.Fatalf("expected second chunk after two seconds, got %v", delta)
+	}
+}
+
+func TestWaitForNextAudioGivesUpOnUnconfirmedMarks(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	b := newAudioBuffer(audio.EncodingInfo{SampleRate: 1, Format: audio.EncodingLinear16})
+	b.clock = fakeClock
+	b.AddAudio([]byte{1, 2})
+	b.Mark()
+	b.AddAudio([]byte{3, 4})
+	b.Mark(true)
+	b.AllAudioLoaded()
+
+	timedOut := make(chan int, 1)
+	b.mu.Lock()
+	b.internalPlayhead = 2
+	b.lastMarkTimestamp = fakeClock.Now()
+	b.onMarksTimedOut = func(pending int) { timedOut <- pending }
+	b.mu.Unlock()
+
+	done := make(chan bool, 1)
+	go func() {
+		done <- b.waitForNextAudio(func(audioOrMark) bool { return true })
+	}()
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(2*time.Second + markTimeoutGrace - time.Millisecond)
+	select {
+	case <-done:
+		t.Fatalf("expected to keep waiting for marks before the audio was supposed to end")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(time.Millisecond)
+	select {
+	case ok := <-done:
+		if ok {
+			t.Fatalf("expected the loop to end once marks timed out")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for the mark watchdog")
+	}
+	if pending := <-timedOut; pending != 2 {
+		t.Fatalf("expected 2 pending marks, got %d", pending)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.audioDoneLocked() {
+		t.Fatalf("expected audio to be done after marks timed out")
+	}
+}
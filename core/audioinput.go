@@ -3,12 +3,12 @@ package orchestration
 import (
 	"context"
 	"errors"
-	"log"
 	"reflect"
 	"sync/atomic"
 
 	"github.com/koscakluka/ema-core/core/audio"
 	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/logging"
 )
 
 type audioInput struct {
@@ -27,14 +27,19 @@ type audioInput struct {
 	// shouldCapture reports whether the input client should be capturing audio.
 	shouldCapture atomic.Bool
 
+	// borrowFrames emits captured audio as borrowed frames, see
+	// [WithBorrowedAudioFrames].
+	borrowFrames bool
+
 	emitEvent eventEmitter
+	logger    logging.Logger
 }
 
 // newAudioInput creates an audioInput wrapper around the provided client.
 //
 // Capture defaults to always-on mode.
 func newAudioInput(client audioInputBase) *audioInput {
-	audioInput := audioInput{emitEvent: noopEventEmitter}
+	audioInput := audioInput{emitEvent: noopEventEmitter, logger: logging.Default()}
 	audioInput.alwaysCapture.Store(true)
 	audioInput.Set(client)
 	return &audioInput
@@ -77,7 +82,9 @@ func (a *audioInput) EnableAlwaysCapture(ctx context.Context) error {
 		return nil
 	}
 
-	a.alwaysCapture.Store(true)
+	if !a.alwaysCapture.Swap(true) {
+		a.emit(events.NewAudioAlwaysCaptureChanged(true))
+	}
 	return a.Capture(ctx)
 }
 
@@ -87,10 +94,23 @@ func (a *audioInput) DisableAlwaysCapture(context.Context) error {
 		return nil
 	}
 
-	a.alwaysCapture.Store(false)
+	if a.alwaysCapture.Swap(false) {
+		a.emit(events.NewAudioAlwaysCaptureChanged(false))
+	}
 	return a.StopCapture()
 }
 
+// RestoreCapturePolicy sets capture intent without starting or stopping
+// capture, it is applied the next time capture is started.
+func (a *audioInput) RestoreCapturePolicy(alwaysCapture, shouldCapture bool) {
+	if a == nil {
+		return
+	}
+
+	a.alwaysCapture.Store(alwaysCapture)
+	a.shouldCapture.Store(shouldCapture)
+}
+
 // RequestCapture marks capture as needed for the current phase and starts it.
 func (a *audioInput) RequestCapture(ctx context.Context) error {
 	if a == nil {
@@ -150,11 +170,10 @@ func (a *audioInput) Capture(ctx context.Context) error {
 
 	if a.SupportsCaptureControls() {
 		if a.IsAlwaysRecording() || a.ShouldCapture() {
+			a.emit(events.NewAudioCaptureStarted(a.IsAlwaysRecording()))
 			go func() {
 				if err := a.fineCaptureControle.StartCapture(ctx, a.onAudio); err != nil {
-					a.isCapturing.Store(false)
-					// TODO: Find a way to propagate this error
-					log.Printf("Failed to start audio input: %v", err)
+					a.captureFailed(err)
 				}
 			}()
 			return nil
@@ -165,11 +184,10 @@ func (a *audioInput) Capture(ctx context.Context) error {
 	}
 
 	if a.base != nil {
+		a.emit(events.NewAudioCaptureStarted(a.IsAlwaysRecording()))
 		go func() {
 			if err := a.base.Stream(ctx, a.onAudio); err != nil {
-				a.isCapturing.Store(false)
-				// TODO: Find a way to propagate this error
-				log.Printf("Failed to start audio input: %v", err)
+				a.captureFailed(err)
 			}
 		}()
 		return nil
@@ -194,11 +212,29 @@ func (a *audioInput) Close() error {
 
 		a.base.Close()
 	}
-	a.isCapturing.Store(false)
+	if a.isCapturing.Swap(false) {
+		a.emit(events.NewAudioCaptureStopped())
+	}
 
 	return errs
 }
 
+// captureFailed ends the capture session that failed with err, reporting
+// why, e.g. the microphone permission being denied.
+func (a *audioInput) captureFailed(err error) {
+	a.isCapturing.Store(false)
+	a.logger.Error("failed to start audio input", "error", err)
+
+	reason := events.AudioCaptureFailureUnknown
+	switch {
+	case errors.Is(err, audio.ErrPermissionDenied):
+		reason = events.AudioCaptureFailurePermissionDenied
+	case errors.Is(err, audio.ErrDeviceBusy):
+		reason = events.AudioCaptureFailureDeviceBusy
+	}
+	a.emit(events.NewAudioCaptureFailed(reason, err.Error()))
+}
+
 // StopCapture stops capture only when no active policy requires it.
 //
 // For clients without explicit capture controls, capture lifecycle is managed
@@ -212,13 +248,34 @@ func (a *audioInput) StopCapture() error {
 		if err := a.fineCaptureControle.StopCapture(); err != nil {
 			return err
 		}
-		a.isCapturing.Store(false)
+		if a.isCapturing.Swap(false) {
+			a.emit(events.NewAudioCaptureStopped())
+		}
 		return nil
 	}
 
 	return nil
 }
 
+// Stop ends the capture session without closing the client, so capture can
+// start again. Clients without capture controls keep streaming, their stream
+// is left to deliver audio to the next session.
+func (a *audioInput) Stop() error {
+	if !a.SupportsCaptureControls() {
+		return nil
+	}
+
+	if a.IsCapturing() {
+		if err := a.fineCaptureControle.StopCapture(); err != nil {
+			return err
+		}
+	}
+	if a.isCapturing.Swap(false) {
+		a.emit(events.NewAudioCaptureStopped())
+	}
+	return nil
+}
+
 // EncodingInfo returns input encoding metadata or the package defaults.
 func (a *audioInput) EncodingInfo() audio.EncodingInfo {
 	if a == nil || a.base == nil {
@@ -234,12 +291,19 @@ func (a *audioInput) onAudio(audio []byte) {
 		return
 	}
 
+	if a.borrowFrames {
+		a.emit(events.NewBorrowedUserAudioFrame(audio))
+		return
+	}
+	a.emit(events.NewUserAudioFrame(audio))
+}
+
+func (a *audioInput) emit(event events.Event) {
 	emitEvent := a.emitEvent
 	if emitEvent == nil {
 		emitEvent = noopEventEmitter
 	}
-
-	emitEvent(events.NewUserAudioFrame(audio))
+	emitEvent(event)
 }
 
 func isNilAudioInputBase(client audioInputBase) bool {
@@ -2,10 +2,15 @@ package orchestration
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/koscakluka/ema-core/core/audio"
+	events "github.com/koscakluka/ema-core/core/events"
 )
 
 func TestWithAudioInputConfiguresAudioInputFacade(t *testing.T) {
@@ -117,6 +122,7 @@ type testFineAudioInputClient struct {
 	startCaptureCalls  atomic.Int32
 	stopCaptureCalls   atomic.Int32
 	startCaptureCalled chan struct{}
+	startCaptureErr    error
 }
 
 func (c *testFineAudioInputClient) StartCapture(context.Context, func([]byte)) error {
@@ -127,7 +133,7 @@ func (c *testFineAudioInputClient) StartCapture(context.Context, func([]byte)) e
 		default:
 		}
 	}
-	return nil
+	return c.startCaptureErr
 }
 
 func (c *testFineAudioInputClient) StopCapture() error {
@@ -146,3 +152,72 @@ func (c *testStreamingAudioInputClient) Stream(_ context.Context, onAudio func([
 	onAudio([]byte{0x02})
 	return nil
 }
+
+func TestWithBorrowedAudioFramesEmitsBorrowedInputFrames(t *testing.T) {
+	for _, borrow := range []bool{false, true} {
+		opts := []OrchestratorOption{}
+		if borrow {
+			opts = append(opts, WithBorrowedAudioFrames())
+		}
+		o := NewOrchestrator(opts...)
+
+		var frame events.UserAudioFrame
+		o.audioInput.SetEventEmitter(func(event events.Event) {
+			frame, _ = event.(events.UserAudioFrame)
+		})
+		o.audioInput.onAudio([]byte{1, 2})
+
+		if frame.Borrowed() != borrow {
+			t.Fatalf("expected borrowed %t input frame, got %t", borrow, frame.Borrowed())
+		}
+	}
+}
+
+func TestAudioInputFacadeEmitsCaptureEvents(t *testing.T) {
+	record := func(facade *audioInput) func() []events.Event {
+		var mu sync.Mutex
+		var recorded []events.Event
+		facade.SetEventEmitter(func(event events.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			recorded = append(recorded, event)
+		})
+		return func() []events.Event {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(recorded)
+		}
+	}
+	kinds := func(recorded []events.Event) []events.Kind {
+		kinds := make([]events.Kind, 0, len(recorded))
+		for _, event := range recorded {
+			kinds = append(kinds, event.Kind())
+		}
+		return kinds
+	}
+
+	ctx := context.Background()
+	facade := newTestAudioInput(&testFineAudioInputClient{})
+	recorded := record(facade)
+	if err := facade.Capture(ctx); err != nil {
+		t.Fatalf("unexpected capture error: %v", err)
+	}
+	if err := facade.DisableAlwaysCapture(ctx); err != nil {
+		t.Fatalf("unexpected stop error: %v", err)
+	}
+	want := []events.Kind{events.KindAudioCaptureStarted, events.KindAudioAlwaysCaptureChanged, events.KindAudioCaptureStopped}
+	if got := kinds(recorded()); !slices.Equal(got, want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+
+	failing := newTestAudioInput(&testFineAudioInputClient{startCaptureErr: fmt.Errorf("failed to open microphone: %w", audio.ErrPermissionDenied)})
+	recorded = record(failing)
+	if err := failing.Capture(ctx); err != nil {
+		t.Fatalf("unexpected capture error: %v", err)
+	}
+	waitForCondition(t, time.Second, "capture to fail", func() bool { return len(recorded()) == 2 })
+	failure, ok := recorded()[1].(events.AudioCaptureFailed)
+	if !ok || failure.Reason != events.AudioCaptureFailurePermissionDenied || failing.IsCapturing() {
+		t.Fatalf("expected capture to fail with permission denied, got %+v", recorded())
+	}
+}
@@ -29,6 +29,8 @@ type audioOutput struct {
 
 	// supportsCallbackMarks reports whether marks can invoke callbacks directly.
 	supportsCallbackMarks bool
+	// dryRun drops audio and confirms marks right away, see [WithDryRun].
+	dryRun bool
 }
 
 // newAudioOutput builds a facade and applies Set immediately so typed
@@ -86,19 +88,24 @@ func (a *audioOutput) Snapshot() *audioOutput {
 		return a
 	}
 
-	return newAudioOutput(a.base)
+	snapshot := newAudioOutput(a.base)
+	snapshot.dryRun = a.dryRun
+	return snapshot
 }
 
 // SendAudio forwards a chunk to the configured output client.
 //
 // v1 is preferred when available; otherwise v0 is used. If no usable client is
 // configured, the chunk is dropped.
-func (a *audioOutput) SendAudio(audio []byte) {
-	if a.v1 != nil {
-		a.v1.SendAudio(audio)
+func (a *audioOutput) SendAudio(audio []byte) error {
+	if a.dryRun {
+		return nil
+	} else if a.v1 != nil {
+		return a.v1.SendAudio(audio)
 	} else if a.v0 != nil {
-		a.v0.SendAudio(audio)
+		return a.v0.SendAudio(audio)
 	}
+	return nil
 }
 
 // Mark coordinates transcript marks with output playback.
@@ -108,9 +115,11 @@ func (a *audioOutput) SendAudio(audio []byte) {
 // callback-driven.
 // Without output configured, the callback is invoked immediately so turn state
 // can continue progressing.
-func (a *audioOutput) Mark(mark string, callback func(string)) {
-	if a.v1 != nil {
-		a.v1.Mark(mark, callback)
+func (a *audioOutput) Mark(mark string, callback func(string)) error {
+	if a.dryRun {
+		callback(mark)
+	} else if a.v1 != nil {
+		return a.v1.Mark(mark, callback)
 	} else if a.v0 != nil {
 		// Legacy outputs expose mark confirmation as a blocking wait. Run the
 		// wait in a goroutine so mark handling does not block the caller.
@@ -121,13 +130,16 @@ func (a *audioOutput) Mark(mark string, callback func(string)) {
 	} else {
 		callback(mark)
 	}
+	return nil
 }
 
 // Clear flushes buffered output on the configured client.
 //
 // If no supported client is configured, this is a no-op.
 func (a *audioOutput) Clear() {
-	if a.v1 != nil {
+	if a.dryRun {
+		return
+	} else if a.v1 != nil {
 		a.v1.ClearBuffer()
 	} else if a.v0 != nil {
 		a.v0.ClearBuffer()
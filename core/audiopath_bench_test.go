@@ -0,0 +1,55 @@
+package orchestration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// 20ms of 16kHz linear16 audio, the frame size most transports use
+const benchmarkFrameSize = 640
+
+func BenchmarkAudioInputFrame(b *testing.B) {
+	var received int
+	input := newAudioInput(nil)
+	input.SetEventEmitter(newCallbackEventEmitter(OrchestrateOptions{
+		onEvent:      func(events.Event) {},
+		onInputAudio: func(audio []byte) { received += len(audio) },
+	}))
+	frame := make([]byte, benchmarkFrameSize)
+
+	b.ReportAllocs()
+	b.SetBytes(benchmarkFrameSize)
+	for b.Loop() {
+		input.onAudio(frame)
+	}
+}
+
+func BenchmarkAudioBufferPlaybackDelta(b *testing.B) {
+	for _, bench := range []struct {
+		name   string
+		chunks int
+	}{
+		{name: "single_chunk", chunks: 1},
+		{name: "multiple_chunks", chunks: 5},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			buffer := newAudioBuffer(audio.GetDefaultEncodingInfo())
+			for range bench.chunks {
+				buffer.AddAudio(make([]byte, benchmarkFrameSize))
+			}
+			buffer.mu.Lock()
+			buffer.internalPlayhead = bench.chunks
+			buffer.lastMarkTimestamp = time.Now().Add(-time.Hour)
+			buffer.mu.Unlock()
+
+			b.ReportAllocs()
+			b.SetBytes(int64(bench.chunks * benchmarkFrameSize))
+			for b.Loop() {
+				_, _, _ = buffer.ApproximatePlaybackDelta(0)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+// BackchannelV0 is a short acknowledgement, e.g. "mm-hmm", with its speech
+// audio in the encoding of the audio output.
+type BackchannelV0 struct {
+	Phrase string
+	Audio  []byte
+}
+
+// RenderBackchannels generates the speech of phrases with client once, so
+// they can be played from cache with [WithBackchannels].
+func RenderBackchannels(ctx context.Context, client TextToSpeechV1, encoding audio.EncodingInfo, phrases ...string) ([]BackchannelV0, error) {
+	backchannels := make([]BackchannelV0, 0, len(phrases))
+	for _, phrase := range phrases {
+		speech, err := renderSpeech(ctx, client, encoding, phrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render backchannel %q: %w", phrase, err)
+		}
+		backchannels = append(backchannels, BackchannelV0{Phrase: phrase, Audio: speech})
+	}
+	return backchannels, nil
+}
+
+func renderSpeech(ctx context.Context, client TextToSpeechV1, encoding audio.EncodingInfo, text string) ([]byte, error) {
+	var mu sync.Mutex
+	var speech []byte
+	done := make(chan struct{})
+	failed := make(chan error, 1)
+	var doneOnce sync.Once
+
+	generator, err := client.NewSpeechGeneratorV0(ctx,
+		texttospeech.WithEncodingInfo(encoding),
+		texttospeech.WithSpeechAudioCallback(func(audio []byte) {
+			mu.Lock()
+			speech = append(speech, audio...)
+			mu.Unlock()
+		}),
+		texttospeech.WithSpeechMarkCallback(func(string) { doneOnce.Do(func() { close(done) }) }),
+		texttospeech.WithErrorCallback(func(err error) {
+			select {
+			case failed <- err:
+			default:
+			}
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer generator.Close()
+
+	if err := generator.SendText(text); err != nil {
+		return nil, err
+	} else if err := generator.Mark(); err != nil {
+		return nil, err
+	} else if err := generator.EndOfText(); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-done:
+	case err := <-failed:
+		return nil, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return speech, nil
+}
+
+type BackchannelOptions struct {
+	afterSpeech   time.Duration
+	afterToolWait time.Duration
+	interval      time.Duration
+}
+
+type BackchannelOption func(*BackchannelOptions)
+
+// WithBackchannelAfterSpeech sets how long the user speaks before a
+// backchannel is played, 4s by default.
+func WithBackchannelAfterSpeech(d time.Duration) BackchannelOption {
+	return func(o *BackchannelOptions) {
+		o.afterSpeech = d
+	}
+}
+
+// WithBackchannelAfterToolWait sets how long a tool call runs before a
+// backchannel is played, 2s by default.
+func WithBackchannelAfterToolWait(d time.Duration) BackchannelOption {
+	return func(o *BackchannelOptions) {
+		o.afterToolWait = d
+	}
+}
+
+// WithBackchannelInterval sets the least time between two backchannels, 4s
+// by default.
+func WithBackchannelInterval(d time.Duration) BackchannelOption {
+	return func(o *BackchannelOptions) {
+		o.interval = d
+	}
+}
+
+// WithBackchannels plays the cached backchannels, in turns, to the audio
+// output during long user utterances and slow tool calls, e.g. from
+// [RenderBackchannels].
+//
+// Backchannels are only played once the user has been speaking for a while,
+// so they never overlap the onset of user speech, and not while the
+// assistant speaks or during tool calls the user talks through. They are not
+// part of any turn and never enter the conversation history, each one is
+// reported with a [events.BackchannelPlayed] event.
+func WithBackchannels(backchannels []BackchannelV0, opts ...BackchannelOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if len(backchannels) == 0 {
+			o.backchannels = nil
+			return
+		}
+
+		options := BackchannelOptions{
+			afterSpeech:   4 * time.Second,
+			afterToolWait: 2 * time.Second,
+			interval:      4 * time.Second,
+		}
+		for _, opt := range opts {
+			opt(&options)
+		}
+		o.backchannels = &backchanneler{BackchannelOptions: options, backchannels: backchannels}
+	}
+}
+
+// backchanneler plays backchannels based on the events of the conversation.
+type backchanneler struct {
+	BackchannelOptions
+	backchannels []BackchannelV0
+
+	ctx       context.Context
+	clock     clock.Clock
+	output    *audioOutput
+	emitEvent eventEmitter
+
+	mu sync.Mutex
+	// speechGeneration and toolGeneration change whenever the user speech or
+	// the tool wait a scheduled backchannel was waiting on ends.
+	speechGeneration int
+	toolGeneration   int
+	userSpeaking     bool
+	assistantSpeaks  bool
+	pendingTools     int
+	lastPlayedAt     time.Time
+	next             int
+}
+
+// start prepares b to play backchannels to output until ctx is done.
+func (b *backchanneler) start(ctx context.Context, clock clock.Clock, output *audioOutput, emitEvent eventEmitter) {
+	b.ctx = ctx
+	b.clock = clock
+	b.output = output
+	b.emitEvent = emitEvent
+}
+
+// observe wraps emitEvent so b follows the events of the conversation.
+func (b *backchanneler) observe(emitEvent eventEmitter) eventEmitter {
+	return func(event events.Event) {
+		emitEvent(event)
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		switch event.(type) {
+		case events.UserSpeechStarted:
+			b.userSpeaking = true
+			b.speechGeneration++
+			b.toolGeneration++
+			b.schedule(b.afterSpeech, events.BackchannelReasonUserSpeech, b.speechGeneration)
+		case events.UserSpeechEnded:
+			b.userSpeaking = false
+			b.speechGeneration++
+			if b.pendingTools > 0 {
+				b.toolGeneration++
+				b.schedule(b.afterToolWait, events.BackchannelReasonToolWait, b.toolGeneration)
+			}
+		case events.ToolCallStarted:
+			b.pendingTools++
+			if b.pendingTools == 1 {
+				b.toolGeneration++
+				b.schedule(b.afterToolWait, events.BackchannelReasonToolWait, b.toolGeneration)
+			}
+		case events.ToolCallCompleted, events.ToolCallFailed:
+			if b.pendingTools > 0 {
+				b.pendingTools--
+			}
+			if b.pendingTools == 0 {
+				b.toolGeneration++
+			}
+		case events.AssistantPlaybackStarted:
+			b.assistantSpeaks = true
+		case events.AssistantPlaybackEnded:
+			b.assistantSpeaks = false
+		case events.TurnCompleted, events.TurnCancelled, events.TurnFailed:
+			b.assistantSpeaks = false
+			b.pendingTools = 0
+			b.toolGeneration++
+		}
+	}
+}
+
+// schedule plays a backchannel for reason after d, unless the generation of
+// reason changes in the meantime. b.mu must be held.
+func (b *backchanneler) schedule(d time.Duration, reason string, generation int) {
+	if b.ctx == nil {
+		return
+	}
+
+	go func() {
+		select {
+		case <-b.clock.After(d):
+			b.play(reason, generation)
+		case <-b.ctx.Done():
+		}
+	}()
+}
+
+func (b *backchanneler) play(reason string, generation int) {
+	b.mu.Lock()
+	switch reason {
+	case events.BackchannelReasonUserSpeech:
+		if generation != b.speechGeneration || !b.userSpeaking {
+			b.mu.Unlock()
+			return
+		}
+	case events.BackchannelReasonToolWait:
+		if generation != b.toolGeneration || b.pendingTools == 0 || b.userSpeaking {
+			b.mu.Unlock()
+			return
+		}
+	}
+
+	now := b.clock.Now()
+	if wait := b.interval - now.Sub(b.lastPlayedAt); !b.lastPlayedAt.IsZero() && wait > 0 {
+		b.schedule(wait, reason, generation)
+		b.mu.Unlock()
+		return
+	}
+	// Keep acknowledging for as long as the user speaks or the tool runs.
+	b.schedule(b.interval, reason, generation)
+	if b.assistantSpeaks {
+		b.mu.Unlock()
+		return
+	}
+
+	backchannel := b.backchannels[b.next%len(b.backchannels)]
+	b.next++
+	b.lastPlayedAt = now
+	b.mu.Unlock()
+
+	if err := b.output.SendAudio(backchannel.Audio); err != nil {
+		return
+	}
+	b.emitEvent(events.NewBackchannelPlayed(backchannel.Phrase, reason))
+}
@@ -0,0 +1,73 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestBackchannelsDuringLongSpeechAndToolWaits(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	output := &bridgeAudioOutputStub{}
+	var mu sync.Mutex
+	var played []events.BackchannelPlayed
+	o := NewOrchestrator(WithBackchannels([]BackchannelV0{
+		{Phrase: "mm-hmm", Audio: []byte{1}},
+		{Phrase: "got it", Audio: []byte{2}},
+	}))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	backchannels := o.backchannels
+	backchannels.start(ctx, fakeClock, newAudioOutput(output), func(event events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		played = append(played, event.(events.BackchannelPlayed))
+	})
+	emit := backchannels.observe(noopEventEmitter)
+	playedCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(played)
+	}
+
+	// A short utterance is not acknowledged.
+	emit(events.NewUserSpeechStarted())
+	fakeClock.BlockUntil(1)
+	emit(events.NewUserSpeechEnded())
+	fakeClock.Advance(4 * time.Second)
+
+	emit(events.NewUserSpeechStarted())
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(4 * time.Second)
+	waitForCondition(t, time.Second, "backchannel during speech", func() bool { return playedCount() == 1 })
+	fakeClock.BlockUntil(1)
+	emit(events.NewUserSpeechEnded())
+	fakeClock.Advance(4 * time.Second)
+
+	emit(events.NewToolCallStarted("call", "lookup", "{}"))
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(2 * time.Second)
+	waitForCondition(t, time.Second, "backchannel during tool wait", func() bool { return playedCount() == 2 })
+	emit(events.NewToolCallCompleted("call", "lookup", "done"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if played[0].Phrase != "mm-hmm" || played[0].Reason != events.BackchannelReasonUserSpeech ||
+		played[1].Phrase != "got it" || played[1].Reason != events.BackchannelReasonToolWait {
+		t.Fatalf("unexpected backchannels %+v", played)
+	}
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	if len(output.audio) != 2 || output.audio[0][0] != 1 || output.audio[1][0] != 2 {
+		t.Fatalf("expected the cached audio to be played, got %v", output.audio)
+	}
+	if len(o.conversation.History()) != 0 {
+		t.Fatalf("expected backchannels to stay out of the history")
+	}
+}
@@ -0,0 +1,133 @@
+package orchestration
+
+import (
+	"errors"
+	"sync"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// ErrBudgetExceeded is the error of turns refused because the conversation
+// exceeded its budget, see [WithBudget].
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+type BudgetOptions struct {
+	pricing  func(usage llms.Usage) float64
+	fallback LLM
+}
+
+type BudgetOption func(*BudgetOptions)
+
+// WithBudgetPricing sets how much LLM usage costs, without it the cost limit
+// of the budget is never reached.
+func WithBudgetPricing(pricing func(usage llms.Usage) float64) BudgetOption {
+	return func(o *BudgetOptions) {
+		o.pricing = pricing
+	}
+}
+
+// WithBudgetFallback makes turns after the budget is exceeded use fallback,
+// e.g. a cheaper model, instead of being refused.
+func WithBudgetFallback(fallback LLM) BudgetOption {
+	return func(o *BudgetOptions) {
+		o.fallback = fallback
+	}
+}
+
+// WithBudget limits the LLM tokens, the cost and the number of turns of the
+// conversation, a zero limit is not enforced. Tokens and cost are counted
+// from the usage streaming LLMs report.
+//
+// The budget is checked before each turn: once a limit is reached a
+// [events.BudgetExceeded] event is emitted and following turns fail with
+// [ErrBudgetExceeded], or use the [WithBudgetFallback] LLM. A turn in
+// progress is not stopped, so usage can overshoot the limits by one turn.
+func WithBudget(maxTokens int, maxCost float64, maxTurns int, opts ...BudgetOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		budget := &budget{maxTokens: maxTokens, maxCost: maxCost, maxTurns: maxTurns}
+		for _, opt := range opts {
+			opt(&budget.BudgetOptions)
+		}
+		o.llm.budget = budget
+	}
+}
+
+type budget struct {
+	BudgetOptions
+	maxTokens int
+	maxCost   float64
+	maxTurns  int
+
+	mu       sync.Mutex
+	tokens   int
+	cost     float64
+	turns    int
+	exceeded bool
+}
+
+// recordUsage adds the usage of an LLM call to the budget.
+func (b *budget) recordUsage(usage llms.Usage) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += usage.TotalTokens
+	if b.pricing != nil {
+		b.cost += b.pricing(usage)
+	}
+}
+
+// admitTurn counts a new turn against the budget. It returns whether the
+// budget is exceeded and, the first time it is, the event to emit.
+func (b *budget) admitTurn() (bool, events.Event) {
+	if b == nil {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	limit := b.reachedLimit()
+	if limit == "" {
+		b.turns++
+		return false, nil
+	}
+	if b.exceeded {
+		return true, nil
+	}
+	b.exceeded = true
+	return true, events.NewBudgetExceeded(limit, b.tokens, b.cost, b.turns, b.fallback != nil)
+}
+
+func (b *budget) reachedLimit() events.BudgetLimit {
+	switch {
+	case b.maxTokens > 0 && b.tokens >= b.maxTokens:
+		return events.BudgetLimitTokens
+	case b.maxCost > 0 && b.cost >= b.maxCost:
+		return events.BudgetLimitCost
+	case b.maxTurns > 0 && b.turns >= b.maxTurns:
+		return events.BudgetLimitTurns
+	default:
+		return ""
+	}
+}
+
+// admitTurn checks the budget before a turn and switches to the fallback LLM
+// once it is exceeded, it fails with [ErrBudgetExceeded] if the turn is
+// refused.
+func (runtime *llm) admitTurn() error {
+	exceeded, event := runtime.budget.admitTurn()
+	if event != nil {
+		runtime.emitEvent(event)
+	}
+	if !exceeded {
+		return nil
+	}
+	if runtime.budget.fallback == nil {
+		return ErrBudgetExceeded
+	}
+	runtime.client = runtime.budget.fallback
+	return nil
+}
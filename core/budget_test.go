@@ -0,0 +1,146 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestBudgetAdmitTurn(t *testing.T) {
+	b := &budget{maxTokens: 100, maxCost: 1}
+	b.pricing = func(usage llms.Usage) float64 { return float64(usage.TotalTokens) / 100 }
+
+	if exceeded, event := b.admitTurn(); exceeded || event != nil {
+		t.Fatalf("expected turn to be admitted")
+	}
+	b.recordUsage(llms.Usage{TotalTokens: 120})
+
+	exceeded, event := b.admitTurn()
+	if !exceeded {
+		t.Fatalf("expected budget to be exceeded")
+	}
+	budgetExceeded, ok := event.(events.BudgetExceeded)
+	if !ok || budgetExceeded.Limit != events.BudgetLimitTokens || budgetExceeded.Tokens != 120 || budgetExceeded.Cost != 1.2 {
+		t.Fatalf("unexpected budget exceeded event %+v", event)
+	}
+
+	if exceeded, event := b.admitTurn(); !exceeded || event != nil {
+		t.Fatalf("expected budget to stay exceeded without another event")
+	}
+}
+
+func runBudgetedTurns(t *testing.T, prompts int, opts ...OrchestratorOption) ([]events.Event, []error) {
+	t.Helper()
+	o := NewOrchestrator(opts...)
+	defer o.Close()
+
+	var mu sync.Mutex
+	var budgetEvents []events.Event
+	var turnErrors []error
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch event := event.(type) {
+		case events.BudgetExceeded:
+			budgetEvents = append(budgetEvents, event)
+		case events.TurnCompleted:
+			turnErrors = append(turnErrors, nil)
+		case events.TurnFailed:
+			turnErrors = append(turnErrors, errors.New(event.Error))
+		}
+	}))
+
+	for i := range prompts {
+		o.SendPrompt("prompt")
+		waitForCondition(t, 2*time.Second, "turn finished", func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(turnErrors) == i+1
+		})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return budgetEvents, turnErrors
+}
+
+func TestWithBudgetRefusesTurns(t *testing.T) {
+	budgetEvents, turnErrors := runBudgetedTurns(t, 3,
+		WithLLM(promptLLMStub{response: "response"}),
+		WithBudget(0, 0, 1),
+	)
+
+	if turnErrors[0] != nil {
+		t.Fatalf("expected first turn to complete, got %v", turnErrors[0])
+	}
+	for _, err := range turnErrors[1:] {
+		if err == nil || !strings.Contains(err.Error(), ErrBudgetExceeded.Error()) {
+			t.Fatalf("expected turn to be refused, got %v", err)
+		}
+	}
+	if len(budgetEvents) != 1 {
+		t.Fatalf("expected a single budget exceeded event, got %d", len(budgetEvents))
+	}
+}
+
+func TestWithBudgetSwitchesToFallback(t *testing.T) {
+	o := NewOrchestrator(
+		WithLLM(promptLLMStub{response: "primary"}),
+		WithBudget(0, 0, 1, WithBudgetFallback(promptLLMStub{response: "fallback"})),
+	)
+	defer o.Close()
+
+	var completed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Add(1)
+		}
+	}))
+
+	o.SendPrompt("first")
+	o.SendPrompt("second")
+	waitForCondition(t, 2*time.Second, "turns completed", func() bool { return completed.Load() == 2 })
+
+	history := o.conversation.History()
+	if len(history) != 2 || history[1].Responses[0].Message != "fallback" {
+		t.Fatalf("expected second turn to use the fallback llm, got %+v", history)
+	}
+}
+
+func TestWithBudgetFallbackReplacesLLMsOfTriggers(t *testing.T) {
+	o := NewOrchestrator(
+		WithLLM(promptLLMStub{response: "primary"}),
+		WithBudget(0, 0, 1, WithBudgetFallback(promptLLMStub{response: "fallback"})),
+	)
+	defer o.Close()
+
+	var completed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Add(1)
+		}
+	}))
+
+	o.SendPrompt("first")
+	o.HandleTrigger(llmTrigger{UserPromptTrigger: triggers.NewUserPromptTrigger("second"), llm: promptLLMStub{response: "trigger"}})
+	waitForCondition(t, 2*time.Second, "turns completed", func() bool { return completed.Load() == 2 })
+
+	history := o.conversation.History()
+	if len(history) != 2 || history[1].Responses[0].Message != "fallback" {
+		t.Fatalf("expected the turn of the trigger to use the fallback llm, got %+v", history)
+	}
+}
@@ -0,0 +1,101 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrMissingClient is the error of starting a conversation without a client
+// the conversation requires.
+var ErrMissingClient = errors.New("missing client")
+
+// OrchestratorBuilder assembles an orchestrator from its components and
+// starts its conversation, as an alternative to passing
+// [OrchestratorOption]s to [NewOrchestrator] and [OrchestrateOption]s to
+// [Orchestrator.Orchestrate].
+//
+//	conversation, err := orchestration.NewOrchestratorBuilder().
+//		WithLLM(llm).
+//		WithSTT(stt).
+//		WithTTS(tts).
+//		WithAudioIO(input, output).
+//		StartConversation(ctx, orchestration.WithEventCallback(onEvent))
+//
+// A builder starts a single conversation.
+type OrchestratorBuilder struct {
+	opts    []OrchestratorOption
+	started atomic.Bool
+}
+
+// NewOrchestratorBuilder creates a builder with opts, for the options
+// without a method of their own, e.g. [WithTools].
+func NewOrchestratorBuilder(opts ...OrchestratorOption) *OrchestratorBuilder {
+	return &OrchestratorBuilder{opts: opts}
+}
+
+// WithLLM sets the LLM responding in the conversation, it is required.
+func (b *OrchestratorBuilder) WithLLM(client LLMWithStream) *OrchestratorBuilder {
+	return b.With(WithStreamingLLM(client))
+}
+
+// WithSTT sets the speech to text transcribing the audio input.
+func (b *OrchestratorBuilder) WithSTT(client SpeechToText) *OrchestratorBuilder {
+	return b.With(WithSpeechToTextClient(client))
+}
+
+// WithTTS sets the text to speech speaking the responses, it requires an
+// audio output, see [OrchestratorBuilder.WithAudioIO].
+func (b *OrchestratorBuilder) WithTTS(client TextToSpeechV1) *OrchestratorBuilder {
+	return b.With(WithTextToSpeechClientV1(client))
+}
+
+// WithAudioIO sets the audio input and output, either can be nil to leave it
+// out, e.g. for text input with spoken responses.
+func (b *OrchestratorBuilder) WithAudioIO(input AudioInput, output AudioOutputV1) *OrchestratorBuilder {
+	if input != nil {
+		b.With(WithAudioInput(input))
+	}
+	if output != nil {
+		b.With(WithAudioOutputV1(output))
+	}
+	return b
+}
+
+// With adds opts to the orchestrator, e.g. [WithTools].
+func (b *OrchestratorBuilder) With(opts ...OrchestratorOption) *OrchestratorBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// StartConversation creates the orchestrator and starts its conversation
+// with callbacks, like [Orchestrator.Orchestrate]. It fails if a conversation
+// was already started by the builder, the LLM is missing or the options are
+// invalid, see [NewOrchestratorE].
+func (b *OrchestratorBuilder) StartConversation(ctx context.Context, callbacks ...OrchestrateOption) (*Conversation, error) {
+	if !b.started.CompareAndSwap(false, true) {
+		return nil, ErrConversationStarted
+	}
+
+	o, err := NewOrchestratorE(b.opts...)
+	if err != nil {
+		return nil, err
+	}
+	if o.llm.client == nil {
+		o.Close()
+		return nil, fmt.Errorf("%s: %w", ComponentLLM, ErrMissingClient)
+	}
+	if err := o.Orchestrate(ctx, callbacks...); err != nil {
+		return nil, fmt.Errorf("failed to start conversation: %w", err)
+	}
+	return &Conversation{o}, nil
+}
+
+// Conversation is a conversation started by
+// [OrchestratorBuilder.StartConversation], driven through the methods of its
+// orchestrator, e.g. [Orchestrator.SendPrompt], and ended with
+// [Orchestrator.EndConversation] or [Orchestrator.Close].
+type Conversation struct {
+	*Orchestrator
+}
@@ -0,0 +1,44 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrchestratorBuilderStartsASingleConversation(t *testing.T) {
+	builder := NewOrchestratorBuilder().WithLLM(repeatingStreamLLMStub{chunk: "hi", interval: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conversation, err := builder.StartConversation(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conversation.Close()
+
+	if _, err := builder.StartConversation(ctx); !errors.Is(err, ErrConversationStarted) {
+		t.Fatalf("expected ErrConversationStarted from the builder, got %v", err)
+	}
+	if err := conversation.Orchestrate(ctx); !errors.Is(err, ErrConversationStarted) {
+		t.Fatalf("expected ErrConversationStarted from the orchestrator, got %v", err)
+	}
+}
+
+func TestOrchestratorBuilderRequiresAnLLM(t *testing.T) {
+	_, err := NewOrchestratorBuilder().StartConversation(context.Background())
+	if !errors.Is(err, ErrMissingClient) {
+		t.Fatalf("expected ErrMissingClient, got %v", err)
+	}
+}
+
+func TestOrchestratorBuilderValidatesOptions(t *testing.T) {
+	_, err := NewOrchestratorBuilder().
+		WithLLM(repeatingStreamLLMStub{chunk: "hi", interval: time.Hour}).
+		WithSTT(nil).
+		StartConversation(context.Background())
+	if !errors.Is(err, ErrNilClient) {
+		t.Fatalf("expected ErrNilClient, got %v", err)
+	}
+}
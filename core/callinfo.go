@@ -0,0 +1,72 @@
+package orchestration
+
+import "maps"
+
+// CallInfoV0 describes the telephone call carrying a conversation, as known
+// to the transport adapter answering it, e.g. to greet callers by their
+// region or to route on the dialed number.
+type CallInfoV0 struct {
+	// CallerID is the number or SIP URI of the caller.
+	CallerID string `json:"caller_id,omitempty"`
+	// CalledNumber is the number or SIP URI the caller dialed.
+	CalledNumber string `json:"called_number,omitempty"`
+	// SIPHeaders are the headers of the SIP INVITE the adapter passes on,
+	// e.g. X- headers set by the carrier.
+	SIPHeaders map[string]string `json:"sip_headers,omitempty"`
+	// Channel names the transport of the call, e.g. "pstn", "sip" or
+	// "webrtc".
+	Channel string `json:"channel,omitempty"`
+}
+
+func (info CallInfoV0) clone() CallInfoV0 {
+	info.SIPHeaders = maps.Clone(info.SIPHeaders)
+	return info
+}
+
+// WithCallInfoV0 sets the call information of the conversation, for
+// transport adapters that know it before the orchestrator is created. See
+// [Orchestrator.SetCallInfo].
+func WithCallInfoV0(info CallInfoV0) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.conversation.setCallInfo(&info)
+	}
+}
+
+// SetCallInfo sets the call information of the conversation, replacing
+// earlier information, e.g. once the SIP INVITE of the call was answered.
+//
+// Tools read it with [Orchestrator.CallInfo], system prompt providers
+// with [ConversationV1.CallInfo]. It is kept in the session state.
+func (o *Orchestrator) SetCallInfo(info CallInfoV0) {
+	o.conversation.setCallInfo(&info)
+}
+
+// CallInfo returns the call information of the conversation, if any.
+func (o *Orchestrator) CallInfo() (CallInfoV0, bool) {
+	info := o.conversation.callInfoSnapshot()
+	if info == nil {
+		return CallInfoV0{}, false
+	}
+	return *info, true
+}
+
+func (t *activeConversation) setCallInfo(info *CallInfoV0) {
+	if info != nil {
+		cloned := info.clone()
+		info = &cloned
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.callInfo = info
+}
+
+func (t *activeConversation) callInfoSnapshot() *CallInfoV0 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.callInfo == nil {
+		return nil
+	}
+	info := t.callInfo.clone()
+	return &info
+}
@@ -0,0 +1,64 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+func TestCallInfoReachesSystemPromptsAndSessionState(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	var completed atomic.Int32
+	o := NewOrchestrator(WithLLM(llm), WithSystemPromptProvider(func(conversation ConversationV1, _ llms.TriggerV0) string {
+		if conversation.CallInfo == nil {
+			return "No call."
+		}
+		return "Caller dialed " + conversation.CallInfo.CalledNumber + "."
+	}))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Add(1)
+		}
+	}))
+
+	headers := map[string]string{"X-Region": "eu"}
+	o.SetCallInfo(CallInfoV0{CallerID: "+385911234567", CalledNumber: "+38516000000", SIPHeaders: headers, Channel: "pstn"})
+	headers["X-Region"] = "changed by the adapter"
+	o.SendPrompt("hello")
+	waitForCondition(t, 2*time.Second, "turn completed", func() bool { return completed.Load() == 1 })
+
+	llm.mu.Lock()
+	instructions := append([]string(nil), llm.instructions...)
+	llm.mu.Unlock()
+	if len(instructions) != 1 || instructions[0] != "Caller dialed +38516000000." {
+		t.Fatalf("expected the system prompt to see the dialed number, got %v", instructions)
+	}
+
+	state, err := o.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected drain error: %v", err)
+	}
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	var decoded SessionStateV0
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+
+	resumed := NewOrchestrator(WithSessionStateV0(decoded))
+	defer resumed.Close()
+	info, ok := resumed.CallInfo()
+	if !ok || info.CallerID != "+385911234567" || info.Channel != "pstn" || info.SIPHeaders["X-Region"] != "eu" {
+		t.Fatalf("expected the call info to be restored, got %+v, %v", info, ok)
+	}
+}
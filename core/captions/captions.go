@@ -0,0 +1,192 @@
+// Package captions generates SRT and WebVTT captions of the assistant speech
+// from orchestration events, e.g. for accessibility overlays or rendering
+// calls to video.
+//
+//	recorder := captions.NewRecorder()
+//	orchestrator.Orchestrate(ctx, orchestration.WithEventCallback(recorder.Observe))
+//	...
+//	vtt := captions.WebVTT(recorder.Cues())
+//
+// Cues are timed by the playback marks confirmed by the audio output, one
+// segment of speech, usually a sentence, per mark.
+package captions
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// Cue is a caption shown from Start to End.
+type Cue struct {
+	TurnID string
+	Start  time.Duration
+	End    time.Duration
+	Text   string
+}
+
+type RecorderOptions struct {
+	maxCueLength int
+}
+
+type RecorderOption func(*RecorderOptions)
+
+// WithMaxCueLength sets how many characters a cue holds, 84 by default, i.e.
+// two lines of 42. Longer segments are split into several cues, timed by
+// their share of the characters. Zero keeps segments whole.
+func WithMaxCueLength(n int) RecorderOption {
+	return func(o *RecorderOptions) {
+		o.maxCueLength = n
+	}
+}
+
+// Recorder collects caption cues from the events of a conversation.
+type Recorder struct {
+	RecorderOptions
+
+	mu sync.Mutex
+	// start is when the first event was observed, the start of the
+	// conversation timeline.
+	start        time.Time
+	turnID       string
+	segmentStart time.Time
+	cues         []cue
+}
+
+type cue struct {
+	turnID     string
+	start, end time.Time
+	text       string
+}
+
+func NewRecorder(opts ...RecorderOption) *Recorder {
+	recorder := &Recorder{RecorderOptions: RecorderOptions{maxCueLength: 84}}
+	for _, opt := range opts {
+		opt(&recorder.RecorderOptions)
+	}
+	return recorder
+}
+
+// Observe records event, it is meant to be passed as, or called from, the
+// event callback of the orchestrator.
+func (r *Recorder) Observe(event events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.start.IsZero() {
+		r.start = event.Timestamp()
+	}
+
+	switch typedEvent := event.(type) {
+	case events.TurnStarted:
+		r.turnID = typedEvent.TurnID
+	case events.AssistantPlaybackStarted:
+		r.segmentStart = typedEvent.Timestamp()
+	case events.AssistantPlaybackMarkPlayed:
+		if r.segmentStart.IsZero() {
+			return
+		}
+		if text := strings.Join(strings.Fields(typedEvent.Transcript), " "); text != "" {
+			r.addCues(text, r.segmentStart, typedEvent.Timestamp())
+		}
+		r.segmentStart = typedEvent.Timestamp()
+	case events.AssistantPlaybackEnded:
+		r.segmentStart = time.Time{}
+	}
+}
+
+// addCues adds the cues of a segment played from start to end. r.mu must be
+// held.
+func (r *Recorder) addCues(text string, start, end time.Time) {
+	lines := splitText(text, r.maxCueLength)
+	duration := end.Sub(start)
+	played := 0
+	for _, line := range lines {
+		lineStart := start.Add(time.Duration(float64(duration) * float64(played) / float64(len(text))))
+		played += len(line) + 1
+		lineEnd := start.Add(time.Duration(float64(duration) * float64(min(played, len(text))) / float64(len(text))))
+		r.cues = append(r.cues, cue{turnID: r.turnID, start: lineStart, end: lineEnd, text: line})
+	}
+}
+
+// Cues returns the cues of the conversation, timed from its first event.
+func (r *Recorder) Cues() []Cue {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cues := make([]Cue, 0, len(r.cues))
+	for _, c := range r.cues {
+		cues = append(cues, Cue{TurnID: c.turnID, Start: c.start.Sub(r.start), End: c.end.Sub(r.start), Text: c.text})
+	}
+	return cues
+}
+
+// TurnCues returns the cues of the turn identified by turnID, timed from the
+// start of its speech.
+func (r *Recorder) TurnCues(turnID string) []Cue {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var cues []Cue
+	var turnStart time.Time
+	for _, c := range r.cues {
+		if c.turnID != turnID {
+			continue
+		}
+		if turnStart.IsZero() {
+			turnStart = c.start
+		}
+		cues = append(cues, Cue{TurnID: c.turnID, Start: c.start.Sub(turnStart), End: c.end.Sub(turnStart), Text: c.text})
+	}
+	return cues
+}
+
+// SRT formats cues as SubRip subtitles.
+func SRT(cues []Cue) string {
+	var srt strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&srt, "%d\n%s --> %s\n%s\n\n", i+1, formatTimestamp(cue.Start, ","), formatTimestamp(cue.End, ","), cue.Text)
+	}
+	return srt.String()
+}
+
+// WebVTT formats cues as WebVTT captions.
+func WebVTT(cues []Cue) string {
+	var vtt strings.Builder
+	vtt.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&vtt, "%s --> %s\n%s\n\n", formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."), cue.Text)
+	}
+	return vtt.String()
+}
+
+func formatTimestamp(d time.Duration, millisecondSeparator string) string {
+	d = max(d, 0)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d",
+		int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, millisecondSeparator, d.Milliseconds()%1000)
+}
+
+// splitText splits text at spaces into lines of at most maxLength
+// characters, words longer than that get a line of their own.
+func splitText(text string, maxLength int) []string {
+	if maxLength <= 0 || len(text) <= maxLength {
+		return []string{text}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > maxLength {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,53 @@
+package captions
+
+import (
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestRecorderCuesMarkedSegments(t *testing.T) {
+	recorder := NewRecorder(WithMaxCueLength(20))
+	recorder.Observe(events.NewTurnStarted("turn-1", "hi"))
+	recorder.Observe(events.NewAssistantPlaybackStarted())
+	time.Sleep(10 * time.Millisecond)
+	recorder.Observe(events.NewAssistantPlaybackMarkPlayed("1", "Hello there. "))
+	time.Sleep(10 * time.Millisecond)
+	recorder.Observe(events.NewAssistantPlaybackMarkPlayed("2", "How can I help you today?"))
+	recorder.Observe(events.NewAssistantPlaybackEnded("Hello there. How can I help you today?"))
+	recorder.Observe(events.NewAssistantPlaybackMarkPlayed("3", "late mark"))
+
+	cues := recorder.Cues()
+	texts := []string{"Hello there.", "How can I help you", "today?"}
+	if len(cues) != len(texts) {
+		t.Fatalf("expected %d cues, got %+v", len(texts), cues)
+	}
+	for i, cue := range cues {
+		if cue.Text != texts[i] || cue.TurnID != "turn-1" || cue.End <= cue.Start {
+			t.Fatalf("unexpected cue %d %+v", i, cue)
+		}
+		if i > 0 && cue.Start != cues[i-1].End {
+			t.Fatalf("expected cue %d to follow the previous one, got %+v", i, cues)
+		}
+	}
+
+	turnCues := recorder.TurnCues("turn-1")
+	if len(turnCues) != 3 || turnCues[0].Start != 0 {
+		t.Fatalf("expected turn cues timed from the turn speech, got %+v", turnCues)
+	}
+}
+
+func TestCaptionFormats(t *testing.T) {
+	cues := []Cue{
+		{Start: 1500 * time.Millisecond, End: 3 * time.Second, Text: "Hello there."},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Text: "Bye."},
+	}
+
+	if got, want := SRT(cues), "1\n00:00:01,500 --> 00:00:03,000\nHello there.\n\n2\n01:02:03,004 --> 01:02:05,000\nBye.\n\n"; got != want {
+		t.Fatalf("expected SRT %q, got %q", want, got)
+	}
+	if got, want := WebVTT(cues), "WEBVTT\n\n00:00:01.500 --> 00:00:03.000\nHello there.\n\n01:02:03.004 --> 01:02:05.000\nBye.\n\n"; got != want {
+		t.Fatalf("expected WebVTT %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,108 @@
+package orchestration
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// WithChatEvents emits the conversation as chat messages for on-screen chat,
+// next to the events they are derived from. Each message of the user or the
+// assistant gets a stable ID, is updated in place with
+// [events.ChatMessageUpdated] while it changes, e.g. with interim transcripts
+// or streaming response text, and is frozen with
+// [events.ChatMessageFinalized].
+func WithChatEvents() OrchestrateOption {
+	return func(o *OrchestrateOptions) {
+		o.chatEvents = true
+	}
+}
+
+// chatMessages derives chat messages from user_input and assistant_response
+// events, see [WithChatEvents].
+type chatMessages struct {
+	mu sync.Mutex
+	// user are the open messages of the users by speaker.
+	user      map[string]*chatMessage
+	assistant *chatMessage
+}
+
+type chatMessage struct {
+	id   string
+	text string
+}
+
+func newChatMessages() *chatMessages {
+	return &chatMessages{user: map[string]*chatMessage{}}
+}
+
+func (c *chatMessages) observe(emitEvent eventEmitter) eventEmitter {
+	return func(event events.Event) {
+		emitEvent(event)
+		for _, chatEvent := range c.derive(event) {
+			emitEvent(chatEvent)
+		}
+	}
+}
+
+func (c *chatMessages) derive(event events.Event) []events.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch e := event.(type) {
+	case events.UserTranscriptInterimUpdated:
+		if e.Transcript == "" {
+			return nil
+		}
+		message := c.openUser(e.SpeakerID)
+		message.text = e.Transcript
+		return []events.Event{events.NewChatMessageUpdated(message.id, events.ChatRoleUser, e.SpeakerID, message.text)}
+	case events.UserTranscriptFinal:
+		message := c.openUser(e.SpeakerID)
+		delete(c.user, e.SpeakerID)
+		return []events.Event{events.NewChatMessageFinalized(message.id, events.ChatRoleUser, e.SpeakerID, e.Transcript)}
+	case events.UserMessageTyped:
+		return []events.Event{events.NewChatMessageFinalized(uuid.NewString(), events.ChatRoleUser, e.SpeakerID, e.Message)}
+
+	case events.AssistantResponseStarted:
+		c.assistant = &chatMessage{id: uuid.NewString()}
+	case events.AssistantResponseSegment:
+		if c.assistant == nil || e.Segment == "" {
+			return nil
+		}
+		c.assistant.text += e.Segment
+		return []events.Event{events.NewChatMessageUpdated(c.assistant.id, events.ChatRoleAssistant, "", c.assistant.text)}
+	case events.AssistantResponseSegmentReplaced:
+		if c.assistant == nil {
+			return nil
+		}
+		c.assistant.text = strings.TrimSuffix(c.assistant.text, e.Replaced) + e.Segment
+		return []events.Event{events.NewChatMessageUpdated(c.assistant.id, events.ChatRoleAssistant, "", c.assistant.text)}
+	case events.AssistantResponseFinalized:
+		if c.assistant != nil {
+			c.assistant.text = e.Response
+		}
+	case events.AssistantResponseFinal:
+		message := c.assistant
+		c.assistant = nil
+		// Responses without text, e.g. only calling tools, are no messages.
+		if message == nil || message.text == "" {
+			return nil
+		}
+		return []events.Event{events.NewChatMessageFinalized(message.id, events.ChatRoleAssistant, "", message.text)}
+	}
+	return nil
+}
+
+// openUser returns the open message of speakerID, opening one if there is
+// none.
+func (c *chatMessages) openUser(speakerID string) *chatMessage {
+	message, ok := c.user[speakerID]
+	if !ok {
+		message = &chatMessage{id: uuid.NewString()}
+		c.user[speakerID] = message
+	}
+	return message
+}
@@ -0,0 +1,61 @@
+package orchestration
+
+import (
+	"testing"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestChatMessagesUpdateInPlaceAndFinalize(t *testing.T) {
+	var chat []events.Event
+	emit := newChatMessages().observe(func(event events.Event) {
+		switch event.(type) {
+		case events.ChatMessageUpdated, events.ChatMessageFinalized:
+			chat = append(chat, event)
+		}
+	})
+
+	emit(events.NewUserTranscriptInterimUpdated("what's the"))
+	emit(events.NewUserTranscriptInterimUpdated("what's the weather"))
+	emit(events.NewUserTranscriptInterimUpdated(""))
+	emit(events.NewUserTranscriptFinal("What's the weather?"))
+	emit(events.NewAssistantResponseStarted())
+	emit(events.NewAssistantResponseFinal())
+	emit(events.NewAssistantResponseStarted())
+	emit(events.NewAssistantResponseSegment("It is rainy"))
+	emit(events.NewAssistantResponseSegmentReplaced("rainy", "sunny."))
+	emit(events.NewAssistantResponseFinalized("It is sunny."))
+	emit(events.NewAssistantResponseFinal())
+	emit(events.NewUserMessageTyped("Thanks"))
+
+	type message struct{ id, role, text string }
+	var got []message
+	for _, event := range chat {
+		switch e := event.(type) {
+		case events.ChatMessageUpdated:
+			got = append(got, message{e.MessageID, e.Role, e.Text})
+		case events.ChatMessageFinalized:
+			got = append(got, message{e.MessageID, e.Role, "final: " + e.Text})
+		}
+	}
+	want := []message{
+		{got[0].id, events.ChatRoleUser, "what's the"},
+		{got[0].id, events.ChatRoleUser, "what's the weather"},
+		{got[0].id, events.ChatRoleUser, "final: What's the weather?"},
+		{got[3].id, events.ChatRoleAssistant, "It is rainy"},
+		{got[3].id, events.ChatRoleAssistant, "It is sunny."},
+		{got[3].id, events.ChatRoleAssistant, "final: It is sunny."},
+		{got[6].id, events.ChatRoleUser, "final: Thanks"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected chat messages %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected chat messages %+v, got %+v", want, got)
+		}
+	}
+	if got[0].id == got[3].id || got[3].id == got[6].id || got[0].id == got[6].id {
+		t.Fatalf("expected every message to have its own ID, got %+v", got)
+	}
+}
@@ -0,0 +1,93 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// PlaybackCheckpointV0 is how far the user heard a response, by the marks
+// the audio output confirmed as played, so the response can be resumed from
+// the last confirmed sentence after a pause, transfer or reconnect.
+type PlaybackCheckpointV0 struct {
+	TurnID string `json:"turn_id"`
+	// Spoken is the part of the response confirmed as played.
+	Spoken string `json:"spoken"`
+	// Unspoken is the rest of the response as far as it was generated.
+	Unspoken string `json:"unspoken"`
+}
+
+// PlaybackCheckpoint returns the checkpoint of the response being spoken, or
+// of the last response if its playback was cut off, e.g. to persist it
+// alongside the history. It returns false if no response is left unheard or
+// the response is not spoken.
+func (o *Orchestrator) PlaybackCheckpoint() (PlaybackCheckpointV0, bool) {
+	if checkpoint, ok := o.currentResponsePipeline().playbackCheckpoint(); ok {
+		return checkpoint, true
+	}
+
+	history := o.conversation.History()
+	if len(history) == 0 {
+		return PlaybackCheckpointV0{}, false
+	}
+	return checkpointOf(history[len(history)-1])
+}
+
+// ResumePlayback starts a turn continuing the response of checkpoint from
+// where the user stopped hearing it, "as I was saying". The turn starts once
+// the active turn, if any, ended, like with [Orchestrator.InitiateTurn].
+func (o *Orchestrator) ResumePlayback(ctx context.Context, checkpoint PlaybackCheckpointV0) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !o.triggerPlayer.Load().CanIngest() {
+		return ErrOrchestratorClosed
+	}
+
+	o.ingestTrigger(newResumePlaybackTrigger(checkpoint))
+	return nil
+}
+
+func newResumePlaybackTrigger(checkpoint PlaybackCheckpointV0) triggers.AssistantInitiatedTrigger {
+	return triggers.NewAssistantInitiatedTrigger(fmt.Sprintf(
+		"Your previous response was cut off before the user heard all of it. "+
+			"Continue it from where they stopped hearing it, starting with \"As I was saying\". "+
+			"The part they did not hear: %q", checkpoint.Unspoken,
+	))
+}
+
+// playbackCheckpoint returns the checkpoint of the response of the pipeline
+// while its speech is played.
+func (p *responsePipeline) playbackCheckpoint() (PlaybackCheckpointV0, bool) {
+	var turn *activeTurn
+	p.rLockFor(func() { turn = p.turn })
+	if turn == nil || p.textOnly.Load() || !p.textToSpeech.connected.Load() {
+		return PlaybackCheckpointV0{}, false
+	}
+
+	p.spokenMu.Lock()
+	defer p.spokenMu.Unlock()
+	if turn.IsFinalised {
+		return checkpointOf(turn.TurnV1)
+	}
+	spoken, unspoken := splitSpoken(*turn.finalResponse)
+	if unspoken == "" {
+		return PlaybackCheckpointV0{}, false
+	}
+	return PlaybackCheckpointV0{TurnID: turn.ID, Spoken: spoken, Unspoken: unspoken}, true
+}
+
+// checkpointOf returns the checkpoint of the last response of a finalised
+// turn if its playback was cut off.
+func checkpointOf(turn llms.TurnV1) (PlaybackCheckpointV0, bool) {
+	if len(turn.Responses) == 0 {
+		return PlaybackCheckpointV0{}, false
+	}
+	response := turn.Responses[len(turn.Responses)-1]
+	if !response.IsInterrupted || response.UnspokenMessage == "" {
+		return PlaybackCheckpointV0{}, false
+	}
+	return PlaybackCheckpointV0{TurnID: turn.ID, Spoken: response.Message, Unspoken: response.UnspokenMessage}, true
+}
@@ -0,0 +1,57 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestCheckpointedResponseResumesAfterTransfer(t *testing.T) {
+	turn := newActiveTurn(triggers.NewUserPromptTrigger("weather?"))
+	turn.finalResponse.Message = "It is sunny. It will rain tomorrow."
+	turn.finalResponse.TypedMessage = "It is sunny. It will rain tomorrow."
+	turn.finalResponse.SpokenResponse = "It is sunny."
+	turn.truncateToSpoken()
+	turn.Finalise()
+
+	checkpoint, ok := checkpointOf(turn.TurnV1)
+	if !ok || checkpoint.TurnID != turn.ID || checkpoint.Spoken != "It is sunny." || checkpoint.Unspoken != " It will rain tomorrow." {
+		t.Fatalf("unexpected checkpoint %+v, %v", checkpoint, ok)
+	}
+
+	encoded, err := json.Marshal(SessionStateV0{History: []llms.TurnV1{turn.TurnV1}, Checkpoint: &checkpoint})
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	var state SessionStateV0
+	if err := json.Unmarshal(encoded, &state); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+
+	resumed := NewOrchestrator(WithStreamingLLM(scriptedStreamLLMStub{chunks: []string{"As I was saying, it will rain tomorrow."}}), WithSessionStateV0(state))
+	defer resumed.Close()
+	if restored, ok := resumed.PlaybackCheckpoint(); !ok || restored != checkpoint {
+		t.Fatalf("expected the restored history to hold the checkpoint, got %+v", restored)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resumed.Orchestrate(ctx)
+	resumed.Mute()
+	waitForCondition(t, 2*time.Second, "response to be resumed", func() bool {
+		return len(resumed.conversation.History()) == 2
+	})
+
+	trigger, ok := resumed.conversation.History()[1].Trigger.(triggers.AssistantInitiatedTrigger)
+	if !ok || !strings.Contains(trigger.Instruction, "It will rain tomorrow.") {
+		t.Fatalf("expected an assistant-initiated turn resuming the unspoken part, got %#v", resumed.conversation.History()[1].Trigger)
+	}
+	if _, ok := resumed.PlaybackCheckpoint(); ok {
+		t.Fatalf("expected no checkpoint once the response was resumed")
+	}
+}
@@ -0,0 +1,20 @@
+// Package clock abstracts the time source of time-dependent logic, so tests
+// can step through it deterministically and replays can run faster than real
+// time.
+package clock
+
+import "time"
+
+// Clock is a time source.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real returns a [Clock] backed by the time package.
+func Real() Clock { return realClock{} }
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
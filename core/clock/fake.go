@@ -0,0 +1,83 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a [Clock] that only moves when advanced, letting tests step
+// through timing deterministically.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeTimer
+	changed chan struct{}
+}
+
+type fakeTimer struct {
+	at    time.Time
+	fired chan time.Time
+}
+
+// NewFake creates a fake clock starting at start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, changed: make(chan struct{})}
+}
+
+func (c *Fake) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Fake) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fired := make(chan time.Time, 1)
+	if d <= 0 {
+		fired <- c.now
+		return fired
+	}
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), fired: fired})
+	c.notifyLocked()
+	return fired
+}
+
+// Advance moves the clock forward by d and fires all timers that are due.
+func (c *Fake) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, waiter := range c.waiters {
+		if waiter.at.After(c.now) {
+			pending = append(pending, waiter)
+			continue
+		}
+		waiter.fired <- c.now
+	}
+	c.waiters = pending
+	c.notifyLocked()
+}
+
+// BlockUntil waits until at least n timers are waiting on the clock, so a
+// test can advance it only once the code under test reached the expected
+// point.
+func (c *Fake) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		waiting, changed := len(c.waiters), c.changed
+		c.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+func (c *Fake) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
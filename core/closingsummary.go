@@ -0,0 +1,92 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultClosingSummaryPrompt = `You write the closing summary of a conversation between a user and an assistant for the record of the call.
+Reply with a few sentences only, covering why the user got in touch, what was done and what was agreed to happen next.`
+
+const defaultClosingSummaryTimeout = 30 * time.Second
+
+type ClosingSummaryOptions struct {
+	systemPrompt string
+	timeout      time.Duration
+}
+
+type ClosingSummaryOption func(*ClosingSummaryOptions)
+
+// WithClosingSummaryPrompt replaces the system prompt the closing summary is
+// written with, e.g. to follow the format of a CRM.
+func WithClosingSummaryPrompt(systemPrompt string) ClosingSummaryOption {
+	return func(o *ClosingSummaryOptions) {
+		o.systemPrompt = systemPrompt
+	}
+}
+
+// WithClosingSummaryTimeout bounds how long writing the closing summary may
+// take, defaults to 30 seconds.
+func WithClosingSummaryTimeout(timeout time.Duration) ClosingSummaryOption {
+	return func(o *ClosingSummaryOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithClosingSummary has [Orchestrator.EndConversation] write a closing
+// summary of the conversation with one last pass of the configured LLM, so
+// it can be stored with the record of the call.
+//
+// Unlike [Orchestrator.Analyze], the summary is free text meant for people,
+// it is kept as [ConversationV1.ClosingSummary].
+func WithClosingSummary(opts ...ClosingSummaryOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		options := ClosingSummaryOptions{systemPrompt: defaultClosingSummaryPrompt, timeout: defaultClosingSummaryTimeout}
+		for _, opt := range opts {
+			opt(&options)
+		}
+		o.closingSummary = &options
+	}
+}
+
+// EndConversation stops the orchestrator from starting new turns, waits for
+// the turn in progress to finish and closes the orchestrator. If ctx is done
+// before the turn finishes, the turn is cancelled.
+//
+// With [WithClosingSummary] the closing summary is written before closing
+// and returned, otherwise the summary is empty. The summary is written even
+// if ctx is done, e.g. because ending the conversation was cut short, within
+// [WithClosingSummaryTimeout]. The orchestrator is closed even if writing the
+// summary fails.
+func (o *Orchestrator) EndConversation(ctx context.Context) (string, error) {
+	if !o.triggerPlayer.Load().CanIngest() {
+		return "", ErrOrchestratorClosed
+	}
+	defer o.Close()
+
+	o.stopTurns(ctx)
+	if o.closingSummary == nil {
+		return "", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), o.closingSummary.timeout)
+	defer cancel()
+	ctx, span := tracer.Start(ctx, "summarize conversation")
+	defer span.End()
+
+	summary, err := o.llm.prompt(ctx, formatTranscript(o.conversation.History()), o.closingSummary.systemPrompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to write closing summary: %w", err)
+	}
+	summary = strings.TrimSpace(summary)
+	o.conversation.setClosingSummary(summary)
+	return summary, nil
+}
+
+func (t *activeConversation) setClosingSummary(summary string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closingSummary = summary
+}
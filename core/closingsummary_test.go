@@ -0,0 +1,89 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestEndConversationWritesClosingSummary(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	o := NewOrchestrator(
+		WithLLM(llm),
+		WithInitialHistory(llms.TurnV1{
+			Trigger:   triggers.NewUserPromptTrigger("Can I move my appointment?"),
+			Responses: []llms.TurnResponseV0{{Message: "Moved to Friday."}},
+		}),
+		WithClosingSummary(WithClosingSummaryPrompt("Summarize for the CRM.")),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx)
+
+	summary, err := o.EndConversation(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != "echo: User: Can I move my appointment?\nAssistant: Moved to Friday." {
+		t.Fatalf("expected the summary of the transcript, got %q", summary)
+	}
+	llm.mu.Lock()
+	instructions := append([]string(nil), llm.instructions...)
+	llm.mu.Unlock()
+	if len(instructions) != 1 || instructions[0] != "Summarize for the CRM." {
+		t.Fatalf("expected one summary pass with the configured prompt, got %v", instructions)
+	}
+	if stored := o.ConversationV1().ClosingSummary; stored != summary {
+		t.Fatalf("expected the summary to be kept on the conversation, got %q", stored)
+	}
+	if _, err := o.EndConversation(context.Background()); !errors.Is(err, ErrOrchestratorClosed) {
+		t.Fatalf("expected ErrOrchestratorClosed once ended, got %v", err)
+	}
+}
+
+// deadlinePromptLLMStub answers prompts once their context is done if block
+// is set, and right away otherwise.
+type deadlinePromptLLMStub struct {
+	block bool
+}
+
+func (stub deadlinePromptLLMStub) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
+	if stub.block {
+		<-ctx.Done()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return []llms.Message{{Content: "Moved the appointment."}}, nil
+}
+
+func TestEndConversationWritesClosingSummaryAfterItsContextIsDone(t *testing.T) {
+	o := NewOrchestrator(WithLLM(deadlinePromptLLMStub{}), WithClosingSummary())
+	o.Orchestrate(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary, err := o.EndConversation(ctx)
+	if err != nil || summary != "Moved the appointment." {
+		t.Fatalf("expected the summary despite the done context, got %q, %v", summary, err)
+	}
+}
+
+func TestEndConversationBoundsTheClosingSummary(t *testing.T) {
+	o := NewOrchestrator(
+		WithLLM(deadlinePromptLLMStub{block: true}),
+		WithClosingSummary(WithClosingSummaryTimeout(10*time.Millisecond)),
+	)
+	o.Orchestrate(context.Background())
+
+	if _, err := o.EndConversation(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the summary to time out, got %v", err)
+	}
+	if o.triggerPlayer.Load().CanIngest() {
+		t.Fatal("expected the orchestrator to be closed")
+	}
+}
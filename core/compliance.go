@@ -0,0 +1,142 @@
+package orchestration
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// compliancePauseSource is the source of the context turns marking
+// compliance pauses in the history.
+const compliancePauseSource = "compliance"
+
+type CompliancePauseOptions struct {
+	maxDuration time.Duration
+}
+
+type CompliancePauseOption func(*CompliancePauseOptions)
+
+// WithCompliancePauseMaxDuration ends the pause after d if it was not ended
+// before, so a missed [Orchestrator.EndCompliancePause] does not leave the
+// assistant deaf for the rest of the call.
+func WithCompliancePauseMaxDuration(d time.Duration) CompliancePauseOption {
+	return func(o *CompliancePauseOptions) {
+		o.maxDuration = d
+	}
+}
+
+// StartCompliancePause withholds user audio and transcripts for a sensitive
+// segment, e.g. while the user reads a card number to a payment system,
+// until [Orchestrator.EndCompliancePause]. Audio is not passed to
+// speech-to-text, audio frame and transcript events are not emitted and
+// nothing the user says triggers a turn.
+//
+// The pause is reported with [events.CompliancePauseStarted] and
+// [events.CompliancePauseEnded] events and recorded in the history as a
+// context turn once it ends, so the gap can be audited. Starting a pause
+// while one is active does nothing.
+func (o *Orchestrator) StartCompliancePause(reason string, opts ...CompliancePauseOption) {
+	options := CompliancePauseOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	o.compliance.mu.Lock()
+	if o.compliance.active {
+		o.compliance.mu.Unlock()
+		return
+	}
+	o.compliance.active = true
+	o.compliance.reason = reason
+	o.compliance.startedAt = o.clock.Now()
+	o.compliance.droppedFrames = 0
+	o.compliance.generation++
+	generation := o.compliance.generation
+	o.compliance.mu.Unlock()
+
+	o.emitEvent(events.NewCompliancePauseStarted(reason))
+
+	if options.maxDuration > 0 {
+		go func() {
+			<-o.clock.After(options.maxDuration)
+			o.endCompliancePause(generation, true)
+		}()
+	}
+}
+
+// EndCompliancePause lets user audio and transcripts flow again after
+// [Orchestrator.StartCompliancePause].
+func (o *Orchestrator) EndCompliancePause() {
+	o.compliance.mu.Lock()
+	generation := o.compliance.generation
+	o.compliance.mu.Unlock()
+	o.endCompliancePause(generation, false)
+}
+
+// IsCompliancePaused reports whether user audio and transcripts are
+// withheld.
+func (o *Orchestrator) IsCompliancePaused() bool {
+	o.compliance.mu.Lock()
+	defer o.compliance.mu.Unlock()
+	return o.compliance.active
+}
+
+// endCompliancePause ends the pause started as generation, if it is still
+// active.
+func (o *Orchestrator) endCompliancePause(generation int, timedOut bool) {
+	o.compliance.mu.Lock()
+	if !o.compliance.active || o.compliance.generation != generation {
+		o.compliance.mu.Unlock()
+		return
+	}
+	o.compliance.active = false
+	reason := o.compliance.reason
+	duration := o.clock.Now().Sub(o.compliance.startedAt)
+	droppedFrames := o.compliance.droppedFrames
+	o.compliance.mu.Unlock()
+
+	o.saveTurn(o.baseContext, o.conversation.appendContextTurn(triggers.NewContextTrigger(compliancePauseSource,
+		fmt.Sprintf("User audio and transcripts were withheld for %v (%s), what the user said in that time is unknown.", duration.Round(time.Second), reason),
+	)))
+	o.emitEvent(events.NewCompliancePauseEnded(reason, duration, droppedFrames, timedOut))
+}
+
+// compliancePause is the state of a compliance pause, see
+// [Orchestrator.StartCompliancePause].
+type compliancePause struct {
+	mu            sync.Mutex
+	active        bool
+	reason        string
+	startedAt     time.Time
+	droppedFrames int
+	// generation identifies the pause, so the timeout of an earlier one does
+	// not end it.
+	generation int
+}
+
+// dropsAudio reports whether user audio is withheld, counting the frame as
+// dropped if it is.
+func (p *compliancePause) dropsAudio() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active {
+		p.droppedFrames++
+	}
+	return p.active
+}
+
+// dropsEvent reports whether event of the user is withheld.
+func (p *compliancePause) dropsEvent(event events.Event) bool {
+	switch event.(type) {
+	case events.UserTranscriptInterimUpdated, events.UserTranscriptInterimSegmentUpdated,
+		events.UserTranscriptSegment, events.UserTranscriptFinal:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.active
+	default:
+		return false
+	}
+}
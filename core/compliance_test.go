@@ -0,0 +1,77 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestCompliancePauseWithholdsUserAudioAndTranscripts(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	sttClient := &recordingSpeechToTextClient{}
+	o := NewOrchestrator(WithSpeechToTextClient(sttClient), WithClock(fakeClock))
+	defer o.Close()
+
+	var mu sync.Mutex
+	var received []events.Event
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+	}))
+	audioEmitter := o.composeAudioInputEventEmitter(o.emitEvent)
+	sttEmitter := o.composeSTTEventEmitter(o.emitEvent)
+
+	o.StartCompliancePause("card number", WithCompliancePauseMaxDuration(time.Minute))
+	audioEmitter(events.NewUserAudioFrame([]byte{1}))
+	if err := o.SendAudio([]byte{2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sttEmitter(events.NewUserTranscriptFinal("4242 4242 4242 4242"))
+	if sent := sttClient.snapshot(); len(sent) != 0 {
+		t.Fatalf("expected no audio to reach speech-to-text while paused, got %v", sent)
+	}
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(time.Minute)
+	waitForCondition(t, time.Second, "pause to time out", func() bool { return !o.IsCompliancePaused() })
+
+	audioEmitter(events.NewUserAudioFrame([]byte{3}))
+	if sent := sttClient.snapshot(); len(sent) != 1 || sent[0][0] != 3 {
+		t.Fatalf("expected audio to reach speech-to-text after the pause, got %v", sent)
+	}
+
+	mu.Lock()
+	var ended *events.CompliancePauseEnded
+	for _, event := range received {
+		switch event := event.(type) {
+		case events.UserAudioFrame:
+			if event.Audio[0] != 3 {
+				t.Fatalf("expected withheld audio frame not to be emitted, got %v", event.Audio)
+			}
+		case events.UserTranscriptFinal:
+			t.Fatalf("expected withheld transcript not to be emitted, got %q", event.Transcript)
+		case events.CompliancePauseEnded:
+			ended = &event
+		}
+	}
+	mu.Unlock()
+	if ended == nil || ended.Reason != "card number" || ended.Duration != time.Minute || ended.DroppedFrames != 2 || !ended.TimedOut {
+		t.Fatalf("unexpected pause ended event %+v", ended)
+	}
+
+	history := o.conversation.History()
+	if len(history) != 1 {
+		t.Fatalf("expected the pause to be recorded in the history, got %+v", history)
+	}
+	if trigger, ok := history[0].Trigger.(triggers.ContextTrigger); !ok || trigger.Source != compliancePauseSource {
+		t.Fatalf("expected a compliance context turn, got %#v", history[0].Trigger)
+	}
+}
@@ -0,0 +1,273 @@
+// Package config builds an orchestrator from a YAML or JSON file, so
+// deployments can switch providers, models and voices without recompiling.
+//
+//	llm:
+//	  provider: groq
+//	  model: llama-3.3-70b-versatile
+//	text_to_speech:
+//	  provider: deepgram
+//	  voice: aura-2-asteria-en
+//	history:
+//	  max_turns: 50
+//
+// Providers are looked up by name in a [Registry], which provider packages
+// can register themselves in, see [RegisterLLM]. API keys are not part of
+// the config and are resolved by the providers as usual, e.g. from the
+// environment.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes the providers and policies of an orchestrator, omitted
+// providers are not configured.
+type Config struct {
+	LLM          *Provider `json:"llm" yaml:"llm"`
+	SpeechToText *Provider `json:"speech_to_text" yaml:"speech_to_text"`
+	TextToSpeech *Provider `json:"text_to_speech" yaml:"text_to_speech"`
+	AudioInput   *Provider `json:"audio_input" yaml:"audio_input"`
+	AudioOutput  *Provider `json:"audio_output" yaml:"audio_output"`
+
+	History *History `json:"history" yaml:"history"`
+	Budget  *Budget  `json:"budget" yaml:"budget"`
+}
+
+// Provider selects a provider registered under Name and configures it. Which
+// of the other fields apply depends on the provider, e.g. voices only apply
+// to text to speech.
+type Provider struct {
+	Name         string    `json:"provider" yaml:"provider"`
+	Model        string    `json:"model,omitempty" yaml:"model,omitempty"`
+	Voice        string    `json:"voice,omitempty" yaml:"voice,omitempty"`
+	SystemPrompt string    `json:"system_prompt,omitempty" yaml:"system_prompt,omitempty"`
+	Encoding     *Encoding `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	// Options are passed to the provider as is, for settings specific to it.
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// ParseProvider parses a provider spec of the form "name" or "name:model",
+// e.g. "deepgram" or "openai:gpt-4o". Providers can be given as specs in
+// config files as well:
+//
+//	llm: openai:gpt-4o
+func ParseProvider(spec string) (Provider, error) {
+	name, model, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	if name == "" {
+		return Provider{}, fmt.Errorf("provider spec %q has no provider name", spec)
+	}
+	return Provider{Name: name, Model: model}, nil
+}
+
+// providerFields is Provider without its methods, decoded as is.
+type providerFields Provider
+
+func (p *Provider) UnmarshalJSON(data []byte) error {
+	var spec string
+	if err := json.Unmarshal(data, &spec); err == nil {
+		provider, err := ParseProvider(spec)
+		if err != nil {
+			return err
+		}
+		*p = provider
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode((*providerFields)(p))
+}
+
+func (p *Provider) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		provider, err := ParseProvider(value.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", value.Line, err)
+		}
+		*p = provider
+		return nil
+	}
+
+	if value.Kind == yaml.MappingNode {
+		// Nodes decode without the known fields check of the decoder.
+		for i := 0; i < len(value.Content); i += 2 {
+			if key := value.Content[i]; !slices.Contains(providerYAMLFields, key.Value) {
+				return fmt.Errorf("line %d: field %s not found in type config.Provider", key.Line, key.Value)
+			}
+		}
+	}
+	return value.Decode((*providerFields)(p))
+}
+
+// providerYAMLFields are the field names of Provider in YAML.
+var providerYAMLFields = func() []string {
+	providerType := reflect.TypeFor[Provider]()
+	fields := make([]string, 0, providerType.NumField())
+	for i := range providerType.NumField() {
+		name, _, _ := strings.Cut(providerType.Field(i).Tag.Get("yaml"), ",")
+		fields = append(fields, name)
+	}
+	return fields
+}()
+
+// Encoding describes the audio encoding of audio devices.
+type Encoding struct {
+	// Format is one of "linear16", "mulaw" or "alaw".
+	Format     string `json:"format" yaml:"format"`
+	SampleRate int    `json:"sample_rate" yaml:"sample_rate"`
+}
+
+// EncodingInfo returns the encoding of the provider, the default encoding if
+// it has none.
+func (p Provider) EncodingInfo() audio.EncodingInfo {
+	if p.Encoding == nil {
+		return audio.GetDefaultEncodingInfo()
+	}
+
+	encoding := audio.GetDefaultEncodingInfo()
+	if format, ok := encodingFormats[p.Encoding.Format]; ok {
+		encoding.Format = format.Format
+	}
+	if p.Encoding.SampleRate != 0 {
+		encoding.SampleRate = p.Encoding.SampleRate
+	}
+	return encoding
+}
+
+var encodingFormats = map[string]audio.EncodingInfo{
+	audio.EncodingLinear16.Name(): {Format: audio.EncodingLinear16},
+	audio.EncodingMulaw.Name():    {Format: audio.EncodingMulaw},
+	audio.EncodingALaw.Name():     {Format: audio.EncodingALaw},
+}
+
+// History configures [orchestration.WithHistoryLimit].
+type History struct {
+	MaxTurns int `json:"max_turns,omitempty" yaml:"max_turns,omitempty"`
+	MaxBytes int `json:"max_bytes,omitempty" yaml:"max_bytes,omitempty"`
+}
+
+// Budget configures [orchestration.WithBudget], the fallback LLM is selected
+// like the main one.
+type Budget struct {
+	MaxTokens int       `json:"max_tokens,omitempty" yaml:"max_tokens,omitempty"`
+	MaxCost   float64   `json:"max_cost,omitempty" yaml:"max_cost,omitempty"`
+	MaxTurns  int       `json:"max_turns,omitempty" yaml:"max_turns,omitempty"`
+	Fallback  *Provider `json:"fallback,omitempty" yaml:"fallback,omitempty"`
+}
+
+// Format is the encoding of a config file.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// Load reads the config file at path, the format is taken from the ".json",
+// ".yaml" or ".yml" extension.
+func Load(path string) (Config, error) {
+	var format Format
+	switch filepath.Ext(path) {
+	case ".json":
+		format = FormatJSON
+	case ".yaml", ".yml":
+		format = FormatYAML
+	default:
+		return Config{}, fmt.Errorf("unknown config format of %q", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+	return Parse(data, format)
+}
+
+// Parse decodes a config, unknown fields are reported as errors so typos do
+// not silently fall back to defaults.
+func Parse(data []byte, format Format) (Config, error) {
+	var config Config
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			return Config{}, fmt.Errorf("failed to parse json config: %w", err)
+		}
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+			return Config{}, fmt.Errorf("failed to parse yaml config: %w", err)
+		}
+	default:
+		return Config{}, fmt.Errorf("unknown config format %q", format)
+	}
+	return config, nil
+}
+
+// validate checks the parts of the config that do not depend on the
+// registry.
+func (c Config) validate() []error {
+	var errs []error
+	if c.LLM == nil {
+		errs = append(errs, errors.New("llm: required"))
+	}
+	for _, p := range c.providers() {
+		if p.provider.Name == "" {
+			errs = append(errs, fmt.Errorf("%s.provider: required", p.field))
+		}
+		if encoding := p.provider.Encoding; encoding != nil {
+			if _, ok := encodingFormats[encoding.Format]; !ok {
+				errs = append(errs, fmt.Errorf("%s.encoding.format: unknown format %q", p.field, encoding.Format))
+			}
+			if encoding.SampleRate < 0 {
+				errs = append(errs, fmt.Errorf("%s.encoding.sample_rate: must not be negative", p.field))
+			}
+		}
+	}
+	if c.History != nil && (c.History.MaxTurns < 0 || c.History.MaxBytes < 0) {
+		errs = append(errs, errors.New("history: limits must not be negative"))
+	}
+	if c.Budget != nil && (c.Budget.MaxTokens < 0 || c.Budget.MaxCost < 0 || c.Budget.MaxTurns < 0) {
+		errs = append(errs, errors.New("budget: limits must not be negative"))
+	}
+	return errs
+}
+
+type namedProvider struct {
+	field    string
+	provider *Provider
+}
+
+// providers returns the configured providers with their field names.
+func (c Config) providers() []namedProvider {
+	var providers []namedProvider
+	for _, p := range []namedProvider{
+		{"llm", c.LLM},
+		{"speech_to_text", c.SpeechToText},
+		{"text_to_speech", c.TextToSpeech},
+		{"audio_input", c.AudioInput},
+		{"audio_output", c.AudioOutput},
+	} {
+		if p.provider != nil {
+			providers = append(providers, p)
+		}
+	}
+	if c.Budget != nil && c.Budget.Fallback != nil {
+		providers = append(providers, namedProvider{"budget.fallback", c.Budget.Fallback})
+	}
+	return providers
+}
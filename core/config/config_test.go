@@ -0,0 +1,179 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+type llmStub struct{}
+
+func (llmStub) PromptWithStream(context.Context, *string, ...llms.StreamingPromptOption) llms.Stream {
+	return nil
+}
+
+func TestParseYAMLAndJSON(t *testing.T) {
+	yamlConfig := `
+llm:
+  provider: groq
+  model: qwen/qwen3-32b
+text_to_speech:
+  provider: deepgram
+  voice: aura-2-asteria-en
+history:
+  max_turns: 20
+`
+	jsonConfig := `{
+	"llm": {"provider": "groq", "model": "qwen/qwen3-32b"},
+	"text_to_speech": {"provider": "deepgram", "voice": "aura-2-asteria-en"},
+	"history": {"max_turns": 20}
+}`
+
+	for format, data := range map[Format]string{FormatYAML: yamlConfig, FormatJSON: jsonConfig} {
+		config, err := Parse([]byte(data), format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if config.LLM.Name != "groq" || config.LLM.Model != "qwen/qwen3-32b" || config.TextToSpeech.Voice != "aura-2-asteria-en" || config.History.MaxTurns != 20 {
+			t.Fatalf("%s: unexpected config %+v", format, config)
+		}
+	}
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	for format, data := range map[Format]string{
+		FormatYAML: "llm:\n  provider: groq\n  modle: qwen/qwen3-32b\n",
+		FormatJSON: `{"llm": {"provider": "groq", "modle": "qwen/qwen3-32b"}}`,
+	} {
+		if _, err := Parse([]byte(data), format); err == nil || !strings.Contains(err.Error(), "modle") {
+			t.Fatalf("%s: expected unknown field error, got %v", format, err)
+		}
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	err := NewRegistry().Validate(Config{
+		SpeechToText: &Provider{Name: "assemblyai"},
+		AudioOutput:  &Provider{Name: "speaker", Encoding: &Encoding{Format: "opus"}},
+		Budget:       &Budget{MaxTurns: -1},
+	})
+
+	for _, problem := range []string{
+		"llm: required",
+		`speech_to_text.provider: unknown provider "assemblyai"`,
+		`audio_output.encoding.format: unknown format "opus"`,
+		`audio_output.provider: unknown provider "speaker"`,
+		"budget: limits must not be negative",
+	} {
+		if err == nil || !strings.Contains(err.Error(), problem) {
+			t.Fatalf("expected %q to be reported, got %v", problem, err)
+		}
+	}
+}
+
+func TestRegistryBuildsRegisteredProviders(t *testing.T) {
+	registry := NewRegistry()
+	var built Provider
+	registry.RegisterLLM("stub", func(_ context.Context, provider Provider) (orchestration.LLMWithStream, error) {
+		built = provider
+		return llmStub{}, nil
+	})
+
+	o, err := registry.NewOrchestrator(context.Background(), Config{
+		LLM: &Provider{Name: "stub", Model: "small", Options: map[string]string{"temperature": "0.2"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(o.Close)
+	if built.Model != "small" || built.Options["temperature"] != "0.2" {
+		t.Fatalf("expected provider config to be passed to the factory, got %+v", built)
+	}
+}
+
+func TestRegistryReportsProviderFailures(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterLLM("stub", func(context.Context, Provider) (orchestration.LLMWithStream, error) {
+		return nil, errors.New("no such model")
+	})
+	registry.RegisterAudioOutput("speaker", func(context.Context, Provider) (any, error) {
+		return "speaker", nil
+	})
+
+	_, err := registry.NewOrchestrator(context.Background(), Config{
+		LLM:         &Provider{Name: "stub"},
+		AudioOutput: &Provider{Name: "speaker"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "llm: no such model") || !strings.Contains(err.Error(), "audio_output: string is not an audio output") {
+		t.Fatalf("expected both provider failures, got %v", err)
+	}
+}
+
+func TestPackageRegisteredProvidersFromSpecs(t *testing.T) {
+	var built Provider
+	RegisterLLM("spec-stub", func(_ context.Context, provider Provider) (orchestration.LLMWithStream, error) {
+		built = provider
+		return llmStub{}, nil
+	})
+
+	if _, err := NewRegistry().NewLLM(context.Background(), "spec-stub:small"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if built.Name != "spec-stub" || built.Model != "small" {
+		t.Fatalf("expected spec to be passed to the factory, got %+v", built)
+	}
+	if _, err := NewRegistry().NewLLM(context.Background(), "whisper"); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("expected unknown provider error, got %v", err)
+	}
+
+	for format, data := range map[Format]string{
+		FormatYAML: "llm: spec-stub:small\n",
+		FormatJSON: `{"llm": "spec-stub:small"}`,
+	} {
+		config, err := Parse([]byte(data), format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if config.LLM.Name != "spec-stub" || config.LLM.Model != "small" {
+			t.Fatalf("%s: unexpected provider %+v", format, config.LLM)
+		}
+	}
+}
+
+func TestNewOrchestratorFromConfigBuildsProvidersFromSpecs(t *testing.T) {
+	var llm, voice Provider
+	RegisterLLM("from-config-stub", func(_ context.Context, provider Provider) (orchestration.LLMWithStream, error) {
+		llm = provider
+		return llmStub{}, nil
+	})
+	RegisterTextToSpeech("from-config-stub", func(_ context.Context, provider Provider) (orchestration.TextToSpeechV1, error) {
+		voice = provider
+		return nil, errors.New("no such voice")
+	})
+
+	o, err := NewOrchestratorFromConfig(context.Background(), SpecConfig{LLM: "from-config-stub:small"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(o.Close)
+	if llm.Name != "from-config-stub" || llm.Model != "small" {
+		t.Fatalf("expected the LLM spec to be passed to the factory, got %+v", llm)
+	}
+
+	_, err = NewOrchestratorFromConfig(context.Background(), SpecConfig{LLM: "from-config-stub", TextToSpeech: "from-config-stub:calm"})
+	if err == nil || !strings.Contains(err.Error(), "text_to_speech: no such voice") {
+		t.Fatalf("expected the text to speech failure, got %v", err)
+	}
+	if voice.Voice != "calm" || voice.Model != "" {
+		t.Fatalf("expected the text to speech spec to select the voice, got %+v", voice)
+	}
+
+	if _, err := NewOrchestratorFromConfig(context.Background(), SpecConfig{SpeechToText: "no-such-provider"}); err == nil ||
+		!strings.Contains(err.Error(), "llm: required") || !strings.Contains(err.Error(), ErrUnknownProvider.Error()) {
+		t.Fatalf("expected the missing LLM and the unknown provider to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/config"
+	_ "github.com/koscakluka/ema-core/core/llms/anthropic"
+	_ "github.com/koscakluka/ema-core/core/llms/groq"
+	_ "github.com/koscakluka/ema-core/core/llms/openai"
+	_ "github.com/koscakluka/ema-core/core/speechtotext/azure"
+	_ "github.com/koscakluka/ema-core/core/speechtotext/deepgram"
+	_ "github.com/koscakluka/ema-core/core/speechtotext/whisper"
+	_ "github.com/koscakluka/ema-core/core/texttospeech/azure"
+	_ "github.com/koscakluka/ema-core/core/texttospeech/cartesia"
+	_ "github.com/koscakluka/ema-core/core/texttospeech/deepgram"
+	_ "github.com/koscakluka/ema-core/core/texttospeech/openai"
+)
+
+func TestProviderPackagesRegisterThemselves(t *testing.T) {
+	registry := config.NewRegistry()
+	for _, llm := range []string{"groq", "openai", "anthropic"} {
+		err := registry.Validate(config.Config{
+			LLM:          &config.Provider{Name: llm},
+			SpeechToText: &config.Provider{Name: "deepgram"},
+			TextToSpeech: &config.Provider{Name: "deepgram"},
+		})
+		if err != nil {
+			t.Fatalf("expected imported providers to be registered, got %v", err)
+		}
+	}
+	for _, stt := range []string{"deepgram", "whisper", "azure"} {
+		err := registry.Validate(config.Config{
+			LLM:          &config.Provider{Name: "openai"},
+			SpeechToText: &config.Provider{Name: stt},
+		})
+		if err != nil {
+			t.Fatalf("expected imported providers to be registered, got %v", err)
+		}
+	}
+	for _, tts := range []string{"deepgram", "openai", "azure", "cartesia"} {
+		err := registry.Validate(config.Config{
+			LLM:          &config.Provider{Name: "openai"},
+			TextToSpeech: &config.Provider{Name: tts},
+		})
+		if err != nil {
+			t.Fatalf("expected imported providers to be registered, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+)
+
+type LLMFactory func(ctx context.Context, provider Provider) (orchestration.LLMWithStream, error)
+
+type SpeechToTextFactory func(ctx context.Context, provider Provider) (orchestration.SpeechToText, error)
+
+type TextToSpeechFactory func(ctx context.Context, provider Provider) (orchestration.TextToSpeechV1, error)
+
+type AudioInputFactory func(ctx context.Context, provider Provider) (orchestration.AudioInput, error)
+
+// AudioOutputFactory creates an [orchestration.AudioOutputV1] or an
+// [orchestration.AudioOutputV0].
+type AudioOutputFactory func(ctx context.Context, provider Provider) (any, error)
+
+// ErrUnknownProvider is the error of providers selected by a name nothing is
+// registered under.
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// Registry maps provider names of a [Config] to the factories creating them.
+// Providers are registered before building, the registry is not safe for
+// concurrent registration.
+type Registry struct {
+	llms         map[string]LLMFactory
+	speechToText map[string]SpeechToTextFactory
+	textToSpeech map[string]TextToSpeechFactory
+	audioInputs  map[string]AudioInputFactory
+	audioOutputs map[string]AudioOutputFactory
+}
+
+func newEmptyRegistry() *Registry {
+	return &Registry{
+		llms:         map[string]LLMFactory{},
+		speechToText: map[string]SpeechToTextFactory{},
+		textToSpeech: map[string]TextToSpeechFactory{},
+		audioInputs:  map[string]AudioInputFactory{},
+		audioOutputs: map[string]AudioOutputFactory{},
+	}
+}
+
+// NewRegistry returns a registry with the providers registered with the
+// package-level Register functions. The provider packages of this module
+// register themselves when imported, e.g. the "groq" and "openai" LLMs and
+// "deepgram" speech to text and text to speech:
+//
+//	import _ "github.com/koscakluka/ema-core/core/llms/groq"
+//
+// Audio devices depend on the platform and have to be registered.
+func NewRegistry() *Registry {
+	r := newEmptyRegistry()
+
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	maps.Copy(r.llms, registered.llms)
+	maps.Copy(r.speechToText, registered.speechToText)
+	maps.Copy(r.textToSpeech, registered.textToSpeech)
+	maps.Copy(r.audioInputs, registered.audioInputs)
+	maps.Copy(r.audioOutputs, registered.audioOutputs)
+	return r
+}
+
+var (
+	registeredMu sync.RWMutex
+	// registered are the providers registered by provider packages, copied
+	// into every new registry.
+	registered = newEmptyRegistry()
+)
+
+// RegisterLLM makes the LLM created by factory available under name to
+// every registry created afterwards, so provider packages can plug in by
+// registering from init:
+//
+//	func init() {
+//		config.RegisterLLM("mistral", newLLM)
+//	}
+//
+// The provider is then selected by importing its package, e.g. for side
+// effects only. RegisterLLM panics when name is already registered.
+func RegisterLLM(name string, factory LLMFactory) {
+	register(registered.llms, "llm", name, factory)
+}
+
+// RegisterSpeechToText registers a speech-to-text provider like
+// [RegisterLLM].
+func RegisterSpeechToText(name string, factory SpeechToTextFactory) {
+	register(registered.speechToText, "speech-to-text", name, factory)
+}
+
+// RegisterTextToSpeech registers a text-to-speech provider like
+// [RegisterLLM].
+func RegisterTextToSpeech(name string, factory TextToSpeechFactory) {
+	register(registered.textToSpeech, "text-to-speech", name, factory)
+}
+
+// RegisterAudioInput registers an audio input like [RegisterLLM].
+func RegisterAudioInput(name string, factory AudioInputFactory) {
+	register(registered.audioInputs, "audio input", name, factory)
+}
+
+// RegisterAudioOutput registers an audio output like [RegisterLLM].
+func RegisterAudioOutput(name string, factory AudioOutputFactory) {
+	register(registered.audioOutputs, "audio output", name, factory)
+}
+
+func register[F any](factories map[string]F, kind, name string, factory F) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	if _, ok := factories[name]; ok {
+		panic(fmt.Sprintf("config: %s provider %q registered twice", kind, name))
+	}
+	factories[name] = factory
+}
+
+// RegisterLLM registers factory under name, replacing a previous one.
+func (r *Registry) RegisterLLM(name string, factory LLMFactory) {
+	r.llms[name] = factory
+}
+
+// RegisterSpeechToText registers factory under name, replacing a previous
+// one.
+func (r *Registry) RegisterSpeechToText(name string, factory SpeechToTextFactory) {
+	r.speechToText[name] = factory
+}
+
+// RegisterTextToSpeech registers factory under name, replacing a previous
+// one.
+func (r *Registry) RegisterTextToSpeech(name string, factory TextToSpeechFactory) {
+	r.textToSpeech[name] = factory
+}
+
+// RegisterAudioInput registers factory under name, replacing a previous one.
+func (r *Registry) RegisterAudioInput(name string, factory AudioInputFactory) {
+	r.audioInputs[name] = factory
+}
+
+// RegisterAudioOutput registers factory under name, replacing a previous
+// one.
+func (r *Registry) RegisterAudioOutput(name string, factory AudioOutputFactory) {
+	r.audioOutputs[name] = factory
+}
+
+// Validate reports every problem of config at once, including providers
+// that are not registered, without creating any provider.
+func (r *Registry) Validate(config Config) error {
+	errs := config.validate()
+	for _, p := range config.providers() {
+		if p.provider.Name == "" {
+			continue
+		}
+
+		var registered bool
+		switch p.field {
+		case "llm", "budget.fallback":
+			_, registered = r.llms[p.provider.Name]
+		case "speech_to_text":
+			_, registered = r.speechToText[p.provider.Name]
+		case "text_to_speech":
+			_, registered = r.textToSpeech[p.provider.Name]
+		case "audio_input":
+			_, registered = r.audioInputs[p.provider.Name]
+		case "audio_output":
+			_, registered = r.audioOutputs[p.provider.Name]
+		}
+		if !registered {
+			errs = append(errs, fmt.Errorf("%s.provider: %w %q", p.field, ErrUnknownProvider, p.provider.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewOrchestrator validates config and creates an orchestrator with its
+// providers and policies, opts are applied after the config ones. Providers
+// that fail to create are reported together.
+func (r *Registry) NewOrchestrator(ctx context.Context, config Config, opts ...orchestration.OrchestratorOption) (*orchestration.Orchestrator, error) {
+	if err := r.Validate(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	configOpts, err := r.options(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create providers: %w", err)
+	}
+	return orchestration.NewOrchestratorE(append(configOpts, opts...)...)
+}
+
+// NewLLM creates the LLM selected by spec, see [ParseProvider], e.g.
+// "openai:gpt-4o", so callers like a session manager can pick the LLM of each
+// session from a string.
+func (r *Registry) NewLLM(ctx context.Context, spec string) (orchestration.LLMWithStream, error) {
+	provider, err := ParseProvider(spec)
+	if err != nil {
+		return nil, err
+	}
+	return create(ctx, r.llms, provider)
+}
+
+// NewSpeechToText creates the speech-to-text client selected by spec, e.g.
+// "deepgram", see [Registry.NewLLM].
+func (r *Registry) NewSpeechToText(ctx context.Context, spec string) (orchestration.SpeechToText, error) {
+	provider, err := ParseProvider(spec)
+	if err != nil {
+		return nil, err
+	}
+	return create(ctx, r.speechToText, provider)
+}
+
+// NewTextToSpeech creates the text-to-speech client selected by spec, e.g.
+// "deepgram:aura-2-asteria-en", see [Registry.NewLLM]. The part after the
+// colon is the voice.
+func (r *Registry) NewTextToSpeech(ctx context.Context, spec string) (orchestration.TextToSpeechV1, error) {
+	provider, err := ParseProvider(spec)
+	if err != nil {
+		return nil, err
+	}
+	provider.Voice, provider.Model = provider.Model, ""
+	return create(ctx, r.textToSpeech, provider)
+}
+
+func create[F ~func(context.Context, Provider) (T, error), T any](ctx context.Context, factories map[string]F, provider Provider) (T, error) {
+	factory, ok := factories[provider.Name]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w %q", ErrUnknownProvider, provider.Name)
+	}
+	return factory(ctx, provider)
+}
+
+func (r *Registry) options(ctx context.Context, config Config) ([]orchestration.OrchestratorOption, error) {
+	var opts []orchestration.OrchestratorOption
+	var errs []error
+
+	if llm, err := r.llms[config.LLM.Name](ctx, *config.LLM); err != nil {
+		errs = append(errs, fmt.Errorf("llm: %w", err))
+	} else {
+		opts = append(opts, orchestration.WithStreamingLLM(llm))
+	}
+
+	if config.SpeechToText != nil {
+		if client, err := r.speechToText[config.SpeechToText.Name](ctx, *config.SpeechToText); err != nil {
+			errs = append(errs, fmt.Errorf("speech_to_text: %w", err))
+		} else {
+			opts = append(opts, orchestration.WithSpeechToTextClient(client))
+		}
+	}
+
+	if config.TextToSpeech != nil {
+		if client, err := r.textToSpeech[config.TextToSpeech.Name](ctx, *config.TextToSpeech); err != nil {
+			errs = append(errs, fmt.Errorf("text_to_speech: %w", err))
+		} else {
+			opts = append(opts, orchestration.WithTextToSpeechClientV1(client))
+		}
+	}
+
+	if config.AudioInput != nil {
+		if client, err := r.audioInputs[config.AudioInput.Name](ctx, *config.AudioInput); err != nil {
+			errs = append(errs, fmt.Errorf("audio_input: %w", err))
+		} else {
+			opts = append(opts, orchestration.WithAudioInput(client))
+		}
+	}
+
+	if config.AudioOutput != nil {
+		client, err := r.audioOutputs[config.AudioOutput.Name](ctx, *config.AudioOutput)
+		switch client := client.(type) {
+		case nil:
+			if err == nil {
+				err = errors.New("provider created no audio output")
+			}
+			errs = append(errs, fmt.Errorf("audio_output: %w", err))
+		case orchestration.AudioOutputV1:
+			opts = append(opts, orchestration.WithAudioOutputV1(client))
+		case orchestration.AudioOutputV0:
+			opts = append(opts, orchestration.WithAudioOutputV0(client))
+		default:
+			errs = append(errs, fmt.Errorf("audio_output: %T is not an audio output", client))
+		}
+	}
+
+	if history := config.History; history != nil {
+		opts = append(opts, orchestration.WithHistoryLimit(
+			orchestration.WithHistoryMaxTurns(history.MaxTurns),
+			orchestration.WithHistoryMaxBytes(history.MaxBytes),
+		))
+	}
+
+	if budget := config.Budget; budget != nil {
+		var budgetOpts []orchestration.BudgetOption
+		if budget.Fallback != nil {
+			if fallback, err := r.llms[budget.Fallback.Name](ctx, *budget.Fallback); err != nil {
+				errs = append(errs, fmt.Errorf("budget.fallback: %w", err))
+			} else {
+				budgetOpts = append(budgetOpts, orchestration.WithBudgetFallback(fallback))
+			}
+		}
+		opts = append(opts, orchestration.WithBudget(budget.MaxTokens, budget.MaxCost, budget.MaxTurns, budgetOpts...))
+	}
+
+	return opts, errors.Join(errs...)
+}
+
+// NewOrchestrator creates an orchestrator from config with the providers of
+// [NewRegistry].
+func NewOrchestrator(ctx context.Context, config Config, opts ...orchestration.OrchestratorOption) (*orchestration.Orchestrator, error) {
+	return NewRegistry().NewOrchestrator(ctx, config, opts...)
+}
+
+// SpecConfig is the config of [NewOrchestratorFromConfig], selecting the
+// providers of an orchestrator by spec, see [ParseProvider], e.g. for
+// deployments picking them with flags or environment variables rather than a
+// config file. Empty specs leave the provider unconfigured.
+type SpecConfig struct {
+	// LLM selects the LLM, e.g. "openai:gpt-4o", it is required.
+	LLM string
+	// SpeechToText selects the speech-to-text client, e.g. "deepgram".
+	SpeechToText string
+	// TextToSpeech selects the text-to-speech client, the part after the
+	// colon is the voice, e.g. "deepgram:aura-2-asteria-en".
+	TextToSpeech string
+}
+
+// Config returns the [Config] selecting the providers of c.
+func (c SpecConfig) Config() (Config, error) {
+	var config Config
+	var errs []error
+	if c.LLM != "" {
+		if provider, err := ParseProvider(c.LLM); err != nil {
+			errs = append(errs, fmt.Errorf("llm: %w", err))
+		} else {
+			config.LLM = &provider
+		}
+	}
+	if c.SpeechToText != "" {
+		if provider, err := ParseProvider(c.SpeechToText); err != nil {
+			errs = append(errs, fmt.Errorf("speech_to_text: %w", err))
+		} else {
+			config.SpeechToText = &provider
+		}
+	}
+	if c.TextToSpeech != "" {
+		if provider, err := ParseProvider(c.TextToSpeech); err != nil {
+			errs = append(errs, fmt.Errorf("text_to_speech: %w", err))
+		} else {
+			provider.Voice, provider.Model = provider.Model, ""
+			config.TextToSpeech = &provider
+		}
+	}
+	return config, errors.Join(errs...)
+}
+
+// NewOrchestratorFromConfig creates an orchestrator with the providers
+// selected by config and registered in [NewRegistry], opts are applied after
+// the config ones:
+//
+//	o, err := config.NewOrchestratorFromConfig(ctx, config.SpecConfig{
+//		LLM:          os.Getenv("EMA_LLM"),
+//		TextToSpeech: "deepgram:aura-2-asteria-en",
+//	})
+func NewOrchestratorFromConfig(ctx context.Context, config SpecConfig, opts ...orchestration.OrchestratorOption) (*orchestration.Orchestrator, error) {
+	providers, err := config.Config()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return NewOrchestrator(ctx, providers, opts...)
+}
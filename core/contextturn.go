@@ -0,0 +1,36 @@
+package orchestration
+
+import "github.com/koscakluka/ema-core/core/triggers"
+
+type ContextOptions struct {
+	respond bool
+}
+
+type ContextOption func(*ContextOptions)
+
+// WithContextResponse makes the assistant respond to the context right away
+// in a turn of its own, e.g. to tell the user their payment went through.
+// The turn starts once the active turn, if any, ended.
+func WithContextResponse() ContextOption {
+	return func(o *ContextOptions) {
+		o.respond = true
+	}
+}
+
+// AddContext adds content from source, e.g. a tool finishing in the
+// background or a webhook, to the conversation as a turn of its own, with a
+// [triggers.ContextTrigger], so it reaches the LLM without being passed off
+// as something the user said.
+//
+// By default the context is only added to the history, nothing is spoken
+// and turns already in progress do not see it, see [WithContextResponse].
+func (o *Orchestrator) AddContext(source, content string, opts ...ContextOption) {
+	options := ContextOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	trigger := triggers.NewContextTrigger(source, content)
+	trigger.Respond = options.respond
+	o.ingestTrigger(trigger)
+}
@@ -0,0 +1,54 @@
+package orchestration
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestAddContextReachesTheLLMWithoutAResponse(t *testing.T) {
+	llm := &historyLLMStub{}
+	o := NewOrchestrator(WithLLM(llm))
+	t.Cleanup(o.Close)
+	var completed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Add(1)
+		}
+	}))
+
+	o.AddContext("payment webhook", "payment of 20 EUR confirmed")
+	history := o.conversation.History()
+	if len(history) != 1 || !history[0].IsFinalised || len(history[0].Responses) != 0 {
+		t.Fatalf("expected a finalised context turn without responses, got %+v", history)
+	}
+
+	o.SendPrompt("did my payment go through?")
+	waitForCondition(t, 2*time.Second, "prompted turn completed", func() bool { return completed.Load() == 1 })
+	llm.mu.Lock()
+	if len(llm.history) != 1 || len(llm.history[0]) != 1 {
+		llm.mu.Unlock()
+		t.Fatalf("expected only the prompted turn to reach the LLM with the context in its history, got %+v", llm.history)
+	}
+	added, ok := llm.history[0][0].Trigger.(triggers.ContextTrigger)
+	llm.mu.Unlock()
+	if !ok || added.Source != "payment webhook" || added.Content != "payment of 20 EUR confirmed" {
+		t.Fatalf("unexpected context turn %+v", added)
+	}
+
+	o.AddContext("", "the courier is two minutes away", WithContextResponse())
+	waitForCondition(t, 2*time.Second, "context turn completed", func() bool { return completed.Load() == 2 })
+	history = o.conversation.History()
+	if len(history) != 3 || len(history[2].Responses) == 0 {
+		t.Fatalf("expected the context to be responded to, got %+v", history)
+	}
+	if context, ok := history[2].Trigger.(triggers.ContextTrigger); !ok || !context.Respond {
+		t.Fatalf("unexpected responded context turn %+v", history[2])
+	}
+}
@@ -0,0 +1,41 @@
+package orchestration
+
+import (
+	"context"
+
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/llms/contextwindow"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithContextWindowPolicy fits the history passed to the LLM of each turn
+// into the context window of policy, together with the system prompt and
+// the trigger of the turn. The history of the conversation is kept as is,
+// see [WithHistoryLimit] to cap it.
+//
+// The policy is used by a single conversation, create one per orchestrator.
+func WithContextWindowPolicy(policy *contextwindow.Policy) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.contextWindow = policy
+	}
+}
+
+// fitContextWindow fits the history of req into the context window, if a
+// policy is set.
+func (o *Orchestrator) fitContextWindow(ctx context.Context, req TurnRequest) []llms.TurnV1 {
+	if o.contextWindow == nil {
+		return req.History
+	}
+
+	prompt := ""
+	if req.Trigger != nil {
+		prompt = req.Trigger.String()
+	}
+	history, err := o.contextWindow.Fit(ctx, req.History, req.SystemPrompt, prompt)
+	if err != nil {
+		errorreport.Record(ctx, trace.SpanFromContext(ctx), err, "component", "context_window")
+		o.logger.Warn("failed to fit the history into the context window", "error", err)
+	}
+	return history
+}
@@ -0,0 +1,60 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/llms/contextwindow"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestContextWindowPolicyFitsTheHistoryPassedToTheLLM(t *testing.T) {
+	var history []llms.TurnV1
+	for i := range 10 {
+		history = append(history, llms.TurnV1{
+			ID:        fmt.Sprintf("turn-%d", i),
+			Trigger:   triggers.NewUserPromptTrigger(strings.Repeat("a", 40)),
+			Responses: []llms.TurnResponseV0{{Message: strings.Repeat("b", 40)}},
+		})
+	}
+	summarizer := contextwindow.SummarizerFunc(func(context.Context, string, []llms.TurnV1) (string, error) {
+		return "The user asked about an order.", nil
+	})
+
+	llm := &historyLLMStub{}
+	var completed atomic.Bool
+	o := NewOrchestrator(
+		WithLLM(llm),
+		WithInitialHistory(history...),
+		WithContextWindowPolicy(contextwindow.NewPolicy(150, contextwindow.WithSummarizer(summarizer))),
+	)
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+	o.SendPrompt("any news?")
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	if len(llm.history) != 1 {
+		t.Fatalf("expected a single prompt, got %d", len(llm.history))
+	}
+	prompted := llm.history[0]
+	if len(prompted) < 2 || len(prompted) >= len(history) || prompted[0].ID != contextwindow.SummaryTurnID || prompted[len(prompted)-1].ID != "turn-9" {
+		t.Fatalf("expected a summary followed by the newest turns, got %+v", prompted)
+	}
+	if kept := o.conversation.History(); len(kept) != len(history)+1 {
+		t.Fatalf("expected the conversation history to be kept, got %d turns", len(kept))
+	}
+}
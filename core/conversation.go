@@ -4,11 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/koscakluka/ema-core/core/conversations"
 	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
 )
 
 var _ conversations.ActiveContextV0 = (*activeConversation)(nil)
@@ -25,6 +28,15 @@ type activeConversation struct {
 	activeTurn *activeTurn
 
 	availableTools func() []llms.Tool
+	// tokenUsage reports the token usage of the conversation with its
+	// history, see [TokenUsageV0].
+	tokenUsage func(history []llms.TurnV1) TokenUsageV0
+	// callInfo describes the call of the conversation, see [CallInfoV0].
+	callInfo *CallInfoV0
+	// closingSummary is written by [Orchestrator.EndConversation].
+	closingSummary string
+	// historyLimit caps turns, see [WithHistoryLimit]. Nil keeps all turns.
+	historyLimit *HistoryLimitOptions
 
 	// currentPipeline provides access to the active response pipeline.
 	//
@@ -46,6 +58,14 @@ type ConversationV1 struct {
 	History        []llms.TurnV1
 	ActiveTurn     *llms.TurnV1
 	AvailableTools []llms.Tool
+	// CallInfo describes the call carrying the conversation, if set, see
+	// [Orchestrator.SetCallInfo].
+	CallInfo *CallInfoV0
+	// ClosingSummary is the summary written once the conversation ended, see
+	// [WithClosingSummary].
+	ClosingSummary string
+	// TokenUsage is the running token usage of the conversation.
+	TokenUsage TokenUsageV0
 }
 
 func (t *activeConversation) Snapshot() ConversationV1 {
@@ -60,15 +80,27 @@ func (t *activeConversation) Snapshot() ConversationV1 {
 		activeTurn = &snapshot
 	}
 
+	var callInfo *CallInfoV0
+	if t.callInfo != nil {
+		info := t.callInfo.clone()
+		callInfo = &info
+	}
+
+	closingSummary := t.closingSummary
 	availableTools := t.availableTools
+	tokenUsage := t.tokenUsage
 	t.mu.RUnlock()
 
 	var tools []llms.Tool
 	if availableTools != nil {
 		tools = availableTools()
 	}
+	var usage TokenUsageV0
+	if tokenUsage != nil {
+		usage = tokenUsage(turns)
+	}
 
-	return ConversationV1{History: turns, ActiveTurn: activeTurn, AvailableTools: tools}
+	return ConversationV1{History: turns, ActiveTurn: activeTurn, AvailableTools: tools, CallInfo: callInfo, ClosingSummary: closingSummary, TokenUsage: usage}
 }
 
 func (t *activeConversation) History() []llms.TurnV1 {
@@ -103,6 +135,29 @@ func (t *activeConversation) AvailableTools() []llms.Tool {
 	return availableTools()
 }
 
+// labelTurn sets the outcome of the finalised turn id, it reports whether
+// there is one.
+func (t *activeConversation) labelTurn(id string, outcome llms.OutcomeV0) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, turn := range slices.Backward(t.turns) {
+		if turn.ID == id {
+			t.turns[i].Outcome = &outcome
+			return true
+		}
+	}
+	return false
+}
+
+// restoreHistory replaces the finalised turns of the conversation.
+func (t *activeConversation) restoreHistory(history []llms.TurnV1) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.turns = slices.Clone(history)
+}
+
 func (t *activeConversation) addInterruptionToActiveTurn(interruption llms.InterruptionV0) bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -115,6 +170,16 @@ func (t *activeConversation) addInterruptionToActiveTurn(interruption llms.Inter
 	return true
 }
 
+// replaceActiveTurnTrigger replaces the trigger turn was started with.
+func (t *activeConversation) replaceActiveTurnTrigger(turn *activeTurn, trigger llms.TriggerV0) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	turn.Trigger = trigger
+	turn.SpeakerID = speakerOf(trigger)
+	turn.InputModality = inputModalityOf(trigger)
+}
+
 func (t *activeConversation) startNewTurn(trigger llms.TriggerV0) (*activeTurn, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -146,6 +211,38 @@ func (t *activeConversation) finaliseTurn(finalisedTurn llms.TurnV1) error {
 	return nil
 }
 
+// appendHistory adds finalised turns to the history, before the active turn
+// if there is one.
+func (t *activeConversation) appendHistory(turns []llms.TurnV1) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.turns = append(t.turns, turns...)
+}
+
+// appendContextTurn adds a finalised turn without responses for trigger to
+// the history, before the active turn if there is one, and returns it.
+func (t *activeConversation) appendContextTurn(trigger triggers.ContextTrigger) llms.TurnV1 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	turn := newActiveTurn(trigger).TurnV1
+	turn.IsFinalised = true
+	t.turns = append(t.turns, turn)
+	return turn
+}
+
+// discardActiveTurn drops the active turn with id without adding it to the
+// history.
+func (t *activeConversation) discardActiveTurn(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.activeTurn != nil && t.activeTurn.TurnV1.ID == id {
+		t.activeTurn = nil
+	}
+}
+
 func (t *activeConversation) updateInterruption(id int64, update func(*llms.InterruptionV0)) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -171,18 +268,63 @@ type activeTurn struct {
 	llms.TurnV1
 
 	finalResponse *llms.TurnResponseV0
+	// queuedAt is when the trigger of the turn was queued.
+	queuedAt time.Time
 }
 
 func newActiveTurn(trigger llms.TriggerV0) *activeTurn {
 	return &activeTurn{
 		TurnV1: llms.TurnV1{
-			ID:      uuid.NewString(),
-			Trigger: trigger,
+			ID:            uuid.NewString(),
+			Trigger:       trigger,
+			SpeakerID:     speakerOf(trigger),
+			InputModality: inputModalityOf(trigger),
 		},
 		finalResponse: &llms.TurnResponseV0{},
 	}
 }
 
+// truncateToSpoken keeps only the part of the response the user heard
+// before the turn was interrupted, setting the rest aside.
+func (t *activeTurn) truncateToSpoken() {
+	response := t.finalResponse
+	if response == nil {
+		return
+	}
+	spoken, unspoken := splitSpoken(*response)
+	if unspoken == "" {
+		return
+	}
+
+	response.Message = spoken
+	response.UnspokenMessage = unspoken
+	response.IsSpoken = true
+	response.IsInterrupted = true
+}
+
+// splitSpoken splits the text of response into the part confirmed as played
+// and the rest.
+func splitSpoken(response llms.TurnResponseV0) (spoken, unspoken string) {
+	if response.TypedMessage == "" {
+		return response.SpokenResponse, ""
+	}
+
+	generated := response.Message
+	if generated == "" {
+		generated = response.TypedMessage
+	}
+	spoken = response.SpokenResponse
+	unspoken = generated
+	if strings.HasPrefix(generated, spoken) {
+		unspoken = generated[len(spoken):]
+	} else if strings.HasPrefix(response.TypedMessage, spoken) {
+		// The spoken text differs from the generated one, e.g. once
+		// translated, the rest is only known as it was typed.
+		unspoken = response.TypedMessage[len(spoken):]
+	}
+	return spoken, unspoken
+}
+
 func (t *activeTurn) Finalise() {
 	if t.IsFinalised {
 		return
@@ -0,0 +1,77 @@
+package conversations
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// MemoryStore is a [Store] keeping conversations in memory, for tests and
+// processes whose conversations only need to outlive their orchestrators.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	conversations map[string][]llms.TurnV1
+	order         []string
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{conversations: map[string][]llms.TurnV1{}}
+}
+
+func (s *MemoryStore) SaveTurn(ctx context.Context, conversationID string, turn llms.TurnV1) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	turns, ok := s.conversations[conversationID]
+	if !ok {
+		s.order = append(s.order, conversationID)
+	}
+	if i := slices.IndexFunc(turns, func(saved llms.TurnV1) bool {
+		if turn.ID == "" {
+			return saved.ID == "" && reflect.DeepEqual(saved, turn)
+		}
+		return saved.ID == turn.ID
+	}); i >= 0 {
+		turns[i] = turn
+		return nil
+	}
+	s.conversations[conversationID] = append(turns, turn)
+	return nil
+}
+
+func (s *MemoryStore) LoadConversation(ctx context.Context, conversationID string) ([]llms.TurnV1, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	turns, ok := s.conversations[conversationID]
+	if !ok {
+		return nil, ErrConversationNotFound
+	}
+	return slices.Clone(turns), nil
+}
+
+// DeleteConversation deletes the saved turns of the conversation with
+// conversationID, deleting a conversation without saved turns is a no-op.
+func (s *MemoryStore) DeleteConversation(ctx context.Context, conversationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.conversations[conversationID]; !ok {
+		return nil
+	}
+	delete(s.conversations, conversationID)
+	s.order = slices.DeleteFunc(s.order, func(id string) bool { return id == conversationID })
+	return nil
+}
+
+func (s *MemoryStore) ListConversations(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.order), nil
+}
@@ -0,0 +1,70 @@
+package conversations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+func TestMemoryStoreKeepsTurnsPerConversation(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	if _, err := store.LoadConversation(ctx, "a"); !errors.Is(err, ErrConversationNotFound) {
+		t.Fatalf("expected missing conversation error, got %v", err)
+	}
+
+	store.SaveTurn(ctx, "b", llms.TurnV1{ID: "1"})
+	store.SaveTurn(ctx, "a", llms.TurnV1{ID: "2"})
+	store.SaveTurn(ctx, "b", llms.TurnV1{ID: "3"})
+	store.SaveTurn(ctx, "b", llms.TurnV1{ID: "1", IsFinalised: true})
+
+	turns, err := store.LoadConversation(ctx, "b")
+	if err != nil {
+		t.Fatalf("unexpected load error: %v", err)
+	}
+	if len(turns) != 2 || turns[0].ID != "1" || !turns[0].IsFinalised || turns[1].ID != "3" {
+		t.Fatalf("expected turn 1 to be replaced in place, got %+v", turns)
+	}
+	ids, _ := store.ListConversations(ctx)
+	if len(ids) != 2 || ids[0] != "b" || ids[1] != "a" {
+		t.Fatalf("expected conversations in the order they were started, got %v", ids)
+	}
+}
+
+func TestMemoryStoreReplacesEqualTurnsWithoutIDs(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	store.SaveTurn(ctx, "a", llms.TurnV1{SpeakerID: "caller"})
+	store.SaveTurn(ctx, "a", llms.TurnV1{SpeakerID: "agent"})
+	store.SaveTurn(ctx, "a", llms.TurnV1{SpeakerID: "caller"})
+
+	turns, _ := store.LoadConversation(ctx, "a")
+	if len(turns) != 2 || turns[0].SpeakerID != "caller" || turns[1].SpeakerID != "agent" {
+		t.Fatalf("expected the equal turn to be saved once, got %+v", turns)
+	}
+}
+
+func TestMemoryStoreDeletesConversations(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	store.SaveTurn(ctx, "a", llms.TurnV1{ID: "1"})
+	store.SaveTurn(ctx, "b", llms.TurnV1{ID: "2"})
+	if err := store.DeleteConversation(ctx, "a"); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+	if err := store.DeleteConversation(ctx, "missing"); err != nil {
+		t.Fatalf("unexpected error deleting a missing conversation: %v", err)
+	}
+
+	if _, err := store.LoadConversation(ctx, "a"); !errors.Is(err, ErrConversationNotFound) {
+		t.Fatalf("expected the deleted conversation to be missing, got %v", err)
+	}
+	if ids, _ := store.ListConversations(ctx); len(ids) != 1 || ids[0] != "b" {
+		t.Fatalf("expected only conversation b to be listed, got %v", ids)
+	}
+}
@@ -0,0 +1,172 @@
+// Package sqlite provides a conversation store backed by a SQLite database.
+//
+// Each finalised turn is a row of the turns table, encoded as JSON in the
+// format of [orchestration.MarshalHistoryV0], so triggers must be from the
+// triggers package to be saved. Turns are keyed by their IDs, turns without
+// an ID by a hash of their encoding, so saving the same turn again replaces
+// it rather than adding a row.
+//
+// The package does not depend on a SQLite driver, the application opens the
+// database with its driver of choice, e.g. modernc.org/sqlite or
+// github.com/mattn/go-sqlite3. Upserts require SQLite 3.24 or newer.
+//
+//	db, err := sql.Open("sqlite", "conversations.db")
+//	store, err := sqlite.NewStore(ctx, db)
+//	o := orchestration.NewOrchestrator(orchestration.WithConversationStore(store, id))
+package sqlite
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/conversations"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+const defaultTable = "ema_conversation_turns"
+
+// Store is a [conversations.Store] backed by a SQLite database.
+type Store struct {
+	db    *sql.DB
+	table string
+}
+
+var _ conversations.Store = (*Store)(nil)
+
+type StoreOptions struct {
+	table string
+}
+
+type StoreOption func(*StoreOptions)
+
+// WithTable sets the name of the turns table, defaults to
+// "ema_conversation_turns". The name is used in statements as is, it must
+// not come from untrusted input.
+func WithTable(table string) StoreOption {
+	return func(o *StoreOptions) {
+		o.table = table
+	}
+}
+
+// NewStore creates a store on db, creating the turns table if needed.
+func NewStore(ctx context.Context, db *sql.DB, opts ...StoreOption) (*Store, error) {
+	options := StoreOptions{table: defaultTable}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if db == nil {
+		return nil, errors.New("sqlite store requires a database")
+	}
+	if options.table == "" {
+		return nil, errors.New("sqlite store requires a table name")
+	}
+
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	conversation_id TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	turn_id TEXT NOT NULL,
+	turn TEXT NOT NULL,
+	PRIMARY KEY (conversation_id, position),
+	UNIQUE (conversation_id, turn_id)
+)`, options.table)); err != nil {
+		return nil, fmt.Errorf("failed to create turns table: %w", err)
+	}
+
+	return &Store{db: db, table: options.table}, nil
+}
+
+func (s *Store) SaveTurn(ctx context.Context, conversationID string, turn llms.TurnV1) error {
+	encoded, err := orchestration.MarshalHistoryV0([]llms.TurnV1{turn})
+	if err != nil {
+		return fmt.Errorf("failed to encode turn: %w", err)
+	}
+	turnID := turn.ID
+	if turnID == "" {
+		// NULLs never conflict, ID-less turns get a key of their content
+		hash := sha256.Sum256(encoded)
+		turnID = "sha256:" + hex.EncodeToString(hash[:])
+	}
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %[1]s (conversation_id, position, turn_id, turn)
+VALUES (?1, (SELECT COALESCE(MAX(position), -1) + 1 FROM %[1]s WHERE conversation_id = ?1), ?2, ?3)
+ON CONFLICT (conversation_id, turn_id) DO UPDATE SET turn = excluded.turn`, s.table),
+		conversationID, turnID, string(encoded),
+	); err != nil {
+		return fmt.Errorf("failed to save turn: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) LoadConversation(ctx context.Context, conversationID string) ([]llms.TurnV1, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT turn FROM %s WHERE conversation_id = ? ORDER BY position`, s.table),
+		conversationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+	defer rows.Close()
+
+	var turns []llms.TurnV1
+	found := false
+	for rows.Next() {
+		found = true
+		var encoded string
+		if err := rows.Scan(&encoded); err != nil {
+			return nil, fmt.Errorf("failed to read turn: %w", err)
+		}
+		decoded, err := orchestration.UnmarshalHistoryV0([]byte(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode turn: %w", err)
+		}
+		turns = append(turns, decoded...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+	if !found {
+		return nil, conversations.ErrConversationNotFound
+	}
+	return turns, nil
+}
+
+// DeleteConversation deletes the saved turns of the conversation with
+// conversationID, deleting a conversation without saved turns is a no-op.
+func (s *Store) DeleteConversation(ctx context.Context, conversationID string) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		`DELETE FROM %s WHERE conversation_id = ?`, s.table),
+		conversationID,
+	); err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListConversations(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT conversation_id FROM %s GROUP BY conversation_id ORDER BY MIN(rowid)`, s.table),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to read conversation id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/conversations"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// Every connection to :memory: is a database of its own
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		t.Skipf("sqlite driver unavailable: %v", err)
+	}
+
+	store, err := NewStore(context.Background(), db)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	return store
+}
+
+func TestStoreRoundTripsTurns(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	if _, err := store.LoadConversation(ctx, "a"); !errors.Is(err, conversations.ErrConversationNotFound) {
+		t.Fatalf("expected missing conversation error, got %v", err)
+	}
+
+	saved := llms.TurnV1{
+		ID:            "turn-1",
+		Trigger:       triggers.NewTypedUserPromptTrigger("my order is late"),
+		InputModality: llms.InputModalityText,
+		Responses:     []llms.TurnResponseV0{{Message: "Let me check.", IsMessageFullyGenerated: true}},
+		IsFinalised:   true,
+	}
+	if err := store.SaveTurn(ctx, "a", saved); err != nil {
+		t.Fatalf("unexpected save error: %v", err)
+	}
+	if err := store.SaveTurn(ctx, "a", llms.TurnV1{ID: "turn-2"}); err != nil {
+		t.Fatalf("unexpected save error: %v", err)
+	}
+
+	turns, err := store.LoadConversation(ctx, "a")
+	if err != nil {
+		t.Fatalf("unexpected load error: %v", err)
+	}
+	if len(turns) != 2 || turns[0].ID != "turn-1" || turns[1].ID != "turn-2" {
+		t.Fatalf("expected both turns in order, got %+v", turns)
+	}
+	if turns[0].Trigger == nil || turns[0].Trigger.String() != "my order is late" ||
+		turns[0].InputModality != llms.InputModalityText ||
+		len(turns[0].Responses) != 1 || turns[0].Responses[0].Message != "Let me check." ||
+		!turns[0].IsFinalised {
+		t.Fatalf("expected the turn to round-trip, got %+v", turns[0])
+	}
+}
+
+func TestStoreReplacesSavedTurns(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	store.SaveTurn(ctx, "b", llms.TurnV1{ID: "1"})
+	store.SaveTurn(ctx, "a", llms.TurnV1{ID: "2"})
+	store.SaveTurn(ctx, "b", llms.TurnV1{SpeakerID: "caller"})
+	store.SaveTurn(ctx, "b", llms.TurnV1{ID: "3"})
+	store.SaveTurn(ctx, "b", llms.TurnV1{ID: "1", IsFinalised: true})
+	store.SaveTurn(ctx, "b", llms.TurnV1{SpeakerID: "caller"})
+
+	turns, err := store.LoadConversation(ctx, "b")
+	if err != nil {
+		t.Fatalf("unexpected load error: %v", err)
+	}
+	if len(turns) != 3 || turns[0].ID != "1" || !turns[0].IsFinalised ||
+		turns[1].ID != "" || turns[1].SpeakerID != "caller" || turns[2].ID != "3" {
+		t.Fatalf("expected saved turns to be replaced in place, got %+v", turns)
+	}
+	ids, err := store.ListConversations(ctx)
+	if err != nil {
+		t.Fatalf("unexpected list error: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "b" || ids[1] != "a" {
+		t.Fatalf("expected conversations in the order they were started, got %v", ids)
+	}
+}
+
+func TestStoreDeletesConversations(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	store.SaveTurn(ctx, "a", llms.TurnV1{ID: "1"})
+	store.SaveTurn(ctx, "b", llms.TurnV1{ID: "1"})
+	if err := store.DeleteConversation(ctx, "a"); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+
+	if _, err := store.LoadConversation(ctx, "a"); !errors.Is(err, conversations.ErrConversationNotFound) {
+		t.Fatalf("expected the deleted conversation to be missing, got %v", err)
+	}
+	if turns, err := store.LoadConversation(ctx, "b"); err != nil || len(turns) != 1 {
+		t.Fatalf("expected conversation b to be kept, got %+v, %v", turns, err)
+	}
+
+	store.SaveTurn(ctx, "a", llms.TurnV1{ID: "2"})
+	if turns, err := store.LoadConversation(ctx, "a"); err != nil || len(turns) != 1 || turns[0].ID != "2" {
+		t.Fatalf("expected a deleted conversation to start over, got %+v, %v", turns, err)
+	}
+}
@@ -0,0 +1,28 @@
+package conversations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// ErrConversationNotFound is the error of loading a conversation without
+// saved turns.
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// Store persists the finalised turns of conversations, so a conversation can
+// be continued after a restart of the process running it.
+type Store interface {
+	// SaveTurn saves turn as the latest turn of the conversation with
+	// conversationID, replacing a saved turn with the same ID in place.
+	// Turns without an ID replace an equal saved turn without an ID.
+	SaveTurn(ctx context.Context, conversationID string, turn llms.TurnV1) error
+	// LoadConversation loads the saved turns of the conversation with
+	// conversationID, oldest first. It fails with [ErrConversationNotFound]
+	// if none were saved.
+	LoadConversation(ctx context.Context, conversationID string) ([]llms.TurnV1, error)
+	// ListConversations lists the IDs of the saved conversations, in the
+	// order their first turns were saved.
+	ListConversations(ctx context.Context) ([]string, error)
+}
@@ -0,0 +1,72 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/koscakluka/ema-core/core/conversations"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"github.com/koscakluka/ema-core/core/llms"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// conversationStore persists the turns of the conversation, see
+// [WithConversationStore].
+type conversationStore struct {
+	store          conversations.Store
+	conversationID string
+}
+
+// WithConversationStore persists the finalised turns of the conversation
+// with conversationID to store and continues the conversation from the
+// turns saved earlier, e.g. by an orchestrator of a process that was
+// restarted. See [conversations.MemoryStore] and the conversations/sqlite
+// package for stores.
+//
+// The saved turns are loaded when [Orchestrator.Orchestrate] is called, which
+// fails if they cannot be loaded. Turns that fail to be saved are logged and
+// reported, the conversation goes on.
+//
+// It cannot be combined with [WithSessionStateV0] or [WithInitialHistory],
+// [NewOrchestratorE] rejects the combination.
+func WithConversationStore(store conversations.Store, conversationID string) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.validation.restoreHistory("WithConversationStore")
+		if isNilClient(store) {
+			o.validation.errs = append(o.validation.errs, fmt.Errorf("WithConversationStore: %w", ErrNilClient))
+			return
+		}
+		o.conversationStore = &conversationStore{store: store, conversationID: conversationID}
+	}
+}
+
+// loadStoredConversation restores the history saved to the conversation
+// store, if any.
+func (o *Orchestrator) loadStoredConversation(ctx context.Context) error {
+	if o.conversationStore == nil {
+		return nil
+	}
+
+	turns, err := o.conversationStore.store.LoadConversation(ctx, o.conversationStore.conversationID)
+	if errors.Is(err, conversations.ErrConversationNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to load conversation %s: %w", o.conversationStore.conversationID, err)
+	}
+	o.conversation.restoreHistory(turns)
+	return nil
+}
+
+// saveTurn saves a finalised turn to the conversation store, if any.
+func (o *Orchestrator) saveTurn(ctx context.Context, turn llms.TurnV1) {
+	if o.conversationStore == nil {
+		return
+	}
+
+	if err := o.conversationStore.store.SaveTurn(ctx, o.conversationStore.conversationID, turn); err != nil {
+		recordedErr := fmt.Errorf("failed to save turn %s: %w", turn.ID, err)
+		errorreport.Record(ctx, trace.SpanFromContext(ctx), recordedErr, "component", "conversation_store")
+		o.logger.Warn("failed to save turn", "turn_id", turn.ID, "error", err)
+	}
+}
@@ -0,0 +1,82 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/conversations"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestConversationStoreContinuesConversationAfterRestart(t *testing.T) {
+	store := conversations.NewMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var completed atomic.Int32
+	first := NewOrchestrator(WithLLM(&recordingPromptLLMStub{}), WithConversationStore(store, "call-1"))
+	if err := first.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Add(1)
+		}
+	})); err != nil {
+		t.Fatalf("unexpected orchestrate error: %v", err)
+	}
+	first.SendPrompt("hello")
+	waitForCondition(t, 2*time.Second, "turn completed", func() bool { return completed.Load() == 1 })
+	first.triggerPlayer.Load().Ingest(triggers.NewContextTrigger("crm", "Caller is a premium customer."))
+	waitForCondition(t, 2*time.Second, "context turn saved", func() bool {
+		turns, _ := store.LoadConversation(ctx, "call-1")
+		return len(turns) == 2
+	})
+	first.Close()
+
+	second := NewOrchestrator(WithLLM(&recordingPromptLLMStub{}), WithConversationStore(store, "call-1"))
+	t.Cleanup(second.Close)
+	if history := second.ConversationV1().History; len(history) != 0 {
+		t.Fatalf("expected the history to be loaded once orchestrated, got %+v", history)
+	}
+	if err := second.Orchestrate(ctx); err != nil {
+		t.Fatalf("unexpected orchestrate error: %v", err)
+	}
+	history := second.ConversationV1().History
+	if len(history) != 2 || history[0].Trigger.String() != "hello" || history[0].Responses[0].Message != "echo: hello" {
+		t.Fatalf("expected the saved turns to be restored, got %+v", history)
+	}
+	if ids, _ := store.ListConversations(ctx); len(ids) != 1 || ids[0] != "call-1" {
+		t.Fatalf("expected a single saved conversation, got %v", ids)
+	}
+}
+
+func TestConversationStoreFailuresAreReported(t *testing.T) {
+	if _, err := NewOrchestratorE(
+		WithInitialHistory(llms.TurnV1{Trigger: triggers.NewUserPromptTrigger("hello")}),
+		WithConversationStore(conversations.NewMemoryStore(), "call-1"),
+	); err == nil {
+		t.Fatalf("expected restored history to conflict with the conversation store")
+	}
+
+	loadErr := errors.New("database unavailable")
+	o := NewOrchestrator(WithConversationStore(failingConversationStore{err: loadErr}, "call-1"))
+	t.Cleanup(o.Close)
+	if err := o.Orchestrate(context.Background()); !errors.Is(err, loadErr) {
+		t.Fatalf("expected the load error, got %v", err)
+	}
+	if o.triggerPlayer.Load().CanIngest() {
+		t.Fatalf("expected the orchestrator to be closed")
+	}
+}
+
+type failingConversationStore struct {
+	conversations.Store
+	err error
+}
+
+func (s failingConversationStore) LoadConversation(context.Context, string) ([]llms.TurnV1, error) {
+	return nil, s.err
+}
@@ -0,0 +1,75 @@
+// Package credentials provides secrets, such as provider API keys, to
+// provider clients.
+//
+// Provider clients resolve their credentials by name each time they open a
+// connection or send a request, so keys can differ per tenant, be rotated
+// without restarting, or come from a secret manager. By default credentials
+// are read from environment variables of the same name, e.g.
+// DEEPGRAM_API_KEY.
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrNotFound = errors.New("credential not found")
+
+// Credentials resolves credentials by name.
+type Credentials interface {
+	// Credential returns the credential called name, or an error wrapping
+	// [ErrNotFound] if it does not exist.
+	Credential(ctx context.Context, name string) (string, error)
+}
+
+// Default returns the credentials used by provider clients when none are
+// configured, i.e. [Env].
+func Default() Credentials { return Env{} }
+
+// Env reads credentials from environment variables.
+type Env struct{}
+
+func (Env) Credential(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: environment variable %s not set", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// Static serves credentials from a fixed map.
+type Static map[string]string
+
+func (s Static) Credential(_ context.Context, name string) (string, error) {
+	value, ok := s[name]
+	if !ok || value == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// Func adapts a function to [Credentials], e.g. to query a secret manager.
+type Func func(ctx context.Context, name string) (string, error)
+
+func (f Func) Credential(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// Chain tries each of the credentials in order and returns the first one
+// found.
+func Chain(sources ...Credentials) Credentials {
+	return Func(func(ctx context.Context, name string) (string, error) {
+		for _, source := range sources {
+			value, err := source.Credential(ctx, name)
+			if err == nil {
+				return value, nil
+			}
+			if !errors.Is(err, ErrNotFound) {
+				return "", err
+			}
+		}
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	})
+}
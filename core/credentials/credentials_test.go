@@ -0,0 +1,28 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChainFallsBackOnlyWhenNotFound(t *testing.T) {
+	t.Setenv("EMA_TEST_KEY", "from-env")
+	chain := Chain(Static{"OTHER_KEY": "static"}, Env{})
+
+	value, err := chain.Credential(context.Background(), "EMA_TEST_KEY")
+	if err != nil || value != "from-env" {
+		t.Fatalf("expected env fallback, got %q, %v", value, err)
+	}
+
+	if _, err := chain.Credential(context.Background(), "EMA_MISSING_KEY"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	failing := Chain(Func(func(context.Context, string) (string, error) {
+		return "", errors.New("secret manager unavailable")
+	}), Env{})
+	if _, err := failing.Credential(context.Background(), "EMA_TEST_KEY"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected lookup failure to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package orchestration
+
+import (
+	"sync"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// speechDegradation tracks the speech components that failed. A failing
+// component degrades its turn to text-only, every following turn tries the
+// component again and reports when it recovered.
+type speechDegradation struct {
+	mu     sync.Mutex
+	failed map[Component]bool
+}
+
+// fail records component failing and reports whether it was working before.
+func (d *speechDegradation) fail(component Component) bool {
+	if d == nil {
+		return true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.failed[component] {
+		return false
+	}
+	if d.failed == nil {
+		d.failed = map[Component]bool{}
+	}
+	d.failed[component] = true
+	return true
+}
+
+// recover records component working and reports whether it failed before.
+func (d *speechDegradation) recover(component Component) bool {
+	if d == nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.failed[component] {
+		return false
+	}
+	delete(d.failed, component)
+	return true
+}
+
+// degradeToTextOnly continues the turn without speech after component
+// failed.
+func (p *responsePipeline) degradeToTextOnly(component Component, err error) {
+	p.textOnly.Store(true)
+	if p.degradation.fail(component) {
+		p.emitEvent(events.NewDegradedToTextOnly(p.state.TurnID(), string(component), err.Error()))
+	}
+}
+
+func (p *responsePipeline) speechRecovered(component Component) {
+	if p.degradation.recover(component) {
+		p.emitEvent(events.NewDegradationRecovered(p.state.TurnID(), string(component)))
+	}
+}
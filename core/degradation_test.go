@@ -0,0 +1,112 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+type flakyTTSStub struct {
+	bridgeTTSV1Stub
+	failing atomic.Bool
+}
+
+func (stub *flakyTTSStub) NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
+	if stub.failing.Load() {
+		return nil, errors.New("tts unavailable")
+	}
+	return stub.bridgeTTSV1Stub.NewSpeechGeneratorV0(ctx, opts...)
+}
+
+type flakyAudioOutputStub struct {
+	bridgeAudioOutputStub
+	failing atomic.Bool
+}
+
+func (output *flakyAudioOutputStub) SendAudio(audio []byte) error {
+	if output.failing.Load() {
+		return errors.New("device unplugged")
+	}
+	return output.bridgeAudioOutputStub.SendAudio(audio)
+}
+
+// runDegradedTurns runs a turn per recovery flag, setting failing to !recovered
+// before each, and returns the degradation events.
+func runDegradedTurns(t *testing.T, failing *atomic.Bool, recovered []bool, opts ...OrchestratorOption) (*Orchestrator, []events.Event) {
+	t.Helper()
+	o := NewOrchestrator(append([]OrchestratorOption{WithLLM(promptLLMStub{response: "Hello there."})}, opts...)...)
+	t.Cleanup(o.Close)
+
+	var mu sync.Mutex
+	var degradationEvents []events.Event
+	var completed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		switch event.(type) {
+		case events.DegradedToTextOnly, events.DegradationRecovered:
+			mu.Lock()
+			degradationEvents = append(degradationEvents, event)
+			mu.Unlock()
+		case events.TurnCompleted:
+			completed.Add(1)
+		}
+	}))
+
+	for i, recovered := range recovered {
+		failing.Store(!recovered)
+		o.SendPrompt("prompt")
+		waitForCondition(t, 2*time.Second, "turn completed", func() bool { return completed.Load() == int32(i+1) })
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return o, degradationEvents
+}
+
+func TestTextToSpeechFailureDegradesToTextOnly(t *testing.T) {
+	tts := &flakyTTSStub{}
+	o, degradationEvents := runDegradedTurns(t, &tts.failing, []bool{false, false, true},
+		WithTextToSpeechClientV1(tts),
+		WithAudioOutputV1(&bridgeAudioOutputStub{}),
+	)
+
+	if len(degradationEvents) != 2 {
+		t.Fatalf("expected a degradation and a recovery event, got %+v", degradationEvents)
+	}
+	if degraded, ok := degradationEvents[0].(events.DegradedToTextOnly); !ok || degraded.Component != string(ComponentTextToSpeech) {
+		t.Fatalf("expected text to speech degradation, got %+v", degradationEvents[0])
+	}
+	if recovered, ok := degradationEvents[1].(events.DegradationRecovered); !ok || recovered.Component != string(ComponentTextToSpeech) {
+		t.Fatalf("expected text to speech recovery, got %+v", degradationEvents[1])
+	}
+	for _, turn := range o.conversation.History() {
+		if len(turn.Responses) == 0 || turn.Responses[0].Message != "Hello there." {
+			t.Fatalf("expected text-only turns to keep the response, got %+v", turn)
+		}
+	}
+}
+
+func TestAudioOutputFailureDegradesToTextOnly(t *testing.T) {
+	output := &flakyAudioOutputStub{}
+	_, degradationEvents := runDegradedTurns(t, &output.failing, []bool{false, true},
+		WithTextToSpeechClientV1(&bridgeTTSV1Stub{}),
+		WithAudioOutputV1(output),
+	)
+
+	if len(degradationEvents) != 2 {
+		t.Fatalf("expected a degradation and a recovery event, got %+v", degradationEvents)
+	}
+	if degraded, ok := degradationEvents[0].(events.DegradedToTextOnly); !ok || degraded.Component != string(ComponentAudioOutput) {
+		t.Fatalf("expected audio output degradation, got %+v", degradationEvents[0])
+	}
+	if _, ok := degradationEvents[1].(events.DegradationRecovered); !ok {
+		t.Fatalf("expected audio output recovery, got %+v", degradationEvents[1])
+	}
+}
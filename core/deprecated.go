@@ -3,10 +3,10 @@ package orchestration
 import (
 	"context"
 	"fmt"
-	"log"
 	"slices"
 
 	emaContext "github.com/koscakluka/ema-core/core/context"
+	"github.com/koscakluka/ema-core/core/errorreport"
 	"github.com/koscakluka/ema-core/core/interruptions"
 	"github.com/koscakluka/ema-core/core/llms"
 	"github.com/koscakluka/ema-core/core/triggers"
@@ -70,6 +70,7 @@ type Config struct {
 // Deprecated: (since v0.0.13) use WithStreamingLLM instead
 func WithLLM(client LLMWithPrompt) OrchestratorOption {
 	return func(o *Orchestrator) {
+		o.validation.configure("WithLLM", ComponentLLM, client)
 		o.llm.set(client)
 	}
 }
@@ -87,6 +88,7 @@ type LLMWithPrompt interface {
 // Deprecated: (since v0.0.13) use WithAudioOutputV0 instead, we want to free up this option
 func WithAudioOutput(client AudioOutputV0) OrchestratorOption {
 	return func(o *Orchestrator) {
+		o.validation.configure("WithAudioOutput", ComponentAudioOutput, client)
 		o.audioOutput.Set(client)
 	}
 }
@@ -98,8 +100,8 @@ func WithAudioOutput(client AudioOutputV0) OrchestratorOption {
 // Deprecated: (since v0.0.16)
 func (o *Orchestrator) QueuePrompt(prompt string) {
 	go func() {
-		if ok := o.triggerPlayer.Ingest(triggers.NewUserPromptTrigger(prompt)); !ok {
-			log.Printf("Warning: failed to queue prompt")
+		if ok := o.triggerPlayer.Load().Ingest(triggers.NewUserPromptTrigger(prompt)); !ok {
+			o.logger.Warn("failed to queue prompt")
 		}
 	}()
 }
@@ -462,7 +464,7 @@ func (o *Orchestrator) SetAlwaysRecording(isAlwaysRecording bool) {
 	if err != nil {
 		recordedErr := fmt.Errorf("failed to set always recording to %t: %w", isAlwaysRecording, err)
 		span := trace.SpanFromContext(o.baseContext)
-		span.RecordError(recordedErr)
+		errorreport.Record(o.baseContext, span, recordedErr, "component", string(ComponentAudioInput))
 		span.SetStatus(codes.Error, recordedErr.Error())
 	}
 }
@@ -0,0 +1,46 @@
+package orchestration
+
+import "fmt"
+
+type DryRunOptions struct {
+	mockResponses map[string]string
+}
+
+type DryRunOption func(*DryRunOptions)
+
+// WithMockToolResponse declares the response the tool name returns in dry
+// runs instead of executing.
+func WithMockToolResponse(name, response string) DryRunOption {
+	return func(o *DryRunOptions) {
+		if o.mockResponses == nil {
+			o.mockResponses = map[string]string{}
+		}
+		o.mockResponses[name] = response
+	}
+}
+
+// WithDryRun runs turns without side effects, e.g. to evaluate prompts and
+// toolsets against recorded traffic before going to production.
+//
+// The LLM generates responses and selects tools as usual, but tools are not
+// executed: a call returns the response declared with
+// [WithMockToolResponse], or a note that the tool was not executed. Speech is
+// generated but its audio is not sent to the audio output, whose marks are
+// confirmed right away. All events are emitted as in a live conversation.
+func WithDryRun(opts ...DryRunOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		options := DryRunOptions{}
+		for _, opt := range opts {
+			opt(&options)
+		}
+		o.llm.dryRun = &options
+		o.audioOutput.dryRun = true
+	}
+}
+
+func (o *DryRunOptions) mockResponse(toolName string) string {
+	if response, ok := o.mockResponses[toolName]; ok {
+		return response
+	}
+	return fmt.Sprintf("Dry run: %s was not executed and has no mock response.", toolName)
+}
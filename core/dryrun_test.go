@@ -0,0 +1,72 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+func TestDryRunMocksToolsAndSuppressesAudio(t *testing.T) {
+	var executed atomic.Bool
+	output := &bridgeAudioOutputStub{}
+	o := NewOrchestrator(
+		WithStreamingLLM(&toolCallingLLMStub{toolCalls: []llms.ToolCall{{ID: "call-1", Name: "refund", Arguments: "{}"}}}),
+		WithTools(llms.NewTool("refund", "refunds the order", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+			executed.Store(true)
+			return "refunded", nil
+		})),
+		WithTextToSpeechClientV1(&bridgeTTSV1Stub{}),
+		WithAudioOutputV1(output),
+		WithDryRun(WithMockToolResponse("refund", "refund scheduled")),
+	)
+	t.Cleanup(o.Close)
+
+	var mu sync.Mutex
+	var toolResponse string
+	var playbackEnded, completed bool
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch event := event.(type) {
+		case events.ToolCallCompleted:
+			toolResponse = event.Response
+		case events.AssistantPlaybackEnded:
+			playbackEnded = true
+		case events.TurnCompleted:
+			completed = true
+		}
+	}))
+
+	o.SendPrompt("refund my order")
+	waitForCondition(t, 2*time.Second, "turn completed", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return completed
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if executed.Load() {
+		t.Fatalf("expected the tool not to be executed in a dry run")
+	}
+	if toolResponse != "refund scheduled" {
+		t.Fatalf("expected the mock tool response, got %q", toolResponse)
+	}
+	if !playbackEnded {
+		t.Fatalf("expected playback events to be emitted in a dry run")
+	}
+	chunks := output.nonEmptyAudioChunks()
+	output.mu.Lock()
+	marks := output.markCount
+	output.mu.Unlock()
+	if chunks != 0 || marks != 0 {
+		t.Fatalf("expected no audio or marks sent to the output, got %d chunks and %d marks", chunks, marks)
+	}
+}
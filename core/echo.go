@@ -0,0 +1,35 @@
+package orchestration
+
+import (
+	"strings"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// WithEchoSuppression drops transcripts of speech that started while the
+// assistant was playing audio if they have fewer than minWords words, they
+// are likely the assistant hearing itself on devices without echo
+// cancellation rather than the user barging in.
+//
+// Input events and triggers of such speech are marked with DuringPlayback
+// regardless, so custom trigger handlers can apply their own rules.
+func WithEchoSuppression(minWords int) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.echoMinWords = minWords
+	}
+}
+
+// isPlayingAudio reports whether the assistant is playing the audio of a
+// response.
+func (o *Orchestrator) isPlayingAudio() bool {
+	pipeline := o.currentResponsePipeline()
+	return pipeline != nil && pipeline.state.Current() == TurnStateSpeaking
+}
+
+// isLikelyEcho reports whether trigger is too short a transcript of speech
+// during playback to be handled, see [WithEchoSuppression].
+func (o *Orchestrator) isLikelyEcho(trigger llms.TriggerV0) bool {
+	transcription, ok := trigger.(triggers.TranscriptionTrigger)
+	return ok && transcription.DuringPlayback && len(strings.Fields(transcription.Transcript())) < o.echoMinWords
+}
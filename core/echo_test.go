@@ -0,0 +1,49 @@
+package orchestration
+
+import (
+	"testing"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestEchoSuppressionFlagsInputDuringPlayback(t *testing.T) {
+	o := NewOrchestrator(WithEchoSuppression(3))
+	t.Cleanup(o.Close)
+
+	var emitted []events.Event
+	emitEvent := func(event events.Event) { emitted = append(emitted, event) }
+	o.composeAudioInputEventEmitter(emitEvent)(events.NewUserAudioFrame([]byte{1, 2}))
+
+	pipeline := &responsePipeline{state: newTurnStateMachine(nil)}
+	pipeline.state.Transition(TurnStateGenerating)
+	pipeline.state.Transition(TurnStateSpeaking)
+	o.responsePipeline.Store(pipeline)
+	defer o.responsePipeline.Store(nil)
+	o.composeAudioInputEventEmitter(emitEvent)(events.NewUserAudioFrame([]byte{3, 4}))
+	o.composeSTTEventEmitter(emitEvent)(events.NewUserSpeechStarted())
+
+	if len(emitted) != 3 {
+		t.Fatalf("expected 3 events, got %+v", emitted)
+	}
+	if frame := emitted[0].(events.UserAudioFrame); frame.DuringPlayback {
+		t.Fatalf("expected audio before playback not to be flagged")
+	}
+	if frame := emitted[1].(events.UserAudioFrame); !frame.DuringPlayback {
+		t.Fatalf("expected audio during playback to be flagged")
+	}
+	if started := emitted[2].(events.UserSpeechStarted); !started.DuringPlayback || !o.speechDuringPlayback.Load() {
+		t.Fatalf("expected speech started during playback to be flagged")
+	}
+
+	echo := triggers.NewTranscriptionTrigger("okay sure")
+	echo.DuringPlayback = true
+	bargeIn := triggers.NewTranscriptionTrigger("no wait, that is wrong")
+	bargeIn.DuringPlayback = true
+	if !o.isLikelyEcho(echo) {
+		t.Fatalf("expected a short transcript during playback to be dropped")
+	}
+	if o.isLikelyEcho(bargeIn) || o.isLikelyEcho(triggers.NewTranscriptionTrigger("okay sure")) {
+		t.Fatalf("expected long transcripts and transcripts outside playback to be handled")
+	}
+}
@@ -0,0 +1,105 @@
+// Package errorreport passes errors that are otherwise only recorded on
+// spans to a reporter carried by the context, so error trackers such as
+// Sentry or Rollbar can be integrated without an OpenTelemetry backend.
+//
+//	ctx = errorreport.NewContext(ctx, func(ctx context.Context, err error, metadata map[string]string) {
+//		sentry.CaptureException(err)
+//	})
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Reporter receives reported errors with the metadata of where they
+// happened, e.g. the component and the turn. It is called synchronously
+// from the failing goroutine and should not block.
+type Reporter func(ctx context.Context, err error, metadata map[string]string)
+
+type reporterKey struct{}
+
+type metadataKey struct{}
+
+// NewContext returns a copy of ctx errors are reported from to reporter.
+func NewContext(ctx context.Context, reporter Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, reporter)
+}
+
+// FromContext returns the reporter of ctx, nil if it has none.
+func FromContext(ctx context.Context) Reporter {
+	if ctx == nil {
+		return nil
+	}
+	reporter, _ := ctx.Value(reporterKey{}).(Reporter)
+	return reporter
+}
+
+// WithMetadata returns a copy of ctx that adds metadata, alternating keys and
+// values, to every error reported from it.
+func WithMetadata(ctx context.Context, keyValues ...string) context.Context {
+	metadata := maps.Clone(metadataFromContext(ctx))
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	addKeyValues(metadata, keyValues)
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// Record records err on span and reports it with the metadata of ctx and
+// keyValues, alternating keys and values.
+func Record(ctx context.Context, span trace.Span, err error, keyValues ...string) {
+	if err == nil {
+		return
+	}
+	if span != nil {
+		span.RecordError(err)
+	}
+
+	reporter := FromContext(ctx)
+	if reporter == nil {
+		return
+	}
+	metadata := maps.Clone(metadataFromContext(ctx))
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	addKeyValues(metadata, keyValues)
+	reporter(ctx, err, metadata)
+}
+
+// RecoverPanic recovers a panic of the calling goroutine and reports it like
+// [ReportPanic]. It has to be deferred directly:
+//
+//	defer errorreport.RecoverPanic(ctx, "read loop")
+func RecoverPanic(ctx context.Context, worker string, keyValues ...string) {
+	if recovered := recover(); recovered != nil {
+		ReportPanic(ctx, worker, recovered, debug.Stack(), keyValues...)
+	}
+}
+
+// ReportPanic reports a panic recovered in worker with the "worker" name and
+// the "stack" trace in the metadata, and records it on the span of ctx.
+func ReportPanic(ctx context.Context, worker string, recovered any, stack []byte, keyValues ...string) {
+	err := fmt.Errorf("%s panicked: %v", worker, recovered)
+	keyValues = append([]string{"worker", worker, "stack", string(stack)}, keyValues...)
+	Record(ctx, trace.SpanFromContext(ctx), err, keyValues...)
+}
+
+func metadataFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return metadata
+}
+
+func addKeyValues(metadata map[string]string, keyValues []string) {
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		metadata[keyValues[i]] = keyValues[i+1]
+	}
+}
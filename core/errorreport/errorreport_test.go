@@ -0,0 +1,52 @@
+package errorreport
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRecordReportsWithMetadata(t *testing.T) {
+	var reported error
+	var reportedMetadata map[string]string
+	ctx := NewContext(context.Background(), func(_ context.Context, err error, metadata map[string]string) {
+		reported = err
+		reportedMetadata = metadata
+	})
+	ctx = WithMetadata(ctx, "turn_id", "t1")
+	WithMetadata(ctx, "turn_id", "other")
+
+	err := errors.New("boom")
+	Record(ctx, nil, err, "component", "llm")
+
+	if reported != err {
+		t.Fatalf("expected error to be reported, got %v", reported)
+	}
+	if reportedMetadata["turn_id"] != "t1" || reportedMetadata["component"] != "llm" {
+		t.Fatalf("unexpected metadata %v", reportedMetadata)
+	}
+}
+
+func TestRecordWithoutReporter(t *testing.T) {
+	Record(context.Background(), nil, errors.New("boom"))
+}
+
+func TestRecoverPanicReportsWorkerAndStack(t *testing.T) {
+	var reportedMetadata map[string]string
+	ctx := NewContext(context.Background(), func(_ context.Context, _ error, metadata map[string]string) {
+		reportedMetadata = metadata
+	})
+
+	func() {
+		defer RecoverPanic(ctx, "read loop", "provider", "test")
+		panic("boom")
+	}()
+
+	if reportedMetadata["worker"] != "read loop" || reportedMetadata["provider"] != "test" {
+		t.Fatalf("unexpected metadata %v", reportedMetadata)
+	}
+	if !strings.Contains(reportedMetadata["stack"], "TestRecoverPanicReportsWorkerAndStack") {
+		t.Fatalf("expected stack trace of the panic, got %q", reportedMetadata["stack"])
+	}
+}
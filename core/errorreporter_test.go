@@ -0,0 +1,100 @@
+package orchestration
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+func TestErrorReporterReceivesTurnErrors(t *testing.T) {
+	type report struct {
+		err      error
+		metadata map[string]string
+	}
+	var mu sync.Mutex
+	var reports []report
+	reporter := func(_ context.Context, err error, metadata map[string]string) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, report{err: err, metadata: metadata})
+	}
+
+	tts := &flakyTTSStub{}
+	o, _ := runDegradedTurns(t, &tts.failing, []bool{false},
+		WithTextToSpeechClientV1(tts),
+		WithAudioOutputV1(&bridgeAudioOutputStub{}),
+		WithErrorReporter(reporter),
+	)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) != 1 {
+		t.Fatalf("expected a single reported error, got %+v", reports)
+	}
+	if reports[0].err == nil || reports[0].metadata["component"] != string(ComponentTextToSpeech) {
+		t.Fatalf("expected text to speech error, got %+v", reports[0])
+	}
+	history := o.conversation.History()
+	if len(history) != 1 || reports[0].metadata["turn_id"] != history[0].ID {
+		t.Fatalf("expected error of turn %+v, got metadata %+v", history, reports[0].metadata)
+	}
+}
+
+type panickingLLMStub struct{}
+
+func (panickingLLMStub) Prompt(context.Context, string, ...llms.PromptOption) ([]llms.Message, error) {
+	panic("llm exploded")
+}
+
+func TestWorkerPanicIsEmittedAndReported(t *testing.T) {
+	var mu sync.Mutex
+	var panicEvents []events.PanicRecovered
+	var reportedMetadata []map[string]string
+	var failed atomic.Bool
+
+	o := NewOrchestrator(
+		WithLLM(panickingLLMStub{}),
+		WithErrorReporter(func(_ context.Context, _ error, metadata map[string]string) {
+			mu.Lock()
+			defer mu.Unlock()
+			reportedMetadata = append(reportedMetadata, metadata)
+		}),
+	)
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		switch e := event.(type) {
+		case events.PanicRecovered:
+			mu.Lock()
+			panicEvents = append(panicEvents, e)
+			mu.Unlock()
+		case events.TurnFailed:
+			failed.Store(true)
+		}
+	}))
+
+	o.SendPrompt("prompt")
+	waitForCondition(t, 2*time.Second, "turn failed", failed.Load)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(panicEvents) != 1 || panicEvents[0].Worker != "llm generation" || panicEvents[0].Value != "llm exploded" {
+		t.Fatalf("expected a single llm generation panic event, got %+v", panicEvents)
+	}
+	if !strings.Contains(panicEvents[0].Stack, "panickingLLMStub") {
+		t.Fatalf("expected stack trace of the panic, got %q", panicEvents[0].Stack)
+	}
+	for _, metadata := range reportedMetadata {
+		if metadata["worker"] == "llm generation worker" && metadata["turn_id"] == panicEvents[0].TurnID {
+			return
+		}
+	}
+	t.Fatalf("expected the panic to be reported, got %+v", reportedMetadata)
+}
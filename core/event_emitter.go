@@ -6,8 +6,23 @@ type eventEmitter func(events.Event)
 
 func noopEventEmitter(events.Event) {}
 
+// emitEvent emits event with the emitter set by [Orchestrator.Orchestrate],
+// it is safe to call concurrently with it, e.g. from the session manager.
+func (o *Orchestrator) emitEvent(event events.Event) {
+	if emit := o.emitter.Load(); emit != nil {
+		(*emit)(event)
+	}
+}
+
 func newCallbackEventEmitter(opts OrchestrateOptions) eventEmitter {
 	return func(event events.Event) {
+		if opts.onEvent != nil {
+			opts.onEvent(event)
+		}
+		for _, sink := range opts.sinks {
+			sink.handle(event)
+		}
+
 		switch typedEvent := event.(type) {
 		case events.UserAudioFrame:
 			if opts.onInputAudio != nil {
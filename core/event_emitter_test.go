@@ -0,0 +1,154 @@
+package orchestration
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestCallbackEventEmitterForwardsEveryEventToEventCallback(t *testing.T) {
+	opts := OrchestrateOptions{}
+	received := []events.Kind{}
+	finalResponse := ""
+	WithEventCallback(func(event events.Event) {
+		received = append(received, event.Kind())
+	})(&opts)
+	WithResponseCallback(func(response string) {
+		finalResponse = response
+	})(&opts)
+
+	emit := newCallbackEventEmitter(opts)
+	emit(events.NewUserSpeechStarted())
+	emit(events.NewAssistantResponseSegment("hello"))
+
+	if len(received) != 2 {
+		t.Fatalf("expected 2 events forwarded to event callback, got %d", len(received))
+	}
+	if received[0] != events.KindUserSpeechStarted || received[1] != events.KindAssistantResponseSegment {
+		t.Fatalf("unexpected forwarded kinds: %v", received)
+	}
+	if finalResponse != "hello" {
+		t.Fatalf("expected specialised callbacks to still run, got %q", finalResponse)
+	}
+}
+
+func TestEventSinksReceiveTheirNamespaces(t *testing.T) {
+	opts := OrchestrateOptions{}
+	var turnState []events.Kind
+	WithEventSink(events.SinkFunc(func(event events.Event) {
+		turnState = append(turnState, event.Kind())
+	}), WithEventNamespaces("turn_state"))(&opts)
+
+	var mu sync.Mutex
+	var buffered []events.Event
+	release := make(chan struct{})
+	WithEventSink(events.SinkFunc(func(event events.Event) {
+		<-release
+		mu.Lock()
+		buffered = append(buffered, event)
+		mu.Unlock()
+	}), WithEventBuffer(8))(&opts)
+
+	for _, sink := range opts.sinks {
+		sink.start(nil)
+		t.Cleanup(sink.stop)
+	}
+	emit := newCallbackEventEmitter(opts)
+	frame := []byte{1, 2}
+	emit(events.NewBorrowedAssistantPlaybackFrame(frame))
+	emit(events.NewTurnStarted("turn-1", "hi"))
+	frame[0] = 9
+
+	if len(turnState) != 1 || turnState[0] != events.KindTurnStarted {
+		t.Fatalf("expected only turn_state events in the inline sink, got %v", turnState)
+	}
+
+	close(release)
+	waitForCondition(t, time.Second, "buffered sink received events", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(buffered) == 2
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if played, ok := buffered[0].(events.AssistantPlaybackFrame); !ok || played.Borrowed() || played.Audio[0] != 1 {
+		t.Fatalf("expected the buffered sink to receive a retained frame, got %+v", buffered[0])
+	}
+}
+
+// slowEventSink records the kinds of the events it receives, slowly enough
+// for events to be buffered when the conversation shuts down.
+type slowEventSink struct {
+	mu    sync.Mutex
+	kinds []events.Kind
+}
+
+func (s *slowEventSink) Handle(event events.Event) {
+	time.Sleep(5 * time.Millisecond)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kinds = append(s.kinds, event.Kind())
+}
+
+func (s *slowEventSink) received(kind events.Kind) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Contains(s.kinds, kind)
+}
+
+func TestBufferedEventSinksReceiveEventsEmittedWhileShuttingDown(t *testing.T) {
+	o := NewOrchestrator(WithStreamingLLM(repeatingStreamLLMStub{chunk: "hi ", interval: time.Millisecond}))
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := &slowEventSink{}
+	var started sync.WaitGroup
+	started.Add(1)
+	var once sync.Once
+	o.Orchestrate(ctx, WithEventSink(sink, WithEventBuffer(1024)), WithEventCallback(func(event events.Event) {
+		if event.Kind() == events.KindTurnStarted {
+			once.Do(started.Done)
+		}
+	}))
+	o.SendPrompt("hello")
+	started.Wait()
+
+	cancel()
+	o.Close()
+	if !sink.received(events.KindTurnCancelled) {
+		t.Fatalf("expected the sink to receive the cancelled turn before Close returned, got %v", sink.kinds)
+	}
+}
+
+func TestRestartStopsTheBufferedEventSinksOfThePreviousConversation(t *testing.T) {
+	o := NewOrchestrator(WithStreamingLLM(repeatingStreamLLMStub{chunk: "hi ", interval: time.Millisecond}))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	previous := &slowEventSink{}
+	var started sync.WaitGroup
+	started.Add(1)
+	var once sync.Once
+	o.Orchestrate(ctx, WithEventSink(previous, WithEventBuffer(1024)), WithEventCallback(func(event events.Event) {
+		if event.Kind() == events.KindTurnStarted {
+			once.Do(started.Done)
+		}
+	}))
+	routed := (*o.eventSinks.Load())[0]
+	o.SendPrompt("hello")
+	started.Wait()
+
+	if err := o.Restart(ctx, WithEventSink(&slowEventSink{}, WithEventBuffer(1024))); err != nil {
+		t.Fatalf("unexpected restart error: %v", err)
+	}
+	select {
+	case <-routed.done:
+	default:
+		t.Fatal("expected the previous sink to be stopped by Restart")
+	}
+	if !previous.received(events.KindTurnCancelled) {
+		t.Fatalf("expected the previous sink to receive the cancelled turn, got %v", previous.kinds)
+	}
+}
@@ -0,0 +1,132 @@
+package orchestration
+
+import (
+	"slices"
+	"sync"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+type EventSinkOptions struct {
+	namespaces []string
+	bufferSize int
+}
+
+type EventSinkOption func(*EventSinkOptions)
+
+// WithEventNamespaces routes only events of namespaces, e.g. "turn_state",
+// to the sink. By default the sink receives every event.
+func WithEventNamespaces(namespaces ...string) EventSinkOption {
+	return func(o *EventSinkOptions) {
+		o.namespaces = namespaces
+	}
+}
+
+// WithEventBuffer hands events to the sink on its own goroutine, buffering up
+// to size events, so a slow sink neither blocks the emitting path nor other
+// sinks. Events arriving at a full buffer are dropped. By default the sink
+// is called inline.
+func WithEventBuffer(size int) EventSinkOption {
+	return func(o *EventSinkOptions) {
+		o.bufferSize = size
+	}
+}
+
+// WithEventSink routes emitted events to sink, e.g. audio frames to a
+// recorder, turn_state events to a webhook and everything to a UI socket,
+// each registered with its own namespaces and buffering. Sinks receive events
+// after the [WithEventCallback] callback. Buffered sinks are drained and
+// stopped when the orchestrator is closed or restarted, so they receive the
+// events emitted while shutting down, e.g. the final turn events.
+func WithEventSink(sink events.Sink, opts ...EventSinkOption) OrchestrateOption {
+	return func(o *OrchestrateOptions) {
+		options := EventSinkOptions{}
+		for _, opt := range opts {
+			opt(&options)
+		}
+		o.sinks = append(o.sinks, &routedEventSink{EventSinkOptions: options, sink: sink})
+	}
+}
+
+type routedEventSink struct {
+	EventSinkOptions
+	sink events.Sink
+
+	// queue is set for buffered sinks once started, done is closed once
+	// their goroutine handed the queue to the sink after it was closed.
+	queue  chan events.Event
+	done   chan struct{}
+	logger logging.Logger
+
+	// mu guards stopped, so the queue is never sent to once closed.
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// start starts the goroutine of a buffered sink, handing it events until it
+// is stopped.
+func (s *routedEventSink) start(logger logging.Logger) {
+	if s.bufferSize <= 0 {
+		return
+	}
+
+	s.logger = logger
+	s.queue = make(chan events.Event, s.bufferSize)
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		for event := range s.queue {
+			s.sink.Handle(event)
+		}
+	}()
+}
+
+// stop stops the goroutine of a buffered sink once the sink received the
+// buffered events, events emitted afterwards are dropped.
+func (s *routedEventSink) stop() {
+	if s.queue == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	<-s.done
+}
+
+func (s *routedEventSink) handle(event events.Event) {
+	if len(s.namespaces) > 0 && !slices.Contains(s.namespaces, event.Kind().Namespace()) {
+		return
+	}
+	if s.queue == nil {
+		s.sink.Handle(event)
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.stopped {
+		return
+	}
+	select {
+	case s.queue <- events.Retain(event):
+	default:
+		s.logger.Warn("event sink buffer full, dropping event", "kind", event.Kind())
+	}
+}
+
+// stopEventSinks drains and stops the buffered sinks of the conversation,
+// see [WithEventSink].
+func (o *Orchestrator) stopEventSinks() {
+	sinks := o.eventSinks.Swap(nil)
+	if sinks == nil {
+		return
+	}
+	for _, sink := range *sinks {
+		sink.stop()
+	}
+}
@@ -1,5 +1,7 @@
 package events
 
+import "bytes"
+
 const (
 	// KindAssistantPlaybackStarted identifies playback start for the current response.
 	KindAssistantPlaybackStarted Kind = "assistant_playback.started"
@@ -24,9 +26,14 @@ func NewAssistantPlaybackStarted() AssistantPlaybackStarted {
 }
 
 // AssistantPlaybackFrame carries an approximated append-only playback audio delta.
+//
+// A borrowed frame's Audio is only valid until the event callback returns,
+// use [AssistantPlaybackFrame.Retain] to keep it longer.
 type AssistantPlaybackFrame struct {
 	Base
 	Audio []byte
+
+	borrowed bool
 }
 
 // NewAssistantPlaybackFrame creates an assistant playback frame event.
@@ -34,6 +41,26 @@ func NewAssistantPlaybackFrame(audio []byte) AssistantPlaybackFrame {
 	return AssistantPlaybackFrame{Base: NewBase(KindAssistantPlaybackFrame), Audio: audio}
 }
 
+// NewBorrowedAssistantPlaybackFrame creates an assistant playback frame event
+// whose audio is reused once the event callback returns.
+func NewBorrowedAssistantPlaybackFrame(audio []byte) AssistantPlaybackFrame {
+	return AssistantPlaybackFrame{Base: NewBase(KindAssistantPlaybackFrame), Audio: audio, borrowed: true}
+}
+
+// Borrowed reports whether Audio is only valid until the event callback
+// returns.
+func (e AssistantPlaybackFrame) Borrowed() bool { return e.borrowed }
+
+// Retain returns the frame with audio that can be kept after the event
+// callback returns, copying it if the frame is borrowed.
+func (e AssistantPlaybackFrame) Retain() AssistantPlaybackFrame {
+	if e.borrowed {
+		e.Audio = bytes.Clone(e.Audio)
+		e.borrowed = false
+	}
+	return e
+}
+
 // AssistantPlaybackMarkPlayed marks confirmation that a playback mark was played.
 type AssistantPlaybackMarkPlayed struct {
 	Base
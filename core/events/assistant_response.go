@@ -5,6 +5,9 @@ const (
 	KindAssistantResponseStarted Kind = "assistant_response.started"
 	// KindAssistantResponseSegment identifies streamed assistant response text.
 	KindAssistantResponseSegment Kind = "assistant_response.segment"
+	// KindAssistantResponseSegmentReplaced identifies a revision of streamed
+	// assistant response text.
+	KindAssistantResponseSegmentReplaced Kind = "assistant_response.segment_replaced"
 	// KindAssistantResponseFinal identifies assistant response stream completion.
 	KindAssistantResponseFinal Kind = "assistant_response.final"
 	// KindAssistantResponseFinalized identifies final assembled assistant response payload.
@@ -30,6 +33,21 @@ func NewAssistantResponseSegment(segment string) AssistantResponseSegment {
 	return AssistantResponseSegment{Base: NewBase(KindAssistantResponseSegment), Segment: segment}
 }
 
+// AssistantResponseSegmentReplaced marks the model revising text it
+// streamed before. Replaced is the end of the response streamed so far that
+// Segment replaces; parts of it not yet passed to speech are not spoken.
+type AssistantResponseSegmentReplaced struct {
+	Base
+	Replaced string
+	Segment  string
+}
+
+// NewAssistantResponseSegmentReplaced creates an assistant response segment
+// replaced event.
+func NewAssistantResponseSegmentReplaced(replaced, segment string) AssistantResponseSegmentReplaced {
+	return AssistantResponseSegmentReplaced{Base: NewBase(KindAssistantResponseSegmentReplaced), Replaced: replaced, Segment: segment}
+}
+
 // AssistantResponseFinal marks assistant response stream completion.
 type AssistantResponseFinal struct{ Base }
 
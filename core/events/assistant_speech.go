@@ -1,10 +1,15 @@
 package events
 
+import "time"
+
 const (
 	// KindAssistantSpeechFrame identifies synthesized assistant speech audio.
 	KindAssistantSpeechFrame Kind = "assistant_speech.frame"
 	// KindAssistantSpeechMarkGenerated identifies a generated TTS mark.
 	KindAssistantSpeechMarkGenerated Kind = "assistant_speech.mark_generated"
+	// KindAssistantSpeechTimestamps identifies the timing of words in
+	// synthesized speech.
+	KindAssistantSpeechTimestamps Kind = "assistant_speech.timestamps"
 	// KindAssistantSpeechFinal identifies TTS generation completion.
 	KindAssistantSpeechFinal Kind = "assistant_speech.final"
 )
@@ -34,6 +39,26 @@ func NewAssistantSpeechMarkGenerated(transcript string) AssistantSpeechMarkGener
 	return AssistantSpeechMarkGenerated{Base: NewBase(KindAssistantSpeechMarkGenerated), Transcript: transcript}
 }
 
+// SpeechWordTimestamp is the timing of a word in synthesized speech, as
+// offsets from the start of the speech of the response.
+type SpeechWordTimestamp struct {
+	Word  string
+	Start time.Duration
+	End   time.Duration
+}
+
+// AssistantSpeechTimestamps carries the timing of words in synthesized
+// speech, reported by TTS clients that support it.
+type AssistantSpeechTimestamps struct {
+	Base
+	Words []SpeechWordTimestamp
+}
+
+// NewAssistantSpeechTimestamps creates an assistant speech timestamps event.
+func NewAssistantSpeechTimestamps(words []SpeechWordTimestamp) AssistantSpeechTimestamps {
+	return AssistantSpeechTimestamps{Base: NewBase(KindAssistantSpeechTimestamps), Words: words}
+}
+
 // AssistantSpeechFinal marks completion of TTS generation.
 type AssistantSpeechFinal struct{ Base }
 
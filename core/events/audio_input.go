@@ -0,0 +1,100 @@
+package events
+
+const (
+	// KindAudioCaptureStarted identifies audio input capture starting.
+	KindAudioCaptureStarted Kind = "audio_input.capture_started"
+	// KindAudioCaptureStopped identifies audio input capture stopping.
+	KindAudioCaptureStopped Kind = "audio_input.capture_stopped"
+	// KindAudioCaptureFailed identifies audio input capture failing.
+	KindAudioCaptureFailed Kind = "audio_input.capture_failed"
+	// KindAudioAlwaysCaptureChanged identifies always-on capture being
+	// toggled.
+	KindAudioAlwaysCaptureChanged Kind = "audio_input.always_capture_changed"
+	// KindMicrophoneMuted identifies user audio no longer being passed to
+	// speech-to-text.
+	KindMicrophoneMuted Kind = "audio_input.microphone_muted"
+	// KindMicrophoneUnmuted identifies user audio being passed to
+	// speech-to-text again.
+	KindMicrophoneUnmuted Kind = "audio_input.microphone_unmuted"
+)
+
+// Reasons of [AudioCaptureFailed].
+const (
+	AudioCaptureFailurePermissionDenied = "permission_denied"
+	AudioCaptureFailureDeviceBusy       = "device_busy"
+	AudioCaptureFailureUnknown          = "unknown"
+)
+
+// AudioCaptureStarted marks the audio input starting to capture the
+// microphone.
+type AudioCaptureStarted struct {
+	Base
+	// AlwaysCapture reports whether capture runs regardless of the turn
+	// state.
+	AlwaysCapture bool
+}
+
+// NewAudioCaptureStarted creates an audio capture started event.
+func NewAudioCaptureStarted(alwaysCapture bool) AudioCaptureStarted {
+	return AudioCaptureStarted{Base: NewBase(KindAudioCaptureStarted), AlwaysCapture: alwaysCapture}
+}
+
+// AudioCaptureStopped marks the audio input releasing the microphone.
+type AudioCaptureStopped struct {
+	Base
+}
+
+// NewAudioCaptureStopped creates an audio capture stopped event.
+func NewAudioCaptureStopped() AudioCaptureStopped {
+	return AudioCaptureStopped{Base: NewBase(KindAudioCaptureStopped)}
+}
+
+// AudioCaptureFailed marks capture failing to start or breaking off, the
+// microphone is not captured afterwards. Reason is one of the
+// AudioCaptureFailure reasons, e.g. [AudioCaptureFailurePermissionDenied].
+type AudioCaptureFailed struct {
+	Base
+	Reason string
+	Error  string
+}
+
+// NewAudioCaptureFailed creates an audio capture failed event.
+func NewAudioCaptureFailed(reason, err string) AudioCaptureFailed {
+	return AudioCaptureFailed{Base: NewBase(KindAudioCaptureFailed), Reason: reason, Error: err}
+}
+
+// AudioAlwaysCaptureChanged marks always-on capture being enabled or
+// disabled.
+type AudioAlwaysCaptureChanged struct {
+	Base
+	AlwaysCapture bool
+}
+
+// NewAudioAlwaysCaptureChanged creates an always capture changed event.
+func NewAudioAlwaysCaptureChanged(alwaysCapture bool) AudioAlwaysCaptureChanged {
+	return AudioAlwaysCaptureChanged{Base: NewBase(KindAudioAlwaysCaptureChanged), AlwaysCapture: alwaysCapture}
+}
+
+// MicrophoneMuted marks the orchestrator no longer passing user audio to
+// speech-to-text, e.g. while a call is on hold.
+type MicrophoneMuted struct {
+	Base
+	// KeepRecording reports whether user audio frames are still emitted
+	// while muted.
+	KeepRecording bool
+}
+
+// NewMicrophoneMuted creates a microphone muted event.
+func NewMicrophoneMuted(keepRecording bool) MicrophoneMuted {
+	return MicrophoneMuted{Base: NewBase(KindMicrophoneMuted), KeepRecording: keepRecording}
+}
+
+// MicrophoneUnmuted marks user audio being passed to speech-to-text again.
+type MicrophoneUnmuted struct {
+	Base
+}
+
+// NewMicrophoneUnmuted creates a microphone unmuted event.
+func NewMicrophoneUnmuted() MicrophoneUnmuted {
+	return MicrophoneUnmuted{Base: NewBase(KindMicrophoneUnmuted)}
+}
@@ -0,0 +1,30 @@
+package events
+
+// KindBackchannelPlayed identifies a played backchannel.
+const KindBackchannelPlayed Kind = "backchannel.played"
+
+// Backchannel reasons.
+const (
+	// BackchannelReasonUserSpeech is a backchannel during a long user
+	// utterance.
+	BackchannelReasonUserSpeech = "user_speech"
+	// BackchannelReasonToolWait is a backchannel while waiting on a slow tool
+	// call.
+	BackchannelReasonToolWait = "tool_wait"
+)
+
+// BackchannelPlayed marks a short acknowledgement, e.g. "mm-hmm", sent to
+// audio output. Backchannels are not part of any turn and never enter the
+// conversation history.
+type BackchannelPlayed struct {
+	Base
+	Phrase string
+	// Reason is why the backchannel was played, see BackchannelReason
+	// constants.
+	Reason string
+}
+
+// NewBackchannelPlayed creates a backchannel played event.
+func NewBackchannelPlayed(phrase, reason string) BackchannelPlayed {
+	return BackchannelPlayed{Base: NewBase(KindBackchannelPlayed), Phrase: phrase, Reason: reason}
+}
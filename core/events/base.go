@@ -1,9 +1,19 @@
 package events
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Kind string
 
+// Namespace returns the receiver-facing group of the kind, e.g. "turn_state"
+// for "turn_state.started".
+func (k Kind) Namespace() string {
+	namespace, _, _ := strings.Cut(string(k), ".")
+	return namespace
+}
+
 type Event interface {
 	Kind() Kind
 	Timestamp() time.Time
@@ -0,0 +1,35 @@
+package events
+
+const (
+	// KindBudgetExceeded identifies the conversation exceeding its budget.
+	KindBudgetExceeded Kind = "budget.exceeded"
+)
+
+// BudgetLimit names a limit of a conversation budget.
+type BudgetLimit string
+
+const (
+	BudgetLimitTokens BudgetLimit = "tokens"
+	BudgetLimitCost   BudgetLimit = "cost"
+	BudgetLimitTurns  BudgetLimit = "turns"
+)
+
+// BudgetExceeded marks the conversation exceeding its budget, emitted once
+// when the first limit is reached.
+type BudgetExceeded struct {
+	Base
+	// Limit is the limit that was reached.
+	Limit BudgetLimit
+	// Tokens, Cost and Turns are spent so far.
+	Tokens int
+	Cost   float64
+	Turns  int
+	// Fallback reports whether following turns switch to a fallback LLM
+	// instead of being refused.
+	Fallback bool
+}
+
+// NewBudgetExceeded creates a budget exceeded event.
+func NewBudgetExceeded(limit BudgetLimit, tokens int, cost float64, turns int, fallback bool) BudgetExceeded {
+	return BudgetExceeded{Base: NewBase(KindBudgetExceeded), Limit: limit, Tokens: tokens, Cost: cost, Turns: turns, Fallback: fallback}
+}
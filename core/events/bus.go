@@ -0,0 +1,130 @@
+package events
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultBusSubscriptionBuffer = 64
+
+// Bus fans events out to any number of subscribers, e.g. a UI, a logger and
+// analytics, each receiving only the kinds it subscribed to. It is a [Sink],
+// register it with the orchestrator's WithEventSink.
+//
+// Events are delivered without blocking the emitting path: each subscription
+// buffers events, events arriving at a full buffer are dropped for that
+// subscription and counted, see [Subscription.Dropped].
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+	closed        bool
+	bufferSize    int
+}
+
+type BusOptions struct {
+	bufferSize int
+}
+
+type BusOption func(*BusOptions)
+
+// WithSubscriptionBuffer sets how many events each subscription buffers,
+// defaults to 64.
+func WithSubscriptionBuffer(size int) BusOption {
+	return func(o *BusOptions) {
+		o.bufferSize = size
+	}
+}
+
+// NewBus creates a bus without subscribers.
+func NewBus(opts ...BusOption) *Bus {
+	options := BusOptions{bufferSize: defaultBusSubscriptionBuffer}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Bus{
+		subscriptions: map[*Subscription]struct{}{},
+		bufferSize:    max(options.bufferSize, 0),
+	}
+}
+
+// Subscription is a filtered stream of the events of a [Bus].
+type Subscription struct {
+	// C receives the events of the subscription, it is closed once the
+	// subscription or the bus is closed.
+	C <-chan Event
+
+	events  chan Event
+	kinds   []Kind
+	bus     *Bus
+	dropped atomic.Int64
+}
+
+// Subscribe returns a subscription to events of kinds, or to every event if
+// no kinds are given. Close the subscription once done with it.
+func (b *Bus) Subscribe(kinds ...Kind) *Subscription {
+	events := make(chan Event, b.bufferSize)
+	s := &Subscription{C: events, events: events, kinds: slices.Clone(kinds), bus: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(events)
+		return s
+	}
+	b.subscriptions[s] = struct{}{}
+	return s
+}
+
+// Handle delivers event to the subscriptions of its kind.
+func (b *Bus) Handle(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var retained Event
+	for s := range b.subscriptions {
+		if len(s.kinds) > 0 && !slices.Contains(s.kinds, event.Kind()) {
+			continue
+		}
+		if retained == nil {
+			retained = Retain(event)
+		}
+		select {
+		case s.events <- retained:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Close closes all subscriptions, later subscriptions are closed right
+// away.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for s := range b.subscriptions {
+		close(s.events)
+	}
+	clear(b.subscriptions)
+}
+
+// Close stops delivering events to the subscription and closes C.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subscriptions[s]; ok {
+		delete(s.bus.subscriptions, s)
+		close(s.events)
+	}
+}
+
+// Dropped returns how many events were dropped because the buffer of the
+// subscription was full.
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
@@ -0,0 +1,40 @@
+package events
+
+import "testing"
+
+func TestBusDeliversFilteredEventsToEachSubscriber(t *testing.T) {
+	bus := NewBus(WithSubscriptionBuffer(1))
+	turns := bus.Subscribe(KindTurnStarted, KindTurnCompleted)
+	everything := bus.Subscribe()
+	defer turns.Close()
+
+	bus.Handle(NewTurnStarted("turn", "hello"))
+	bus.Handle(NewUserSpeechStarted())
+
+	if event := <-turns.C; event.Kind() != KindTurnStarted {
+		t.Fatalf("expected the turn started event, got %s", event.Kind())
+	}
+	select {
+	case event := <-turns.C:
+		t.Fatalf("expected events of other kinds to be filtered out, got %s", event.Kind())
+	default:
+	}
+	if event := <-everything.C; event.Kind() != KindTurnStarted {
+		t.Fatalf("expected the unfiltered subscription to receive the turn started event, got %s", event.Kind())
+	}
+	if dropped := everything.Dropped(); dropped != 1 {
+		t.Fatalf("expected the event arriving at the full buffer to be dropped, got %d dropped", dropped)
+	}
+
+	everything.Close()
+	if _, ok := <-everything.C; ok {
+		t.Fatalf("expected a closed subscription to close its channel")
+	}
+	bus.Close()
+	if _, ok := <-turns.C; ok {
+		t.Fatalf("expected closing the bus to close its subscriptions")
+	}
+	if _, ok := <-bus.Subscribe().C; ok {
+		t.Fatalf("expected subscriptions of a closed bus to be closed")
+	}
+}
@@ -0,0 +1,49 @@
+package events
+
+const (
+	// KindChatMessageUpdated identifies a chat message being created or its
+	// text changing.
+	KindChatMessageUpdated Kind = "chat.message_updated"
+	// KindChatMessageFinalized identifies a chat message whose text no longer
+	// changes.
+	KindChatMessageFinalized Kind = "chat.message_finalized"
+)
+
+// Roles of chat messages.
+const (
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+// ChatMessageUpdated carries the full text of a chat message so far, e.g. an
+// interim transcript of the user or a streaming assistant response. The
+// first update with a MessageID creates the message, later ones replace its
+// text.
+type ChatMessageUpdated struct {
+	Base
+	MessageID string
+	Role      string
+	SpeakerID string `json:",omitempty"`
+	Text      string
+}
+
+// NewChatMessageUpdated creates a chat message updated event.
+func NewChatMessageUpdated(messageID, role, speakerID, text string) ChatMessageUpdated {
+	return ChatMessageUpdated{Base: NewBase(KindChatMessageUpdated), MessageID: messageID, Role: role, SpeakerID: speakerID, Text: text}
+}
+
+// ChatMessageFinalized carries the final text of a chat message, no update
+// of the message follows. Messages that were never updated, e.g. typed
+// messages, are created by it.
+type ChatMessageFinalized struct {
+	Base
+	MessageID string
+	Role      string
+	SpeakerID string `json:",omitempty"`
+	Text      string
+}
+
+// NewChatMessageFinalized creates a chat message finalized event.
+func NewChatMessageFinalized(messageID, role, speakerID, text string) ChatMessageFinalized {
+	return ChatMessageFinalized{Base: NewBase(KindChatMessageFinalized), MessageID: messageID, Role: role, SpeakerID: speakerID, Text: text}
+}
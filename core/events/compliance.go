@@ -0,0 +1,44 @@
+package events
+
+import "time"
+
+const (
+	// KindCompliancePauseStarted identifies user audio and transcripts being
+	// withheld for a sensitive segment.
+	KindCompliancePauseStarted Kind = "compliance.pause_started"
+	// KindCompliancePauseEnded identifies user audio and transcripts flowing
+	// again after a sensitive segment.
+	KindCompliancePauseEnded Kind = "compliance.pause_ended"
+)
+
+// CompliancePauseStarted marks the start of a sensitive segment, e.g. the
+// user reading a card number. Until the pause ends no user audio reaches
+// speech-to-text and no user_input audio frame or transcript events are
+// emitted.
+type CompliancePauseStarted struct {
+	Base
+	Reason string
+}
+
+// NewCompliancePauseStarted creates a compliance pause started event.
+func NewCompliancePauseStarted(reason string) CompliancePauseStarted {
+	return CompliancePauseStarted{Base: NewBase(KindCompliancePauseStarted), Reason: reason}
+}
+
+// CompliancePauseEnded marks the end of a sensitive segment, for auditing
+// the gap in the recording and the transcript.
+type CompliancePauseEnded struct {
+	Base
+	Reason   string
+	Duration time.Duration
+	// DroppedFrames is the number of user audio frames withheld.
+	DroppedFrames int
+	// TimedOut is whether the pause ended because it reached its maximum
+	// duration.
+	TimedOut bool
+}
+
+// NewCompliancePauseEnded creates a compliance pause ended event.
+func NewCompliancePauseEnded(reason string, duration time.Duration, droppedFrames int, timedOut bool) CompliancePauseEnded {
+	return CompliancePauseEnded{Base: NewBase(KindCompliancePauseEnded), Reason: reason, Duration: duration, DroppedFrames: droppedFrames, TimedOut: timedOut}
+}
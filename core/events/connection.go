@@ -0,0 +1,37 @@
+package events
+
+const (
+	// KindConnectionLost identifies a provider connection dropping.
+	KindConnectionLost Kind = "connection.lost"
+	// KindConnectionRestored identifies a dropped provider connection being
+	// reopened.
+	KindConnectionRestored Kind = "connection.restored"
+)
+
+// ConnectionLost marks the connection of a streaming component, e.g. the
+// speech-to-text stream, dropping unexpectedly. The component reconnects on
+// its own; audio sent in the meantime is lost.
+type ConnectionLost struct {
+	Base
+	// Component is the component whose connection dropped, e.g.
+	// "speech_to_text".
+	Component string
+	Error     string
+}
+
+// NewConnectionLost creates a connection lost event.
+func NewConnectionLost(component, err string) ConnectionLost {
+	return ConnectionLost{Base: NewBase(KindConnectionLost), Component: component, Error: err}
+}
+
+// ConnectionRestored marks a component reconnecting after its connection
+// dropped.
+type ConnectionRestored struct {
+	Base
+	Component string
+}
+
+// NewConnectionRestored creates a connection restored event.
+func NewConnectionRestored(component string) ConnectionRestored {
+	return ConnectionRestored{Base: NewBase(KindConnectionRestored), Component: component}
+}
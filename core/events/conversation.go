@@ -0,0 +1,32 @@
+package events
+
+// KindConversationAnalyzed identifies the post-call analysis of a
+// conversation.
+const KindConversationAnalyzed Kind = "conversation.analyzed"
+
+// ConversationAnalyzed carries the analysis of a conversation, typically the
+// last event of the conversation.
+type ConversationAnalyzed struct {
+	Base
+	Summary string
+	// Resolution is whether the user got what they came for: "resolved",
+	// "unresolved" or "escalated".
+	Resolution string
+	// Sentiment is the overall sentiment of the user: "positive",
+	// "neutral" or "negative".
+	Sentiment   string
+	ActionItems []string
+	Topics      []string
+}
+
+// NewConversationAnalyzed creates a conversation analyzed event.
+func NewConversationAnalyzed(summary, resolution, sentiment string, actionItems, topics []string) ConversationAnalyzed {
+	return ConversationAnalyzed{
+		Base:        NewBase(KindConversationAnalyzed),
+		Summary:     summary,
+		Resolution:  resolution,
+		Sentiment:   sentiment,
+		ActionItems: actionItems,
+		Topics:      topics,
+	}
+}
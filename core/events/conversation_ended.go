@@ -0,0 +1,34 @@
+package events
+
+import "time"
+
+// KindConversationEnded identifies a conversation being ended by the session
+// manager for being idle.
+const KindConversationEnded Kind = "conversation.ended"
+
+// Reasons of [ConversationEnded].
+const (
+	// ConversationEndedIdleTimeout is the reason of conversations ended for
+	// having no activity for longer than their idle timeout.
+	ConversationEndedIdleTimeout = "idle_timeout"
+)
+
+// ConversationEnded marks the session manager ending a conversation on its
+// own, e.g. one abandoned by its client. The orchestrator of the session is
+// closed after the event.
+//
+// It is only emitted for [ConversationEndedIdleTimeout]. Conversations ended
+// by their client, with EndConversation or Close of the orchestrator, are not
+// reported with it, the client knows it ended them.
+type ConversationEnded struct {
+	Base
+	SessionID string
+	Reason    string
+	// Idle is how long the conversation had no activity.
+	Idle time.Duration
+}
+
+// NewConversationEnded creates a conversation ended event.
+func NewConversationEnded(sessionID, reason string, idle time.Duration) ConversationEnded {
+	return ConversationEnded{Base: NewBase(KindConversationEnded), SessionID: sessionID, Reason: reason, Idle: idle}
+}
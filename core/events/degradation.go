@@ -0,0 +1,57 @@
+package events
+
+const (
+	// KindDegradedToTextOnly identifies a turn continuing without speech.
+	KindDegradedToTextOnly Kind = "degradation.text_only"
+	// KindDegradationRecovered identifies a failed speech component working
+	// again.
+	KindDegradationRecovered Kind = "degradation.recovered"
+	// KindPlaybackMarksTimedOut identifies the audio output not confirming
+	// playback marks.
+	KindPlaybackMarksTimedOut Kind = "degradation.marks_timed_out"
+)
+
+// DegradedToTextOnly marks a speech component failing, the turn continues
+// text-only: assistant_response events still flow while speech and playback
+// events stop. Emitted once per component until it recovers.
+type DegradedToTextOnly struct {
+	Base
+	TurnID string
+	// Component is the failed component, "text_to_speech" or
+	// "audio_output".
+	Component string
+	Error     string
+}
+
+// NewDegradedToTextOnly creates a degraded to text-only event.
+func NewDegradedToTextOnly(turnID, component, err string) DegradedToTextOnly {
+	return DegradedToTextOnly{Base: NewBase(KindDegradedToTextOnly), TurnID: turnID, Component: component, Error: err}
+}
+
+// PlaybackMarksTimedOut marks the audio output not confirming the marks of
+// a response well past the expected end of its audio, e.g. a transport
+// losing them. Playback is considered ended, the spoken transcript only
+// includes confirmed marks.
+type PlaybackMarksTimedOut struct {
+	Base
+	// Pending is the number of marks given up on.
+	Pending int
+}
+
+// NewPlaybackMarksTimedOut creates a playback marks timed out event.
+func NewPlaybackMarksTimedOut(pending int) PlaybackMarksTimedOut {
+	return PlaybackMarksTimedOut{Base: NewBase(KindPlaybackMarksTimedOut), Pending: pending}
+}
+
+// DegradationRecovered marks a previously failed speech component working
+// again in a later turn.
+type DegradationRecovered struct {
+	Base
+	TurnID    string
+	Component string
+}
+
+// NewDegradationRecovered creates a degradation recovered event.
+func NewDegradationRecovered(turnID, component string) DegradationRecovered {
+	return DegradationRecovered{Base: NewBase(KindDegradationRecovered), TurnID: turnID, Component: component}
+}
@@ -3,11 +3,22 @@
 // Event kinds are grouped by receiver-facing namespaces:
 //
 //   - user_input.*
+//   - audio_input.*
 //   - assistant_response.*
 //   - tool_call.*
 //   - assistant_speech.*
 //   - assistant_playback.*
 //   - turn_state.*
+//   - budget.*
+//   - tenant.*
+//   - compliance.*
+//   - degradation.*
+//   - panic.*
+//   - backchannel.*
+//   - translation.*
+//   - conversation.*
+//   - chat.*
+//   - connection.*
 //
 // Semantics used across the package:
 //
@@ -19,6 +30,9 @@
 //
 // user_input events
 //
+// In conversations with several users, user_input events carry the SpeakerID
+// of the user they come from.
+//
 //   - UserAudioFrame (user_input.audio_frame): raw user input audio frame.
 //   - UserSpeechStarted (user_input.speech_started): speech activity began.
 //   - UserSpeechEnded (user_input.speech_ended): speech activity ended.
@@ -30,6 +44,27 @@
 //     append-only transcript segment.
 //   - UserTranscriptFinal (user_input.transcript_final): terminal full
 //     transcript for the utterance.
+//   - UserMessageTyped (user_input.message_typed): the user typed a message
+//     instead of speaking.
+//   - UserWakeWordDetected (user_input.wake_word_detected): the wake word was
+//     heard, the audio that follows is transcribed.
+//
+// audio_input events
+//
+//   - AudioCaptureStarted (audio_input.capture_started): the microphone is
+//     being captured; includes whether capture is always on.
+//   - AudioCaptureStopped (audio_input.capture_stopped): the microphone was
+//     released.
+//   - AudioCaptureFailed (audio_input.capture_failed): capture failed to
+//     start or broke off; includes the reason, e.g. permission_denied or
+//     device_busy, and the error.
+//   - AudioAlwaysCaptureChanged (audio_input.always_capture_changed):
+//     always-on capture was enabled or disabled.
+//   - MicrophoneMuted (audio_input.microphone_muted): user audio is no longer
+//     passed to speech-to-text; includes whether audio frames are still
+//     emitted for recording.
+//   - MicrophoneUnmuted (audio_input.microphone_unmuted): user audio is
+//     passed to speech-to-text again.
 //
 // assistant_response events
 //
@@ -37,6 +72,9 @@
 //     started.
 //   - AssistantResponseSegment (assistant_response.segment): streamed response
 //     text segment.
+//   - AssistantResponseSegmentReplaced (assistant_response.segment_replaced):
+//     the model revised the end of the streamed text; includes the replaced
+//     and the replacing text.
 //   - AssistantResponseFinal (assistant_response.final): response text stream
 //     is complete.
 //   - AssistantResponseFinalized (assistant_response.finalized): final assembled
@@ -45,8 +83,15 @@
 // tool_call events
 //
 //   - ToolCallStarted (tool_call.started): tool execution started.
+//   - ToolCallApprovalRequested (tool_call.approval_requested): call of a
+//     sensitive tool is held until it is approved or rejected.
 //   - ToolCallCompleted (tool_call.completed): tool execution completed.
 //   - ToolCallFailed (tool_call.failed): tool execution failed.
+//   - ToolCallProgress (tool_call.progress): tool execution is taking a while;
+//     includes the holding phrase spoken meanwhile or the status reported by
+//     a streaming tool.
+//   - ToolCallOutputFlagged (tool_call.output_flagged): tool output was
+//     withheld from the LLM as a likely prompt injection; includes the reason.
 //
 // assistant_speech events
 //
@@ -55,6 +100,8 @@
 //   - AssistantSpeechMarkGenerated (assistant_speech.mark_generated): TTS mark
 //     generated with transcript text associated with that mark. In legacy mode,
 //     empty transcript may indicate terminal end-of-stream mark.
+//   - AssistantSpeechTimestamps (assistant_speech.timestamps): timing of words
+//     in synthesized speech, for TTS clients that report it.
 //   - AssistantSpeechFinal (assistant_speech.final): TTS generation ended.
 //
 // assistant_playback events
@@ -79,4 +126,112 @@
 //     successfully.
 //   - TurnFailed (turn_state.failed): current turn failed.
 //   - TurnCancelled (turn_state.cancelled): current turn was cancelled.
+//   - TurnStateChanged (turn_state.changed): current turn moved to another
+//     [TurnState]; includes the previous and the new state.
+//   - TurnStalled (turn_state.stalled): current turn made no progress for a
+//     while; includes the state of each response pipeline worker.
+//
+// budget events
+//
+//   - BudgetExceeded (budget.exceeded): conversation reached a limit of its
+//     budget; includes the limit, what was spent and whether turns fall back
+//     to another LLM.
+//
+// tenant events
+//
+//   - TenantLimitExceeded (tenant.limit_exceeded): a conversation or a turn
+//     was refused because its tenant reached a limit of the session manager.
+//
+// compliance events
+//
+//   - CompliancePauseStarted (compliance.pause_started): user audio and
+//     transcripts are withheld for a sensitive segment; includes the reason.
+//   - CompliancePauseEnded (compliance.pause_ended): the sensitive segment
+//     ended; includes its duration and the number of withheld audio frames.
+//
+// degradation events
+//
+//   - DegradedToTextOnly (degradation.text_only): a speech component failed
+//     and the turn continues without speech.
+//   - DegradationRecovered (degradation.recovered): a failed speech component
+//     works again.
+//   - PlaybackMarksTimedOut (degradation.marks_timed_out): the audio output
+//     stopped confirming playback marks and playback was considered ended.
+//
+// panic events
+//
+//   - PanicRecovered (panic.recovered): a pipeline worker panicked and the
+//     panic was contained; includes the worker name and the stack trace.
+//
+// backchannel events
+//
+//   - BackchannelPlayed (backchannel.played): a short acknowledgement was
+//     played outside of any turn; includes the phrase and why it was played.
+//
+// translation events
+//
+//   - TextTranslated (translation.text_translated): a user prompt or a
+//     sentence of an assistant response was translated; includes both the
+//     original and the translated text.
+//
+// conversation events
+//
+//   - ConversationAnalyzed (conversation.analyzed): the conversation was
+//     analyzed; includes the summary, resolution, sentiment, action items and
+//     topics.
+//   - OutcomeLabeled (conversation.outcome_labeled): an outcome label, e.g.
+//     resolved or escalated, was attached to a turn or, without a turn ID,
+//     to the conversation; includes the notes and who labeled it.
+//   - ConversationEnded (conversation.ended): the session manager ended the
+//     conversation; includes the session, the reason, e.g. idle_timeout, and
+//     how long it was idle.
+//
+// chat events
+//
+// Derived from user_input and assistant_response events for on-screen chat,
+// see WithChatEvents of the orchestrator.
+//
+//   - ChatMessageUpdated (chat.message_updated): a message of the user or the
+//     assistant was created or its text changed; includes the stable message
+//     ID, the role and the full text so far.
+//   - ChatMessageFinalized (chat.message_finalized): the message text is
+//     final.
+//
+// connection events
+//
+//   - ConnectionLost (connection.lost): the connection of a streaming
+//     component dropped unexpectedly; includes the component and the error.
+//   - ConnectionRestored (connection.restored): the component reconnected.
+//
+// # Subscribing
+//
+// A [Bus] registered as a sink of the orchestrator lets independent
+// consumers, e.g. a UI, logging and analytics, each [Bus.Subscribe] to the
+// kinds they need and receive them on their own channel.
+//
+// # Wire format
+//
+// Sinks ship events as JSON [Envelope] values carrying the [WireVersion],
+// see [MarshalEvent]. Receivers decode them with [DecodeEnvelope] or their
+// own types, or with [ParseEvent] back into the event types of this package.
+// Within a wire version:
+//
+//   - kinds and fields are only added, never removed, renamed or retyped;
+//   - field names of the data are the exported field names of the event
+//     types;
+//   - receivers ignore unknown kinds and unknown fields.
+//
+// Changes breaking these rules bump the wire version, and [DecodeEnvelope]
+// keeps accepting all older versions.
+//
+// Events encoded on their own, e.g. with json.Marshal, carry their kind and
+// timestamp as "kind" and "timestamp" next to their fields. Envelopes carry
+// them only once, outside of the data.
+//
+// # Borrowed frames
+//
+// With borrowed audio frames enabled on the orchestrator, UserAudioFrame and
+// AssistantPlaybackFrame audio is reused once the event callback returns.
+// Borrowed reports whether a frame is borrowed; receivers that keep audio
+// past the callback call Retain (or the package level [Retain]) to get a copy.
 package events
@@ -0,0 +1,72 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WireVersion is the version of the [Envelope] wire format, see the package
+// documentation for its compatibility rules.
+const WireVersion = 1
+
+// ErrUnsupportedWireVersion is the error of envelopes newer than the
+// [WireVersion] the decoder knows.
+var ErrUnsupportedWireVersion = errors.New("unsupported wire version")
+
+// Envelope wraps an event with its kind and timestamp for shipping it to
+// external receivers, e.g. as JSON.
+//
+// Data carries the exported fields of the concrete event type.
+type Envelope struct {
+	Version   int       `json:"version"`
+	Kind      Kind      `json:"kind"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      Event     `json:"data"`
+}
+
+// NewEnvelope wraps event in an envelope of the current [WireVersion].
+func NewEnvelope(event Event) Envelope {
+	return Envelope{Version: WireVersion, Kind: event.Kind(), Timestamp: event.Timestamp(), Data: event}
+}
+
+// MarshalJSON encodes the envelope with the fields of the event as its data,
+// the kind and timestamp of the event are only encoded once, in the envelope.
+func (e Envelope) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = withoutEventHeader(data); err != nil {
+		return nil, err
+	}
+	return json.Marshal(RawEnvelope{Version: e.Version, Kind: e.Kind, Timestamp: e.Timestamp, Data: data})
+}
+
+// RawEnvelope is a decoded [Envelope] with its data left encoded, receivers
+// decode the data of the kinds they handle and skip the others.
+type RawEnvelope struct {
+	Version   int             `json:"version"`
+	Kind      Kind            `json:"kind"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// DecodeEnvelope decodes a JSON envelope of the current or an older wire
+// version. Envelopes without a version predate versioning and decode as
+// version 0, which has the same layout as version 1. Newer versions fail
+// with [ErrUnsupportedWireVersion].
+func DecodeEnvelope(data []byte) (RawEnvelope, error) {
+	var envelope RawEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return RawEnvelope{}, fmt.Errorf("failed to decode envelope: %w", err)
+	}
+	if envelope.Version > WireVersion {
+		return RawEnvelope{}, fmt.Errorf("%w: %d, newest known is %d", ErrUnsupportedWireVersion, envelope.Version, WireVersion)
+	}
+	if envelope.Kind == "" {
+		return RawEnvelope{}, errors.New("failed to decode envelope: missing kind")
+	}
+	return envelope, nil
+}
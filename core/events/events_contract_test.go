@@ -1,6 +1,12 @@
 package events
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestConstructorsEmitExpectedKinds(t *testing.T) {
 	testCases := []struct {
@@ -24,6 +30,7 @@ func TestConstructorsEmitExpectedKinds(t *testing.T) {
 		{name: "tool call failed", event: NewToolCallFailed("id", "name", "boom"), expected: KindToolCallFailed},
 		{name: "assistant speech frame", event: NewAssistantSpeechFrame([]byte{1}), expected: KindAssistantSpeechFrame},
 		{name: "assistant speech mark generated", event: NewAssistantSpeechMarkGenerated("mark"), expected: KindAssistantSpeechMarkGenerated},
+		{name: "assistant speech timestamps", event: NewAssistantSpeechTimestamps([]SpeechWordTimestamp{{Word: "word", End: time.Second}}), expected: KindAssistantSpeechTimestamps},
 		{name: "assistant speech final", event: NewAssistantSpeechFinal(), expected: KindAssistantSpeechFinal},
 		{name: "assistant playback started", event: NewAssistantPlaybackStarted(), expected: KindAssistantPlaybackStarted},
 		{name: "assistant playback frame", event: NewAssistantPlaybackFrame([]byte{1}), expected: KindAssistantPlaybackFrame},
@@ -35,6 +42,38 @@ func TestConstructorsEmitExpectedKinds(t *testing.T) {
 		{name: "turn completed", event: NewTurnCompleted("turn-id"), expected: KindTurnCompleted},
 		{name: "turn failed", event: NewTurnFailed("turn-id", "error"), expected: KindTurnFailed},
 		{name: "turn cancelled", event: NewTurnCancelled(), expected: KindTurnCancelled},
+		{name: "turn state changed", event: NewTurnStateChanged("turn-id", TurnStateQueued, TurnStateGenerating), expected: KindTurnStateChanged},
+		{name: "turn stalled", event: NewTurnStalled("turn-id", []WorkerState{{Name: "llm", State: "streaming"}}), expected: KindTurnStalled},
+		{name: "budget exceeded", event: NewBudgetExceeded(BudgetLimitTurns, 10, 0.5, 3, false), expected: KindBudgetExceeded},
+		{name: "compliance pause started", event: NewCompliancePauseStarted("card number"), expected: KindCompliancePauseStarted},
+		{name: "compliance pause ended", event: NewCompliancePauseEnded("card number", time.Second, 50, false), expected: KindCompliancePauseEnded},
+		{name: "tenant limit exceeded", event: NewTenantLimitExceeded("acme", "session-id", TenantLimitConversations, 10), expected: KindTenantLimitExceeded},
+		{name: "degraded to text only", event: NewDegradedToTextOnly("turn-id", "audio_output", "error"), expected: KindDegradedToTextOnly},
+		{name: "degradation recovered", event: NewDegradationRecovered("turn-id", "audio_output"), expected: KindDegradationRecovered},
+		{name: "panic recovered", event: NewPanicRecovered("turn-id", "worker", "value", "stack"), expected: KindPanicRecovered},
+		{name: "backchannel played", event: NewBackchannelPlayed("mm-hmm", BackchannelReasonUserSpeech), expected: KindBackchannelPlayed},
+		{name: "text translated", event: NewTextTranslated(TranslationDirectionUser, "hola", "hello", "es", "en"), expected: KindTextTranslated},
+		{name: "conversation analyzed", event: NewConversationAnalyzed("summary", "resolved", "positive", nil, nil), expected: KindConversationAnalyzed},
+		{name: "chat message updated", event: NewChatMessageUpdated("message-id", ChatRoleUser, "", "hel"), expected: KindChatMessageUpdated},
+		{name: "chat message finalized", event: NewChatMessageFinalized("message-id", ChatRoleUser, "", "hello"), expected: KindChatMessageFinalized},
+		{name: "outcome labeled", event: NewOutcomeLabeled("turn-id", "resolved", "notes", "agent"), expected: KindOutcomeLabeled},
+		{name: "conversation ended", event: NewConversationEnded("session-id", ConversationEndedIdleTimeout, time.Minute), expected: KindConversationEnded},
+		{name: "user message typed", event: NewUserMessageTyped("hello"), expected: KindUserMessageTyped},
+		{name: "user wake word detected", event: NewUserWakeWordDetected("hey ema"), expected: KindUserWakeWordDetected},
+		{name: "audio capture started", event: NewAudioCaptureStarted(true), expected: KindAudioCaptureStarted},
+		{name: "audio capture stopped", event: NewAudioCaptureStopped(), expected: KindAudioCaptureStopped},
+		{name: "audio capture failed", event: NewAudioCaptureFailed(AudioCaptureFailureDeviceBusy, "error"), expected: KindAudioCaptureFailed},
+		{name: "audio always capture changed", event: NewAudioAlwaysCaptureChanged(false), expected: KindAudioAlwaysCaptureChanged},
+		{name: "microphone muted", event: NewMicrophoneMuted(true), expected: KindMicrophoneMuted},
+		{name: "microphone unmuted", event: NewMicrophoneUnmuted(), expected: KindMicrophoneUnmuted},
+		{name: "tool call progress", event: NewToolCallProgress("id", "lookup", "Let me check."), expected: KindToolCallProgress},
+		{name: "tool call status", event: NewToolCallStatus("id", "search", "Searched 3 of 10 stores"), expected: KindToolCallProgress},
+		{name: "tool call approval requested", event: NewToolCallApprovalRequested("id", "delete_file", `{"path":"notes.txt"}`), expected: KindToolCallApprovalRequested},
+		{name: "tool call output flagged", event: NewToolCallOutputFlagged("id", "search", "asks to reveal the prompt"), expected: KindToolCallOutputFlagged},
+		{name: "playback marks timed out", event: NewPlaybackMarksTimedOut(2), expected: KindPlaybackMarksTimedOut},
+		{name: "assistant response segment replaced", event: NewAssistantResponseSegmentReplaced("old", "new"), expected: KindAssistantResponseSegmentReplaced},
+		{name: "connection lost", event: NewConnectionLost("speech_to_text", "error"), expected: KindConnectionLost},
+		{name: "connection restored", event: NewConnectionRestored("speech_to_text"), expected: KindConnectionRestored},
 	}
 
 	for _, testCase := range testCases {
@@ -42,10 +81,113 @@ func TestConstructorsEmitExpectedKinds(t *testing.T) {
 			if got := testCase.event.Kind(); got != testCase.expected {
 				t.Fatalf("expected kind %q, got %q", testCase.expected, got)
 			}
+
 		})
 	}
 }
 
+// filledValue returns a value of t with every exported field set, so round
+// trips show fields that are lost.
+func filledValue(t reflect.Type) reflect.Value {
+	value := reflect.New(t).Elem()
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		value.Set(reflect.ValueOf(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)))
+	case t == reflect.TypeFor[Base]():
+	case t.Kind() == reflect.String:
+		value.SetString("value")
+	case t.Kind() == reflect.Bool:
+		value.SetBool(true)
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		value.SetInt(2)
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		value.SetUint(2)
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		value.SetFloat(1.5)
+	case t.Kind() == reflect.Slice:
+		value.Set(reflect.Append(value, filledValue(t.Elem())))
+	case t.Kind() == reflect.Map:
+		value.Set(reflect.MakeMap(t))
+		value.SetMapIndex(filledValue(t.Key()), filledValue(t.Elem()))
+	case t.Kind() == reflect.Pointer:
+		pointer := reflect.New(t.Elem())
+		pointer.Elem().Set(filledValue(t.Elem()))
+		value.Set(pointer)
+	case t.Kind() == reflect.Struct:
+		for i := range t.NumField() {
+			if t.Field(i).IsExported() {
+				value.Field(i).Set(filledValue(t.Field(i).Type))
+			}
+		}
+	}
+	return value
+}
+
+func TestEveryRegisteredKindRoundTrips(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for kind, eventType := range eventTypes {
+		t.Run(string(kind), func(t *testing.T) {
+			value := filledValue(eventType)
+			value.FieldByName("Base").Set(reflect.ValueOf(Base{kind: kind, timestamp: timestamp}))
+			event := value.Interface().(Event)
+
+			encoded, err := MarshalEvent(event)
+			if err != nil {
+				t.Fatalf("unexpected marshal error: %v", err)
+			}
+			parsed, err := ParseEvent(encoded)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			if !reflect.DeepEqual(parsed, event) {
+				t.Fatalf("expected the envelope to round-trip\n%+v\ngot\n%+v", event, parsed)
+			}
+
+			direct, err := json.Marshal(event)
+			if err != nil {
+				t.Fatalf("unexpected marshal error: %v", err)
+			}
+			decoded := reflect.New(eventType)
+			if err := json.Unmarshal(direct, decoded.Interface()); err != nil {
+				t.Fatalf("unexpected unmarshal error: %v", err)
+			}
+			if !reflect.DeepEqual(decoded.Elem().Interface(), event) {
+				t.Fatalf("expected json.Marshal to round-trip with the kind and timestamp, got %s", direct)
+			}
+		})
+	}
+}
+
+func TestParseEventRejectsUnknownKinds(t *testing.T) {
+	if _, err := ParseEvent([]byte(`{"version":1,"kind":"future.kind","data":{}}`)); !errors.Is(err, ErrUnknownKind) {
+		t.Fatalf("expected ErrUnknownKind, got %v", err)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal([]byte(`{"version":1,"kind":"assistant_playback.mark_played","data":{"Mark":"m1","Transcript":"Hi."}}`), &envelope); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if played, ok := envelope.Data.(AssistantPlaybackMarkPlayed); !ok || played.Mark != "m1" || played.Transcript != "Hi." {
+		t.Fatalf("expected the envelope data to decode to the typed event, got %#v", envelope.Data)
+	}
+}
+
+func TestEnvelopeKeepsUnknownKinds(t *testing.T) {
+	payload := `{"version":1,"kind":"future.kind","timestamp":"2026-01-02T03:04:05Z","data":{"Detail":"new"}}`
+
+	var envelope Envelope
+	if err := json.Unmarshal([]byte(payload), &envelope); err != nil {
+		t.Fatalf("expected unknown kinds to decode, got %v", err)
+	}
+	unknown, ok := envelope.Data.(UnknownEvent)
+	if !ok || unknown.Kind() != "future.kind" || string(unknown.Data) != `{"Detail":"new"}` {
+		t.Fatalf("expected the data of the unknown kind to be kept, got %#v", envelope.Data)
+	}
+	if encoded, err := json.Marshal(envelope); err != nil || string(encoded) != payload {
+		t.Fatalf("expected the envelope to encode as received, got %s (%v)", encoded, err)
+	}
+}
+
 func TestUserSpeechStartedAndEndedKindsAreDistinct(t *testing.T) {
 	started := NewUserSpeechStarted()
 	ended := NewUserSpeechEnded()
@@ -54,3 +196,75 @@ func TestUserSpeechStartedAndEndedKindsAreDistinct(t *testing.T) {
 		t.Fatalf("expected speech started and speech ended kinds to differ, both were %q", started.Kind())
 	}
 }
+
+func TestKindNamespace(t *testing.T) {
+	if got := KindTurnStarted.Namespace(); got != "turn_state" {
+		t.Fatalf("expected turn_state namespace, got %q", got)
+	}
+	if got := KindAssistantPlaybackMarkPlayed.Namespace(); got != "assistant_playback" {
+		t.Fatalf("expected assistant_playback namespace, got %q", got)
+	}
+}
+
+func TestRetainCopiesBorrowedFrames(t *testing.T) {
+	audio := []byte{1, 2}
+	borrowed := NewBorrowedUserAudioFrame(audio)
+	retained, ok := Retain(borrowed).(UserAudioFrame)
+	if !ok {
+		t.Fatalf("expected retained event to stay a user audio frame")
+	}
+	audio[0] = 9
+
+	if retained.Borrowed() || retained.Audio[0] != 1 {
+		t.Fatalf("expected retained frame to own a copy of the audio, got %+v", retained)
+	}
+
+	owned := NewAssistantPlaybackFrame(audio)
+	if kept := owned.Retain(); &kept.Audio[0] != &audio[0] {
+		t.Fatal("expected frames that are not borrowed to be kept without copying")
+	}
+}
+
+func TestEnvelopeWireFormatV1(t *testing.T) {
+	envelope := NewEnvelope(NewTurnFailed("turn-id", "boom"))
+	envelope.Timestamp = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	encoded, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"version":1,"kind":"turn_state.failed","timestamp":"2026-01-02T03:04:05Z","data":{"TurnID":"turn-id","Error":"boom"}}`
+	if string(encoded) != expected {
+		t.Fatalf("wire format changed, expected\n%s\ngot\n%s", expected, encoded)
+	}
+}
+
+func TestDecodeEnvelopeAcceptsOlderVersions(t *testing.T) {
+	for name, payload := range map[string]string{
+		"v0": `{"kind":"turn_state.failed","timestamp":"2026-01-02T03:04:05Z","data":{"TurnID":"turn-id","Error":"boom"}}`,
+		"v1": `{"version":1,"kind":"turn_state.failed","timestamp":"2026-01-02T03:04:05Z","data":{"TurnID":"turn-id","Error":"boom","Added":true}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			envelope, err := DecodeEnvelope([]byte(payload))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var data struct{ TurnID, Error string }
+			if err := json.Unmarshal(envelope.Data, &data); err != nil {
+				t.Fatalf("unexpected data error: %v", err)
+			}
+			if envelope.Kind != KindTurnFailed || data.TurnID != "turn-id" || data.Error != "boom" {
+				t.Fatalf("unexpected envelope %+v with data %+v", envelope, data)
+			}
+		})
+	}
+}
+
+func TestDecodeEnvelopeRejectsNewerVersions(t *testing.T) {
+	_, err := DecodeEnvelope([]byte(`{"version":2,"kind":"turn_state.failed","data":{}}`))
+	if !errors.Is(err, ErrUnsupportedWireVersion) {
+		t.Fatalf("expected unsupported wire version error, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+//go:build ignore
+
+// gen_json writes json_gen.go, the MarshalJSON and UnmarshalJSON methods of
+// every event type registered in eventTypes of parse.go.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+)
+
+type eventType struct {
+	name string
+	kind string
+}
+
+func main() {
+	types, err := registeredTypes("parse.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].name < types[j].name })
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by gen_json.go; DO NOT EDIT.\n\npackage events\n")
+	for _, t := range types {
+		fmt.Fprintf(&out, `
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e %[1]s) MarshalJSON() ([]byte, error) {
+	type fields %[1]s
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *%[1]s) UnmarshalJSON(data []byte) error {
+	type fields %[1]s
+	return unmarshalEvent(data, %[2]s, &e.Base, (*fields)(e))
+}
+`, t.name, t.kind)
+	}
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("json_gen.go", formatted, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// registeredTypes reads the kinds and type names of the eventTypes map
+// literal in file.
+func registeredTypes(file string) ([]eventType, error) {
+	parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []eventType
+	ast.Inspect(parsed, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "eventTypes" {
+			return true
+		}
+		literal := spec.Values[0].(*ast.CompositeLit)
+		for _, element := range literal.Elts {
+			entry := element.(*ast.KeyValueExpr)
+			// reflect.TypeFor[T]()
+			index := entry.Value.(*ast.CallExpr).Fun.(*ast.IndexExpr)
+			types = append(types, eventType{
+				name: index.Index.(*ast.Ident).Name,
+				kind: entry.Key.(*ast.Ident).Name,
+			})
+		}
+		return false
+	})
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no event types registered in %s", file)
+	}
+	return types, nil
+}
@@ -0,0 +1,92 @@
+package events
+
+// The MarshalJSON and UnmarshalJSON methods of the event types registered in
+// eventTypes are generated into json_gen.go.
+//go:generate go run gen_json.go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// eventHeader is the kind and timestamp the JSON of an event starts with,
+// followed by the exported fields of its type.
+type eventHeader struct {
+	Kind      Kind      `json:"kind"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// marshalEvent encodes fields, an event converted to a type without methods,
+// after the header of base.
+func marshalEvent(base Base, fields any) ([]byte, error) {
+	header, err := json.Marshal(eventHeader{Kind: base.kind, Timestamp: base.timestamp})
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	if string(data) == "{}" {
+		return header, nil
+	}
+	return append(append(header[:len(header)-1], ','), data[1:]...), nil
+}
+
+// unmarshalEvent decodes an event of kind encoded by marshalEvent into base
+// and fields. The kind is optional, e.g. in the data of envelopes, but must
+// match when present.
+func unmarshalEvent(data []byte, kind Kind, base *Base, fields any) error {
+	var header eventHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+	if header.Kind != "" && header.Kind != kind {
+		return fmt.Errorf("cannot decode a %s event as %s", header.Kind, kind)
+	}
+	if err := json.Unmarshal(data, fields); err != nil {
+		return err
+	}
+	*base = Base{kind: kind, timestamp: header.Timestamp}
+	return nil
+}
+
+// withoutEventHeader drops the kind and timestamp from the JSON of an event,
+// keeping the order of its fields, for the data of envelopes which carry
+// them themselves.
+func withoutEventHeader(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return data, err
+	}
+
+	var stripped bytes.Buffer
+	stripped.WriteByte('{')
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		if key == "kind" || key == "timestamp" {
+			continue
+		}
+		if stripped.Len() > 1 {
+			stripped.WriteByte(',')
+		}
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		stripped.Write(encodedKey)
+		stripped.WriteByte(':')
+		stripped.Write(value)
+	}
+	stripped.WriteByte('}')
+	return stripped.Bytes(), nil
+}
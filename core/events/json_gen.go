@@ -0,0 +1,711 @@
+// Code generated by gen_json.go; DO NOT EDIT.
+
+package events
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantPlaybackEnded) MarshalJSON() ([]byte, error) {
+	type fields AssistantPlaybackEnded
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantPlaybackEnded) UnmarshalJSON(data []byte) error {
+	type fields AssistantPlaybackEnded
+	return unmarshalEvent(data, KindAssistantPlaybackEnded, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantPlaybackFrame) MarshalJSON() ([]byte, error) {
+	type fields AssistantPlaybackFrame
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantPlaybackFrame) UnmarshalJSON(data []byte) error {
+	type fields AssistantPlaybackFrame
+	return unmarshalEvent(data, KindAssistantPlaybackFrame, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantPlaybackMarkPlayed) MarshalJSON() ([]byte, error) {
+	type fields AssistantPlaybackMarkPlayed
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantPlaybackMarkPlayed) UnmarshalJSON(data []byte) error {
+	type fields AssistantPlaybackMarkPlayed
+	return unmarshalEvent(data, KindAssistantPlaybackMarkPlayed, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantPlaybackStarted) MarshalJSON() ([]byte, error) {
+	type fields AssistantPlaybackStarted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantPlaybackStarted) UnmarshalJSON(data []byte) error {
+	type fields AssistantPlaybackStarted
+	return unmarshalEvent(data, KindAssistantPlaybackStarted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantPlaybackTranscriptSegment) MarshalJSON() ([]byte, error) {
+	type fields AssistantPlaybackTranscriptSegment
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantPlaybackTranscriptSegment) UnmarshalJSON(data []byte) error {
+	type fields AssistantPlaybackTranscriptSegment
+	return unmarshalEvent(data, KindAssistantPlaybackTranscriptSegment, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantPlaybackTranscriptUpdated) MarshalJSON() ([]byte, error) {
+	type fields AssistantPlaybackTranscriptUpdated
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantPlaybackTranscriptUpdated) UnmarshalJSON(data []byte) error {
+	type fields AssistantPlaybackTranscriptUpdated
+	return unmarshalEvent(data, KindAssistantPlaybackTranscriptUpdated, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantResponseFinal) MarshalJSON() ([]byte, error) {
+	type fields AssistantResponseFinal
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantResponseFinal) UnmarshalJSON(data []byte) error {
+	type fields AssistantResponseFinal
+	return unmarshalEvent(data, KindAssistantResponseFinal, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantResponseFinalized) MarshalJSON() ([]byte, error) {
+	type fields AssistantResponseFinalized
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantResponseFinalized) UnmarshalJSON(data []byte) error {
+	type fields AssistantResponseFinalized
+	return unmarshalEvent(data, KindAssistantResponseFinalized, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantResponseSegment) MarshalJSON() ([]byte, error) {
+	type fields AssistantResponseSegment
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantResponseSegment) UnmarshalJSON(data []byte) error {
+	type fields AssistantResponseSegment
+	return unmarshalEvent(data, KindAssistantResponseSegment, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantResponseSegmentReplaced) MarshalJSON() ([]byte, error) {
+	type fields AssistantResponseSegmentReplaced
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantResponseSegmentReplaced) UnmarshalJSON(data []byte) error {
+	type fields AssistantResponseSegmentReplaced
+	return unmarshalEvent(data, KindAssistantResponseSegmentReplaced, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantResponseStarted) MarshalJSON() ([]byte, error) {
+	type fields AssistantResponseStarted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantResponseStarted) UnmarshalJSON(data []byte) error {
+	type fields AssistantResponseStarted
+	return unmarshalEvent(data, KindAssistantResponseStarted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantSpeechFinal) MarshalJSON() ([]byte, error) {
+	type fields AssistantSpeechFinal
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantSpeechFinal) UnmarshalJSON(data []byte) error {
+	type fields AssistantSpeechFinal
+	return unmarshalEvent(data, KindAssistantSpeechFinal, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantSpeechFrame) MarshalJSON() ([]byte, error) {
+	type fields AssistantSpeechFrame
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantSpeechFrame) UnmarshalJSON(data []byte) error {
+	type fields AssistantSpeechFrame
+	return unmarshalEvent(data, KindAssistantSpeechFrame, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantSpeechMarkGenerated) MarshalJSON() ([]byte, error) {
+	type fields AssistantSpeechMarkGenerated
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantSpeechMarkGenerated) UnmarshalJSON(data []byte) error {
+	type fields AssistantSpeechMarkGenerated
+	return unmarshalEvent(data, KindAssistantSpeechMarkGenerated, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AssistantSpeechTimestamps) MarshalJSON() ([]byte, error) {
+	type fields AssistantSpeechTimestamps
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AssistantSpeechTimestamps) UnmarshalJSON(data []byte) error {
+	type fields AssistantSpeechTimestamps
+	return unmarshalEvent(data, KindAssistantSpeechTimestamps, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AudioAlwaysCaptureChanged) MarshalJSON() ([]byte, error) {
+	type fields AudioAlwaysCaptureChanged
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AudioAlwaysCaptureChanged) UnmarshalJSON(data []byte) error {
+	type fields AudioAlwaysCaptureChanged
+	return unmarshalEvent(data, KindAudioAlwaysCaptureChanged, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AudioCaptureFailed) MarshalJSON() ([]byte, error) {
+	type fields AudioCaptureFailed
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AudioCaptureFailed) UnmarshalJSON(data []byte) error {
+	type fields AudioCaptureFailed
+	return unmarshalEvent(data, KindAudioCaptureFailed, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AudioCaptureStarted) MarshalJSON() ([]byte, error) {
+	type fields AudioCaptureStarted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AudioCaptureStarted) UnmarshalJSON(data []byte) error {
+	type fields AudioCaptureStarted
+	return unmarshalEvent(data, KindAudioCaptureStarted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e AudioCaptureStopped) MarshalJSON() ([]byte, error) {
+	type fields AudioCaptureStopped
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *AudioCaptureStopped) UnmarshalJSON(data []byte) error {
+	type fields AudioCaptureStopped
+	return unmarshalEvent(data, KindAudioCaptureStopped, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e BackchannelPlayed) MarshalJSON() ([]byte, error) {
+	type fields BackchannelPlayed
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *BackchannelPlayed) UnmarshalJSON(data []byte) error {
+	type fields BackchannelPlayed
+	return unmarshalEvent(data, KindBackchannelPlayed, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e BudgetExceeded) MarshalJSON() ([]byte, error) {
+	type fields BudgetExceeded
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *BudgetExceeded) UnmarshalJSON(data []byte) error {
+	type fields BudgetExceeded
+	return unmarshalEvent(data, KindBudgetExceeded, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ChatMessageFinalized) MarshalJSON() ([]byte, error) {
+	type fields ChatMessageFinalized
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ChatMessageFinalized) UnmarshalJSON(data []byte) error {
+	type fields ChatMessageFinalized
+	return unmarshalEvent(data, KindChatMessageFinalized, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ChatMessageUpdated) MarshalJSON() ([]byte, error) {
+	type fields ChatMessageUpdated
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ChatMessageUpdated) UnmarshalJSON(data []byte) error {
+	type fields ChatMessageUpdated
+	return unmarshalEvent(data, KindChatMessageUpdated, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e CompliancePauseEnded) MarshalJSON() ([]byte, error) {
+	type fields CompliancePauseEnded
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *CompliancePauseEnded) UnmarshalJSON(data []byte) error {
+	type fields CompliancePauseEnded
+	return unmarshalEvent(data, KindCompliancePauseEnded, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e CompliancePauseStarted) MarshalJSON() ([]byte, error) {
+	type fields CompliancePauseStarted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *CompliancePauseStarted) UnmarshalJSON(data []byte) error {
+	type fields CompliancePauseStarted
+	return unmarshalEvent(data, KindCompliancePauseStarted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ConnectionLost) MarshalJSON() ([]byte, error) {
+	type fields ConnectionLost
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ConnectionLost) UnmarshalJSON(data []byte) error {
+	type fields ConnectionLost
+	return unmarshalEvent(data, KindConnectionLost, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ConnectionRestored) MarshalJSON() ([]byte, error) {
+	type fields ConnectionRestored
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ConnectionRestored) UnmarshalJSON(data []byte) error {
+	type fields ConnectionRestored
+	return unmarshalEvent(data, KindConnectionRestored, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ConversationAnalyzed) MarshalJSON() ([]byte, error) {
+	type fields ConversationAnalyzed
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ConversationAnalyzed) UnmarshalJSON(data []byte) error {
+	type fields ConversationAnalyzed
+	return unmarshalEvent(data, KindConversationAnalyzed, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ConversationEnded) MarshalJSON() ([]byte, error) {
+	type fields ConversationEnded
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ConversationEnded) UnmarshalJSON(data []byte) error {
+	type fields ConversationEnded
+	return unmarshalEvent(data, KindConversationEnded, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e DegradationRecovered) MarshalJSON() ([]byte, error) {
+	type fields DegradationRecovered
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *DegradationRecovered) UnmarshalJSON(data []byte) error {
+	type fields DegradationRecovered
+	return unmarshalEvent(data, KindDegradationRecovered, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e DegradedToTextOnly) MarshalJSON() ([]byte, error) {
+	type fields DegradedToTextOnly
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *DegradedToTextOnly) UnmarshalJSON(data []byte) error {
+	type fields DegradedToTextOnly
+	return unmarshalEvent(data, KindDegradedToTextOnly, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e MicrophoneMuted) MarshalJSON() ([]byte, error) {
+	type fields MicrophoneMuted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *MicrophoneMuted) UnmarshalJSON(data []byte) error {
+	type fields MicrophoneMuted
+	return unmarshalEvent(data, KindMicrophoneMuted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e MicrophoneUnmuted) MarshalJSON() ([]byte, error) {
+	type fields MicrophoneUnmuted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *MicrophoneUnmuted) UnmarshalJSON(data []byte) error {
+	type fields MicrophoneUnmuted
+	return unmarshalEvent(data, KindMicrophoneUnmuted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e OutcomeLabeled) MarshalJSON() ([]byte, error) {
+	type fields OutcomeLabeled
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *OutcomeLabeled) UnmarshalJSON(data []byte) error {
+	type fields OutcomeLabeled
+	return unmarshalEvent(data, KindOutcomeLabeled, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e PanicRecovered) MarshalJSON() ([]byte, error) {
+	type fields PanicRecovered
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *PanicRecovered) UnmarshalJSON(data []byte) error {
+	type fields PanicRecovered
+	return unmarshalEvent(data, KindPanicRecovered, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e PlaybackMarksTimedOut) MarshalJSON() ([]byte, error) {
+	type fields PlaybackMarksTimedOut
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *PlaybackMarksTimedOut) UnmarshalJSON(data []byte) error {
+	type fields PlaybackMarksTimedOut
+	return unmarshalEvent(data, KindPlaybackMarksTimedOut, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e TenantLimitExceeded) MarshalJSON() ([]byte, error) {
+	type fields TenantLimitExceeded
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *TenantLimitExceeded) UnmarshalJSON(data []byte) error {
+	type fields TenantLimitExceeded
+	return unmarshalEvent(data, KindTenantLimitExceeded, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e TextTranslated) MarshalJSON() ([]byte, error) {
+	type fields TextTranslated
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *TextTranslated) UnmarshalJSON(data []byte) error {
+	type fields TextTranslated
+	return unmarshalEvent(data, KindTextTranslated, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ToolCallApprovalRequested) MarshalJSON() ([]byte, error) {
+	type fields ToolCallApprovalRequested
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ToolCallApprovalRequested) UnmarshalJSON(data []byte) error {
+	type fields ToolCallApprovalRequested
+	return unmarshalEvent(data, KindToolCallApprovalRequested, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ToolCallCompleted) MarshalJSON() ([]byte, error) {
+	type fields ToolCallCompleted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ToolCallCompleted) UnmarshalJSON(data []byte) error {
+	type fields ToolCallCompleted
+	return unmarshalEvent(data, KindToolCallCompleted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ToolCallFailed) MarshalJSON() ([]byte, error) {
+	type fields ToolCallFailed
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ToolCallFailed) UnmarshalJSON(data []byte) error {
+	type fields ToolCallFailed
+	return unmarshalEvent(data, KindToolCallFailed, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ToolCallOutputFlagged) MarshalJSON() ([]byte, error) {
+	type fields ToolCallOutputFlagged
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ToolCallOutputFlagged) UnmarshalJSON(data []byte) error {
+	type fields ToolCallOutputFlagged
+	return unmarshalEvent(data, KindToolCallOutputFlagged, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ToolCallProgress) MarshalJSON() ([]byte, error) {
+	type fields ToolCallProgress
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ToolCallProgress) UnmarshalJSON(data []byte) error {
+	type fields ToolCallProgress
+	return unmarshalEvent(data, KindToolCallProgress, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e ToolCallStarted) MarshalJSON() ([]byte, error) {
+	type fields ToolCallStarted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *ToolCallStarted) UnmarshalJSON(data []byte) error {
+	type fields ToolCallStarted
+	return unmarshalEvent(data, KindToolCallStarted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e TurnCancelled) MarshalJSON() ([]byte, error) {
+	type fields TurnCancelled
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *TurnCancelled) UnmarshalJSON(data []byte) error {
+	type fields TurnCancelled
+	return unmarshalEvent(data, KindTurnCancelled, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e TurnCompleted) MarshalJSON() ([]byte, error) {
+	type fields TurnCompleted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *TurnCompleted) UnmarshalJSON(data []byte) error {
+	type fields TurnCompleted
+	return unmarshalEvent(data, KindTurnCompleted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e TurnFailed) MarshalJSON() ([]byte, error) {
+	type fields TurnFailed
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *TurnFailed) UnmarshalJSON(data []byte) error {
+	type fields TurnFailed
+	return unmarshalEvent(data, KindTurnFailed, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e TurnStalled) MarshalJSON() ([]byte, error) {
+	type fields TurnStalled
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *TurnStalled) UnmarshalJSON(data []byte) error {
+	type fields TurnStalled
+	return unmarshalEvent(data, KindTurnStalled, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e TurnStarted) MarshalJSON() ([]byte, error) {
+	type fields TurnStarted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *TurnStarted) UnmarshalJSON(data []byte) error {
+	type fields TurnStarted
+	return unmarshalEvent(data, KindTurnStarted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e TurnStateChanged) MarshalJSON() ([]byte, error) {
+	type fields TurnStateChanged
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *TurnStateChanged) UnmarshalJSON(data []byte) error {
+	type fields TurnStateChanged
+	return unmarshalEvent(data, KindTurnStateChanged, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e UserAudioFrame) MarshalJSON() ([]byte, error) {
+	type fields UserAudioFrame
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *UserAudioFrame) UnmarshalJSON(data []byte) error {
+	type fields UserAudioFrame
+	return unmarshalEvent(data, KindUserAudioFrame, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e UserMessageTyped) MarshalJSON() ([]byte, error) {
+	type fields UserMessageTyped
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *UserMessageTyped) UnmarshalJSON(data []byte) error {
+	type fields UserMessageTyped
+	return unmarshalEvent(data, KindUserMessageTyped, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e UserSpeechEnded) MarshalJSON() ([]byte, error) {
+	type fields UserSpeechEnded
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *UserSpeechEnded) UnmarshalJSON(data []byte) error {
+	type fields UserSpeechEnded
+	return unmarshalEvent(data, KindUserSpeechEnded, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e UserSpeechStarted) MarshalJSON() ([]byte, error) {
+	type fields UserSpeechStarted
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *UserSpeechStarted) UnmarshalJSON(data []byte) error {
+	type fields UserSpeechStarted
+	return unmarshalEvent(data, KindUserSpeechStarted, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e UserTranscriptFinal) MarshalJSON() ([]byte, error) {
+	type fields UserTranscriptFinal
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *UserTranscriptFinal) UnmarshalJSON(data []byte) error {
+	type fields UserTranscriptFinal
+	return unmarshalEvent(data, KindUserTranscriptFinal, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e UserTranscriptInterimSegmentUpdated) MarshalJSON() ([]byte, error) {
+	type fields UserTranscriptInterimSegmentUpdated
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *UserTranscriptInterimSegmentUpdated) UnmarshalJSON(data []byte) error {
+	type fields UserTranscriptInterimSegmentUpdated
+	return unmarshalEvent(data, KindUserTranscriptInterimSegmentUpdated, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e UserTranscriptInterimUpdated) MarshalJSON() ([]byte, error) {
+	type fields UserTranscriptInterimUpdated
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *UserTranscriptInterimUpdated) UnmarshalJSON(data []byte) error {
+	type fields UserTranscriptInterimUpdated
+	return unmarshalEvent(data, KindUserTranscriptInterimUpdated, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e UserTranscriptSegment) MarshalJSON() ([]byte, error) {
+	type fields UserTranscriptSegment
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *UserTranscriptSegment) UnmarshalJSON(data []byte) error {
+	type fields UserTranscriptSegment
+	return unmarshalEvent(data, KindUserTranscriptSegment, &e.Base, (*fields)(e))
+}
+
+// MarshalJSON encodes the event with its kind and timestamp.
+func (e UserWakeWordDetected) MarshalJSON() ([]byte, error) {
+	type fields UserWakeWordDetected
+	return marshalEvent(e.Base, fields(e))
+}
+
+// UnmarshalJSON decodes an event encoded by MarshalJSON.
+func (e *UserWakeWordDetected) UnmarshalJSON(data []byte) error {
+	type fields UserWakeWordDetected
+	return unmarshalEvent(data, KindUserWakeWordDetected, &e.Base, (*fields)(e))
+}
@@ -0,0 +1,184 @@
+// Package kafka exports orchestration events to Kafka topics.
+//
+// Events are produced as JSON [events.Envelope] payloads. The sink does not
+// depend on a Kafka client library, the application adapts its producer to
+// [Producer], e.g. for github.com/segmentio/kafka-go:
+//
+//	type producer struct{ w *kafka.Writer }
+//
+//	func (p producer) Produce(ctx context.Context, topic string, key, value []byte) error {
+//		return p.w.WriteMessages(ctx, kafka.Message{Topic: topic, Key: key, Value: value})
+//	}
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	defaultTopicPrefix = "ema"
+	defaultQueueSize   = 1024
+)
+
+// Producer produces a single record to a Kafka topic.
+type Producer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// Sink produces events to Kafka, it implements [events.Sink].
+//
+// Events are queued and produced in order by a single background worker so
+// Handle never blocks on the broker.
+type Sink struct {
+	producer   Producer
+	topic      events.TopicFunc
+	key        []byte
+	namespaces []string
+
+	queue     chan events.Event
+	done      chan struct{}
+	closeOnce sync.Once
+	closeMu   sync.RWMutex
+	closed    bool
+}
+
+type SinkOptions struct {
+	topic      events.TopicFunc
+	key        []byte
+	namespaces []string
+	queueSize  int
+}
+
+type SinkOption func(*SinkOptions)
+
+// WithTopic sets how the topic of an event is chosen, defaults to
+// [events.TopicPerNamespace] with the "ema" prefix.
+func WithTopic(topic events.TopicFunc) SinkOption {
+	return func(o *SinkOptions) {
+		o.topic = topic
+	}
+}
+
+// WithSessionKey keys all records with sessionID so that a session's events
+// land in a single partition and keep their order.
+func WithSessionKey(sessionID string) SinkOption {
+	return func(o *SinkOptions) {
+		o.key = []byte(sessionID)
+	}
+}
+
+// WithNamespaces restricts the exported events to the given namespaces, all
+// events are exported by default.
+func WithNamespaces(namespaces ...string) SinkOption {
+	return func(o *SinkOptions) {
+		o.namespaces = namespaces
+	}
+}
+
+// WithQueueSize sets how many events can wait to be produced before new
+// events are dropped.
+func WithQueueSize(size int) SinkOption {
+	return func(o *SinkOptions) {
+		o.queueSize = size
+	}
+}
+
+// NewSink creates a sink producing to producer and starts its worker.
+func NewSink(producer Producer, opts ...SinkOption) *Sink {
+	options := SinkOptions{
+		topic:     events.TopicPerNamespace(defaultTopicPrefix),
+		queueSize: defaultQueueSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.queueSize < 1 {
+		options.queueSize = 1
+	}
+
+	s := &Sink{
+		producer:   producer,
+		topic:      options.topic,
+		key:        options.key,
+		namespaces: options.namespaces,
+		queue:      make(chan events.Event, options.queueSize),
+		done:       make(chan struct{}),
+	}
+	go s.produceLoop()
+
+	return s
+}
+
+// Handle queues event for producing if its namespace is selected.
+func (s *Sink) Handle(event events.Event) {
+	if len(s.namespaces) > 0 && !slices.Contains(s.namespaces, event.Kind().Namespace()) {
+		return
+	}
+
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.queue <- events.Retain(event):
+	default:
+		logger.Warn("kafka queue full, dropping event", "kind", event.Kind())
+	}
+}
+
+// Close stops accepting events and waits until queued events are produced
+// or ctx is done.
+func (s *Sink) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		s.closeMu.Lock()
+		s.closed = true
+		close(s.queue)
+		s.closeMu.Unlock()
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Sink) produceLoop() {
+	defer close(s.done)
+	for event := range s.queue {
+		s.produce(context.Background(), event)
+	}
+}
+
+func (s *Sink) produce(ctx context.Context, event events.Event) {
+	topic := s.topic(event)
+	ctx, span := tracer.Start(ctx, "produce event")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("event.kind", string(event.Kind())),
+		attribute.String("kafka.topic", topic),
+	)
+
+	payload, err := json.Marshal(events.NewEnvelope(event))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to marshal event")
+		return
+	}
+
+	if err := s.producer.Produce(ctx, topic, s.key, payload); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to produce event")
+		logger.Error("failed to produce event to kafka", "kind", event.Kind(), "error", err)
+	}
+}
@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestSinkProducesQueuedEventsInOrder(t *testing.T) {
+	producer := &recordingProducer{}
+	sink := NewSink(producer, WithSessionKey("session-1"))
+
+	sink.Handle(events.NewTurnStarted("turn-1", "hello"))
+	sink.Handle(events.NewAssistantResponseSegment("hi"))
+	sink.Handle(events.NewTurnCompleted("turn-1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedTopics := []string{"ema.turn_state", "ema.assistant_response", "ema.turn_state"}
+	if len(producer.topics) != len(expectedTopics) {
+		t.Fatalf("expected topics %v, got %v", expectedTopics, producer.topics)
+	}
+	for i, topic := range expectedTopics {
+		if producer.topics[i] != topic {
+			t.Fatalf("expected topics %v, got %v", expectedTopics, producer.topics)
+		}
+		if string(producer.keys[i]) != "session-1" {
+			t.Fatalf("expected session key, got %q", producer.keys[i])
+		}
+	}
+
+	sink.Handle(events.NewTurnStarted("turn-2", "late"))
+	if len(producer.topics) != len(expectedTopics) {
+		t.Fatalf("expected events after close to be ignored")
+	}
+}
+
+type recordingProducer struct {
+	mu     sync.Mutex
+	topics []string
+	keys   [][]byte
+}
+
+func (p *recordingProducer) Produce(_ context.Context, topic string, key, _ []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.topics = append(p.topics, topic)
+	p.keys = append(p.keys, key)
+	return nil
+}
@@ -0,0 +1,368 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	packetConnect    byte = 1
+	packetConnAck    byte = 2
+	packetPublish    byte = 3
+	packetPubAck     byte = 4
+	packetPubRec     byte = 5
+	packetPubRel     byte = 6
+	packetPubComp    byte = 7
+	packetPingReq    byte = 12
+	packetPingResp   byte = 13
+	packetDisconnect byte = 14
+
+	protocolLevel311 byte = 4
+
+	defaultKeepAlive = 30 * time.Second
+)
+
+// ErrClientClosed is returned when publishing on a closed or disconnected
+// client.
+var ErrClientClosed = errors.New("mqtt client closed")
+
+// Client is a minimal MQTT 3.1.1 client that only publishes, it implements
+// [Publisher].
+//
+// Publishing with [AtLeastOnce] or [ExactlyOnce] waits for the broker
+// acknowledgements. The client does not reconnect, a lost connection fails
+// all further publishes with [ErrClientClosed].
+type Client struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+
+	pendingMu    sync.Mutex
+	pending      map[uint16]chan byte
+	nextPacketID uint16
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+type ClientOptions struct {
+	clientID  string
+	username  string
+	password  string
+	keepAlive time.Duration
+	dial      func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithClientID sets the client identifier, the broker assigns one when
+// empty.
+func WithClientID(id string) ClientOption {
+	return func(o *ClientOptions) {
+		o.clientID = id
+	}
+}
+
+// WithAuth sets the username and password sent on connect.
+func WithAuth(username, password string) ClientOption {
+	return func(o *ClientOptions) {
+		o.username = username
+		o.password = password
+	}
+}
+
+// WithKeepAlive sets the keep alive interval, defaults to 30 seconds.
+func WithKeepAlive(keepAlive time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.keepAlive = keepAlive
+	}
+}
+
+// WithDialer sets how the connection to the broker is opened, e.g. to use
+// TLS with (&tls.Dialer{Config: cfg}).DialContext.
+func WithDialer(dial func(ctx context.Context, network, address string) (net.Conn, error)) ClientOption {
+	return func(o *ClientOptions) {
+		o.dial = dial
+	}
+}
+
+// Dial connects to the broker at address and waits for it to accept the
+// connection.
+func Dial(ctx context.Context, address string, opts ...ClientOption) (*Client, error) {
+	options := ClientOptions{
+		keepAlive: defaultKeepAlive,
+		dial:      (&net.Dialer{}).DialContext,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	conn, err := options.dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to mqtt broker: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	reader := bufio.NewReader(conn)
+	if err := connect(conn, reader, options); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Client{
+		conn:    conn,
+		pending: map[uint16]chan byte{},
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(reader)
+	if options.keepAlive > 0 {
+		go c.keepAliveLoop(options.keepAlive)
+	}
+
+	return c, nil
+}
+
+func connect(w io.Writer, r *bufio.Reader, options ClientOptions) error {
+	var flags byte = 0x02 // clean session
+	payload := appendString(nil, options.clientID)
+	if options.username != "" {
+		flags |= 0x80
+		payload = appendString(payload, options.username)
+		if options.password != "" {
+			flags |= 0x40
+			payload = appendString(payload, options.password)
+		}
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel311, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(options.keepAlive/time.Second))
+	body = append(body, payload...)
+	if _, err := w.Write(encodePacket(packetConnect<<4, body)); err != nil {
+		return fmt.Errorf("failed to send connect: %w", err)
+	}
+
+	header, body, err := readPacket(r)
+	if err != nil {
+		return fmt.Errorf("failed to read connack: %w", err)
+	}
+	if header>>4 != packetConnAck || len(body) != 2 {
+		return fmt.Errorf("unexpected packet %d instead of connack", header>>4)
+	}
+	if body[1] != 0 {
+		return fmt.Errorf("mqtt broker refused connection with code %d", body[1])
+	}
+	return nil
+}
+
+// Publish sends payload to topic and waits for the acknowledgements that
+// qos requires.
+func (c *Client) Publish(ctx context.Context, topic string, qos QoS, retain bool, payload []byte) error {
+	if qos > ExactlyOnce {
+		return fmt.Errorf("invalid qos %d", qos)
+	}
+
+	header := packetPublish<<4 | byte(qos)<<1
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+
+	var packetID uint16
+	var acks chan byte
+	if qos > AtMostOnce {
+		packetID, acks = c.reservePacketID()
+		defer c.releasePacketID(packetID)
+		body = binary.BigEndian.AppendUint16(body, packetID)
+	}
+	body = append(body, payload...)
+
+	if err := c.write(encodePacket(header, body)); err != nil {
+		return err
+	}
+
+	switch qos {
+	case AtLeastOnce:
+		return c.awaitAck(ctx, acks, packetPubAck)
+	case ExactlyOnce:
+		if err := c.awaitAck(ctx, acks, packetPubRec); err != nil {
+			return err
+		}
+		if err := c.write(encodePacket(packetPubRel<<4|0x02, binary.BigEndian.AppendUint16(nil, packetID))); err != nil {
+			return err
+		}
+		return c.awaitAck(ctx, acks, packetPubComp)
+	}
+	return nil
+}
+
+// Close disconnects from the broker.
+func (c *Client) Close() error {
+	_ = c.write(encodePacket(packetDisconnect<<4, nil))
+	c.shutdown(ErrClientClosed)
+	return nil
+}
+
+func (c *Client) awaitAck(ctx context.Context, acks chan byte, expected byte) error {
+	select {
+	case ack := <-acks:
+		if ack != expected {
+			return fmt.Errorf("unexpected packet %d instead of %d", ack, expected)
+		}
+		return nil
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) reservePacketID() (uint16, chan byte) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	for {
+		c.nextPacketID++
+		if c.nextPacketID == 0 {
+			continue
+		}
+		if _, taken := c.pending[c.nextPacketID]; !taken {
+			break
+		}
+	}
+	acks := make(chan byte, 2)
+	c.pending[c.nextPacketID] = acks
+	return c.nextPacketID, acks
+}
+
+func (c *Client) releasePacketID(id uint16) {
+	c.pendingMu.Lock()
+	delete(c.pending, id)
+	c.pendingMu.Unlock()
+}
+
+func (c *Client) write(packet []byte) error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.conn.Write(packet); err != nil {
+		c.shutdown(fmt.Errorf("%w: %v", ErrClientClosed, err))
+		return c.err
+	}
+	return nil
+}
+
+func (c *Client) readLoop(r *bufio.Reader) {
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			c.shutdown(fmt.Errorf("%w: %v", ErrClientClosed, err))
+			return
+		}
+
+		switch packetType := header >> 4; packetType {
+		case packetPubAck, packetPubRec, packetPubComp:
+			if len(body) < 2 {
+				continue
+			}
+			c.pendingMu.Lock()
+			acks, ok := c.pending[binary.BigEndian.Uint16(body)]
+			c.pendingMu.Unlock()
+			if ok {
+				select {
+				case acks <- packetType:
+				default:
+				}
+			}
+		case packetPingResp:
+		default:
+			logger.Warn("ignoring unexpected mqtt packet", "type", packetType)
+		}
+	}
+}
+
+func (c *Client) keepAliveLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval * 3 / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			_ = c.write(encodePacket(packetPingReq<<4, nil))
+		}
+	}
+}
+
+func (c *Client) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.done)
+		_ = c.conn.Close()
+	})
+}
+
+func encodePacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, fmt.Errorf("malformed remaining length")
+		}
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
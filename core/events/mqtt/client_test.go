@@ -0,0 +1,135 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestClientPublishesWithAcknowledgements(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	published := make(chan brokerMessage, 4)
+	go serveFakeBroker(t, listener, published)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client, err := Dial(ctx, listener.Addr().String(), WithClientID("edge-1"), WithAuth("user", "secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	for _, qos := range []QoS{AtMostOnce, AtLeastOnce, ExactlyOnce} {
+		if err := client.Publish(ctx, "ema/turn_state", qos, qos == ExactlyOnce, []byte("payload")); err != nil {
+			t.Fatalf("unexpected error publishing with qos %d: %v", qos, err)
+		}
+
+		select {
+		case msg := <-published:
+			if msg.topic != "ema/turn_state" || msg.qos != qos || string(msg.payload) != "payload" || msg.retain != (qos == ExactlyOnce) {
+				t.Fatalf("unexpected message %+v", msg)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for qos %d message", qos)
+		}
+	}
+}
+
+func TestClientFailsPublishAfterConnectionLoss(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		r := bufio.NewReader(conn)
+		_, _, _ = readPacket(r)
+		_, _ = conn.Write(encodePacket(packetConnAck<<4, []byte{0, 0}))
+		_ = conn.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client, err := Dial(ctx, listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-client.done:
+	case <-ctx.Done():
+		t.Fatalf("timed out waiting for disconnect")
+	}
+	if err := client.Publish(ctx, "ema/turn_state", AtLeastOnce, false, nil); err == nil {
+		t.Fatalf("expected publish to fail")
+	}
+}
+
+type brokerMessage struct {
+	topic   string
+	qos     QoS
+	retain  bool
+	payload []byte
+}
+
+func serveFakeBroker(t *testing.T, listener net.Listener, published chan<- brokerMessage) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	header, body, err := readPacket(r)
+	if err != nil || header>>4 != packetConnect {
+		t.Errorf("expected connect packet, got %d (%v)", header>>4, err)
+		return
+	}
+	if flags := body[7]; flags&0xc0 != 0xc0 {
+		t.Errorf("expected username and password flags, got %08b", flags)
+	}
+	_, _ = conn.Write(encodePacket(packetConnAck<<4, []byte{0, 0}))
+
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		switch header >> 4 {
+		case packetPublish:
+			qos := QoS(header >> 1 & 0x03)
+			topicLength := int(binary.BigEndian.Uint16(body))
+			msg := brokerMessage{topic: string(body[2 : 2+topicLength]), qos: qos, retain: header&0x01 == 1}
+			rest := body[2+topicLength:]
+			if qos > AtMostOnce {
+				packetID := rest[:2]
+				rest = rest[2:]
+				if qos == AtLeastOnce {
+					_, _ = conn.Write(encodePacket(packetPubAck<<4, packetID))
+				} else {
+					_, _ = conn.Write(encodePacket(packetPubRec<<4, packetID))
+				}
+			}
+			msg.payload = rest
+			published <- msg
+		case packetPubRel:
+			_, _ = conn.Write(encodePacket(packetPubComp<<4, body))
+		case packetDisconnect:
+			return
+		}
+	}
+}
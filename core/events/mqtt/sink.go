@@ -0,0 +1,248 @@
+// Package mqtt exports orchestration events to an MQTT broker.
+//
+// Events are published as JSON [events.Envelope] payloads, which lets an
+// orchestrator running on an edge device report conversation state to IoT
+// backends. The sink publishes through any [Publisher]; [Dial] returns a
+// small MQTT 3.1.1 client without third party dependencies:
+//
+//	client, _ := emamqtt.Dial(ctx, "broker.local:1883", emamqtt.WithClientID("kitchen-speaker"))
+//	sink := emamqtt.NewSink(client,
+//		emamqtt.WithTopic(emamqtt.TopicTemplate("devices/kitchen/ema/{namespace}")),
+//		emamqtt.WithQoS(emamqtt.AtLeastOnce),
+//	)
+//	o.Orchestrate(ctx, orchestration.WithEventSink(sink))
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	defaultTopicTemplate = "ema/{namespace}"
+	defaultQueueSize     = 256
+)
+
+// QoS is the MQTT delivery guarantee of a published message.
+type QoS byte
+
+const (
+	AtMostOnce  QoS = 0
+	AtLeastOnce QoS = 1
+	ExactlyOnce QoS = 2
+)
+
+// Publisher publishes a single message to an MQTT topic.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, qos QoS, retain bool, payload []byte) error
+}
+
+// TopicTemplate builds topics by replacing placeholders in template:
+//
+//   - {namespace}: the event namespace, e.g. "turn_state"
+//   - {event}: the event name within its namespace, e.g. "started"
+//   - {kind}: the full kind with "/" separators, e.g. "turn_state/started"
+func TopicTemplate(template string) events.TopicFunc {
+	return func(event events.Event) string {
+		kind := string(event.Kind())
+		namespace := event.Kind().Namespace()
+		name := strings.TrimPrefix(strings.TrimPrefix(kind, namespace), ".")
+		return strings.NewReplacer(
+			"{namespace}", namespace,
+			"{event}", name,
+			"{kind}", strings.ReplaceAll(kind, ".", "/"),
+		).Replace(template)
+	}
+}
+
+// Sink publishes events to MQTT, it implements [events.Sink].
+//
+// Events are queued and published in order by a single background worker so
+// Handle never blocks waiting on broker acknowledgements.
+type Sink struct {
+	publisher    Publisher
+	topic        events.TopicFunc
+	qos          QoS
+	namespaceQoS map[string]QoS
+	retained     []string
+	namespaces   []string
+
+	queue     chan events.Event
+	done      chan struct{}
+	closeOnce sync.Once
+	closeMu   sync.RWMutex
+	closed    bool
+}
+
+type SinkOptions struct {
+	topic        events.TopicFunc
+	qos          QoS
+	namespaceQoS map[string]QoS
+	retained     []string
+	namespaces   []string
+	queueSize    int
+}
+
+type SinkOption func(*SinkOptions)
+
+// WithTopic sets how the topic of an event is chosen, defaults to
+// "ema/{namespace}", see [TopicTemplate].
+func WithTopic(topic events.TopicFunc) SinkOption {
+	return func(o *SinkOptions) {
+		o.topic = topic
+	}
+}
+
+// WithQoS sets the QoS events are published with, defaults to [AtMostOnce].
+func WithQoS(qos QoS) SinkOption {
+	return func(o *SinkOptions) {
+		o.qos = qos
+	}
+}
+
+// WithNamespaceQoS overrides the QoS for events of a single namespace, e.g.
+// to deliver "turn_state" at least once while audio frames stay best effort.
+func WithNamespaceQoS(namespace string, qos QoS) SinkOption {
+	return func(o *SinkOptions) {
+		if o.namespaceQoS == nil {
+			o.namespaceQoS = map[string]QoS{}
+		}
+		o.namespaceQoS[namespace] = qos
+	}
+}
+
+// WithRetained publishes events of the given namespaces as retained messages
+// so that clients subscribing later receive the latest state right away.
+func WithRetained(namespaces ...string) SinkOption {
+	return func(o *SinkOptions) {
+		o.retained = namespaces
+	}
+}
+
+// WithNamespaces restricts the exported events to the given namespaces, all
+// events are exported by default.
+func WithNamespaces(namespaces ...string) SinkOption {
+	return func(o *SinkOptions) {
+		o.namespaces = namespaces
+	}
+}
+
+// WithQueueSize sets how many events can wait to be published before new
+// events are dropped.
+func WithQueueSize(size int) SinkOption {
+	return func(o *SinkOptions) {
+		o.queueSize = size
+	}
+}
+
+// NewSink creates a sink publishing to publisher and starts its worker.
+func NewSink(publisher Publisher, opts ...SinkOption) *Sink {
+	options := SinkOptions{
+		topic:     TopicTemplate(defaultTopicTemplate),
+		qos:       AtMostOnce,
+		queueSize: defaultQueueSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.queueSize < 1 {
+		options.queueSize = 1
+	}
+
+	s := &Sink{
+		publisher:    publisher,
+		topic:        options.topic,
+		qos:          options.qos,
+		namespaceQoS: options.namespaceQoS,
+		retained:     options.retained,
+		namespaces:   options.namespaces,
+		queue:        make(chan events.Event, options.queueSize),
+		done:         make(chan struct{}),
+	}
+	go s.publishLoop()
+
+	return s
+}
+
+// Handle queues event for publishing if its namespace is selected.
+func (s *Sink) Handle(event events.Event) {
+	if len(s.namespaces) > 0 && !slices.Contains(s.namespaces, event.Kind().Namespace()) {
+		return
+	}
+
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.queue <- events.Retain(event):
+	default:
+		logger.Warn("mqtt queue full, dropping event", "kind", event.Kind())
+	}
+}
+
+// Close stops accepting events and waits until queued events are published
+// or ctx is done. It does not close the publisher.
+func (s *Sink) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		s.closeMu.Lock()
+		s.closed = true
+		close(s.queue)
+		s.closeMu.Unlock()
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Sink) publishLoop() {
+	defer close(s.done)
+	for event := range s.queue {
+		s.publish(context.Background(), event)
+	}
+}
+
+func (s *Sink) publish(ctx context.Context, event events.Event) {
+	namespace := event.Kind().Namespace()
+	topic := s.topic(event)
+	qos := s.qos
+	if namespaceQoS, ok := s.namespaceQoS[namespace]; ok {
+		qos = namespaceQoS
+	}
+	retain := slices.Contains(s.retained, namespace)
+
+	ctx, span := tracer.Start(ctx, "publish event")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("event.kind", string(event.Kind())),
+		attribute.String("mqtt.topic", topic),
+		attribute.Int("mqtt.qos", int(qos)),
+		attribute.Bool("mqtt.retain", retain),
+	)
+
+	payload, err := json.Marshal(events.NewEnvelope(event))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to marshal event")
+		return
+	}
+
+	if err := s.publisher.Publish(ctx, topic, qos, retain, payload); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to publish event")
+		logger.Error("failed to publish event to mqtt", "kind", event.Kind(), "error", err)
+	}
+}
@@ -0,0 +1,81 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestSinkPublishesWithTopicTemplateAndQoS(t *testing.T) {
+	publisher := &recordingPublisher{}
+	sink := NewSink(publisher,
+		WithTopic(TopicTemplate("devices/kitchen/{namespace}/{event}")),
+		WithQoS(AtMostOnce),
+		WithNamespaceQoS("turn_state", AtLeastOnce),
+		WithRetained("turn_state"),
+		WithNamespaces("turn_state", "tool_call"),
+	)
+
+	sink.Handle(events.NewTurnStarted("turn-1", "hello"))
+	sink.Handle(events.NewUserAudioFrame([]byte{1, 2}))
+	sink.Handle(events.NewToolCallStarted("call-1", "lookup", "{}"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(publisher.messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(publisher.messages))
+	}
+
+	turn := publisher.messages[0]
+	if turn.topic != "devices/kitchen/turn_state/started" || turn.qos != AtLeastOnce || !turn.retain {
+		t.Fatalf("unexpected turn message %+v", turn)
+	}
+	tool := publisher.messages[1]
+	if tool.topic != "devices/kitchen/tool_call/started" || tool.qos != AtMostOnce || tool.retain {
+		t.Fatalf("unexpected tool message %+v", tool)
+	}
+
+	var envelope struct {
+		Kind events.Kind `json:"kind"`
+	}
+	if err := json.Unmarshal(turn.payload, &envelope); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if envelope.Kind != events.KindTurnStarted {
+		t.Fatalf("expected %q, got %q", events.KindTurnStarted, envelope.Kind)
+	}
+}
+
+func TestTopicTemplateExpandsKind(t *testing.T) {
+	topic := TopicTemplate("ema/{kind}")(events.NewTurnCompleted("turn-1"))
+	if topic != "ema/turn_state/completed" {
+		t.Fatalf("unexpected topic %q", topic)
+	}
+}
+
+type publishedMessage struct {
+	topic   string
+	qos     QoS
+	retain  bool
+	payload []byte
+}
+
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages []publishedMessage
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, topic string, qos QoS, retain bool, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, publishedMessage{topic: topic, qos: qos, retain: retain, payload: payload})
+	return nil
+}
@@ -0,0 +1,86 @@
+// Package nats exports orchestration events to NATS subjects.
+//
+// Events are published as JSON [events.Envelope] payloads. The sink works on
+// top of any [Publisher], a connected *nats.Conn from github.com/nats-io/nats.go
+// can be passed directly:
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	sink := emanats.NewSink(nc, emanats.WithSubject(events.TopicPerSession("ema", sessionID)))
+//	o.Orchestrate(ctx, orchestration.WithEventSink(sink))
+package nats
+
+import (
+	"encoding/json"
+	"slices"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+const defaultSubjectPrefix = "ema"
+
+// Publisher publishes a message to a NATS subject.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// Sink publishes events to NATS, it implements [events.Sink].
+//
+// NATS clients buffer published messages, so events are published inline.
+type Sink struct {
+	publisher  Publisher
+	subject    events.TopicFunc
+	namespaces []string
+}
+
+type SinkOptions struct {
+	subject    events.TopicFunc
+	namespaces []string
+}
+
+type SinkOption func(*SinkOptions)
+
+// WithSubject sets how the subject of an event is chosen, defaults to
+// [events.TopicPerNamespace] with the "ema" prefix.
+func WithSubject(subject events.TopicFunc) SinkOption {
+	return func(o *SinkOptions) {
+		o.subject = subject
+	}
+}
+
+// WithNamespaces restricts the exported events to the given namespaces, all
+// events are exported by default.
+func WithNamespaces(namespaces ...string) SinkOption {
+	return func(o *SinkOptions) {
+		o.namespaces = namespaces
+	}
+}
+
+func NewSink(publisher Publisher, opts ...SinkOption) *Sink {
+	options := SinkOptions{subject: events.TopicPerNamespace(defaultSubjectPrefix)}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Sink{
+		publisher:  publisher,
+		subject:    options.subject,
+		namespaces: options.namespaces,
+	}
+}
+
+// Handle publishes event if its namespace is selected.
+func (s *Sink) Handle(event events.Event) {
+	if len(s.namespaces) > 0 && !slices.Contains(s.namespaces, event.Kind().Namespace()) {
+		return
+	}
+
+	payload, err := json.Marshal(events.NewEnvelope(event))
+	if err != nil {
+		logger.Error("failed to marshal event", "kind", event.Kind(), "error", err)
+		return
+	}
+
+	if err := s.publisher.Publish(s.subject(event), payload); err != nil {
+		logger.Error("failed to publish event to nats", "kind", event.Kind(), "error", err)
+	}
+}
@@ -0,0 +1,59 @@
+package nats
+
+import (
+	"encoding/json"
+	"testing"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestSinkPublishesEnvelopesPerNamespace(t *testing.T) {
+	publisher := &recordingPublisher{}
+	sink := NewSink(publisher, WithNamespaces("turn_state", "tool_call"))
+
+	sink.Handle(events.NewTurnStarted("turn-1", "hello"))
+	sink.Handle(events.NewUserAudioFrame([]byte{1, 2}))
+	sink.Handle(events.NewToolCallStarted("call-1", "lookup", "{}"))
+
+	expectedSubjects := []string{"ema.turn_state", "ema.tool_call"}
+	if len(publisher.subjects) != len(expectedSubjects) {
+		t.Fatalf("expected subjects %v, got %v", expectedSubjects, publisher.subjects)
+	}
+	for i, subject := range expectedSubjects {
+		if publisher.subjects[i] != subject {
+			t.Fatalf("expected subjects %v, got %v", expectedSubjects, publisher.subjects)
+		}
+	}
+
+	var envelope struct {
+		Kind events.Kind `json:"kind"`
+	}
+	if err := json.Unmarshal(publisher.payloads[0], &envelope); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if envelope.Kind != events.KindTurnStarted {
+		t.Fatalf("expected %q, got %q", events.KindTurnStarted, envelope.Kind)
+	}
+}
+
+func TestSinkPublishesPerSessionSubject(t *testing.T) {
+	publisher := &recordingPublisher{}
+	sink := NewSink(publisher, WithSubject(events.TopicPerSession("calls", "abc")))
+
+	sink.Handle(events.NewTurnCompleted("turn-1"))
+
+	if len(publisher.subjects) != 1 || publisher.subjects[0] != "calls.abc" {
+		t.Fatalf("expected session subject, got %v", publisher.subjects)
+	}
+}
+
+type recordingPublisher struct {
+	subjects []string
+	payloads [][]byte
+}
+
+func (p *recordingPublisher) Publish(subject string, data []byte) error {
+	p.subjects = append(p.subjects, subject)
+	p.payloads = append(p.payloads, data)
+	return nil
+}
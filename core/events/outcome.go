@@ -0,0 +1,20 @@
+package events
+
+// KindOutcomeLabeled identifies an outcome label being attached to a turn or
+// a conversation.
+const KindOutcomeLabeled Kind = "conversation.outcome_labeled"
+
+// OutcomeLabeled marks the application or a classifier labeling how a turn,
+// or the conversation if TurnID is empty, went.
+type OutcomeLabeled struct {
+	Base
+	TurnID    string `json:",omitempty"`
+	Label     string
+	Notes     string `json:",omitempty"`
+	LabeledBy string `json:",omitempty"`
+}
+
+// NewOutcomeLabeled creates an outcome labeled event.
+func NewOutcomeLabeled(turnID, label, notes, labeledBy string) OutcomeLabeled {
+	return OutcomeLabeled{Base: NewBase(KindOutcomeLabeled), TurnID: turnID, Label: label, Notes: notes, LabeledBy: labeledBy}
+}
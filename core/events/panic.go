@@ -0,0 +1,21 @@
+package events
+
+// KindPanicRecovered identifies a recovered worker panic.
+const KindPanicRecovered Kind = "panic.recovered"
+
+// PanicRecovered marks a pipeline worker panicking. The panic is contained,
+// the turn fails with the panic as its error.
+type PanicRecovered struct {
+	Base
+	TurnID string
+	// Worker names the panicked worker, e.g. "llm generation".
+	Worker string
+	// Value is the formatted value passed to panic.
+	Value string
+	Stack string
+}
+
+// NewPanicRecovered creates a panic recovered event.
+func NewPanicRecovered(turnID, worker, value, stack string) PanicRecovered {
+	return PanicRecovered{Base: NewBase(KindPanicRecovered), TurnID: turnID, Worker: worker, Value: value, Stack: stack}
+}
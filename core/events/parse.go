@@ -0,0 +1,148 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrUnknownKind is the error of events whose kind the package does not
+// define, e.g. kinds added by a newer version. Receivers following the wire
+// compatibility rules skip them.
+var ErrUnknownKind = errors.New("unknown event kind")
+
+// UnknownEvent is the event of an [Envelope] of a kind the package does not
+// define, e.g. one added by a newer version, with its data left encoded.
+// Receivers skip it or decode the data themselves.
+type UnknownEvent struct {
+	Base
+	Data json.RawMessage
+}
+
+// MarshalJSON encodes the data of the event as it was received.
+func (e UnknownEvent) MarshalJSON() ([]byte, error) {
+	if len(e.Data) == 0 {
+		return []byte("null"), nil
+	}
+	return e.Data, nil
+}
+
+// eventTypes maps kinds to the concrete types events of the kind decode to.
+var eventTypes = map[Kind]reflect.Type{
+	KindAssistantPlaybackStarted:            reflect.TypeFor[AssistantPlaybackStarted](),
+	KindAssistantPlaybackFrame:              reflect.TypeFor[AssistantPlaybackFrame](),
+	KindAssistantPlaybackMarkPlayed:         reflect.TypeFor[AssistantPlaybackMarkPlayed](),
+	KindAssistantPlaybackTranscriptUpdated:  reflect.TypeFor[AssistantPlaybackTranscriptUpdated](),
+	KindAssistantPlaybackTranscriptSegment:  reflect.TypeFor[AssistantPlaybackTranscriptSegment](),
+	KindAssistantPlaybackEnded:              reflect.TypeFor[AssistantPlaybackEnded](),
+	KindAssistantResponseStarted:            reflect.TypeFor[AssistantResponseStarted](),
+	KindAssistantResponseSegment:            reflect.TypeFor[AssistantResponseSegment](),
+	KindAssistantResponseSegmentReplaced:    reflect.TypeFor[AssistantResponseSegmentReplaced](),
+	KindAssistantResponseFinal:              reflect.TypeFor[AssistantResponseFinal](),
+	KindAssistantResponseFinalized:          reflect.TypeFor[AssistantResponseFinalized](),
+	KindAssistantSpeechFrame:                reflect.TypeFor[AssistantSpeechFrame](),
+	KindAssistantSpeechMarkGenerated:        reflect.TypeFor[AssistantSpeechMarkGenerated](),
+	KindAssistantSpeechTimestamps:           reflect.TypeFor[AssistantSpeechTimestamps](),
+	KindAssistantSpeechFinal:                reflect.TypeFor[AssistantSpeechFinal](),
+	KindAudioCaptureStarted:                 reflect.TypeFor[AudioCaptureStarted](),
+	KindAudioCaptureStopped:                 reflect.TypeFor[AudioCaptureStopped](),
+	KindAudioCaptureFailed:                  reflect.TypeFor[AudioCaptureFailed](),
+	KindAudioAlwaysCaptureChanged:           reflect.TypeFor[AudioAlwaysCaptureChanged](),
+	KindMicrophoneMuted:                     reflect.TypeFor[MicrophoneMuted](),
+	KindMicrophoneUnmuted:                   reflect.TypeFor[MicrophoneUnmuted](),
+	KindBackchannelPlayed:                   reflect.TypeFor[BackchannelPlayed](),
+	KindBudgetExceeded:                      reflect.TypeFor[BudgetExceeded](),
+	KindChatMessageUpdated:                  reflect.TypeFor[ChatMessageUpdated](),
+	KindChatMessageFinalized:                reflect.TypeFor[ChatMessageFinalized](),
+	KindCompliancePauseStarted:              reflect.TypeFor[CompliancePauseStarted](),
+	KindCompliancePauseEnded:                reflect.TypeFor[CompliancePauseEnded](),
+	KindConnectionLost:                      reflect.TypeFor[ConnectionLost](),
+	KindConnectionRestored:                  reflect.TypeFor[ConnectionRestored](),
+	KindConversationAnalyzed:                reflect.TypeFor[ConversationAnalyzed](),
+	KindConversationEnded:                   reflect.TypeFor[ConversationEnded](),
+	KindDegradedToTextOnly:                  reflect.TypeFor[DegradedToTextOnly](),
+	KindPlaybackMarksTimedOut:               reflect.TypeFor[PlaybackMarksTimedOut](),
+	KindDegradationRecovered:                reflect.TypeFor[DegradationRecovered](),
+	KindOutcomeLabeled:                      reflect.TypeFor[OutcomeLabeled](),
+	KindPanicRecovered:                      reflect.TypeFor[PanicRecovered](),
+	KindTenantLimitExceeded:                 reflect.TypeFor[TenantLimitExceeded](),
+	KindToolCallStarted:                     reflect.TypeFor[ToolCallStarted](),
+	KindToolCallApprovalRequested:           reflect.TypeFor[ToolCallApprovalRequested](),
+	KindToolCallCompleted:                   reflect.TypeFor[ToolCallCompleted](),
+	KindToolCallProgress:                    reflect.TypeFor[ToolCallProgress](),
+	KindToolCallOutputFlagged:               reflect.TypeFor[ToolCallOutputFlagged](),
+	KindToolCallFailed:                      reflect.TypeFor[ToolCallFailed](),
+	KindTextTranslated:                      reflect.TypeFor[TextTranslated](),
+	KindTurnStarted:                         reflect.TypeFor[TurnStarted](),
+	KindTurnCompleted:                       reflect.TypeFor[TurnCompleted](),
+	KindTurnFailed:                          reflect.TypeFor[TurnFailed](),
+	KindTurnCancelled:                       reflect.TypeFor[TurnCancelled](),
+	KindTurnStalled:                         reflect.TypeFor[TurnStalled](),
+	KindTurnStateChanged:                    reflect.TypeFor[TurnStateChanged](),
+	KindUserAudioFrame:                      reflect.TypeFor[UserAudioFrame](),
+	KindUserSpeechStarted:                   reflect.TypeFor[UserSpeechStarted](),
+	KindUserSpeechEnded:                     reflect.TypeFor[UserSpeechEnded](),
+	KindUserTranscriptInterimSegmentUpdated: reflect.TypeFor[UserTranscriptInterimSegmentUpdated](),
+	KindUserTranscriptInterimUpdated:        reflect.TypeFor[UserTranscriptInterimUpdated](),
+	KindUserTranscriptSegment:               reflect.TypeFor[UserTranscriptSegment](),
+	KindUserTranscriptFinal:                 reflect.TypeFor[UserTranscriptFinal](),
+	KindUserMessageTyped:                    reflect.TypeFor[UserMessageTyped](),
+	KindUserWakeWordDetected:                reflect.TypeFor[UserWakeWordDetected](),
+}
+
+// MarshalEvent encodes event as a JSON [Envelope] of the current
+// [WireVersion].
+func MarshalEvent(event Event) ([]byte, error) {
+	return json.Marshal(NewEnvelope(event))
+}
+
+// ParseEvent decodes a JSON [Envelope] into an event of the concrete type of
+// its kind, e.g. an [AssistantPlaybackMarkPlayed] for
+// "assistant_playback.mark_played", keeping the kind and timestamp of the
+// envelope. Kinds the package does not define fail with [ErrUnknownKind].
+func ParseEvent(data []byte) (Event, error) {
+	envelope, err := DecodeEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	return envelope.Event()
+}
+
+// Event decodes the data of the envelope into an event of the concrete type
+// of its kind, see [ParseEvent].
+func (e RawEnvelope) Event() (Event, error) {
+	eventType, ok := eventTypes[e.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, e.Kind)
+	}
+
+	event := reflect.New(eventType)
+	if len(e.Data) > 0 && string(e.Data) != "null" {
+		if err := json.Unmarshal(e.Data, event.Interface()); err != nil {
+			return nil, fmt.Errorf("failed to decode %s event: %w", e.Kind, err)
+		}
+	}
+	event.Elem().FieldByName("Base").Set(reflect.ValueOf(Base{kind: e.Kind, timestamp: e.Timestamp}))
+	return event.Elem().Interface().(Event), nil
+}
+
+// UnmarshalJSON decodes a JSON envelope with its data decoded into an event
+// of the concrete type of its kind, see [ParseEvent]. Envelopes of kinds the
+// package does not define decode with an [UnknownEvent] as their data, so
+// receivers decoding a stream of envelopes can skip them.
+func (e *Envelope) UnmarshalJSON(data []byte) error {
+	raw, err := DecodeEnvelope(data)
+	if err != nil {
+		return err
+	}
+	event, err := raw.Event()
+	if errors.Is(err, ErrUnknownKind) {
+		event = UnknownEvent{Base: Base{kind: raw.Kind, timestamp: raw.Timestamp}, Data: raw.Data}
+	} else if err != nil {
+		return err
+	}
+
+	*e = Envelope{Version: raw.Version, Kind: raw.Kind, Timestamp: raw.Timestamp, Data: event}
+	return nil
+}
@@ -0,0 +1,45 @@
+package events
+
+// Sink consumes emitted events, e.g. by exporting them to an external system.
+//
+// Handle is called inline on the emitting path and should not block.
+type Sink interface {
+	Handle(event Event)
+}
+
+// SinkFunc adapts a function to a [Sink].
+type SinkFunc func(event Event)
+
+func (f SinkFunc) Handle(event Event) { f(event) }
+
+// Retain returns event with any borrowed audio copied, so that it can be
+// kept after the event callback returns. Sinks that queue events call it
+// before queueing.
+func Retain(event Event) Event {
+	switch typedEvent := event.(type) {
+	case UserAudioFrame:
+		return typedEvent.Retain()
+	case AssistantPlaybackFrame:
+		return typedEvent.Retain()
+	}
+	return event
+}
+
+// TopicFunc maps an event to the topic or subject it is published under.
+type TopicFunc func(event Event) string
+
+// TopicPerNamespace publishes events under "<prefix>.<namespace>", e.g.
+// "ema.turn_state".
+func TopicPerNamespace(prefix string) TopicFunc {
+	return func(event Event) string {
+		return prefix + "." + event.Kind().Namespace()
+	}
+}
+
+// TopicPerSession publishes all events of a session under
+// "<prefix>.<sessionID>".
+func TopicPerSession(prefix, sessionID string) TopicFunc {
+	return func(Event) string {
+		return prefix + "." + sessionID
+	}
+}
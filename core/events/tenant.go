@@ -0,0 +1,31 @@
+package events
+
+const (
+	// KindTenantLimitExceeded identifies a tenant exceeding a limit of a
+	// shared deployment.
+	KindTenantLimitExceeded Kind = "tenant.limit_exceeded"
+)
+
+// TenantLimit names a limit of a tenant.
+type TenantLimit string
+
+const (
+	TenantLimitConversations  TenantLimit = "conversations"
+	TenantLimitTurnsPerMinute TenantLimit = "turns_per_minute"
+)
+
+// TenantLimitExceeded marks a conversation or a turn refused because its
+// tenant reached a limit.
+type TenantLimitExceeded struct {
+	Base
+	Tenant    string
+	SessionID string
+	Limit     TenantLimit
+	// Max is the value of the limit.
+	Max int
+}
+
+// NewTenantLimitExceeded creates a tenant limit exceeded event.
+func NewTenantLimitExceeded(tenant, sessionID string, limit TenantLimit, max int) TenantLimitExceeded {
+	return TenantLimitExceeded{Base: NewBase(KindTenantLimitExceeded), Tenant: tenant, SessionID: sessionID, Limit: limit, Max: max}
+}
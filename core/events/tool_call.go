@@ -7,6 +7,14 @@ const (
 	KindToolCallCompleted Kind = "tool_call.completed"
 	// KindToolCallFailed identifies tool call failure.
 	KindToolCallFailed Kind = "tool_call.failed"
+	// KindToolCallProgress identifies a tool call still running.
+	KindToolCallProgress Kind = "tool_call.progress"
+	// KindToolCallOutputFlagged identifies a tool output withheld from the
+	// LLM.
+	KindToolCallOutputFlagged Kind = "tool_call.output_flagged"
+	// KindToolCallApprovalRequested identifies a tool call held until it is
+	// approved.
+	KindToolCallApprovalRequested Kind = "tool_call.approval_requested"
 )
 
 // ToolCallStarted marks start of tool execution.
@@ -22,6 +30,20 @@ func NewToolCallStarted(id, name, arguments string) ToolCallStarted {
 	return ToolCallStarted{Base: NewBase(KindToolCallStarted), ID: id, Name: name, Arguments: arguments}
 }
 
+// ToolCallApprovalRequested marks a call of a sensitive tool held until the
+// approval handler decides on it.
+type ToolCallApprovalRequested struct {
+	Base
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// NewToolCallApprovalRequested creates a tool call approval requested event.
+func NewToolCallApprovalRequested(id, name, arguments string) ToolCallApprovalRequested {
+	return ToolCallApprovalRequested{Base: NewBase(KindToolCallApprovalRequested), ID: id, Name: name, Arguments: arguments}
+}
+
 // ToolCallCompleted marks successful tool execution.
 type ToolCallCompleted struct {
 	Base
@@ -35,6 +57,43 @@ func NewToolCallCompleted(id, name, response string) ToolCallCompleted {
 	return ToolCallCompleted{Base: NewBase(KindToolCallCompleted), ID: id, Name: name, Response: response}
 }
 
+// ToolCallProgress reports a tool call still running after a while.
+type ToolCallProgress struct {
+	Base
+	ID   string
+	Name string
+	// Message is the holding phrase spoken to the user meanwhile, it is empty
+	// for the status reported by a streaming tool.
+	Message string
+	// Status is the status reported by a streaming tool, if any.
+	Status string
+}
+
+// NewToolCallProgress creates a tool call progress event.
+func NewToolCallProgress(id, name, message string) ToolCallProgress {
+	return ToolCallProgress{Base: NewBase(KindToolCallProgress), ID: id, Name: name, Message: message}
+}
+
+// NewToolCallStatus creates a tool call progress event with the status a
+// streaming tool reported.
+func NewToolCallStatus(id, name, status string) ToolCallProgress {
+	return ToolCallProgress{Base: NewBase(KindToolCallProgress), ID: id, Name: name, Status: status}
+}
+
+// ToolCallOutputFlagged marks a tool output withheld from the LLM because it
+// looked like a prompt injection.
+type ToolCallOutputFlagged struct {
+	Base
+	ID     string
+	Name   string
+	Reason string
+}
+
+// NewToolCallOutputFlagged creates a tool call output flagged event.
+func NewToolCallOutputFlagged(id, name, reason string) ToolCallOutputFlagged {
+	return ToolCallOutputFlagged{Base: NewBase(KindToolCallOutputFlagged), ID: id, Name: name, Reason: reason}
+}
+
 // ToolCallFailed marks failed tool execution.
 type ToolCallFailed struct {
 	Base
@@ -0,0 +1,39 @@
+package events
+
+// KindTextTranslated identifies translated conversation text.
+const KindTextTranslated Kind = "translation.text_translated"
+
+// Translation directions.
+const (
+	// TranslationDirectionUser is user text translated for the LLM.
+	TranslationDirectionUser = "user"
+	// TranslationDirectionAssistant is assistant text translated for the
+	// user.
+	TranslationDirectionAssistant = "assistant"
+)
+
+// TextTranslated carries a text of the conversation with its translation.
+// User prompts are translated whole, assistant responses sentence by
+// sentence as they are generated.
+type TextTranslated struct {
+	Base
+	// Direction is who the text comes from, see TranslationDirection
+	// constants.
+	Direction      string
+	Original       string
+	Translation    string
+	SourceLanguage string
+	TargetLanguage string
+}
+
+// NewTextTranslated creates a text translated event.
+func NewTextTranslated(direction, original, translation, sourceLanguage, targetLanguage string) TextTranslated {
+	return TextTranslated{
+		Base:           NewBase(KindTextTranslated),
+		Direction:      direction,
+		Original:       original,
+		Translation:    translation,
+		SourceLanguage: sourceLanguage,
+		TargetLanguage: targetLanguage,
+	}
+}
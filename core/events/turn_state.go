@@ -1,5 +1,7 @@
 package events
 
+import "time"
+
 const (
 	// KindTurnStarted identifies turn start.
 	KindTurnStarted Kind = "turn_state.started"
@@ -9,13 +11,41 @@ const (
 	KindTurnFailed Kind = "turn_state.failed"
 	// KindTurnCancelled identifies turn cancellation.
 	KindTurnCancelled Kind = "turn_state.cancelled"
+	// KindTurnStateChanged identifies a turn lifecycle transition.
+	KindTurnStateChanged Kind = "turn_state.changed"
+	// KindTurnStalled identifies a turn making no progress.
+	KindTurnStalled Kind = "turn_state.stalled"
 )
 
+// TurnState is a stage of the turn lifecycle. A turn moves from queued
+// through generating, speaking and finalizing to one of the terminal states
+// completed, failed or cancelled. Stages can be skipped, e.g. a turn without
+// audio never speaks.
+type TurnState string
+
+const (
+	TurnStateQueued     TurnState = "queued"
+	TurnStateGenerating TurnState = "generating"
+	TurnStateSpeaking   TurnState = "speaking"
+	TurnStateFinalizing TurnState = "finalizing"
+	TurnStateCompleted  TurnState = "completed"
+	TurnStateFailed     TurnState = "failed"
+	TurnStateCancelled  TurnState = "cancelled"
+)
+
+// IsTerminal reports whether the turn can not leave state anymore.
+func (s TurnState) IsTerminal() bool {
+	return s == TurnStateCompleted || s == TurnStateFailed || s == TurnStateCancelled
+}
+
 // TurnStarted marks creation of a new turn.
 type TurnStarted struct {
 	Base
 	TurnID  string
 	Trigger string
+	// InputModality is how the user gave the trigger, "speech" or "text",
+	// empty for triggers not coming from the user.
+	InputModality string `json:",omitempty"`
 }
 
 // NewTurnStarted creates a turn started event.
@@ -26,7 +56,20 @@ func NewTurnStarted(turnID, trigger string) TurnStarted {
 // TurnCompleted marks successful completion of a turn.
 type TurnCompleted struct {
 	Base
-	TurnID string
+	TurnID  string
+	Latency LatencyReport
+}
+
+// LatencyReport breaks down the latency of a turn. QueueWait is how long the
+// trigger waited before the turn started, all other stages are measured from
+// the start of the turn. A zero duration means the stage did not happen.
+type LatencyReport struct {
+	QueueWait     time.Duration
+	LLMFirstToken time.Duration
+	LLMTotal      time.Duration
+	TTSFirstAudio time.Duration
+	PlaybackStart time.Duration
+	PlaybackEnd   time.Duration
 }
 
 // NewTurnCompleted creates a turn completed event.
@@ -53,3 +96,39 @@ type TurnCancelled struct{ Base }
 func NewTurnCancelled() TurnCancelled {
 	return TurnCancelled{Base: NewBase(KindTurnCancelled)}
 }
+
+// TurnStateChanged marks a transition of the turn lifecycle.
+type TurnStateChanged struct {
+	Base
+	TurnID string
+	From   TurnState
+	To     TurnState
+}
+
+// TurnStalled marks a turn that made no progress for a while, it describes
+// what each worker of the response pipeline was last doing.
+type TurnStalled struct {
+	Base
+	TurnID  string
+	Workers []WorkerState
+	// Cancelled is set when the turn is cancelled because it stalled.
+	Cancelled bool
+}
+
+// WorkerState is the state of a response pipeline worker.
+type WorkerState struct {
+	Name  string
+	State string
+	// Idle is how long ago the worker last made progress.
+	Idle time.Duration
+}
+
+// NewTurnStalled creates a turn stalled event.
+func NewTurnStalled(turnID string, workers []WorkerState) TurnStalled {
+	return TurnStalled{Base: NewBase(KindTurnStalled), TurnID: turnID, Workers: workers}
+}
+
+// NewTurnStateChanged creates a turn state changed event.
+func NewTurnStateChanged(turnID string, from, to TurnState) TurnStateChanged {
+	return TurnStateChanged{Base: NewBase(KindTurnStateChanged), TurnID: turnID, From: from, To: to}
+}
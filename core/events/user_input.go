@@ -1,5 +1,7 @@
 package events
 
+import "bytes"
+
 const (
 	// KindUserAudioFrame identifies raw audio captured from user input.
 	KindUserAudioFrame Kind = "user_input.audio_frame"
@@ -15,12 +17,27 @@ const (
 	KindUserTranscriptSegment Kind = "user_input.transcript_segment"
 	// KindUserTranscriptFinal identifies the final transcript for the utterance.
 	KindUserTranscriptFinal Kind = "user_input.transcript_final"
+	// KindUserMessageTyped identifies a message the user typed.
+	KindUserMessageTyped Kind = "user_input.message_typed"
+	// KindUserWakeWordDetected identifies the wake word heard in user audio.
+	KindUserWakeWordDetected Kind = "user_input.wake_word_detected"
 )
 
 // UserAudioFrame carries a user input audio frame.
+//
+// A borrowed frame's Audio is only valid until the event callback returns,
+// use [UserAudioFrame.Retain] to keep it longer.
 type UserAudioFrame struct {
 	Base
 	Audio []byte
+	// SpeakerID identifies the user in conversations with several users,
+	// see [WithSpeaker].
+	SpeakerID string `json:",omitempty"`
+	// DuringPlayback marks audio captured while the assistant was playing
+	// audio, it might contain the assistant hearing itself.
+	DuringPlayback bool `json:",omitempty"`
+
+	borrowed bool
 }
 
 // NewUserAudioFrame creates a user input audio frame event.
@@ -28,8 +45,34 @@ func NewUserAudioFrame(audio []byte) UserAudioFrame {
 	return UserAudioFrame{Base: NewBase(KindUserAudioFrame), Audio: audio}
 }
 
+// NewBorrowedUserAudioFrame creates a user input audio frame event whose
+// audio is reused once the event callback returns.
+func NewBorrowedUserAudioFrame(audio []byte) UserAudioFrame {
+	return UserAudioFrame{Base: NewBase(KindUserAudioFrame), Audio: audio, borrowed: true}
+}
+
+// Borrowed reports whether Audio is only valid until the event callback
+// returns.
+func (e UserAudioFrame) Borrowed() bool { return e.borrowed }
+
+// Retain returns the frame with audio that can be kept after the event
+// callback returns, copying it if the frame is borrowed.
+func (e UserAudioFrame) Retain() UserAudioFrame {
+	if e.borrowed {
+		e.Audio = bytes.Clone(e.Audio)
+		e.borrowed = false
+	}
+	return e
+}
+
 // UserSpeechStarted marks when user speech activity starts.
-type UserSpeechStarted struct{ Base }
+type UserSpeechStarted struct {
+	Base
+	SpeakerID string `json:",omitempty"`
+	// DuringPlayback marks speech that started while the assistant was
+	// playing audio, it might be the assistant hearing itself.
+	DuringPlayback bool `json:",omitempty"`
+}
 
 // NewUserSpeechStarted creates a user speech started event.
 func NewUserSpeechStarted() UserSpeechStarted {
@@ -37,7 +80,10 @@ func NewUserSpeechStarted() UserSpeechStarted {
 }
 
 // UserSpeechEnded marks when user speech activity ends.
-type UserSpeechEnded struct{ Base }
+type UserSpeechEnded struct {
+	Base
+	SpeakerID string `json:",omitempty"`
+}
 
 // NewUserSpeechEnded creates a user speech ended event.
 func NewUserSpeechEnded() UserSpeechEnded {
@@ -47,7 +93,8 @@ func NewUserSpeechEnded() UserSpeechEnded {
 // UserTranscriptInterimSegmentUpdated carries a mutable interim transcript tail segment.
 type UserTranscriptInterimSegmentUpdated struct {
 	Base
-	Segment string
+	Segment   string
+	SpeakerID string `json:",omitempty"`
 }
 
 // NewUserTranscriptInterimSegmentUpdated creates a mutable interim segment update event.
@@ -59,6 +106,7 @@ func NewUserTranscriptInterimSegmentUpdated(segment string) UserTranscriptInteri
 type UserTranscriptInterimUpdated struct {
 	Base
 	Transcript string
+	SpeakerID  string `json:",omitempty"`
 }
 
 // NewUserTranscriptInterimUpdated creates an interim transcript snapshot update event.
@@ -69,7 +117,8 @@ func NewUserTranscriptInterimUpdated(transcript string) UserTranscriptInterimUpd
 // UserTranscriptSegment carries a finalized transcript segment.
 type UserTranscriptSegment struct {
 	Base
-	Segment string
+	Segment   string
+	SpeakerID string `json:",omitempty"`
 }
 
 // NewUserTranscriptSegment creates a finalized transcript segment event.
@@ -81,9 +130,68 @@ func NewUserTranscriptSegment(segment string) UserTranscriptSegment {
 type UserTranscriptFinal struct {
 	Base
 	Transcript string
+	SpeakerID  string `json:",omitempty"`
 }
 
 // NewUserTranscriptFinal creates a final transcript event.
 func NewUserTranscriptFinal(transcript string) UserTranscriptFinal {
 	return UserTranscriptFinal{Base: NewBase(KindUserTranscriptFinal), Transcript: transcript}
 }
+
+// UserMessageTyped carries a message the user typed instead of speaking.
+type UserMessageTyped struct {
+	Base
+	Message   string
+	SpeakerID string `json:",omitempty"`
+}
+
+// NewUserMessageTyped creates a typed message event.
+func NewUserMessageTyped(message string) UserMessageTyped {
+	return UserMessageTyped{Base: NewBase(KindUserMessageTyped), Message: message}
+}
+
+// UserWakeWordDetected marks the wake word heard in the user audio, the
+// audio that follows is transcribed.
+type UserWakeWordDetected struct {
+	Base
+	// Keyword is the wake word heard, for detectors spotting several.
+	Keyword string
+}
+
+// NewUserWakeWordDetected creates a wake word detected event.
+func NewUserWakeWordDetected(keyword string) UserWakeWordDetected {
+	return UserWakeWordDetected{Base: NewBase(KindUserWakeWordDetected), Keyword: keyword}
+}
+
+// WithSpeaker returns the user_input event attributed to the user identified
+// by speakerID. Other events are returned unchanged.
+func WithSpeaker(event Event, speakerID string) Event {
+	switch e := event.(type) {
+	case UserAudioFrame:
+		e.SpeakerID = speakerID
+		return e
+	case UserSpeechStarted:
+		e.SpeakerID = speakerID
+		return e
+	case UserSpeechEnded:
+		e.SpeakerID = speakerID
+		return e
+	case UserTranscriptInterimSegmentUpdated:
+		e.SpeakerID = speakerID
+		return e
+	case UserTranscriptInterimUpdated:
+		e.SpeakerID = speakerID
+		return e
+	case UserTranscriptSegment:
+		e.SpeakerID = speakerID
+		return e
+	case UserTranscriptFinal:
+		e.SpeakerID = speakerID
+		return e
+	case UserMessageTyped:
+		e.SpeakerID = speakerID
+		return e
+	default:
+		return event
+	}
+}
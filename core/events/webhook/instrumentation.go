@@ -0,0 +1,14 @@
+package webhook
+
+import (
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel"
+)
+
+const scopeName = "github.com/koscakluka/ema-core/core/events/webhook"
+
+var (
+	tracer = otel.Tracer(scopeName)
+	meter  = otel.Meter(scopeName)
+	logger = otelslog.NewLogger(scopeName)
+)
@@ -0,0 +1,288 @@
+// Package webhook delivers orchestration events to an HTTP endpoint.
+//
+// By default only turn_state.*, tool_call.* and conversation.* events are
+// delivered, which is enough for external systems to learn call outcomes,
+// including outcome labels. Each event is POSTed as
+// a JSON [events.Envelope]. When a secret is configured, requests are signed
+// with HMAC-SHA256 over "<timestamp>.<body>":
+//
+//	X-Ema-Timestamp: <unix seconds>
+//	X-Ema-Signature: sha256=<hex digest>
+//
+// Receivers should recompute the digest and reject stale timestamps.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	TimestampHeader = "X-Ema-Timestamp"
+	SignatureHeader = "X-Ema-Signature"
+
+	defaultQueueSize    = 256
+	defaultMaxAttempts  = 5
+	defaultRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff     = 30 * time.Second
+)
+
+var defaultNamespaces = []string{"turn_state", "tool_call", "conversation"}
+
+// Sink POSTs events to a webhook URL, it implements [events.Sink].
+//
+// Events are queued and delivered in order by a single background worker;
+// Handle never blocks on the network. Use Handle as the event callback, e.g.
+// with orchestration.WithEventSink.
+type Sink struct {
+	url          string
+	secret       []byte
+	httpClient   *http.Client
+	namespaces   []string
+	maxAttempts  int
+	retryBackoff time.Duration
+
+	queue     chan events.Event
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+	closeMu   sync.RWMutex
+	closed    bool
+}
+
+type SinkOptions struct {
+	secret       string
+	httpClient   *http.Client
+	namespaces   []string
+	maxAttempts  int
+	retryBackoff time.Duration
+	queueSize    int
+}
+
+type SinkOption func(*SinkOptions)
+
+// WithSecret enables HMAC-SHA256 request signing with secret.
+func WithSecret(secret string) SinkOption {
+	return func(o *SinkOptions) {
+		o.secret = secret
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for delivery.
+func WithHTTPClient(client *http.Client) SinkOption {
+	return func(o *SinkOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithNamespaces selects the event namespaces that are delivered, defaults to
+// turn_state, tool_call and conversation.
+func WithNamespaces(namespaces ...string) SinkOption {
+	return func(o *SinkOptions) {
+		o.namespaces = namespaces
+	}
+}
+
+// WithRetry sets how many times delivery of a single event is attempted and
+// the initial backoff between attempts, which doubles after each failure.
+func WithRetry(maxAttempts int, backoff time.Duration) SinkOption {
+	return func(o *SinkOptions) {
+		o.maxAttempts = maxAttempts
+		o.retryBackoff = backoff
+	}
+}
+
+// WithQueueSize sets how many events can wait for delivery before new events
+// are dropped.
+func WithQueueSize(size int) SinkOption {
+	return func(o *SinkOptions) {
+		o.queueSize = size
+	}
+}
+
+// NewSink creates a sink delivering events to url and starts its worker.
+func NewSink(url string, opts ...SinkOption) (*Sink, error) {
+	options := SinkOptions{
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		namespaces:   defaultNamespaces,
+		maxAttempts:  defaultMaxAttempts,
+		retryBackoff: defaultRetryBackoff,
+		queueSize:    defaultQueueSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if url == "" {
+		return nil, fmt.Errorf("webhook url is required")
+	}
+	if options.maxAttempts < 1 {
+		options.maxAttempts = 1
+	}
+	if options.queueSize < 1 {
+		options.queueSize = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Sink{
+		url:          url,
+		secret:       []byte(options.secret),
+		httpClient:   options.httpClient,
+		namespaces:   options.namespaces,
+		maxAttempts:  options.maxAttempts,
+		retryBackoff: options.retryBackoff,
+		queue:        make(chan events.Event, options.queueSize),
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+	go s.deliverLoop()
+
+	return s, nil
+}
+
+// Handle queues event for delivery if its namespace is selected.
+func (s *Sink) Handle(event events.Event) {
+	if !slices.Contains(s.namespaces, event.Kind().Namespace()) {
+		return
+	}
+
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.queue <- events.Retain(event):
+	default:
+		logger.Warn("webhook queue full, dropping event", "kind", event.Kind())
+	}
+}
+
+// Close stops accepting events and waits until queued events are delivered
+// or ctx is done, in which case the delivery in progress and the events still
+// queued are abandoned.
+func (s *Sink) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		s.closeMu.Lock()
+		s.closed = true
+		close(s.queue)
+		s.closeMu.Unlock()
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
+func (s *Sink) deliverLoop() {
+	defer close(s.done)
+	defer s.cancel()
+	for event := range s.queue {
+		if s.ctx.Err() != nil {
+			logger.Warn("webhook sink closed, dropping queued events", "count", len(s.queue)+1)
+			return
+		}
+		if err := s.deliver(s.ctx, event); err != nil {
+			logger.Error("failed to deliver webhook event", "kind", event.Kind(), "error", err)
+		}
+	}
+}
+
+func (s *Sink) deliver(ctx context.Context, event events.Event) error {
+	ctx, span := tracer.Start(ctx, "deliver webhook event")
+	defer span.End()
+	span.SetAttributes(attribute.String("event.kind", string(event.Kind())))
+
+	body, err := json.Marshal(events.NewEnvelope(event))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to marshal event")
+		return fmt.Errorf("error marshalling event: %w", err)
+	}
+
+	backoff := s.retryBackoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := s.post(ctx, body)
+		if err == nil {
+			span.SetAttributes(attribute.Int("webhook.attempts", attempt))
+			return nil
+		}
+		if !retryable || attempt >= s.maxAttempts {
+			span.SetAttributes(attribute.Int("webhook.attempts", attempt))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to deliver event")
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			span.SetAttributes(attribute.Int("webhook.attempts", attempt))
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "delivery abandoned")
+			return err
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+func (s *Sink) post(ctx context.Context, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, "sha256="+Sign(s.secret, timestamp, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of a webhook request, as
+// sent in the signature header without the "sha256=" prefix.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,182 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestSinkDeliversSelectedNamespacesWithSignature(t *testing.T) {
+	var mu sync.Mutex
+	received := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := "sha256=" + Sign([]byte("secret"), r.Header.Get(TimestampHeader), body)
+		if got := r.Header.Get(SignatureHeader); got != expected {
+			t.Errorf("unexpected signature %q, expected %q", got, expected)
+		}
+
+		var envelope struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		mu.Lock()
+		received = append(received, envelope.Kind)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL, WithSecret("secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sink.Handle(events.NewTurnStarted("turn-1", "hello"))
+	sink.Handle(events.NewAssistantResponseSegment("hi"))
+	sink.Handle(events.NewToolCallCompleted("call-1", "lookup", "ok"))
+	sink.Handle(events.NewTurnCompleted("turn-1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		string(events.KindTurnStarted),
+		string(events.KindToolCallCompleted),
+		string(events.KindTurnCompleted),
+	}
+	if len(received) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, received)
+	}
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, received)
+		}
+	}
+}
+
+func TestSinkDeliversConversationOutcomesByDefault(t *testing.T) {
+	var mu sync.Mutex
+	received := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var envelope struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&envelope); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		mu.Lock()
+		received = append(received, envelope.Kind)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sink.Handle(events.NewOutcomeLabeled("", "resolved", "refund issued", "agent"))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(received) != 1 || received[0] != string(events.KindOutcomeLabeled) {
+		t.Fatalf("expected the outcome label to be delivered, got %v", received)
+	}
+}
+
+func TestSinkRetriesServerErrors(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL, WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sink.Handle(events.NewTurnCompleted("turn-1"))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestSinkDoesNotRetryClientErrors(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL, WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sink.Handle(events.NewTurnCompleted("turn-1"))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_ = sink.Close(ctx)
+
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestSinkCloseAbandonsRetriesWhenContextIsDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL, WithRetry(5, time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sink.Handle(events.NewTurnCompleted("turn-1"))
+	sink.Handle(events.NewTurnCompleted("turn-2"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := sink.Close(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	select {
+	case <-sink.done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the worker to stop once close gave up")
+	}
+}
@@ -0,0 +1,101 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HealthChecker is implemented by providers that can verify they are
+// reachable and their credentials are accepted without doing real work, e.g.
+// by listing models or opening and closing a stream.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Component names a provider slot of the orchestrator.
+type Component string
+
+const (
+	ComponentLLM          Component = "llm"
+	ComponentSpeechToText Component = "speech_to_text"
+	ComponentTextToSpeech Component = "text_to_speech"
+	ComponentAudioInput   Component = "audio_input"
+	ComponentAudioOutput  Component = "audio_output"
+)
+
+// ComponentHealth is the health of a single component.
+type ComponentHealth struct {
+	Component Component
+	// Configured reports whether a provider is set for the component.
+	Configured bool
+	// Checked reports whether the provider implements [HealthChecker], the
+	// health of other providers is unknown and assumed fine.
+	Checked bool
+	Err     error
+	Latency time.Duration
+}
+
+// HealthReport is the outcome of [Orchestrator.HealthCheck].
+type HealthReport struct {
+	Components []ComponentHealth
+}
+
+// Healthy reports whether none of the checked components failed.
+func (r HealthReport) Healthy() bool {
+	return r.Err() == nil
+}
+
+// Err joins the errors of the failed components.
+func (r HealthReport) Err() error {
+	var errs []error
+	for _, component := range r.Components {
+		if component.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", component.Component, component.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HealthCheck checks the configured providers concurrently and reports the
+// health of each, e.g. to back a readiness probe so calls are not routed to
+// an instance with rejected credentials or an unreachable provider. Bound
+// the check with a ctx deadline, providers are not timed out otherwise.
+func (o *Orchestrator) HealthCheck(ctx context.Context) HealthReport {
+	providers := []struct {
+		component Component
+		provider  any
+	}{
+		{ComponentLLM, o.llm.client},
+		{ComponentSpeechToText, o.speechToText.client},
+		{ComponentTextToSpeech, o.textToSpeech.base},
+		{ComponentAudioInput, o.audioInput.base},
+		{ComponentAudioOutput, o.audioOutput.base},
+	}
+
+	report := HealthReport{Components: make([]ComponentHealth, len(providers))}
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		health := &report.Components[i]
+		health.Component = p.component
+		health.Configured = p.provider != nil
+		checker, ok := p.provider.(HealthChecker)
+		if !ok {
+			continue
+		}
+
+		health.Checked = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			startedAt := time.Now()
+			health.Err = checker.HealthCheck(ctx)
+			health.Latency = time.Since(startedAt)
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
@@ -0,0 +1,50 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type healthCheckedLLMStub struct {
+	promptLLMStub
+	err error
+}
+
+func (stub healthCheckedLLMStub) HealthCheck(context.Context) error { return stub.err }
+
+func TestHealthCheckReportsComponents(t *testing.T) {
+	o := NewOrchestrator(
+		WithLLM(healthCheckedLLMStub{err: errors.New("unauthorized")}),
+		WithSpeechToTextClient(&recordingSpeechToTextClient{}),
+	)
+	defer o.Close()
+
+	report := o.HealthCheck(context.Background())
+	if report.Healthy() {
+		t.Fatalf("expected report to be unhealthy")
+	}
+
+	components := map[Component]ComponentHealth{}
+	for _, component := range report.Components {
+		components[component.Component] = component
+	}
+	if llm := components[ComponentLLM]; !llm.Configured || !llm.Checked || llm.Err == nil {
+		t.Fatalf("expected llm to be checked and failing, got %+v", llm)
+	}
+	if stt := components[ComponentSpeechToText]; !stt.Configured || stt.Checked || stt.Err != nil {
+		t.Fatalf("expected speech to text to be configured but unchecked, got %+v", stt)
+	}
+	if tts := components[ComponentTextToSpeech]; tts.Configured || tts.Checked {
+		t.Fatalf("expected text to speech to be unconfigured, got %+v", tts)
+	}
+}
+
+func TestHealthCheckHealthyWithoutFailures(t *testing.T) {
+	o := NewOrchestrator(WithLLM(healthCheckedLLMStub{}))
+	defer o.Close()
+
+	if report := o.HealthCheck(context.Background()); !report.Healthy() {
+		t.Fatalf("expected report to be healthy, got %v", report.Err())
+	}
+}
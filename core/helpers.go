@@ -3,6 +3,10 @@ package orchestration
 import (
 	"context"
 	"fmt"
+	"runtime/debug"
+
+	"github.com/koscakluka/ema-core/core/errorreport"
+	events "github.com/koscakluka/ema-core/core/events"
 )
 
 func (o *Orchestrator) currentResponsePipeline() *responsePipeline {
@@ -43,10 +47,15 @@ func withContextCancelHook(ctx context.Context, onContextDone func()) chan struc
 
 type workerRun func(context.Context) error
 
-func panicSafeNamedWorker(name string, run func(context.Context) error) workerRun {
+// panicSafeNamedWorker fails the worker on panics instead of crashing the
+// process, the panic is emitted as [events.PanicRecovered] and reported.
+func (p *responsePipeline) panicSafeNamedWorker(name string, run func(context.Context) error) workerRun {
 	return func(ctx context.Context) (err error) {
 		defer func() {
 			if recovered := recover(); recovered != nil {
+				stack := debug.Stack()
+				p.emitEvent(events.NewPanicRecovered(p.state.TurnID(), name, fmt.Sprint(recovered), string(stack)))
+				errorreport.ReportPanic(ctx, name+" worker", recovered, stack)
 				err = fmt.Errorf("%s worker panicked: %v", name, recovered)
 			}
 		}()
@@ -0,0 +1,169 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// HistoryEvictor handles the oldest turns evicted once the conversation
+// history exceeds its limits, see [WithHistoryLimit].
+type HistoryEvictor interface {
+	// Evict receives the evicted turns, oldest first, and returns the turns
+	// that take their place at the start of the history, e.g. a summary. If
+	// it fails the history is kept as is and eviction is retried after the
+	// next turn.
+	Evict(ctx context.Context, evicted []llms.TurnV1) ([]llms.TurnV1, error)
+}
+
+// HistoryEvictorFunc adapts a function to a [HistoryEvictor].
+type HistoryEvictorFunc func(ctx context.Context, evicted []llms.TurnV1) ([]llms.TurnV1, error)
+
+func (f HistoryEvictorFunc) Evict(ctx context.Context, evicted []llms.TurnV1) ([]llms.TurnV1, error) {
+	return f(ctx, evicted)
+}
+
+// DropOldestTurns discards evicted turns.
+func DropOldestTurns() HistoryEvictor {
+	return HistoryEvictorFunc(func(context.Context, []llms.TurnV1) ([]llms.TurnV1, error) {
+		return nil, nil
+	})
+}
+
+// CompactTurns replaces evicted turns with the single turn summarize
+// returns. A summary turn is evicted like any other once it is the oldest,
+// so summarize should fold earlier summaries into the new one.
+func CompactTurns(summarize func(ctx context.Context, turns []llms.TurnV1) (llms.TurnV1, error)) HistoryEvictor {
+	return HistoryEvictorFunc(func(ctx context.Context, evicted []llms.TurnV1) ([]llms.TurnV1, error) {
+		summary, err := summarize(ctx, evicted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize evicted turns: %w", err)
+		}
+		return []llms.TurnV1{summary}, nil
+	})
+}
+
+// HistoryStore keeps turns evicted from the in-memory history, see
+// [SpillTurns].
+type HistoryStore interface {
+	AppendTurns(ctx context.Context, turns []llms.TurnV1) error
+}
+
+// SpillTurns moves evicted turns to store.
+func SpillTurns(store HistoryStore) HistoryEvictor {
+	return HistoryEvictorFunc(func(ctx context.Context, evicted []llms.TurnV1) ([]llms.TurnV1, error) {
+		if err := store.AppendTurns(ctx, evicted); err != nil {
+			return nil, fmt.Errorf("failed to spill evicted turns: %w", err)
+		}
+		return nil, nil
+	})
+}
+
+type HistoryLimitOptions struct {
+	maxTurns int
+	maxBytes int
+	evictor  HistoryEvictor
+}
+
+type HistoryLimitOption func(*HistoryLimitOptions)
+
+// WithHistoryMaxTurns limits the history to n turns.
+func WithHistoryMaxTurns(n int) HistoryLimitOption {
+	return func(o *HistoryLimitOptions) {
+		o.maxTurns = n
+	}
+}
+
+// WithHistoryMaxBytes limits the history to n bytes, measured as the size of
+// the turns encoded as in [SessionStateV0]. The latest turn is kept even if
+// it exceeds the limit on its own.
+func WithHistoryMaxBytes(n int) HistoryLimitOption {
+	return func(o *HistoryLimitOptions) {
+		o.maxBytes = n
+	}
+}
+
+// WithHistoryEvictor sets what happens to evicted turns, defaults to
+// [DropOldestTurns].
+func WithHistoryEvictor(evictor HistoryEvictor) HistoryLimitOption {
+	return func(o *HistoryLimitOptions) {
+		o.evictor = evictor
+	}
+}
+
+// WithHistoryLimit caps the history kept in memory, by default it grows
+// without bound. The oldest turns exceeding the limits are evicted after each
+// turn, before the next one starts.
+func WithHistoryLimit(opts ...HistoryLimitOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		options := HistoryLimitOptions{evictor: DropOldestTurns()}
+		for _, opt := range opts {
+			opt(&options)
+		}
+		if options.evictor == nil {
+			options.evictor = DropOldestTurns()
+		}
+		o.conversation.historyLimit = &options
+	}
+}
+
+// evictHistory evicts the oldest turns exceeding the history limits.
+func (t *activeConversation) evictHistory(ctx context.Context) error {
+	t.mu.RLock()
+	limit := t.historyLimit
+	var evicted []llms.TurnV1
+	if limit != nil {
+		evicted = slices.Clone(t.turns[:limit.exceeding(t.turns)])
+	}
+	t.mu.RUnlock()
+	if len(evicted) == 0 {
+		return nil
+	}
+
+	replacements, err := limit.evictor.Evict(ctx, evicted)
+	if err != nil {
+		return fmt.Errorf("failed to evict history: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.turns) < len(evicted) || !slices.EqualFunc(t.turns[:len(evicted)], evicted, func(a, b llms.TurnV1) bool { return a.ID == b.ID }) {
+		return fmt.Errorf("failed to evict history: history changed during eviction")
+	}
+	t.turns = append(slices.Clone(replacements), t.turns[len(evicted):]...)
+	return nil
+}
+
+// exceeding returns how many of the oldest turns exceed the limits.
+func (o *HistoryLimitOptions) exceeding(turns []llms.TurnV1) int {
+	evict := 0
+	if o.maxTurns > 0 && len(turns) > o.maxTurns {
+		evict = len(turns) - o.maxTurns
+	}
+
+	if o.maxBytes > 0 {
+		size := 0
+		for _, turn := range turns[evict:] {
+			size += turnSize(turn)
+		}
+		for ; size > o.maxBytes && evict < len(turns)-1; evict++ {
+			size -= turnSize(turns[evict])
+		}
+	}
+
+	return evict
+}
+
+func turnSize(turn llms.TurnV1) int {
+	encoded, err := encodeTurn(turn)
+	if err != nil {
+		// Count the turn without its trigger rather than not at all.
+		turn.Trigger = nil
+		encoded, _ = encodeTurn(turn)
+	}
+	data, _ := json.Marshal(encoded)
+	return len(data)
+}
@@ -0,0 +1,125 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func historyTurns(ids ...string) []llms.TurnV1 {
+	turns := make([]llms.TurnV1, 0, len(ids))
+	for _, id := range ids {
+		turns = append(turns, llms.TurnV1{ID: id, Responses: []llms.TurnResponseV0{{Message: strings.Repeat("x", 100)}}})
+	}
+	return turns
+}
+
+func turnIDs(turns []llms.TurnV1) string {
+	ids := make([]string, 0, len(turns))
+	for _, turn := range turns {
+		ids = append(ids, turn.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestHistoryLimitExceeding(t *testing.T) {
+	turns := historyTurns("1", "2", "3", "4")
+	size := turnSize(turns[0])
+
+	testCases := []struct {
+		name     string
+		limit    HistoryLimitOptions
+		expected int
+	}{
+		{name: "unlimited", limit: HistoryLimitOptions{}, expected: 0},
+		{name: "max turns", limit: HistoryLimitOptions{maxTurns: 3}, expected: 1},
+		{name: "max bytes", limit: HistoryLimitOptions{maxBytes: 2 * size}, expected: 2},
+		{name: "stricter limit wins", limit: HistoryLimitOptions{maxTurns: 3, maxBytes: size}, expected: 3},
+		{name: "keeps latest turn", limit: HistoryLimitOptions{maxBytes: 1}, expected: 3},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := testCase.limit.exceeding(turns); got != testCase.expected {
+				t.Fatalf("expected %d turns to be evicted, got %d", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestEvictHistoryReplacesEvictedTurns(t *testing.T) {
+	conversation := newConversation(nil, nil)
+	conversation.restoreHistory(historyTurns("1", "2", "3"))
+	conversation.historyLimit = &HistoryLimitOptions{maxTurns: 2, evictor: CompactTurns(func(_ context.Context, turns []llms.TurnV1) (llms.TurnV1, error) {
+		return llms.TurnV1{ID: "summary(" + turnIDs(turns) + ")"}, nil
+	})}
+	if err := conversation.evictHistory(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := turnIDs(conversation.History()); got != "summary(1),2,3" {
+		t.Fatalf("expected evicted turns to be replaced by a summary, got %s", got)
+	}
+}
+
+func TestEvictHistoryKeepsTurnsWhenEvictorFails(t *testing.T) {
+	conversation := newConversation(nil, nil)
+	conversation.restoreHistory(historyTurns("1", "2", "3"))
+	conversation.historyLimit = &HistoryLimitOptions{maxTurns: 1, evictor: HistoryEvictorFunc(func(context.Context, []llms.TurnV1) ([]llms.TurnV1, error) {
+		return nil, errors.New("store unavailable")
+	})}
+
+	if err := conversation.evictHistory(context.Background()); err == nil {
+		t.Fatalf("expected eviction error")
+	}
+	if got := turnIDs(conversation.History()); got != "1,2,3" {
+		t.Fatalf("expected history to be kept, got %s", got)
+	}
+}
+
+func TestWithHistoryLimitCapsHistory(t *testing.T) {
+	o := NewOrchestrator(WithLLM(promptLLMStub{response: "response"}), WithHistoryLimit(WithHistoryMaxTurns(2)))
+	defer o.Close()
+
+	var completed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Add(1)
+		}
+	}))
+
+	for i := range 3 {
+		o.SendPrompt("prompt")
+		waitForCondition(t, 2*time.Second, "turn completed", func() bool { return completed.Load() == int32(i+1) })
+	}
+
+	waitForCondition(t, time.Second, "history capped", func() bool { return len(o.conversation.History()) == 2 })
+}
+
+func TestActiveTurnKeepsOnlyTheSpokenPartOfInterruptedResponses(t *testing.T) {
+	turn := newActiveTurn(triggers.NewUserPromptTrigger("weather?"))
+	turn.finalResponse.Message = "It is sunny. It will rain tomorrow."
+	turn.finalResponse.TypedMessage = "It is sunny. It will rain tomorrow."
+	turn.finalResponse.SpokenResponse = "It is sunny."
+	turn.finalResponse.IsMessageFullyGenerated = true
+
+	turn.truncateToSpoken()
+	turn.Finalise()
+
+	response := turn.Responses[0]
+	if response.Message != "It is sunny." || response.UnspokenMessage != " It will rain tomorrow." {
+		t.Fatalf("unexpected truncated response %+v", response)
+	}
+	if !response.IsInterrupted || response.IsCompleted() || !response.IsDelivered() || !turn.IsCancelled() {
+		t.Fatalf("expected the response to be interrupted but delivered, got %+v", response)
+	}
+}
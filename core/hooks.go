@@ -0,0 +1,76 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// ErrTurnVetoed is the error of turns an OnTurnStart hook refused to run.
+var ErrTurnVetoed = errors.New("turn vetoed")
+
+// Hooks intercept the conversation lifecycle, see [WithHooks].
+//
+// Unlike events, hooks run synchronously on the lifecycle path and can stop
+// it by returning an error. Unset hooks are skipped.
+type Hooks struct {
+	// OnConversationStart runs when [Orchestrator.Orchestrate] starts, an
+	// error closes the orchestrator instead of starting the conversation.
+	OnConversationStart func(ctx context.Context) error
+	// OnConversationEnd runs once the orchestrator of a started conversation
+	// is closed.
+	OnConversationEnd func(ctx context.Context)
+	// OnTurnStart runs before the response to a turn is generated. An error
+	// vetoes the turn, e.g. when a budget is exceeded: the turn is left out of
+	// the history and fails with [ErrTurnVetoed].
+	OnTurnStart func(ctx context.Context, turn llms.TurnV1) error
+	// OnTurnEnd runs after a turn finished, err is nil if it completed.
+	OnTurnEnd func(ctx context.Context, turn llms.TurnV1, err error)
+}
+
+// WithHooks registers lifecycle hooks. Hooks registered by several calls run
+// in registration order, the first error stops the rest.
+func WithHooks(hooks Hooks) OrchestratorOption {
+	return func(o *Orchestrator) { o.hooks = append(o.hooks, hooks) }
+}
+
+type hookChain []Hooks
+
+func (c hookChain) conversationStart(ctx context.Context) error {
+	for _, hooks := range c {
+		if hooks.OnConversationStart != nil {
+			if err := hooks.OnConversationStart(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c hookChain) conversationEnd(ctx context.Context) {
+	for _, hooks := range c {
+		if hooks.OnConversationEnd != nil {
+			hooks.OnConversationEnd(ctx)
+		}
+	}
+}
+
+func (c hookChain) turnStart(ctx context.Context, turn llms.TurnV1) error {
+	for _, hooks := range c {
+		if hooks.OnTurnStart != nil {
+			if err := hooks.OnTurnStart(ctx, turn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c hookChain) turnEnd(ctx context.Context, turn llms.TurnV1, err error) {
+	for _, hooks := range c {
+		if hooks.OnTurnEnd != nil {
+			hooks.OnTurnEnd(ctx, turn, err)
+		}
+	}
+}
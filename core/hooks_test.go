@@ -0,0 +1,97 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+func TestOnTurnStartErrorVetoesTurn(t *testing.T) {
+	errBudgetExceeded := errors.New("budget exceeded")
+
+	var mu sync.Mutex
+	var endErrs []error
+	o := NewOrchestrator(
+		WithLLM(promptLLMStub{response: "response"}),
+		WithHooks(Hooks{
+			OnTurnStart: func(context.Context, llms.TurnV1) error { return errBudgetExceeded },
+			OnTurnEnd: func(_ context.Context, _ llms.TurnV1, err error) {
+				mu.Lock()
+				endErrs = append(endErrs, err)
+				mu.Unlock()
+			},
+		}),
+	)
+	defer o.Close()
+
+	failed := make(chan events.TurnFailed, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if typedEvent, ok := event.(events.TurnFailed); ok {
+			failed <- typedEvent
+		}
+	}))
+
+	o.SendPrompt("hello")
+	select {
+	case <-failed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for vetoed turn to fail")
+	}
+
+	if history := o.conversation.History(); len(history) != 0 {
+		t.Fatalf("expected vetoed turn to be left out of history, got %d turns", len(history))
+	}
+	waitForCondition(t, time.Second, "turn end hook", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(endErrs) == 1
+	})
+	if err := endErrs[0]; !errors.Is(err, ErrTurnVetoed) || !errors.Is(err, errBudgetExceeded) {
+		t.Fatalf("expected turn end hook to receive the veto, got %v", err)
+	}
+}
+
+func TestConversationHooksRunOnStartAndClose(t *testing.T) {
+	var calls []string
+	o := NewOrchestrator(
+		WithHooks(Hooks{
+			OnConversationStart: func(context.Context) error { calls = append(calls, "first start"); return nil },
+			OnConversationEnd:   func(context.Context) { calls = append(calls, "end") },
+		}),
+		WithHooks(Hooks{
+			OnConversationStart: func(context.Context) error { calls = append(calls, "second start"); return nil },
+		}),
+	)
+
+	o.Orchestrate(context.Background())
+	o.Close()
+	o.Close()
+
+	if got, expected := len(calls), 3; got != expected || calls[0] != "first start" || calls[1] != "second start" || calls[2] != "end" {
+		t.Fatalf("expected hooks to run in registration order once, got %v", calls)
+	}
+}
+
+func TestConversationStartErrorClosesOrchestrator(t *testing.T) {
+	ended := false
+	o := NewOrchestrator(WithHooks(Hooks{
+		OnConversationStart: func(context.Context) error { return errors.New("not allowed") },
+		OnConversationEnd:   func(context.Context) { ended = true },
+	}))
+
+	o.Orchestrate(context.Background())
+
+	if o.triggerPlayer.Load().CanIngest() {
+		t.Fatalf("expected orchestrator to be closed after a failing start hook")
+	}
+	if ended {
+		t.Fatalf("expected conversation end hook to be skipped for a conversation that never started")
+	}
+}
@@ -0,0 +1,101 @@
+package orchestration
+
+import (
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// maxIdleCheckInterval bounds how long idle sessions are left unchecked when
+// none is about to time out.
+const maxIdleCheckInterval = time.Minute
+
+// WithIdleTimeout ends sessions that had no activity, no triggers like user
+// prompts or transcripts and no turns ending, for longer than ttl, so
+// sessions abandoned by their client do not hold on to provider connections.
+// Ended sessions are reported with a [events.ConversationEnded] event, both
+// to the manager and the session, then closed and no longer managed. Sessions
+// with a turn in progress are not idle, however long the turn takes.
+//
+// Sessions override the timeout with [WithSessionIdleTimeout].
+func WithIdleTimeout(ttl time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.idleTimeout = ttl
+	}
+}
+
+// WithSessionIdleTimeout sets the idle timeout of the session instead of the
+// one of [WithIdleTimeout], a zero ttl keeps the session until it is removed.
+func WithSessionIdleTimeout(ttl time.Duration) SessionOption {
+	return func(o *SessionOptions) {
+		o.idleTimeout = &ttl
+	}
+}
+
+// markActive records activity of the conversation, see [WithIdleTimeout].
+func (o *Orchestrator) markActive() {
+	o.lastActivity.Store(o.clock.Now().UnixNano())
+}
+
+// idleFor is how long the conversation had no activity.
+func (o *Orchestrator) idleFor() time.Duration {
+	return o.clock.Now().Sub(time.Unix(0, o.lastActivity.Load()))
+}
+
+// watchIdle starts ending idle sessions, once.
+func (m *Manager) watchIdle() {
+	m.idleWatchStarted.Do(func() { go m.endIdleSessions() })
+	select {
+	case m.idleWake <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) endIdleSessions() {
+	for {
+		next := m.endIdleSessionsOnce()
+		select {
+		case <-m.idleDone:
+			return
+		case <-m.idleWake:
+		case <-m.idleClock.After(next):
+		}
+	}
+}
+
+// endIdleSessionsOnce ends the sessions idle for longer than their timeout
+// and returns when to check again.
+func (m *Manager) endIdleSessionsOnce() time.Duration {
+	type idleSession struct {
+		id           string
+		orchestrator *Orchestrator
+		idle         time.Duration
+	}
+
+	next := maxIdleCheckInterval
+	var ended []idleSession
+	m.mu.Lock()
+	for id, ttl := range m.idleTimeouts {
+		orchestrator := m.sessions[id]
+		if orchestrator.currentResponsePipeline() != nil {
+			next = min(next, ttl)
+			continue
+		}
+		idle := orchestrator.idleFor()
+		if idle < ttl {
+			next = min(next, ttl-idle)
+			continue
+		}
+		m.forgetLocked(id)
+		ended = append(ended, idleSession{id: id, orchestrator: orchestrator, idle: idle})
+	}
+	m.mu.Unlock()
+
+	for _, session := range ended {
+		event := events.NewConversationEnded(session.id, events.ConversationEndedIdleTimeout, session.idle)
+		m.emitEvent(event)
+		session.orchestrator.emitEvent(event)
+		session.orchestrator.Close()
+	}
+	return next
+}
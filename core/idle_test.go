@@ -0,0 +1,134 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+func TestManagerEndsIdleSessions(t *testing.T) {
+	var mu sync.Mutex
+	var ended []events.ConversationEnded
+	record := func(event events.Event) {
+		if event, ok := event.(events.ConversationEnded); ok {
+			mu.Lock()
+			defer mu.Unlock()
+			ended = append(ended, event)
+		}
+	}
+	recorded := func() []events.ConversationEnded {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]events.ConversationEnded(nil), ended...)
+	}
+
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	manager := NewManager(WithIdleTimeout(time.Minute), WithManagerEventCallback(record))
+	defer manager.Close()
+	manager.idleClock = fakeClock
+
+	idle := NewOrchestrator(WithClock(fakeClock))
+	if err := manager.Add("idle", idle); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.Add("kept", NewOrchestrator(WithClock(fakeClock)), WithSessionIdleTimeout(0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle.Orchestrate(ctx, WithEventCallback(record))
+
+	waitForCondition(t, 2*time.Second, "idle session to end", func() bool {
+		fakeClock.Advance(10 * time.Second)
+		_, ok := manager.Get("idle")
+		return !ok
+	})
+	waitForCondition(t, time.Second, "both callbacks to receive the event", func() bool { return len(recorded()) == 2 })
+	for _, event := range recorded() {
+		if event.SessionID != "idle" || event.Reason != events.ConversationEndedIdleTimeout || event.Idle < time.Minute {
+			t.Fatalf("unexpected conversation ended event %+v", event)
+		}
+	}
+	if _, ok := manager.Get("kept"); !ok {
+		t.Fatalf("expected the session without an idle timeout to be kept")
+	}
+}
+
+func TestManagerKeepsSessionsWithTurnsInProgress(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	manager := NewManager(WithIdleTimeout(time.Minute))
+	defer manager.Close()
+	manager.idleClock = fakeClock
+
+	o := NewOrchestrator(
+		WithClock(fakeClock),
+		WithStreamingLLM(scriptedStreamLLMStub{chunks: []string{"Let me check."}, interval: time.Hour}),
+	)
+	if err := manager.Add("busy", o); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx)
+
+	o.SendPrompt("can you check my order?")
+	waitForCondition(t, 2*time.Second, "turn started", func() bool { return o.currentResponsePipeline() != nil })
+	fakeClock.Advance(2 * time.Minute)
+	manager.endIdleSessionsOnce()
+
+	if _, ok := manager.Get("busy"); !ok {
+		t.Fatalf("expected the session with a turn outliving the idle timeout to be kept")
+	}
+}
+
+// failingStreamLLMStub fails its stream once release is closed.
+type failingStreamLLMStub struct {
+	release chan struct{}
+}
+
+func (stub failingStreamLLMStub) PromptWithStream(context.Context, *string, ...llms.StreamingPromptOption) llms.Stream {
+	return stub
+}
+
+func (stub failingStreamLLMStub) Chunks(ctx context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		select {
+		case <-stub.release:
+		case <-ctx.Done():
+			return
+		}
+		yield(nil, errors.New("provider unavailable"))
+	}
+}
+
+func TestFailedTurnsMarkActivityWhenTheyEnd(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	release := make(chan struct{})
+	o := NewOrchestrator(WithClock(fakeClock), WithStreamingLLM(failingStreamLLMStub{release: release}))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var failed atomic.Bool
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnFailed); ok {
+			failed.Store(true)
+		}
+	}))
+
+	o.SendPrompt("can you check my order?")
+	waitForCondition(t, 2*time.Second, "turn started", func() bool { return o.currentResponsePipeline() != nil })
+	fakeClock.Advance(2 * time.Minute)
+	close(release)
+	waitForCondition(t, 2*time.Second, "turn failed", func() bool { return failed.Load() && o.currentResponsePipeline() == nil })
+
+	if idle := o.idleFor(); idle != 0 {
+		t.Fatalf("expected the failed turn to mark activity when it ended, idle for %v", idle)
+	}
+}
@@ -0,0 +1,62 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestInitiateTurnSpeaksUnprompted(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	o := NewOrchestrator(WithLLM(llm))
+	t.Cleanup(o.Close)
+	var mu sync.Mutex
+	var started []events.TurnStarted
+	var completed atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		switch typedEvent := event.(type) {
+		case events.TurnStarted:
+			mu.Lock()
+			started = append(started, typedEvent)
+			mu.Unlock()
+		case events.TurnCompleted:
+			completed.Store(true)
+		}
+	}))
+
+	if err := o.InitiateTurn(context.Background(), "Remind the user of their 3pm appointment."); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	history := o.conversation.History()
+	if len(history) != 1 {
+		t.Fatalf("expected the initiated turn in the history, got %+v", history)
+	}
+	initiated, ok := history[0].Trigger.(triggers.AssistantInitiatedTrigger)
+	if !ok || initiated.Instruction != "Remind the user of their 3pm appointment." || history[0].InputModality != "" {
+		t.Fatalf("unexpected initiated turn %+v", history[0])
+	}
+	llm.mu.Lock()
+	if len(llm.prompts) != 1 || llm.prompts[0] != initiated.String() {
+		t.Fatalf("expected the instruction to be prompted, got %v", llm.prompts)
+	}
+	llm.mu.Unlock()
+	mu.Lock()
+	if len(started) != 1 || started[0].TurnID != history[0].ID {
+		t.Fatalf("expected a turn started event, got %+v", started)
+	}
+	mu.Unlock()
+
+	o.Close()
+	if err := o.InitiateTurn(context.Background(), "Say goodbye."); err != ErrOrchestratorClosed {
+		t.Fatalf("expected initiating on a closed orchestrator to fail, got %v", err)
+	}
+}
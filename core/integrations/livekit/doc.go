@@ -0,0 +1,19 @@
+// Package livekit connects the orchestrator to LiveKit (https://livekit.io)
+// rooms, for agents talking to browser and mobile clients over WebRTC.
+//
+// WebRTC is not implemented by this module, the [Input] and [Output] work on
+// top of a [Room], an adapter around a LiveKit client, e.g.
+// github.com/livekit/server-sdk-go, which exchanges PCM with the tracks of
+// the room; the opus package decodes and encodes their audio. The input
+// subscribes to the microphone track of the caller, the output publishes the
+// speech of the agent on a track of its own, paced in real time so its marks
+// are confirmed once the audio before them was published, see
+// [WithMarkLatencyCallback].
+//
+//	input, err := livekit.NewInput(ctx, room, "caller")
+//	output, err := livekit.NewOutput(ctx, room)
+//	o := orchestration.NewOrchestrator(
+//		orchestration.WithAudioInput(input),
+//		orchestration.WithAudioOutputV1(output),
+//	)
+package livekit
@@ -0,0 +1,94 @@
+package livekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+// Input is the audio of a remote participant of a room, as the audio input
+// of the orchestrator.
+type Input struct {
+	track    RemoteTrack
+	encoding audio.EncodingInfo
+	logger   logging.Logger
+
+	onAudio   func(audio []byte)
+	capturing bool
+	captureMu sync.Mutex
+
+	closeOnce sync.Once
+}
+
+// NewInput subscribes to the microphone track of the participant of room
+// with identity, an empty identity is the first remote participant
+// publishing one.
+func NewInput(ctx context.Context, room Room, identity string, opts ...Option) (*Input, error) {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if room == nil {
+		return nil, errors.New("room is required")
+	}
+	if options.encoding.IsZero() || options.encoding.Format.ByteSize() <= 0 {
+		return nil, fmt.Errorf("invalid encoding: %+v", options.encoding)
+	}
+
+	track, err := room.SubscribeAudio(ctx, identity, options.encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to livekit audio: %w", err)
+	}
+
+	i := &Input{track: track, encoding: options.encoding, logger: options.logger}
+	track.OnAudio(i.receiveAudio)
+	return i, nil
+}
+
+func (i *Input) EncodingInfo() audio.EncodingInfo {
+	return i.encoding
+}
+
+// Stream starts forwarding the audio of the participant to onAudio.
+func (i *Input) Stream(ctx context.Context, onAudio func(audio []byte)) error {
+	return i.StartCapture(ctx, onAudio)
+}
+
+func (i *Input) StartCapture(_ context.Context, onAudio func(audio []byte)) error {
+	i.captureMu.Lock()
+	defer i.captureMu.Unlock()
+	i.onAudio = onAudio
+	i.capturing = true
+	return nil
+}
+
+func (i *Input) StopCapture() error {
+	i.captureMu.Lock()
+	defer i.captureMu.Unlock()
+	i.capturing = false
+	return nil
+}
+
+// Close unsubscribes from the track.
+func (i *Input) Close() {
+	i.closeOnce.Do(func() {
+		i.track.OnAudio(nil)
+		if err := i.track.Unsubscribe(); err != nil {
+			i.logger.Error("failed to unsubscribe from livekit audio", "error", err)
+		}
+	})
+}
+
+func (i *Input) receiveAudio(audio []byte) {
+	i.captureMu.Lock()
+	onAudio, capturing := i.onAudio, i.capturing
+	i.captureMu.Unlock()
+
+	if capturing && onAudio != nil {
+		onAudio(audio)
+	}
+}
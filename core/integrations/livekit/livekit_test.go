@@ -0,0 +1,210 @@
+package livekit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+func TestInputForwardsParticipantAudioOnlyWhileCapturing(t *testing.T) {
+	room := &fakeRoom{remote: &fakeRemoteTrack{}}
+	input, err := NewInput(context.Background(), room, "caller", WithLogger(logging.Discard()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if room.subscribed != "caller" {
+		t.Fatalf("expected the track of the caller to be subscribed, got %q", room.subscribed)
+	}
+
+	received := 0
+	if err := input.Stream(context.Background(), func(audio []byte) { received += len(audio) }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	room.remote.receive([]byte{1, 2})
+	_ = input.StopCapture()
+	room.remote.receive([]byte{3, 4})
+	if received != 2 {
+		t.Fatalf("expected 2 bytes forwarded, got %d", received)
+	}
+
+	input.Close()
+	if !room.remote.unsubscribed {
+		t.Fatalf("expected closing to unsubscribe from the track")
+	}
+}
+
+func TestOutputConfirmsMarksWithLatencyOncePublished(t *testing.T) {
+	room := &fakeRoom{local: &fakeLocalTrack{latency: 80 * time.Millisecond}}
+	latencies := make(chan MarkLatency, 1)
+	output, err := NewOutput(context.Background(), room,
+		WithFrameDuration(time.Millisecond),
+		WithMarkLatencyCallback(func(latency MarkLatency) { latencies <- latency }),
+		WithLogger(logging.Discard()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer output.Close()
+	if room.published != defaultTrackName {
+		t.Fatalf("expected the track to be published as %q, got %q", defaultTrackName, room.published)
+	}
+
+	// 10ms of default encoded audio
+	frame := make([]byte, audio.DefaultSampleRate*2/100)
+	if err := output.SendAudio(frame); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	marked := make(chan int, 1)
+	if err := output.Mark("first", func(string) { marked <- room.local.written() }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case written := <-marked:
+		if written != len(frame) {
+			t.Fatalf("expected %d bytes published before mark, got %d", len(frame), written)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for mark")
+	}
+	latency := <-latencies
+	if latency.Mark != "first" || latency.Queued != 10*time.Millisecond || latency.Transport != 80*time.Millisecond {
+		t.Fatalf("expected the latency of the mark, got %+v", latency)
+	}
+	if latency.Confirmed <= 0 {
+		t.Fatalf("expected the confirmation to take time, got %v", latency.Confirmed)
+	}
+}
+
+func TestOutputClearBufferDropsQueuedAudio(t *testing.T) {
+	room := &fakeRoom{local: &fakeLocalTrack{}}
+	output, err := NewOutput(context.Background(), room, WithFrameDuration(time.Hour), WithLogger(logging.Discard()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_ = output.SendAudio(make([]byte, 1024))
+	_ = output.Mark("dropped", func(string) { t.Errorf("mark should have been dropped") })
+	output.ClearBuffer()
+
+	frame, marks := output.nextFrame(640)
+	if len(frame) != 0 || len(marks) != 0 {
+		t.Fatalf("expected empty buffer after clear, got %d bytes and %d marks", len(frame), len(marks))
+	}
+
+	output.Close()
+	if !room.local.unpublished {
+		t.Fatalf("expected closing to unpublish the track")
+	}
+	if err := output.SendAudio(frame); !errors.Is(err, ErrOutputClosed) {
+		t.Fatalf("expected sending to a closed output to fail, got %v", err)
+	}
+}
+
+func TestOutputAwaitMarkReturnsWhenTheBufferIsCleared(t *testing.T) {
+	output, err := NewOutput(context.Background(), &fakeRoom{local: &fakeLocalTrack{}}, WithFrameDuration(time.Hour), WithLogger(logging.Discard()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer output.Close()
+
+	_ = output.SendAudio(make([]byte, 1024))
+	awaited := make(chan error, 1)
+	go func() { awaited <- output.AwaitMark() }()
+	// The mark is set once the output holds it.
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(time.Millisecond) {
+		output.bufferMu.Lock()
+		marks := len(output.marks)
+		output.bufferMu.Unlock()
+		if marks == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for the mark")
+		}
+	}
+	output.ClearBuffer()
+
+	select {
+	case err := <-awaited:
+		if !errors.Is(err, ErrBufferCleared) {
+			t.Fatalf("expected ErrBufferCleared, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("AwaitMark kept waiting after the buffer was cleared")
+	}
+}
+
+type fakeRoom struct {
+	remote     *fakeRemoteTrack
+	local      *fakeLocalTrack
+	subscribed string
+	published  string
+}
+
+func (r *fakeRoom) SubscribeAudio(_ context.Context, identity string, _ audio.EncodingInfo) (RemoteTrack, error) {
+	r.subscribed = identity
+	return r.remote, nil
+}
+
+func (r *fakeRoom) PublishAudio(_ context.Context, name string, _ audio.EncodingInfo) (LocalTrack, error) {
+	r.published = name
+	return r.local, nil
+}
+
+type fakeRemoteTrack struct {
+	mu           sync.Mutex
+	onAudio      func(audio []byte)
+	unsubscribed bool
+}
+
+func (t *fakeRemoteTrack) OnAudio(onAudio func(audio []byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onAudio = onAudio
+}
+
+func (t *fakeRemoteTrack) Unsubscribe() error {
+	t.unsubscribed = true
+	return nil
+}
+
+func (t *fakeRemoteTrack) receive(audio []byte) {
+	t.mu.Lock()
+	onAudio := t.onAudio
+	t.mu.Unlock()
+	if onAudio != nil {
+		onAudio(audio)
+	}
+}
+
+type fakeLocalTrack struct {
+	mu          sync.Mutex
+	audio       []byte
+	latency     time.Duration
+	unpublished bool
+}
+
+func (t *fakeLocalTrack) WriteAudio(audio []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.audio = append(t.audio, audio...)
+	return nil
+}
+
+func (t *fakeLocalTrack) Unpublish() error {
+	t.unpublished = true
+	return nil
+}
+
+func (t *fakeLocalTrack) Latency() time.Duration { return t.latency }
+
+func (t *fakeLocalTrack) written() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.audio)
+}
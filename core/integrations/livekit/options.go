@@ -0,0 +1,69 @@
+package livekit
+
+import (
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+const (
+	defaultFrameDuration = 20 * time.Millisecond
+	defaultTrackName     = "agent"
+)
+
+type Options struct {
+	encoding      audio.EncodingInfo
+	frameDuration time.Duration
+	trackName     string
+	onMarkLatency func(MarkLatency)
+	logger        logging.Logger
+}
+
+type Option func(*Options)
+
+func defaultOptions() Options {
+	return Options{
+		encoding:      audio.GetDefaultEncodingInfo(),
+		frameDuration: defaultFrameDuration,
+		trackName:     defaultTrackName,
+		logger:        logging.Default(),
+	}
+}
+
+// WithEncodingInfo sets the encoding of the audio exchanged with the tracks,
+// defaults to [audio.GetDefaultEncodingInfo].
+func WithEncodingInfo(encoding audio.EncodingInfo) Option {
+	return func(o *Options) {
+		o.encoding = encoding
+	}
+}
+
+// WithFrameDuration sets the duration of the audio published at once,
+// defaults to 20ms.
+func WithFrameDuration(duration time.Duration) Option {
+	return func(o *Options) {
+		o.frameDuration = duration
+	}
+}
+
+// WithTrackName sets the name of the published track, defaults to "agent".
+func WithTrackName(name string) Option {
+	return func(o *Options) {
+		o.trackName = name
+	}
+}
+
+// WithMarkLatencyCallback sets the callback receiving the latency of each
+// mark of the output once it is confirmed.
+func WithMarkLatencyCallback(callback func(MarkLatency)) Option {
+	return func(o *Options) {
+		o.onMarkLatency = callback
+	}
+}
+
+func WithLogger(logger logging.Logger) Option {
+	return func(o *Options) {
+		o.logger = logger
+	}
+}
@@ -0,0 +1,251 @@
+package livekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+// ErrOutputClosed is the error of sending to a closed output.
+var ErrOutputClosed = errors.New("livekit output closed")
+
+// ErrBufferCleared is the error of [Output.AwaitMark] when the audio it
+// awaits is dropped by [Output.ClearBuffer].
+var ErrBufferCleared = errors.New("livekit output buffer cleared")
+
+// MarkLatency describes the confirmation of a mark of the output.
+type MarkLatency struct {
+	Mark string
+	// Queued is the duration of the audio queued before the mark when it
+	// was set, the least it takes to confirm it.
+	Queued time.Duration
+	// Confirmed is the time from setting the mark to its confirmation, it
+	// exceeds Queued when publishing falls behind.
+	Confirmed time.Duration
+	// Transport is the latency of the audio to the participants, reported
+	// by the track if it is a [LatencyReporter]. The participants hear the
+	// audio before the mark that much after its confirmation.
+	Transport time.Duration
+}
+
+// Output publishes audio on a track of a room, as the marked audio output of
+// the orchestrator.
+//
+// Audio sent to the output is buffered and published one frame at a time in
+// real time, so marks are confirmed once the audio before them was
+// published.
+type Output struct {
+	track         LocalTrack
+	encoding      audio.EncodingInfo
+	frameDuration time.Duration
+	onMarkLatency func(MarkLatency)
+	logger        logging.Logger
+
+	buffer   audio.FrameQueue
+	marks    []playbackMark
+	bufferMu sync.Mutex
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type playbackMark struct {
+	name     string
+	position int
+	callback func(string)
+	// cleared is called instead of callback when the mark is dropped by
+	// [Output.ClearBuffer].
+	cleared func()
+
+	setAt  time.Time
+	queued time.Duration
+}
+
+// NewOutput publishes a track on room for the audio sent to the output.
+func NewOutput(ctx context.Context, room Room, opts ...Option) (*Output, error) {
+	options := defaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if room == nil {
+		return nil, errors.New("room is required")
+	}
+	if options.encoding.IsZero() || options.encoding.Format.ByteSize() <= 0 {
+		return nil, fmt.Errorf("invalid encoding: %+v", options.encoding)
+	}
+	if options.frameDuration <= 0 {
+		return nil, errors.New("frame duration must be positive")
+	}
+
+	track, err := room.PublishAudio(ctx, options.trackName, options.encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish livekit audio: %w", err)
+	}
+
+	o := &Output{
+		track:         track,
+		encoding:      options.encoding,
+		frameDuration: options.frameDuration,
+		onMarkLatency: options.onMarkLatency,
+		logger:        options.logger,
+		done:          make(chan struct{}),
+	}
+	go o.publishLoop()
+	return o, nil
+}
+
+func (o *Output) EncodingInfo() audio.EncodingInfo {
+	return o.encoding
+}
+
+// SendAudio queues audio for publishing.
+func (o *Output) SendAudio(audio []byte) error {
+	select {
+	case <-o.done:
+		return ErrOutputClosed
+	default:
+	}
+
+	o.bufferMu.Lock()
+	defer o.bufferMu.Unlock()
+	o.buffer.Write(audio)
+	return nil
+}
+
+// ClearBuffer drops queued audio together with its pending marks, whose
+// callbacks are not called.
+func (o *Output) ClearBuffer() {
+	o.bufferMu.Lock()
+	o.buffer.Reset()
+	dropped := o.marks
+	o.marks = nil
+	o.bufferMu.Unlock()
+
+	for _, mark := range dropped {
+		if mark.cleared != nil {
+			mark.cleared()
+		}
+	}
+}
+
+// Mark calls callback once all audio queued before it has been published.
+func (o *Output) Mark(mark string, callback func(string)) error {
+	o.addMark(playbackMark{name: mark, callback: callback})
+	return nil
+}
+
+// AwaitMark blocks until all currently queued audio has been published. It
+// fails with [ErrBufferCleared] if the audio is dropped by
+// [Output.ClearBuffer] first.
+func (o *Output) AwaitMark() error {
+	published := make(chan struct{})
+	cleared := make(chan struct{})
+	o.addMark(playbackMark{
+		callback: func(string) { close(published) },
+		cleared:  func() { close(cleared) },
+	})
+
+	select {
+	case <-published:
+		return nil
+	case <-cleared:
+		return ErrBufferCleared
+	case <-o.done:
+		return ErrOutputClosed
+	}
+}
+
+func (o *Output) addMark(mark playbackMark) {
+	o.bufferMu.Lock()
+	defer o.bufferMu.Unlock()
+	mark.position = o.buffer.Len()
+	mark.setAt = time.Now()
+	mark.queued = o.duration(o.buffer.Len())
+	o.marks = append(o.marks, mark)
+}
+
+// Close stops publishing and unpublishes the track.
+func (o *Output) Close() {
+	o.closeOnce.Do(func() {
+		close(o.done)
+		if err := o.track.Unpublish(); err != nil {
+			o.logger.Error("failed to unpublish livekit audio", "error", err)
+		}
+	})
+}
+
+func (o *Output) publishLoop() {
+	frameSize := o.encoding.SampleRate * o.encoding.Format.ByteSize() * int(o.frameDuration) / int(time.Second)
+	ticker := time.NewTicker(o.frameDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-ticker.C:
+		}
+
+		frame, passedMarks := o.nextFrame(frameSize)
+		if len(frame) > 0 {
+			if err := o.track.WriteAudio(frame); err != nil {
+				o.logger.Error("failed to write audio to livekit track", "error", err)
+			}
+		}
+		audio.PutFrame(frame)
+		for _, mark := range passedMarks {
+			o.confirm(mark)
+		}
+	}
+}
+
+func (o *Output) nextFrame(frameSize int) ([]byte, []playbackMark) {
+	o.bufferMu.Lock()
+	defer o.bufferMu.Unlock()
+
+	n := min(frameSize, o.buffer.Len())
+	frame := audio.GetFrame(n)
+	o.buffer.Read(frame)
+
+	passed := 0
+	for i := range o.marks {
+		o.marks[i].position -= n
+		if o.marks[i].position <= 0 {
+			passed++
+		}
+	}
+	passedMarks := o.marks[:passed:passed]
+	o.marks = o.marks[passed:]
+
+	return frame, passedMarks
+}
+
+func (o *Output) confirm(mark playbackMark) {
+	latency := MarkLatency{
+		Mark:      mark.name,
+		Queued:    mark.queued,
+		Confirmed: time.Since(mark.setAt),
+	}
+	if reporter, ok := o.track.(LatencyReporter); ok {
+		latency.Transport = reporter.Latency()
+	}
+	o.logger.Debug("livekit mark confirmed", "mark", latency.Mark, "queued", latency.Queued,
+		"confirmed", latency.Confirmed, "transport", latency.Transport)
+
+	if o.onMarkLatency != nil {
+		o.onMarkLatency(latency)
+	}
+	if mark.callback != nil {
+		mark.callback(mark.name)
+	}
+}
+
+// duration returns the duration of size bytes of audio.
+func (o *Output) duration(size int) time.Duration {
+	return time.Duration(size) * time.Second / time.Duration(o.encoding.SampleRate*o.encoding.Format.ByteSize())
+}
@@ -0,0 +1,43 @@
+package livekit
+
+import (
+	"context"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+// Room is a joined LiveKit room.
+type Room interface {
+	// SubscribeAudio subscribes to the microphone track of the participant
+	// with identity, an empty identity is the first remote participant
+	// publishing one. Its audio is delivered with encoding.
+	SubscribeAudio(ctx context.Context, identity string, encoding audio.EncodingInfo) (RemoteTrack, error)
+	// PublishAudio publishes an audio track named name, written with
+	// encoding.
+	PublishAudio(ctx context.Context, name string, encoding audio.EncodingInfo) (LocalTrack, error)
+}
+
+// RemoteTrack is a subscribed audio track of a remote participant.
+type RemoteTrack interface {
+	// OnAudio registers the callback receiving the audio of the track.
+	// Registering a new callback replaces the previous one, nil stops
+	// delivery.
+	OnAudio(onAudio func(audio []byte))
+	Unsubscribe() error
+}
+
+// LocalTrack is a published audio track.
+type LocalTrack interface {
+	// WriteAudio publishes audio on the track. The frame is reused once
+	// WriteAudio returns, so it must not be retained.
+	WriteAudio(audio []byte) error
+	Unpublish() error
+}
+
+// LatencyReporter is implemented by local tracks that know the latency of
+// their audio to the participants, e.g. from the WebRTC statistics of the
+// connection. It is reported with the confirmed marks, see [MarkLatency].
+type LatencyReporter interface {
+	Latency() time.Duration
+}
@@ -0,0 +1,28 @@
+// Package twilio connects phone calls to the orchestrator over Twilio Media
+// Streams (https://www.twilio.com/docs/voice/media-streams).
+//
+// A bidirectional stream, started with <Connect><Stream> in the TwiML of the
+// call, opens a websocket to the agent. [Accept] takes it over as a
+// [MediaStream], which is the audio input and the marked audio output of the
+// orchestrator: the audio of the caller is received as mulaw at 8kHz and the
+// audio sent to it is played to the caller, with Twilio reporting the marks
+// once the audio before them was played.
+//
+//	http.HandleFunc("/media", func(w http.ResponseWriter, r *http.Request) {
+//		stream, err := twilio.Accept(w, r)
+//		if err != nil {
+//			return
+//		}
+//		o := orchestration.NewOrchestrator(append(stream.Options(),
+//			orchestration.WithStreamingLLM(llm),
+//			orchestration.WithSpeechToTextClient(stt),
+//			orchestration.WithTextToSpeechClientV1(tts),
+//		)...)
+//		defer o.Close()
+//		o.Orchestrate(r.Context())
+//		<-stream.Done()
+//	})
+//
+// The speech to text and text to speech clients have to be configured for
+// mulaw at 8kHz, see [MediaStream.EncodingInfo].
+package twilio
@@ -0,0 +1,43 @@
+package twilio
+
+// message is a message of the media streams protocol, in either direction.
+type message struct {
+	Event     string        `json:"event"`
+	StreamSID string        `json:"streamSid,omitempty"`
+	Start     *startPayload `json:"start,omitempty"`
+	Media     *mediaPayload `json:"media,omitempty"`
+	Mark      *markPayload  `json:"mark,omitempty"`
+}
+
+type startPayload struct {
+	StreamSID        string            `json:"streamSid"`
+	AccountSID       string            `json:"accountSid"`
+	CallSID          string            `json:"callSid"`
+	Tracks           []string          `json:"tracks"`
+	CustomParameters map[string]string `json:"customParameters"`
+	MediaFormat      struct {
+		Encoding   string `json:"encoding"`
+		SampleRate int    `json:"sampleRate"`
+		Channels   int    `json:"channels"`
+	} `json:"mediaFormat"`
+}
+
+type mediaPayload struct {
+	Track   string `json:"track,omitempty"`
+	Payload string `json:"payload"`
+}
+
+type markPayload struct {
+	Name string `json:"name"`
+}
+
+const (
+	eventConnected = "connected"
+	eventStart     = "start"
+	eventMedia     = "media"
+	eventMark      = "mark"
+	eventClear     = "clear"
+	eventStop      = "stop"
+
+	trackInbound = "inbound"
+)
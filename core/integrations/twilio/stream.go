@@ -0,0 +1,398 @@
+package twilio
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+// ErrStreamClosed is the error of sending to a stream once the call ended or
+// the stream was closed.
+var ErrStreamClosed = errors.New("twilio media stream closed")
+
+// ErrPlaybackCleared is the error of [MediaStream.AwaitMark] when the audio
+// it awaits is dropped by [MediaStream.ClearBuffer].
+var ErrPlaybackCleared = errors.New("twilio playback cleared")
+
+const defaultStartTimeout = 10 * time.Second
+
+// Conn is the websocket of a media stream, e.g. a [websocket.Conn].
+type Conn interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
+// StartInfo describes the stream and its call, as Twilio reports it when the
+// stream starts.
+type StartInfo struct {
+	StreamSID  string
+	CallSID    string
+	AccountSID string
+	// CustomParameters are the <Parameter>s of the <Stream> in the TwiML,
+	// e.g. the numbers of the call, see [MediaStream.CallInfo].
+	CustomParameters map[string]string
+}
+
+type MediaStreamOptions struct {
+	startTimeout time.Duration
+	logger       logging.Logger
+}
+
+type MediaStreamOption func(*MediaStreamOptions)
+
+// WithStartTimeout limits the wait for Twilio to start the stream after the
+// websocket was opened, defaults to 10s.
+func WithStartTimeout(timeout time.Duration) MediaStreamOption {
+	return func(o *MediaStreamOptions) {
+		o.startTimeout = timeout
+	}
+}
+
+func WithLogger(logger logging.Logger) MediaStreamOption {
+	return func(o *MediaStreamOptions) {
+		o.logger = logger
+	}
+}
+
+// MediaStream is a started media stream of a call, it is an audio input and
+// a marked audio output of the orchestrator, see [MediaStream.Options].
+type MediaStream struct {
+	conn   Conn
+	start  StartInfo
+	logger logging.Logger
+
+	onAudio   func(audio []byte)
+	capturing bool
+	captureMu sync.Mutex
+
+	// marks are sent to Twilio and not yet played.
+	marks []playbackMark
+	// markSeq numbers the marks sent, so reports of marks dropped by
+	// ClearBuffer are not mistaken for later marks of the same name.
+	markSeq int
+	writeMu sync.Mutex
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type playbackMark struct {
+	id       string
+	name     string
+	callback func(string)
+	// cleared is called instead of callback when the mark is dropped by
+	// [MediaStream.ClearBuffer].
+	cleared func()
+}
+
+var upgrader = websocket.Upgrader{}
+
+// Accept upgrades the request of Twilio to a websocket and waits for the
+// media stream to start on it.
+func Accept(w http.ResponseWriter, r *http.Request, opts ...MediaStreamOption) (*MediaStream, error) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upgrade twilio media stream: %w", err)
+	}
+	return NewMediaStream(conn, opts...)
+}
+
+// NewMediaStream waits for the media stream to start on conn, e.g. a
+// websocket upgraded by the HTTP server of the application. It closes conn
+// if the stream does not start.
+func NewMediaStream(conn Conn, opts ...MediaStreamOption) (*MediaStream, error) {
+	options := MediaStreamOptions{startTimeout: defaultStartTimeout, logger: logging.Default()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if conn == nil {
+		return nil, errors.New("twilio media stream connection is nil")
+	}
+
+	// Reads only fail on a closed connection, it is closed once the start
+	// is overdue.
+	timeout := time.AfterFunc(options.startTimeout, func() { _ = conn.Close() })
+	start, err := awaitStart(conn)
+	if !timeout.Stop() && err != nil {
+		err = fmt.Errorf("timed out waiting for twilio media stream to start: %w", err)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	s := &MediaStream{
+		conn:   conn,
+		start:  start,
+		logger: logging.With(options.logger, "stream_sid", start.StreamSID),
+		done:   make(chan struct{}),
+	}
+	go s.readLoop()
+	return s, nil
+}
+
+func awaitStart(conn Conn) (StartInfo, error) {
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return StartInfo{}, fmt.Errorf("failed to read twilio media stream: %w", err)
+		}
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return StartInfo{}, fmt.Errorf("failed to parse twilio message: %w", err)
+		}
+
+		switch msg.Event {
+		case eventConnected:
+		case eventStart:
+			if msg.Start == nil {
+				return StartInfo{}, errors.New("twilio start message without start")
+			}
+			if format := msg.Start.MediaFormat; format.Encoding != "" && format.Encoding != "audio/x-mulaw" {
+				return StartInfo{}, fmt.Errorf("unsupported twilio media format: %s", format.Encoding)
+			}
+			return StartInfo{
+				StreamSID:        msg.Start.StreamSID,
+				CallSID:          msg.Start.CallSID,
+				AccountSID:       msg.Start.AccountSID,
+				CustomParameters: msg.Start.CustomParameters,
+			}, nil
+		default:
+			return StartInfo{}, fmt.Errorf("unexpected twilio message before start: %s", msg.Event)
+		}
+	}
+}
+
+// Start returns the description of the stream.
+func (s *MediaStream) Start() StartInfo {
+	return s.start
+}
+
+// CallInfo returns the call information of the stream, the caller and the
+// dialed number are taken from the "From" and "To" custom parameters, e.g.
+// set with <Parameter name="From" value="{{From}}"/> in the TwiML.
+func (s *MediaStream) CallInfo() orchestration.CallInfoV0 {
+	return orchestration.CallInfoV0{
+		CallerID:     s.start.CustomParameters["From"],
+		CalledNumber: s.start.CustomParameters["To"],
+		Channel:      "pstn",
+	}
+}
+
+// Options returns the orchestrator options connecting the stream, its audio
+// input, output and call information.
+func (s *MediaStream) Options() []orchestration.OrchestratorOption {
+	return []orchestration.OrchestratorOption{
+		orchestration.WithAudioInput(s),
+		orchestration.WithAudioOutputV1(s),
+		orchestration.WithCallInfoV0(s.CallInfo()),
+	}
+}
+
+// Done is closed once the call ended or the stream was closed.
+func (s *MediaStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// EncodingInfo returns the encoding of the audio of Twilio, mulaw at 8kHz.
+func (s *MediaStream) EncodingInfo() audio.EncodingInfo {
+	return audio.EncodingInfo{SampleRate: 8000, Format: audio.EncodingMulaw}
+}
+
+// Stream starts forwarding the audio of the caller to onAudio.
+func (s *MediaStream) Stream(ctx context.Context, onAudio func(audio []byte)) error {
+	return s.StartCapture(ctx, onAudio)
+}
+
+func (s *MediaStream) StartCapture(_ context.Context, onAudio func(audio []byte)) error {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+	s.onAudio = onAudio
+	s.capturing = true
+	return nil
+}
+
+func (s *MediaStream) StopCapture() error {
+	s.captureMu.Lock()
+	defer s.captureMu.Unlock()
+	s.capturing = false
+	return nil
+}
+
+// SendAudio sends audio, mulaw at 8kHz, to be played to the caller.
+func (s *MediaStream) SendAudio(audio []byte) error {
+	return s.send(message{Event: eventMedia, Media: &mediaPayload{Payload: base64.StdEncoding.EncodeToString(audio)}})
+}
+
+// ClearBuffer stops the playback of the audio sent, dropping its pending
+// marks, whose callbacks are not called.
+func (s *MediaStream) ClearBuffer() {
+	s.writeMu.Lock()
+	dropped := s.marks
+	s.marks = nil
+	if err := s.write(message{Event: eventClear}); err != nil && !errors.Is(err, ErrStreamClosed) {
+		s.logger.Error("failed to clear twilio playback", "error", err)
+	}
+	s.writeMu.Unlock()
+
+	for _, mark := range dropped {
+		if mark.cleared != nil {
+			mark.cleared()
+		}
+	}
+}
+
+// Mark calls callback once Twilio played all audio sent before it.
+func (s *MediaStream) Mark(mark string, callback func(string)) error {
+	return s.addMark(playbackMark{name: mark, callback: callback})
+}
+
+// AwaitMark blocks until Twilio played all audio sent. It fails with
+// [ErrPlaybackCleared] if the audio is dropped by [MediaStream.ClearBuffer]
+// first.
+func (s *MediaStream) AwaitMark() error {
+	played := make(chan struct{})
+	cleared := make(chan struct{})
+	if err := s.addMark(playbackMark{
+		callback: func(string) { close(played) },
+		cleared:  func() { close(cleared) },
+	}); err != nil {
+		return err
+	}
+
+	select {
+	case <-played:
+		return nil
+	case <-cleared:
+		return ErrPlaybackCleared
+	case <-s.done:
+		return ErrStreamClosed
+	}
+}
+
+// addMark sends mark to Twilio under an ID unique to the stream.
+func (s *MediaStream) addMark(mark playbackMark) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.markSeq++
+	mark.id = fmt.Sprintf("%s#%d", mark.name, s.markSeq)
+	if err := s.write(message{Event: eventMark, Mark: &markPayload{Name: mark.id}}); err != nil {
+		return err
+	}
+	s.marks = append(s.marks, mark)
+	return nil
+}
+
+// Close closes the websocket, which ends the stream, Twilio continues with
+// the TwiML of the call after <Connect>.
+func (s *MediaStream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if err := s.conn.Close(); err != nil {
+			s.logger.Debug("failed to close twilio media stream", "error", err)
+		}
+	})
+}
+
+func (s *MediaStream) send(msg message) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.write(msg)
+}
+
+func (s *MediaStream) write(msg message) error {
+	select {
+	case <-s.done:
+		return ErrStreamClosed
+	default:
+	}
+
+	msg.StreamSID = s.start.StreamSID
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode twilio message: %w", err)
+	}
+	if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("failed to send twilio message: %w", err)
+	}
+	return nil
+}
+
+func (s *MediaStream) readLoop() {
+	defer s.Close()
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-s.done:
+			default:
+				s.logger.Warn("twilio media stream ended unexpectedly", "error", err)
+			}
+			return
+		}
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.logger.Warn("failed to parse twilio message", "error", err)
+			continue
+		}
+
+		switch msg.Event {
+		case eventMedia:
+			if msg.Media != nil && (msg.Media.Track == "" || msg.Media.Track == trackInbound) {
+				s.receiveAudio(msg.Media.Payload)
+			}
+		case eventMark:
+			if msg.Mark != nil {
+				s.markPlayed(msg.Mark.Name)
+			}
+		case eventStop:
+			return
+		}
+	}
+}
+
+func (s *MediaStream) receiveAudio(payload string) {
+	audio, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		s.logger.Warn("failed to decode twilio audio", "error", err)
+		return
+	}
+
+	s.captureMu.Lock()
+	onAudio, capturing := s.onAudio, s.capturing
+	s.captureMu.Unlock()
+	if capturing && onAudio != nil {
+		onAudio(audio)
+	}
+}
+
+// markPlayed calls the callback of the pending mark id. Marks reported after
+// the playback was cleared are not pending anymore.
+func (s *MediaStream) markPlayed(id string) {
+	s.writeMu.Lock()
+	var played *playbackMark
+	for i, mark := range s.marks {
+		if mark.id == id {
+			played = &mark
+			s.marks = append(s.marks[:i:i], s.marks[i+1:]...)
+			break
+		}
+	}
+	s.writeMu.Unlock()
+
+	if played != nil && played.callback != nil {
+		played.callback(played.name)
+	}
+}
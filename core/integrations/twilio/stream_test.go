@@ -0,0 +1,195 @@
+package twilio
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+// connectTwilio accepts a media stream from a websocket client playing
+// Twilio, which sends the connected and start messages.
+func connectTwilio(t *testing.T, opts ...MediaStreamOption) (*MediaStream, *websocket.Conn) {
+	t.Helper()
+
+	accepted := make(chan *MediaStream, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream, err := Accept(w, r, append(opts, WithLogger(logging.Discard()))...)
+		if err != nil {
+			t.Errorf("failed to accept stream: %v", err)
+		}
+		accepted <- stream
+	}))
+	t.Cleanup(server.Close)
+
+	twilio, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { twilio.Close() })
+	sendJSON(t, twilio, `{"event":"connected","protocol":"Call","version":"1.0.0"}`)
+	sendJSON(t, twilio, `{"event":"start","sequenceNumber":"1","streamSid":"MZ1","start":{"streamSid":"MZ1","accountSid":"AC1","callSid":"CA1","tracks":["inbound"],"customParameters":{"From":"+15550001","To":"+15550002"},"mediaFormat":{"encoding":"audio/x-mulaw","sampleRate":8000,"channels":1}}}`)
+
+	select {
+	case stream := <-accepted:
+		if stream == nil {
+			t.FailNow()
+		}
+		t.Cleanup(stream.Close)
+		return stream, twilio
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out accepting stream")
+		return nil, nil
+	}
+}
+
+func sendJSON(t *testing.T, conn *websocket.Conn, msg string) {
+	t.Helper()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		t.Fatalf("failed to send %s: %v", msg, err)
+	}
+}
+
+func receive(t *testing.T, conn *websocket.Conn) message {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("failed to parse %s: %v", data, err)
+	}
+	return msg
+}
+
+func TestMediaStreamForwardsCallerAudio(t *testing.T) {
+	stream, twilio := connectTwilio(t)
+
+	if start := stream.Start(); start.CallSID != "CA1" || start.StreamSID != "MZ1" {
+		t.Fatalf("expected the start of the stream, got %+v", start)
+	}
+	if info := stream.CallInfo(); info.CallerID != "+15550001" || info.CalledNumber != "+15550002" {
+		t.Fatalf("expected the numbers of the call, got %+v", info)
+	}
+
+	received := make(chan []byte, 1)
+	stream.Stream(context.Background(), func(audio []byte) { received <- audio })
+	sendJSON(t, twilio, `{"event":"media","streamSid":"MZ1","media":{"track":"inbound","chunk":"1","timestamp":"5","payload":"`+base64.StdEncoding.EncodeToString([]byte{0xff, 0x7f})+`"}}`)
+
+	select {
+	case audio := <-received:
+		if string(audio) != string([]byte{0xff, 0x7f}) {
+			t.Fatalf("expected the decoded payload, got %x", audio)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for audio")
+	}
+}
+
+func TestMediaStreamReportsMarksOncePlayed(t *testing.T) {
+	stream, twilio := connectTwilio(t)
+
+	if err := stream.SendAudio([]byte{0xff}); err != nil {
+		t.Fatalf("failed to send audio: %v", err)
+	}
+	if msg := receive(t, twilio); msg.Event != eventMedia || msg.StreamSID != "MZ1" || msg.Media.Payload != "/w==" {
+		t.Fatalf("expected the audio of the stream, got %+v", msg)
+	}
+
+	marked := make(chan string, 2)
+	stream.Mark("dropped", func(mark string) { marked <- mark })
+	dropped := receive(t, twilio)
+	stream.ClearBuffer()
+	if msg := receive(t, twilio); msg.Event != eventClear {
+		t.Fatalf("expected playback to be cleared, got %+v", msg)
+	}
+	stream.Mark("dropped", func(mark string) { marked <- mark })
+	played := receive(t, twilio)
+
+	// Twilio reports the marks dropped by the clear as well.
+	sendJSON(t, twilio, `{"event":"mark","streamSid":"MZ1","mark":{"name":"`+dropped.Mark.Name+`"}}`)
+	sendJSON(t, twilio, `{"event":"mark","streamSid":"MZ1","mark":{"name":"`+played.Mark.Name+`"}}`)
+
+	select {
+	case mark := <-marked:
+		if mark != "dropped" {
+			t.Fatalf("expected the mark name, got %q", mark)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for mark")
+	}
+	select {
+	case <-marked:
+		t.Fatalf("expected the mark dropped by the clear not to be reported")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMediaStreamAwaitMarkReturnsWhenPlaybackIsCleared(t *testing.T) {
+	stream, twilio := connectTwilio(t)
+
+	awaited := make(chan error, 1)
+	go func() { awaited <- stream.AwaitMark() }()
+	if msg := receive(t, twilio); msg.Event != eventMark {
+		t.Fatalf("expected the awaited mark, got %+v", msg)
+	}
+	stream.ClearBuffer()
+
+	select {
+	case err := <-awaited:
+		if !errors.Is(err, ErrPlaybackCleared) {
+			t.Fatalf("expected ErrPlaybackCleared, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("AwaitMark kept waiting after playback was cleared")
+	}
+}
+
+func TestMediaStreamEndsWithTheCall(t *testing.T) {
+	stream, twilio := connectTwilio(t)
+
+	sendJSON(t, twilio, `{"event":"stop","streamSid":"MZ1","stop":{"callSid":"CA1"}}`)
+	select {
+	case <-stream.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for the stream to end")
+	}
+	if err := stream.SendAudio([]byte{0xff}); !errors.Is(err, ErrStreamClosed) {
+		t.Fatalf("expected sending to an ended stream to fail, got %v", err)
+	}
+}
+
+func TestAcceptTimesOutWithoutStart(t *testing.T) {
+	accepted := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := Accept(w, r, WithStartTimeout(50*time.Millisecond), WithLogger(logging.Discard()))
+		accepted <- err
+	}))
+	defer server.Close()
+
+	twilio, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer twilio.Close()
+	sendJSON(t, twilio, `{"event":"connected"}`)
+
+	select {
+	case err := <-accepted:
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Fatalf("expected accepting to time out, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for accept")
+	}
+}
@@ -0,0 +1,116 @@
+package orchestration
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// sideQuestionInterruptionType is the type the LLM interruption handlers
+// classify unrelated questions of the user as.
+const sideQuestionInterruptionType = "new prompt"
+
+type InterruptionResumeOptions struct {
+	types []string
+	offer bool
+}
+
+type InterruptionResumeOption func(*InterruptionResumeOptions)
+
+// WithSideQuestionTypes sets the interruption types treated as side
+// questions, "new prompt" by default.
+func WithSideQuestionTypes(types ...string) InterruptionResumeOption {
+	return func(o *InterruptionResumeOptions) {
+		o.types = types
+	}
+}
+
+// WithResumeOffer makes the assistant offer to continue the interrupted
+// response instead of continuing it right away.
+func WithResumeOffer() InterruptionResumeOption {
+	return func(o *InterruptionResumeOptions) {
+		o.offer = true
+	}
+}
+
+// WithInterruptionResume picks an interrupted response back up once a side
+// question of the user is answered, "where was I". When an interruption
+// handler resolves an interruption of the active turn as a side question,
+// see [WithSideQuestionTypes], the turn is cancelled and the part of its
+// response the user did not hear is kept. Once the next turn, answering the
+// side question, completes, a turn continuing the kept response starts like
+// with [Orchestrator.ResumePlayback].
+//
+// The kept response is dropped if the answer to the side question is
+// interrupted in turn.
+func WithInterruptionResume(opts ...InterruptionResumeOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		resume := &interruptionResume{InterruptionResumeOptions: InterruptionResumeOptions{types: []string{sideQuestionInterruptionType}}}
+		for _, opt := range opts {
+			opt(&resume.InterruptionResumeOptions)
+		}
+		o.interruptionResume = resume
+	}
+}
+
+type interruptionResume struct {
+	InterruptionResumeOptions
+
+	mu sync.Mutex
+	// interruptedTurnID is the turn cancelled for a side question, its
+	// response is kept once it ends.
+	interruptedTurnID string
+	kept              *PlaybackCheckpointV0
+}
+
+// interrupted reports whether an interruption of type in the active turn is
+// a side question, remembering the turn if it is.
+func (r *interruptionResume) interrupted(activeTurn *llms.TurnV1, id int64, interruptionType string) bool {
+	if r == nil || activeTurn == nil || !slices.Contains(r.types, interruptionType) {
+		return false
+	}
+	if !slices.ContainsFunc(activeTurn.Interruptions, func(interruption llms.InterruptionV0) bool { return interruption.ID == id }) {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interruptedTurnID = activeTurn.ID
+	return true
+}
+
+// turnEnded keeps the response of a turn cancelled for a side question and
+// returns the trigger resuming it once the side question was answered.
+func (r *interruptionResume) turnEnded(turn llms.TurnV1) (llms.TriggerV0, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if turn.ID == r.interruptedTurnID {
+		r.interruptedTurnID = ""
+		r.kept = nil
+		if checkpoint, ok := checkpointOf(turn); ok {
+			r.kept = &checkpoint
+		}
+		return nil, false
+	}
+
+	kept := r.kept
+	r.kept = nil
+	if kept == nil || turn.IsCancelled() {
+		return nil, false
+	}
+	if r.offer {
+		return triggers.NewAssistantInitiatedTrigger(fmt.Sprintf(
+			"Your previous response was cut off by a question of the user, which you just answered. "+
+				"Briefly offer to continue where you left off. The part they did not hear: %q", kept.Unspoken,
+		)), true
+	}
+	return newResumePlaybackTrigger(*kept), true
+}
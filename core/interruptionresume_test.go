@@ -0,0 +1,46 @@
+package orchestration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestInterruptionResumeContinuesAfterSideQuestion(t *testing.T) {
+	o := NewOrchestrator(WithInterruptionResume())
+	defer o.Close()
+	resume := o.interruptionResume
+
+	interrupted := newActiveTurn(triggers.NewUserPromptTrigger("weather?"))
+	interrupted.Interruptions = []llms.InterruptionV0{{ID: 1, Source: "what time is it?"}}
+	if resume.interrupted(&interrupted.TurnV1, 1, "clarification") {
+		t.Fatal("expected clarifications not to count as side questions")
+	}
+	if !resume.interrupted(&interrupted.TurnV1, 1, sideQuestionInterruptionType) {
+		t.Fatal("expected the side question to interrupt the turn")
+	}
+
+	interrupted.finalResponse.Message = "It is sunny. It will rain tomorrow."
+	interrupted.finalResponse.TypedMessage = "It is sunny. It will rain tomorrow."
+	interrupted.finalResponse.SpokenResponse = "It is sunny."
+	interrupted.truncateToSpoken()
+	interrupted.Finalise()
+	if _, ok := resume.turnEnded(interrupted.TurnV1); ok {
+		t.Fatal("expected no resume when the interrupted turn ends")
+	}
+
+	answer := newActiveTurn(triggers.NewUserPromptTrigger("what time is it?"))
+	answer.finalResponse.Message = "It is noon."
+	answer.finalResponse.IsMessageFullyGenerated = true
+	answer.Finalise()
+	trigger, ok := resume.turnEnded(answer.TurnV1)
+	assistant, isAssistant := trigger.(triggers.AssistantInitiatedTrigger)
+	if !ok || !isAssistant || !strings.Contains(assistant.Instruction, "It will rain tomorrow.") {
+		t.Fatalf("expected the answer to resume the unspoken part, got %#v", trigger)
+	}
+	if _, ok := resume.turnEnded(answer.TurnV1); ok {
+		t.Fatal("expected the response to be resumed once")
+	}
+}
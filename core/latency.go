@@ -0,0 +1,83 @@
+package orchestration
+
+import (
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// turnLatency records when each stage of a turn first happened. Stages are
+// marked from the pipeline workers concurrently.
+type turnLatency struct {
+	mu sync.Mutex
+
+	queuedAt  time.Time
+	startedAt time.Time
+
+	llmFirstToken   time.Time
+	llmDone         time.Time
+	ttsFirstAudio   time.Time
+	playbackStarted time.Time
+	playbackEnded   time.Time
+}
+
+func newTurnLatency(queuedAt time.Time) *turnLatency {
+	return &turnLatency{queuedAt: queuedAt, startedAt: time.Now()}
+}
+
+// mark records the current time for stage unless it was already recorded.
+func (l *turnLatency) mark(stage *time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if stage.IsZero() {
+		*stage = time.Now()
+	}
+}
+
+// markLatest records the current time for stage, overwriting earlier records.
+func (l *turnLatency) markLatest(stage *time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*stage = time.Now()
+}
+
+func (l *turnLatency) Report() llms.LatencyReport {
+	if l == nil {
+		return llms.LatencyReport{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sinceStart := func(stage time.Time) time.Duration {
+		if stage.IsZero() {
+			return 0
+		}
+		return stage.Sub(l.startedAt)
+	}
+
+	report := llms.LatencyReport{
+		LLMFirstToken: sinceStart(l.llmFirstToken),
+		LLMTotal:      sinceStart(l.llmDone),
+		TTSFirstAudio: sinceStart(l.ttsFirstAudio),
+		PlaybackStart: sinceStart(l.playbackStarted),
+		PlaybackEnd:   sinceStart(l.playbackEnded),
+	}
+	if !l.queuedAt.IsZero() && l.startedAt.After(l.queuedAt) {
+		report.QueueWait = l.startedAt.Sub(l.queuedAt)
+	}
+	return report
+}
+
+func latencyAttributes(report llms.LatencyReport) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Float64("assistant_turn.latency.queue_wait", report.QueueWait.Seconds()),
+		attribute.Float64("assistant_turn.latency.llm_first_token", report.LLMFirstToken.Seconds()),
+		attribute.Float64("assistant_turn.latency.llm_total", report.LLMTotal.Seconds()),
+		attribute.Float64("assistant_turn.latency.tts_first_audio", report.TTSFirstAudio.Seconds()),
+		attribute.Float64("assistant_turn.latency.playback_start", report.PlaybackStart.Seconds()),
+		attribute.Float64("assistant_turn.latency.playback_end", report.PlaybackEnd.Seconds()),
+	}
+}
@@ -0,0 +1,69 @@
+package orchestration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestCompletedTurnReportsLatency(t *testing.T) {
+	interval := 20 * time.Millisecond
+	o := NewOrchestrator(WithStreamingLLM(scriptedStreamLLMStub{chunks: []string{"one ", "two"}, interval: interval}))
+	defer o.Close()
+
+	completed := make(chan events.TurnCompleted, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if turnCompleted, ok := event.(events.TurnCompleted); ok {
+			completed <- turnCompleted
+		}
+	}))
+
+	o.QueuePrompt("hello")
+
+	var event events.TurnCompleted
+	select {
+	case event = <-completed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for turn to complete")
+	}
+
+	report := event.Latency
+	if report.LLMFirstToken < interval {
+		t.Fatalf("expected first token after at least %v, got %v", interval, report.LLMFirstToken)
+	}
+	if report.LLMTotal < 2*interval || report.LLMTotal < report.LLMFirstToken {
+		t.Fatalf("expected llm total to cover both chunks, got %+v", report)
+	}
+	if report.TTSFirstAudio != 0 || report.PlaybackStart != 0 || report.PlaybackEnd != 0 {
+		t.Fatalf("expected no speech stages without text to speech, got %+v", report)
+	}
+
+	history := o.conversation.History()
+	if len(history) != 1 {
+		t.Fatalf("expected one finalised turn, got %d", len(history))
+	}
+	if events.LatencyReport(history[0].Latency) != report {
+		t.Fatalf("expected turn latency %+v to match event %+v", history[0].Latency, report)
+	}
+}
+
+func TestTurnLatencyKeepsFirstMarkAndMeasuresQueueWait(t *testing.T) {
+	latency := newTurnLatency(time.Now().Add(-time.Second))
+
+	latency.mark(&latency.llmFirstToken)
+	first := latency.Report().LLMFirstToken
+	time.Sleep(5 * time.Millisecond)
+	latency.mark(&latency.llmFirstToken)
+
+	report := latency.Report()
+	if report.LLMFirstToken != first {
+		t.Fatalf("expected first mark %v to be kept, got %v", first, report.LLMFirstToken)
+	}
+	if report.QueueWait < time.Second {
+		t.Fatalf("expected queue wait of at least a second, got %v", report.QueueWait)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
-	"log"
-
+	"github.com/koscakluka/ema-core/core/errorreport"
 	events "github.com/koscakluka/ema-core/core/events"
 	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/triggers"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -18,11 +20,54 @@ type llm struct {
 	client LLM
 	// tools stores the effective tool list exposed to model calls.
 	tools []llms.Tool
+	// toolSources add the tools they currently offer to tools, see
+	// [WithToolSource].
+	toolSources []ToolSourceV0
+	// toolPool executes tool calls when set, otherwise they run inline.
+	toolPool *ToolPool
+	// budget limits usage when set, see [WithBudget].
+	budget *budget
+	// usage adds up the usage of the conversation, see [TokenUsageV0].
+	usage *usageMeter
+	// systemPrompt replaces the system prompt of the client for a turn when
+	// set, see [TurnRequest].
+	systemPrompt string
+	// systemPromptAddition is appended to the system prompt of the turn, or
+	// of the client without one, see [WithVoiceShaping].
+	systemPromptAddition string
+	// stopGeneration ends a streamed response early when it reports true,
+	// see [WithVoiceShaping].
+	stopGeneration func() bool
+	// toolFiller speaks holding phrases during slow tool calls when set,
+	// see [WithToolCallFiller].
+	toolFiller *toolFiller
+	// toolSanitizer marks tool outputs as data when set, see
+	// [WithToolOutputSanitizer].
+	toolSanitizer *toolOutputSanitizer
+	// dryRun returns mock responses instead of executing tools when set,
+	// see [WithDryRun].
+	dryRun *DryRunOptions
+	// speakFiller passes holding phrases to the speech of the turn.
+	speakFiller func(string)
+	// reviseText passes revisions of the streamed response to the speech of
+	// the turn, see [llms.StreamRevisionChunk].
+	reviseText func(replaced, replacement string)
+	// reportProgress describes what the LLM is doing to stall detection,
+	// see [WithStallDetection].
+	reportProgress func(state string)
+	// pauseProgress stops stall detection while a tool call awaits approval.
+	pauseProgress func(paused bool)
+	// approveToolCall decides on calls of sensitive tools, see
+	// [WithToolApprovalHandler].
+	approveToolCall ToolApprovalHandler
 
 	emitEvent eventEmitter
+	logger    logging.Logger
 }
 
-func newLLM() llm { return llm{emitEvent: noopEventEmitter} }
+func newLLM() llm {
+	return llm{usage: &usageMeter{}, emitEvent: noopEventEmitter, logger: logging.Default()}
+}
 
 func (runtime *llm) set(client LLM) {
 	if runtime == nil {
@@ -61,6 +106,16 @@ func (runtime *llm) SetEventEmitter(emitEvent eventEmitter) {
 	runtime.emitEvent = emitEvent
 }
 
+func (runtime *llm) appendToolSource(source ToolSourceV0) {
+	if runtime == nil || source == nil {
+		return
+	}
+
+	runtime.toolSources = append(runtime.toolSources, source)
+}
+
+// availableTools returns the tools and the tools the tool sources currently
+// offer.
 func (runtime *llm) availableTools() []llms.Tool {
 	if runtime == nil {
 		return nil
@@ -68,6 +123,9 @@ func (runtime *llm) availableTools() []llms.Tool {
 
 	tools := make([]llms.Tool, len(runtime.tools))
 	copy(tools, runtime.tools)
+	for _, source := range runtime.toolSources {
+		tools = append(tools, source.Tools()...)
+	}
 	return tools
 }
 
@@ -76,11 +134,25 @@ func (runtime *llm) snapshot() llm {
 		return llm{}
 	}
 
-	snapshot := llm{client: runtime.client}
+	snapshot := llm{
+		client:        runtime.client,
+		toolPool:      runtime.toolPool,
+		budget:        runtime.budget,
+		usage:         runtime.usage,
+		toolFiller:    runtime.toolFiller,
+		toolSanitizer: runtime.toolSanitizer,
+		dryRun:        runtime.dryRun,
+		logger:        runtime.logger,
+
+		approveToolCall: runtime.approveToolCall,
+	}
 	if len(runtime.tools) > 0 {
 		snapshot.tools = make([]llms.Tool, len(runtime.tools))
 		copy(snapshot.tools, runtime.tools)
 	}
+	if len(runtime.toolSources) > 0 {
+		snapshot.toolSources = append([]ToolSourceV0(nil), runtime.toolSources...)
+	}
 	snapshot.SetEventEmitter(runtime.emitEvent)
 
 	return snapshot
@@ -111,7 +183,7 @@ func (runtime *llm) generate(
 		if err != nil {
 			return nil, err
 		}
-		if response != nil {
+		if response != nil && (activeTurnCancelled == nil || !activeTurnCancelled()) {
 			runtime.emitEvent(events.NewAssistantResponseFinalized(response.Content))
 		}
 		return response, nil
@@ -137,25 +209,32 @@ func (runtime *llm) processPrompt(ctx context.Context,
 	conversations []llms.TurnV1,
 	onChunk func(string),
 ) (*llms.Response, error) {
-	response, err := client.Prompt(ctx, trigger.String(),
+	opts := []llms.PromptOption{
 		llms.WithTurnsV1(conversations...),
-		llms.WithTools(runtime.tools...),
+		llms.WithTools(runtime.availableTools()...),
 		llms.WithStream(func(chunk string) {
 			if onChunk != nil {
 				onChunk(chunk)
 			}
 			runtime.emitEvent(events.NewAssistantResponseSegment(chunk))
 		}),
-	)
+	}
+	if runtime.systemPrompt != "" {
+		opts = append(opts, llms.WithSystemPrompt(runtime.systemPrompt))
+	}
+	if runtime.systemPromptAddition != "" {
+		opts = append(opts, llms.WithSystemPromptAddition(runtime.systemPromptAddition))
+	}
+	response, err := client.Prompt(ctx, trigger.String(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prompt llm: %w", err)
 	}
 
 	if len(response) == 0 {
-		log.Println("Warning: no turns returned for assistants turn")
+		runtime.logger.Warn("no turns returned for assistants turn")
 		return nil, nil
 	} else if len(response) > 1 {
-		log.Println("Warning: multiple turns returned for assistants turn")
+		runtime.logger.Warn("multiple turns returned for assistants turn", "turns", len(response))
 	}
 	return (*llms.Response)(&response[0]), nil
 }
@@ -170,33 +249,69 @@ func (runtime *llm) processStreaming(ctx context.Context,
 	span := trace.SpanFromContext(ctx)
 
 	turn := llms.TurnV1{Trigger: trigger}
+	var message strings.Builder
+	// partial is returned once the turn is cancelled, it keeps the text and
+	// the results of the tools that completed before the cancellation.
+	partial := func() *llms.Response {
+		return &llms.Response{Content: message.String(), ToolCalls: turn.ToolCalls}
+	}
+	cancelled := func() bool {
+		return activeTurnCancelled != nil && activeTurnCancelled()
+	}
 	for {
-		stream := client.PromptWithStream(ctx, nil,
+		if cancelled() {
+			return partial(), nil
+		}
+
+		opts := []llms.StreamingPromptOption{
 			llms.WithTurnsV1(append(conversation, turn)...),
-			llms.WithTools(runtime.tools...),
-		)
+			llms.WithTools(runtime.availableTools()...),
+		}
+		if runtime.systemPrompt != "" {
+			opts = append(opts, llms.WithSystemPrompt(runtime.systemPrompt))
+		}
+		if runtime.systemPromptAddition != "" {
+			opts = append(opts, llms.WithSystemPromptAddition(runtime.systemPromptAddition))
+		}
+		stream := client.PromptWithStream(ctx, nil, opts...)
 
-		var message strings.Builder
+		message.Reset()
 		toolCalls := []llms.ToolCall{}
 		for chunk, err := range stream.Chunks(ctx) {
+			if cancelled() {
+				return partial(), nil
+			}
 			if err != nil {
 				err = fmt.Errorf("failed to stream llm response: %w", err)
-				span.RecordError(err)
+				errorreport.Record(ctx, span, err, "component", string(ComponentLLM))
 				span.SetStatus(codes.Error, err.Error())
 				return nil, err
 			}
 
-			if activeTurnCancelled != nil && activeTurnCancelled() {
-				// TODO: This is probably not the best way to handle this,
-				// returning something would make more sense
-				return nil, nil
-			}
-
 			switch chunk.(type) {
 			// case llms.StreamRoleChunk:
 			// case llms.StreamReasoningChunk:
-			// case llms.StreamUsageChunk:
-			// 	chunk := chunk.(llms.StreamUsageChunk)
+			case llms.StreamUsageChunk:
+				runtime.recordUsage(chunk.(llms.StreamUsageChunk).Usage())
+
+			case llms.StreamRevisionChunk:
+				chunk := chunk.(llms.StreamRevisionChunk)
+
+				text := message.String()
+				cut := len(text) - min(max(chunk.Revises(), 0), len(text))
+				// A cut inside a multi-byte character replaces all of it.
+				for cut > 0 && cut < len(text) && !utf8.RuneStart(text[cut]) {
+					cut--
+				}
+				replaced := text[cut:]
+				message.Reset()
+				message.WriteString(text[:cut])
+				message.WriteString(chunk.Content())
+				if runtime.reviseText != nil {
+					runtime.reviseText(replaced, chunk.Content())
+				}
+				runtime.emitEvent(events.NewAssistantResponseSegmentReplaced(replaced, chunk.Content()))
+
 			case llms.StreamContentChunk:
 				chunk := chunk.(llms.StreamContentChunk)
 
@@ -205,6 +320,9 @@ func (runtime *llm) processStreaming(ctx context.Context,
 					onChunk(chunk.Content())
 				}
 				runtime.emitEvent(events.NewAssistantResponseSegment(chunk.Content()))
+				if runtime.stopGeneration != nil && runtime.stopGeneration() {
+					return &llms.Response{Content: message.String(), ToolCalls: turn.ToolCalls}, nil
+				}
 
 			case llms.StreamToolCallChunk:
 				toolCalls = append(toolCalls, chunk.(llms.StreamToolCallChunk).ToolCall())
@@ -212,10 +330,15 @@ func (runtime *llm) processStreaming(ctx context.Context,
 		}
 
 		for _, toolCall := range toolCalls {
+			if cancelled() {
+				return partial(), nil
+			}
 			toolResponse, err := runtime.callTool(ctx, toolCall)
-			if err != nil {
+			if err != nil && cancelled() {
+				return partial(), nil
+			} else if err != nil {
 				err = fmt.Errorf("failed to call tool: %w", err)
-				span.RecordError(err)
+				errorreport.Record(ctx, span, err, "component", string(ComponentLLM))
 				span.SetStatus(codes.Error, err.Error())
 				return nil, err
 			}
@@ -233,3 +356,44 @@ func (runtime *llm) processStreaming(ctx context.Context,
 		}
 	}
 }
+
+// prompt sends prompt to the client outside of any turn, without tools and
+// history, and returns the response text.
+func (runtime *llm) prompt(ctx context.Context, prompt string, systemPrompt string) (string, error) {
+	if runtime == nil || runtime.client == nil {
+		return "", fmt.Errorf("no LLM configured")
+	}
+
+	switch client := runtime.client.(type) {
+	case LLMWithStream:
+		stream := client.PromptWithStream(ctx, nil,
+			llms.WithTurnsV1(llms.TurnV1{Trigger: triggers.NewUserPromptTrigger(prompt)}),
+			llms.WithSystemPrompt(systemPrompt),
+		)
+		var response strings.Builder
+		for chunk, err := range stream.Chunks(ctx) {
+			if err != nil {
+				return "", err
+			}
+			switch chunk := chunk.(type) {
+			case llms.StreamUsageChunk:
+				runtime.recordUsage(chunk.Usage())
+			case llms.StreamContentChunk:
+				response.WriteString(chunk.Content())
+			}
+		}
+		return response.String(), nil
+
+	case LLMWithPrompt:
+		response, err := client.Prompt(ctx, prompt, llms.WithSystemPrompt(systemPrompt))
+		if err != nil {
+			return "", err
+		} else if len(response) == 0 {
+			return "", nil
+		}
+		return response[len(response)-1].Content, nil
+
+	default:
+		return "", fmt.Errorf("unknown LLM type")
+	}
+}
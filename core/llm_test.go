@@ -0,0 +1,44 @@
+package orchestration
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestStreamingToolLoopStopsAtCancellationPoints(t *testing.T) {
+	var cancelled, secondExecuted atomic.Bool
+	client := &toolCallingLLMStub{toolCalls: []llms.ToolCall{
+		{ID: "call-1", Name: "lookup", Arguments: "{}"},
+		{ID: "call-2", Name: "book", Arguments: "{}"},
+	}}
+	runtime := newLLM()
+	runtime.set(client)
+	runtime.setTools(
+		llms.NewTool("lookup", "cancels the turn while running", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+			cancelled.Store(true)
+			return "found it", nil
+		}),
+		llms.NewTool("book", "must not run after cancellation", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+			secondExecuted.Store(true)
+			return "booked", nil
+		}),
+	)
+
+	response, err := runtime.generate(context.Background(), triggers.NewUserPromptTrigger("book a table"), nil, nil, cancelled.Load)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secondExecuted.Load() {
+		t.Fatalf("expected no tool execution after the turn was cancelled")
+	}
+	if prompts := client.prompts.Load(); prompts != 1 {
+		t.Fatalf("expected no follow-up prompt after cancellation, got %d prompts", prompts)
+	}
+	if response == nil || len(response.ToolCalls) != 1 || response.ToolCalls[0].ID != "call-1" || response.ToolCalls[0].Response != "found it" {
+		t.Fatalf("expected the partial result of the completed tool call, got %+v", response)
+	}
+}
@@ -0,0 +1,144 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+const (
+	envVarApiKeyName = "ANTHROPIC_API_KEY"
+
+	defaultMaxTokens = 1024
+	defaultPrompt    = "You are a helpful assistant, keep the conversation going and answer any questions to the best of your ability. Reply concisely and clearly unless asked to expand on something. If told to not respond, respond with '...'."
+)
+
+type baseClient struct {
+	credentials credentials.Credentials
+
+	model     ChatModel
+	maxTokens int
+
+	systemPrompt string
+}
+
+func newBase(model ChatModel, opts ...ClientOption) (*baseClient, error) {
+	options := &baseClient{
+		credentials: credentials.Default(),
+
+		model:     model,
+		maxTokens: defaultMaxTokens,
+
+		systemPrompt: defaultPrompt,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Custom credentials are resolved per request, only fail early when the
+	// key is expected in the environment.
+	if _, ok := options.credentials.(credentials.Env); ok {
+		if _, err := options.apiKey(context.Background()); err != nil {
+			return nil, fmt.Errorf("anthropic api key neither found (ANTHROPIC_API_KEY) nor provided")
+		}
+	}
+
+	return options, nil
+}
+
+func (c *baseClient) apiKey(ctx context.Context) (string, error) {
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve anthropic api key: %w", err)
+	}
+	return apiKey, nil
+}
+
+func (c *baseClient) Prompt(ctx context.Context, prompt string, opts ...llms.GeneralPromptOption) (*llms.Message, error) {
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Prompt(ctx, apiKey, string(c.model), c.maxTokens, prompt, c.systemPrompt, opts...)
+}
+
+func (c *baseClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
+	stream := PromptWithStream(ctx, "", string(c.model), c.maxTokens, prompt, c.systemPrompt, opts...)
+	stream.resolveAPIKey = c.apiKey
+	return stream
+}
+
+func (c *baseClient) ModelCard() llms.ModelCard {
+	card, ok := ModelCards[c.model]
+	if !ok {
+		return llms.ModelCard{}
+	}
+
+	return card
+}
+
+type ClientOption func(*baseClient)
+
+func WithSystemPrompt(prompt string) ClientOption {
+	return func(c *baseClient) {
+		c.systemPrompt = prompt
+	}
+}
+
+func WithAPIKey(apiKey string) ClientOption {
+	return func(c *baseClient) {
+		c.credentials = credentials.Static{envVarApiKeyName: apiKey}
+	}
+}
+
+// WithCredentials sets the credentials the ANTHROPIC_API_KEY is resolved
+// from on each request, defaults to [credentials.Default].
+func WithCredentials(source credentials.Credentials) ClientOption {
+	return func(c *baseClient) {
+		c.credentials = source
+	}
+}
+
+// WithMaxTokens limits the length of a response, Anthropic requires a limit
+// on every request, defaults to 1024 tokens.
+func WithMaxTokens(maxTokens int) ClientOption {
+	return func(c *baseClient) {
+		c.maxTokens = maxTokens
+	}
+}
+
+type ClaudeSonnet45Client struct{ baseClient }
+
+func NewClaudeSonnet45Client(opts ...ClientOption) (*ClaudeSonnet45Client, error) {
+	base, err := newBase(ModelClaudeSonnet45, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClaudeSonnet45Client{baseClient: *base}, nil
+}
+
+type ClaudeHaiku45Client struct{ baseClient }
+
+func NewClaudeHaiku45Client(opts ...ClientOption) (*ClaudeHaiku45Client, error) {
+	base, err := newBase(ModelClaudeHaiku45, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClaudeHaiku45Client{baseClient: *base}, nil
+}
+
+type ClaudeOpus41Client struct{ baseClient }
+
+func NewClaudeOpus41Client(opts ...ClientOption) (*ClaudeOpus41Client, error) {
+	base, err := newBase(ModelClaudeOpus41, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClaudeOpus41Client{baseClient: *base}, nil
+}
@@ -0,0 +1,3 @@
+// Package anthropic provides an opinionated LLM client for Anthropic's
+// Messages API.
+package anthropic
@@ -0,0 +1,35 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const modelsUrl = "https://api.anthropic.com/v1/models"
+
+// HealthCheck lists the available models, which fails if Anthropic is
+// unreachable or rejects the API key, without spending tokens.
+func (c *baseClient) HealthCheck(ctx context.Context) error {
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsUrl, nil)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	setHeaders(req, apiKey)
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+	}
+	return nil
+}
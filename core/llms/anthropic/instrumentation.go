@@ -0,0 +1,9 @@
+package anthropic
+
+import (
+	"go.opentelemetry.io/otel"
+)
+
+const scopeName = "github.com/koscakluka/ema-core/core/llms/anthropic"
+
+var tracer = otel.Tracer(scopeName)
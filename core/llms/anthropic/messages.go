@@ -0,0 +1,106 @@
+package anthropic
+
+import (
+	"encoding/json"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+type message struct {
+	Role    messageRole    `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+type messageRole string
+
+const (
+	messageRoleUser      messageRole = "user"
+	messageRoleAssistant messageRole = "assistant"
+)
+
+type contentBlock struct {
+	Type contentBlockType `json:"type"`
+
+	Text string `json:"text,omitempty"`
+
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type contentBlockType string
+
+const (
+	contentBlockTypeText       contentBlockType = "text"
+	contentBlockTypeThinking   contentBlockType = "thinking"
+	contentBlockTypeToolUse    contentBlockType = "tool_use"
+	contentBlockTypeToolResult contentBlockType = "tool_result"
+)
+
+func textMessage(role messageRole, text string) message {
+	return message{Role: role, Content: []contentBlock{{Type: contentBlockTypeText, Text: text}}}
+}
+
+// toMessages converts turns to messages, the instructions are not part of
+// them but sent as the system prompt of the request.
+func toMessages(turns []llms.TurnV1) []message {
+	messages := []message{}
+	for _, turn := range turns {
+		if turn.Trigger.String() != "" {
+			messages = append(messages, textMessage(messageRoleUser, turn.Trigger.String()))
+		}
+
+		if len(turn.ToolCalls) > 0 {
+			// Anthropic rejects tool uses that are not followed by their
+			// results, so every call gets one even if the tool returned
+			// nothing.
+			toolUses := message{Role: messageRoleAssistant}
+			toolResults := message{Role: messageRoleUser}
+			for _, toolCall := range turn.ToolCalls {
+				toolUses.Content = append(toolUses.Content, contentBlock{
+					Type:  contentBlockTypeToolUse,
+					ID:    toolCall.ID,
+					Name:  toolCall.Name,
+					Input: toolInput(toolCall.Arguments),
+				})
+				toolResults.Content = append(toolResults.Content, contentBlock{
+					Type:      contentBlockTypeToolResult,
+					ToolUseID: toolCall.ID,
+					Content:   toolCall.Response,
+				})
+			}
+			messages = append(messages, toolUses, toolResults)
+		}
+
+		for _, response := range turn.Responses {
+			if !response.IsDelivered() {
+				continue
+			}
+			content := response.Message
+			if response.IsTyped {
+				content = response.TypedMessage
+			} else if response.IsSpoken {
+				content = response.SpokenResponse
+			}
+			if content == "" {
+				continue
+			}
+
+			messages = append(messages, textMessage(messageRoleAssistant, content))
+		}
+	}
+	return messages
+}
+
+// toolInput returns the arguments of a tool call as the JSON object
+// Anthropic expects, arguments that are not an object become an empty one.
+func toolInput(arguments string) json.RawMessage {
+	var input map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(arguments), &input); err != nil || input == nil {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(arguments)
+}
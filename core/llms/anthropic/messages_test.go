@@ -0,0 +1,84 @@
+package anthropic
+
+import (
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestToMessages_PairsToolUsesWithTheirResults(t *testing.T) {
+	turns := []llms.TurnV1{
+		{
+			Trigger: triggers.NewUserPromptTrigger("what is the weather"),
+			ToolCalls: []llms.ToolCall{
+				{ID: "toolu_1", Name: "lookup_weather", Arguments: `{"city":"Prague"}`, Response: `{"temp":21}`},
+				{ID: "toolu_2", Name: "lookup_time", Arguments: ""},
+			},
+			Responses: []llms.TurnResponseV0{
+				{Message: "It is 21C in Prague.", IsMessageFullyGenerated: true},
+			},
+		},
+	}
+
+	messages := toMessages(turns)
+
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d: %+v", len(messages), messages)
+	}
+
+	if messages[0].Role != messageRoleUser || messages[0].Content[0].Text != "what is the weather" {
+		t.Fatalf("unexpected first message: %+v", messages[0])
+	}
+
+	toolUses := messages[1]
+	if toolUses.Role != messageRoleAssistant || len(toolUses.Content) != 2 {
+		t.Fatalf("unexpected tool use message: %+v", toolUses)
+	}
+	if toolUses.Content[0].Type != contentBlockTypeToolUse || toolUses.Content[0].ID != "toolu_1" || string(toolUses.Content[0].Input) != `{"city":"Prague"}` {
+		t.Fatalf("unexpected tool use: %+v", toolUses.Content[0])
+	}
+	if string(toolUses.Content[1].Input) != "{}" {
+		t.Fatalf("expected empty arguments to be sent as an empty object, got %s", toolUses.Content[1].Input)
+	}
+
+	toolResults := messages[2]
+	if toolResults.Role != messageRoleUser || len(toolResults.Content) != 2 {
+		t.Fatalf("unexpected tool result message: %+v", toolResults)
+	}
+	if toolResults.Content[0].Type != contentBlockTypeToolResult || toolResults.Content[0].ToolUseID != "toolu_1" || toolResults.Content[0].Content != `{"temp":21}` {
+		t.Fatalf("unexpected tool result: %+v", toolResults.Content[0])
+	}
+	if toolResults.Content[1].ToolUseID != "toolu_2" {
+		t.Fatalf("expected a result for every tool use, got %+v", toolResults.Content[1])
+	}
+
+	if messages[3].Role != messageRoleAssistant || messages[3].Content[0].Text != "It is 21C in Prague." {
+		t.Fatalf("unexpected final assistant message: %+v", messages[3])
+	}
+}
+
+func TestToMessages_SendsOnlyTheHeardPartOfInterruptedResponses(t *testing.T) {
+	turns := []llms.TurnV1{
+		{
+			Trigger: triggers.NewUserPromptTrigger("prompt"),
+			Responses: []llms.TurnResponseV0{
+				{
+					Message:                 "It is sunny.",
+					TypedMessage:            "It is sunny. It will rain tomorrow.",
+					SpokenResponse:          "It is sunny.",
+					UnspokenMessage:         " It will rain tomorrow.",
+					IsMessageFullyGenerated: true,
+					IsSpoken:                true,
+					IsInterrupted:           true,
+				},
+			},
+		},
+	}
+
+	messages := toMessages(turns)
+
+	if len(messages) != 2 || messages[1].Role != messageRoleAssistant || messages[1].Content[0].Text != "It is sunny." {
+		t.Fatalf("unexpected messages: %+v", messages)
+	}
+}
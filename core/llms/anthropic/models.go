@@ -0,0 +1,33 @@
+package anthropic
+
+import (
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+type ChatModel llms.ChatModel
+
+const (
+	ModelClaudeSonnet45 ChatModel = "claude-sonnet-4-5"
+	ModelClaudeHaiku45  ChatModel = "claude-haiku-4-5"
+	ModelClaudeOpus41   ChatModel = "claude-opus-4-1"
+)
+
+// ModelCards contain parsed information about the models
+// For more information check: https://docs.anthropic.com/en/docs/about-claude/models
+var ModelCards = map[ChatModel]llms.ModelCard{
+	ModelClaudeSonnet45: {
+		Name:            string(ModelClaudeSonnet45),
+		ProductionReady: true,
+		Capabilities:    llms.Capabilities{ToolCalls: true, Caching: true},
+	},
+	ModelClaudeHaiku45: {
+		Name:            string(ModelClaudeHaiku45),
+		ProductionReady: true,
+		Capabilities:    llms.Capabilities{ToolCalls: true, Caching: true},
+	},
+	ModelClaudeOpus41: {
+		Name:            string(ModelClaudeOpus41),
+		ProductionReady: true,
+		Capabilities:    llms.Capabilities{ToolCalls: true, Caching: true},
+	},
+}
@@ -0,0 +1,157 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+const url = "https://api.anthropic.com/v1/messages"
+
+func Prompt(
+	ctx context.Context,
+	apiKey string,
+	model string,
+	maxTokens int,
+	prompt string,
+	systemPrompt string,
+	opts ...llms.GeneralPromptOption,
+) (*llms.Message, error) {
+	options := llms.GeneralPromptOptions{BaseOptions: llms.BaseOptions{Instructions: systemPrompt}}
+	for _, opt := range opts {
+		opt.ApplyToGeneral(&options)
+	}
+
+	messages := toMessages(options.BaseOptions.TurnsV1)
+	messages = append(messages, textMessage(messageRoleUser, prompt))
+
+	var choice *toolChoice
+	var tools []anthropicTool
+	if options.Tools != nil {
+		choice = toolChoiceAuto
+		if options.ForcedToolsCall {
+			choice = toolChoiceAny
+		}
+
+		tools = toAnthropicTools(options.Tools)
+	}
+
+	reqBody := requestBody{
+		Model:      model,
+		MaxTokens:  maxTokens,
+		System:     options.BaseOptions.Instructions,
+		Messages:   messages,
+		Stream:     false,
+		Tools:      tools,
+		ToolChoice: choice,
+	}
+
+	requestBodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	setHeaders(req, apiKey)
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	var responseBody responseBody
+	if err := json.Unmarshal(bodyBytes, &responseBody); err != nil {
+		return nil, fmt.Errorf("error unmarshalling response body: %w", err)
+	}
+
+	response := llms.Message{Role: llms.MessageRoleAssistant}
+	for _, block := range responseBody.Content {
+		switch block.Type {
+		case contentBlockTypeText:
+			response.Content += block.Text
+		case contentBlockTypeToolUse:
+			response.ToolCalls = append(response.ToolCalls, toToolCall(block.ID, block.Name, string(block.Input)))
+		}
+	}
+
+	return &response, nil
+}
+
+// responseError describes a failed request, with the message Anthropic sent
+// in the error body when there is one.
+func responseError(resp *http.Response) error {
+	var body errorBody
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error.Message == "" {
+		// TODO: Retry depending on status, send back a message to the user
+		// to indicate that something is going on
+		return fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+	}
+	return fmt.Errorf("non-OK HTTP status: %s: %s: %s", resp.Status, body.Error.Type, body.Error.Message)
+}
+
+func toToolCall(id, name, arguments string) llms.ToolCall {
+	return llms.ToolCall{
+		ID:        id,
+		Type:      "function",
+		Name:      name,
+		Arguments: arguments,
+		Function:  llms.ToolCallFunction{Name: name, Arguments: arguments},
+	}
+}
+
+type requestBody struct {
+	Model      string          `json:"model"`
+	MaxTokens  int             `json:"max_tokens"`
+	System     string          `json:"system,omitempty"`
+	Messages   []message       `json:"messages"`
+	Stream     bool            `json:"stream"`
+	ToolChoice *toolChoice     `json:"tool_choice,omitempty"`
+	Tools      []anthropicTool `json:"tools,omitempty"`
+}
+
+type responseBody struct {
+	Content    []contentBlock     `json:"content"`
+	StopReason *string            `json:"stop_reason"`
+	Usage      *responseBodyUsage `json:"usage"`
+}
+
+// responseBodyUsage is the token usage of a response. In streamed responses
+// the input tokens come with the start of the message and the output tokens
+// are accumulated in the message deltas.
+type responseBodyUsage struct {
+	// InputTokens is the number of input tokens that were not read from or
+	// written to the cache.
+	InputTokens int `json:"input_tokens"`
+	// CacheCreationInputTokens is the number of input tokens written to the
+	// cache.
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	// CacheReadInputTokens is the number of input tokens read from the cache.
+	CacheReadInputTokens int `json:"cache_read_input_tokens"`
+	// OutputTokens is the number of output tokens.
+	OutputTokens int `json:"output_tokens"`
+}
+
+type errorBody struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
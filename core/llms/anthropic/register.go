@@ -0,0 +1,33 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "anthropic" LLM of [config], so configs
+// can select it once the package is imported.
+func init() {
+	config.RegisterLLM("anthropic", newConfiguredLLM)
+}
+
+func newConfiguredLLM(_ context.Context, provider config.Provider) (orchestration.LLMWithStream, error) {
+	opts := []ClientOption{}
+	if provider.SystemPrompt != "" {
+		opts = append(opts, WithSystemPrompt(provider.SystemPrompt))
+	}
+
+	switch ChatModel(provider.Model) {
+	case ModelClaudeSonnet45, "":
+		return NewClaudeSonnet45Client(opts...)
+	case ModelClaudeHaiku45:
+		return NewClaudeHaiku45Client(opts...)
+	case ModelClaudeOpus41:
+		return NewClaudeOpus41Client(opts...)
+	default:
+		return nil, fmt.Errorf("unknown anthropic model %q", provider.Model)
+	}
+}
@@ -0,0 +1,406 @@
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const chunkPrefix = "data:"
+
+func PromptWithStream(
+	_ context.Context,
+	apiKey string,
+	model string,
+	maxTokens int,
+	prompt *string,
+	systemPrompt string,
+	opts ...llms.StreamingPromptOption,
+) *Stream {
+	options := llms.StreamingPromptOptions{
+		GeneralPromptOptions: llms.GeneralPromptOptions{
+			BaseOptions: llms.BaseOptions{Instructions: systemPrompt},
+		},
+	}
+	for _, opt := range opts {
+		opt.ApplyToStreaming(&options)
+	}
+
+	messages := toMessages(options.BaseOptions.TurnsV1)
+	if prompt != nil {
+		messages = append(messages, textMessage(messageRoleUser, *prompt))
+	}
+
+	var tools []anthropicTool
+	if options.GeneralPromptOptions.Tools != nil {
+		tools = toAnthropicTools(options.GeneralPromptOptions.Tools)
+	}
+
+	return &Stream{
+		apiKey:    apiKey,
+		model:     model,
+		maxTokens: maxTokens,
+		system:    options.BaseOptions.Instructions,
+		tools:     tools,
+		messages:  messages,
+	}
+}
+
+type Stream struct {
+	apiKey string
+	// resolveAPIKey, when set, resolves the API key when the request is sent.
+	resolveAPIKey func(ctx context.Context) (string, error)
+
+	model     string
+	maxTokens int
+	system    string
+	tools     []anthropicTool
+	messages  []message
+}
+
+func (s *Stream) Chunks(ctx context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		ctx, span := tracer.Start(ctx, "prompt llm stream")
+		defer span.End()
+		span.SetAttributes(attribute.String("request.model", s.model))
+		var toolNames []string
+		for _, tool := range s.tools {
+			toolNames = append(toolNames, tool.Name)
+		}
+		span.SetAttributes(attribute.StringSlice("request.available_tools", toolNames))
+
+		var choice *toolChoice
+		if s.tools != nil {
+			choice = toolChoiceAuto
+		}
+
+		reqBody := requestBody{
+			Model:      s.model,
+			MaxTokens:  s.maxTokens,
+			System:     s.system,
+			Messages:   s.messages,
+			Stream:     true,
+			Tools:      s.tools,
+			ToolChoice: choice,
+		}
+
+		requestBodyBytes, err := json.Marshal(reqBody)
+		if err != nil {
+			err = fmt.Errorf("error marshalling JSON: %w", err)
+			span.RecordError(err)
+			yield(nil, err)
+			return
+		}
+
+		apiKey := s.apiKey
+		if s.resolveAPIKey != nil {
+			if apiKey, err = s.resolveAPIKey(ctx); err != nil {
+				span.RecordError(err)
+				yield(nil, err)
+				return
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBodyBytes))
+		if err != nil {
+			err = fmt.Errorf("error creating HTTP request: %w", err)
+			span.RecordError(err)
+			yield(nil, err)
+			return
+		}
+		setHeaders(req, apiKey)
+
+		span.SetAttributes(attribute.String("request.url", req.URL.String()))
+		requestStart := time.Now()
+		span.AddEvent("request started")
+		resp, err := newHTTPClient().Do(req)
+		if err != nil {
+			err = fmt.Errorf("error sending request: %w", err)
+			span.RecordError(err)
+			yield(nil, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		span.SetAttributes(attribute.Int("response.status_code", resp.StatusCode))
+		if resp.StatusCode != http.StatusOK {
+			err := responseError(resp)
+			span.RecordError(err)
+			yield(nil, err)
+			return
+		}
+
+		readEvents(span, requestStart, resp.Body, yield)
+	}
+}
+
+// readEvents turns the server-sent events of a streamed message into chunks.
+// Tool calls are streamed as partial JSON, they are yielded once their
+// content block is complete.
+func readEvents(span trace.Span, requestStart time.Time, body io.Reader, yield func(llms.StreamChunk, error) bool) {
+	usage := llms.Usage{}
+	var finishReason *string
+	firstTokenTime := time.Time{}
+	receivedToken := func() {
+		if !firstTokenTime.IsZero() {
+			return
+		}
+		firstTokenTime = time.Now()
+		span.SetAttributes(attribute.Float64("response.request_to_first_token_time", firstTokenTime.Sub(requestStart).Seconds()))
+		span.AddEvent("received first chunk")
+	}
+
+	toolUses := map[int]*streamedToolUse{}
+	toolCallNames := []string{}
+	defer func() {
+		span.SetAttributes(attribute.StringSlice("response.tool_calls", toolCallNames))
+	}()
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, chunkPrefix) {
+			// Event names are repeated in the type of the data, so only
+			// data lines are read.
+			continue
+		}
+		chunk := strings.TrimSpace(strings.TrimPrefix(line, chunkPrefix))
+		if len(chunk) == 0 {
+			continue
+		}
+
+		var event streamingEvent
+		if err := json.Unmarshal([]byte(chunk), &event); err != nil {
+			err = fmt.Errorf("error unmarshalling JSON: %w", err)
+			span.RecordError(err)
+			if !yield(nil, err) {
+				return
+			}
+			continue
+		}
+
+		switch event.Type {
+		case streamingEventMessageStart:
+			if event.Message != nil && event.Message.Usage != nil {
+				u := event.Message.Usage
+				usage.InputTokens = u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+				usage.PromptTokens = usage.InputTokens
+				usage.OutputTokens = u.OutputTokens
+				usage.CompletionTokens = u.OutputTokens
+				if u.CacheReadInputTokens > 0 {
+					usage.InputTokensDetails = &llms.InputTokensDetails{CachedTokens: u.CacheReadInputTokens}
+				}
+			}
+
+		case streamingEventContentBlockStart:
+			if event.ContentBlock != nil && event.ContentBlock.Type == contentBlockTypeToolUse {
+				toolUses[event.Index] = &streamedToolUse{id: event.ContentBlock.ID, name: event.ContentBlock.Name}
+			}
+
+		case streamingEventContentBlockDelta:
+			if event.Delta == nil {
+				continue
+			}
+			receivedToken()
+			switch event.Delta.Type {
+			case streamingDeltaText:
+				if !yield(StreamContentChunk{content: event.Delta.Text}, nil) {
+					return
+				}
+			case streamingDeltaThinking:
+				if !yield(StreamReasoningChunk{reasoning: event.Delta.Thinking}, nil) {
+					return
+				}
+			case streamingDeltaInputJSON:
+				if toolUse, ok := toolUses[event.Index]; ok {
+					toolUse.arguments.WriteString(event.Delta.PartialJSON)
+				}
+			}
+
+		case streamingEventContentBlockStop:
+			toolUse, ok := toolUses[event.Index]
+			if !ok {
+				continue
+			}
+			delete(toolUses, event.Index)
+			toolCallNames = append(toolCallNames, toolUse.name)
+
+			arguments := toolUse.arguments.String()
+			if arguments == "" {
+				arguments = "{}"
+			}
+			if !yield(StreamToolCallChunk{toolCall: toToolCall(toolUse.id, toolUse.name, arguments)}, nil) {
+				return
+			}
+
+		case streamingEventMessageDelta:
+			if event.Delta != nil && event.Delta.StopReason != nil {
+				finishReason = event.Delta.StopReason
+			}
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+				usage.CompletionTokens = event.Usage.OutputTokens
+			}
+
+		case streamingEventMessageStop:
+			usage.TotalTokens = usage.InputTokens + usage.OutputTokens
+			if !firstTokenTime.IsZero() {
+				usage.InputProcessingTimes = firstTokenTime.Sub(requestStart).Seconds()
+				usage.PromptTime = usage.InputProcessingTimes
+				usage.CompletionTime = time.Since(firstTokenTime).Seconds()
+				usage.OutputProcessingTime = usage.CompletionTime
+			}
+			usage.TotalTime = time.Since(requestStart).Seconds()
+
+			span.SetAttributes(attribute.Int("usage.input", usage.InputTokens))
+			span.SetAttributes(attribute.Int("usage.prompt", usage.InputTokens))
+			span.SetAttributes(attribute.Int("usage.output", usage.OutputTokens))
+			span.SetAttributes(attribute.Int("usage.completion", usage.OutputTokens))
+			span.SetAttributes(attribute.Int("usage.total", usage.TotalTokens))
+			if usage.InputTokensDetails != nil {
+				span.SetAttributes(attribute.Int("usage.cached", usage.InputTokensDetails.CachedTokens))
+			}
+			span.SetAttributes(attribute.Float64("usage.prompt_time", usage.PromptTime))
+			span.SetAttributes(attribute.Float64("usage.completion_time", usage.CompletionTime))
+			span.SetAttributes(attribute.Float64("usage.total_time", usage.TotalTime))
+
+			yield(StreamUsageChunk{finishReason: finishReason, usage: usage}, nil)
+			return
+
+		case streamingEventError:
+			err := fmt.Errorf("error streaming response")
+			if event.Error != nil {
+				err = fmt.Errorf("error streaming response: %s: %s", event.Error.Type, event.Error.Message)
+			}
+			span.RecordError(err)
+			yield(nil, err)
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		yield(nil, fmt.Errorf("error reading streamed response: %w", err))
+		return
+	}
+}
+
+type streamedToolUse struct {
+	id        string
+	name      string
+	arguments strings.Builder
+}
+
+type streamingEventType string
+
+const (
+	streamingEventMessageStart      streamingEventType = "message_start"
+	streamingEventMessageDelta      streamingEventType = "message_delta"
+	streamingEventMessageStop       streamingEventType = "message_stop"
+	streamingEventContentBlockStart streamingEventType = "content_block_start"
+	streamingEventContentBlockDelta streamingEventType = "content_block_delta"
+	streamingEventContentBlockStop  streamingEventType = "content_block_stop"
+	streamingEventError             streamingEventType = "error"
+)
+
+type streamingDeltaType string
+
+const (
+	streamingDeltaText      streamingDeltaType = "text_delta"
+	streamingDeltaThinking  streamingDeltaType = "thinking_delta"
+	streamingDeltaInputJSON streamingDeltaType = "input_json_delta"
+)
+
+// streamingEvent is the union of the streamed events, only the fields of its
+// type are set.
+type streamingEvent struct {
+	Type  streamingEventType `json:"type"`
+	Index int                `json:"index"`
+
+	Message *struct {
+		Usage *responseBodyUsage `json:"usage"`
+	} `json:"message,omitempty"`
+	ContentBlock *contentBlock      `json:"content_block,omitempty"`
+	Delta        *streamingDelta    `json:"delta,omitempty"`
+	Usage        *responseBodyUsage `json:"usage,omitempty"`
+	Error        *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type streamingDelta struct {
+	Type streamingDeltaType `json:"type"`
+
+	Text        string `json:"text"`
+	Thinking    string `json:"thinking"`
+	PartialJSON string `json:"partial_json"`
+
+	StopReason *string `json:"stop_reason"`
+}
+
+type StreamReasoningChunk struct {
+	finishReason *string
+	reasoning    string
+	channel      string
+}
+
+func (s StreamReasoningChunk) FinishReason() *string {
+	return s.finishReason
+}
+
+func (s StreamReasoningChunk) Reasoning() string {
+	return s.reasoning
+}
+
+func (s StreamReasoningChunk) Channel() string {
+	return s.channel
+}
+
+type StreamContentChunk struct {
+	finishReason *string
+	content      string
+}
+
+func (s StreamContentChunk) FinishReason() *string {
+	return s.finishReason
+}
+
+func (s StreamContentChunk) Content() string {
+	return s.content
+}
+
+type StreamToolCallChunk struct {
+	finishReason *string
+	toolCall     llms.ToolCall
+}
+
+func (s StreamToolCallChunk) FinishReason() *string {
+	return s.finishReason
+}
+
+func (s StreamToolCallChunk) ToolCall() llms.ToolCall {
+	return s.toolCall
+}
+
+type StreamUsageChunk struct {
+	finishReason *string
+	usage        llms.Usage
+}
+
+func (s StreamUsageChunk) FinishReason() *string {
+	return s.finishReason
+}
+
+func (s StreamUsageChunk) Usage() llms.Usage {
+	return s.usage
+}
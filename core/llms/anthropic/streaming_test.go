@@ -0,0 +1,106 @@
+package anthropic
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const toolUseStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"usage":{"input_tokens":20,"cache_read_input_tokens":5,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me check"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"lookup_weather","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\": "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Prague\"}"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":1}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use","stop_sequence":null},"usage":{"output_tokens":12}}
+
+event: message_stop
+data: {"type":"message_stop"}
+`
+
+func TestReadEvents_YieldsContentCompleteToolCallsAndUsage(t *testing.T) {
+	span := trace.SpanFromContext(context.Background())
+
+	var chunks []llms.StreamChunk
+	readEvents(span, time.Now(), strings.NewReader(toolUseStream), func(chunk llms.StreamChunk, err error) bool {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		chunks = append(chunks, chunk)
+		return true
+	})
+
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d: %+v", len(chunks), chunks)
+	}
+
+	if content, ok := chunks[0].(llms.StreamContentChunk); !ok || content.Content() != "Let me check" {
+		t.Fatalf("unexpected content chunk: %+v", chunks[0])
+	}
+
+	toolCall, ok := chunks[1].(llms.StreamToolCallChunk)
+	if !ok {
+		t.Fatalf("expected a tool call chunk, got %+v", chunks[1])
+	}
+	if call := toolCall.ToolCall(); call.ID != "toolu_1" || call.Name != "lookup_weather" || call.Arguments != `{"city": "Prague"}` {
+		t.Fatalf("unexpected tool call: %+v", call)
+	}
+
+	usageChunk, ok := chunks[2].(llms.StreamUsageChunk)
+	if !ok {
+		t.Fatalf("expected a usage chunk, got %+v", chunks[2])
+	}
+	if reason := usageChunk.FinishReason(); reason == nil || *reason != "tool_use" {
+		t.Fatalf("unexpected finish reason: %v", reason)
+	}
+	usage := usageChunk.Usage()
+	if usage.InputTokens != 25 || usage.OutputTokens != 12 || usage.TotalTokens != 37 {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+	if usage.InputTokensDetails == nil || usage.InputTokensDetails.CachedTokens != 5 {
+		t.Fatalf("unexpected input token details: %+v", usage.InputTokensDetails)
+	}
+}
+
+func TestReadEvents_ReportsStreamedErrors(t *testing.T) {
+	span := trace.SpanFromContext(context.Background())
+	body := `event: error
+data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}
+`
+
+	var errs []error
+	readEvents(span, time.Now(), strings.NewReader(body), func(_ llms.StreamChunk, err error) bool {
+		errs = append(errs, err)
+		return true
+	})
+
+	if len(errs) != 1 || errs[0] == nil || !strings.Contains(errs[0].Error(), "overloaded_error") {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
@@ -0,0 +1,13 @@
+package anthropic
+
+import "github.com/koscakluka/ema-core/core/llms"
+
+// tokenCounter approximates the tokenizer of the Claude models, which splits
+// English text into more tokens than most, about 3.5 characters per token.
+var tokenCounter = llms.ApproximateTokenCounter{CharsPerToken: 3.5}
+
+// CountTokens approximates the tokens text takes for the model, see
+// [llms.TokenCounter].
+func (c *baseClient) CountTokens(text string) int {
+	return tokenCounter.CountTokens(text)
+}
@@ -0,0 +1,45 @@
+package anthropic
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+type anthropicTool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema inputSchema `json:"input_schema"`
+}
+
+type parameterBase struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+type inputSchema struct {
+	Type       string                   `json:"type"`
+	Properties map[string]parameterBase `json:"properties"`
+}
+
+func toAnthropicTools(tools []llms.Tool) []anthropicTool {
+	anthropicTools := []anthropicTool{}
+	for _, tool := range tools {
+		anthropicTool := anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: inputSchema{Type: "object", Properties: map[string]parameterBase{}},
+		}
+		copier.Copy(&anthropicTool.InputSchema.Properties, tool.Function.Parameters)
+		anthropicTools = append(anthropicTools, anthropicTool)
+	}
+	return anthropicTools
+}
+
+type toolChoice struct {
+	Type string `json:"type"`
+}
+
+var (
+	toolChoiceAuto = &toolChoice{Type: "auto"}
+	toolChoiceAny  = &toolChoice{Type: "any"}
+)
@@ -0,0 +1,25 @@
+package anthropic
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const apiVersion = "2023-06-01"
+
+// newHTTPClient returns a client that records a span per request and
+// propagates the trace context of the request to Anthropic.
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport,
+		otelhttp.WithSpanNameFormatter(func(operationName string, request *http.Request) string {
+			return operationName + " " + request.URL.Path
+		}),
+	)}
+}
+
+func setHeaders(req *http.Request, apiKey string) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", apiKey)
+	req.Header.Set("Anthropic-Version", apiVersion)
+}
@@ -0,0 +1,202 @@
+// Package contextwindow fits the history of a conversation into the context
+// window of its LLM.
+//
+// A [Policy] approximates the tokens of the turns for the tokenizer of the
+// provider and keeps the newest turns that fit. Older turns are dropped, or
+// with a [Summarizer] replaced by a summary that is extended as the
+// conversation goes on, so long calls keep their earlier context.
+//
+//	policy := contextwindow.NewPolicy(128_000,
+//		contextwindow.WithProvider(contextwindow.ProviderOpenAI),
+//		contextwindow.WithReservedTokens(4_000),
+//		contextwindow.WithSummarizer(contextwindow.LLMSummarizer(smallLLM)),
+//	)
+//	o := orchestration.NewOrchestrator(orchestration.WithContextWindowPolicy(policy))
+package contextwindow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// SummaryTurnID is the ID of the turn holding the summary of the turns that
+// did not fit the window.
+const SummaryTurnID = "context-window-summary"
+
+const summarySource = "summary of the earlier conversation"
+
+// Policy fits histories into the context window of an LLM, see [Policy.Fit].
+// A policy keeps the summary of a single conversation, conversations must
+// not share it.
+type Policy struct {
+	maxTokens      int
+	reservedTokens int
+	counter        llms.TokenCounter
+	summarizer     Summarizer
+
+	mu sync.Mutex
+	// summary folds the oldest turns of the history up to and including the
+	// turn with ID summarizedThrough.
+	summary           string
+	summarizedThrough string
+}
+
+type PolicyOptions struct {
+	provider       string
+	counter        llms.TokenCounter
+	reservedTokens int
+	summarizer     Summarizer
+}
+
+type PolicyOption func(*PolicyOptions)
+
+// WithProvider sets the provider of the LLM, see the Provider constants,
+// whose tokenizer the token counts approximate. Unknown providers are
+// counted at four characters per token.
+func WithProvider(provider string) PolicyOption {
+	return func(o *PolicyOptions) {
+		o.provider = provider
+	}
+}
+
+// WithTokenCounter counts tokens with counter, e.g. the LLM client of the
+// conversation, instead of approximating them for the provider.
+func WithTokenCounter(counter llms.TokenCounter) PolicyOption {
+	return func(o *PolicyOptions) {
+		o.counter = counter
+	}
+}
+
+// WithReservedTokens keeps n tokens of the window free, e.g. for the
+// response and the tool definitions.
+func WithReservedTokens(n int) PolicyOption {
+	return func(o *PolicyOptions) {
+		o.reservedTokens = n
+	}
+}
+
+// WithSummarizer replaces the turns that do not fit the window with a
+// summary written by summarizer, instead of dropping them.
+func WithSummarizer(summarizer Summarizer) PolicyOption {
+	return func(o *PolicyOptions) {
+		o.summarizer = summarizer
+	}
+}
+
+// NewPolicy creates a policy for a context window of maxTokens tokens.
+func NewPolicy(maxTokens int, opts ...PolicyOption) *Policy {
+	options := PolicyOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	counter := options.counter
+	if counter == nil {
+		counter = providerCounters[options.provider]
+	}
+	if counter == nil {
+		counter = llms.ApproximateTokenCounter{}
+	}
+	return &Policy{
+		maxTokens:      maxTokens,
+		reservedTokens: options.reservedTokens,
+		counter:        counter,
+		summarizer:     options.summarizer,
+	}
+}
+
+// Fit returns the newest turns of history that fit the window together with
+// prompts, e.g. the system prompt and the trigger of the turn. The older
+// turns are dropped or, with a summarizer, replaced by a summary turn with
+// [SummaryTurnID].
+//
+// If summarizing fails the older turns are dropped and the error is returned
+// along with the fitted history.
+func (p *Policy) Fit(ctx context.Context, history []llms.TurnV1, prompts ...string) ([]llms.TurnV1, error) {
+	budget := p.maxTokens - p.reservedTokens
+	for _, prompt := range prompts {
+		budget -= p.countText(prompt)
+	}
+	if p.CountTurns(history) <= budget {
+		return history, nil
+	}
+	if p.summarizer == nil {
+		return history[p.firstFitting(history, budget):], nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	summarized := p.summarizedLocked(history)
+	summary := p.summary
+	// A grown summary may push more turns out of the window, which are
+	// folded into it once more.
+	for range 2 {
+		start := max(p.firstFitting(history, budget-p.summaryTokens(summary)), summarized)
+		if start > summarized {
+			var err error
+			summary, err = p.summarizer.Summarize(ctx, summary, history[summarized:start])
+			if err != nil {
+				return history[p.firstFitting(history, budget):], fmt.Errorf("failed to summarize history: %w", err)
+			}
+			summarized = start
+			p.summary, p.summarizedThrough = summary, history[start-1].ID
+		}
+		if summary == "" {
+			return history[start:], nil
+		}
+		if p.summaryTokens(summary)+p.CountTurns(history[start:]) <= budget {
+			return append([]llms.TurnV1{summaryTurn(summary)}, history[start:]...), nil
+		}
+	}
+
+	// The summary alone outgrew the window.
+	return history[p.firstFitting(history, budget):], nil
+}
+
+// summarizedLocked returns how many of the oldest turns of history the
+// summary folds, forgetting the summary if history does not start with them,
+// e.g. once they were evicted.
+func (p *Policy) summarizedLocked(history []llms.TurnV1) int {
+	if p.summarizedThrough != "" {
+		for i, turn := range history {
+			if turn.ID == p.summarizedThrough {
+				return i + 1
+			}
+		}
+	}
+	p.summary, p.summarizedThrough = "", ""
+	return 0
+}
+
+// firstFitting returns the index of the oldest turn of history from which
+// the turns fit budget.
+func (p *Policy) firstFitting(history []llms.TurnV1, budget int) int {
+	start := len(history)
+	for tokens := 0; start > 0; start-- {
+		tokens += p.CountTurns(history[start-1 : start])
+		if tokens > budget {
+			break
+		}
+	}
+	return start
+}
+
+func (p *Policy) summaryTokens(summary string) int {
+	if summary == "" {
+		return 0
+	}
+	return p.CountTurns([]llms.TurnV1{summaryTurn(summary)})
+}
+
+func summaryTurn(summary string) llms.TurnV1 {
+	return llms.TurnV1{
+		ID:          SummaryTurnID,
+		Trigger:     triggers.NewContextTrigger(summarySource, summary),
+		IsFinalised: true,
+	}
+}
@@ -0,0 +1,98 @@
+package contextwindow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// turns creates n turns of about 30 tokens each.
+func turns(n int) []llms.TurnV1 {
+	history := make([]llms.TurnV1, 0, n)
+	for i := range n {
+		history = append(history, llms.TurnV1{
+			ID:        fmt.Sprintf("turn-%d", i),
+			Trigger:   triggers.NewUserPromptTrigger(strings.Repeat("a", 40)),
+			Responses: []llms.TurnResponseV0{{Message: strings.Repeat("b", 40)}},
+		})
+	}
+	return history
+}
+
+func TestPolicyDropsTheOldestTurns(t *testing.T) {
+	policy := NewPolicy(100)
+	history := turns(5)
+
+	fitted, err := policy.Fit(context.Background(), history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fitted) != 3 || fitted[0].ID != "turn-2" {
+		t.Fatalf("expected the newest 3 turns to fit, got %d from %s", len(fitted), fitted[0].ID)
+	}
+	if policy.CountTurns(fitted) > 100 {
+		t.Fatalf("expected fitted turns to fit, got %d tokens", policy.CountTurns(fitted))
+	}
+
+	if fitted, _ := policy.Fit(context.Background(), history, strings.Repeat("s", 100)); len(fitted) != 2 {
+		t.Fatalf("expected the prompt to take the place of a turn, got %d turns", len(fitted))
+	}
+	if fitted, _ := NewPolicy(1000).Fit(context.Background(), history); len(fitted) != 5 {
+		t.Fatalf("expected a fitting history to be kept, got %d turns", len(fitted))
+	}
+}
+
+func TestPolicyExtendsTheSummaryOfDroppedTurns(t *testing.T) {
+	var summarized [][]string
+	policy := NewPolicy(120, WithSummarizer(SummarizerFunc(func(_ context.Context, previous string, turns []llms.TurnV1) (string, error) {
+		ids := []string{}
+		for _, turn := range turns {
+			ids = append(ids, turn.ID)
+		}
+		summarized = append(summarized, ids)
+		return "summary", nil
+	})))
+
+	history := turns(6)
+	fitted, err := policy.Fit(context.Background(), history)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fitted) == 0 || fitted[0].ID != SummaryTurnID || fitted[1].ID != "turn-3" {
+		t.Fatalf("expected the summary to replace the oldest turns, got %+v", fitted)
+	}
+	if policy.CountTurns(fitted) > 120 {
+		t.Fatalf("expected fitted turns to fit, got %d tokens", policy.CountTurns(fitted))
+	}
+
+	if _, err := policy.Fit(context.Background(), append(history, turns(8)[6:]...)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var folded []string
+	for _, ids := range summarized {
+		folded = append(folded, ids...)
+	}
+	if strings.Join(folded, ",") != "turn-0,turn-1,turn-2,turn-3,turn-4" {
+		t.Fatalf("expected each dropped turn to be summarized once, got %v", summarized)
+	}
+}
+
+func TestPolicyDropsTurnsWhenSummarizingFails(t *testing.T) {
+	summarizeErr := errors.New("summarizer unavailable")
+	policy := NewPolicy(100, WithSummarizer(SummarizerFunc(func(context.Context, string, []llms.TurnV1) (string, error) {
+		return "", summarizeErr
+	})))
+
+	fitted, err := policy.Fit(context.Background(), turns(5))
+	if !errors.Is(err, summarizeErr) {
+		t.Fatalf("expected the summarizer error, got %v", err)
+	}
+	if len(fitted) != 3 || fitted[0].ID != "turn-2" {
+		t.Fatalf("expected the oldest turns to be dropped, got %+v", fitted)
+	}
+}
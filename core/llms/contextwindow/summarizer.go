@@ -0,0 +1,96 @@
+package contextwindow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+const defaultSummaryPrompt = `You summarize the earlier part of a conversation between a user and an assistant, so the assistant can continue the conversation without it.
+Keep every fact, request, decision and commitment that may matter later, e.g. names, numbers and what was agreed. Reply with the summary only.`
+
+// Summarizer folds turns dropped from the context window into a summary.
+type Summarizer interface {
+	// Summarize returns the summary of turns, oldest first, continuing
+	// previous, the summary of the turns before them, if any.
+	Summarize(ctx context.Context, previous string, turns []llms.TurnV1) (string, error)
+}
+
+// SummarizerFunc adapts a function to a [Summarizer].
+type SummarizerFunc func(ctx context.Context, previous string, turns []llms.TurnV1) (string, error)
+
+func (f SummarizerFunc) Summarize(ctx context.Context, previous string, turns []llms.TurnV1) (string, error) {
+	return f(ctx, previous, turns)
+}
+
+// StreamingLLM is an LLM client streaming its responses, e.g. those of the
+// openai, groq and anthropic packages.
+type StreamingLLM interface {
+	PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream
+}
+
+type LLMSummarizerOptions struct {
+	systemPrompt string
+}
+
+type LLMSummarizerOption func(*LLMSummarizerOptions)
+
+// WithSummaryPrompt replaces the system prompt summaries are written with.
+func WithSummaryPrompt(systemPrompt string) LLMSummarizerOption {
+	return func(o *LLMSummarizerOptions) {
+		o.systemPrompt = systemPrompt
+	}
+}
+
+// LLMSummarizer summarizes turns with llm, usually a smaller and cheaper
+// model than the one of the conversation.
+func LLMSummarizer(llm StreamingLLM, opts ...LLMSummarizerOption) Summarizer {
+	options := LLMSummarizerOptions{systemPrompt: defaultSummaryPrompt}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return SummarizerFunc(func(ctx context.Context, previous string, turns []llms.TurnV1) (string, error) {
+		var prompt strings.Builder
+		if previous != "" {
+			fmt.Fprintf(&prompt, "Summary of the conversation so far:\n%s\n\nContinuation:\n", previous)
+		}
+		prompt.WriteString(formatTranscript(turns))
+
+		stream := llm.PromptWithStream(ctx, nil,
+			llms.WithTurnsV1(llms.TurnV1{Trigger: triggers.NewUserPromptTrigger(prompt.String())}),
+			llms.WithSystemPrompt(options.systemPrompt),
+		)
+		var summary strings.Builder
+		for chunk, err := range stream.Chunks(ctx) {
+			if err != nil {
+				return "", fmt.Errorf("failed to summarize turns: %w", err)
+			}
+			if chunk, ok := chunk.(llms.StreamContentChunk); ok {
+				summary.WriteString(chunk.Content())
+			}
+		}
+		return strings.TrimSpace(summary.String()), nil
+	})
+}
+
+func formatTranscript(turns []llms.TurnV1) string {
+	var transcript strings.Builder
+	for _, turn := range turns {
+		if turn.Trigger != nil {
+			fmt.Fprintf(&transcript, "User: %s\n", turn.Trigger.String())
+		}
+		for _, toolCall := range turn.ToolCalls {
+			fmt.Fprintf(&transcript, "Assistant called %s: %s\n", toolCall.Name, toolCall.Response)
+		}
+		for _, response := range turn.Responses {
+			if response.Message != "" {
+				fmt.Fprintf(&transcript, "Assistant: %s\n", response.Message)
+			}
+		}
+	}
+	return transcript.String()
+}
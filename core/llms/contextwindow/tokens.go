@@ -0,0 +1,30 @@
+package contextwindow
+
+import (
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// Providers with known token densities, see [WithProvider].
+const (
+	ProviderOpenAI    = "openai"
+	ProviderGroq      = "groq"
+	ProviderAnthropic = "anthropic"
+)
+
+// providerCounters approximate the tokenizers of the providers, like the
+// CountTokens methods of their clients.
+var providerCounters = map[string]llms.TokenCounter{
+	ProviderOpenAI:    llms.ApproximateTokenCounter{CharsPerToken: 4.0},
+	ProviderGroq:      llms.ApproximateTokenCounter{CharsPerToken: 3.8},
+	ProviderAnthropic: llms.ApproximateTokenCounter{CharsPerToken: 3.5},
+}
+
+// countText counts the tokens of text as a message of a prompt.
+func (p *Policy) countText(text string) int {
+	return llms.MessageTokenOverhead + p.counter.CountTokens(text)
+}
+
+// CountTurns counts the tokens turns take in a prompt.
+func (p *Policy) CountTurns(turns []llms.TurnV1) int {
+	return llms.CountTurnTokens(p.counter, turns...)
+}
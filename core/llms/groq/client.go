@@ -3,9 +3,9 @@ package groq
 import (
 	"context"
 	"fmt"
-	"os"
 	"slices"
 
+	"github.com/koscakluka/ema-core/core/credentials"
 	"github.com/koscakluka/ema-core/core/llms"
 )
 
@@ -17,7 +17,7 @@ const (
 )
 
 type Client struct {
-	apiKey string
+	apiKeySource
 
 	model        string
 	tools        []llms.Tool
@@ -32,7 +32,7 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 	}
 
 	return &Client{
-		apiKey:       options.apiKey,
+		apiKeySource: apiKeySource{credentials: options.credentials},
 		model:        options.model,
 		tools:        options.tools,
 		systemPrompt: options.systemPrompt,
@@ -40,7 +40,7 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 }
 
 type ClientOptions struct {
-	apiKey       string
+	credentials  credentials.Credentials
 	model        string
 	tools        []llms.Tool
 	systemPrompt string
@@ -72,16 +72,24 @@ func WithSystemPrompt(prompt string) ClientOption {
 
 func WithAPIKey(apiKey string) ClientOption {
 	return func(c *ClientOptions) {
-		c.apiKey = apiKey
+		c.credentials = credentials.Static{envVarApiKeyName: apiKey}
+	}
+}
+
+// WithCredentials sets the credentials the GROQ_API_KEY is resolved from on
+// each request, defaults to [credentials.Default].
+func WithCredentials(source credentials.Credentials) ClientOption {
+	return func(c *ClientOptions) {
+		c.credentials = source
 	}
 }
 
 func (c *Client) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
-	return Prompt(ctx, c.apiKey, defaultModel, prompt, c.systemPrompt, c.tools, opts...)
+	return c.prompt(ctx, defaultModel, prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *Client) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, defaultModel, prompt, c.systemPrompt, c.tools, opts...)
+	return c.promptWithStream(ctx, defaultModel, prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *Client) ModelCard() llms.ModelCard {
@@ -95,7 +103,7 @@ func (c *Client) ModelCard() llms.ModelCard {
 
 func populateOptions(opts ...ClientOption) (*ClientOptions, error) {
 	options := &ClientOptions{
-		apiKey: os.Getenv(envVarApiKeyName),
+		credentials: credentials.Default(),
 
 		model:        defaultModel,
 		systemPrompt: defaultPrompt,
@@ -105,15 +113,47 @@ func populateOptions(opts ...ClientOption) (*ClientOptions, error) {
 		opt(options)
 	}
 
-	if options.apiKey == "" {
-		return nil, fmt.Errorf("groq api key neither found (GROQ_API_KEY) nor provided")
+	// Custom credentials are resolved per request, only fail early when the
+	// key is expected in the environment.
+	if _, ok := options.credentials.(credentials.Env); ok {
+		if _, err := options.credentials.Credential(context.Background(), envVarApiKeyName); err != nil {
+			return nil, fmt.Errorf("groq api key neither found (GROQ_API_KEY) nor provided")
+		}
 	}
 
 	return options, nil
 }
 
+// apiKeySource resolves the API key for every request, so rotated keys are
+// picked up without rebuilding the client.
+type apiKeySource struct {
+	credentials credentials.Credentials
+}
+
+func (s apiKeySource) apiKey(ctx context.Context) (string, error) {
+	apiKey, err := s.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve groq api key: %w", err)
+	}
+	return apiKey, nil
+}
+
+func (s apiKeySource) prompt(ctx context.Context, model string, prompt string, systemPrompt string, tools []llms.Tool, opts ...llms.PromptOption) ([]llms.Message, error) {
+	apiKey, err := s.apiKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Prompt(ctx, apiKey, model, prompt, systemPrompt, tools, opts...)
+}
+
+func (s apiKeySource) promptWithStream(ctx context.Context, model string, prompt *string, systemPrompt string, tools []llms.Tool, opts ...llms.StreamingPromptOption) llms.Stream {
+	stream := PromptWithStream(ctx, "", model, prompt, systemPrompt, tools, opts...)
+	stream.resolveAPIKey = s.apiKey
+	return stream
+}
+
 type Llmaa3370BVersatileClient struct {
-	apiKey string
+	apiKeySource
 
 	tools        []llms.Tool
 	systemPrompt string
@@ -126,22 +166,22 @@ func NewLlama3370BVersatileClient(opts ...ClientOption) (*Llmaa3370BVersatileCli
 	}
 
 	return &Llmaa3370BVersatileClient{
-		apiKey:       options.apiKey,
+		apiKeySource: apiKeySource{credentials: options.credentials},
 		tools:        options.tools,
 		systemPrompt: options.systemPrompt,
 	}, nil
 }
 
 func (c *Llmaa3370BVersatileClient) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
-	return Prompt(ctx, c.apiKey, string(ModelLlama3370BVersatile), prompt, c.systemPrompt, c.tools, opts...)
+	return c.prompt(ctx, string(ModelLlama3370BVersatile), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *Llmaa3370BVersatileClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, string(ModelLlama3370BVersatile), prompt, c.systemPrompt, c.tools, opts...)
+	return c.promptWithStream(ctx, string(ModelLlama3370BVersatile), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 type Llama318BInstructClient struct {
-	apiKey string
+	apiKeySource
 
 	tools        []llms.Tool
 	systemPrompt string
@@ -154,22 +194,22 @@ func NewLlama318BInstructClient(opts ...ClientOption) (*Llama318BInstructClient,
 	}
 
 	return &Llama318BInstructClient{
-		apiKey:       options.apiKey,
+		apiKeySource: apiKeySource{credentials: options.credentials},
 		tools:        options.tools,
 		systemPrompt: options.systemPrompt,
 	}, nil
 }
 
 func (c *Llama318BInstructClient) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
-	return Prompt(ctx, c.apiKey, string(ModelLlama318BInstant), prompt, c.systemPrompt, c.tools, opts...)
+	return c.prompt(ctx, string(ModelLlama318BInstant), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *Llama318BInstructClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, string(ModelLlama318BInstant), prompt, c.systemPrompt, c.tools, opts...)
+	return c.promptWithStream(ctx, string(ModelLlama318BInstant), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 type GPTOSS20BClient struct {
-	apiKey string
+	apiKeySource
 
 	tools        []llms.Tool
 	systemPrompt string
@@ -182,27 +222,31 @@ func NewGPTOSS20BClient(opts ...ClientOption) (*GPTOSS20BClient, error) {
 	}
 
 	return &GPTOSS20BClient{
-		apiKey:       options.apiKey,
+		apiKeySource: apiKeySource{credentials: options.credentials},
 		tools:        options.tools,
 		systemPrompt: options.systemPrompt,
 	}, nil
 }
 
 func (c *GPTOSS20BClient) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
-	return Prompt(ctx, c.apiKey, string(ModelGPTOSS20B), prompt, c.systemPrompt, c.tools, opts...)
+	return c.prompt(ctx, string(ModelGPTOSS20B), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *GPTOSS20BClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, string(ModelGPTOSS20B), prompt, c.systemPrompt, c.tools, opts...)
+	return c.promptWithStream(ctx, string(ModelGPTOSS20B), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *GPTOSS20BClient) PromptWithStructure(ctx context.Context, prompt string, outputSchema any, opts ...llms.StructuredPromptOption) error {
-	_, err := PromptJSONSchema(ctx, c.apiKey, string(ModelGPTOSS20B), prompt, c.systemPrompt, outputSchema, opts...)
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = PromptJSONSchema(ctx, apiKey, string(ModelGPTOSS20B), prompt, c.systemPrompt, outputSchema, opts...)
 	return err
 }
 
 type GPTOSS120BClient struct {
-	apiKey string
+	apiKeySource
 
 	tools        []llms.Tool
 	systemPrompt string
@@ -215,27 +259,31 @@ func NewGPTOSS120BClient(opts ...ClientOption) (*GPTOSS120BClient, error) {
 	}
 
 	return &GPTOSS120BClient{
-		apiKey:       options.apiKey,
+		apiKeySource: apiKeySource{credentials: options.credentials},
 		tools:        options.tools,
 		systemPrompt: options.systemPrompt,
 	}, nil
 }
 
 func (c *GPTOSS120BClient) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
-	return Prompt(ctx, c.apiKey, string(ModelGPTOSS120B), prompt, c.systemPrompt, c.tools, opts...)
+	return c.prompt(ctx, string(ModelGPTOSS120B), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *GPTOSS120BClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, string(ModelGPTOSS120B), prompt, c.systemPrompt, c.tools, opts...)
+	return c.promptWithStream(ctx, string(ModelGPTOSS120B), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *GPTOSS120BClient) PromptWithStructure(ctx context.Context, prompt string, outputSchema any, opts ...llms.StructuredPromptOption) error {
-	_, err := PromptJSONSchema(ctx, c.apiKey, string(ModelGPTOSS120B), prompt, c.systemPrompt, outputSchema, opts...)
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = PromptJSONSchema(ctx, apiKey, string(ModelGPTOSS120B), prompt, c.systemPrompt, outputSchema, opts...)
 	return err
 }
 
 type Llama4Maverick17BInstructClient struct {
-	apiKey string
+	apiKeySource
 
 	tools        []llms.Tool
 	systemPrompt string
@@ -248,27 +296,31 @@ func NewLlama4Maverick17BInstructClient(opts ...ClientOption) (*Llama4Maverick17
 	}
 
 	return &Llama4Maverick17BInstructClient{
-		apiKey:       options.apiKey,
+		apiKeySource: apiKeySource{credentials: options.credentials},
 		tools:        options.tools,
 		systemPrompt: options.systemPrompt,
 	}, nil
 }
 
 func (c *Llama4Maverick17BInstructClient) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
-	return Prompt(ctx, c.apiKey, string(ModelLlama4Maverick17BInstruct), prompt, c.systemPrompt, c.tools, opts...)
+	return c.prompt(ctx, string(ModelLlama4Maverick17BInstruct), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *Llama4Maverick17BInstructClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, string(ModelLlama4Maverick17BInstruct), prompt, c.systemPrompt, c.tools, opts...)
+	return c.promptWithStream(ctx, string(ModelLlama4Maverick17BInstruct), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *Llama4Maverick17BInstructClient) PromptWithStructure(ctx context.Context, prompt string, outputSchema any, opts ...llms.StructuredPromptOption) error {
-	_, err := PromptJSONSchema(ctx, c.apiKey, string(ModelLlama4Maverick17BInstruct), prompt, c.systemPrompt, outputSchema, opts...)
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = PromptJSONSchema(ctx, apiKey, string(ModelLlama4Maverick17BInstruct), prompt, c.systemPrompt, outputSchema, opts...)
 	return err
 }
 
 type Llama4Scout17BInstructClient struct {
-	apiKey string
+	apiKeySource
 
 	tools        []llms.Tool
 	systemPrompt string
@@ -281,27 +333,31 @@ func NewLlama4Scout17BInstructClient(opts ...ClientOption) (*Llama4Scout17BInstr
 	}
 
 	return &Llama4Scout17BInstructClient{
-		apiKey:       options.apiKey,
+		apiKeySource: apiKeySource{credentials: options.credentials},
 		tools:        options.tools,
 		systemPrompt: options.systemPrompt,
 	}, nil
 }
 
 func (c *Llama4Scout17BInstructClient) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
-	return Prompt(ctx, c.apiKey, string(ModelLlama4Scout17BInstruct), prompt, c.systemPrompt, c.tools, opts...)
+	return c.prompt(ctx, string(ModelLlama4Scout17BInstruct), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *Llama4Scout17BInstructClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, string(ModelLlama4Scout17BInstruct), prompt, c.systemPrompt, c.tools, opts...)
+	return c.promptWithStream(ctx, string(ModelLlama4Scout17BInstruct), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *Llama4Scout17BInstructClient) PromptWithStructure(ctx context.Context, prompt string, outputSchema any, opts ...llms.StructuredPromptOption) error {
-	_, err := PromptJSONSchema(ctx, c.apiKey, string(ModelLlama4Scout17BInstruct), prompt, c.systemPrompt, outputSchema, opts...)
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = PromptJSONSchema(ctx, apiKey, string(ModelLlama4Scout17BInstruct), prompt, c.systemPrompt, outputSchema, opts...)
 	return err
 }
 
 type KimiK2Instruct0905Client struct {
-	apiKey string
+	apiKeySource
 
 	tools        []llms.Tool
 	systemPrompt string
@@ -314,27 +370,31 @@ func NewKimiK2Instruct0905Client(opts ...ClientOption) (*KimiK2Instruct0905Clien
 	}
 
 	return &KimiK2Instruct0905Client{
-		apiKey:       options.apiKey,
+		apiKeySource: apiKeySource{credentials: options.credentials},
 		tools:        options.tools,
 		systemPrompt: options.systemPrompt,
 	}, nil
 }
 
 func (c *KimiK2Instruct0905Client) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
-	return Prompt(ctx, c.apiKey, string(ModelKimiK2Instruct0905), prompt, c.systemPrompt, c.tools, opts...)
+	return c.prompt(ctx, string(ModelKimiK2Instruct0905), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *KimiK2Instruct0905Client) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, string(ModelKimiK2Instruct0905), prompt, c.systemPrompt, c.tools, opts...)
+	return c.promptWithStream(ctx, string(ModelKimiK2Instruct0905), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *KimiK2Instruct0905Client) PromptWithStructure(ctx context.Context, prompt string, outputSchema any, opts ...llms.StructuredPromptOption) error {
-	_, err := PromptJSONSchema(ctx, c.apiKey, string(ModelKimiK2Instruct0905), prompt, c.systemPrompt, outputSchema, opts...)
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = PromptJSONSchema(ctx, apiKey, string(ModelKimiK2Instruct0905), prompt, c.systemPrompt, outputSchema, opts...)
 	return err
 }
 
 type Qwen332BClient struct {
-	apiKey string
+	apiKeySource
 
 	tools        []llms.Tool
 	systemPrompt string
@@ -347,16 +407,16 @@ func NewQwen332BClient(opts ...ClientOption) (*Qwen332BClient, error) {
 	}
 
 	return &Qwen332BClient{
-		apiKey:       options.apiKey,
+		apiKeySource: apiKeySource{credentials: options.credentials},
 		tools:        options.tools,
 		systemPrompt: options.systemPrompt,
 	}, nil
 }
 
 func (c *Qwen332BClient) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
-	return Prompt(ctx, c.apiKey, string(ModelQwen332B), prompt, c.systemPrompt, c.tools, opts...)
+	return c.prompt(ctx, string(ModelQwen332B), prompt, c.systemPrompt, c.tools, opts...)
 }
 
 func (c *Qwen332BClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, string(ModelQwen332B), prompt, c.systemPrompt, c.tools, opts...)
+	return c.promptWithStream(ctx, string(ModelQwen332B), prompt, c.systemPrompt, c.tools, opts...)
 }
@@ -0,0 +1,38 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const modelsUrl = "https://api.groq.com/openai/v1/models"
+
+// HealthCheck lists the available models, which fails if Groq is unreachable
+// or rejects the API key, without spending tokens.
+func (s apiKeySource) HealthCheck(ctx context.Context) error {
+	apiKey, err := s.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsUrl, nil)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	client := &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+	}
+	return nil
+}
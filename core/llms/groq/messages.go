@@ -72,7 +72,7 @@ func toMessages(instructions string, turns []llms.TurnV1) []message {
 			messages = append(messages, responseMsgs...)
 		}
 		for _, response := range turn.Responses {
-			if !response.IsCompleted() {
+			if !response.IsDelivered() {
 				continue
 			}
 			msg := message{Role: messageRoleAssistant}
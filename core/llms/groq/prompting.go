@@ -6,14 +6,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"slices"
 	"strings"
 
 	"github.com/jinzhu/copier"
 	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/logging"
 	"github.com/koscakluka/ema-core/internal/utils"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 const (
@@ -24,7 +25,7 @@ const (
 )
 
 func Prompt(
-	_ context.Context,
+	ctx context.Context,
 	apiKey string,
 	model string,
 	prompt string,
@@ -77,7 +78,7 @@ func Prompt(
 			return nil, fmt.Errorf("error marshalling JSON: %w", err)
 		}
 
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBodyBytes))
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBodyBytes))
 		if err != nil {
 			return nil, fmt.Errorf("error creating HTTP request: %w", err)
 		}
@@ -85,7 +86,11 @@ func Prompt(
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", "Bearer "+apiKey)
 
-		client := &http.Client{}
+		client := &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport,
+			otelhttp.WithSpanNameFormatter(func(operationName string, request *http.Request) string {
+				return operationName + " " + request.URL.Path
+			}),
+		)}
 		resp, err := client.Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("error sending request: %w", err)
@@ -94,7 +99,7 @@ func Prompt(
 		if resp.StatusCode != http.StatusOK {
 			// TODO: Retry depending on status, send back a message to the user
 			// to indicate that something is going on
-			log.Println("Non-OK HTTP status:", resp.Status)
+			logging.Default().Warn("non-OK HTTP status from groq", "status", resp.Status)
 		}
 
 		toolCalls := []toolCall{}
@@ -115,7 +120,7 @@ func Prompt(
 			var responseBody streamingResponseBody
 			err := json.Unmarshal([]byte(chunk), &responseBody)
 			if err != nil {
-				log.Println("Error unmarshalling JSON:", err)
+				logging.Default().Error("failed to unmarshal groq chunk", "error", err)
 				continue
 			}
 			if len(responseBody.Choices) == 0 {
@@ -133,10 +138,10 @@ func Prompt(
 		}
 
 		if err := scanner.Err(); err != nil {
-			log.Println("Error reading streamed response:", err)
+			logging.Default().Error("failed to read groq streamed response", "error", err)
 		}
 		if err := resp.Body.Close(); err != nil {
-			log.Println("Error closing response body:", err)
+			logging.Default().Error("failed to close groq response body", "error", err)
 		}
 
 		messages = append(messages, message{
@@ -169,7 +174,7 @@ func Prompt(
 				if tool.Function.Name == toolCall.Function.Name {
 					resp, err := tool.Execute(toolCall.Function.Arguments)
 					if err != nil {
-						log.Println("Error executing tool:", err)
+						logging.Default().Error("failed to execute tool", "tool", toolCall.Function.Name, "error", err)
 					}
 					messages = append(messages, message{
 						ToolCallID: toolCall.ID,
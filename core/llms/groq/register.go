@@ -0,0 +1,43 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "groq" LLM of [config], so configs can
+// select it once the package is imported.
+func init() {
+	config.RegisterLLM("groq", newConfiguredLLM)
+}
+
+func newConfiguredLLM(_ context.Context, provider config.Provider) (orchestration.LLMWithStream, error) {
+	opts := []ClientOption{}
+	if provider.SystemPrompt != "" {
+		opts = append(opts, WithSystemPrompt(provider.SystemPrompt))
+	}
+
+	switch ChatModel(provider.Model) {
+	case ModelLlama3370BVersatile, "":
+		return NewLlama3370BVersatileClient(opts...)
+	case ModelLlama318BInstant:
+		return NewLlama318BInstructClient(opts...)
+	case ModelGPTOSS20B:
+		return NewGPTOSS20BClient(opts...)
+	case ModelGPTOSS120B:
+		return NewGPTOSS120BClient(opts...)
+	case ModelLlama4Maverick17BInstruct:
+		return NewLlama4Maverick17BInstructClient(opts...)
+	case ModelLlama4Scout17BInstruct:
+		return NewLlama4Scout17BInstructClient(opts...)
+	case ModelKimiK2Instruct0905:
+		return NewKimiK2Instruct0905Client(opts...)
+	case ModelQwen332B:
+		return NewQwen332BClient(opts...)
+	default:
+		return nil, fmt.Errorf("unknown groq model %q", provider.Model)
+	}
+}
@@ -65,6 +65,8 @@ func PromptWithStream(
 
 type Stream struct {
 	apiKey string
+	// resolveAPIKey, when set, resolves the API key when the request is sent.
+	resolveAPIKey func(ctx context.Context) (string, error)
 
 	model    string
 	tools    []Tool
@@ -116,6 +118,15 @@ func (s *Stream) Chunks(ctx context.Context) func(func(llms.StreamChunk, error)
 			return
 		}
 
+		apiKey := s.apiKey
+		if s.resolveAPIKey != nil {
+			if apiKey, err = s.resolveAPIKey(ctx); err != nil {
+				span.RecordError(err)
+				yield(nil, err)
+				return
+			}
+		}
+
 		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBodyBytes))
 		if err != nil {
 			err = fmt.Errorf("error creating HTTP request: %w", err)
@@ -125,7 +136,7 @@ func (s *Stream) Chunks(ctx context.Context) func(func(llms.StreamChunk, error)
 		}
 
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+		req.Header.Set("Authorization", "Bearer "+apiKey)
 
 		span.SetAttributes(attribute.String("request.url", req.URL.String()))
 		client := &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport,
@@ -0,0 +1,13 @@
+package groq
+
+import "github.com/koscakluka/ema-core/core/llms"
+
+// tokenCounter approximates the tokenizers of the open models Groq serves,
+// about 3.8 characters per token of English text.
+var tokenCounter = llms.ApproximateTokenCounter{CharsPerToken: 3.8}
+
+// CountTokens approximates the tokens text takes for the model, see
+// [llms.TokenCounter]. It is promoted to every client of the package.
+func (apiKeySource) CountTokens(text string) int {
+	return tokenCounter.CountTokens(text)
+}
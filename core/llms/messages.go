@@ -1,6 +1,9 @@
 package llms
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Message is a single message in a conversation, but actually it represents a
 // response from an LLM. It is an alias for Response for backwards compatibility.
@@ -48,11 +51,23 @@ type Turn struct {
 	ToolCallID string
 }
 
+// Input modalities of user triggers.
+const (
+	InputModalitySpeech = "speech"
+	InputModalityText   = "text"
+)
+
 type TurnV1 struct {
 	ID string
 	// Trigger is what initiated the turn, e.g. a user message, notification,
 	// completed tool call, etc.
 	Trigger TriggerV0
+	// SpeakerID identifies the user whose trigger started the turn in
+	// conversations with several users, it is empty if unknown.
+	SpeakerID string
+	// InputModality is how the user gave the trigger, see the InputModality
+	// constants, it is empty for triggers not coming from the user.
+	InputModality string
 
 	// Responses is a list of responses that the assistant has generated for
 	// the turn. The assistant may generate multiple responses for a single
@@ -69,6 +84,25 @@ type TurnV1 struct {
 	// assistant has generated a response and the assistant has finished
 	// generating responses for the turn.
 	IsFinalised bool
+
+	// Latency is where the time of the turn was spent, it is set once the
+	// turn is finalised.
+	Latency LatencyReport
+	// Outcome is how the turn went, if it was labeled.
+	Outcome *OutcomeV0
+}
+
+// LatencyReport breaks down the latency of a turn. QueueWait is how long the
+// trigger waited before the turn started, all other stages are measured from
+// the start of the turn. A zero duration means the stage did not happen, e.g.
+// there was no audio to play.
+type LatencyReport struct {
+	QueueWait     time.Duration
+	LLMFirstToken time.Duration
+	LLMTotal      time.Duration
+	TTSFirstAudio time.Duration
+	PlaybackStart time.Duration
+	PlaybackEnd   time.Duration
 }
 
 func (t *TurnV1) IsCancelled() bool {
@@ -96,18 +130,30 @@ type TurnResponseV0 struct {
 	Message        string
 	TypedMessage   string
 	SpokenResponse string
+	// UnspokenMessage is the part of the response the user never heard
+	// because its playback was interrupted, Message then keeps only the part
+	// confirmed as played.
+	UnspokenMessage string
 
 	IsMessageFullyGenerated bool
 	IsTyped                 bool
 	IsSpoken                bool
+	// IsInterrupted marks responses whose playback was interrupted.
+	IsInterrupted bool
 }
 
 func (g *TurnResponseV0) IsCompleted() bool {
-	return g.IsMessageFullyGenerated &&
+	return g.IsMessageFullyGenerated && !g.IsInterrupted &&
 		(!g.IsTyped || len(g.Message) == len(g.TypedMessage)) &&
 		(!g.IsSpoken || len(g.Message) == len(g.SpokenResponse))
 }
 
+// IsDelivered reports whether the response reached the user, in full or, if
+// it was interrupted, the part heard before that.
+func (g *TurnResponseV0) IsDelivered() bool {
+	return g.IsCompleted() || (g.IsInterrupted && g.Message != "")
+}
+
 func (g *TurnResponseV0) IsFullyTyped() bool {
 	return g.IsMessageFullyGenerated && (!g.IsTyped || len(g.Message) == len(g.TypedMessage))
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/koscakluka/ema-core/core/credentials"
 	"github.com/koscakluka/ema-core/core/llms"
 )
 
@@ -17,9 +18,9 @@ const (
 )
 
 type baseClient[T any] struct {
-	apiKey    string
-	orgId     string
-	projectId string
+	credentials credentials.Credentials
+	orgId       string
+	projectId   string
 
 	model        ChatModel
 	modelVersion T
@@ -29,9 +30,9 @@ type baseClient[T any] struct {
 
 func newBase[T any](model ChatModel, defaultModelVersion T, opts ...BaseOption[T]) (*baseClient[T], error) {
 	options := &baseClient[T]{
-		apiKey:    os.Getenv(envVarApiKeyName),
-		orgId:     os.Getenv(envVarOrgIdName),
-		projectId: os.Getenv(envVarProjectIdName),
+		credentials: credentials.Default(),
+		orgId:       os.Getenv(envVarOrgIdName),
+		projectId:   os.Getenv(envVarProjectIdName),
 
 		model:        model,
 		modelVersion: defaultModelVersion,
@@ -43,13 +44,39 @@ func newBase[T any](model ChatModel, defaultModelVersion T, opts ...BaseOption[T
 		opt(options)
 	}
 
-	if options.apiKey == "" {
-		return nil, fmt.Errorf("openai api key neither found (OPENAI_API_KEY) nor provided")
+	// Custom credentials are resolved per request, only fail early when the
+	// key is expected in the environment.
+	if _, ok := options.credentials.(credentials.Env); ok {
+		if _, err := options.apiKey(context.Background()); err != nil {
+			return nil, fmt.Errorf("openai api key neither found (OPENAI_API_KEY) nor provided")
+		}
 	}
 
 	return options, nil
 }
 
+func (c *baseClient[T]) apiKey(ctx context.Context) (string, error) {
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve openai api key: %w", err)
+	}
+	return apiKey, nil
+}
+
+func (c *baseClient[T]) prompt(ctx context.Context, model string, prompt string, opts ...llms.GeneralPromptOption) (*llms.Message, error) {
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Prompt(ctx, apiKey, model, prompt, c.systemPrompt, opts...)
+}
+
+func (c *baseClient[T]) promptWithStream(ctx context.Context, model string, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
+	stream := PromptWithStream(ctx, "", model, prompt, c.systemPrompt, opts...)
+	stream.resolveAPIKey = c.apiKey
+	return stream
+}
+
 type BaseOption[T any] func(*baseClient[T])
 
 func WithSystemPrompt[T any](prompt string) BaseOption[T] {
@@ -60,7 +87,15 @@ func WithSystemPrompt[T any](prompt string) BaseOption[T] {
 
 func WithAPIKey[T any](apiKey string) BaseOption[T] {
 	return func(c *baseClient[T]) {
-		c.apiKey = apiKey
+		c.credentials = credentials.Static{envVarApiKeyName: apiKey}
+	}
+}
+
+// WithCredentials sets the credentials the OPENAI_API_KEY is resolved from
+// on each request, defaults to [credentials.Default].
+func WithCredentials[T any](source credentials.Credentials) BaseOption[T] {
+	return func(c *baseClient[T]) {
+		c.credentials = source
 	}
 }
 
@@ -94,11 +129,11 @@ func NewGPT4oClient(opts ...BaseOption[GPT4oVersion]) (*GPT4oClient, error) {
 }
 
 func (c *GPT4oClient) Prompt(ctx context.Context, prompt string, opts ...llms.GeneralPromptOption) (*llms.Message, error) {
-	return Prompt(ctx, c.apiKey, buildModelString(c.model, string(c.modelVersion)), prompt, c.systemPrompt, opts...)
+	return c.prompt(ctx, buildModelString(c.model, string(c.modelVersion)), prompt, opts...)
 }
 
 func (c *GPT4oClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, buildModelString(c.model, string(c.modelVersion)), prompt, c.systemPrompt, opts...)
+	return c.promptWithStream(ctx, buildModelString(c.model, string(c.modelVersion)), prompt, opts...)
 }
 
 type GPT41Client struct{ baseClient[GPT41Version] }
@@ -113,11 +148,11 @@ func NewGPT41Client(opts ...BaseOption[GPT41Version]) (*GPT41Client, error) {
 }
 
 func (c *GPT41Client) Prompt(ctx context.Context, prompt string, opts ...llms.GeneralPromptOption) (*llms.Message, error) {
-	return Prompt(ctx, c.apiKey, buildModelString(c.model, string(c.modelVersion)), prompt, c.systemPrompt, opts...)
+	return c.prompt(ctx, buildModelString(c.model, string(c.modelVersion)), prompt, opts...)
 }
 
 func (c *GPT41Client) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, buildModelString(c.model, string(c.modelVersion)), prompt, c.systemPrompt, opts...)
+	return c.promptWithStream(ctx, buildModelString(c.model, string(c.modelVersion)), prompt, opts...)
 }
 
 type GPT5NanoClient struct{ baseClient[GPT5NanoVersion] }
@@ -132,9 +167,9 @@ func NewGPT5NanoClient(opts ...BaseOption[GPT5NanoVersion]) (*GPT5NanoClient, er
 }
 
 func (c *GPT5NanoClient) Prompt(ctx context.Context, prompt string, opts ...llms.GeneralPromptOption) (*llms.Message, error) {
-	return Prompt(ctx, c.apiKey, buildModelString(c.model, string(c.modelVersion)), prompt, c.systemPrompt, opts...)
+	return c.prompt(ctx, buildModelString(c.model, string(c.modelVersion)), prompt, opts...)
 }
 
 func (c *GPT5NanoClient) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
-	return PromptWithStream(ctx, c.apiKey, buildModelString(c.model, string(c.modelVersion)), prompt, c.systemPrompt, opts...)
+	return c.promptWithStream(ctx, buildModelString(c.model, string(c.modelVersion)), prompt, opts...)
 }
@@ -0,0 +1,35 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const modelsUrl = "https://api.openai.com/v1/models"
+
+// HealthCheck lists the available models, which fails if OpenAI is
+// unreachable or rejects the API key, without spending tokens.
+func (c *baseClient[T]) HealthCheck(ctx context.Context) error {
+	apiKey, err := c.apiKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsUrl, nil)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+	}
+	return nil
+}
@@ -69,7 +69,7 @@ func toOpenAIMessages(instructions string, turns []llms.TurnV1) []openAIMessage
 			}
 		}
 		for _, response := range turn.Responses {
-			if !response.IsCompleted() {
+			if !response.IsDelivered() {
 				continue
 			}
 			msg := openAIMessage{
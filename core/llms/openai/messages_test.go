@@ -67,3 +67,28 @@ func TestToOpenAIMessages_DoesNotTruncateHistoryAfterToolCalls(t *testing.T) {
 		t.Fatalf("unexpected final assistant message: %+v", messages[5])
 	}
 }
+
+func TestToOpenAIMessages_SendsOnlyTheHeardPartOfInterruptedResponses(t *testing.T) {
+	turns := []llms.TurnV1{
+		{
+			Trigger: triggers.NewUserPromptTrigger("prompt"),
+			Responses: []llms.TurnResponseV0{
+				{
+					Message:                 "It is sunny.",
+					TypedMessage:            "It is sunny. It will rain tomorrow.",
+					SpokenResponse:          "It is sunny.",
+					UnspokenMessage:         " It will rain tomorrow.",
+					IsMessageFullyGenerated: true,
+					IsSpoken:                true,
+					IsInterrupted:           true,
+				},
+			},
+		},
+	}
+
+	messages := toOpenAIMessages("", turns)
+
+	if len(messages) != 2 || messages[1].Role != messageRoleAssistant || messages[1].Content != "It is sunny." {
+		t.Fatalf("unexpected messages: %+v", messages)
+	}
+}
@@ -13,7 +13,7 @@ import (
 )
 
 func Prompt(
-	_ context.Context,
+	ctx context.Context,
 	apiKey string,
 	model string,
 	prompt string,
@@ -57,7 +57,7 @@ func Prompt(
 		return nil, fmt.Errorf("error marshalling JSON: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("error creating HTTP request: %w", err)
 	}
@@ -66,7 +66,7 @@ func Prompt(
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 	// TODO: Add org and project headers
 
-	client := &http.Client{}
+	client := newHTTPClient()
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %w", err)
@@ -0,0 +1,35 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "openai" LLM of [config], so configs can
+// select it once the package is imported.
+func init() {
+	config.RegisterLLM("openai", newConfiguredLLM)
+}
+
+func newConfiguredLLM(_ context.Context, provider config.Provider) (orchestration.LLMWithStream, error) {
+	switch ChatModel(provider.Model) {
+	case ModelGPT4o:
+		return NewGPT4oClient(configuredOptions[GPT4oVersion](provider)...)
+	case ModelGPT41, "":
+		return NewGPT41Client(configuredOptions[GPT41Version](provider)...)
+	case ModelGPT5Nano:
+		return NewGPT5NanoClient(configuredOptions[GPT5NanoVersion](provider)...)
+	default:
+		return nil, fmt.Errorf("unknown openai model %q", provider.Model)
+	}
+}
+
+func configuredOptions[T any](provider config.Provider) []BaseOption[T] {
+	if provider.SystemPrompt == "" {
+		return nil
+	}
+	return []BaseOption[T]{WithSystemPrompt[T](provider.SystemPrompt)}
+}
@@ -63,6 +63,8 @@ func PromptWithStream(
 
 type Stream struct {
 	apiKey string
+	// resolveAPIKey, when set, resolves the API key when the request is sent.
+	resolveAPIKey func(ctx context.Context) (string, error)
 
 	model    string
 	tools    []openAITool
@@ -98,6 +100,14 @@ func (s *Stream) Chunks(ctx context.Context) func(func(llms.StreamChunk, error)
 			return
 		}
 
+		apiKey := s.apiKey
+		if s.resolveAPIKey != nil {
+			if apiKey, err = s.resolveAPIKey(ctx); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+
 		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBodyBytes))
 		if err != nil {
 			yield(nil, fmt.Errorf("error creating HTTP request: %w", err))
@@ -105,10 +115,10 @@ func (s *Stream) Chunks(ctx context.Context) func(func(llms.StreamChunk, error)
 		}
 
 		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+		req.Header.Set("Authorization", "Bearer "+apiKey)
 		// TODO: Add org and project headers
 
-		client := &http.Client{}
+		client := newHTTPClient()
 		resp, err := client.Do(req)
 		if err != nil {
 			yield(nil, fmt.Errorf("error sending request: %w", err))
@@ -0,0 +1,13 @@
+package openai
+
+import "github.com/koscakluka/ema-core/core/llms"
+
+// tokenCounter approximates the tokenizer of the models, about four
+// characters per token of English text.
+var tokenCounter = llms.ApproximateTokenCounter{CharsPerToken: 4.0}
+
+// CountTokens approximates the tokens text takes for the model, see
+// [llms.TokenCounter].
+func (c *baseClient[T]) CountTokens(text string) int {
+	return tokenCounter.CountTokens(text)
+}
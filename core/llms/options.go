@@ -136,6 +136,22 @@ func WithSystemPrompt(prompt string) PromptOption {
 	}
 }
 
+// WithSystemPromptAddition is a PromptOption that appends addition to the
+// system prompt, the one the client was configured with or the one set with
+// [WithSystemPrompt] before it.
+func WithSystemPromptAddition(addition string) PromptOption {
+	return func(opts *PromptOptions) {
+		if addition == "" {
+			return
+		}
+		prompt := addition
+		if opts.Instructions != "" {
+			prompt = opts.Instructions + "\n\n" + addition
+		}
+		WithSystemPrompt(prompt)(opts)
+	}
+}
+
 // WithMessages is a PromptOption that adds passed messages to the prompt.
 // Repeating this option will sequentially add more messages.
 //
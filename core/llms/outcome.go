@@ -0,0 +1,19 @@
+package llms
+
+// Common outcome labels, applications may use their own.
+const (
+	OutcomeResolved  = "resolved"
+	OutcomeEscalated = "escalated"
+	OutcomeAbandoned = "abandoned"
+)
+
+// OutcomeV0 labels how a turn or a conversation went, for quality
+// monitoring.
+type OutcomeV0 struct {
+	// Label is e.g. [OutcomeResolved].
+	Label string `json:"label"`
+	Notes string `json:"notes,omitempty"`
+	// LabeledBy is who attached the label, e.g. "agent" or the name of a
+	// classifier.
+	LabeledBy string `json:"labeled_by,omitempty"`
+}
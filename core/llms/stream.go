@@ -31,6 +31,16 @@ type StreamContentChunk interface {
 	Content() string
 }
 
+// StreamRevisionChunk revises content streamed before, e.g. after
+// speculative decoding or a guardrail rewrite: Content replaces the last
+// Revises bytes of the content streamed so far. A cut inside a multi-byte
+// character replaces the whole character.
+type StreamRevisionChunk interface {
+	StreamChunk
+	Revises() int
+	Content() string
+}
+
 type StreamToolCallChunk interface {
 	StreamChunk
 	ToolCall() ToolCall
@@ -0,0 +1,54 @@
+package llms
+
+import "math"
+
+// MessageTokenOverhead approximates the tokens each message of a prompt
+// costs on top of its content, e.g. for its role.
+const MessageTokenOverhead = 4
+
+const defaultCharsPerToken = 4.0
+
+// TokenCounter counts the tokens text takes for the tokenizer of a model,
+// e.g. to check a prompt fits the context window before it is sent.
+type TokenCounter interface {
+	CountTokens(text string) int
+}
+
+// ApproximateTokenCounter approximates token counts from the length of the
+// text, for models without a counter of their own.
+type ApproximateTokenCounter struct {
+	// CharsPerToken is the average number of characters per token, defaults
+	// to 4, typical of English text.
+	CharsPerToken float64
+}
+
+func (c ApproximateTokenCounter) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	charsPerToken := c.CharsPerToken
+	if charsPerToken <= 0 {
+		charsPerToken = defaultCharsPerToken
+	}
+	return int(math.Ceil(float64(len(text)) / charsPerToken))
+}
+
+// CountTurnTokens counts the tokens turns take in a prompt with counter,
+// including the overhead of their messages.
+func CountTurnTokens(counter TokenCounter, turns ...TurnV1) int {
+	tokens := 0
+	for _, turn := range turns {
+		if turn.Trigger != nil {
+			tokens += MessageTokenOverhead + counter.CountTokens(turn.Trigger.String())
+		}
+		for _, toolCall := range turn.ToolCalls {
+			tokens += 2*MessageTokenOverhead + counter.CountTokens(toolCall.Name) + counter.CountTokens(toolCall.Arguments) + counter.CountTokens(toolCall.Response)
+		}
+		for _, response := range turn.Responses {
+			if response.Message != "" {
+				tokens += MessageTokenOverhead + counter.CountTokens(response.Message)
+			}
+		}
+	}
+	return tokens
+}
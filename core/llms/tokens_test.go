@@ -0,0 +1,23 @@
+package llms
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountTurnTokensIncludesMessageOverhead(t *testing.T) {
+	counter := ApproximateTokenCounter{}
+	if tokens := counter.CountTokens(strings.Repeat("a", 9)); tokens != 3 {
+		t.Fatalf("expected partial tokens to count as whole ones, got %d", tokens)
+	}
+
+	turn := TurnV1{
+		ToolCalls: []ToolCall{{Name: "lookup", Arguments: "{}", Response: strings.Repeat("r", 8)}},
+		Responses: []TurnResponseV0{{Message: strings.Repeat("m", 8)}, {}},
+	}
+	// Tool call: two messages, 2 + 1 + 2 tokens. Response: one message, 2
+	// tokens, the empty response is not sent.
+	if tokens := CountTurnTokens(counter, turn); tokens != 3*MessageTokenOverhead+7 {
+		t.Fatalf("unexpected turn tokens %d", tokens)
+	}
+}
@@ -1,8 +1,10 @@
 package llms
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 type Tool struct {
@@ -13,16 +15,111 @@ type Tool struct {
 		Parameters  parameters[ParameterBase]
 	}
 	Execute func(parameters string) (string, error)
+	// ExecuteStreaming is set for streaming tools, see [NewStreamingTool].
+	// It is used in place of Execute when set.
+	ExecuteStreaming func(ctx context.Context, parameters string, progress ProgressFunc) (string, error)
+
+	// Timeout limits each execution of the tool, zero does not limit it, see
+	// [WithTimeout].
+	Timeout time.Duration
+	// Retries is how many times a failed execution is retried, see
+	// [WithRetries].
+	Retries int
+	// Idempotent marks the tool as safe to execute again, failed executions
+	// are only retried for idempotent tools, see [WithIdempotent].
+	Idempotent bool
+	// RetryBackoff is the wait before the first retry, it doubles with each
+	// following retry.
+	RetryBackoff time.Duration
+	// ErrorPolicy is what happens once the tool fails for good, see
+	// [WithErrorPolicy].
+	ErrorPolicy ToolErrorPolicy
+	// RequiresApproval holds calls of the tool until they are approved, see
+	// [WithApprovalRequired].
+	RequiresApproval bool
+}
+
+// ToolErrorPolicy is what happens to the turn when a tool call fails, after
+// any retries.
+type ToolErrorPolicy int
+
+const (
+	// ToolErrorFailTurn fails the turn, it is the default.
+	ToolErrorFailTurn ToolErrorPolicy = iota
+	// ToolErrorReportToLLM passes the error to the LLM as the response of the
+	// call, so it can recover, e.g. by trying other arguments or telling the
+	// user.
+	ToolErrorReportToLLM
+	// ToolErrorSkip gives the failed call an empty response, the turn goes on
+	// as if the tool found nothing.
+	ToolErrorSkip
+)
+
+// ToolOption configures how a tool is executed, see [NewTool].
+type ToolOption func(*Tool)
+
+// WithTimeout limits each execution of the tool to timeout, an execution
+// that times out fails with [context.DeadlineExceeded]. Streaming tools
+// get the deadline with their context. Execute functions of other tools have
+// no context to be cancelled with, so they are abandoned rather than
+// cancelled: they keep running in the background, side effects included,
+// and their response is dropped.
+func WithTimeout(timeout time.Duration) ToolOption {
+	return func(t *Tool) {
+		t.Timeout = timeout
+	}
+}
+
+// WithRetries retries failed executions of the tool up to retries times,
+// waiting backoff before the first retry and twice as long before each
+// following one. Only tools marked with [WithIdempotent] are retried, as a
+// failed or timed out execution may have had its side effects, or may still
+// be running, see [WithTimeout].
+func WithRetries(retries int, backoff time.Duration) ToolOption {
+	return func(t *Tool) {
+		t.Retries = retries
+		t.RetryBackoff = backoff
+	}
+}
+
+// WithIdempotent marks the tool as safe to execute again with the same
+// arguments, e.g. a lookup, so its failed executions can be retried, see
+// [WithRetries].
+func WithIdempotent() ToolOption {
+	return func(t *Tool) {
+		t.Idempotent = true
+	}
+}
+
+// WithErrorPolicy sets what happens to the turn once the tool fails for
+// good, defaults to [ToolErrorFailTurn].
+func WithErrorPolicy(policy ToolErrorPolicy) ToolOption {
+	return func(t *Tool) {
+		t.ErrorPolicy = policy
+	}
+}
+
+// WithApprovalRequired marks the tool as sensitive, e.g. a destructive
+// action, its calls are only executed once the approval handler of the
+// orchestrator approves them.
+func WithApprovalRequired() ToolOption {
+	return func(t *Tool) {
+		t.RequiresApproval = true
+	}
 }
 
+// ProgressFunc reports the status of a streaming tool while it runs, e.g.
+// "Searched 3 of 10 stores".
+type ProgressFunc func(status string)
+
 type parameters[T ParameterBase] map[string]T
 type ParameterBase struct {
 	Type        string
 	Description string
 }
 
-func NewTool[T any](name string, description string, params parameters[ParameterBase], execute func(T) (string, error)) Tool {
-	return Tool{
+func NewTool[T any](name string, description string, params parameters[ParameterBase], execute func(T) (string, error), opts ...ToolOption) Tool {
+	tool := Tool{
 		Type: "function",
 		Function: struct {
 			Name        string
@@ -41,4 +138,48 @@ func NewTool[T any](name string, description string, params parameters[Parameter
 			return execute(unmarshalledParameters)
 		},
 	}
+	for _, opt := range opts {
+		opt(&tool)
+	}
+	return tool
+}
+
+// NewStreamingTool creates a streaming tool, for long-running tools like
+// database queries or web searches. Its execute function gets the context of
+// the turn and reports its status with progress while it runs, which the
+// orchestrator passes on as tool call progress events.
+//
+// Execute runs it with a background context and without reporting progress.
+func NewStreamingTool[T any](name string, description string, params parameters[ParameterBase], execute func(ctx context.Context, params T, progress ProgressFunc) (string, error), opts ...ToolOption) Tool {
+	executeStreaming := func(ctx context.Context, parameters string, progress ProgressFunc) (string, error) {
+		var unmarshalledParameters T
+		if err := json.Unmarshal([]byte(parameters), &unmarshalledParameters); err != nil {
+			return "Invalid parameters format", fmt.Errorf("error unmarshalling JSON: %w", err)
+		}
+		if progress == nil {
+			progress = func(string) {}
+		}
+		return execute(ctx, unmarshalledParameters, progress)
+	}
+
+	tool := Tool{
+		Type: "function",
+		Function: struct {
+			Name        string
+			Description string
+			Parameters  parameters[ParameterBase]
+		}{
+			Name:        name,
+			Description: description,
+			Parameters:  params,
+		},
+		Execute: func(parameters string) (string, error) {
+			return executeStreaming(context.Background(), parameters, nil)
+		},
+		ExecuteStreaming: executeStreaming,
+	}
+	for _, opt := range opts {
+		opt(&tool)
+	}
+	return tool
 }
@@ -0,0 +1,64 @@
+// Package logging defines the structured logger used across ema packages.
+//
+// Any *slog.Logger satisfies [Logger], so logs can be routed and leveled with
+// the standard library handlers:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil)).With("session_id", id)
+//	o := orchestration.NewOrchestrator(orchestration.WithLogger(logger))
+package logging
+
+import "log/slog"
+
+// Logger is a leveled, structured logger. The methods match [slog.Logger],
+// args are alternating keys and values.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Default returns the logger used when none is configured. It forwards every
+// call to [slog.Default], so [slog.SetDefault] also reroutes ema logs.
+func Default() Logger { return defaultLogger{} }
+
+type defaultLogger struct{}
+
+func (defaultLogger) Debug(msg string, args ...any) { slog.Default().Debug(msg, args...) }
+func (defaultLogger) Info(msg string, args ...any)  { slog.Default().Info(msg, args...) }
+func (defaultLogger) Warn(msg string, args ...any)  { slog.Default().Warn(msg, args...) }
+func (defaultLogger) Error(msg string, args ...any) { slog.Default().Error(msg, args...) }
+
+// Discard returns a logger that drops everything.
+func Discard() Logger { return slog.New(slog.DiscardHandler) }
+
+// With returns a logger that adds args to every log, e.g. to correlate logs
+// by session or turn ID. The logger's own With is used when it has one.
+func With(logger Logger, args ...any) Logger {
+	if logger == nil {
+		logger = Default()
+	}
+	if len(args) == 0 {
+		return logger
+	}
+	if withLogger, ok := logger.(interface {
+		With(args ...any) *slog.Logger
+	}); ok {
+		return withLogger.With(args...)
+	}
+	return withArgs{logger: logger, args: args}
+}
+
+type withArgs struct {
+	logger Logger
+	args   []any
+}
+
+func (l withArgs) Debug(msg string, args ...any) { l.logger.Debug(msg, l.join(args)...) }
+func (l withArgs) Info(msg string, args ...any)  { l.logger.Info(msg, l.join(args)...) }
+func (l withArgs) Warn(msg string, args ...any)  { l.logger.Warn(msg, l.join(args)...) }
+func (l withArgs) Error(msg string, args ...any) { l.logger.Error(msg, l.join(args)...) }
+
+func (l withArgs) join(args []any) []any {
+	return append(append(make([]any, 0, len(l.args)+len(args)), l.args...), args...)
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithAddsArgsToEveryLog(t *testing.T) {
+	recorder := &recordingLogger{}
+	logger := With(With(recorder, "session_id", "abc"), "turn_id", "t1")
+
+	logger.Warn("slow response", "seconds", 3)
+
+	if len(recorder.args) != 1 {
+		t.Fatalf("expected one log, got %d", len(recorder.args))
+	}
+	expected := []any{"session_id", "abc", "turn_id", "t1", "seconds", 3}
+	for i, arg := range expected {
+		if recorder.args[0][i] != arg {
+			t.Fatalf("expected args %v, got %v", expected, recorder.args[0])
+		}
+	}
+}
+
+func TestWithUsesSlogLoggerWith(t *testing.T) {
+	var out bytes.Buffer
+	logger := With(slog.New(slog.NewTextHandler(&out, nil)), "turn_id", "t1")
+	if _, ok := logger.(*slog.Logger); !ok {
+		t.Fatalf("expected *slog.Logger, got %T", logger)
+	}
+
+	logger.Info("started")
+	if !strings.Contains(out.String(), "turn_id=t1") {
+		t.Fatalf("expected turn id in %q", out.String())
+	}
+}
+
+type recordingLogger struct {
+	args [][]any
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) { l.args = append(l.args, args) }
+func (l *recordingLogger) Info(msg string, args ...any)  { l.args = append(l.args, args) }
+func (l *recordingLogger) Warn(msg string, args ...any)  { l.args = append(l.args, args) }
+func (l *recordingLogger) Error(msg string, args ...any) { l.args = append(l.args, args) }
@@ -0,0 +1,234 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+var (
+	ErrSessionExists   = errors.New("session already exists")
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// Manager keeps track of the sessions served by a process, one orchestrator
+// per session.
+//
+// The manager does not configure or start orchestrators, sessions are added
+// once they are created. It coordinates operations that span sessions, like
+// draining all of them before a deploy so they can be resumed on another
+// instance with [WithSessionStateV0].
+type Manager struct {
+	mu       sync.Mutex
+	sessions map[string]*Orchestrator
+
+	// tenants is set by [WithTenantLimits].
+	tenants *tenantLimiter
+	// emitEvent is set by [WithManagerEventCallback].
+	emitEvent eventEmitter
+
+	// idleTimeout is set by [WithIdleTimeout], idleTimeouts are the timeouts
+	// of the sessions that have one.
+	idleTimeout      time.Duration
+	idleTimeouts     map[string]time.Duration
+	idleClock        clock.Clock
+	idleWatchStarted sync.Once
+	idleWake         chan struct{}
+	idleDone         chan struct{}
+	closeOnce        sync.Once
+}
+
+type ManagerOption func(*Manager)
+
+// WithManagerEventCallback receives the events of the manager, e.g.
+// [events.TenantLimitExceeded]. Events of the sessions are not passed to it.
+func WithManagerEventCallback(callback func(event events.Event)) ManagerOption {
+	return func(m *Manager) {
+		m.emitEvent = callback
+	}
+}
+
+func NewManager(opts ...ManagerOption) *Manager {
+	m := &Manager{
+		sessions:     map[string]*Orchestrator{},
+		emitEvent:    noopEventEmitter,
+		idleTimeouts: map[string]time.Duration{},
+		idleClock:    clock.Real(),
+		idleWake:     make(chan struct{}, 1),
+		idleDone:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.emitEvent == nil {
+		m.emitEvent = noopEventEmitter
+	}
+	if m.tenants != nil {
+		m.tenants.emitEvent = m.emitEvent
+	}
+	return m
+}
+
+type SessionOptions struct {
+	tenant      string
+	idleTimeout *time.Duration
+}
+
+type SessionOption func(*SessionOptions)
+
+// WithTenant attributes the session to tenant, e.g. a customer or an API
+// key, for the limits of [WithTenantLimits].
+func WithTenant(tenant string) SessionOption {
+	return func(o *SessionOptions) {
+		o.tenant = tenant
+	}
+}
+
+// Add registers orchestrator under the session id. It fails with
+// [ErrTenantConversationLimit] if the tenant of the session already has as
+// many sessions as its limit allows.
+func (m *Manager) Add(id string, orchestrator *Orchestrator, opts ...SessionOption) error {
+	if orchestrator == nil {
+		return fmt.Errorf("orchestrator is required")
+	}
+	options := SessionOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.sessions[id]; ok {
+		return fmt.Errorf("%w: %s", ErrSessionExists, id)
+	}
+	if err := m.tenants.admitSession(options.tenant, id); err != nil {
+		return err
+	}
+
+	m.sessions[id] = orchestrator
+	if m.tenants != nil {
+		orchestrator.admitTurn.Store(&turnAdmission{admit: func() (func(), error) { return m.tenants.admitTurn(options.tenant, id) }})
+	}
+
+	idleTimeout := m.idleTimeout
+	if options.idleTimeout != nil {
+		idleTimeout = *options.idleTimeout
+	}
+	if idleTimeout > 0 {
+		orchestrator.markActive()
+		m.idleTimeouts[id] = idleTimeout
+		m.watchIdle()
+	}
+	return nil
+}
+
+// forgetLocked stops managing the session id, m.mu must be held.
+func (m *Manager) forgetLocked(id string) {
+	delete(m.sessions, id)
+	delete(m.idleTimeouts, id)
+	m.tenants.releaseSession(id)
+}
+
+// Get returns the orchestrator of the session id.
+func (m *Manager) Get(id string) (*Orchestrator, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	orchestrator, ok := m.sessions[id]
+	return orchestrator, ok
+}
+
+// Sessions returns the ids of all managed sessions.
+func (m *Manager) Sessions() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Remove closes the session id and stops managing it.
+func (m *Manager) Remove(id string) {
+	m.mu.Lock()
+	orchestrator, ok := m.sessions[id]
+	m.forgetLocked(id)
+	m.mu.Unlock()
+
+	if ok {
+		orchestrator.Close()
+	}
+}
+
+// Drain drains the session id, see [Orchestrator.Drain], and stops managing
+// it.
+func (m *Manager) Drain(ctx context.Context, id string) (SessionStateV0, error) {
+	m.mu.Lock()
+	orchestrator, ok := m.sessions[id]
+	m.forgetLocked(id)
+	m.mu.Unlock()
+
+	if !ok {
+		return SessionStateV0{}, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	return orchestrator.Drain(ctx)
+}
+
+// DrainAll drains all sessions concurrently and returns their states by
+// session id. Sessions that failed to drain are left out of the result and
+// their errors joined.
+func (m *Manager) DrainAll(ctx context.Context) (map[string]SessionStateV0, error) {
+	m.mu.Lock()
+	sessions := maps.Clone(m.sessions)
+	for id := range sessions {
+		m.forgetLocked(id)
+	}
+	m.mu.Unlock()
+
+	var mu sync.Mutex
+	var errs []error
+	states := make(map[string]SessionStateV0, len(sessions))
+
+	var wg sync.WaitGroup
+	for id, orchestrator := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			state, err := orchestrator.Drain(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to drain session %s: %w", id, err))
+				return
+			}
+			states[id] = state
+		}()
+	}
+	wg.Wait()
+
+	return states, errors.Join(errs...)
+}
+
+// Close closes all sessions.
+func (m *Manager) Close() {
+	m.closeOnce.Do(func() { close(m.idleDone) })
+	m.mu.Lock()
+	sessions := maps.Clone(m.sessions)
+	for id := range sessions {
+		m.forgetLocked(id)
+	}
+	m.mu.Unlock()
+
+	for _, orchestrator := range sessions {
+		orchestrator.Close()
+	}
+}
@@ -0,0 +1,68 @@
+package orchestration
+
+import (
+	"sync/atomic"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+const (
+	microphoneOpen uint32 = iota
+	microphoneMuted
+	microphoneMutedRecording
+)
+
+type MicrophoneMuteOptions struct {
+	keepRecording bool
+}
+
+type MicrophoneMuteOption func(*MicrophoneMuteOptions)
+
+// WithRecordingWhileMuted keeps emitting user audio frames while the
+// microphone is muted, e.g. for call recording sinks, they are only no
+// longer passed to speech-to-text.
+func WithRecordingWhileMuted() MicrophoneMuteOption {
+	return func(o *MicrophoneMuteOptions) {
+		o.keepRecording = true
+	}
+}
+
+// microphoneMute is the mute state of user audio, see
+// [Orchestrator.MuteMicrophone].
+type microphoneMute struct {
+	state atomic.Uint32
+}
+
+// MuteMicrophone stops passing user audio to speech-to-text, so nothing the
+// user says is transcribed or responded to, independently of [Orchestrator.Mute]
+// muting the assistant. Audio input keeps being captured, its frames are
+// dropped unless [WithRecordingWhileMuted] is passed.
+//
+// A change is reported with an [events.MicrophoneMuted] event.
+func (o *Orchestrator) MuteMicrophone(opts ...MicrophoneMuteOption) {
+	options := MicrophoneMuteOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	state := microphoneMuted
+	if options.keepRecording {
+		state = microphoneMutedRecording
+	}
+	if o.microphone.state.Swap(state) != state {
+		o.emitEvent(events.NewMicrophoneMuted(options.keepRecording))
+	}
+}
+
+// UnmuteMicrophone resumes passing user audio to speech-to-text.
+func (o *Orchestrator) UnmuteMicrophone() {
+	if o.microphone.state.Swap(microphoneOpen) != microphoneOpen {
+		o.emitEvent(events.NewMicrophoneUnmuted())
+	}
+}
+
+// IsMicrophoneMuted reports whether user audio is kept from speech-to-text,
+// see [Orchestrator.MuteMicrophone].
+func (o *Orchestrator) IsMicrophoneMuted() bool {
+	return o.microphone.state.Load() != microphoneOpen
+}
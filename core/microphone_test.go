@@ -0,0 +1,62 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestMutedMicrophoneKeepsAudioFromSpeechToText(t *testing.T) {
+	sttClient := &recordingSpeechToTextClient{}
+	o := NewOrchestrator(WithSpeechToTextClient(sttClient))
+	defer o.Close()
+
+	var mu sync.Mutex
+	var frames []byte
+	var changes []events.Event
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch event := event.(type) {
+		case events.UserAudioFrame:
+			frames = append(frames, event.Audio[0])
+		case events.MicrophoneMuted, events.MicrophoneUnmuted:
+			changes = append(changes, event)
+		}
+	}))
+	audioEmitter := o.composeAudioInputEventEmitter(o.emitEvent)
+
+	o.MuteMicrophone()
+	o.MuteMicrophone()
+	audioEmitter(events.NewUserAudioFrame([]byte{1}))
+	if err := o.SendAudio([]byte{2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o.MuteMicrophone(WithRecordingWhileMuted())
+	audioEmitter(events.NewUserAudioFrame([]byte{3}))
+	if sent := sttClient.snapshot(); len(sent) != 0 {
+		t.Fatalf("expected no audio to reach speech-to-text while muted, got %v", sent)
+	}
+
+	o.UnmuteMicrophone()
+	audioEmitter(events.NewUserAudioFrame([]byte{4}))
+	if sent := sttClient.snapshot(); len(sent) != 1 || sent[0][0] != 4 {
+		t.Fatalf("expected audio to reach speech-to-text once unmuted, got %v", sent)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if string(frames) != string([]byte{3, 4}) {
+		t.Fatalf("expected frames only while recording or unmuted, got %v", frames)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("expected muted, muted while recording and unmuted events, got %+v", changes)
+	}
+	if muted, ok := changes[1].(events.MicrophoneMuted); !ok || !muted.KeepRecording {
+		t.Fatalf("expected the second change to keep recording, got %+v", changes[1])
+	}
+}
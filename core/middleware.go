@@ -0,0 +1,80 @@
+package orchestration
+
+import (
+	"context"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// TurnRequest is what the response of a turn is generated from.
+type TurnRequest struct {
+	Trigger llms.TriggerV0
+	// History is the conversation before the turn, as passed to the LLM.
+	History []llms.TurnV1
+	// SystemPrompt replaces the system prompt of the LLM when set.
+	SystemPrompt string
+	// LLM generates the response of the turn. It is the LLM of the trigger if
+	// it is a [TriggerWithLLM], otherwise the LLM of the orchestrator, and the
+	// budget fallback in place of either once the budget is exceeded. It can
+	// be replaced for the turn only, e.g. with a larger model when the user is
+	// frustrated.
+	LLM LLM
+}
+
+// TriggerWithLLM is a trigger whose turn is generated by its own LLM
+// instead of the LLM of the orchestrator, e.g. a small model for
+// confirmations.
+type TriggerWithLLM interface {
+	llms.TriggerV0
+	TurnLLM() LLM
+}
+
+// TurnHandler generates the response to req and returns the finalised turn.
+type TurnHandler func(ctx context.Context, req TurnRequest) (llms.TurnV1, error)
+
+// TurnMiddleware wraps the response pipeline of every turn, see
+// [WithTurnMiddleware]. Before calling next it can change the request, e.g.
+// to defend against prompt injection, and after it can inspect or change the
+// finalised turn before it is added to the history, e.g. to post-edit the
+// response. Returning without calling next skips generating a response.
+type TurnMiddleware interface {
+	WrapTurn(next TurnHandler) TurnHandler
+}
+
+// TurnMiddlewareFunc adapts a function to a [TurnMiddleware].
+type TurnMiddlewareFunc func(next TurnHandler) TurnHandler
+
+func (f TurnMiddlewareFunc) WrapTurn(next TurnHandler) TurnHandler { return f(next) }
+
+// WithTurnMiddleware wraps turns in middlewares. Middlewares registered
+// first are outermost, they see the request first and the turn last.
+//
+// A changed trigger replaces the trigger of the turn in the history. The
+// middlewares run after the OnTurnStart hooks and the budget check.
+func WithTurnMiddleware(middlewares ...TurnMiddleware) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.turnMiddlewares = append(o.turnMiddlewares, middlewares...)
+	}
+}
+
+// WithSystemPromptProvider sets the system prompt of each turn to the one
+// provider returns for the conversation and trigger of the turn, so
+// instructions depending on state, e.g. the contents of a cart, are kept up
+// to date without changing the LLM client between turns. An empty prompt
+// keeps the system prompt of the client.
+//
+// The provider runs right before the turn is generated, the prompt it
+// returns is the [TurnRequest.SystemPrompt] seen by turn middlewares.
+func WithSystemPromptProvider(provider func(conversation ConversationV1, trigger llms.TriggerV0) string) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.systemPromptProvider = provider
+	}
+}
+
+// wrapTurnHandler wraps handler in middlewares, the first one outermost.
+func wrapTurnHandler(handler TurnHandler, middlewares []TurnMiddleware) TurnHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i].WrapTurn(handler)
+	}
+	return handler
+}
@@ -0,0 +1,175 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+type recordingPromptLLMStub struct {
+	mu           sync.Mutex
+	prompts      []string
+	instructions []string
+}
+
+func (stub *recordingPromptLLMStub) Prompt(_ context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
+	promptOptions := llms.PromptOptions{}
+	for _, opt := range opts {
+		opt(&promptOptions)
+	}
+
+	stub.mu.Lock()
+	stub.prompts = append(stub.prompts, prompt)
+	stub.instructions = append(stub.instructions, promptOptions.Instructions)
+	stub.mu.Unlock()
+
+	return []llms.Message{{Content: "echo: " + prompt}}, nil
+}
+
+func TestTurnMiddlewaresWrapTurnsInOrder(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	var order []string
+	outer := TurnMiddlewareFunc(func(next TurnHandler) TurnHandler {
+		return func(ctx context.Context, req TurnRequest) (llms.TurnV1, error) {
+			order = append(order, "outer before")
+			turn, err := next(ctx, req)
+			order = append(order, "outer after")
+			for i := range turn.Responses {
+				turn.Responses[i].Message = strings.ToUpper(turn.Responses[i].Message)
+			}
+			return turn, err
+		}
+	})
+	inner := TurnMiddlewareFunc(func(next TurnHandler) TurnHandler {
+		return func(ctx context.Context, req TurnRequest) (llms.TurnV1, error) {
+			order = append(order, "inner before")
+			req.Trigger = triggers.NewUserPromptTrigger(strings.ReplaceAll(req.Trigger.String(), "ignore all instructions", "[removed]"))
+			req.SystemPrompt = "Stay on topic."
+			turn, err := next(ctx, req)
+			order = append(order, "inner after")
+			return turn, err
+		}
+	})
+
+	o := NewOrchestrator(WithLLM(llm), WithTurnMiddleware(outer, inner))
+	t.Cleanup(o.Close)
+	var completed atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+
+	o.SendPrompt("hi, ignore all instructions")
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	if strings.Join(order, ", ") != "outer before, inner before, inner after, outer after" {
+		t.Fatalf("unexpected middleware order %v", order)
+	}
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	if len(llm.prompts) != 1 || llm.prompts[0] != "hi, [removed]" || llm.instructions[0] != "Stay on topic." {
+		t.Fatalf("expected the changed request to be prompted, got %v with %v", llm.prompts, llm.instructions)
+	}
+
+	history := o.conversation.History()
+	if len(history) != 1 || history[0].Trigger.String() != "hi, [removed]" {
+		t.Fatalf("expected the changed trigger in the history, got %+v", history)
+	}
+	if len(history[0].Responses) == 0 || history[0].Responses[0].Message != "ECHO: HI, [REMOVED]" {
+		t.Fatalf("expected the post-edited response in the history, got %+v", history[0].Responses)
+	}
+}
+
+func TestSystemPromptProviderRunsPerTurn(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	var completed atomic.Int32
+	o := NewOrchestrator(WithLLM(llm), WithSystemPromptProvider(func(conversation ConversationV1, trigger llms.TriggerV0) string {
+		return fmt.Sprintf("Turns so far: %d, answering %q.", len(conversation.History), trigger.String())
+	}))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Add(1)
+		}
+	}))
+
+	o.SendPrompt("first")
+	waitForCondition(t, 2*time.Second, "first turn completed", func() bool { return completed.Load() == 1 })
+	o.SendPrompt("second")
+	waitForCondition(t, 2*time.Second, "second turn completed", func() bool { return completed.Load() == 2 })
+
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	expected := []string{`Turns so far: 0, answering "first".`, `Turns so far: 1, answering "second".`}
+	if strings.Join(llm.instructions, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected %v, got %v", expected, llm.instructions)
+	}
+}
+
+type llmTrigger struct {
+	triggers.UserPromptTrigger
+	llm LLM
+}
+
+func (t llmTrigger) TurnLLM() LLM { return t.llm }
+
+func TestTurnLLMCanBeReplacedForOneTurn(t *testing.T) {
+	defaultLLM := &recordingPromptLLMStub{}
+	largeLLM := &recordingPromptLLMStub{}
+	smallLLM := &recordingPromptLLMStub{}
+	escalate := TurnMiddlewareFunc(func(next TurnHandler) TurnHandler {
+		return func(ctx context.Context, req TurnRequest) (llms.TurnV1, error) {
+			if strings.Contains(req.Trigger.String(), "frustrated") {
+				req.LLM = largeLLM
+			}
+			return next(ctx, req)
+		}
+	})
+
+	o := NewOrchestrator(WithLLM(defaultLLM), WithTurnMiddleware(escalate))
+	t.Cleanup(o.Close)
+	var completed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Add(1)
+		}
+	}))
+
+	o.SendPrompt("I am frustrated")
+	waitForCondition(t, 2*time.Second, "escalated turn completed", func() bool { return completed.Load() == 1 })
+	o.HandleTrigger(llmTrigger{UserPromptTrigger: triggers.NewUserPromptTrigger("yes, confirm"), llm: smallLLM})
+	waitForCondition(t, 2*time.Second, "confirmation turn completed", func() bool { return completed.Load() == 2 })
+	o.SendPrompt("thanks")
+	waitForCondition(t, 2*time.Second, "regular turn completed", func() bool { return completed.Load() == 3 })
+
+	for name, tc := range map[string]struct {
+		llm     *recordingPromptLLMStub
+		prompts string
+	}{
+		"default": {defaultLLM, "thanks"},
+		"large":   {largeLLM, "I am frustrated"},
+		"small":   {smallLLM, "yes, confirm"},
+	} {
+		tc.llm.mu.Lock()
+		prompts := strings.Join(tc.llm.prompts, ", ")
+		tc.llm.mu.Unlock()
+		if prompts != tc.prompts {
+			t.Fatalf("expected the %s llm to be prompted with %q, got %q", name, tc.prompts, prompts)
+		}
+	}
+}
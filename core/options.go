@@ -2,11 +2,17 @@ package orchestration
 
 import (
 	"context"
+	"fmt"
 	"iter"
+	"time"
 
 	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
 	"github.com/koscakluka/ema-core/core/conversations"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	events "github.com/koscakluka/ema-core/core/events"
 	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/logging"
 	"github.com/koscakluka/ema-core/core/speechtotext"
 	"github.com/koscakluka/ema-core/core/texttospeech"
 )
@@ -25,6 +31,7 @@ type LLMWithGeneralPrompt interface {
 
 func WithStreamingLLM(client LLMWithStream) OrchestratorOption {
 	return func(o *Orchestrator) {
+		o.validation.configure("WithStreamingLLM", ComponentLLM, client)
 		o.llm.set(client)
 	}
 }
@@ -36,10 +43,21 @@ type SpeechToText interface {
 
 func WithSpeechToTextClient(client SpeechToText) OrchestratorOption {
 	return func(o *Orchestrator) {
+		o.validation.configure("WithSpeechToTextClient", ComponentSpeechToText, client)
 		o.speechToText.set(client)
 	}
 }
 
+// WithTranscriptionOptions configures the transcription of the speech-to-text
+// clients, e.g. [speechtotext.WithEndpointingMs] to tune how quickly the
+// assistant takes its turn. The callbacks and encoding are set by the
+// orchestrator and cannot be replaced.
+func WithTranscriptionOptions(opts ...speechtotext.TranscriptionOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.transcriptionOptions = append(o.transcriptionOptions, opts...)
+	}
+}
+
 type TextToSpeech interface {
 	OpenStream(ctx context.Context, opts ...texttospeech.TextToSpeechOption) error
 	SendText(text string) error
@@ -48,6 +66,7 @@ type TextToSpeech interface {
 
 func WithTextToSpeechClient(client TextToSpeech) OrchestratorOption {
 	return func(o *Orchestrator) {
+		o.validation.configure("WithTextToSpeechClient", ComponentTextToSpeech, client)
 		o.textToSpeech.set(client)
 		o.IsSpeaking = true
 		// TODO: This doesn't really make sense here
@@ -61,6 +80,7 @@ type TextToSpeechV1 interface {
 
 func WithTextToSpeechClientV1(client TextToSpeechV1) OrchestratorOption {
 	return func(o *Orchestrator) {
+		o.validation.configure("WithTextToSpeechClientV1", ComponentTextToSpeech, client)
 		o.textToSpeech.set(client)
 		o.IsSpeaking = true
 		// TODO: This doesn't really make sense here
@@ -78,7 +98,10 @@ type AudioInputFine interface {
 }
 
 func WithAudioInput(client AudioInput) OrchestratorOption {
-	return func(o *Orchestrator) { o.audioInput.Set(client) }
+	return func(o *Orchestrator) {
+		o.validation.configure("WithAudioInput", ComponentAudioInput, client)
+		o.audioInput.Set(client)
+	}
 }
 
 type AudioOutputV0 interface {
@@ -87,7 +110,10 @@ type AudioOutputV0 interface {
 }
 
 func WithAudioOutputV0(client AudioOutputV0) OrchestratorOption {
-	return func(o *Orchestrator) { o.audioOutput.Set(client) }
+	return func(o *Orchestrator) {
+		o.validation.configure("WithAudioOutputV0", ComponentAudioOutput, client)
+		o.audioOutput.Set(client)
+	}
 }
 
 type AudioOutputV1 interface {
@@ -96,7 +122,10 @@ type AudioOutputV1 interface {
 }
 
 func WithAudioOutputV1(client AudioOutputV1) OrchestratorOption {
-	return func(o *Orchestrator) { o.audioOutput.Set(client) }
+	return func(o *Orchestrator) {
+		o.validation.configure("WithAudioOutputV1", ComponentAudioOutput, client)
+		o.audioOutput.Set(client)
+	}
 }
 
 func WithTools(tools ...llms.Tool) OrchestratorOption {
@@ -107,6 +136,26 @@ func WithOrchestrationTools() OrchestratorOption {
 	return func(o *Orchestrator) { o.llm.appendTools(orchestrationTools(o)...) }
 }
 
+// ToolSourceV0 offers tools that can change during the conversation, e.g.
+// the tools of an MCP server (see the tools/mcp package).
+type ToolSourceV0 interface {
+	// Tools returns the tools currently offered, it is called for every
+	// request to the LLM and every tool call.
+	Tools() []llms.Tool
+}
+
+// WithToolSource adds the tools source currently offers to the tools of
+// every turn, next to the tools of [WithTools].
+func WithToolSource(source ToolSourceV0) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if isNilClient(source) {
+			o.validation.errs = append(o.validation.errs, fmt.Errorf("WithToolSource: %w", ErrNilClient))
+			return
+		}
+		o.llm.appendToolSource(source)
+	}
+}
+
 type TriggerHandlerV0 interface {
 	HandleTriggerV0(ctx context.Context, trigger llms.TriggerV0, conversation conversations.ActiveContextV0) iter.Seq2[llms.TriggerV0, error]
 }
@@ -121,6 +170,97 @@ func WithTriggerHandlerV0(handler TriggerHandlerV0) OrchestratorOption {
 	}
 }
 
+// WithLogger sets the logger used by the orchestrator, defaults to
+// [logging.Default]. Logs written during a turn carry its "turn_id".
+func WithLogger(logger logging.Logger) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if logger == nil {
+			logger = logging.Default()
+		}
+		o.logger = logger
+	}
+}
+
+// WithErrorReporter passes errors that are otherwise only recorded on spans,
+// e.g. of pipeline workers, facades and provider read loops, to reporter.
+// The metadata carries the "component" and, within a turn, the "turn_id".
+func WithErrorReporter(reporter errorreport.Reporter) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.errorReporter = reporter
+	}
+}
+
+// WithToolPool executes tool calls on pool instead of inline in the turn,
+// share one pool between the orchestrators of a process to bound the
+// goroutines and concurrency tools can use, see [ToolPool].
+func WithToolPool(pool *ToolPool) OrchestratorOption {
+	return func(o *Orchestrator) { o.llm.toolPool = pool }
+}
+
+// WithBorrowedAudioFrames emits user audio and playback frames as borrowed
+// frames: their audio is only valid until the event callback returns and is
+// reused afterwards, avoiding a copy per frame. Receivers that keep frames
+// must copy them with Retain, see [events.UserAudioFrame.Retain].
+//
+// Audio input clients may reuse the buffer they pass to the orchestrator
+// once the callback returns when this is enabled.
+func WithBorrowedAudioFrames() OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.borrowAudioFrames = true
+	}
+}
+
+// WithBoundedPlaybackMemory releases the audio and text of a response while
+// it is played instead of keeping them for the whole turn. Audio is released
+// once the audio output confirmed playing it and it was emitted as playback
+// frames, at least retention later; confirmed text segments are folded into
+// the spoken text. Long responses then hold only the audio not yet played.
+func WithBoundedPlaybackMemory(retention time.Duration) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.speechPlayer.releasePlayed = true
+		o.speechPlayer.releaseRetention = retention
+	}
+}
+
+// WithClock sets the time source for playback progress approximation and
+// spoken-text updates, defaults to [clock.Real]. Tests and replays pass a
+// [clock.Fake] to step through playback without waiting.
+func WithClock(c clock.Clock) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if c == nil {
+			c = clock.Real()
+		}
+		o.clock = c
+	}
+}
+
+// TriggerQueueV0 is an external queue backing the orchestrator trigger queue.
+//
+// Triggers accepted for turn processing are enqueued through it instead of
+// being kept in memory, and are acknowledged only after the turn they started
+// has been processed. A persistent implementation lets queued triggers
+// survive a process crash and another instance pick them up.
+type TriggerQueueV0 interface {
+	Enqueue(ctx context.Context, trigger llms.TriggerV0) error
+	// Dequeue blocks until a trigger is available or ctx is done.
+	Dequeue(ctx context.Context) (QueuedTriggerV0, error)
+	// Ack marks the dequeued trigger with id as processed.
+	Ack(ctx context.Context, id string) error
+}
+
+// QueuedTriggerV0 is a trigger taken out of a [TriggerQueueV0].
+type QueuedTriggerV0 struct {
+	ID       string
+	Trigger  llms.TriggerV0
+	QueuedAt time.Time
+}
+
+// WithTriggerQueueV0 backs the trigger queue with an external queue, e.g. a
+// Redis stream.
+func WithTriggerQueueV0(queue TriggerQueueV0) OrchestratorOption {
+	return func(o *Orchestrator) { o.triggerPlayer.Load().SetQueue(queue) }
+}
+
 type OrchestrateOptions struct {
 	onTranscription               func(transcript string)
 	onPartialTranscription        func(transcript string)
@@ -136,6 +276,10 @@ type OrchestrateOptions struct {
 	onAudioEnded                  func(transcript string)
 	onSpokenText                  func(spokenText string)
 	onSpokenTextDelta             func(spokenTextDelta string)
+	onEvent                       func(event events.Event)
+	sinks                         []*routedEventSink
+	// chatEvents is set by [WithChatEvents].
+	chatEvents bool
 }
 
 type OrchestrateOption func(*OrchestrateOptions)
@@ -268,6 +412,18 @@ func WithInputAudioCallback(callback func(audio []byte)) OrchestrateOption {
 	}
 }
 
+// WithEventCallback registers a callback for every emitted orchestration
+// event.
+//
+// The callback receives the typed events from [events] in emission order,
+// before any of the specialised callbacks are invoked. It runs inline on the
+// emitting path (including audio frames) and should not block.
+func WithEventCallback(callback func(event events.Event)) OrchestrateOption {
+	return func(o *OrchestrateOptions) {
+		o.onEvent = callback
+	}
+}
+
 type LLM any
 
 type audioOutputBase interface {
@@ -1,6 +1,18 @@
 package orchestration
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
 
 func TestWithConfigForwardsAlwaysRecording(t *testing.T) {
 	o := NewOrchestrator(WithConfig(&Config{AlwaysRecording: false}))
@@ -17,3 +29,68 @@ func TestWithConfigNilIsNoop(t *testing.T) {
 		t.Fatalf("expected nil config to keep default always recording")
 	}
 }
+
+func TestWithLoggerCorrelatesTurnLogs(t *testing.T) {
+	out := &lockedBuffer{}
+	o := NewOrchestrator(
+		WithLLM(emptyPromptLLMStub{}),
+		WithLogger(slog.New(slog.NewTextHandler(out, nil))),
+	)
+	defer o.Close()
+
+	started := make(chan string, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if turnStarted, ok := event.(events.TurnStarted); ok {
+			started <- turnStarted.TurnID
+		}
+	}))
+	o.QueuePrompt("hello")
+
+	var turnID string
+	select {
+	case turnID = <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for turn to start")
+	}
+
+	waitForCondition(t, 2*time.Second, "turn scoped warning", func() bool {
+		logs := out.String()
+		return strings.Contains(logs, "no turns returned") && strings.Contains(logs, "turn_id="+turnID)
+	})
+}
+
+type emptyPromptLLMStub struct{}
+
+func (emptyPromptLLMStub) Prompt(context.Context, string, ...llms.PromptOption) ([]llms.Message, error) {
+	return nil, nil
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWithClockDrivesPlaybackApproximation(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	o := NewOrchestrator(WithClock(fakeClock))
+
+	player := o.speechPlayer.Snapshot()
+	player.InitBuffers(o.audioOutput.EncodingInfo(), "")
+	if player.audioBuffer.clock != fakeClock {
+		t.Fatalf("expected turn audio buffers to use the configured clock")
+	}
+}
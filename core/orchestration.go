@@ -4,13 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"sync/atomic"
+	"time"
 
-	"log"
-
+	"github.com/koscakluka/ema-core/core/clock"
+	"github.com/koscakluka/ema-core/core/errorreport"
 	events "github.com/koscakluka/ema-core/core/events"
 	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/llms/contextwindow"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/speechtotext"
 	"github.com/koscakluka/ema-core/core/triggers"
 	"github.com/koscakluka/ema-core/internal/utils"
 	"go.opentelemetry.io/otel/attribute"
@@ -18,6 +23,14 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrConversationStarted is the error of starting a conversation on an
+// orchestrator that already started one.
+var ErrConversationStarted = errors.New("conversation already started")
+
+// ErrConversationNotStarted is the error of restarting a conversation that
+// was not started.
+var ErrConversationNotStarted = errors.New("conversation not started")
+
 type Orchestrator struct {
 	baseContext  context.Context
 	conversation activeConversation
@@ -32,8 +45,23 @@ type Orchestrator struct {
 	textToSpeech textToSpeech
 	audioOutput  audioOutput
 	speechPlayer speechPlayer
+	// speakerSpeechToText transcribes the audio streams of single users, see
+	// [WithSpeakerSpeechToText].
+	speakerSpeechToText map[string]*speechToText
+	// transcriptionOptions configure every speech-to-text stream, see
+	// [WithTranscriptionOptions].
+	transcriptionOptions []speechtotext.TranscriptionOption
+	// voiceActivityDetector reports speech in place of the speech-to-text
+	// client when set, see [WithVoiceActivityDetector].
+	voiceActivityDetector VoiceActivityDetectorV0
+	// wakeWord holds the audio input back from speech-to-text until the
+	// wake word is heard when set, see [WithWakeWord].
+	wakeWord *wakeWordGate
 
 	triggerHandler TriggerHandlerV0
+	// customTriggerHandlers handle trigger types registered with
+	// [WithCustomTrigger].
+	customTriggerHandlers map[reflect.Type]TriggerHandlerV0
 	// defaultTriggerHandler is the internal trigger handler used to handle incoming
 	// triggers if no other handler is configured.
 	//
@@ -41,9 +69,96 @@ type Orchestrator struct {
 	// probably on minor release
 	defaultTriggerHandler internalTriggerHandler
 
-	triggerPlayer    *triggerPlayer
+	// triggerPlayer is replaced by [Orchestrator.Restart].
+	triggerPlayer    atomic.Pointer[triggerPlayer]
 	responsePipeline atomic.Pointer[responsePipeline]
 
+	// resumedState is applied when orchestration starts, see
+	// [WithSessionStateV0].
+	resumedState *SessionStateV0
+
+	logger logging.Logger
+	// borrowAudioFrames is set by [WithBorrowedAudioFrames].
+	borrowAudioFrames bool
+	clock             clock.Clock
+	hooks             hookChain
+	// systemPromptProvider is set by [WithSystemPromptProvider].
+	systemPromptProvider func(conversation ConversationV1, trigger llms.TriggerV0) string
+	// turnMiddlewares wrap every turn, see [WithTurnMiddleware].
+	turnMiddlewares []TurnMiddleware
+	// addressingPolicy is set by [WithAddressingPolicy].
+	addressingPolicy AddressingPolicyV0
+	// turnTaking is set by [WithTurnTakingModel].
+	turnTaking *turnTaking
+	// backchannels is set by [WithBackchannels].
+	backchannels *backchanneler
+	// translation is set by [WithTranslation].
+	translation *translation
+	// voiceShaping is set by [WithVoiceShaping].
+	voiceShaping *VoiceShapingOptions
+	// stallDetection is set by [WithStallDetection].
+	stallDetection *stallDetection
+	// echoMinWords is set by [WithEchoSuppression].
+	echoMinWords int
+	// speechDuringPlayback is whether the latest user speech started while
+	// the assistant was playing audio.
+	speechDuringPlayback atomic.Bool
+	// admitTurn is set by the [Manager] enforcing [WithTenantLimits].
+	admitTurn atomic.Pointer[turnAdmission]
+	// errorReporter is set by [WithErrorReporter].
+	errorReporter errorreport.Reporter
+	// validation collects option problems, see [NewOrchestratorE].
+	validation optionValidation
+	// speechDegradation tracks failed speech components across turns, see
+	// [events.DegradedToTextOnly].
+	speechDegradation speechDegradation
+	// conversationStarted is set once the conversation start hooks passed.
+	conversationStarted atomic.Bool
+	// orchestrated is set by the first [Orchestrator.Orchestrate], later
+	// calls are refused.
+	orchestrated atomic.Bool
+	// closing is set once [Orchestrator.Close] started, so a concurrent
+	// [Orchestrator.Restart] does not start the conversation again.
+	closing atomic.Bool
+	// restartMu serializes [Orchestrator.Restart] calls.
+	restartMu sync.Mutex
+	// lastActivity is the time of the last activity of the conversation in
+	// Unix nanoseconds, see [WithIdleTimeout].
+	lastActivity atomic.Int64
+	// emitter emits events outside of the components, it is set by
+	// [Orchestrator.Orchestrate], see [Orchestrator.emitEvent].
+	emitter atomic.Pointer[eventEmitter]
+	// eventSinks are the sinks of the conversation, they are stopped by
+	// [Orchestrator.Close] and [Orchestrator.Restart], see [WithEventSink].
+	eventSinks atomic.Pointer[[]*routedEventSink]
+	// compliance withholds user audio and transcripts during sensitive
+	// segments, see [Orchestrator.StartCompliancePause].
+	compliance compliancePause
+	// interruptionResume resumes responses interrupted by side questions, see
+	// [WithInterruptionResume].
+	interruptionResume *interruptionResume
+	// tokenCounter is set by [WithTokenCounter].
+	tokenCounter llms.TokenCounter
+	// contextWindow is set by [WithContextWindowPolicy].
+	contextWindow *contextwindow.Policy
+	// conversationStore is set by [WithConversationStore].
+	conversationStore *conversationStore
+	// analysis is the latest report of [Orchestrator.Analyze].
+	analysis atomic.Pointer[ConversationAnalysisV0]
+	// warmStandby prepares the pipeline of the next turn, see
+	// [WithWarmStandby].
+	warmStandby *warmStandby
+	// outcome is set by [Orchestrator.LabelConversationOutcome].
+	outcome atomic.Pointer[llms.OutcomeV0]
+	// closingSummary is set by [WithClosingSummary].
+	closingSummary *ClosingSummaryOptions
+	// pipelineStages creates the custom stages of turns, see
+	// [WithPipelineStage].
+	pipelineStages map[PipelinePosition][]NewPipelineStageV0
+	// microphone keeps user audio from speech-to-text while muted, see
+	// [Orchestrator.MuteMicrophone].
+	microphone microphoneMute
+
 	// IsRecording indicates whether the orchestrator is currently recording
 	// audio input.
 	//
@@ -73,10 +188,13 @@ func NewOrchestrator(opts ...OrchestratorOption) *Orchestrator {
 		audioOutput:  *newAudioOutput(nil),
 		speechPlayer: *newSpeechPlayer(),
 
-		triggerPlayer: newTriggerPlayer(),
+		logger: logging.Default(),
+		clock:  clock.Real(),
 	}
+	o.triggerPlayer.Store(newTriggerPlayer())
 	// TODO: Move up once pipeline is removed from the constructor
 	o.conversation = newConversation(o.currentResponsePipeline, o.llm.availableTools)
+	o.conversation.tokenUsage = o.tokenUsage
 
 	// TODO: Remove defaultTriggerHandler once we remove the interruption handlers
 	// probably on minor release
@@ -86,30 +204,58 @@ func NewOrchestrator(opts ...OrchestratorOption) *Orchestrator {
 	for _, opt := range opts {
 		opt(o)
 	}
+	o.triggerPlayer.Load().logger = o.logger
+	o.audioInput.logger = o.logger
+	o.llm.logger = o.logger
+	o.audioInput.borrowFrames = o.borrowAudioFrames
+	o.speechPlayer.borrowFrames = o.borrowAudioFrames
+	o.speechPlayer.clock = o.clock
+	if o.llm.toolFiller != nil {
+		o.llm.toolFiller.clock = o.clock
+	}
+	if o.turnTaking != nil {
+		o.turnTaking.clock = o.clock
+		o.turnTaking.commit = o.dispatchTrigger
+	}
 
 	return o
 }
 
 func (o *Orchestrator) Close() {
 	o.closeOnce.Do(func() {
-		o.triggerPlayer.Stop()
+		o.closing.Store(true)
+		o.triggerPlayer.Load().Stop()
 		o.currentResponsePipeline().Cancel()
+		o.warmStandby.close(o.baseContext)
 
 		if err := o.audioInput.Close(); err != nil {
 			recordedErr := fmt.Errorf("failed to close audio input: %w", err)
 			span := trace.SpanFromContext(o.baseContext)
-			span.RecordError(recordedErr)
+			errorreport.Record(o.baseContext, span, recordedErr, "component", string(ComponentAudioInput))
 			span.SetStatus(codes.Error, recordedErr.Error())
 		}
 
 		if err := o.speechToText.Close(o.baseContext); err != nil {
 			recordedErr := fmt.Errorf("failed to close speech-to-text client: %w", err)
 			span := trace.SpanFromContext(o.baseContext)
-			span.RecordError(recordedErr)
+			errorreport.Record(o.baseContext, span, recordedErr, "component", string(ComponentSpeechToText))
 			span.SetStatus(codes.Error, recordedErr.Error())
 		}
+		for speakerID, stt := range o.speakerSpeechToText {
+			if err := stt.Close(o.baseContext); err != nil {
+				recordedErr := fmt.Errorf("failed to close speech-to-text client of speaker %s: %w", speakerID, err)
+				span := trace.SpanFromContext(o.baseContext)
+				errorreport.Record(o.baseContext, span, recordedErr, "component", string(ComponentSpeechToText), "speaker_id", speakerID)
+				span.SetStatus(codes.Error, recordedErr.Error())
+			}
+		}
+
+		o.triggerPlayer.Load().AwaitDone()
 
-		o.triggerPlayer.AwaitDone()
+		if o.conversationStarted.Load() {
+			o.hooks.conversationEnd(context.WithoutCancel(o.baseContext))
+		}
+		o.stopEventSinks()
 	})
 }
 
@@ -118,71 +264,237 @@ func (o *Orchestrator) Close() {
 // ctx is used as a base context for any agent and tool calls, allowing for
 // cancellation
 //
-// Contract: call Orchestrate at most once per orchestrator instance.
-// Repeated or concurrent calls are unsupported and may race while runtime
-// callbacks/options are being reconfigured.
-// TODO: Enforce this contract with a hard runtime guard (single-start gate).
-func (o *Orchestrator) Orchestrate(ctx context.Context, opts ...OrchestrateOption) {
-	// TODO: Rename this to StartConversation which will start a new conversation
-	// with everything setup
-	// It is also probably worth it to invest into a builder pattern instead of
-	// using the options, including the callbacks and then finish with calling
-	// the StartConversation method. It will be clearer to the caller and
-	// it will allow reusing the orchestrator. But we need to have the contract
-	// say that if there is a running conversation, it will be cancelled.
-	// and new one started from scratch.
-	// Additionally, we need to allow populating the conversation with
-	// tools and history.
-	// This method will probably need a EndConversation method to nicely clean
-	// up the conversation if the user choosed to do so.
-
-	if !o.triggerPlayer.CanIngest() {
-		log.Println("Warning: orchestrator already closed, skipping Orchestrate")
-		return
+// Orchestrate starts at most one conversation per orchestrator instance,
+// repeated or concurrent calls fail with [ErrConversationStarted] and calls
+// on a closed orchestrator with [ErrOrchestratorClosed]. Use
+// [Orchestrator.Restart] to start the conversation again.
+func (o *Orchestrator) Orchestrate(ctx context.Context, opts ...OrchestrateOption) error {
+	if !o.triggerPlayer.Load().CanIngest() {
+		o.logger.Warn("orchestrator already closed, skipping Orchestrate")
+		return ErrOrchestratorClosed
+	}
+	if !o.orchestrated.CompareAndSwap(false, true) {
+		return ErrConversationStarted
+	}
+
+	return o.start(ctx, opts...)
+}
+
+// Restart tears down the conversation started by [Orchestrator.Orchestrate]
+// and starts it again with ctx and opts, e.g. to recover from a broken audio
+// input. The turn in progress is cancelled and queued triggers are dropped,
+// while the history and the clients are kept. The trigger player is rebuilt,
+// the speech-to-text clients are closed and started again and audio input
+// capture is stopped and started again, so clients must support starting
+// again once closed. Conversation start hooks do not run again.
+//
+// Restart fails with [ErrConversationNotStarted] before Orchestrate and with
+// [ErrOrchestratorClosed] once the orchestrator is closed.
+func (o *Orchestrator) Restart(ctx context.Context, opts ...OrchestrateOption) error {
+	o.restartMu.Lock()
+	defer o.restartMu.Unlock()
+
+	previous := o.triggerPlayer.Load()
+	if !previous.CanIngest() || o.closing.Load() {
+		return ErrOrchestratorClosed
+	}
+	if !o.orchestrated.Load() {
+		return ErrConversationNotStarted
+	}
+
+	previous.Stop()
+	o.currentResponsePipeline().Cancel()
+	previous.AwaitDone()
+	if pipeline := o.warmStandby.take(o.baseContext, o.clock.Now()); pipeline != nil {
+		// The standby emits to the callbacks being replaced.
+		_ = pipeline.textToSpeech.Close(o.baseContext)
+	}
+
+	if err := o.audioInput.Stop(); err != nil {
+		o.logger.Warn("failed to stop audio input capture", "error", err)
+	}
+	if err := o.speechToText.Close(o.baseContext); err != nil {
+		o.logger.Warn("failed to close speech-to-text client", "error", err)
+	}
+	for speakerID, stt := range o.speakerSpeechToText {
+		if err := stt.Close(o.baseContext); err != nil {
+			o.logger.Warn("failed to close speech-to-text client", "speaker_id", speakerID, "error", err)
+		}
 	}
 
+	// The sinks of the previous conversation receive its last events before
+	// the sinks of opts take over.
+	o.stopEventSinks()
+
+	player := newTriggerPlayer()
+	player.logger = previous.logger
+	player.onCancel = previous.onCancel
+	player.SetQueue(previous.external)
+	o.triggerPlayer.Store(player)
+	if o.closing.Load() {
+		player.Stop()
+		return ErrOrchestratorClosed
+	}
+
+	return o.start(ctx, opts...)
+}
+
+// start starts the conversation with ctx and the callbacks of opts.
+func (o *Orchestrator) start(ctx context.Context, opts ...OrchestrateOption) error {
 	orchestrateOptions := OrchestrateOptions{}
 	for _, opt := range opts {
 		opt(&orchestrateOptions)
 	}
+	for _, sink := range orchestrateOptions.sinks {
+		sink.start(o.logger)
+	}
+	o.eventSinks.Store(&orchestrateOptions.sinks)
 	emitEvent := newCallbackEventEmitter(orchestrateOptions)
+	if orchestrateOptions.chatEvents {
+		emitEvent = newChatMessages().observe(emitEvent)
+	}
+	if o.errorReporter != nil {
+		ctx = errorreport.NewContext(ctx, o.errorReporter)
+	}
+	if o.backchannels != nil && o.audioOutput.isConfigured() {
+		o.backchannels.start(ctx, o.clock, &o.audioOutput, emitEvent)
+		emitEvent = o.backchannels.observe(emitEvent)
+	}
+
+	// Restarted conversations continue, their history is loaded and start
+	// hooks already ran.
+	if !o.conversationStarted.Load() {
+		if err := o.loadStoredConversation(ctx); err != nil {
+			span := trace.SpanFromContext(ctx)
+			errorreport.Record(ctx, span, err, "component", "conversation_store")
+			span.SetStatus(codes.Error, err.Error())
+			o.logger.Warn("failed to load stored conversation, closing orchestrator", "error", err)
+			o.Close()
+			return err
+		}
+		if err := o.hooks.conversationStart(ctx); err != nil {
+			recordedErr := fmt.Errorf("conversation start hook failed: %w", err)
+			span := trace.SpanFromContext(ctx)
+			errorreport.Record(ctx, span, recordedErr, "component", "hooks")
+			span.SetStatus(codes.Error, recordedErr.Error())
+			o.logger.Warn("conversation start hook failed, closing orchestrator", "error", err)
+			o.Close()
+			return recordedErr
+		}
+		o.conversationStarted.Store(true)
+	}
 
 	o.baseContext = ctx
+	o.emitter.Store(&emitEvent)
 	o.llm.SetEventEmitter(emitEvent)
 	o.textToSpeech.SetEventEmitter(emitEvent)
 	o.speechPlayer.SetEventEmitter(emitEvent)
-	o.speechToText.SetEventEmitter(o.composeSTTEventEmitter(emitEvent))
+	o.speechToText.SetEventEmitter(o.withoutSpeechActivity(o.composeSTTEventEmitter(emitEvent)))
+	for _, stt := range o.speakerSpeechToText {
+		stt.SetEventEmitter(o.composeSTTEventEmitter(emitEvent))
+	}
 	o.audioInput.SetEventEmitter(o.composeAudioInputEventEmitter(emitEvent))
-	if started := o.triggerPlayer.StartLoop(o.baseContext, func(ctx context.Context, trigger llms.TriggerV0) error {
+	o.applyResumedState()
+	o.prepareStandby(emitEvent)
+	turnMiddlewares := o.turnMiddlewares
+	if o.translation != nil {
+		o.translation.emitEvent = emitEvent
+		turnMiddlewares = append([]TurnMiddleware{o.translation}, turnMiddlewares...)
+	}
+	player := o.triggerPlayer.Load()
+	if started := player.StartLoop(o.baseContext, func(ctx context.Context, trigger llms.TriggerV0, queuedAt time.Time) error {
 		var turnErr error
 		var activeTurn *activeTurn
 
-		pipeline := newResponsePipeline(o.llm.snapshot(), o.textToSpeech.Snapshot(), o.speechPlayer.Snapshot(), o.audioOutput.Snapshot(),
-			emitEvent,
-		)
+		pipeline := o.newTurnPipeline(emitEvent)
+		pipeline.degradation = &o.speechDegradation
+		pipeline.translation = o.translation
+		pipeline.voiceShaping = o.voiceShaping
+		pipeline.stages = o.pipelineStages
+		if o.stallDetection != nil {
+			pipeline.stallDetection = o.stallDetection
+			pipeline.progress = newPipelineProgress(o.clock, workerLLM, workerTextToSpeech, workerAudioOutput)
+			pipeline.llm.reportProgress = func(state string) { pipeline.progress.report(workerLLM, state) }
+			pipeline.llm.pauseProgress = pipeline.progress.setPaused
+		}
+		if prompt, ok := trigger.(triggers.UserPromptTrigger); ok && prompt.IsTextOnly {
+			pipeline.textOnly.Store(true)
+		}
 		if !o.responsePipeline.CompareAndSwap(nil, pipeline) {
 			return fmt.Errorf("active turn already in progress")
 		}
 		defer o.responsePipeline.CompareAndSwap(pipeline, nil)
+		// A turn is activity from its start to its end, however it ends, the
+		// end is marked before the pipeline is released so idle checks never
+		// see the turn ended with its start as the last activity.
+		o.markActive()
+		defer o.markActive()
+		defer pipeline.Stop()
+		o.prepareStandby(emitEvent)
 
 		activeTurn, turnErr = o.conversation.startNewTurn(trigger)
 		if turnErr != nil {
 			return turnErr
 		}
-
-		emitEvent(events.NewTurnStarted(activeTurn.TurnV1.ID, trigger.String()))
+		activeTurn.queuedAt = queuedAt
+		ctx = errorreport.WithMetadata(ctx, "turn_id", activeTurn.TurnV1.ID)
+		pipeline.llm.logger = logging.With(o.logger, "turn_id", activeTurn.TurnV1.ID)
+
+		defer func() { o.hooks.turnEnd(ctx, activeTurn.TurnV1, turnErr) }()
+		started := events.NewTurnStarted(activeTurn.TurnV1.ID, trigger.String())
+		started.InputModality = activeTurn.TurnV1.InputModality
+		emitEvent(started)
 		defer func() {
 			if turnErr != nil {
+				pipeline.state.Transition(TurnStateFailed)
 				emitEvent(events.NewTurnFailed(activeTurn.TurnV1.ID, turnErr.Error()))
 			}
 		}()
 
-		activeTurn.TurnV1, turnErr = pipeline.Run(ctx, activeTurn, o.conversation.History())
+		if err := o.hooks.turnStart(ctx, activeTurn.TurnV1); err != nil {
+			o.conversation.discardActiveTurn(activeTurn.TurnV1.ID)
+			turnErr = fmt.Errorf("%w: %w", ErrTurnVetoed, err)
+			return turnErr
+		}
+		var releaseManagedTurn func()
+		if releaseManagedTurn, turnErr = o.admitManagedTurn(); turnErr != nil {
+			o.conversation.discardActiveTurn(activeTurn.TurnV1.ID)
+			return turnErr
+		}
+		// The budget is checked after the LLM of the trigger is set so its
+		// fallback also replaces LLMs of triggers.
+		if trigger, ok := trigger.(TriggerWithLLM); ok && trigger.TurnLLM() != nil {
+			pipeline.llm.set(trigger.TurnLLM())
+		}
+		if turnErr = pipeline.llm.admitTurn(); turnErr != nil {
+			releaseManagedTurn()
+			o.conversation.discardActiveTurn(activeTurn.TurnV1.ID)
+			return turnErr
+		}
+
+		runTurn := wrapTurnHandler(func(ctx context.Context, req TurnRequest) (llms.TurnV1, error) {
+			o.conversation.replaceActiveTurnTrigger(activeTurn, req.Trigger)
+			if req.LLM != nil {
+				pipeline.llm.set(req.LLM)
+			}
+			pipeline.llm.systemPrompt = req.SystemPrompt
+			if o.voiceShaping != nil {
+				pipeline.llm.systemPromptAddition = o.voiceShaping.guidance
+			}
+			return pipeline.Run(ctx, activeTurn, req.History)
+		}, turnMiddlewares)
+		req := TurnRequest{Trigger: trigger, History: o.conversation.History(), LLM: pipeline.llm.client}
+		if o.systemPromptProvider != nil {
+			req.SystemPrompt = o.systemPromptProvider(o.conversation.Snapshot(), trigger)
+		}
+		req.History = o.fitContextWindow(ctx, req)
+		activeTurn.TurnV1, turnErr = runTurn(ctx, req)
 		if turnErr != nil {
 			// TODO: We should do something more reasonable here
 			if err2 := o.conversation.finaliseTurn(activeTurn.TurnV1); err2 != nil {
 				turnErr = errors.Join(turnErr, fmt.Errorf("failed to finalise turn: %w", err2))
 			}
+			o.saveTurn(ctx, activeTurn.TurnV1)
 			turnErr = fmt.Errorf("failed to run pipeline: %w", turnErr)
 			return turnErr
 		}
@@ -193,32 +505,59 @@ func (o *Orchestrator) Orchestrate(ctx context.Context, opts ...OrchestrateOptio
 		}
 		span := trace.SpanFromContext(ctx)
 		span.SetAttributes(attribute.StringSlice("assistant_turn.interruptions", interruptionTypes))
-		span.SetAttributes(attribute.Int("assistant_turn.queued_triggers", o.triggerPlayer.queuedTriggerCount()))
+		span.SetAttributes(attribute.Int("assistant_turn.queued_triggers", o.triggerPlayer.Load().queuedTriggerCount()))
+		span.SetAttributes(latencyAttributes(activeTurn.Latency)...)
 
 		if err := o.conversation.finaliseTurn(activeTurn.TurnV1); err != nil {
 			turnErr = fmt.Errorf("failed to finalise turn: %w", err)
 			return turnErr
 		}
+		o.saveTurn(ctx, activeTurn.TurnV1)
+		if err := o.conversation.evictHistory(ctx); err != nil {
+			errorreport.Record(ctx, span, err, "component", "history")
+			o.logger.Warn("failed to evict history", "error", err)
+		}
+
+		if resume, ok := o.interruptionResume.turnEnded(activeTurn.TurnV1); ok {
+			go o.ingestTrigger(resume)
+		}
 
-		if !activeTurn.TurnV1.IsCancelled() {
-			emitEvent(events.NewTurnCompleted(activeTurn.TurnV1.ID))
+		if activeTurn.TurnV1.IsCancelled() {
+			pipeline.state.Transition(TurnStateCancelled)
+		} else if pipeline.state.Transition(TurnStateCompleted) {
+			completed := events.NewTurnCompleted(activeTurn.TurnV1.ID)
+			completed.Latency = events.LatencyReport(activeTurn.Latency)
+			emitEvent(completed)
 		}
 		return nil
 	}); started {
 		go func() {
-			<-ctx.Done()
-			o.Close()
+			select {
+			case <-ctx.Done():
+				o.Close()
+			case <-player.closeCh:
+				// Closed or restarted with another context.
+			}
 		}()
 	}
 
-	if err := o.speechToText.Start(o.baseContext, utils.Ptr(o.audioInput.EncodingInfo())); err != nil {
+	if err := o.speechToText.Start(o.baseContext, utils.Ptr(o.audioInput.EncodingInfo()), o.transcriptionOptions...); err != nil {
 		recordedErr := fmt.Errorf("failed to initialize speech-to-text: %w", err)
 		span := trace.SpanFromContext(o.baseContext)
-		span.RecordError(recordedErr)
+		errorreport.Record(o.baseContext, span, recordedErr, "component", string(ComponentSpeechToText))
 		span.SetStatus(codes.Error, recordedErr.Error())
 	}
+	for speakerID, stt := range o.speakerSpeechToText {
+		if err := stt.Start(o.baseContext, utils.Ptr(o.audioInput.EncodingInfo()), o.transcriptionOptions...); err != nil {
+			recordedErr := fmt.Errorf("failed to initialize speech-to-text of speaker %s: %w", speakerID, err)
+			span := trace.SpanFromContext(o.baseContext)
+			errorreport.Record(o.baseContext, span, recordedErr, "component", string(ComponentSpeechToText), "speaker_id", speakerID)
+			span.SetStatus(codes.Error, recordedErr.Error())
+		}
+	}
 
 	o.audioInput.Start(o.baseContext)
+	return nil
 }
 
 func (o *Orchestrator) composeSTTEventEmitter(emitEvent eventEmitter) eventEmitter {
@@ -227,19 +566,40 @@ func (o *Orchestrator) composeSTTEventEmitter(emitEvent eventEmitter) eventEmitt
 	}
 
 	return func(event events.Event) {
+		if o.compliance.dropsEvent(event) {
+			return
+		}
+		if speechStarted, ok := event.(events.UserSpeechStarted); ok {
+			speechStarted.DuringPlayback = o.isPlayingAudio()
+			o.speechDuringPlayback.Store(speechStarted.DuringPlayback)
+			event = speechStarted
+		}
 		emitEvent(event)
 
 		switch typedEvent := event.(type) {
 		case events.UserSpeechStarted:
-			go o.ingestTrigger(triggers.NewSpeechStartedTrigger())
+			if o.turnTaking != nil {
+				o.turnTaking.speechStarted()
+			}
+			if o.wakeWord != nil {
+				o.wakeWord.speechStarted()
+			}
+			trigger := triggers.NewSpeechStartedTrigger(triggers.WithSpeaker(typedEvent.SpeakerID))
+			trigger.DuringPlayback = typedEvent.DuringPlayback
+			go o.ingestTrigger(trigger)
 		case events.UserSpeechEnded:
-			go o.ingestTrigger(triggers.NewSpeechEndedTrigger())
+			go o.ingestTrigger(triggers.NewSpeechEndedTrigger(triggers.WithSpeaker(typedEvent.SpeakerID)))
 		case events.UserTranscriptInterimUpdated:
 			if typedEvent.Transcript != "" {
-				go o.ingestTrigger(triggers.NewInterimTranscriptionTrigger(typedEvent.Transcript))
+				go o.ingestTrigger(triggers.NewInterimTranscriptionTrigger(typedEvent.Transcript, triggers.WithSpeaker(typedEvent.SpeakerID)))
 			}
 		case events.UserTranscriptFinal:
-			go o.ingestTrigger(triggers.NewTranscriptionTrigger(typedEvent.Transcript))
+			if o.wakeWord != nil {
+				o.wakeWord.transcribed()
+			}
+			trigger := triggers.NewTranscriptionTrigger(typedEvent.Transcript, triggers.WithSpeaker(typedEvent.SpeakerID))
+			trigger.DuringPlayback = o.speechDuringPlayback.Swap(false) || o.isPlayingAudio()
+			go o.ingestTrigger(trigger)
 		}
 	}
 }
@@ -249,11 +609,31 @@ func (o *Orchestrator) composeAudioInputEventEmitter(emitEvent eventEmitter) eve
 		emitEvent = noopEventEmitter
 	}
 
+	detectedSpeech := o.composeSTTEventEmitter(emitEvent)
 	return func(event events.Event) {
+		microphone := o.microphone.state.Load()
+		if inputAudio, ok := event.(events.UserAudioFrame); ok {
+			if microphone == microphoneMuted || o.compliance.dropsAudio() {
+				return
+			}
+			inputAudio.DuringPlayback = o.isPlayingAudio()
+			event = inputAudio
+		}
 		emitEvent(event)
 
-		if inputAudio, ok := event.(events.UserAudioFrame); ok {
+		if inputAudio, ok := event.(events.UserAudioFrame); ok && microphone == microphoneOpen {
+			if o.wakeWord != nil && !o.wakeWord.admits(inputAudio, o.audioInput.EncodingInfo(), o.clock, emitEvent) {
+				return
+			}
 			o.speechToText.SendAudio(inputAudio.Audio)
+			if o.voiceActivityDetector != nil {
+				for _, speech := range o.voiceActivityDetector.ProcessFrame(inputAudio, o.audioInput.EncodingInfo()) {
+					detectedSpeech(speech)
+				}
+			}
+			if o.turnTaking != nil {
+				o.turnTaking.observeAudio(inputAudio.Audio, o.audioInput.EncodingInfo())
+			}
 		}
 	}
 }
@@ -263,15 +643,71 @@ func (o *Orchestrator) ConversationV1() ConversationV1 {
 	return o.conversation.Snapshot()
 }
 
+// TurnState returns the lifecycle state of the turn in progress, or an empty
+// state if no turn is in progress.
+func (o *Orchestrator) TurnState() TurnState {
+	if pipeline := o.currentResponsePipeline(); pipeline != nil {
+		return pipeline.state.Current()
+	}
+	return ""
+}
+
+// AwaitCancelled blocks until a cancelled turn in progress fully stopped:
+// its workers returned, text to speech was cancelled and the audio output
+// cleared, so no audio of the turn reaches the output afterwards. It returns
+// right away if no cancelled turn is in progress, and with the error of ctx
+// if it is done first, e.g.
+//
+//	o.CancelTurn()
+//	ctx, cancel := context.WithTimeout(ctx, time.Second)
+//	defer cancel()
+//	err := o.AwaitCancelled(ctx)
+func (o *Orchestrator) AwaitCancelled(ctx context.Context) error {
+	pipeline := o.currentResponsePipeline()
+	if pipeline == nil || !pipeline.IsCancelled() {
+		return nil
+	}
+
+	select {
+	case <-pipeline.cancelled:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (o *Orchestrator) HandleTrigger(trigger llms.TriggerV0) { o.ingestTrigger(trigger) }
 func (o *Orchestrator) SendPrompt(prompt string) {
 	o.ingestTrigger(triggers.NewUserPromptTrigger(prompt))
 }
+
+// InitiateTurn makes the assistant speak without being prompted, e.g. to open
+// an outbound call or deliver a reminder, following instruction. The turn
+// starts once the active turn, if any, ended and is otherwise like any other:
+// it emits turn_state events, can be barged in on and is kept in the history
+// with a [triggers.AssistantInitiatedTrigger].
+func (o *Orchestrator) InitiateTurn(ctx context.Context, instruction string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !o.triggerPlayer.Load().CanIngest() {
+		return ErrOrchestratorClosed
+	}
+
+	o.ingestTrigger(triggers.NewAssistantInitiatedTrigger(instruction))
+	return nil
+}
+
 func (o *Orchestrator) CancelTurn()  { o.ingestTrigger(triggers.NewCancelTurnTrigger()) }
 func (o *Orchestrator) PauseTurn()   { o.ingestTrigger(triggers.NewPauseTurnTrigger()) }
 func (o *Orchestrator) UnpauseTurn() { o.ingestTrigger(triggers.NewUnpauseTurnTrigger()) }
 
-func (o *Orchestrator) SendAudio(audio []byte) error { return o.speechToText.SendAudio(audio) }
+func (o *Orchestrator) SendAudio(audio []byte) error {
+	if o.IsMicrophoneMuted() || o.compliance.dropsAudio() {
+		return nil
+	}
+	return o.speechToText.SendAudio(audio)
+}
 
 // IsMuted indicates whether the orchestrator is currently passing speech to
 // audio output. True means the orchestrator is currently not passing speech to
@@ -2,6 +2,7 @@ package orchestration
 
 import (
 	"context"
+	"errors"
 	"iter"
 	"sync"
 	"sync/atomic"
@@ -10,19 +11,22 @@ import (
 
 	"github.com/koscakluka/ema-core/core/audio"
 	"github.com/koscakluka/ema-core/core/conversations"
+	events "github.com/koscakluka/ema-core/core/events"
 	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+	"github.com/koscakluka/ema-core/core/triggers"
 )
 
 func TestCloseBeforeOrchestrateMarksClosed(t *testing.T) {
 	o := NewOrchestrator()
 	o.Close()
 
-	if o.triggerPlayer.CanIngest() {
+	if o.triggerPlayer.Load().CanIngest() {
 		t.Fatalf("expected orchestrator to be closed")
 	}
 
 	o.Orchestrate(context.Background())
-	if o.triggerPlayer.CanIngest() {
+	if o.triggerPlayer.Load().CanIngest() {
 		t.Fatalf("expected orchestrator to stay closed")
 	}
 }
@@ -42,7 +46,7 @@ func TestControlOperationsWithoutActivePipelineDoNotPanic(t *testing.T) {
 	o.Mute()
 	o.currentResponsePipeline().Close()
 
-	if !o.triggerPlayer.CanIngest() {
+	if !o.triggerPlayer.Load().CanIngest() {
 		t.Fatalf("expected orchestrator to remain open after no-op control operations")
 	}
 }
@@ -347,6 +351,53 @@ func TestCancelTurnEmitsCancellationCallbackOnce(t *testing.T) {
 	}
 }
 
+func TestAwaitCancelledWaitsUntilTurnStopped(t *testing.T) {
+	output := &bridgeAudioOutputStub{}
+	o := NewOrchestrator(
+		WithStreamingLLM(repeatingStreamLLMStub{chunk: "chunk. ", interval: 5 * time.Millisecond}),
+		WithTextToSpeechClientV1(&bridgeTTSV1Stub{}),
+		WithAudioOutputV1(output),
+	)
+	defer o.Close()
+
+	var cancelledEmitted atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCancelled); ok {
+			cancelledEmitted.Store(true)
+		}
+	}))
+
+	o.SendPrompt("please start")
+	waitForCondition(t, 2*time.Second, "audio reaching output", func() bool { return output.nonEmptyAudioChunks() > 0 })
+
+	o.CancelTurn()
+	awaitCtx, awaitCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer awaitCancel()
+	if err := o.AwaitCancelled(awaitCtx); err != nil {
+		t.Fatalf("expected cancelled turn to stop, got %v", err)
+	}
+
+	if !cancelledEmitted.Load() {
+		t.Fatalf("expected turn cancelled event before AwaitCancelled returned")
+	}
+	chunks := output.nonEmptyAudioChunks()
+	time.Sleep(50 * time.Millisecond)
+	if got := output.nonEmptyAudioChunks(); got != chunks {
+		t.Fatalf("expected no audio after cancellation, got %d more chunks", got-chunks)
+	}
+}
+
+func TestAwaitCancelledWithoutCancelledTurnReturns(t *testing.T) {
+	o := NewOrchestrator()
+	defer o.Close()
+
+	if err := o.AwaitCancelled(context.Background()); err != nil {
+		t.Fatalf("expected no error without a turn in progress, got %v", err)
+	}
+}
+
 func TestInterruptionHandlingSeesUpdatedToolSet(t *testing.T) {
 	handler := &toolSnapshotTriggerHandler{}
 	o := NewOrchestrator(
@@ -578,3 +629,144 @@ func (output *recordingAudioOutput) clearCalls() int {
 	defer output.mu.Unlock()
 	return output.clearCount
 }
+
+type streamRevisionChunkStub struct {
+	revises int
+	content string
+}
+
+func (chunk streamRevisionChunkStub) FinishReason() *string { return nil }
+func (chunk streamRevisionChunkStub) Revises() int          { return chunk.revises }
+func (chunk streamRevisionChunkStub) Content() string       { return chunk.content }
+
+type chunkStreamLLMStub struct {
+	chunks []llms.StreamChunk
+}
+
+func (stub chunkStreamLLMStub) PromptWithStream(context.Context, *string, ...llms.StreamingPromptOption) llms.Stream {
+	return stub
+}
+
+func (stub chunkStreamLLMStub) Chunks(context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		for _, chunk := range stub.chunks {
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestStreamedRevisionsReplaceResponseText(t *testing.T) {
+	runtime := newLLM()
+	runtime.set(chunkStreamLLMStub{chunks: []llms.StreamChunk{
+		streamContentChunkStub{content: "You owe "},
+		streamContentChunkStub{content: "50 euros."},
+		streamRevisionChunkStub{revises: len("50 euros."), content: "15 euros."},
+		streamContentChunkStub{content: " Anything else?"},
+	}})
+	var revisions []string
+	runtime.reviseText = func(replaced, replacement string) {
+		revisions = append(revisions, replaced+"->"+replacement)
+	}
+	var replacedEvents []events.AssistantResponseSegmentReplaced
+	runtime.emitEvent = func(event events.Event) {
+		if event, ok := event.(events.AssistantResponseSegmentReplaced); ok {
+			replacedEvents = append(replacedEvents, event)
+		}
+	}
+
+	response, err := runtime.generate(context.Background(), triggers.NewUserPromptTrigger("bill?"), nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Content != "You owe 15 euros. Anything else?" {
+		t.Fatalf("unexpected revised response %q", response.Content)
+	}
+	if len(revisions) != 1 || revisions[0] != "50 euros.->15 euros." {
+		t.Fatalf("unexpected revisions passed to speech %q", revisions)
+	}
+	if len(replacedEvents) != 1 || replacedEvents[0].Replaced != "50 euros." || replacedEvents[0].Segment != "15 euros." {
+		t.Fatalf("unexpected replaced events %+v", replacedEvents)
+	}
+}
+
+func TestStreamedRevisionsCutAtCharacterBoundaries(t *testing.T) {
+	runtime := newLLM()
+	runtime.set(chunkStreamLLMStub{chunks: []llms.StreamChunk{
+		streamContentChunkStub{content: "Das kostet 50€"},
+		// Revises only the last byte of the euro sign.
+		streamRevisionChunkStub{revises: 1, content: "$"},
+	}})
+	var revisions []string
+	runtime.reviseText = func(replaced, replacement string) {
+		revisions = append(revisions, replaced+"->"+replacement)
+	}
+
+	response, err := runtime.generate(context.Background(), triggers.NewUserPromptTrigger("price?"), nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Content != "Das kostet 50$" {
+		t.Fatalf("unexpected revised response %q", response.Content)
+	}
+	if len(revisions) != 1 || revisions[0] != "€->$" {
+		t.Fatalf("unexpected revisions passed to speech %q", revisions)
+	}
+}
+
+func TestOrchestrateRefusesRepeatedCalls(t *testing.T) {
+	o := NewOrchestrator(WithLLM(&recordingPromptLLMStub{}))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	if err := o.Orchestrate(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := o.Orchestrate(ctx); !errors.Is(err, ErrConversationStarted) {
+		t.Fatalf("expected ErrConversationStarted, got %v", err)
+	}
+	o.Close()
+	if err := o.Orchestrate(ctx); !errors.Is(err, ErrOrchestratorClosed) {
+		t.Fatalf("expected ErrOrchestratorClosed, got %v", err)
+	}
+}
+
+func TestRestartRebuildsTheConversationRuntime(t *testing.T) {
+	var transcribed atomic.Int32
+	stt := &speechToTextClientStub{transcribe: func(speechtotext.TranscriptionOptions) { transcribed.Add(1) }}
+	o := NewOrchestrator(WithLLM(&recordingPromptLLMStub{}), WithSpeechToTextClient(stt))
+	t.Cleanup(o.Close)
+	if err := o.Restart(context.Background()); !errors.Is(err, ErrConversationNotStarted) {
+		t.Fatalf("expected ErrConversationNotStarted before Orchestrate, got %v", err)
+	}
+
+	var before, after atomic.Int32
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	if err := o.Orchestrate(firstCtx, WithResponseEndCallback(func() { before.Add(1) })); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secondCtx, cancelSecond := context.WithCancel(context.Background())
+	t.Cleanup(cancelSecond)
+	if err := o.Restart(secondCtx, WithResponseEndCallback(func() { after.Add(1) })); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := transcribed.Load(); got != 2 {
+		t.Fatalf("expected speech-to-text to be started again, got %d starts", got)
+	}
+
+	cancelFirst()
+	o.SendPrompt("hello")
+	waitForCondition(t, 2*time.Second, "response of the restarted conversation", func() bool { return after.Load() == 1 })
+	if before.Load() != 0 {
+		t.Fatalf("expected the callbacks of the first start to be replaced")
+	}
+
+	o.Close()
+	if err := o.Restart(secondCtx); !errors.Is(err, ErrOrchestratorClosed) {
+		t.Fatalf("expected ErrOrchestratorClosed once closed, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package orchestration
+
+import (
+	"errors"
+	"fmt"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+var ErrTurnNotFound = errors.New("turn not found")
+
+// LabelTurnOutcome attaches outcome to the completed turn turnID, replacing
+// an earlier label. The label is kept in the history, see
+// [llms.TurnV1.Outcome], and reported with an [events.OutcomeLabeled] event.
+// It fails with [ErrTurnNotFound] if no turn of the history has the ID, e.g.
+// because the turn is still in progress.
+func (o *Orchestrator) LabelTurnOutcome(turnID string, outcome llms.OutcomeV0) error {
+	if !o.conversation.labelTurn(turnID, outcome) {
+		return fmt.Errorf("%w: %s", ErrTurnNotFound, turnID)
+	}
+	o.emitEvent(events.NewOutcomeLabeled(turnID, outcome.Label, outcome.Notes, outcome.LabeledBy))
+	return nil
+}
+
+// LabelConversationOutcome attaches outcome to the conversation, replacing an
+// earlier label. The label is kept in the session state, see
+// [SessionStateV0.Outcome], and reported with an [events.OutcomeLabeled]
+// event.
+func (o *Orchestrator) LabelConversationOutcome(outcome llms.OutcomeV0) {
+	o.outcome.Store(&outcome)
+	o.emitEvent(events.NewOutcomeLabeled("", outcome.Label, outcome.Notes, outcome.LabeledBy))
+}
+
+// ConversationOutcome returns the outcome the conversation was labeled with,
+// if any.
+func (o *Orchestrator) ConversationOutcome() (llms.OutcomeV0, bool) {
+	outcome := o.outcome.Load()
+	if outcome == nil {
+		return llms.OutcomeV0{}, false
+	}
+	return *outcome, true
+}
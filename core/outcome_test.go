@@ -0,0 +1,43 @@
+package orchestration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestOutcomeLabelsAreKeptInHistoryAndSessionState(t *testing.T) {
+	o := NewOrchestrator(WithInitialHistory(llms.TurnV1{ID: "turn", Trigger: triggers.NewUserPromptTrigger("refund?")}))
+	defer o.Close()
+
+	if err := o.LabelTurnOutcome("missing", llms.OutcomeV0{Label: llms.OutcomeResolved}); !errors.Is(err, ErrTurnNotFound) {
+		t.Fatalf("expected ErrTurnNotFound, got %v", err)
+	}
+	turnOutcome := llms.OutcomeV0{Label: llms.OutcomeEscalated, Notes: "asked for a human", LabeledBy: "classifier"}
+	if err := o.LabelTurnOutcome("turn", turnOutcome); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conversationOutcome := llms.OutcomeV0{Label: llms.OutcomeResolved, LabeledBy: "agent"}
+	o.LabelConversationOutcome(conversationOutcome)
+
+	encoded, err := json.Marshal(SessionStateV0{History: o.conversation.History(), Outcome: &conversationOutcome})
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	var state SessionStateV0
+	if err := json.Unmarshal(encoded, &state); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+	if len(state.History) != 1 || state.History[0].Outcome == nil || *state.History[0].Outcome != turnOutcome {
+		t.Fatalf("expected the turn outcome to be exported, got %+v", state.History)
+	}
+
+	resumed := NewOrchestrator(WithSessionStateV0(state))
+	defer resumed.Close()
+	if outcome, ok := resumed.ConversationOutcome(); !ok || outcome != conversationOutcome {
+		t.Fatalf("expected the conversation outcome to be restored, got %+v, %v", outcome, ok)
+	}
+}
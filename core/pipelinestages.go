@@ -0,0 +1,191 @@
+package orchestration
+
+import (
+	"context"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// PipelinePosition is where custom stages are inserted into the response
+// pipeline, see [WithPipelineStage].
+type PipelinePosition string
+
+const (
+	// PipelineAfterGeneration receives the response text as streamed by the
+	// LLM, before voice shaping and translation.
+	PipelineAfterGeneration PipelinePosition = "after_generation"
+	// PipelineAfterTextProcessing receives the text passed on to
+	// text-to-speech, after voice shaping and translation.
+	PipelineAfterTextProcessing PipelinePosition = "after_text_processing"
+	// PipelineAfterSpeech receives the audio and the marks synthesized by
+	// text-to-speech, before they are buffered for playback.
+	PipelineAfterSpeech PipelinePosition = "after_speech"
+	// PipelineBeforePlayback receives the audio and the marks passed on to
+	// the audio output.
+	PipelineBeforePlayback PipelinePosition = "before_playback"
+)
+
+// PipelineItemKind is the kind of a [PipelineItemV0].
+type PipelineItemKind string
+
+const (
+	// PipelineItemText carries a piece of the response text.
+	PipelineItemText PipelineItemKind = "text"
+	// PipelineItemRevision replaces text passed on before, see
+	// [llms.StreamRevisionChunk].
+	PipelineItemRevision PipelineItemKind = "revision"
+	// PipelineItemAudio carries a chunk of speech audio.
+	PipelineItemAudio PipelineItemKind = "audio"
+	// PipelineItemMark marks how far speech audio got, it is how the
+	// pipeline knows what of the response was spoken.
+	PipelineItemMark PipelineItemKind = "mark"
+)
+
+// PipelineItemV0 is what flows through the response pipeline: text at the
+// text positions, audio and marks at the speech positions.
+type PipelineItemV0 struct {
+	Kind PipelineItemKind
+	// Text is the text of text items, the replacing text of revisions and
+	// the transcript of marks after speech, empty for the last mark of
+	// legacy text-to-speech clients.
+	Text string
+	// Replaced is the text a revision replaces.
+	Replaced string
+	Audio    []byte
+	// Mark identifies marks before playback.
+	Mark string
+}
+
+// PipelineTurnV0 describes the turn a stage is created for.
+type PipelineTurnV0 struct {
+	ID      string
+	Trigger llms.TriggerV0
+	// EmitEvent emits events of the stage, e.g. visemes generated from the
+	// speech, to the receivers of the orchestrator's events.
+	EmitEvent func(events.Event)
+}
+
+// PipelineStageV0 is a custom stage of the response pipeline of one turn,
+// e.g. a viseme generator for lip sync or a branch dubbing the response
+// into a second language.
+//
+// Stages run inline in the pipeline worker of their position, in the order
+// items arrive. Process passes items on to the next stage with next, any
+// number of them: the item unchanged, a changed one or none. Stages doing
+// slow work should hand it off to their own goroutines instead of holding
+// up the pipeline. Marks must be passed on unchanged and in order, they
+// track what of the response was spoken.
+type PipelineStageV0 interface {
+	Process(item PipelineItemV0, next func(PipelineItemV0))
+	// Flush is called once no more items reach the stage in the turn,
+	// e.g. to pass on buffered text. It is not called for items a
+	// cancelled turn no longer plays.
+	Flush(next func(PipelineItemV0))
+}
+
+// NewPipelineStageV0 creates the stage of a turn. ctx is done once the
+// turn ends.
+type NewPipelineStageV0 func(ctx context.Context, turn PipelineTurnV0) PipelineStageV0
+
+// PipelineStageFunc adapts a function to a [PipelineStageV0] that buffers
+// nothing.
+type PipelineStageFunc func(item PipelineItemV0, next func(PipelineItemV0))
+
+func (f PipelineStageFunc) Process(item PipelineItemV0, next func(PipelineItemV0)) { f(item, next) }
+
+func (f PipelineStageFunc) Flush(func(PipelineItemV0)) {}
+
+// WithPipelineStage inserts the stages newStage creates for each turn at
+// position of the response pipeline. Stages at the same position run in
+// the order they were added. Without custom stages the pipeline is
+// assembled as usual.
+func WithPipelineStage(position PipelinePosition, newStage NewPipelineStageV0) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if o.pipelineStages == nil {
+			o.pipelineStages = map[PipelinePosition][]NewPipelineStageV0{}
+		}
+		o.pipelineStages[position] = append(o.pipelineStages[position], newStage)
+	}
+}
+
+// pipelineStageChain passes items through the stages of one position of a
+// turn. A nil chain passes items straight on.
+type pipelineStageChain struct {
+	stages []PipelineStageV0
+}
+
+func newPipelineStageChains(ctx context.Context, newStages map[PipelinePosition][]NewPipelineStageV0, turn PipelineTurnV0) map[PipelinePosition]*pipelineStageChain {
+	if len(newStages) == 0 {
+		return nil
+	}
+
+	chains := make(map[PipelinePosition]*pipelineStageChain, len(newStages))
+	for position, newStagesAt := range newStages {
+		chain := &pipelineStageChain{}
+		for _, newStage := range newStagesAt {
+			if stage := newStage(ctx, turn); stage != nil {
+				chain.stages = append(chain.stages, stage)
+			}
+		}
+		chains[position] = chain
+	}
+	return chains
+}
+
+func (c *pipelineStageChain) push(item PipelineItemV0, sink func(PipelineItemV0)) {
+	if c == nil {
+		sink(item)
+		return
+	}
+	c.pushFrom(0, item, sink)
+}
+
+func (c *pipelineStageChain) pushFrom(i int, item PipelineItemV0, sink func(PipelineItemV0)) {
+	if i == len(c.stages) {
+		sink(item)
+		return
+	}
+	c.stages[i].Process(item, func(item PipelineItemV0) { c.pushFrom(i+1, item, sink) })
+}
+
+// flush flushes the stages in order, so what a stage passes on is still
+// processed by the stages after it.
+func (c *pipelineStageChain) flush(sink func(PipelineItemV0)) {
+	if c == nil {
+		return
+	}
+	for i, stage := range c.stages {
+		stage.Flush(func(item PipelineItemV0) { c.pushFrom(i+1, item, sink) })
+	}
+}
+
+// text routes text and revisions through the chain to add and revise, it
+// returns the functions to pass them to and to flush the chain with.
+func (c *pipelineStageChain) text(add func(string), revise func(replaced, replacement string)) (func(string), func(replaced, replacement string), func()) {
+	if c == nil {
+		return add, revise, func() {}
+	}
+
+	sink := func(item PipelineItemV0) {
+		switch item.Kind {
+		case PipelineItemText:
+			add(item.Text)
+		case PipelineItemRevision:
+			revise(item.Replaced, item.Text)
+		}
+	}
+	return func(text string) {
+			c.push(PipelineItemV0{Kind: PipelineItemText, Text: text}, sink)
+		}, func(replaced, replacement string) {
+			c.push(PipelineItemV0{Kind: PipelineItemRevision, Text: replacement, Replaced: replaced}, sink)
+		}, func() {
+			c.flush(sink)
+		}
+}
+
+func (p *responsePipeline) stagesAt(position PipelinePosition) *pipelineStageChain {
+	var chain *pipelineStageChain
+	p.rLockFor(func() { chain = p.stageChains[position] })
+	return chain
+}
@@ -0,0 +1,91 @@
+package orchestration
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestPipelineStagesAreInsertedAtTheirPositions(t *testing.T) {
+	output := &bridgeAudioOutputStub{}
+	var playbackMarks atomic.Int32
+	o := NewOrchestrator(
+		WithLLM(promptLLMStub{response: "hello there."}),
+		WithTextToSpeechClientV1(&bridgeTTSV1Stub{}),
+		WithAudioOutputV1(output),
+		WithPipelineStage(PipelineAfterGeneration, func(context.Context, PipelineTurnV0) PipelineStageV0 {
+			return PipelineStageFunc(func(item PipelineItemV0, next func(PipelineItemV0)) {
+				item.Text = strings.ToUpper(item.Text)
+				next(item)
+			})
+		}),
+		WithPipelineStage(PipelineAfterSpeech, func(context.Context, PipelineTurnV0) PipelineStageV0 {
+			return &bufferingStage{}
+		}),
+		WithPipelineStage(PipelineBeforePlayback, func(context.Context, PipelineTurnV0) PipelineStageV0 {
+			return PipelineStageFunc(func(item PipelineItemV0, next func(PipelineItemV0)) {
+				if item.Kind == PipelineItemMark {
+					playbackMarks.Add(1)
+				}
+				next(item)
+			})
+		}),
+	)
+	t.Cleanup(o.Close)
+
+	var completed atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+
+	o.SendPrompt("greet me")
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	output.mu.Lock()
+	var played strings.Builder
+	for _, chunk := range output.audio {
+		played.Write(chunk)
+	}
+	output.mu.Unlock()
+	if played.String() != "HELLO THERE." {
+		t.Fatalf("expected the speech of the processed text to be played, got %q", played.String())
+	}
+	if playbackMarks.Load() == 0 {
+		t.Fatalf("expected marks to reach the playback stage")
+	}
+	history := o.conversation.History()
+	if len(history) != 1 || len(history[0].Responses) != 1 || history[0].Responses[0].SpokenResponse != "HELLO THERE." {
+		t.Fatalf("expected the marks passed through the stages to confirm the spoken text, got %+v", history)
+	}
+}
+
+// bufferingStage holds back everything until the speech ended.
+type bufferingStage struct {
+	mu    sync.Mutex
+	items []PipelineItemV0
+}
+
+func (s *bufferingStage) Process(item PipelineItemV0, _ func(PipelineItemV0)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, item)
+}
+
+func (s *bufferingStage) Flush(next func(PipelineItemV0)) {
+	s.mu.Lock()
+	items := s.items
+	s.items = nil
+	s.mu.Unlock()
+	for _, item := range items {
+		next(item)
+	}
+}
@@ -0,0 +1,47 @@
+package orchestration
+
+import "time"
+
+// PlaybackProgressV0 is how far the response of the current turn was played,
+// approximated from the marks the audio output confirmed and the time since.
+type PlaybackProgressV0 struct {
+	TurnID string
+	// SpokenText is the text of the response played so far.
+	SpokenText string
+	// Remaining is how long the audio received so far takes to finish
+	// playing. It grows while speech is still synthesised, see
+	// IsFullySynthesised.
+	Remaining time.Duration
+	// IsFullySynthesised is whether all audio of the response was received,
+	// Remaining is then the time left until playback ends.
+	IsFullySynthesised bool
+	IsPaused           bool
+}
+
+// PlaybackProgress returns the playback progress of the current turn, e.g. to
+// render a progress bar without reconstructing it from playback events. It
+// returns false if no turn started playing yet.
+func (o *Orchestrator) PlaybackProgress() (PlaybackProgressV0, bool) {
+	pipeline := o.currentResponsePipeline()
+	if pipeline == nil {
+		return PlaybackProgressV0{}, false
+	}
+
+	spokenText, remaining, paused, allAudioLoaded, ok := pipeline.speechPlayer.Progress()
+	if !ok {
+		return PlaybackProgressV0{}, false
+	}
+	var turnID string
+	pipeline.rLockFor(func() {
+		if pipeline.turn != nil {
+			turnID = pipeline.turn.ID
+		}
+	})
+	return PlaybackProgressV0{
+		TurnID:             turnID,
+		SpokenText:         spokenText,
+		Remaining:          remaining,
+		IsFullySynthesised: allAudioLoaded,
+		IsPaused:           paused,
+	}, true
+}
@@ -0,0 +1,31 @@
+package replay
+
+import (
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+)
+
+// Clock is the time source replayed interactions wait on.
+type Clock = clock.Clock
+
+// RealClock returns a [Clock] backed by the time package.
+func RealClock() Clock { return clock.Real() }
+
+// instantClock fires every timer right away, replaying interactions in
+// recorded order without waiting.
+type instantClock struct{}
+
+func (instantClock) Now() time.Time { return time.Time{} }
+func (instantClock) After(time.Duration) <-chan time.Time {
+	fired := make(chan time.Time, 1)
+	fired <- time.Time{}
+	return fired
+}
+
+// FakeClock is a [Clock] that only moves when advanced, letting tests step
+// through replayed timing deterministically.
+type FakeClock = clock.Fake
+
+// NewFakeClock creates a fake clock starting at start.
+func NewFakeClock(start time.Time) *FakeClock { return clock.NewFake(start) }
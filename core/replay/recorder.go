@@ -0,0 +1,291 @@
+package replay
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+// StreamingLLM is the streaming LLM client shape accepted by the
+// orchestrator.
+type StreamingLLM interface {
+	PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream
+}
+
+// TextToSpeech is the speech generator client shape accepted by the
+// orchestrator.
+type TextToSpeech interface {
+	NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error)
+}
+
+// SpeechToText is the transcription client shape accepted by the
+// orchestrator.
+type SpeechToText interface {
+	Transcribe(ctx context.Context, opts ...speechtotext.TranscriptionOption) error
+	SendAudio(audio []byte) error
+}
+
+// Recorder wraps provider clients and records their interactions.
+type Recorder struct {
+	mu        sync.Mutex
+	recording Recording
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Recording returns a copy of everything recorded so far.
+func (r *Recorder) Recording() Recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recording := Recording{
+		LLM: make([]LLMExchange, len(r.recording.LLM)),
+		TTS: make([]TTSExchange, len(r.recording.TTS)),
+		STT: make([]STTSession, len(r.recording.STT)),
+	}
+	for i, exchange := range r.recording.LLM {
+		exchange.Chunks = append([]LLMChunk(nil), exchange.Chunks...)
+		recording.LLM[i] = exchange
+	}
+	for i, exchange := range r.recording.TTS {
+		exchange.Text = append([]string(nil), exchange.Text...)
+		exchange.Events = append([]TTSEvent(nil), exchange.Events...)
+		recording.TTS[i] = exchange
+	}
+	for i, session := range r.recording.STT {
+		session.Events = append([]STTEvent(nil), session.Events...)
+		recording.STT[i] = session
+	}
+	return recording
+}
+
+// LLM wraps client so that every streamed response is recorded.
+func (r *Recorder) LLM(client StreamingLLM) StreamingLLM {
+	return &recordingLLM{recorder: r, client: client}
+}
+
+// TextToSpeech wraps client so that every speech generation is recorded.
+func (r *Recorder) TextToSpeech(client TextToSpeech) TextToSpeech {
+	return &recordingTTS{recorder: r, client: client}
+}
+
+// SpeechToText wraps client so that every transcription is recorded.
+func (r *Recorder) SpeechToText(client SpeechToText) SpeechToText {
+	return &recordingSTT{recorder: r, client: client}
+}
+
+func (r *Recorder) update(f func(recording *Recording)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f(&r.recording)
+}
+
+type recordingLLM struct {
+	recorder *Recorder
+	client   StreamingLLM
+}
+
+func (l *recordingLLM) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
+	var index int
+	l.recorder.update(func(recording *Recording) {
+		exchange := LLMExchange{Chunks: []LLMChunk{}}
+		if prompt != nil {
+			exchange.Prompt = new(string)
+			*exchange.Prompt = *prompt
+		}
+		index = len(recording.LLM)
+		recording.LLM = append(recording.LLM, exchange)
+	})
+
+	return &recordingStream{
+		recorder: l.recorder,
+		index:    index,
+		stream:   l.client.PromptWithStream(ctx, prompt, opts...),
+	}
+}
+
+type recordingStream struct {
+	recorder *Recorder
+	index    int
+	stream   llms.Stream
+}
+
+func (s *recordingStream) Chunks(ctx context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		start := time.Now()
+		for chunk, err := range s.stream.Chunks(ctx) {
+			s.recorder.update(func(recording *Recording) {
+				exchange := &recording.LLM[s.index]
+				if err != nil {
+					exchange.Error = err.Error()
+					return
+				}
+				exchange.Chunks = append(exchange.Chunks, recordChunk(chunk, time.Since(start)))
+			})
+			if !yield(chunk, err) {
+				return
+			}
+		}
+	}
+}
+
+func recordChunk(chunk llms.StreamChunk, offset time.Duration) LLMChunk {
+	recorded := LLMChunk{Offset: offset}
+	if chunk == nil {
+		return recorded
+	}
+
+	recorded.FinishReason = chunk.FinishReason()
+	switch chunk := chunk.(type) {
+	case llms.StreamContentChunk:
+		content := chunk.Content()
+		recorded.Content = &content
+	case llms.StreamToolCallChunk:
+		toolCall := chunk.ToolCall()
+		recorded.ToolCall = &toolCall
+	case llms.StreamUsageChunk:
+		usage := chunk.Usage()
+		recorded.Usage = &usage
+	}
+	return recorded
+}
+
+type recordingTTS struct {
+	recorder *Recorder
+	client   TextToSpeech
+}
+
+func (t *recordingTTS) NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
+	options := texttospeech.TextToSpeechOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var index int
+	t.recorder.update(func(recording *Recording) {
+		index = len(recording.TTS)
+		recording.TTS = append(recording.TTS, TTSExchange{Text: []string{}, Events: []TTSEvent{}})
+	})
+	start := time.Now()
+	record := func(event TTSEvent) {
+		event.Offset = time.Since(start)
+		t.recorder.update(func(recording *Recording) {
+			recording.TTS[index].Events = append(recording.TTS[index].Events, event)
+		})
+	}
+
+	generator, err := t.client.NewSpeechGeneratorV0(ctx, append(opts,
+		texttospeech.WithSpeechAudioCallback(func(audio []byte) {
+			record(TTSEvent{Audio: append([]byte(nil), audio...)})
+			if options.SpeechAudioCallback != nil {
+				options.SpeechAudioCallback(audio)
+			}
+		}),
+		texttospeech.WithSpeechMarkCallback(func(transcript string) {
+			record(TTSEvent{Mark: &transcript})
+			if options.SpeechMarkCallback != nil {
+				options.SpeechMarkCallback(transcript)
+			}
+		}),
+		texttospeech.WithSpeechEndedCallbackV0(func(report texttospeech.SpeechEndedReport) {
+			record(TTSEvent{Ended: true})
+			if options.SpeechEndedCallbackV0 != nil {
+				options.SpeechEndedCallbackV0(report)
+			}
+		}),
+		texttospeech.WithErrorCallback(func(err error) {
+			record(TTSEvent{Error: err.Error()})
+			if options.ErrorCallback != nil {
+				options.ErrorCallback(err)
+			}
+		}),
+	)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &recordingSpeechGenerator{SpeechGeneratorV0: generator, recorder: t.recorder, index: index}, nil
+}
+
+type recordingSpeechGenerator struct {
+	texttospeech.SpeechGeneratorV0
+	recorder *Recorder
+	index    int
+}
+
+func (g *recordingSpeechGenerator) SendText(text string) error {
+	g.recorder.update(func(recording *Recording) {
+		recording.TTS[g.index].Text = append(recording.TTS[g.index].Text, text)
+	})
+	return g.SpeechGeneratorV0.SendText(text)
+}
+
+type recordingSTT struct {
+	recorder *Recorder
+	client   SpeechToText
+}
+
+func (s *recordingSTT) Transcribe(ctx context.Context, opts ...speechtotext.TranscriptionOption) error {
+	options := speechtotext.TranscriptionOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var index int
+	s.recorder.update(func(recording *Recording) {
+		index = len(recording.STT)
+		recording.STT = append(recording.STT, STTSession{Events: []STTEvent{}})
+	})
+	start := time.Now()
+	record := func(eventType STTEventType, transcript string) {
+		event := STTEvent{Offset: time.Since(start), Type: eventType, Transcript: transcript}
+		s.recorder.update(func(recording *Recording) {
+			recording.STT[index].Events = append(recording.STT[index].Events, event)
+		})
+	}
+
+	return s.client.Transcribe(ctx, append(opts, recordTranscriptionCallbacks(options, record)...)...)
+}
+
+func (s *recordingSTT) SendAudio(audio []byte) error {
+	return s.client.SendAudio(audio)
+}
+
+// recordTranscriptionCallbacks wraps the callbacks set in options, callbacks
+// that are not set stay unset so the client keeps its behaviour.
+func recordTranscriptionCallbacks(options speechtotext.TranscriptionOptions, record func(STTEventType, string)) []speechtotext.TranscriptionOption {
+	var wrapped []speechtotext.TranscriptionOption
+	wrapTranscript := func(callback func(string), eventType STTEventType, option func(func(string)) speechtotext.TranscriptionOption) {
+		if callback == nil {
+			return
+		}
+		wrapped = append(wrapped, option(func(transcript string) {
+			record(eventType, transcript)
+			callback(transcript)
+		}))
+	}
+	wrapTranscript(options.PartialInterimTranscriptionCallback, STTPartialInterim, speechtotext.WithPartialInterimTranscriptionCallback)
+	wrapTranscript(options.InterimTranscriptionCallback, STTInterim, speechtotext.WithInterimTranscriptionCallback)
+	wrapTranscript(options.PartialTranscriptionCallback, STTPartialTranscription, speechtotext.WithPartialTranscriptionCallback)
+	wrapTranscript(options.TranscriptionCallback, STTTranscription, speechtotext.WithTranscriptionCallback)
+
+	if callback := options.SpeechStartedCallback; callback != nil {
+		wrapped = append(wrapped, speechtotext.WithSpeechStartedCallback(func() {
+			record(STTSpeechStarted, "")
+			callback()
+		}))
+	}
+	if callback := options.SpeechEndedCallback; callback != nil {
+		wrapped = append(wrapped, speechtotext.WithSpeechEndedCallback(func() {
+			record(STTSpeechEnded, "")
+			callback()
+		}))
+	}
+	return wrapped
+}
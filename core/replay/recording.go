@@ -0,0 +1,130 @@
+// Package replay records provider interactions and replays them
+// deterministically, so pipeline behaviour can be tested without live keys
+// or timing-sensitive tests.
+//
+// Wrap the real clients with a [Recorder] once and save the [Recording] as a
+// fixture:
+//
+//	recorder := replay.NewRecorder()
+//	o := orchestration.NewOrchestrator(
+//		orchestration.WithStreamingLLM(recorder.LLM(llm)),
+//		orchestration.WithTextToSpeechClientV1(recorder.TextToSpeech(tts)),
+//		orchestration.WithSpeechToTextClient(recorder.SpeechToText(stt)),
+//	)
+//	// ... run the conversation ...
+//	_ = recorder.Recording().WriteFile("testdata/greeting.json")
+//
+// and replay it in CI:
+//
+//	recording, _ := replay.ReadFile("testdata/greeting.json")
+//	replayer := replay.NewReplayer(recording)
+//	o := orchestration.NewOrchestrator(
+//		orchestration.WithStreamingLLM(replayer.LLM()),
+//		orchestration.WithTextToSpeechClientV1(replayer.TextToSpeech()),
+//		orchestration.WithSpeechToTextClient(replayer.SpeechToText()),
+//	)
+//
+// Replayed interactions are served in recorded order and, unless a [Clock]
+// is set with [WithClock], without waiting for the recorded delays.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// Recording holds the provider interactions of a single run.
+type Recording struct {
+	LLM []LLMExchange `json:"llm,omitempty"`
+	TTS []TTSExchange `json:"tts,omitempty"`
+	STT []STTSession  `json:"stt,omitempty"`
+}
+
+// LLMExchange is a single streamed LLM response.
+type LLMExchange struct {
+	Prompt *string    `json:"prompt,omitempty"`
+	Chunks []LLMChunk `json:"chunks"`
+	// Error is set if the stream failed after Chunks were received.
+	Error string `json:"error,omitempty"`
+}
+
+// LLMChunk is a streamed LLM chunk received Offset after the stream started.
+type LLMChunk struct {
+	Offset       time.Duration  `json:"offset"`
+	Content      *string        `json:"content,omitempty"`
+	ToolCall     *llms.ToolCall `json:"tool_call,omitempty"`
+	Usage        *llms.Usage    `json:"usage,omitempty"`
+	FinishReason *string        `json:"finish_reason,omitempty"`
+}
+
+// TTSExchange is a single speech generation.
+type TTSExchange struct {
+	// Text is the text sent for generation, in order.
+	Text   []string   `json:"text"`
+	Events []TTSEvent `json:"events"`
+}
+
+// TTSEvent is a speech generation callback that happened Offset after the
+// generator was created. Exactly one of Audio, Mark, Ended or Error is set.
+type TTSEvent struct {
+	Offset time.Duration `json:"offset"`
+	Audio  []byte        `json:"audio,omitempty"`
+	Mark   *string       `json:"mark,omitempty"`
+	Ended  bool          `json:"ended,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// STTSession is a single transcription stream.
+type STTSession struct {
+	Events []STTEvent `json:"events"`
+}
+
+// STTEventType identifies the transcription callback of an [STTEvent].
+type STTEventType string
+
+const (
+	STTSpeechStarted        STTEventType = "speech_started"
+	STTSpeechEnded          STTEventType = "speech_ended"
+	STTPartialInterim       STTEventType = "partial_interim"
+	STTInterim              STTEventType = "interim"
+	STTPartialTranscription STTEventType = "partial"
+	STTTranscription        STTEventType = "final"
+)
+
+// STTEvent is a transcription callback that happened Offset after the
+// transcription started.
+type STTEvent struct {
+	Offset     time.Duration `json:"offset"`
+	Type       STTEventType  `json:"type"`
+	Transcript string        `json:"transcript,omitempty"`
+}
+
+// ReadFile reads a recording written by [Recording.WriteFile].
+func ReadFile(path string) (Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Recording{}, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	var recording Recording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return Recording{}, fmt.Errorf("failed to decode recording: %w", err)
+	}
+	return recording, nil
+}
+
+// WriteFile writes the recording to path as indented JSON.
+func (r Recording) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
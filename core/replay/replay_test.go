@@ -0,0 +1,284 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+type stubStream struct {
+	chunks []llms.StreamChunk
+}
+
+func (s stubStream) Chunks(context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		for _, chunk := range s.chunks {
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+}
+
+type stubLLM struct {
+	chunks []llms.StreamChunk
+}
+
+func (l stubLLM) PromptWithStream(context.Context, *string, ...llms.StreamingPromptOption) llms.Stream {
+	return stubStream{chunks: l.chunks}
+}
+
+type stubTTS struct{}
+
+func (stubTTS) NewSpeechGeneratorV0(_ context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
+	options := texttospeech.TextToSpeechOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &stubSpeechGenerator{options: options}, nil
+}
+
+type stubSpeechGenerator struct {
+	options texttospeech.TextToSpeechOptions
+	text    string
+}
+
+func (g *stubSpeechGenerator) SendText(text string) error {
+	g.text += text
+	g.options.SpeechAudioCallback([]byte(text))
+	return nil
+}
+
+func (g *stubSpeechGenerator) Mark() error {
+	g.options.SpeechMarkCallback(g.text)
+	return nil
+}
+
+func (g *stubSpeechGenerator) EndOfText() error {
+	g.options.SpeechEndedCallbackV0(texttospeech.SpeechEndedReport{})
+	return nil
+}
+
+func (g *stubSpeechGenerator) Cancel() error { return nil }
+func (g *stubSpeechGenerator) Close() error  { return nil }
+
+type stubSTT struct{}
+
+func (stubSTT) Transcribe(_ context.Context, opts ...speechtotext.TranscriptionOption) error {
+	options := speechtotext.TranscriptionOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.SpeechStartedCallback()
+	options.InterimTranscriptionCallback("hel")
+	options.TranscriptionCallback("hello")
+	options.SpeechEndedCallback()
+	return nil
+}
+
+func (stubSTT) SendAudio([]byte) error { return nil }
+
+func collectContent(t *testing.T, stream llms.Stream) string {
+	t.Helper()
+	var content string
+	for chunk, err := range stream.Chunks(context.Background()) {
+		if err != nil {
+			t.Fatalf("unexpected stream error: %v", err)
+		}
+		if chunk, ok := chunk.(llms.StreamContentChunk); ok {
+			content += chunk.Content()
+		}
+	}
+	return content
+}
+
+func TestRecordAndReplayLLM(t *testing.T) {
+	stop := "stop"
+	recorder := NewRecorder()
+	llm := recorder.LLM(stubLLM{chunks: []llms.StreamChunk{
+		contentChunk{content: "Hello"},
+		toolCallChunk{toolCall: llms.ToolCall{ID: "call-1", Name: "lookup"}},
+		contentChunk{chunkBase: chunkBase{finishReason: &stop}, content: " there"},
+	}})
+	if got := collectContent(t, llm.PromptWithStream(context.Background(), nil)); got != "Hello there" {
+		t.Fatalf("expected recorded stream to pass content through, got %q", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "recording.json")
+	if err := recorder.Recording().WriteFile(path); err != nil {
+		t.Fatalf("failed to write recording: %v", err)
+	}
+	recording, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read recording: %v", err)
+	}
+
+	replayed := NewReplayer(recording).LLM()
+	var toolCalls []llms.ToolCall
+	var finishReason *string
+	var content string
+	for chunk, err := range replayed.PromptWithStream(context.Background(), nil).Chunks(context.Background()) {
+		if err != nil {
+			t.Fatalf("unexpected replay error: %v", err)
+		}
+		switch chunk := chunk.(type) {
+		case llms.StreamContentChunk:
+			content += chunk.Content()
+		case llms.StreamToolCallChunk:
+			toolCalls = append(toolCalls, chunk.ToolCall())
+		}
+		if chunk.FinishReason() != nil {
+			finishReason = chunk.FinishReason()
+		}
+	}
+	if content != "Hello there" {
+		t.Fatalf("expected replayed content %q, got %q", "Hello there", content)
+	}
+	if len(toolCalls) != 1 || toolCalls[0].Name != "lookup" {
+		t.Fatalf("expected replayed lookup tool call, got %+v", toolCalls)
+	}
+	if finishReason == nil || *finishReason != stop {
+		t.Fatalf("expected replayed finish reason %q, got %v", stop, finishReason)
+	}
+
+	for _, err := range replayed.PromptWithStream(context.Background(), nil).Chunks(context.Background()) {
+		if !errors.Is(err, ErrExhausted) {
+			t.Fatalf("expected exhausted error after the last exchange, got %v", err)
+		}
+	}
+}
+
+func TestReplayLLMWaitsOnClock(t *testing.T) {
+	first, second := "a", "b"
+	recording := Recording{LLM: []LLMExchange{{Chunks: []LLMChunk{
+		{Offset: 100 * time.Millisecond, Content: &first},
+		{Offset: 300 * time.Millisecond, Content: &second},
+	}}}}
+	clock := NewFakeClock(time.Unix(0, 0))
+	stream := NewReplayer(recording, WithClock(clock)).LLM().PromptWithStream(context.Background(), nil)
+
+	received := make(chan string, 2)
+	go func() {
+		for chunk := range stream.Chunks(context.Background()) {
+			received <- chunk.(llms.StreamContentChunk).Content()
+		}
+		close(received)
+	}()
+
+	clock.BlockUntil(1)
+	assertNothingReceived(t, received)
+	clock.Advance(100 * time.Millisecond)
+	if got := <-received; got != first {
+		t.Fatalf("expected %q after first offset, got %q", first, got)
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(199 * time.Millisecond)
+	assertNothingReceived(t, received)
+	clock.Advance(time.Millisecond)
+	if got := <-received; got != second {
+		t.Fatalf("expected %q after second offset, got %q", second, got)
+	}
+	if _, ok := <-received; ok {
+		t.Fatal("expected stream to end after the recorded chunks")
+	}
+}
+
+func assertNothingReceived[T any](t *testing.T, received <-chan T) {
+	t.Helper()
+	select {
+	case value := <-received:
+		t.Fatalf("expected nothing before the clock advanced, got %v", value)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestRecordAndReplayTextToSpeech(t *testing.T) {
+	recorder := NewRecorder()
+	tts := recorder.TextToSpeech(stubTTS{})
+	generator, err := tts.NewSpeechGeneratorV0(context.Background(),
+		texttospeech.WithSpeechAudioCallback(func([]byte) {}),
+		texttospeech.WithSpeechMarkCallback(func(string) {}),
+		texttospeech.WithSpeechEndedCallbackV0(func(texttospeech.SpeechEndedReport) {}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create recorded generator: %v", err)
+	}
+	_ = generator.SendText("Hi.")
+	_ = generator.Mark()
+	_ = generator.EndOfText()
+
+	recording := recorder.Recording()
+	if len(recording.TTS) != 1 || !reflect.DeepEqual(recording.TTS[0].Text, []string{"Hi."}) {
+		t.Fatalf("expected sent text to be recorded, got %+v", recording.TTS)
+	}
+
+	replayed := make(chan string, 3)
+	generator, err = NewReplayer(recording).TextToSpeech().NewSpeechGeneratorV0(context.Background(),
+		texttospeech.WithSpeechAudioCallback(func(audio []byte) { replayed <- "audio:" + string(audio) }),
+		texttospeech.WithSpeechMarkCallback(func(mark string) { replayed <- "mark:" + mark }),
+		texttospeech.WithSpeechEndedCallbackV0(func(texttospeech.SpeechEndedReport) { replayed <- "ended" }),
+	)
+	if err != nil {
+		t.Fatalf("failed to create replayed generator: %v", err)
+	}
+	defer generator.Close()
+
+	assertNothingReceived(t, replayed)
+	_ = generator.SendText("Hi.")
+	if got := <-replayed; got != "audio:Hi." {
+		t.Fatalf("expected audio once text is sent, got %q", got)
+	}
+	assertNothingReceived(t, replayed)
+	_ = generator.Mark()
+	if got := <-replayed; got != "mark:Hi." {
+		t.Fatalf("expected mark once requested, got %q", got)
+	}
+	assertNothingReceived(t, replayed)
+	_ = generator.EndOfText()
+	if got := <-replayed; got != "ended" {
+		t.Fatalf("expected speech to end after end of text, got %q", got)
+	}
+}
+
+func TestRecordAndReplaySpeechToText(t *testing.T) {
+	var recorded []string
+	recordingOptions := []speechtotext.TranscriptionOption{
+		speechtotext.WithSpeechStartedCallback(func() { recorded = append(recorded, "started") }),
+		speechtotext.WithInterimTranscriptionCallback(func(transcript string) { recorded = append(recorded, "interim:"+transcript) }),
+		speechtotext.WithTranscriptionCallback(func(transcript string) { recorded = append(recorded, "final:"+transcript) }),
+		speechtotext.WithSpeechEndedCallback(func() { recorded = append(recorded, "ended") }),
+	}
+	recorder := NewRecorder()
+	if err := recorder.SpeechToText(stubSTT{}).Transcribe(context.Background(), recordingOptions...); err != nil {
+		t.Fatalf("failed to transcribe: %v", err)
+	}
+
+	replayed := make(chan string, 4)
+	stt := NewReplayer(recorder.Recording()).SpeechToText()
+	err := stt.Transcribe(context.Background(),
+		speechtotext.WithSpeechStartedCallback(func() { replayed <- "started" }),
+		speechtotext.WithInterimTranscriptionCallback(func(transcript string) { replayed <- "interim:" + transcript }),
+		speechtotext.WithTranscriptionCallback(func(transcript string) { replayed <- "final:" + transcript }),
+		speechtotext.WithSpeechEndedCallback(func() { replayed <- "ended" }),
+	)
+	if err != nil {
+		t.Fatalf("failed to replay transcription: %v", err)
+	}
+
+	for i, expected := range recorded {
+		if got := <-replayed; got != expected {
+			t.Fatalf("expected replayed event %d to be %q, got %q", i, expected, got)
+		}
+	}
+	if err := stt.Transcribe(context.Background()); !errors.Is(err, ErrExhausted) {
+		t.Fatalf("expected exhausted error after the last session, got %v", err)
+	}
+}
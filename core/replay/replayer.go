@@ -0,0 +1,390 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+// ErrExhausted is returned when a replayed client is used more times than
+// the recording covers.
+var ErrExhausted = errors.New("recording exhausted")
+
+// Replayer serves recorded interactions in place of provider clients.
+type Replayer struct {
+	mu        sync.Mutex
+	recording Recording
+	clock     Clock
+
+	nextLLM int
+	nextTTS int
+	nextSTT int
+}
+
+type ReplayerOptions struct {
+	clock Clock
+}
+
+type ReplayerOption func(*ReplayerOptions)
+
+// WithClock sets the clock recorded delays are waited on. By default
+// interactions are replayed without any delay; use [RealClock] to replay
+// them in real time or a [FakeClock] to step through them.
+func WithClock(clock Clock) ReplayerOption {
+	return func(o *ReplayerOptions) {
+		o.clock = clock
+	}
+}
+
+func NewReplayer(recording Recording, opts ...ReplayerOption) *Replayer {
+	options := ReplayerOptions{clock: instantClock{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Replayer{recording: recording, clock: options.clock}
+}
+
+// LLM returns a streaming LLM client serving the recorded responses in
+// order.
+func (r *Replayer) LLM() StreamingLLM {
+	return &replayLLM{replayer: r}
+}
+
+// TextToSpeech returns a speech generator client serving the recorded
+// speech in order.
+func (r *Replayer) TextToSpeech() TextToSpeech {
+	return &replayTTS{replayer: r}
+}
+
+// SpeechToText returns a transcription client serving the recorded
+// transcriptions in order. Audio sent to it is discarded.
+func (r *Replayer) SpeechToText() SpeechToText {
+	return &replaySTT{replayer: r}
+}
+
+// wait blocks for the time between two recorded offsets.
+func (r *Replayer) wait(ctx context.Context, from, to time.Duration) error {
+	select {
+	case <-r.clock.After(to - from):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type replayLLM struct {
+	replayer *Replayer
+}
+
+func (l *replayLLM) PromptWithStream(ctx context.Context, prompt *string, opts ...llms.StreamingPromptOption) llms.Stream {
+	l.replayer.mu.Lock()
+	defer l.replayer.mu.Unlock()
+
+	if l.replayer.nextLLM >= len(l.replayer.recording.LLM) {
+		return &replayStream{replayer: l.replayer, err: fmt.Errorf("llm: %w", ErrExhausted)}
+	}
+	exchange := l.replayer.recording.LLM[l.replayer.nextLLM]
+	l.replayer.nextLLM++
+	return &replayStream{replayer: l.replayer, exchange: exchange}
+}
+
+type replayStream struct {
+	replayer *Replayer
+	exchange LLMExchange
+	err      error
+}
+
+func (s *replayStream) Chunks(ctx context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		if s.err != nil {
+			yield(nil, s.err)
+			return
+		}
+
+		var offset time.Duration
+		for _, chunk := range s.exchange.Chunks {
+			if err := s.replayer.wait(ctx, offset, chunk.Offset); err != nil {
+				yield(nil, err)
+				return
+			}
+			offset = chunk.Offset
+			if !yield(replayChunk(chunk), nil) {
+				return
+			}
+		}
+		if s.exchange.Error != "" {
+			yield(nil, errors.New(s.exchange.Error))
+		}
+	}
+}
+
+func replayChunk(chunk LLMChunk) llms.StreamChunk {
+	base := chunkBase{finishReason: chunk.FinishReason}
+	switch {
+	case chunk.Content != nil:
+		return contentChunk{chunkBase: base, content: *chunk.Content}
+	case chunk.ToolCall != nil:
+		return toolCallChunk{chunkBase: base, toolCall: *chunk.ToolCall}
+	case chunk.Usage != nil:
+		return usageChunk{chunkBase: base, usage: *chunk.Usage}
+	}
+	return base
+}
+
+type chunkBase struct {
+	finishReason *string
+}
+
+func (c chunkBase) FinishReason() *string { return c.finishReason }
+
+type contentChunk struct {
+	chunkBase
+	content string
+}
+
+func (c contentChunk) Content() string { return c.content }
+
+type toolCallChunk struct {
+	chunkBase
+	toolCall llms.ToolCall
+}
+
+func (c toolCallChunk) ToolCall() llms.ToolCall { return c.toolCall }
+
+type usageChunk struct {
+	chunkBase
+	usage llms.Usage
+}
+
+func (c usageChunk) Usage() llms.Usage { return c.usage }
+
+type replayTTS struct {
+	replayer *Replayer
+}
+
+func (t *replayTTS) NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
+	t.replayer.mu.Lock()
+	if t.replayer.nextTTS >= len(t.replayer.recording.TTS) {
+		t.replayer.mu.Unlock()
+		return nil, fmt.Errorf("text to speech: %w", ErrExhausted)
+	}
+	exchange := t.replayer.recording.TTS[t.replayer.nextTTS]
+	t.replayer.nextTTS++
+	t.replayer.mu.Unlock()
+
+	options := texttospeech.TextToSpeechOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	g := &replaySpeechGenerator{
+		options: options,
+		cancel:  cancel,
+		signal:  make(chan struct{}, 1),
+	}
+	go g.replay(ctx, t.replayer, exchange.Events)
+
+	return g, nil
+}
+
+// replaySpeechGenerator replays recorded speech events, holding back audio
+// until text is sent, marks until they are requested and the end of speech
+// until the end of text is signalled.
+type replaySpeechGenerator struct {
+	options texttospeech.TextToSpeechOptions
+	cancel  context.CancelFunc
+
+	mu        sync.Mutex
+	textSent  bool
+	marks     int
+	endOfText bool
+	closed    bool
+	signal    chan struct{}
+}
+
+func (g *replaySpeechGenerator) SendText(string) error {
+	return g.update(func() error {
+		if g.endOfText {
+			return fmt.Errorf("end of text already sent")
+		}
+		g.textSent = true
+		return nil
+	})
+}
+
+func (g *replaySpeechGenerator) Mark() error {
+	return g.update(func() error {
+		if g.endOfText {
+			return fmt.Errorf("end of text already sent")
+		}
+		g.marks++
+		return nil
+	})
+}
+
+func (g *replaySpeechGenerator) EndOfText() error {
+	return g.update(func() error {
+		g.endOfText = true
+		return nil
+	})
+}
+
+func (g *replaySpeechGenerator) Cancel() error {
+	return g.Close()
+}
+
+func (g *replaySpeechGenerator) Close() error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+	g.cancel()
+	return nil
+}
+
+func (g *replaySpeechGenerator) update(f func() error) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return fmt.Errorf("speech generator closed")
+	}
+	if err := f(); err != nil {
+		return err
+	}
+	select {
+	case g.signal <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// await blocks until ready reports true or ctx is done.
+func (g *replaySpeechGenerator) await(ctx context.Context, ready func() bool) error {
+	for {
+		g.mu.Lock()
+		ok := ready()
+		g.mu.Unlock()
+		if ok {
+			return nil
+		}
+		select {
+		case <-g.signal:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (g *replaySpeechGenerator) replay(ctx context.Context, replayer *Replayer, events []TTSEvent) {
+	defer g.cancel()
+
+	if err := g.await(ctx, func() bool { return g.textSent || g.endOfText }); err != nil {
+		return
+	}
+
+	var offset time.Duration
+	var marks int
+	for _, event := range events {
+		if err := replayer.wait(ctx, offset, event.Offset); err != nil {
+			return
+		}
+		offset = event.Offset
+
+		switch {
+		case event.Mark != nil:
+			marks++
+			if err := g.await(ctx, func() bool { return g.marks >= marks }); err != nil {
+				return
+			}
+			if g.options.SpeechMarkCallback != nil {
+				g.options.SpeechMarkCallback(*event.Mark)
+			}
+		case event.Ended:
+			if err := g.await(ctx, func() bool { return g.endOfText }); err != nil {
+				return
+			}
+			if g.options.SpeechEndedCallbackV0 != nil {
+				g.options.SpeechEndedCallbackV0(texttospeech.SpeechEndedReport{})
+			}
+		case event.Error != "":
+			if g.options.ErrorCallback != nil {
+				g.options.ErrorCallback(errors.New(event.Error))
+			}
+		default:
+			if g.options.SpeechAudioCallback != nil {
+				g.options.SpeechAudioCallback(event.Audio)
+			}
+		}
+	}
+}
+
+type replaySTT struct {
+	replayer *Replayer
+}
+
+func (s *replaySTT) Transcribe(ctx context.Context, opts ...speechtotext.TranscriptionOption) error {
+	s.replayer.mu.Lock()
+	if s.replayer.nextSTT >= len(s.replayer.recording.STT) {
+		s.replayer.mu.Unlock()
+		return fmt.Errorf("speech to text: %w", ErrExhausted)
+	}
+	session := s.replayer.recording.STT[s.replayer.nextSTT]
+	s.replayer.nextSTT++
+	s.replayer.mu.Unlock()
+
+	options := speechtotext.TranscriptionOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	go func() {
+		var offset time.Duration
+		for _, event := range session.Events {
+			if err := s.replayer.wait(ctx, offset, event.Offset); err != nil {
+				return
+			}
+			offset = event.Offset
+			emitTranscriptionEvent(options, event)
+		}
+	}()
+
+	return nil
+}
+
+func (s *replaySTT) SendAudio([]byte) error {
+	return nil
+}
+
+func emitTranscriptionEvent(options speechtotext.TranscriptionOptions, event STTEvent) {
+	var callback func(string)
+	switch event.Type {
+	case STTSpeechStarted:
+		if options.SpeechStartedCallback != nil {
+			options.SpeechStartedCallback()
+		}
+		return
+	case STTSpeechEnded:
+		if options.SpeechEndedCallback != nil {
+			options.SpeechEndedCallback()
+		}
+		return
+	case STTPartialInterim:
+		callback = options.PartialInterimTranscriptionCallback
+	case STTInterim:
+		callback = options.InterimTranscriptionCallback
+	case STTPartialTranscription:
+		callback = options.PartialTranscriptionCallback
+	case STTTranscription:
+		callback = options.TranscriptionCallback
+	}
+	if callback != nil {
+		callback(event.Transcript)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/koscakluka/ema-core/core/errorreport"
 	events "github.com/koscakluka/ema-core/core/events"
 	"github.com/koscakluka/ema-core/core/llms"
 	"go.opentelemetry.io/otel/attribute"
@@ -20,6 +21,11 @@ const defaultSpeechPlayerSegmentationBoundaries = "?.!"
 type responsePipeline struct {
 	ctxMu sync.RWMutex
 	ctx   context.Context
+	// stopGenerating cancels the context of the LLM generation, so provider
+	// calls and tool executions of a cancelled turn don't keep running.
+	stopGenerating context.CancelFunc
+	// turn is the active turn the pipeline responds to.
+	turn *activeTurn
 
 	llm          llm
 	textToSpeech *textToSpeech
@@ -27,8 +33,40 @@ type responsePipeline struct {
 	audioOutput  *audioOutput
 
 	emitEvent eventEmitter
-
-	cancelled atomic.Bool
+	latency   *turnLatency
+
+	state *turnStateMachine
+
+	// degradation is shared across turns, textOnly is set once a speech
+	// component failed in this turn.
+	degradation *speechDegradation
+	textOnly    atomic.Bool
+
+	// translation translates the response before it is spoken, if set.
+	translation *translation
+	// voiceShaping shapes the response before it is translated, if set.
+	voiceShaping *VoiceShapingOptions
+	// stallDetection watches the turn when set, progress is reported by
+	// the workers, see [WithStallDetection].
+	stallDetection *stallDetection
+	progress       *pipelineProgress
+	// stages creates the custom stages of the turn, see
+	// [WithPipelineStage]. stageChains holds them once the turn started.
+	stages      map[PipelinePosition][]NewPipelineStageV0
+	stageChains map[PipelinePosition]*pipelineStageChain
+
+	// stopped closes once the turn of the pipeline ended and cancelled once
+	// a cancelled turn fully stopped, see [Orchestrator.AwaitCancelled].
+	stopped    chan struct{}
+	stopOnce   sync.Once
+	cancelled  chan struct{}
+	cancelOnce sync.Once
+
+	// spokenMu orders confirmed spoken text before the turn is finalised,
+	// output marks are confirmed asynchronously and the last one unblocks
+	// finalisation. It guards the text of the response, so checkpoints see
+	// the spoken and generated text together.
+	spokenMu sync.Mutex
 }
 
 func newResponsePipeline(
@@ -55,6 +93,9 @@ func newResponsePipeline(
 		speechPlayer: speechPlayer,
 
 		emitEvent: emitEvent,
+		state:     newTurnStateMachine(emitEvent),
+		stopped:   make(chan struct{}),
+		cancelled: make(chan struct{}),
 	}
 }
 
@@ -70,20 +111,41 @@ func (p *responsePipeline) Run(
 		return llms.TurnV1{}, fmt.Errorf("active turn is required")
 	}
 
-	p.lockFor(func() { p.ctx = ctx })
+	p.lockFor(func() {
+		p.ctx = ctx
+		p.turn = activeTurn
+	})
+	p.latency = newTurnLatency(activeTurn.queuedAt)
+	p.state.SetTurnID(activeTurn.ID)
+	p.state.Transition(TurnStateGenerating)
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	stageChains := newPipelineStageChains(ctx, p.stages, PipelineTurnV0{ID: activeTurn.ID, Trigger: activeTurn.Trigger, EmitEvent: p.emitEvent})
+	p.lockFor(func() { p.stageChains = stageChains })
 	defer p.Close()
+	if p.stallDetection != nil {
+		go p.stallDetection.watch(ctx, p, activeTurn.ID)
+	}
 
 	err := p.runWorkers(ctx, cancel,
-		panicSafeNamedWorker("llm generation", func(ctx context.Context) error { return p.generateLLM(ctx, activeTurn, history) }),
-		panicSafeNamedWorker("response text processing", func(ctx context.Context) error { return p.processResponseText(ctx, activeTurn) }),
-		panicSafeNamedWorker("speech processing", func(ctx context.Context) error { return p.processSpeech(ctx, activeTurn) }),
+		p.panicSafeNamedWorker("llm generation", func(ctx context.Context) error { return p.generateLLM(ctx, activeTurn, history) }),
+		p.panicSafeNamedWorker("response text processing", func(ctx context.Context) error { return p.processResponseText(ctx, activeTurn) }),
+		p.panicSafeNamedWorker("speech processing", func(ctx context.Context) error { return p.processSpeech(ctx, activeTurn) }),
 	)
 
-	if finaliseErr := panicSafeNamedWorker("active turn finalise",
-		func(context.Context) error { activeTurn.Finalise(); return nil },
+	p.state.Transition(TurnStateFinalizing)
+	if finaliseErr := p.panicSafeNamedWorker("active turn finalise",
+		func(context.Context) error {
+			p.spokenMu.Lock()
+			defer p.spokenMu.Unlock()
+			activeTurn.Latency = p.latency.Report()
+			if p.IsCancelled() && p.textToSpeech.connected.Load() && !p.textOnly.Load() {
+				activeTurn.truncateToSpoken()
+			}
+			activeTurn.Finalise()
+			return nil
+		},
 	)(ctx); finaliseErr != nil {
 		err = errors.Join(err, finaliseErr)
 	}
@@ -126,19 +188,53 @@ func (p *responsePipeline) runWorkers(ctx context.Context, cancel context.Cancel
 func (processor *responsePipeline) generateLLM(ctx context.Context, turn *activeTurn, history []llms.TurnV1) error {
 	ctx, span := tracer.Start(ctx, "generate llm")
 	defer span.End()
+	ctx, stopGenerating := context.WithCancel(ctx)
+	defer stopGenerating()
+	processor.lockFor(func() { processor.stopGenerating = stopGenerating })
 
-	response, err := processor.llm.generate(ctx, turn.Trigger, history, processor.speechPlayer.AddTextChunk, func() bool {
+	addText, reviseText, flushTextStages := processor.stagesAt(PipelineAfterTextProcessing).text(
+		processor.speechPlayer.AddTextChunk, processor.speechPlayer.ReviseText,
+	)
+	var translator *responseTranslator
+	if processor.translation != nil {
+		translator = processor.translation.newResponseTranslator(ctx, addText)
+		addText, reviseText = translator.add, translator.revise
+	}
+	processor.llm.speakFiller = addText
+	var shaper *voiceShaper
+	if processor.voiceShaping != nil {
+		shaper = processor.voiceShaping.newShaper(addText)
+		processor.llm.stopGeneration = shaper.stopped
+		shapedRevise := reviseText
+		addText, reviseText = shaper.add, func(replaced, replacement string) {
+			shapedRevise(replaced, "")
+			shaper.add(replacement)
+		}
+	}
+	addText, reviseText, flushGenerationStages := processor.stagesAt(PipelineAfterGeneration).text(addText, reviseText)
+	processor.llm.reviseText = reviseText
+	processor.progress.report(workerLLM, "generating")
+	onChunk := func(chunk string) {
+		processor.latency.mark(&processor.latency.llmFirstToken)
+		processor.progress.report(workerLLM, "streaming")
+		addText(chunk)
+	}
+	response, err := processor.llm.generate(ctx, turn.Trigger, history, onChunk, func() bool {
 		return processor.IsCancelled()
 	})
+	processor.latency.mark(&processor.latency.llmDone)
+	processor.progress.report(workerLLM, "done")
 	if err != nil {
 		err := fmt.Errorf("failed to generate llm response: %w", err)
-		span.RecordError(err)
+		errorreport.Record(ctx, span, err, "component", string(ComponentLLM))
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 	if response != nil {
-		turn.finalResponse.IsMessageFullyGenerated = true
+		processor.spokenMu.Lock()
+		turn.finalResponse.IsMessageFullyGenerated = !processor.IsCancelled()
 		turn.finalResponse.Message = response.Content
+		processor.spokenMu.Unlock()
 		turn.ToolCalls = response.ToolCalls
 		var toolCalls []string
 		for _, toolCall := range response.ToolCalls {
@@ -147,6 +243,14 @@ func (processor *responsePipeline) generateLLM(ctx context.Context, turn *active
 		span.SetAttributes(attribute.StringSlice("assistant_turn.tool_calls", toolCalls))
 	}
 
+	flushGenerationStages()
+	if shaper != nil {
+		shaper.flush()
+	}
+	if translator != nil {
+		translator.flush()
+	}
+	flushTextStages()
 	processor.speechPlayer.TextComplete()
 	return nil
 }
@@ -162,11 +266,14 @@ func (processor *responsePipeline) processResponseText(
 	defer span.End()
 
 	processor.textToSpeech.SetEventEmitter(processor.composeTTSEventEmitter())
+	processor.progress.report(workerTextToSpeech, "connecting")
 	if err := processor.textToSpeech.init(ctx, processor.audioOutput.EncodingInfo()); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		return err
+		errorreport.Record(ctx, span, err, "component", string(ComponentTextToSpeech))
+		processor.degradeToTextOnly(ComponentTextToSpeech, err)
+	} else if processor.textToSpeech.connected.Load() {
+		processor.speechRecovered(ComponentTextToSpeech)
 	}
+	processor.progress.report(workerTextToSpeech, "waiting for text")
 
 textLoop:
 	for textOrMark := range processor.speechPlayer.TextOrMarks {
@@ -174,39 +281,66 @@ textLoop:
 			break textLoop
 		}
 
+		processor.progress.report(workerTextToSpeech, "sent "+string(textOrMark.Type))
 		switch textOrMark.Type {
 		case textOrMarkTypeText:
 			chunk := textOrMark.Text
+			processor.spokenMu.Lock()
 			turn.finalResponse.TypedMessage += chunk
+			processor.spokenMu.Unlock()
+			if processor.textOnly.Load() {
+				continue
+			}
 
 			if err := processor.textToSpeech.SendText(chunk); err != nil {
-				span.RecordError(fmt.Errorf("failed to send text to tts: %w", err))
+				errorreport.Record(ctx, span, fmt.Errorf("failed to send text to tts: %w", err), "component", string(ComponentTextToSpeech))
 			}
 		case textOrMarkTypeMark:
+			if processor.textOnly.Load() {
+				continue
+			}
 			if err := processor.textToSpeech.Mark(); err != nil {
-				span.RecordError(fmt.Errorf("failed to send mark to tts: %w", err))
+				errorreport.Record(ctx, span, fmt.Errorf("failed to send mark to tts: %w", err), "component", string(ComponentTextToSpeech))
 			}
 		}
 	}
 
 	if err := processor.textToSpeech.EndOfText(); err != nil {
-		span.RecordError(fmt.Errorf("failed to end of text to tts: %w", err))
+		errorreport.Record(ctx, span, fmt.Errorf("failed to end of text to tts: %w", err), "component", string(ComponentTextToSpeech))
 	}
+	processor.progress.report(workerTextToSpeech, "done")
 
 	return nil
 }
 
 func (processor *responsePipeline) composeTTSEventEmitter() eventEmitter {
+	bufferSpeech := func(item PipelineItemV0) {
+		switch item.Kind {
+		case PipelineItemAudio:
+			processor.speechPlayer.AddAudio(item.Audio)
+		case PipelineItemMark:
+			// Legacy TTS signals terminal/end-of-stream marks with an empty
+			// transcript payload. Preserve that signal explicitly so legacy
+			// playback completion does not fire on non-terminal marks.
+			processor.speechPlayer.AddMark(item.Text == "")
+		}
+	}
+
 	return func(event events.Event) {
 		switch typedEvent := event.(type) {
 		case events.AssistantSpeechFrame:
-			processor.speechPlayer.AddAudio(typedEvent.Audio)
+			processor.latency.mark(&processor.latency.ttsFirstAudio)
+			processor.stagesAt(PipelineAfterSpeech).push(PipelineItemV0{Kind: PipelineItemAudio, Audio: typedEvent.Audio}, bufferSpeech)
 		case events.AssistantSpeechMarkGenerated:
-			// Legacy TTS signals terminal/end-of-stream marks with an empty
-			// transcript payload. Preserve that signal explicitly so legacy
-			// playback completion does not fire on non-terminal marks.
-			processor.speechPlayer.AddMark(typedEvent.Transcript == "")
+			processor.stagesAt(PipelineAfterSpeech).push(PipelineItemV0{Kind: PipelineItemMark, Text: typedEvent.Transcript}, bufferSpeech)
+		case events.AssistantSpeechTimestamps:
+			// Stages after speech may reshape the audio the timestamps are
+			// offsets into, the spoken text is estimated instead then.
+			if chain := processor.stagesAt(PipelineAfterSpeech); chain == nil || len(chain.stages) == 0 {
+				processor.speechPlayer.AddWordTimestamps(typedEvent.Words)
+			}
 		case events.AssistantSpeechFinal:
+			processor.stagesAt(PipelineAfterSpeech).flush(bufferSpeech)
 			processor.speechPlayer.FinishAudio()
 		}
 
@@ -221,6 +355,7 @@ func (processor *responsePipeline) processSpeech(
 	done := withContextCancelHook(ctx, processor.speechPlayer.StopAudio)
 	defer close(done)
 
+	processor.progress.report(workerAudioOutput, "waiting for text-to-speech")
 	if ok := processor.textToSpeech.waitUntilInitialized(ctx); !ok {
 		return nil
 	}
@@ -232,46 +367,110 @@ func (processor *responsePipeline) processSpeech(
 	_, span := tracer.Start(ctx, "passing speech to audio output")
 	defer span.End()
 
-speechLoop:
-	for audioOrMark := range processor.speechPlayer.Audio {
-		switch audioOrMark.Type {
-		case audioOrMarkTypeAudio:
+	processor.progress.report(workerAudioOutput, "waiting for audio")
+	playedAudio := false
+	stopped := false
+	play := func(item PipelineItemV0) {
+		if stopped {
+			return
+		}
+
+		switch item.Kind {
+		case PipelineItemAudio:
 			if processor.textToSpeech.IsMuted() || processor.IsCancelled() {
 				processor.audioOutput.Clear()
-				break speechLoop
+				stopped = true
+				return
 			}
 
-			processor.audioOutput.SendAudio(audioOrMark.Audio)
+			if len(item.Audio) > 0 {
+				if !playedAudio {
+					processor.state.Transition(TurnStateSpeaking)
+				}
+				playedAudio = true
+				processor.latency.mark(&processor.latency.playbackStarted)
+			}
+			if err := processor.audioOutput.SendAudio(item.Audio); err != nil {
+				processor.stopSpeechAfterOutputFailure(fmt.Errorf("failed to send audio to output: %w", err))
+				stopped = true
+			}
 
-		case audioOrMarkTypeMark:
-			mark := audioOrMark.Mark
+		case PipelineItemMark:
+			mark := item.Mark
 			span.AddEvent("received mark", trace.WithAttributes(attribute.String("mark", mark), attribute.String("audio_output.version", "v1")))
-			processor.audioOutput.Mark(mark, func(mark string) {
+			if err := processor.audioOutput.Mark(mark, func(mark string) {
 				span.AddEvent("mark played", trace.WithAttributes(attribute.String("mark", mark), attribute.String("audio_output.version", "v1")))
+				processor.latency.markLatest(&processor.latency.playbackEnded)
+				processor.progress.report(workerAudioOutput, "mark played")
+				processor.spokenMu.Lock()
+				defer processor.spokenMu.Unlock()
 				if transcript := processor.speechPlayer.ConfirmOutputMark(mark); transcript != nil {
 					turn.finalResponse.SpokenResponse += *transcript
 				}
-			})
+			}); err != nil {
+				processor.stopSpeechAfterOutputFailure(fmt.Errorf("failed to send mark to output: %w", err))
+				stopped = true
+			}
 		}
+	}
 
+	playbackStages := processor.stagesAt(PipelineBeforePlayback)
+	for audioOrMark := range processor.speechPlayer.Audio {
+		processor.progress.report(workerAudioOutput, "sent "+string(audioOrMark.Type))
+		switch audioOrMark.Type {
+		case audioOrMarkTypeAudio:
+			playbackStages.push(PipelineItemV0{Kind: PipelineItemAudio, Audio: audioOrMark.Audio}, play)
+		case audioOrMarkTypeMark:
+			playbackStages.push(PipelineItemV0{Kind: PipelineItemMark, Mark: audioOrMark.Mark}, play)
+		}
+		if stopped {
+			break
+		}
+	}
+	if !stopped {
+		playbackStages.flush(play)
 	}
 
-	processor.audioOutput.SendAudio([]byte{})
+	if playedAudio {
+		// Outputs without callback marks never confirm playback, fall back
+		// to when all audio was handed over.
+		processor.latency.mark(&processor.latency.playbackEnded)
+	}
+	if !processor.textOnly.Load() {
+		if playedAudio {
+			processor.speechRecovered(ComponentAudioOutput)
+		}
+		_ = processor.audioOutput.SendAudio([]byte{}) // Ignored on purpose, only flushes the output
+	}
 	processor.audioOutput.Clear()
+	processor.progress.report(workerAudioOutput, "done")
 
 	return nil
 }
 
+// stopSpeechAfterOutputFailure degrades the turn to text-only and stops
+// generating speech nobody can hear.
+func (processor *responsePipeline) stopSpeechAfterOutputFailure(err error) {
+	ctx := processor.Ctx()
+	errorreport.Record(ctx, trace.SpanFromContext(ctx), err, "component", string(ComponentAudioOutput))
+	processor.degradeToTextOnly(ComponentAudioOutput, err)
+	if err := processor.textToSpeech.Cancel(); err != nil {
+		errorreport.Record(ctx, trace.SpanFromContext(ctx), err, "component", string(ComponentTextToSpeech))
+	}
+}
+
 func (p *responsePipeline) Pause() {
 	if p != nil {
 		p.speechPlayer.PauseAudio()
 		p.audioOutput.Clear()
+		p.progress.setPaused(true)
 	}
 }
 
 func (p *responsePipeline) Unpause() {
 	if p != nil {
 		p.speechPlayer.ResumeAudio()
+		p.progress.setPaused(false)
 	}
 }
 
@@ -283,18 +482,53 @@ func (p *responsePipeline) StopSpeaking() {
 	}
 }
 
+// Cancel stops the turn, [events.TurnCancelled] is emitted once the turn
+// ended, see [responsePipeline.Stop].
 func (p *responsePipeline) Cancel() {
-	if p != nil && p.cancelled.CompareAndSwap(false, true) {
+	if p != nil && p.state.Transition(TurnStateCancelled) {
+		p.rLockFor(func() {
+			if p.stopGenerating != nil {
+				p.stopGenerating()
+			}
+		})
 		p.Close()
 		p.textToSpeech.Cancel()
 		p.speechPlayer.StopAudio()
 		p.audioOutput.Clear()
-		p.emitEvent(events.NewTurnCancelled())
+
+		select {
+		case <-p.stopped:
+			p.completeCancellation()
+		default:
+		}
+	}
+}
+
+// Stop marks the turn of the pipeline as ended, no worker touches the
+// speech or the audio output anymore.
+func (p *responsePipeline) Stop() {
+	if p != nil {
+		p.stopOnce.Do(func() { close(p.stopped) })
+		p.completeCancellation()
+	}
+}
+
+// completeCancellation clears audio a worker sent while the turn was being
+// cancelled and reports the cancellation, once the cancelled turn ended.
+func (p *responsePipeline) completeCancellation() {
+	if !p.IsCancelled() {
+		return
 	}
+
+	p.cancelOnce.Do(func() {
+		p.audioOutput.Clear()
+		p.emitEvent(events.NewTurnCancelled())
+		close(p.cancelled)
+	})
 }
 
 func (p *responsePipeline) IsCancelled() bool {
-	return p != nil && p.cancelled.Load()
+	return p != nil && p.state.Current() == TurnStateCancelled
 }
 
 func (p *responsePipeline) Close() {
@@ -303,7 +537,7 @@ func (p *responsePipeline) Close() {
 		if err := p.textToSpeech.Close(pipelineCtx); err != nil {
 			err = fmt.Errorf("failed to close tts resources while cancelling active turn: %w", err)
 			span := trace.SpanFromContext(pipelineCtx)
-			span.RecordError(err)
+			errorreport.Record(pipelineCtx, span, err, "component", string(ComponentTextToSpeech))
 			span.SetStatus(codes.Error, err.Error())
 		}
 	}
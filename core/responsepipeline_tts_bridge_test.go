@@ -332,3 +332,43 @@ func (output *bridgeAudioOutputStub) marks() int {
 	defer output.mu.Unlock()
 	return output.markCount
 }
+
+func TestResponsePipelineKeepsSpokenTextConfirmedByLastMark(t *testing.T) {
+	for range 20 {
+		o := NewOrchestrator(
+			WithLLM(promptLLMStub{response: "confirmed asynchronously"}),
+			WithTextToSpeechClientV1(&bridgeTTSV1Stub{}),
+			WithAudioOutputV1(&asyncMarkAudioOutputStub{}),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		o.Orchestrate(ctx)
+		o.SendPrompt("prompt")
+
+		waitForCondition(t, 2*time.Second, "turn finalised", func() bool {
+			return len(o.conversation.History()) == 1
+		})
+		history := o.conversation.History()
+		if len(history[0].Responses) != 1 || history[0].Responses[0].SpokenResponse != "confirmed asynchronously" {
+			t.Fatalf("expected the spoken text confirmed by the last mark, got %+v", history[0].Responses)
+		}
+
+		cancel()
+		o.Close()
+	}
+}
+
+// asyncMarkAudioOutputStub confirms marks from another goroutine, as outputs
+// confirming actual playback do.
+type asyncMarkAudioOutputStub struct {
+	bridgeAudioOutputStub
+}
+
+func (output *asyncMarkAudioOutputStub) Mark(mark string, callback func(string)) error {
+	output.mu.Lock()
+	output.markCount++
+	output.mu.Unlock()
+
+	go callback(mark)
+	return nil
+}
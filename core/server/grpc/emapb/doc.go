@@ -0,0 +1,5 @@
+// Package emapb holds the code generated from ema.proto, the schema of the
+// gRPC API served by the grpc package.
+package emapb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ema.proto
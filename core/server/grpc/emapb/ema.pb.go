@@ -0,0 +1,629 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: ema.proto
+
+package emapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SendPromptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prompt        string                 `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendPromptRequest) Reset() {
+	*x = SendPromptRequest{}
+	mi := &file_ema_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendPromptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendPromptRequest) ProtoMessage() {}
+
+func (x *SendPromptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendPromptRequest.ProtoReflect.Descriptor instead.
+func (*SendPromptRequest) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SendPromptRequest) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+type SendPromptResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendPromptResponse) Reset() {
+	*x = SendPromptResponse{}
+	mi := &file_ema_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendPromptResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendPromptResponse) ProtoMessage() {}
+
+func (x *SendPromptResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendPromptResponse.ProtoReflect.Descriptor instead.
+func (*SendPromptResponse) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{1}
+}
+
+type CancelTurnRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTurnRequest) Reset() {
+	*x = CancelTurnRequest{}
+	mi := &file_ema_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTurnRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTurnRequest) ProtoMessage() {}
+
+func (x *CancelTurnRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTurnRequest.ProtoReflect.Descriptor instead.
+func (*CancelTurnRequest) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{2}
+}
+
+type CancelTurnResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTurnResponse) Reset() {
+	*x = CancelTurnResponse{}
+	mi := &file_ema_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTurnResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTurnResponse) ProtoMessage() {}
+
+func (x *CancelTurnResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTurnResponse.ProtoReflect.Descriptor instead.
+func (*CancelTurnResponse) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{3}
+}
+
+type MuteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MuteRequest) Reset() {
+	*x = MuteRequest{}
+	mi := &file_ema_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MuteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MuteRequest) ProtoMessage() {}
+
+func (x *MuteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MuteRequest.ProtoReflect.Descriptor instead.
+func (*MuteRequest) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{4}
+}
+
+type MuteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MuteResponse) Reset() {
+	*x = MuteResponse{}
+	mi := &file_ema_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MuteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MuteResponse) ProtoMessage() {}
+
+func (x *MuteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MuteResponse.ProtoReflect.Descriptor instead.
+func (*MuteResponse) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{5}
+}
+
+type UnmuteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnmuteRequest) Reset() {
+	*x = UnmuteRequest{}
+	mi := &file_ema_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnmuteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnmuteRequest) ProtoMessage() {}
+
+func (x *UnmuteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnmuteRequest.ProtoReflect.Descriptor instead.
+func (*UnmuteRequest) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{6}
+}
+
+type UnmuteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnmuteResponse) Reset() {
+	*x = UnmuteResponse{}
+	mi := &file_ema_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnmuteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnmuteResponse) ProtoMessage() {}
+
+func (x *UnmuteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnmuteResponse.ProtoReflect.Descriptor instead.
+func (*UnmuteResponse) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{7}
+}
+
+type SendAudioRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Audio         []byte                 `protobuf:"bytes,1,opt,name=audio,proto3" json:"audio,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendAudioRequest) Reset() {
+	*x = SendAudioRequest{}
+	mi := &file_ema_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendAudioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendAudioRequest) ProtoMessage() {}
+
+func (x *SendAudioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendAudioRequest.ProtoReflect.Descriptor instead.
+func (*SendAudioRequest) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SendAudioRequest) GetAudio() []byte {
+	if x != nil {
+		return x.Audio
+	}
+	return nil
+}
+
+type SendAudioResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendAudioResponse) Reset() {
+	*x = SendAudioResponse{}
+	mi := &file_ema_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendAudioResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendAudioResponse) ProtoMessage() {}
+
+func (x *SendAudioResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendAudioResponse.ProtoReflect.Descriptor instead.
+func (*SendAudioResponse) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{9}
+}
+
+type StreamEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// kinds restricts the stream to events of the kinds, e.g.
+	// "turn_state.started", all events are streamed if empty.
+	Kinds         []string `protobuf:"bytes,1,rep,name=kinds,proto3" json:"kinds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_ema_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StreamEventsRequest) GetKinds() []string {
+	if x != nil {
+		return x.Kinds
+	}
+	return nil
+}
+
+// Event mirrors the JSON envelope of the events package, receivers follow
+// its wire compatibility rules, e.g. skipping kinds they do not know.
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// version is the version of the wire format of data.
+	Version int32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// kind is the kind of the event, e.g. "turn_state.started".
+	Kind      string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// data holds the fields of the event as they are encoded in the JSON
+	// envelope, e.g. audio as base64 strings.
+	Data          *structpb.Struct `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_ema_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_ema_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_ema_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Event) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Event) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetData() *structpb.Struct {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_ema_proto protoreflect.FileDescriptor
+
+const file_ema_proto_rawDesc = "" +
+	"\n" +
+	"\tema.proto\x12\x06ema.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"+\n" +
+	"\x11SendPromptRequest\x12\x16\n" +
+	"\x06prompt\x18\x01 \x01(\tR\x06prompt\"\x14\n" +
+	"\x12SendPromptResponse\"\x13\n" +
+	"\x11CancelTurnRequest\"\x14\n" +
+	"\x12CancelTurnResponse\"\r\n" +
+	"\vMuteRequest\"\x0e\n" +
+	"\fMuteResponse\"\x0f\n" +
+	"\rUnmuteRequest\"\x10\n" +
+	"\x0eUnmuteResponse\"(\n" +
+	"\x10SendAudioRequest\x12\x14\n" +
+	"\x05audio\x18\x01 \x01(\fR\x05audio\"\x13\n" +
+	"\x11SendAudioResponse\"+\n" +
+	"\x13StreamEventsRequest\x12\x14\n" +
+	"\x05kinds\x18\x01 \x03(\tR\x05kinds\"\x9c\x01\n" +
+	"\x05Event\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12+\n" +
+	"\x04data\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x04data2\x86\x03\n" +
+	"\fOrchestrator\x12C\n" +
+	"\n" +
+	"SendPrompt\x12\x19.ema.v1.SendPromptRequest\x1a\x1a.ema.v1.SendPromptResponse\x12C\n" +
+	"\n" +
+	"CancelTurn\x12\x19.ema.v1.CancelTurnRequest\x1a\x1a.ema.v1.CancelTurnResponse\x121\n" +
+	"\x04Mute\x12\x13.ema.v1.MuteRequest\x1a\x14.ema.v1.MuteResponse\x127\n" +
+	"\x06Unmute\x12\x15.ema.v1.UnmuteRequest\x1a\x16.ema.v1.UnmuteResponse\x12B\n" +
+	"\tSendAudio\x12\x18.ema.v1.SendAudioRequest\x1a\x19.ema.v1.SendAudioResponse(\x01\x12<\n" +
+	"\fStreamEvents\x12\x1b.ema.v1.StreamEventsRequest\x1a\r.ema.v1.Event0\x01B7Z5github.com/koscakluka/ema-core/core/server/grpc/emapbb\x06proto3"
+
+var (
+	file_ema_proto_rawDescOnce sync.Once
+	file_ema_proto_rawDescData []byte
+)
+
+func file_ema_proto_rawDescGZIP() []byte {
+	file_ema_proto_rawDescOnce.Do(func() {
+		file_ema_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ema_proto_rawDesc), len(file_ema_proto_rawDesc)))
+	})
+	return file_ema_proto_rawDescData
+}
+
+var file_ema_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_ema_proto_goTypes = []any{
+	(*SendPromptRequest)(nil),     // 0: ema.v1.SendPromptRequest
+	(*SendPromptResponse)(nil),    // 1: ema.v1.SendPromptResponse
+	(*CancelTurnRequest)(nil),     // 2: ema.v1.CancelTurnRequest
+	(*CancelTurnResponse)(nil),    // 3: ema.v1.CancelTurnResponse
+	(*MuteRequest)(nil),           // 4: ema.v1.MuteRequest
+	(*MuteResponse)(nil),          // 5: ema.v1.MuteResponse
+	(*UnmuteRequest)(nil),         // 6: ema.v1.UnmuteRequest
+	(*UnmuteResponse)(nil),        // 7: ema.v1.UnmuteResponse
+	(*SendAudioRequest)(nil),      // 8: ema.v1.SendAudioRequest
+	(*SendAudioResponse)(nil),     // 9: ema.v1.SendAudioResponse
+	(*StreamEventsRequest)(nil),   // 10: ema.v1.StreamEventsRequest
+	(*Event)(nil),                 // 11: ema.v1.Event
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 13: google.protobuf.Struct
+}
+var file_ema_proto_depIdxs = []int32{
+	12, // 0: ema.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	13, // 1: ema.v1.Event.data:type_name -> google.protobuf.Struct
+	0,  // 2: ema.v1.Orchestrator.SendPrompt:input_type -> ema.v1.SendPromptRequest
+	2,  // 3: ema.v1.Orchestrator.CancelTurn:input_type -> ema.v1.CancelTurnRequest
+	4,  // 4: ema.v1.Orchestrator.Mute:input_type -> ema.v1.MuteRequest
+	6,  // 5: ema.v1.Orchestrator.Unmute:input_type -> ema.v1.UnmuteRequest
+	8,  // 6: ema.v1.Orchestrator.SendAudio:input_type -> ema.v1.SendAudioRequest
+	10, // 7: ema.v1.Orchestrator.StreamEvents:input_type -> ema.v1.StreamEventsRequest
+	1,  // 8: ema.v1.Orchestrator.SendPrompt:output_type -> ema.v1.SendPromptResponse
+	3,  // 9: ema.v1.Orchestrator.CancelTurn:output_type -> ema.v1.CancelTurnResponse
+	5,  // 10: ema.v1.Orchestrator.Mute:output_type -> ema.v1.MuteResponse
+	7,  // 11: ema.v1.Orchestrator.Unmute:output_type -> ema.v1.UnmuteResponse
+	9,  // 12: ema.v1.Orchestrator.SendAudio:output_type -> ema.v1.SendAudioResponse
+	11, // 13: ema.v1.Orchestrator.StreamEvents:output_type -> ema.v1.Event
+	8,  // [8:14] is the sub-list for method output_type
+	2,  // [2:8] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_ema_proto_init() }
+func file_ema_proto_init() {
+	if File_ema_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ema_proto_rawDesc), len(file_ema_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ema_proto_goTypes,
+		DependencyIndexes: file_ema_proto_depIdxs,
+		MessageInfos:      file_ema_proto_msgTypes,
+	}.Build()
+	File_ema_proto = out.File
+	file_ema_proto_goTypes = nil
+	file_ema_proto_depIdxs = nil
+}
@@ -0,0 +1,209 @@
+// Package grpc serves the control surface and the events of an orchestrator
+// over gRPC, for backends in other languages than Go. The API is defined in
+// emapb/ema.proto, clients are generated from it with protoc.
+//
+// The [Server] is an event sink, it streams the events it handles to the
+// clients of the StreamEvents call:
+//
+//	server := emagrpc.NewServer(o)
+//	o.Orchestrate(ctx, orchestration.WithEventSink(server))
+//
+//	s := grpc.NewServer()
+//	server.Register(s)
+//	s.Serve(listener)
+//
+// Events are streamed as their JSON envelope, see [events.Envelope], with
+// the data as a google.protobuf.Struct.
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/server/grpc/emapb"
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Orchestrator is the control surface of an orchestrator served, e.g. an
+// *orchestration.Orchestrator.
+type Orchestrator interface {
+	SendPrompt(prompt string)
+	CancelTurn()
+	Mute()
+	Unmute()
+	SendAudio(audio []byte) error
+}
+
+type ServerOptions struct {
+	busOptions []events.BusOption
+	logger     logging.Logger
+}
+
+type ServerOption func(*ServerOptions)
+
+// WithSubscriptionBuffer sets how many events are buffered for each
+// StreamEvents call, events arriving at a full buffer are dropped for the
+// call. Defaults to the buffer of [events.NewBus].
+func WithSubscriptionBuffer(size int) ServerOption {
+	return func(o *ServerOptions) {
+		o.busOptions = append(o.busOptions, events.WithSubscriptionBuffer(size))
+	}
+}
+
+func WithLogger(logger logging.Logger) ServerOption {
+	return func(o *ServerOptions) {
+		o.logger = logger
+	}
+}
+
+// Server implements the Orchestrator service of emapb for an orchestrator,
+// and [events.Sink] for its events.
+type Server struct {
+	emapb.UnimplementedOrchestratorServer
+
+	orchestrator Orchestrator
+	bus          *events.Bus
+	logger       logging.Logger
+}
+
+// NewServer creates a server controlling orchestrator. Register it with the
+// orchestrator as an event sink to stream its events.
+func NewServer(orchestrator Orchestrator, opts ...ServerOption) *Server {
+	options := ServerOptions{logger: logging.Default()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Server{
+		orchestrator: orchestrator,
+		bus:          events.NewBus(options.busOptions...),
+		logger:       options.logger,
+	}
+}
+
+// Register registers the service with registrar, e.g. a *grpc.Server.
+func (s *Server) Register(registrar grpcgo.ServiceRegistrar) {
+	emapb.RegisterOrchestratorServer(registrar, s)
+}
+
+// Handle streams event to the clients of StreamEvents.
+func (s *Server) Handle(event events.Event) {
+	s.bus.Handle(event)
+}
+
+// Close ends the event streams, later StreamEvents calls end right away.
+func (s *Server) Close() {
+	s.bus.Close()
+}
+
+func (s *Server) SendPrompt(_ context.Context, req *emapb.SendPromptRequest) (*emapb.SendPromptResponse, error) {
+	if strings.TrimSpace(req.GetPrompt()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "prompt is empty")
+	}
+	s.orchestrator.SendPrompt(req.GetPrompt())
+	return &emapb.SendPromptResponse{}, nil
+}
+
+func (s *Server) CancelTurn(context.Context, *emapb.CancelTurnRequest) (*emapb.CancelTurnResponse, error) {
+	s.orchestrator.CancelTurn()
+	return &emapb.CancelTurnResponse{}, nil
+}
+
+func (s *Server) Mute(context.Context, *emapb.MuteRequest) (*emapb.MuteResponse, error) {
+	s.orchestrator.Mute()
+	return &emapb.MuteResponse{}, nil
+}
+
+func (s *Server) Unmute(context.Context, *emapb.UnmuteRequest) (*emapb.UnmuteResponse, error) {
+	s.orchestrator.Unmute()
+	return &emapb.UnmuteResponse{}, nil
+}
+
+func (s *Server) SendAudio(stream emapb.Orchestrator_SendAudioServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&emapb.SendAudioResponse{})
+		} else if err != nil {
+			return err
+		}
+		if err := s.orchestrator.SendAudio(req.GetAudio()); err != nil {
+			return status.Errorf(codes.Unavailable, "failed to send audio: %v", err)
+		}
+	}
+}
+
+func (s *Server) StreamEvents(req *emapb.StreamEventsRequest, stream emapb.Orchestrator_StreamEventsServer) error {
+	kinds := make([]events.Kind, 0, len(req.GetKinds()))
+	for _, kind := range req.GetKinds() {
+		kinds = append(kinds, events.Kind(kind))
+	}
+	subscription := s.bus.Subscribe(kinds...)
+	defer func() {
+		subscription.Close()
+		if dropped := subscription.Dropped(); dropped > 0 {
+			s.logger.Warn("events dropped for slow grpc event stream", "dropped", dropped)
+		}
+	}()
+	// The header tells the client the stream receives events from now on.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-subscription.C:
+			if !ok {
+				return nil
+			}
+			message, err := eventMessage(event)
+			if err != nil {
+				s.logger.Error("failed to encode event for grpc", "kind", event.Kind(), "error", err)
+				continue
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// eventMessage converts event to its message, with the data of its JSON
+// envelope.
+func eventMessage(event events.Event) (*emapb.Event, error) {
+	encoded, err := json.Marshal(events.NewEnvelope(event))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+	envelope, err := events.DecodeEnvelope(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &structpb.Struct{}
+	if len(envelope.Data) > 0 && string(envelope.Data) != "null" {
+		if err := protojson.Unmarshal(envelope.Data, data); err != nil {
+			return nil, fmt.Errorf("failed to convert event data: %w", err)
+		}
+	}
+	return &emapb.Event{
+		Version:   int32(envelope.Version),
+		Kind:      string(envelope.Kind),
+		Timestamp: timestamppb.New(envelope.Timestamp),
+		Data:      data,
+	}, nil
+}
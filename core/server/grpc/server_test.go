@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/server/grpc/emapb"
+	grpcgo "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var _ Orchestrator = (*orchestration.Orchestrator)(nil)
+
+// serve serves server over an in-memory connection and returns a client.
+func serve(t *testing.T, server *Server) emapb.OrchestratorClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 16)
+	s := grpcgo.NewServer()
+	server.Register(s)
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpcgo.NewClient("passthrough:///bufnet",
+		grpcgo.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpcgo.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return emapb.NewOrchestratorClient(conn)
+}
+
+type orchestratorStub struct {
+	mu       sync.Mutex
+	calls    []string
+	audio    []byte
+	audioErr error
+}
+
+func (o *orchestratorStub) record(call string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.calls = append(o.calls, call)
+}
+
+func (o *orchestratorStub) SendPrompt(prompt string) { o.record("prompt " + prompt) }
+func (o *orchestratorStub) CancelTurn()              { o.record("cancel") }
+func (o *orchestratorStub) Mute()                    { o.record("mute") }
+func (o *orchestratorStub) Unmute()                  { o.record("unmute") }
+
+func (o *orchestratorStub) SendAudio(audio []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.audio = append(o.audio, audio...)
+	return o.audioErr
+}
+
+func TestServerControlsTheOrchestrator(t *testing.T) {
+	orchestrator := &orchestratorStub{}
+	client := serve(t, NewServer(orchestrator, WithLogger(logging.Discard())))
+	ctx := context.Background()
+
+	if _, err := client.SendPrompt(ctx, &emapb.SendPromptRequest{Prompt: "hello"}); err != nil {
+		t.Fatalf("failed to send prompt: %v", err)
+	}
+	if _, err := client.SendPrompt(ctx, &emapb.SendPromptRequest{Prompt: " "}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected an empty prompt to be invalid, got %v", err)
+	}
+	if _, err := client.CancelTurn(ctx, &emapb.CancelTurnRequest{}); err != nil {
+		t.Fatalf("failed to cancel turn: %v", err)
+	}
+	if _, err := client.Mute(ctx, &emapb.MuteRequest{}); err != nil {
+		t.Fatalf("failed to mute: %v", err)
+	}
+	if _, err := client.Unmute(ctx, &emapb.UnmuteRequest{}); err != nil {
+		t.Fatalf("failed to unmute: %v", err)
+	}
+
+	expected := []string{"prompt hello", "cancel", "mute", "unmute"}
+	if len(orchestrator.calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, orchestrator.calls)
+	}
+	for i := range expected {
+		if orchestrator.calls[i] != expected[i] {
+			t.Fatalf("expected calls %v, got %v", expected, orchestrator.calls)
+		}
+	}
+}
+
+func TestServerPassesStreamedAudio(t *testing.T) {
+	orchestrator := &orchestratorStub{}
+	client := serve(t, NewServer(orchestrator, WithLogger(logging.Discard())))
+
+	stream, err := client.SendAudio(context.Background())
+	if err != nil {
+		t.Fatalf("failed to start audio stream: %v", err)
+	}
+	stream.Send(&emapb.SendAudioRequest{Audio: []byte{1, 2}})
+	stream.Send(&emapb.SendAudioRequest{Audio: []byte{3}})
+	if _, err := stream.CloseAndRecv(); err != nil {
+		t.Fatalf("failed to close audio stream: %v", err)
+	}
+	if string(orchestrator.audio) != string([]byte{1, 2, 3}) {
+		t.Fatalf("expected the streamed audio, got %v", orchestrator.audio)
+	}
+
+	orchestrator.audioErr = errors.New("speech to text disconnected")
+	stream, _ = client.SendAudio(context.Background())
+	stream.Send(&emapb.SendAudioRequest{Audio: []byte{4}})
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected failed audio to end the stream, got %v", err)
+	}
+}
+
+func TestServerStreamsEventsOfRequestedKinds(t *testing.T) {
+	server := NewServer(&orchestratorStub{}, WithLogger(logging.Discard()))
+	client := serve(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stream, err := client.StreamEvents(ctx, &emapb.StreamEventsRequest{Kinds: []string{string(events.KindToolCallStarted)}})
+	if err != nil {
+		t.Fatalf("failed to stream events: %v", err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	server.Handle(events.NewAssistantResponseStarted())
+	server.Handle(events.NewToolCallStarted("call-1", "search", `{"query":"weather"}`))
+
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("failed to receive event: %v", err)
+	}
+	if event.GetKind() != string(events.KindToolCallStarted) || event.GetVersion() != events.WireVersion {
+		t.Fatalf("expected the tool call event, got %v", event)
+	}
+	if fields := event.GetData().GetFields(); fields["Name"].GetStringValue() != "search" || fields["ID"].GetStringValue() != "call-1" {
+		t.Fatalf("expected the data of the event, got %v", event.GetData())
+	}
+	if event.GetTimestamp().AsTime().IsZero() {
+		t.Fatalf("expected the timestamp of the event")
+	}
+
+	server.Close()
+	if _, err := stream.Recv(); err == nil {
+		t.Fatalf("expected closing the server to end the stream")
+	}
+}
@@ -0,0 +1,361 @@
+package orchestration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+var ErrOrchestratorClosed = errors.New("orchestrator closed")
+
+// SessionStateV0 is the in-flight state of a conversation that is carried
+// over when a session moves to another orchestrator, e.g. to another process
+// during a deploy.
+//
+// The active turn is intentionally not part of the state, sessions are
+// drained between turns, see [Orchestrator.Drain]. Triggers must be from the
+// [triggers] package to be serializable.
+type SessionStateV0 struct {
+	History         []llms.TurnV1
+	PendingTriggers []llms.TriggerV0
+	// Analysis is the latest report of [Orchestrator.Analyze], if any.
+	Analysis *ConversationAnalysisV0
+	// Checkpoint is where the response cut off by draining stopped being
+	// heard, if any. The resumed orchestrator continues the response from
+	// there, see [PlaybackCheckpointV0].
+	Checkpoint *PlaybackCheckpointV0
+	// Outcome is the label of [Orchestrator.LabelConversationOutcome], if
+	// any.
+	Outcome *llms.OutcomeV0
+	// CallInfo describes the call carrying the conversation, if set, see
+	// [Orchestrator.SetCallInfo].
+	CallInfo *CallInfoV0
+
+	IsMuted                   bool
+	IsAlwaysCapturingAudio    bool
+	IsRequestedToCaptureAudio bool
+}
+
+// Drain stops the orchestrator from starting new turns, waits for the turn
+// in progress to finish and returns the conversation state before closing
+// the orchestrator.
+//
+// If ctx is done before the turn in progress finishes, the turn is cancelled
+// and recorded in the history as such, with a checkpoint of what was heard
+// of its response. Triggers received while draining are
+// kept as pending triggers.
+func (o *Orchestrator) Drain(ctx context.Context) (SessionStateV0, error) {
+	if !o.triggerPlayer.Load().CanIngest() {
+		return SessionStateV0{}, ErrOrchestratorClosed
+	}
+
+	cutOff := o.stopTurns(ctx)
+	history := o.conversation.History()
+	var checkpoint *PlaybackCheckpointV0
+	if cutOff && len(history) > 0 {
+		if c, ok := checkpointOf(history[len(history)-1]); ok {
+			checkpoint = &c
+		}
+	}
+	state := SessionStateV0{
+		History:                   history,
+		PendingTriggers:           o.triggerPlayer.Load().TakeQueued(),
+		Analysis:                  o.analysis.Load(),
+		Checkpoint:                checkpoint,
+		Outcome:                   o.outcome.Load(),
+		CallInfo:                  o.conversation.callInfoSnapshot(),
+		IsMuted:                   o.IsMuted(),
+		IsAlwaysCapturingAudio:    o.IsAlwaysCapturingAudio(),
+		IsRequestedToCaptureAudio: o.IsRequestedToCaptureAudio(),
+	}
+	o.Close()
+
+	return state, nil
+}
+
+// stopTurns stops the orchestrator from starting new turns and waits for the
+// turn in progress to finish, cancelling it once ctx is done. It reports
+// whether the turn was cut off.
+func (o *Orchestrator) stopTurns(ctx context.Context) bool {
+	o.triggerPlayer.Load().StopTaking()
+
+	turnDone := make(chan struct{})
+	go func() {
+		o.triggerPlayer.Load().AwaitDone()
+		close(turnDone)
+	}()
+	cutOff := false
+	select {
+	case <-turnDone:
+	case <-ctx.Done():
+		o.currentResponsePipeline().Cancel()
+		<-turnDone
+		cutOff = true
+	}
+
+	o.triggerPlayer.Load().Stop()
+	return cutOff
+}
+
+// WithSessionStateV0 resumes a conversation drained from another
+// orchestrator.
+//
+// History is restored immediately, pending triggers are processed before any
+// new trigger and the mute and capture flags are applied once
+// [Orchestrator.Orchestrate] is called. A checkpoint is resumed after the
+// pending triggers, see [Orchestrator.ResumePlayback].
+//
+// It cannot be combined with [WithInitialHistory], [NewOrchestratorE] rejects
+// the combination and otherwise the history of the option given last is
+// kept.
+func WithSessionStateV0(state SessionStateV0) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.validation.restoreHistory("WithSessionStateV0")
+		o.conversation.restoreHistory(state.History)
+		o.triggerPlayer.Load().Preload(state.PendingTriggers...)
+		o.analysis.Store(state.Analysis)
+		o.outcome.Store(state.Outcome)
+		o.conversation.setCallInfo(state.CallInfo)
+		o.resumedState = &state
+	}
+}
+
+// WithInitialHistory starts the conversation with turns, e.g. those of a
+// resumed call or a call transferred from another agent, so the assistant
+// has their context from the first turn. Turns are marked as finalised and
+// given IDs if they have none. Use [UnmarshalHistoryV0] to import exported
+// turns.
+//
+// It cannot be combined with [WithSessionStateV0], which carries its own
+// history.
+func WithInitialHistory(turns ...llms.TurnV1) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.validation.restoreHistory("WithInitialHistory")
+		o.conversation.restoreHistory(finalisedTurns(turns))
+	}
+}
+
+// Append adds turns to the history of the conversation, e.g. those of
+// another agent the call is handed off to mid-conversation, before the turn
+// in progress if there is one. Like with [WithInitialHistory], turns are
+// marked as finalised and given IDs if they have none. They are saved to the
+// conversation store, if any, see [WithConversationStore].
+func (c *Conversation) Append(turns ...llms.TurnV1) {
+	turns = finalisedTurns(turns)
+	c.conversation.appendHistory(turns)
+	for _, turn := range turns {
+		c.saveTurn(c.baseContext, turn)
+	}
+}
+
+// finalisedTurns returns a copy of turns marked as finalised, with IDs.
+func finalisedTurns(turns []llms.TurnV1) []llms.TurnV1 {
+	finalised := make([]llms.TurnV1, 0, len(turns))
+	for _, turn := range turns {
+		if turn.ID == "" {
+			turn.ID = uuid.NewString()
+		}
+		turn.IsFinalised = true
+		finalised = append(finalised, turn)
+	}
+	return finalised
+}
+
+// MarshalHistoryV0 exports turns as JSON, in the format of the history of
+// [SessionStateV0].
+func MarshalHistoryV0(turns []llms.TurnV1) ([]byte, error) {
+	encoded := make([]encodedTurnV1, 0, len(turns))
+	for _, turn := range turns {
+		encodedTurn, err := encodeTurn(turn)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, encodedTurn)
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalHistoryV0 imports turns exported with [MarshalHistoryV0], or the
+// history of a [SessionStateV0] exported as JSON.
+func UnmarshalHistoryV0(data []byte) ([]llms.TurnV1, error) {
+	var encoded []encodedTurnV1
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var state encodedSessionStateV0
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, err
+		}
+		encoded = state.History
+	} else if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, err
+	}
+
+	turns := make([]llms.TurnV1, 0, len(encoded))
+	for _, encodedTurn := range encoded {
+		turn, err := decodeTurn(encodedTurn)
+		if err != nil {
+			return nil, err
+		}
+		turns = append(turns, turn)
+	}
+	return turns, nil
+}
+
+func (o *Orchestrator) applyResumedState() {
+	state := o.resumedState
+	if state == nil {
+		return
+	}
+	o.resumedState = nil
+
+	if state.IsMuted {
+		o.Mute()
+	} else {
+		o.Unmute()
+	}
+	o.audioInput.RestoreCapturePolicy(state.IsAlwaysCapturingAudio, state.IsRequestedToCaptureAudio)
+	o.IsRecording = state.IsRequestedToCaptureAudio
+	if state.Checkpoint != nil {
+		o.ingestTrigger(newResumePlaybackTrigger(*state.Checkpoint))
+	}
+}
+
+type encodedSessionStateV0 struct {
+	History         []encodedTurnV1         `json:"history"`
+	PendingTriggers []json.RawMessage       `json:"pending_triggers"`
+	Analysis        *ConversationAnalysisV0 `json:"analysis,omitempty"`
+	Checkpoint      *PlaybackCheckpointV0   `json:"checkpoint,omitempty"`
+	Outcome         *llms.OutcomeV0         `json:"outcome,omitempty"`
+	CallInfo        *CallInfoV0             `json:"call_info,omitempty"`
+
+	IsMuted                   bool `json:"is_muted"`
+	IsAlwaysCapturingAudio    bool `json:"is_always_capturing_audio"`
+	IsRequestedToCaptureAudio bool `json:"is_requested_to_capture_audio"`
+}
+
+type encodedTurnV1 struct {
+	ID            string                `json:"id"`
+	Trigger       json.RawMessage       `json:"trigger,omitempty"`
+	SpeakerID     string                `json:"speaker_id,omitempty"`
+	InputModality string                `json:"input_modality,omitempty"`
+	Responses     []llms.TurnResponseV0 `json:"responses,omitempty"`
+	ToolCalls     []llms.ToolCall       `json:"tool_calls,omitempty"`
+	Interruptions []llms.InterruptionV0 `json:"interruptions,omitempty"`
+	IsFinalised   bool                  `json:"is_finalised"`
+	Outcome       *llms.OutcomeV0       `json:"outcome,omitempty"`
+}
+
+func (s SessionStateV0) MarshalJSON() ([]byte, error) {
+	encoded := encodedSessionStateV0{
+		History:                   make([]encodedTurnV1, 0, len(s.History)),
+		PendingTriggers:           make([]json.RawMessage, 0, len(s.PendingTriggers)),
+		Analysis:                  s.Analysis,
+		Checkpoint:                s.Checkpoint,
+		Outcome:                   s.Outcome,
+		CallInfo:                  s.CallInfo,
+		IsMuted:                   s.IsMuted,
+		IsAlwaysCapturingAudio:    s.IsAlwaysCapturingAudio,
+		IsRequestedToCaptureAudio: s.IsRequestedToCaptureAudio,
+	}
+
+	for _, turn := range s.History {
+		encodedTurn, err := encodeTurn(turn)
+		if err != nil {
+			return nil, err
+		}
+		encoded.History = append(encoded.History, encodedTurn)
+	}
+
+	for _, pending := range s.PendingTriggers {
+		trigger, err := triggers.Marshal(pending)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode pending trigger: %w", err)
+		}
+		encoded.PendingTriggers = append(encoded.PendingTriggers, trigger)
+	}
+
+	return json.Marshal(encoded)
+}
+
+func encodeTurn(turn llms.TurnV1) (encodedTurnV1, error) {
+	encodedTurn := encodedTurnV1{
+		ID:            turn.ID,
+		SpeakerID:     turn.SpeakerID,
+		InputModality: turn.InputModality,
+		Responses:     turn.Responses,
+		ToolCalls:     turn.ToolCalls,
+		Interruptions: turn.Interruptions,
+		IsFinalised:   turn.IsFinalised,
+		Outcome:       turn.Outcome,
+	}
+	if turn.Trigger != nil {
+		trigger, err := triggers.Marshal(turn.Trigger)
+		if err != nil {
+			return encodedTurnV1{}, fmt.Errorf("failed to encode trigger of turn %s: %w", turn.ID, err)
+		}
+		encodedTurn.Trigger = trigger
+	}
+	return encodedTurn, nil
+}
+
+func decodeTurn(encodedTurn encodedTurnV1) (llms.TurnV1, error) {
+	turn := llms.TurnV1{
+		ID:            encodedTurn.ID,
+		SpeakerID:     encodedTurn.SpeakerID,
+		InputModality: encodedTurn.InputModality,
+		Responses:     encodedTurn.Responses,
+		ToolCalls:     encodedTurn.ToolCalls,
+		Interruptions: encodedTurn.Interruptions,
+		IsFinalised:   encodedTurn.IsFinalised,
+		Outcome:       encodedTurn.Outcome,
+	}
+	if len(encodedTurn.Trigger) > 0 {
+		trigger, err := triggers.Unmarshal(encodedTurn.Trigger)
+		if err != nil {
+			return llms.TurnV1{}, fmt.Errorf("failed to decode trigger of turn %s: %w", encodedTurn.ID, err)
+		}
+		turn.Trigger = trigger
+	}
+	return turn, nil
+}
+
+func (s *SessionStateV0) UnmarshalJSON(data []byte) error {
+	var encoded encodedSessionStateV0
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	state := SessionStateV0{
+		Analysis:                  encoded.Analysis,
+		Checkpoint:                encoded.Checkpoint,
+		Outcome:                   encoded.Outcome,
+		CallInfo:                  encoded.CallInfo,
+		IsMuted:                   encoded.IsMuted,
+		IsAlwaysCapturingAudio:    encoded.IsAlwaysCapturingAudio,
+		IsRequestedToCaptureAudio: encoded.IsRequestedToCaptureAudio,
+	}
+
+	for _, encodedTurn := range encoded.History {
+		turn, err := decodeTurn(encodedTurn)
+		if err != nil {
+			return err
+		}
+		state.History = append(state.History, turn)
+	}
+
+	for _, encodedTrigger := range encoded.PendingTriggers {
+		trigger, err := triggers.Unmarshal(encodedTrigger)
+		if err != nil {
+			return fmt.Errorf("failed to decode pending trigger: %w", err)
+		}
+		state.PendingTriggers = append(state.PendingTriggers, trigger)
+	}
+
+	*s = state
+	return nil
+}
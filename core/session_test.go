@@ -0,0 +1,203 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/conversations"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestDrainedSessionResumesOnAnotherOrchestrator(t *testing.T) {
+	llm := scriptedStreamLLMStub{chunks: []string{"one ", "two"}, interval: 20 * time.Millisecond}
+	source := NewOrchestrator(WithStreamingLLM(llm))
+	defer source.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	turnStarted := make(chan struct{}, 1)
+	source.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if event.Kind() == events.KindTurnStarted {
+			select {
+			case turnStarted <- struct{}{}:
+			default:
+			}
+		}
+	}))
+	source.Mute()
+	source.SendPrompt("first")
+
+	select {
+	case <-turnStarted:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for first turn to start")
+	}
+	source.triggerPlayer.Load().Ingest(triggers.NewUserPromptTrigger("second"))
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer drainCancel()
+	state, err := source.Drain(drainCtx)
+	if err != nil {
+		t.Fatalf("unexpected drain error: %v", err)
+	}
+
+	if len(state.History) != 1 || state.History[0].Trigger.String() != "first" {
+		t.Fatalf("expected the first turn to finish before draining, got %+v", state.History)
+	}
+	if len(state.PendingTriggers) != 1 || state.PendingTriggers[0].String() != "second" {
+		t.Fatalf("expected second prompt to stay pending, got %v", state.PendingTriggers)
+	}
+	if !state.IsMuted {
+		t.Fatalf("expected muted flag to be carried over")
+	}
+	if source.triggerPlayer.Load().CanIngest() {
+		t.Fatalf("expected drained orchestrator to be closed")
+	}
+
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	var decoded SessionStateV0
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+
+	resumed := NewOrchestrator(WithStreamingLLM(llm), WithSessionStateV0(decoded))
+	defer resumed.Close()
+	if history := resumed.ConversationV1().History; len(history) != 1 || history[0].Responses[0].Message != "one two" {
+		t.Fatalf("expected history to be restored before orchestration, got %+v", history)
+	}
+
+	resumed.Orchestrate(ctx)
+	waitForCondition(t, 2*time.Second, "pending trigger to be processed", func() bool {
+		return len(resumed.conversation.History()) == 2
+	})
+	if got := resumed.conversation.History()[1].Trigger.String(); got != "second" {
+		t.Fatalf("expected pending trigger to be processed, got %q", got)
+	}
+	if !resumed.IsMuted() {
+		t.Fatalf("expected resumed orchestrator to be muted")
+	}
+}
+
+// historyLLMStub records the history of the prompts it receives.
+type historyLLMStub struct {
+	mu      sync.Mutex
+	history [][]llms.TurnV1
+}
+
+func (stub *historyLLMStub) Prompt(_ context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
+	promptOptions := llms.PromptOptions{}
+	for _, opt := range opts {
+		opt(&promptOptions)
+	}
+	stub.mu.Lock()
+	stub.history = append(stub.history, promptOptions.TurnsV1)
+	stub.mu.Unlock()
+	return []llms.Message{{Content: "Welcome back."}}, nil
+}
+
+func TestInitialHistoryIsImportedAndSentToTheLLM(t *testing.T) {
+	exported, err := MarshalHistoryV0([]llms.TurnV1{{
+		ID:            "turn-1",
+		Trigger:       triggers.NewTypedUserPromptTrigger("my order is late"),
+		InputModality: llms.InputModalityText,
+		Responses:     []llms.TurnResponseV0{{Message: "Let me check.", IsMessageFullyGenerated: true}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected export error %v", err)
+	}
+	imported, err := UnmarshalHistoryV0(exported)
+	if err != nil {
+		t.Fatalf("unexpected import error %v", err)
+	}
+
+	llm := &historyLLMStub{}
+	var completed atomic.Bool
+	o := NewOrchestrator(WithLLM(llm), WithInitialHistory(imported...))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+	o.SendPrompt("any news?")
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	if len(llm.history) != 1 || len(llm.history[0]) != 1 {
+		t.Fatalf("expected the imported turn in the prompt history, got %+v", llm.history)
+	}
+	seeded := llm.history[0][0]
+	if seeded.ID != "turn-1" || !seeded.IsFinalised || seeded.Trigger.String() != "my order is late" || seeded.InputModality != llms.InputModalityText {
+		t.Fatalf("unexpected seeded turn %+v", seeded)
+	}
+
+	state, err := json.Marshal(SessionStateV0{History: o.conversation.History()})
+	if err != nil {
+		t.Fatalf("unexpected encoding error %v", err)
+	}
+	if fromState, err := UnmarshalHistoryV0(state); err != nil || len(fromState) != 2 {
+		t.Fatalf("expected to import the history of a session state, got %+v, %v", fromState, err)
+	}
+}
+
+func TestManagerTracksSessions(t *testing.T) {
+	manager := NewManager()
+	defer manager.Close()
+
+	if err := manager.Add("a", NewOrchestrator()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.Add("a", NewOrchestrator()); !errors.Is(err, ErrSessionExists) {
+		t.Fatalf("expected ErrSessionExists, got %v", err)
+	}
+	if _, err := manager.Drain(context.Background(), "missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	states, err := manager.DrainAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := states["a"]; !ok || len(manager.Sessions()) != 0 {
+		t.Fatalf("expected all sessions to be drained, got %v", states)
+	}
+}
+
+func TestConversationAppendAddsTurnsToHistory(t *testing.T) {
+	store := conversations.NewMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conversation, err := NewOrchestratorBuilder(WithConversationStore(store, "call-1")).
+		WithLLM(repeatingStreamLLMStub{chunk: "hi", interval: time.Hour}).
+		StartConversation(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conversation.Close()
+
+	conversation.Append(
+		llms.TurnV1{Trigger: triggers.NewUserPromptTrigger("I need a refund")},
+		llms.TurnV1{ID: "handoff", Trigger: triggers.NewContextTrigger("billing", "Refund approved.")},
+	)
+
+	history := conversation.ConversationV1().History
+	if len(history) != 2 || history[0].ID == "" || !history[0].IsFinalised || history[1].ID != "handoff" {
+		t.Fatalf("expected appended turns to be finalised with IDs, got %+v", history)
+	}
+	if saved, err := store.LoadConversation(ctx, "call-1"); err != nil || len(saved) != 2 {
+		t.Fatalf("expected appended turns to be saved, got %+v (%v)", saved, err)
+	}
+}
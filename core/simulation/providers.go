@@ -0,0 +1,337 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+// Profile describes the timing of the synthetic providers.
+type Profile struct {
+	// LLMFirstToken is how long the LLM takes to stream the first token.
+	LLMFirstToken time.Duration
+	// LLMTokenInterval is the delay between the following tokens.
+	LLMTokenInterval time.Duration
+	// ResponseWords is how many words a response has.
+	ResponseWords int
+
+	// TTSFirstAudio is how long text to speech takes to produce the first
+	// audio of a response.
+	TTSFirstAudio time.Duration
+	// SpeechCharsPerSecond is how fast synthesized speech is spoken.
+	SpeechCharsPerSecond int
+
+	Encoding audio.EncodingInfo
+}
+
+// DefaultProfile approximates hosted providers over a good connection.
+func DefaultProfile() Profile {
+	return Profile{
+		LLMFirstToken:        300 * time.Millisecond,
+		LLMTokenInterval:     20 * time.Millisecond,
+		ResponseWords:        20,
+		TTSFirstAudio:        150 * time.Millisecond,
+		SpeechCharsPerSecond: 15,
+		Encoding:             audio.EncodingInfo{SampleRate: 16000, Format: audio.EncodingLinear16},
+	}
+}
+
+// speechDuration is how long text takes to be spoken.
+func (p Profile) speechDuration(text string) time.Duration {
+	if p.SpeechCharsPerSecond <= 0 {
+		return 0
+	}
+	return time.Duration(len(text)) * time.Second / time.Duration(p.SpeechCharsPerSecond)
+}
+
+func (p Profile) bytesPerSecond() int {
+	return p.Encoding.SampleRate * p.Encoding.Format.ByteSize()
+}
+
+// LLM is a streaming LLM answering every prompt with a synthetic response.
+type LLM struct {
+	profile Profile
+}
+
+func NewLLM(profile Profile) *LLM {
+	return &LLM{profile: profile}
+}
+
+func (l *LLM) PromptWithStream(context.Context, *string, ...llms.StreamingPromptOption) llms.Stream {
+	return &stream{profile: l.profile}
+}
+
+type stream struct {
+	profile Profile
+}
+
+func (s *stream) Chunks(ctx context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		for i := range s.profile.ResponseWords {
+			delay := s.profile.LLMTokenInterval
+			if i == 0 {
+				delay = s.profile.LLMFirstToken
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			// End a sentence every 8 words so speech is segmented like a
+			// real response.
+			word := "word "
+			if (i+1)%8 == 0 || i == s.profile.ResponseWords-1 {
+				word = "word. "
+			}
+			if !yield(contentChunk(word), nil) {
+				return
+			}
+		}
+	}
+}
+
+type contentChunk string
+
+func (c contentChunk) FinishReason() *string { return nil }
+func (c contentChunk) Content() string       { return string(c) }
+
+// TextToSpeech synthesizes silence lasting as long as the text would take to
+// be spoken.
+type TextToSpeech struct {
+	profile Profile
+}
+
+func NewTextToSpeech(profile Profile) *TextToSpeech {
+	return &TextToSpeech{profile: profile}
+}
+
+func (t *TextToSpeech) NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
+	options := texttospeech.TextToSpeechOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	g := &speechGenerator{
+		profile: t.profile,
+		options: options,
+		cancel:  cancel,
+		signal:  make(chan struct{}, 1),
+	}
+	go g.generate(ctx)
+	return g, nil
+}
+
+type speechOpType int
+
+const (
+	speechOpText speechOpType = iota
+	speechOpMark
+	speechOpEnd
+)
+
+type speechOp struct {
+	typ  speechOpType
+	text string
+}
+
+// speechGenerator generates speech for the queued operations in order.
+type speechGenerator struct {
+	profile Profile
+	options texttospeech.TextToSpeechOptions
+	cancel  context.CancelFunc
+
+	mu        sync.Mutex
+	ops       []speechOp
+	endOfText bool
+	closed    bool
+	signal    chan struct{}
+}
+
+func (g *speechGenerator) SendText(text string) error {
+	return g.queue(speechOp{typ: speechOpText, text: text})
+}
+
+func (g *speechGenerator) Mark() error {
+	return g.queue(speechOp{typ: speechOpMark})
+}
+
+func (g *speechGenerator) EndOfText() error {
+	g.mu.Lock()
+	endOfText := g.endOfText
+	g.mu.Unlock()
+	if endOfText {
+		return nil
+	}
+	return g.queue(speechOp{typ: speechOpEnd})
+}
+
+func (g *speechGenerator) Cancel() error { return g.Close() }
+
+func (g *speechGenerator) Close() error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+	g.cancel()
+	return nil
+}
+
+func (g *speechGenerator) queue(op speechOp) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return fmt.Errorf("speech generator closed")
+	}
+	if g.endOfText {
+		return fmt.Errorf("end of text already sent")
+	}
+	g.endOfText = op.typ == speechOpEnd
+	g.ops = append(g.ops, op)
+
+	select {
+	case g.signal <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (g *speechGenerator) next(ctx context.Context) (speechOp, bool) {
+	for {
+		g.mu.Lock()
+		if len(g.ops) > 0 {
+			op := g.ops[0]
+			g.ops = g.ops[1:]
+			g.mu.Unlock()
+			return op, true
+		}
+		g.mu.Unlock()
+
+		select {
+		case <-g.signal:
+		case <-ctx.Done():
+			return speechOp{}, false
+		}
+	}
+}
+
+func (g *speechGenerator) generate(ctx context.Context) {
+	defer g.cancel()
+
+	firstAudio := true
+	var transcript strings.Builder
+	for {
+		op, ok := g.next(ctx)
+		if !ok {
+			return
+		}
+
+		switch op.typ {
+		case speechOpText:
+			if firstAudio {
+				firstAudio = false
+				select {
+				case <-time.After(g.profile.TTSFirstAudio):
+				case <-ctx.Done():
+					return
+				}
+			}
+			transcript.WriteString(op.text)
+			if g.options.SpeechAudioCallback != nil {
+				g.options.SpeechAudioCallback(g.silence(op.text))
+			}
+		case speechOpMark:
+			if g.options.SpeechMarkCallback != nil {
+				g.options.SpeechMarkCallback(transcript.String())
+			}
+			transcript.Reset()
+		case speechOpEnd:
+			if g.options.SpeechEndedCallbackV0 != nil {
+				g.options.SpeechEndedCallbackV0(texttospeech.SpeechEndedReport{})
+			}
+			return
+		}
+	}
+}
+
+func (g *speechGenerator) silence(text string) []byte {
+	size := int(g.profile.speechDuration(text) * time.Duration(g.profile.bytesPerSecond()) / time.Second)
+	size -= size % max(g.profile.Encoding.Format.ByteSize(), 1)
+	frame := make([]byte, size)
+	for i := range frame {
+		frame[i] = g.profile.Encoding.SilenceValue()
+	}
+	return frame
+}
+
+// AudioOutput plays audio in real time without a device, confirming marks
+// once the audio sent before them would have been played.
+type AudioOutput struct {
+	profile Profile
+
+	mu           sync.Mutex
+	playingUntil time.Time
+	// generation invalidates pending marks when the buffer is cleared.
+	generation int
+	// lastMark is closed once the latest mark was confirmed, marks are
+	// confirmed one at a time in order like a device would.
+	lastMark chan struct{}
+}
+
+func NewAudioOutput(profile Profile) *AudioOutput {
+	return &AudioOutput{profile: profile}
+}
+
+func (o *AudioOutput) EncodingInfo() audio.EncodingInfo { return o.profile.Encoding }
+
+func (o *AudioOutput) SendAudio(audio []byte) error {
+	bytesPerSecond := o.profile.bytesPerSecond()
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now()
+	if o.playingUntil.Before(now) {
+		o.playingUntil = now
+	}
+	o.playingUntil = o.playingUntil.Add(time.Duration(len(audio)) * time.Second / time.Duration(bytesPerSecond))
+	return nil
+}
+
+func (o *AudioOutput) ClearBuffer() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.playingUntil = time.Now()
+	o.generation++
+}
+
+func (o *AudioOutput) Mark(mark string, callback func(string)) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	generation := o.generation
+	previous, confirmed := o.lastMark, make(chan struct{})
+	o.lastMark = confirmed
+	time.AfterFunc(time.Until(o.playingUntil), func() {
+		defer close(confirmed)
+		if previous != nil {
+			<-previous
+		}
+
+		o.mu.Lock()
+		cleared := o.generation != generation
+		o.mu.Unlock()
+		if !cleared {
+			callback(mark)
+		}
+	})
+	return nil
+}
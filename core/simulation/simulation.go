@@ -0,0 +1,365 @@
+// Package simulation drives concurrent synthetic conversations against
+// orchestrators backed by mock providers and reports latency distributions
+// and resource usage, for capacity planning before production.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// Scenario is the script every simulated conversation follows.
+type Scenario struct {
+	// Prompts are sent one after another, each once the previous turn
+	// finished.
+	Prompts []string
+	// ThinkTime is how long the user waits after a turn before sending the
+	// next prompt.
+	ThinkTime time.Duration
+}
+
+type Options struct {
+	conversations       int
+	scenario            Scenario
+	rampUp              time.Duration
+	profile             Profile
+	turnTimeout         time.Duration
+	sampleInterval      time.Duration
+	orchestratorOptions func(conversation int) []orchestration.OrchestratorOption
+}
+
+type Option func(*Options)
+
+// WithConversations sets how many conversations run concurrently, defaults
+// to 10.
+func WithConversations(n int) Option {
+	return func(o *Options) {
+		o.conversations = n
+	}
+}
+
+// WithScenario sets the script of the conversations, defaults to three short
+// prompts one second apart.
+func WithScenario(scenario Scenario) Option {
+	return func(o *Options) {
+		o.scenario = scenario
+	}
+}
+
+// WithRampUp spreads the start of the conversations evenly over d instead
+// of starting all of them at once.
+func WithRampUp(d time.Duration) Option {
+	return func(o *Options) {
+		o.rampUp = d
+	}
+}
+
+// WithProfile sets the timing of the synthetic providers, defaults to
+// [DefaultProfile].
+func WithProfile(profile Profile) Option {
+	return func(o *Options) {
+		o.profile = profile
+	}
+}
+
+// WithTurnTimeout sets how long a turn may take before it is counted as
+// failed, defaults to 30 seconds.
+func WithTurnTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.turnTimeout = d
+	}
+}
+
+// WithSampleInterval sets how often resource usage is sampled, defaults to
+// 100 milliseconds.
+func WithSampleInterval(d time.Duration) Option {
+	return func(o *Options) {
+		o.sampleInterval = d
+	}
+}
+
+// WithOrchestratorOptions adds options to the orchestrator of each
+// conversation. They are applied after the synthetic providers, so they can
+// replace them, e.g. to measure a real provider under load.
+func WithOrchestratorOptions(options func(conversation int) []orchestration.OrchestratorOption) Option {
+	return func(o *Options) {
+		o.orchestratorOptions = options
+	}
+}
+
+// Report is the outcome of a simulation run.
+type Report struct {
+	Conversations int
+	Turns         int
+	FailedTurns   int
+	Duration      time.Duration
+
+	// TurnLatency is measured from sending a prompt until its turn finished.
+	TurnLatency   Distribution
+	QueueWait     Distribution
+	LLMFirstToken Distribution
+	TTSFirstAudio Distribution
+	PlaybackStart Distribution
+
+	Resources Resources
+}
+
+// Resources is the resource usage of the process during a run.
+type Resources struct {
+	PeakGoroutines int
+	PeakHeapInuse  uint64
+	// TotalAlloc is the number of bytes allocated during the run.
+	TotalAlloc uint64
+	NumGC      uint32
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "conversations=%d turns=%d failed=%d duration=%v\n", r.Conversations, r.Turns, r.FailedTurns, r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "turn:           %v\n", r.TurnLatency)
+	fmt.Fprintf(&b, "queue wait:     %v\n", r.QueueWait)
+	fmt.Fprintf(&b, "llm first:      %v\n", r.LLMFirstToken)
+	fmt.Fprintf(&b, "tts first:      %v\n", r.TTSFirstAudio)
+	fmt.Fprintf(&b, "playback start: %v\n", r.PlaybackStart)
+	fmt.Fprintf(&b, "goroutines=%d heap_inuse=%dKiB alloc=%dKiB gc=%d", r.Resources.PeakGoroutines,
+		r.Resources.PeakHeapInuse/1024, r.Resources.TotalAlloc/1024, r.Resources.NumGC)
+	return b.String()
+}
+
+// Run runs the conversations until all of them followed the scenario or ctx
+// is done, and reports how they performed. Failed turns are counted and
+// reported rather than stopping the run; a soak test ends the run with a ctx
+// deadline and gets the report of everything up to it.
+func Run(ctx context.Context, opts ...Option) (Report, error) {
+	options := Options{
+		conversations: 10,
+		scenario: Scenario{
+			Prompts:   []string{"Hello", "What can you do?", "Thanks, bye"},
+			ThinkTime: time.Second,
+		},
+		profile:        DefaultProfile(),
+		turnTimeout:    30 * time.Second,
+		sampleInterval: 100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.conversations <= 0 {
+		return Report{}, fmt.Errorf("at least one conversation is required")
+	}
+	if len(options.scenario.Prompts) == 0 {
+		return Report{}, fmt.Errorf("scenario has no prompts")
+	}
+
+	sampler := startSampler(options.sampleInterval)
+	manager := orchestration.NewManager()
+	results := &results{}
+	startedAt := time.Now()
+
+	var wg sync.WaitGroup
+	for i := range options.conversations {
+		if i > 0 && options.rampUp > 0 {
+			select {
+			case <-time.After(options.rampUp / time.Duration(options.conversations)):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runConversation(ctx, i, manager, options, results)
+		}()
+	}
+	wg.Wait()
+
+	report := results.report()
+	report.Duration = time.Since(startedAt)
+	report.Resources = sampler.stop()
+	return report, nil
+}
+
+func runConversation(ctx context.Context, conversation int, manager *orchestration.Manager, options Options, results *results) {
+	orchestratorOptions := []orchestration.OrchestratorOption{
+		orchestration.WithStreamingLLM(NewLLM(options.profile)),
+		orchestration.WithTextToSpeechClientV1(NewTextToSpeech(options.profile)),
+		orchestration.WithAudioOutputV1(NewAudioOutput(options.profile)),
+	}
+	if options.orchestratorOptions != nil {
+		orchestratorOptions = append(orchestratorOptions, options.orchestratorOptions(conversation)...)
+	}
+
+	id := fmt.Sprintf("simulation-%d", conversation)
+	orchestrator := orchestration.NewOrchestrator(orchestratorOptions...)
+	if err := manager.Add(id, orchestrator); err != nil {
+		orchestrator.Close()
+		return
+	}
+	defer manager.Remove(id)
+	results.addConversation()
+
+	finished := make(chan events.Event, 1)
+	err := orchestrator.Orchestrate(ctx, orchestration.WithEventCallback(func(event events.Event) {
+		switch event.(type) {
+		case events.TurnCompleted, events.TurnFailed:
+			select {
+			case finished <- event:
+			default:
+			}
+		}
+	}))
+	if err != nil {
+		return
+	}
+
+	for i, prompt := range options.scenario.Prompts {
+		if i > 0 {
+			select {
+			case <-time.After(options.scenario.ThinkTime):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		sentAt := time.Now()
+		orchestrator.SendPrompt(prompt)
+		select {
+		case event := <-finished:
+			if completed, ok := event.(events.TurnCompleted); ok {
+				results.addTurn(time.Since(sentAt), completed.Latency)
+			} else {
+				results.addFailedTurn()
+			}
+		case <-time.After(options.turnTimeout):
+			results.addFailedTurn()
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type results struct {
+	mu sync.Mutex
+
+	conversations int
+	failedTurns   int
+	turn          []time.Duration
+	queueWait     []time.Duration
+	llmFirstToken []time.Duration
+	ttsFirstAudio []time.Duration
+	playbackStart []time.Duration
+}
+
+func (r *results) addConversation() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conversations++
+}
+
+func (r *results) addTurn(turn time.Duration, latency events.LatencyReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.turn = append(r.turn, turn)
+	r.queueWait = append(r.queueWait, latency.QueueWait)
+	appendStage := func(samples []time.Duration, stage time.Duration) []time.Duration {
+		// Zero means the stage did not happen in the turn.
+		if stage == 0 {
+			return samples
+		}
+		return append(samples, stage)
+	}
+	r.llmFirstToken = appendStage(r.llmFirstToken, latency.LLMFirstToken)
+	r.ttsFirstAudio = appendStage(r.ttsFirstAudio, latency.TTSFirstAudio)
+	r.playbackStart = appendStage(r.playbackStart, latency.PlaybackStart)
+}
+
+func (r *results) addFailedTurn() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failedTurns++
+}
+
+func (r *results) report() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Report{
+		Conversations: r.conversations,
+		Turns:         len(r.turn) + r.failedTurns,
+		FailedTurns:   r.failedTurns,
+		TurnLatency:   newDistribution(r.turn),
+		QueueWait:     newDistribution(r.queueWait),
+		LLMFirstToken: newDistribution(r.llmFirstToken),
+		TTSFirstAudio: newDistribution(r.ttsFirstAudio),
+		PlaybackStart: newDistribution(r.playbackStart),
+	}
+}
+
+// sampler tracks peak resource usage in the background.
+type sampler struct {
+	start runtime.MemStats
+	done  chan struct{}
+
+	mu   sync.Mutex
+	peak Resources
+	wg   sync.WaitGroup
+}
+
+func startSampler(interval time.Duration) *sampler {
+	s := &sampler{done: make(chan struct{})}
+	runtime.ReadMemStats(&s.start)
+	s.sample()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(max(interval, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				s.sample()
+			}
+		}
+	}()
+	return s
+}
+
+func (s *sampler) sample() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	goroutines := runtime.NumGoroutine()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.peak.PeakGoroutines = max(s.peak.PeakGoroutines, goroutines)
+	s.peak.PeakHeapInuse = max(s.peak.PeakHeapInuse, stats.HeapInuse)
+}
+
+func (s *sampler) stop() Resources {
+	s.sample()
+	close(s.done)
+	s.wg.Wait()
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resources := s.peak
+	resources.TotalAlloc = stats.TotalAlloc - s.start.TotalAlloc
+	resources.NumGC = stats.NumGC - s.start.NumGC
+	return resources
+}
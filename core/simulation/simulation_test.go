@@ -0,0 +1,69 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+func fastProfile() Profile {
+	return Profile{
+		LLMFirstToken:        5 * time.Millisecond,
+		LLMTokenInterval:     time.Millisecond,
+		ResponseWords:        10,
+		TTSFirstAudio:        5 * time.Millisecond,
+		SpeechCharsPerSecond: 1000,
+		Encoding:             audio.EncodingInfo{SampleRate: 8000, Format: audio.EncodingLinear16},
+	}
+}
+
+func TestRunReportsEveryTurn(t *testing.T) {
+	report, err := Run(context.Background(),
+		WithConversations(4),
+		WithScenario(Scenario{Prompts: []string{"hello", "bye"}, ThinkTime: 5 * time.Millisecond}),
+		WithProfile(fastProfile()),
+		WithTurnTimeout(5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Conversations != 4 || report.Turns != 8 || report.FailedTurns != 0 {
+		t.Fatalf("expected 8 successful turns over 4 conversations, got %+v", report)
+	}
+	if report.TurnLatency.Count != 8 || report.TurnLatency.Min <= 0 {
+		t.Fatalf("expected a turn latency per turn, got %v", report.TurnLatency)
+	}
+	if report.LLMFirstToken.Count != 8 || report.PlaybackStart.Count != 8 {
+		t.Fatalf("expected llm and playback stages in every turn, got %v and %v", report.LLMFirstToken, report.PlaybackStart)
+	}
+	if report.Resources.PeakGoroutines == 0 {
+		t.Fatalf("expected resource usage to be sampled")
+	}
+}
+
+func TestRunRejectsEmptyScenario(t *testing.T) {
+	if _, err := Run(context.Background(), WithScenario(Scenario{})); err == nil {
+		t.Fatalf("expected an error for a scenario without prompts")
+	}
+}
+
+func TestNewDistribution(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	got := newDistribution(samples)
+	if got.Count != 100 || got.Min != time.Millisecond || got.Max != 100*time.Millisecond {
+		t.Fatalf("unexpected bounds: %v", got)
+	}
+	if got.P50 != 50*time.Millisecond || got.P90 != 90*time.Millisecond || got.P99 != 99*time.Millisecond {
+		t.Fatalf("unexpected percentiles: %v", got)
+	}
+	if got.Mean != 50500*time.Microsecond {
+		t.Fatalf("unexpected mean: %v", got.Mean)
+	}
+}
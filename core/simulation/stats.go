@@ -0,0 +1,57 @@
+package simulation
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Distribution summarizes a set of latency samples.
+type Distribution struct {
+	Count int
+	Min   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+func newDistribution(samples []time.Duration) Distribution {
+	if len(samples) == 0 {
+		return Distribution{}
+	}
+
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+
+	var total time.Duration
+	for _, sample := range sorted {
+		total += sample
+	}
+
+	return Distribution{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Mean:  total / time.Duration(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P90:   percentile(sorted, 90),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+func (d Distribution) String() string {
+	if d.Count == 0 {
+		return "n=0"
+	}
+	return fmt.Sprintf("n=%d min=%v mean=%v p50=%v p90=%v p99=%v max=%v", d.Count,
+		d.Min.Round(time.Millisecond), d.Mean.Round(time.Millisecond), d.P50.Round(time.Millisecond),
+		d.P90.Round(time.Millisecond), d.P99.Round(time.Millisecond), d.Max.Round(time.Millisecond))
+}
@@ -0,0 +1,214 @@
+package slots
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Parser extracts the value of a slot from an answer of the user, e.g. a
+// transcript. It fails if the answer holds no valid value.
+type Parser func(answer string) (string, error)
+
+var (
+	errNoDate   = errors.New("no date")
+	errNoNumber = errors.New("no number")
+	errNoOption = errors.New("no option")
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+	"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+	"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+}
+
+// Date parses dates relative to now, e.g. "tomorrow", "on Friday", "October
+// 17th" or "the 3rd of March", and ISO dates, into YYYY-MM-DD. Weekdays are
+// the next such day after today, dates without a year the next such date
+// from today.
+func Date(now func() time.Time) Parser {
+	return func(answer string) (string, error) {
+		today := now()
+		today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+
+		for _, field := range strings.Fields(answer) {
+			if date, err := time.ParseInLocation(time.DateOnly, strings.Trim(field, ".,!?"), today.Location()); err == nil {
+				return date.Format(time.DateOnly), nil
+			}
+		}
+
+		words := words(answer)
+		text := " " + strings.Join(words, " ") + " "
+		switch {
+		case strings.Contains(text, " day after tomorrow "):
+			return today.AddDate(0, 0, 2).Format(time.DateOnly), nil
+		case strings.Contains(text, " tomorrow "):
+			return today.AddDate(0, 0, 1).Format(time.DateOnly), nil
+		case strings.Contains(text, " today "):
+			return today.Format(time.DateOnly), nil
+		}
+
+		for i, word := range words {
+			month, ok := months[word]
+			if !ok {
+				continue
+			}
+			day, ok := dayOfMonth(words, i+1)
+			if !ok && i > 0 {
+				before := i - 1
+				if words[before] == "of" {
+					before--
+				}
+				day, ok = dayOfMonth(words, before)
+			}
+			if !ok {
+				continue
+			}
+
+			year, explicitYear := today.Year(), false
+			if i+2 < len(words) {
+				if y, err := strconv.Atoi(words[i+2]); err == nil && y >= 1000 {
+					year, explicitYear = y, true
+				}
+			}
+			date := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+			if date.Month() != month {
+				return "", fmt.Errorf("%w: %s has no day %d", errNoDate, month, day)
+			}
+			if !explicitYear && date.Before(today) {
+				date = date.AddDate(1, 0, 0)
+			}
+			return date.Format(time.DateOnly), nil
+		}
+
+		for _, word := range words {
+			if weekday, ok := weekdays[word]; ok {
+				days := (int(weekday)-int(today.Weekday())+6)%7 + 1
+				return today.AddDate(0, 0, days).Format(time.DateOnly), nil
+			}
+		}
+		return "", errNoDate
+	}
+}
+
+// dayOfMonth parses words[i] as a day of the month, e.g. "17" or "17th".
+func dayOfMonth(words []string, i int) (int, bool) {
+	if i < 0 || i >= len(words) {
+		return 0, false
+	}
+	word := strings.TrimRight(words[i], "stndrh")
+	day, err := strconv.Atoi(word)
+	if err != nil || day < 1 || day > 31 {
+		return 0, false
+	}
+	return day, true
+}
+
+var spokenDigits = map[string]string{
+	"zero": "0", "oh": "0", "one": "1", "two": "2", "three": "3", "four": "4",
+	"five": "5", "six": "6", "seven": "7", "eight": "8", "nine": "9",
+}
+
+// Digits parses a sequence of minDigits to maxDigits digits, written or
+// spoken one by one, e.g. an account number. Separators between the digits
+// are dropped.
+func Digits(minDigits, maxDigits int) Parser {
+	return func(answer string) (string, error) {
+		var digits strings.Builder
+		for _, field := range strings.FieldsFunc(strings.ToLower(answer), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if digit, ok := spokenDigits[field]; ok {
+				digits.WriteString(digit)
+				continue
+			}
+			for _, r := range field {
+				if unicode.IsDigit(r) {
+					digits.WriteRune(r)
+				}
+			}
+		}
+
+		if n := digits.Len(); n == 0 {
+			return "", errNoNumber
+		} else if n < minDigits || (maxDigits > 0 && n > maxDigits) {
+			return "", fmt.Errorf("%w: %d digits instead of %d to %d", errNoNumber, n, minDigits, maxDigits)
+		}
+		return digits.String(), nil
+	}
+}
+
+// PhoneNumber parses phone numbers of 7 to 15 digits, keeping a leading +.
+func PhoneNumber() Parser {
+	digits := Digits(7, 15)
+	return func(answer string) (string, error) {
+		number, err := digits(answer)
+		if err != nil {
+			return "", err
+		}
+		answer = strings.TrimSpace(answer)
+		if strings.HasPrefix(answer, "+") || strings.HasPrefix(strings.ToLower(answer), "plus") {
+			number = "+" + number
+		}
+		return number, nil
+	}
+}
+
+// Amount parses the first number of the answer, e.g. "$1,250.50" or "about
+// 40 euros", into a decimal without thousands separators.
+func Amount() Parser {
+	return func(answer string) (string, error) {
+		start := strings.IndexFunc(answer, unicode.IsDigit)
+		if start < 0 {
+			return "", errNoNumber
+		}
+
+		var amount strings.Builder
+		for i, r := range answer[start:] {
+			rest := answer[start+i+1:]
+			switch {
+			case unicode.IsDigit(r):
+				amount.WriteRune(r)
+			case r == ',' && len(rest) > 0 && unicode.IsDigit(rune(rest[0])):
+			case r == '.' && len(rest) > 0 && unicode.IsDigit(rune(rest[0])) && !strings.Contains(amount.String(), "."):
+				amount.WriteRune(r)
+			default:
+				return amount.String(), nil
+			}
+		}
+		return amount.String(), nil
+	}
+}
+
+// OneOf parses the first of options mentioned in the answer, ignoring case.
+func OneOf(options ...string) Parser {
+	return func(answer string) (string, error) {
+		text := " " + strings.Join(words(answer), " ") + " "
+		first, value := -1, ""
+		for _, option := range options {
+			i := strings.Index(text, " "+strings.Join(words(option), " ")+" ")
+			if i >= 0 && (first < 0 || i < first) {
+				first, value = i, option
+			}
+		}
+		if first < 0 {
+			return "", errNoOption
+		}
+		return value, nil
+	}
+}
+
+// words splits text into lower case words, dropping punctuation.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
@@ -0,0 +1,325 @@
+// Package slots collects typed values, e.g. dates, phone numbers or amounts,
+// from the user over several turns, re-prompting on invalid answers and
+// confirming the values before they are used.
+//
+// A [Form] is a turn middleware: while it is active, it answers the turns of
+// the user itself, the LLM of the orchestrator sees the exchange in the
+// history afterwards.
+//
+//	form := slots.NewForm([]slots.Slot{
+//		{Name: "date", Prompt: "Which day would you like to come in?", Parse: slots.Date(time.Now)},
+//		{Name: "phone", Prompt: "What number can we reach you on?", Parse: slots.PhoneNumber()},
+//	}, book)
+//	orchestrator := orchestration.NewOrchestrator(orchestration.WithTurnMiddleware(form), ...)
+//	...
+//	prompt := form.Start() // e.g. from a booking tool, asking the first slot
+package slots
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// Slot is a value collected from the user.
+type Slot struct {
+	Name string
+	// Label names the slot in the confirmation, Name by default.
+	Label string
+	// Prompt asks the user for the value, e.g. "What number can we reach
+	// you on?"
+	Prompt string
+	// Reprompt asks again after an answer Parse rejected, by default the
+	// prompt is repeated after an apology.
+	Reprompt string
+	// Parse extracts the value from an answer of the user, it fails if the
+	// answer holds no valid value.
+	Parse Parser
+}
+
+func (s Slot) label() string {
+	if s.Label != "" {
+		return s.Label
+	}
+	return s.Name
+}
+
+func (s Slot) reprompt() string {
+	if s.Reprompt != "" {
+		return s.Reprompt
+	}
+	return "Sorry, I didn't get that. " + s.Prompt
+}
+
+// CompleteFunc receives the confirmed values of a form by slot name. The
+// response it returns is spoken, an empty response leaves responding to the
+// LLM.
+type CompleteFunc func(ctx context.Context, values map[string]string) (response string, err error)
+
+type FormOptions struct {
+	maxAttempts  int
+	confirmation func(values []Value) string
+	confirm      bool
+}
+
+type FormOption func(*FormOptions)
+
+// WithMaxAttempts sets how many invalid answers in a row a slot takes before
+// the form is abandoned and the LLM responds instead, 3 by default.
+func WithMaxAttempts(n int) FormOption {
+	return func(o *FormOptions) {
+		o.maxAttempts = n
+	}
+}
+
+// WithConfirmation sets the question confirming the collected values, by
+// default the values are listed by label.
+func WithConfirmation(confirmation func(values []Value) string) FormOption {
+	return func(o *FormOptions) {
+		o.confirmation = confirmation
+	}
+}
+
+// WithoutConfirmation completes the form as soon as the last slot is
+// filled.
+func WithoutConfirmation() FormOption {
+	return func(o *FormOptions) {
+		o.confirm = false
+	}
+}
+
+// Value is the value collected for a slot.
+type Value struct {
+	Slot  Slot
+	Value string
+}
+
+// Form collects the values of its slots in order, see [Form.Start].
+type Form struct {
+	FormOptions
+	slots    []Slot
+	complete CompleteFunc
+
+	mu         sync.Mutex
+	active     bool
+	next       int
+	values     []Value
+	attempts   int
+	confirming bool
+}
+
+var _ orchestration.TurnMiddleware = (*Form)(nil)
+
+func NewForm(slots []Slot, complete CompleteFunc, opts ...FormOption) *Form {
+	form := &Form{
+		FormOptions: FormOptions{maxAttempts: 3, confirmation: defaultConfirmation, confirm: true},
+		slots:       slots,
+		complete:    complete,
+	}
+	for _, opt := range opts {
+		opt(&form.FormOptions)
+	}
+	return form
+}
+
+// Start activates the form from its first slot, discarding values collected
+// so far, and returns the prompt of the first slot. The following answers of
+// the user are handled by the form until it is completed, abandoned or
+// cancelled.
+func (f *Form) Start() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reset()
+	if len(f.slots) == 0 {
+		return ""
+	}
+	f.active = true
+	return f.slots[0].Prompt
+}
+
+// Cancel deactivates the form, collected values are discarded.
+func (f *Form) Cancel() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reset()
+}
+
+// Active reports whether the form handles the answers of the user.
+func (f *Form) Active() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.active
+}
+
+func (f *Form) reset() {
+	f.active = false
+	f.next = 0
+	f.values = nil
+	f.attempts = 0
+	f.confirming = false
+}
+
+// WrapTurn answers the turns of the user while the form is active.
+func (f *Form) WrapTurn(next orchestration.TurnHandler) orchestration.TurnHandler {
+	return func(ctx context.Context, req orchestration.TurnRequest) (llms.TurnV1, error) {
+		answer, ok := userAnswer(req.Trigger)
+		if !ok {
+			return next(ctx, req)
+		}
+
+		response, err := f.answer(ctx, answer)
+		if err != nil {
+			return llms.TurnV1{}, err
+		}
+		if response != "" {
+			req.LLM = scriptedLLM(response)
+		}
+		return next(ctx, req)
+	}
+}
+
+// answer handles an answer of the user and returns the response to it, an
+// empty response leaves responding to the LLM.
+func (f *Form) answer(ctx context.Context, answer string) (string, error) {
+	f.mu.Lock()
+	if !f.active {
+		f.mu.Unlock()
+		return "", nil
+	}
+
+	if f.confirming {
+		switch confirmationOf(answer) {
+		case confirmed:
+			values := f.valuesByName()
+			f.reset()
+			f.mu.Unlock()
+			return f.completeWith(ctx, values)
+		case rejected:
+			f.reset()
+			f.active = true
+			f.mu.Unlock()
+			return "Let's try again. " + f.slots[0].Prompt, nil
+		default:
+			return f.retryLocked(func() string { return "Sorry, is that right? Please answer yes or no." })
+		}
+	}
+
+	slot := f.slots[f.next]
+	value, err := slot.Parse(answer)
+	if err != nil {
+		return f.retryLocked(slot.reprompt)
+	}
+	f.values = append(f.values, Value{Slot: slot, Value: value})
+	f.attempts = 0
+	f.next++
+	if f.next < len(f.slots) {
+		defer f.mu.Unlock()
+		return f.slots[f.next].Prompt, nil
+	}
+	if f.confirm {
+		defer f.mu.Unlock()
+		f.confirming = true
+		return f.confirmation(f.values), nil
+	}
+
+	values := f.valuesByName()
+	f.reset()
+	f.mu.Unlock()
+	return f.completeWith(ctx, values)
+}
+
+// retryLocked counts an invalid answer and returns the prompt asking again,
+// or abandons the form after too many. f.mu must be held, it is released.
+func (f *Form) retryLocked(prompt func() string) (string, error) {
+	defer f.mu.Unlock()
+
+	f.attempts++
+	if f.maxAttempts > 0 && f.attempts >= f.maxAttempts {
+		f.reset()
+		return "", nil
+	}
+	return prompt(), nil
+}
+
+func (f *Form) completeWith(ctx context.Context, values map[string]string) (string, error) {
+	if f.complete == nil {
+		return "", nil
+	}
+	response, err := f.complete(ctx, values)
+	if err != nil {
+		return "", fmt.Errorf("failed to complete form: %w", err)
+	}
+	return response, nil
+}
+
+func (f *Form) valuesByName() map[string]string {
+	values := make(map[string]string, len(f.values))
+	for _, value := range f.values {
+		values[value.Slot.Name] = value.Value
+	}
+	return values
+}
+
+func defaultConfirmation(values []Value) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, value.Slot.label()+" "+value.Value)
+	}
+	return "Just to confirm: " + strings.Join(parts, ", ") + ". Is that right?"
+}
+
+// userAnswer returns what the user said or typed to start the turn.
+func userAnswer(trigger llms.TriggerV0) (string, bool) {
+	switch t := trigger.(type) {
+	case triggers.UserPromptTrigger:
+		return t.Prompt, true
+	case triggers.TranscriptionTrigger:
+		return t.Transcript(), true
+	default:
+		return "", false
+	}
+}
+
+type confirmationAnswer int
+
+const (
+	unclear confirmationAnswer = iota
+	confirmed
+	rejected
+)
+
+func confirmationOf(answer string) confirmationAnswer {
+	for _, word := range words(answer) {
+		switch word {
+		case "yes", "yeah", "yep", "yup", "correct", "right", "sure", "exactly", "ok", "okay":
+			return confirmed
+		case "no", "nope", "wrong", "incorrect", "not":
+			return rejected
+		}
+	}
+	return unclear
+}
+
+// scriptedLLM is a streaming LLM responding with a fixed response.
+type scriptedLLM string
+
+func (l scriptedLLM) PromptWithStream(context.Context, *string, ...llms.StreamingPromptOption) llms.Stream {
+	return l
+}
+
+func (l scriptedLLM) Chunks(context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		yield(contentChunk(l), nil)
+	}
+}
+
+type contentChunk string
+
+func (c contentChunk) FinishReason() *string { return nil }
+func (c contentChunk) Content() string       { return string(c) }
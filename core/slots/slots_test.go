@@ -0,0 +1,121 @@
+package slots
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestFormCollectsAndConfirmsSlots(t *testing.T) {
+	now := func() time.Time { return time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC) }
+	var completed map[string]string
+	form := NewForm([]Slot{
+		{Name: "date", Prompt: "Which day?", Parse: Date(now)},
+		{Name: "phone", Label: "phone number", Prompt: "What number?", Parse: PhoneNumber()},
+	}, func(_ context.Context, values map[string]string) (string, error) {
+		completed = values
+		return "Booked.", nil
+	})
+
+	const llmResponse = "llm response"
+	handler := form.WrapTurn(func(ctx context.Context, req orchestration.TurnRequest) (llms.TurnV1, error) {
+		llm, ok := req.LLM.(orchestration.LLMWithStream)
+		if !ok {
+			return llms.TurnV1{Responses: []llms.TurnResponseV0{{Message: llmResponse}}}, nil
+		}
+		var response strings.Builder
+		for chunk, err := range llm.PromptWithStream(ctx, nil).Chunks(ctx) {
+			if err != nil {
+				return llms.TurnV1{}, err
+			}
+			if chunk, ok := chunk.(llms.StreamContentChunk); ok {
+				response.WriteString(chunk.Content())
+			}
+		}
+		return llms.TurnV1{Responses: []llms.TurnResponseV0{{Message: response.String()}}}, nil
+	})
+	say := func(prompt string) string {
+		t.Helper()
+		turn, err := handler(context.Background(), orchestration.TurnRequest{Trigger: triggers.NewUserPromptTrigger(prompt)})
+		if err != nil {
+			t.Fatalf("turn %q failed: %v", prompt, err)
+		}
+		return turn.Responses[0].Message
+	}
+
+	if got := say("hello"); got != llmResponse {
+		t.Fatalf("inactive form answered %q", got)
+	}
+	if got := form.Start(); got != "Which day?" {
+		t.Fatalf("start prompt = %q", got)
+	}
+
+	steps := []struct{ say, want string }{
+		{"whenever", "Sorry, I didn't get that. Which day?"},
+		{"next Friday please", "What number?"},
+		{"five five five, one two three four", "Just to confirm: date 2026-10-23, phone number 5551234. Is that right?"},
+		{"no", "Let's try again. Which day?"},
+		{"tomorrow", "What number?"},
+		{"555 1234", "Just to confirm: date 2026-10-17, phone number 5551234. Is that right?"},
+		{"yes, correct", "Booked."},
+	}
+	for _, step := range steps {
+		if got := say(step.say); got != step.want {
+			t.Fatalf("answer to %q = %q, want %q", step.say, got, step.want)
+		}
+	}
+	if completed["date"] != "2026-10-17" || completed["phone"] != "5551234" {
+		t.Fatalf("completed with %v", completed)
+	}
+	if form.Active() {
+		t.Fatal("form still active after completion")
+	}
+
+	form.Start()
+	for range 2 {
+		say("no idea")
+	}
+	if got := say("no idea"); got != llmResponse || form.Active() {
+		t.Fatalf("form not abandoned after max attempts, answered %q", got)
+	}
+}
+
+func TestParsers(t *testing.T) {
+	now := func() time.Time { return time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name   string
+		parse  Parser
+		answer string
+		want   string
+	}{
+		{"iso date", Date(now), "on 2027-01-02 please", "2027-01-02"},
+		{"month day", Date(now), "October 20th", "2026-10-20"},
+		{"day month past", Date(now), "the 3rd of March", "2027-03-03"},
+		{"weekday today", Date(now), "Friday", "2026-10-23"},
+		{"day after tomorrow", Date(now), "the day after tomorrow", "2026-10-18"},
+		{"invalid day", Date(now), "February 30", ""},
+		{"phone", PhoneNumber(), "+44 20 7946 0958", "+442079460958"},
+		{"short phone", PhoneNumber(), "one two three", ""},
+		{"amount", Amount(), "about $1,250.50 in total", "1250.50"},
+		{"no amount", Amount(), "a lot", ""},
+		{"one of", OneOf("credit card", "cash"), "cash, or credit card", "cash"},
+		{"none of", OneOf("credit card", "cash"), "cheque", ""},
+	}
+	for _, test := range tests {
+		got, err := test.parse(test.answer)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%s: parsed %q from %q, want error", test.name, got, test.answer)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("%s: parsed %q, %v from %q, want %q", test.name, got, err, test.answer, test.want)
+		}
+	}
+}
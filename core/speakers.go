@@ -0,0 +1,116 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/koscakluka/ema-core/core/conversations"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// WithSpeakerSpeechToText transcribes a separate audio stream of the user
+// identified by speakerID with client, e.g. a participant of a group call.
+// Audio of the stream is sent with [Orchestrator.SendSpeakerAudio], in the
+// encoding of the audio input.
+//
+// Events and triggers of the stream are attributed to speakerID, as are the
+// turns they start. Diarizing speech-to-text clients attribute the speech of
+// a single stream themselves, see [speechtotext.WithSpeakerChangedCallback].
+func WithSpeakerSpeechToText(speakerID string, client SpeechToText) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if o.speakerSpeechToText == nil {
+			o.speakerSpeechToText = map[string]*speechToText{}
+		}
+		stt := newSpeechToText(client)
+		stt.speakerID = speakerID
+		o.speakerSpeechToText[speakerID] = stt
+	}
+}
+
+// SendSpeakerAudio sends audio of the user identified by speakerID to the
+// speech-to-text client set for them with [WithSpeakerSpeechToText].
+func (o *Orchestrator) SendSpeakerAudio(speakerID string, audio []byte) error {
+	stt, ok := o.speakerSpeechToText[speakerID]
+	if !ok {
+		return fmt.Errorf("no speech-to-text client for speaker %q", speakerID)
+	}
+	if o.IsMicrophoneMuted() || o.compliance.dropsAudio() {
+		return nil
+	}
+	return stt.SendAudio(audio)
+}
+
+// AddressingPolicyV0 decides whether the assistant responds to trigger, a
+// prompt or transcript of a user, in conversations with several users.
+// Triggers it declines are dropped without starting a turn.
+type AddressingPolicyV0 func(ctx context.Context, trigger llms.TriggerV0, conversation conversations.ActiveContextV0) bool
+
+// WithAddressingPolicy sets who the assistant responds to, by default it
+// responds to everyone.
+func WithAddressingPolicy(policy AddressingPolicyV0) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.addressingPolicy = policy
+	}
+}
+
+// RespondToSpeakers responds only to the users identified by speakerIDs, and
+// to triggers not attributed to any user.
+func RespondToSpeakers(speakerIDs ...string) AddressingPolicyV0 {
+	return func(_ context.Context, trigger llms.TriggerV0, _ conversations.ActiveContextV0) bool {
+		speakerID := speakerOf(trigger)
+		return speakerID == "" || slices.Contains(speakerIDs, speakerID)
+	}
+}
+
+// RespondWhenAddressed responds to users mentioning one of names, e.g. the
+// name of the assistant, and keeps responding to the user it last responded
+// to until another user addresses it, like a meeting participant would.
+func RespondWhenAddressed(names ...string) AddressingPolicyV0 {
+	return func(_ context.Context, trigger llms.TriggerV0, conversation conversations.ActiveContextV0) bool {
+		speakerID := speakerOf(trigger)
+		if speakerID == "" {
+			return true
+		}
+
+		text := strings.ToLower(trigger.String())
+		for _, name := range names {
+			if strings.Contains(text, strings.ToLower(name)) {
+				return true
+			}
+		}
+
+		lastSpeakerID := ""
+		if activeTurn := conversation.ActiveTurn(); activeTurn != nil {
+			lastSpeakerID = activeTurn.SpeakerID
+		} else if history := conversation.History(); len(history) > 0 {
+			lastSpeakerID = history[len(history)-1].SpeakerID
+		}
+		return speakerID == lastSpeakerID
+	}
+}
+
+// isAddressed reports whether the assistant responds to trigger under the
+// addressing policy. Only user prompts and transcripts are subject to it.
+func (o *Orchestrator) isAddressed(ctx context.Context, trigger llms.TriggerV0) bool {
+	if o.addressingPolicy == nil {
+		return true
+	}
+
+	switch trigger.(type) {
+	case triggers.UserPromptTrigger, triggers.TranscriptionTrigger:
+		return o.addressingPolicy(ctx, trigger, &o.conversation)
+	default:
+		return true
+	}
+}
+
+// speakerOf returns the ID of the user trigger is attributed to.
+func speakerOf(trigger llms.TriggerV0) string {
+	if attributed, ok := trigger.(interface{ SpeakerID() string }); ok {
+		return attributed.SpeakerID()
+	}
+	return ""
+}
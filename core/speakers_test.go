@@ -0,0 +1,75 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestSpeakerStreamsAreAttributed(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	var mu sync.Mutex
+	transcribe := map[string]func(string){}
+	speakerStub := func(speakerID string) *speechToTextClientStub {
+		return &speechToTextClientStub{transcribe: func(opts speechtotext.TranscriptionOptions) {
+			mu.Lock()
+			defer mu.Unlock()
+			transcribe[speakerID] = opts.TranscriptionCallback
+		}}
+	}
+	var completed atomic.Int32
+	var finals []events.UserTranscriptFinal
+	o := NewOrchestrator(
+		WithLLM(llm),
+		WithSpeakerSpeechToText("alice", speakerStub("alice")),
+		WithSpeakerSpeechToText("bob", speakerStub("bob")),
+		WithAddressingPolicy(RespondWhenAddressed("ema")),
+	)
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		switch typedEvent := event.(type) {
+		case events.UserTranscriptFinal:
+			mu.Lock()
+			finals = append(finals, typedEvent)
+			mu.Unlock()
+		case events.TurnCompleted:
+			completed.Add(1)
+		}
+	}))
+
+	mu.Lock()
+	transcribeAlice, transcribeBob := transcribe["alice"], transcribe["bob"]
+	mu.Unlock()
+	if transcribeAlice == nil || transcribeBob == nil {
+		t.Fatal("expected a transcription per speaker to be started")
+	}
+
+	transcribeAlice("Ema, what's on the agenda?")
+	waitForCondition(t, 2*time.Second, "first turn completed", func() bool { return completed.Load() == 1 })
+	o.HandleTrigger(triggers.NewTranscriptionTrigger("did you see the game", triggers.WithSpeaker("bob")))
+	o.HandleTrigger(triggers.NewTranscriptionTrigger("and who is presenting?", triggers.WithSpeaker("alice")))
+	waitForCondition(t, 2*time.Second, "second turn completed", func() bool { return completed.Load() == 2 })
+	transcribeBob("see you later")
+
+	history := o.conversation.History()
+	if len(history) != 2 || history[0].SpeakerID != "alice" || history[1].SpeakerID != "alice" {
+		t.Fatalf("expected only the turns of the addressing speaker, got %+v", history)
+	}
+	if history[0].Trigger.String() != "Ema, what's on the agenda?" {
+		t.Fatalf("unexpected first turn %+v", history[0])
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(finals) != 2 || finals[0].SpeakerID != "alice" || finals[1].SpeakerID != "bob" {
+		t.Fatalf("expected attributed transcript events, got %+v", finals)
+	}
+}
@@ -1,11 +1,14 @@
 package orchestration
 
 import (
+	"slices"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
 	events "github.com/koscakluka/ema-core/core/events"
 )
 
@@ -19,6 +22,12 @@ type speechPlayer struct {
 	audioBuffer *audioBuffer
 	text        []string
 	playedMarks int
+	// releasedText is the confirmed text released from text, it precedes
+	// it, see [WithBoundedPlaybackMemory].
+	releasedText string
+	// wordTimestamps is the timing of the words of the speech, reported by
+	// TTS clients that support it, see [speechPlayer.AddWordTimestamps].
+	wordTimestamps []events.SpeechWordTimestamp
 
 	lastEmittedSpokenText       string
 	hasEmittedSpokenText        bool
@@ -26,12 +35,23 @@ type speechPlayer struct {
 
 	segmentationBoundaries string
 	emitEvent              eventEmitter
+	// borrowFrames emits playback frames assembled in pooled buffers as
+	// borrowed frames, see [WithBorrowedAudioFrames].
+	borrowFrames bool
+	// clock drives playback progress approximation and the spoken-text
+	// emitter, see [WithClock].
+	clock clock.Clock
+	// releasePlayed and releaseRetention are set by
+	// [WithBoundedPlaybackMemory].
+	releasePlayed    bool
+	releaseRetention time.Duration
 }
 
 func newSpeechPlayer() *speechPlayer {
 	return &speechPlayer{
 		textBuffer: newTextBuffer(),
 		emitEvent:  noopEventEmitter,
+		clock:      clock.Real(),
 	}
 }
 
@@ -39,8 +59,12 @@ func (p *speechPlayer) InitBuffers(encodingInfo audio.EncodingInfo, segmentation
 	p.lockFor(func() {
 		p.textBuffer = newTextBuffer()
 		p.audioBuffer = newAudioBuffer(encodingInfo)
+		p.audioBuffer.clock = p.clock
+		p.audioBuffer.onMarksTimedOut = p.marksTimedOut
 		p.text = nil
 		p.playedMarks = 0
+		p.releasedText = ""
+		p.wordTimestamps = nil
 		p.lastEmittedSpokenText = ""
 		p.hasEmittedSpokenText = false
 		p.lastEmittedPlaybackPlayhead = 0
@@ -54,6 +78,12 @@ func (p *speechPlayer) AddTextChunk(chunk string) {
 	}
 }
 
+// ReviseText replaces the end of the added text matching replaced with
+// replacement. Replaced text already passed on to speech is still spoken.
+func (p *speechPlayer) ReviseText(replaced, replacement string) {
+	p.withTextBuffer(func(textBuffer *textBuffer) { textBuffer.ReplaceTail(replaced, replacement) })
+}
+
 func (p *speechPlayer) TextOrMarks(yield func(textOrMark) bool) {
 	var textBuffer *textBuffer
 	var segmentationBoundaries string
@@ -121,6 +151,14 @@ func (p *speechPlayer) AddMark(isTerminal ...bool) {
 	terminal := len(isTerminal) > 0 && isTerminal[0]
 	p.withAudioBuffer(func(audioBuffer *audioBuffer) { audioBuffer.Mark(terminal) })
 }
+
+// AddWordTimestamps adds the timing of words of the speech, used instead of
+// estimating the spoken text of the segment being played. Timestamps are
+// offsets from the start of the added audio.
+func (p *speechPlayer) AddWordTimestamps(words []events.SpeechWordTimestamp) {
+	p.lockFor(func() { p.wordTimestamps = append(p.wordTimestamps, words...) })
+}
+
 func (p *speechPlayer) FinishAudio() {
 	p.withAudioBuffer(func(audioBuffer *audioBuffer) { audioBuffer.AllAudioLoaded() })
 }
@@ -192,17 +230,16 @@ func (p *speechPlayer) runProgressEmitter(done <-chan struct{}) {
 		return
 	}
 
-	nextUpdate := p.emitPlaybackProgress()
-	timer := time.NewTimer(clampSpokenTextUpdateInterval(nextUpdate))
-	defer timer.Stop()
+	var playerClock clock.Clock
+	p.rLockFor(func() { playerClock = p.clock })
 
+	nextUpdate := p.emitPlaybackProgress()
 	for {
 		select {
 		case <-done:
 			return
-		case <-timer.C:
+		case <-playerClock.After(clampSpokenTextUpdateInterval(nextUpdate)):
 			nextUpdate = p.emitPlaybackProgress()
-			timer.Reset(clampSpokenTextUpdateInterval(nextUpdate))
 		}
 	}
 }
@@ -216,13 +253,22 @@ func (p *speechPlayer) emitPlaybackProgress() time.Duration {
 	var spokenDelta string
 	emitSpokenText := false
 	var frame []byte
+	var borrowFrame bool
+	var pooledFrame []byte
 	nextUpdate := defaultApproximateUpdateDelay
 	p.lockFor(func() {
 		if p.audioBuffer == nil {
 			return
 		}
 
-		progress, delta, approxPlayhead, updateDelay := p.audioBuffer.ApproximateProgressAndPlaybackDelta(p.lastEmittedPlaybackPlayhead)
+		var newFrame func(size int) []byte
+		if borrowFrame = p.borrowFrames; borrowFrame {
+			newFrame = func(size int) []byte {
+				pooledFrame = audio.GetFrame(size)
+				return pooledFrame
+			}
+		}
+		progress, delta, approxPlayhead, updateDelay := p.audioBuffer.ApproximateProgressAndPlaybackDelta(p.lastEmittedPlaybackPlayhead, newFrame)
 		nextUpdate = updateDelay
 		if approxPlayhead > p.lastEmittedPlaybackPlayhead {
 			p.lastEmittedPlaybackPlayhead = approxPlayhead
@@ -231,7 +277,9 @@ func (p *speechPlayer) emitPlaybackProgress() time.Duration {
 			frame = delta
 		}
 
-		spokenText, spokenDelta, emitSpokenText = p.nextSpokenTextUpdateLocked(progress)
+		segmentStart, played := p.audioBuffer.PlaybackPosition()
+		spokenText, spokenDelta, emitSpokenText = p.nextSpokenTextUpdateLocked(progress, segmentStart, played)
+		p.releasePlayedLocked()
 	})
 
 	if emitSpokenText {
@@ -240,14 +288,42 @@ func (p *speechPlayer) emitPlaybackProgress() time.Duration {
 	}
 
 	if len(frame) > 0 {
-		p.emitEvent(events.NewAssistantPlaybackFrame(frame))
+		if borrowFrame {
+			p.emitEvent(events.NewBorrowedAssistantPlaybackFrame(frame))
+		} else {
+			p.emitEvent(events.NewAssistantPlaybackFrame(frame))
+		}
+	}
+	if pooledFrame != nil {
+		audio.PutFrame(pooledFrame)
 	}
 
 	return nextUpdate
 }
 
-func (p *speechPlayer) nextSpokenTextUpdateLocked(currentSegmentProgress float64) (string, string, bool) {
-	spokenText := p.approximateSpokenTextSoFarLocked(currentSegmentProgress)
+// releasePlayedLocked releases the audio emitted as playback frames and the
+// text of confirmed marks, see [WithBoundedPlaybackMemory].
+func (p *speechPlayer) releasePlayedLocked() {
+	if !p.releasePlayed {
+		return
+	}
+
+	if p.playedMarks > 0 {
+		played := min(p.playedMarks, len(p.text))
+		p.releasedText += strings.Join(p.text[:played], "")
+		p.text = slices.Delete(p.text, 0, played)
+		p.playedMarks -= played
+	}
+	p.audioBuffer.Release(p.lastEmittedPlaybackPlayhead, p.releaseRetention)
+
+	segmentStart, _ := p.audioBuffer.PlaybackPosition()
+	p.wordTimestamps = slices.DeleteFunc(p.wordTimestamps, func(word events.SpeechWordTimestamp) bool {
+		return word.End <= segmentStart
+	})
+}
+
+func (p *speechPlayer) nextSpokenTextUpdateLocked(currentSegmentProgress float64, segmentStart, played time.Duration) (string, string, bool) {
+	spokenText := p.approximateSpokenTextSoFarLocked(currentSegmentProgress, segmentStart, played)
 
 	previousSpokenText := p.lastEmittedSpokenText
 	hasPreviousEmission := p.hasEmittedSpokenText
@@ -279,9 +355,37 @@ func (p *speechPlayer) Snapshot() *speechPlayer {
 
 	snapshot := newSpeechPlayer()
 	snapshot.SetEventEmitter(p.emitEvent)
+	p.rLockFor(func() {
+		snapshot.borrowFrames = p.borrowFrames
+		snapshot.clock = p.clock
+		snapshot.releasePlayed = p.releasePlayed
+		snapshot.releaseRetention = p.releaseRetention
+	})
 	return snapshot
 }
 
+// Progress returns the approximate spoken text so far, as emitted with
+// [events.AssistantPlaybackTranscriptUpdated], and the playback state of the
+// audio buffer, see [audioBuffer.Progress].
+func (p *speechPlayer) Progress() (spokenText string, remaining time.Duration, paused, allAudioLoaded bool, ok bool) {
+	var audioBuffer *audioBuffer
+	p.rLockFor(func() { audioBuffer = p.audioBuffer })
+	if audioBuffer == nil {
+		return "", 0, false, false, false
+	}
+
+	segmentProgress, remaining, paused, allAudioLoaded := audioBuffer.Progress()
+	segmentStart, played := audioBuffer.PlaybackPosition()
+	p.rLockFor(func() { spokenText = p.approximateSpokenTextSoFarLocked(segmentProgress, segmentStart, played) })
+	return spokenText, remaining, paused, allAudioLoaded, true
+}
+
+func (p *speechPlayer) marksTimedOut(pending int) {
+	var emitEvent eventEmitter
+	p.rLockFor(func() { emitEvent = p.emitEvent })
+	emitEvent(events.NewPlaybackMarksTimedOut(pending))
+}
+
 func (p *speechPlayer) SetEventEmitter(emitEvent eventEmitter) {
 	if p == nil {
 		return
@@ -319,7 +423,7 @@ func (p *speechPlayer) SpokenTextSoFar() string {
 	var s string
 	p.rLockFor(func() {
 		if p.playedMarks <= 0 || len(p.text) == 0 {
-			s = ""
+			s = p.releasedText
 			return
 		}
 
@@ -329,6 +433,7 @@ func (p *speechPlayer) SpokenTextSoFar() string {
 		}
 
 		var spoken strings.Builder
+		spoken.WriteString(p.releasedText)
 		for i := 0; i < maxSegments; i++ {
 			spoken.WriteString(p.text[i])
 		}
@@ -338,7 +443,12 @@ func (p *speechPlayer) SpokenTextSoFar() string {
 	return s
 
 }
-func (p *speechPlayer) approximateSpokenTextSoFarLocked(currentSegmentProgress float64) string {
+
+// approximateSpokenTextSoFarLocked returns the text of the confirmed segments
+// and the spoken part of the segment being played, from the word timestamps
+// played since segmentStart when there are any, otherwise estimated from
+// currentSegmentProgress.
+func (p *speechPlayer) approximateSpokenTextSoFarLocked(currentSegmentProgress float64, segmentStart, played time.Duration) string {
 	if p == nil {
 		return ""
 	}
@@ -355,29 +465,115 @@ func (p *speechPlayer) approximateSpokenTextSoFarLocked(currentSegmentProgress f
 	}
 
 	var spoken strings.Builder
+	spoken.WriteString(p.releasedText)
 	for i := 0; i < maxSegments; i++ {
 		spoken.WriteString(p.text[i])
 	}
 
-	if currentSegmentProgress == 0 || maxSegments >= len(p.text) {
+	if maxSegments >= len(p.text) {
+		return spoken.String()
+	}
+	if len(p.wordTimestamps) > 0 {
+		spoken.WriteString(p.text[maxSegments][:p.spokenTimestampedLenLocked(p.text[maxSegments], segmentStart, played)])
+		return spoken.String()
+	}
+	if currentSegmentProgress == 0 {
 		return spoken.String()
 	}
 
 	currentSegmentRunes := []rune(p.text[maxSegments])
-	currentSegmentLen := len(currentSegmentRunes)
-	if currentSegmentLen == 0 {
+	if len(currentSegmentRunes) == 0 {
 		return spoken.String()
 	}
 
-	charsToShow := int(float64(currentSegmentLen) * currentSegmentProgress)
-	if charsToShow > currentSegmentLen {
-		charsToShow = currentSegmentLen
+	// Runes are shown once their estimated speech ended, the segment audio
+	// does not play at an even rate per character.
+	durations := estimateSpeechDurations(currentSegmentRunes)
+	var total float64
+	for _, duration := range durations {
+		total += duration
+	}
+	elapsed := total * currentSegmentProgress
+	runesToShow := 0
+	for _, duration := range durations {
+		if elapsed < duration-1e-9 {
+			break
+		}
+		elapsed -= duration
+		runesToShow++
 	}
 
-	spoken.WriteString(string(currentSegmentRunes[:charsToShow]))
+	spoken.WriteString(string(currentSegmentRunes[:runesToShow]))
 	return spoken.String()
 }
 
+// spokenTimestampedLenLocked returns the length of the start of segment
+// spoken by the end of played, matching the words timed to end after
+// segmentStart in order. Words not found in the segment are skipped.
+func (p *speechPlayer) spokenTimestampedLenLocked(segment string, segmentStart, played time.Duration) int {
+	spokenLen := 0
+	for _, word := range p.wordTimestamps {
+		if word.End <= segmentStart {
+			continue
+		} else if word.End > played {
+			break
+		}
+
+		if i := strings.Index(segment[spokenLen:], word.Word); i >= 0 && word.Word != "" {
+			spokenLen += i + len(word.Word)
+		}
+	}
+	return spokenLen
+}
+
+// estimateSpeechDurations estimates how long each rune of text takes to
+// speak, relative to a letter of a word. Digits and symbols are read as
+// words, abbreviations letter by letter and punctuation adds a pause.
+func estimateSpeechDurations(text []rune) []float64 {
+	durations := make([]float64, len(text))
+	for i := 0; i < len(text); {
+		if !unicode.IsLetter(text[i]) {
+			durations[i] = estimateSpeechDuration(text[i])
+			i++
+			continue
+		}
+
+		end := i
+		isAbbreviation := true
+		for end < len(text) && unicode.IsLetter(text[end]) {
+			isAbbreviation = isAbbreviation && unicode.IsUpper(text[end])
+			end++
+		}
+		isAbbreviation = isAbbreviation && end-i > 1
+		for ; i < end; i++ {
+			durations[i] = 1
+			if isAbbreviation {
+				durations[i] = 2.5
+			}
+		}
+	}
+	return durations
+}
+
+func estimateSpeechDuration(r rune) float64 {
+	switch {
+	case unicode.IsDigit(r):
+		return 3.5
+	case unicode.IsSpace(r):
+		return 0.5
+	case strings.ContainsRune(".!?", r):
+		return 4
+	case strings.ContainsRune(",;:", r):
+		return 2
+	case strings.ContainsRune("%$€£&+=@#/", r):
+		return 5
+	case unicode.IsPunct(r):
+		return 0.5
+	default:
+		return 1
+	}
+}
+
 func (p *speechPlayer) withTextBuffer(f func(*textBuffer)) {
 	var textBuffer *textBuffer
 	p.rLockFor(func() {
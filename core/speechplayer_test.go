@@ -2,10 +2,12 @@ package orchestration
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
 	events "github.com/koscakluka/ema-core/core/events"
 )
 
@@ -16,7 +18,7 @@ func setTextSegments(player *speechPlayer, segments ...string) {
 func emitSpokenProgress(player *speechPlayer, progress float64) {
 	spokenText, spokenDelta, emit := "", "", false
 	player.lockFor(func() {
-		spokenText, spokenDelta, emit = player.nextSpokenTextUpdateLocked(progress)
+		spokenText, spokenDelta, emit = player.nextSpokenTextUpdateLocked(progress, 0, 0)
 	})
 	if !emit {
 		return
@@ -32,7 +34,7 @@ func confirmSpokenMark(player *speechPlayer) {
 
 func approximateSpokenText(player *speechPlayer, progress float64) (spoken string) {
 	player.rLockFor(func() {
-		spoken = player.approximateSpokenTextSoFarLocked(progress)
+		spoken = player.approximateSpokenTextSoFarLocked(progress, 0, 0)
 	})
 	return spoken
 }
@@ -118,6 +120,24 @@ func TestSpeechPlayerApproximateSpokenTextSoFarIncludesCurrentSegment(t *testing
 	}
 }
 
+func TestSpeechPlayerApproximateSpokenTextSoFarWeightsDigitsAndAbbreviations(t *testing.T) {
+	player := newSpeechPlayer()
+
+	// Read out, the digits take about half of the segment.
+	setTextSegments(player, "2024 was a good year.")
+	if got := approximateSpokenText(player, 0.5); got != "2024 w" {
+		t.Fatalf("expected approximate spoken text %q, got %q", "2024 w", got)
+	}
+
+	setTextSegments(player, "The FBI agent, sure.")
+	if got := approximateSpokenText(player, 0.5); got != "The FBI ag" {
+		t.Fatalf("expected approximate spoken text %q, got %q", "The FBI ag", got)
+	}
+	if got := approximateSpokenText(player, 0.75); got != "The FBI agent, s" {
+		t.Fatalf("expected approximate spoken text %q, got %q", "The FBI agent, s", got)
+	}
+}
+
 func TestSpeechPlayerApproximateSpokenTextSoFarClampsProgress(t *testing.T) {
 	player := newSpeechPlayer()
 
@@ -651,3 +671,179 @@ func TestSpeechPlayerEmitApproximatePlaybackFrameSkipsRegression(t *testing.T) {
 		t.Fatalf("expected regression to not emit extra playback frame, got %d", len(frames))
 	}
 }
+
+func TestSpeechPlayerEmitsBorrowedPlaybackFrames(t *testing.T) {
+	player := newSpeechPlayer()
+	player.borrowFrames = true
+	player.InitBuffers(audio.GetDefaultEncodingInfo(), "")
+
+	var borrowed []events.AssistantPlaybackFrame
+	var retained [][]byte
+	player.SetEventEmitter(func(event events.Event) {
+		if playbackFrame, ok := event.(events.AssistantPlaybackFrame); ok {
+			borrowed = append(borrowed, playbackFrame)
+			retained = append(retained, playbackFrame.Retain().Audio)
+		}
+	})
+
+	player.AddAudio([]byte{1, 2})
+	player.AddAudio([]byte{3, 4})
+
+	player.audioBuffer.mu.Lock()
+	player.audioBuffer.internalPlayhead = 2
+	player.audioBuffer.lastMarkTimestamp = time.Now().Add(-2 * time.Second)
+	player.audioBuffer.mu.Unlock()
+
+	emitPlaybackProgress(player)
+
+	if len(borrowed) != 1 || !borrowed[0].Borrowed() {
+		t.Fatalf("expected a single borrowed playback frame, got %+v", borrowed)
+	}
+	if !bytes.Equal(retained[0], []byte{1, 2, 3, 4}) {
+		t.Fatalf("expected retained playback frame %v, got %v", []byte{1, 2, 3, 4}, retained[0])
+	}
+	if snapshot := player.Snapshot(); !snapshot.borrowFrames {
+		t.Fatal("expected snapshot to keep borrowing frames")
+	}
+}
+
+func TestSpeechPlayerReviseTextDropsTextNotYetPassedOn(t *testing.T) {
+	player := newSpeechPlayer()
+	player.AddTextChunk("The total is ")
+	player.AddTextChunk("5 euros.")
+
+	var passedOn []string
+	textBuffer := player.textBuffer
+	textBuffer.Chunks(func(chunk string) bool {
+		passedOn = append(passedOn, chunk)
+		return false
+	})
+
+	player.ReviseText("is 5 euros.", "is 7 euros.")
+	player.ReviseText("Unknown", "")
+	player.TextComplete()
+	textBuffer.Chunks(func(chunk string) bool {
+		passedOn = append(passedOn, chunk)
+		return true
+	})
+
+	if got := strings.Join(passedOn, "|"); got != "The total is |7 euros." {
+		t.Fatalf("expected only the revision of text not passed on to be spoken, got %q", got)
+	}
+}
+
+func TestSpeechPlayerProgressEstimatesRemainingPlayback(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	player := newSpeechPlayer()
+	player.clock = fakeClock
+	if _, _, _, _, ok := player.Progress(); ok {
+		t.Fatalf("expected no progress before the buffers are initialised")
+	}
+
+	player.InitBuffers(audio.EncodingInfo{SampleRate: 1, Format: audio.EncodingLinear16}, "")
+	setTextSegments(player, "Hi there. ", "Bye.")
+	b := player.audioBuffer
+	b.AddAudio([]byte{1, 2})
+	b.Mark()
+	b.AddAudio([]byte{3, 4, 5, 6})
+	b.Mark(true)
+	b.AllAudioLoaded()
+
+	b.mu.Lock()
+	b.internalPlayhead = 2
+	b.externalPlayhead = 1
+	b.marks[0].broadcasted, b.marks[0].confirmed = true, true
+	b.marks[1].broadcasted = true
+	b.lastMarkTimestamp = fakeClock.Now()
+	b.mu.Unlock()
+	confirmSpokenMark(player)
+
+	fakeClock.Advance(time.Second)
+	spoken, remaining, paused, loaded, ok := player.Progress()
+	if !ok || spoken != "Hi there. " || remaining != time.Second || paused || !loaded {
+		t.Fatalf("unexpected progress %q %v paused=%t loaded=%t", spoken, remaining, paused, loaded)
+	}
+
+	player.PauseAudio()
+	fakeClock.Advance(time.Second)
+	if _, remaining, paused, _, _ := player.Progress(); !paused || remaining != 2*time.Second {
+		t.Fatalf("expected paused playback to restart the current chunk, got %v paused=%t", remaining, paused)
+	}
+}
+
+func TestSpeechPlayerBoundedMemoryReleasesPlayedAudioAndText(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	player := newSpeechPlayer()
+	player.clock = fakeClock
+	player.releasePlayed, player.releaseRetention = true, time.Second
+	player.InitBuffers(audio.EncodingInfo{SampleRate: 1, Format: audio.EncodingLinear16}, "")
+	setTextSegments(player, "Hi there. ", "Bye.")
+	b := player.audioBuffer
+	b.AddAudio([]byte{1, 2})
+	b.Mark()
+	b.AddAudio([]byte{3, 4, 5, 6})
+	b.Mark(true)
+	b.AllAudioLoaded()
+
+	b.mu.Lock()
+	b.internalPlayhead = 2
+	b.externalPlayhead = 1
+	b.marks[0].broadcasted, b.marks[0].confirmed, b.marks[0].confirmedAt = true, true, fakeClock.Now()
+	b.marks[1].broadcasted = true
+	b.lastMarkTimestamp = fakeClock.Now()
+	b.mu.Unlock()
+	confirmSpokenMark(player)
+
+	emitPlaybackProgress(player)
+	if b.audio[0] == nil {
+		t.Fatalf("expected audio to be kept for the retention window")
+	}
+
+	fakeClock.Advance(time.Second)
+	emitPlaybackProgress(player)
+	if b.audio[0] != nil || b.audio[1] == nil || len(b.marks) != 1 {
+		t.Fatalf("expected only the confirmed chunk to be released, got %v with %d marks", b.audio, len(b.marks))
+	}
+	if len(player.text) != 1 || player.SpokenTextSoFar() != "Hi there. " {
+		t.Fatalf("expected confirmed text to be folded into the spoken text, got %q and %q", player.text, player.SpokenTextSoFar())
+	}
+	if _, remaining, _, _, _ := player.Progress(); remaining != time.Second {
+		t.Fatalf("expected released audio to count as played, got %v remaining", remaining)
+	}
+}
+
+func TestSpeechPlayerProgressFollowsWordTimestamps(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	player := newSpeechPlayer()
+	player.clock = fakeClock
+	player.InitBuffers(audio.EncodingInfo{SampleRate: 1, Format: audio.EncodingLinear16}, "")
+	setTextSegments(player, "Hi there. ", "Good bye now.")
+	player.AddWordTimestamps([]events.SpeechWordTimestamp{
+		{Word: "Hi", End: 400 * time.Millisecond},
+		{Word: "there.", Start: 500 * time.Millisecond, End: time.Second},
+		{Word: "Good", Start: 1200 * time.Millisecond, End: 1800 * time.Millisecond},
+		{Word: "bye", Start: 2 * time.Second, End: 2600 * time.Millisecond},
+		{Word: "now.", Start: 2800 * time.Millisecond, End: 4600 * time.Millisecond},
+	})
+	b := player.audioBuffer
+	b.AddAudio([]byte{1, 2})
+	b.Mark()
+	b.AddAudio([]byte{3, 4, 5, 6, 7, 8, 9, 10})
+	b.Mark(true)
+	b.AllAudioLoaded()
+
+	b.mu.Lock()
+	b.internalPlayhead = 2
+	b.externalPlayhead = 1
+	b.segmentStartLen = 2
+	b.marks[0].broadcasted, b.marks[0].confirmed = true, true
+	b.marks[1].broadcasted = true
+	b.lastMarkTimestamp = fakeClock.Now()
+	b.mu.Unlock()
+	confirmSpokenMark(player)
+
+	fakeClock.Advance(2 * time.Second)
+	if spoken, _, _, _, _ := player.Progress(); spoken != "Hi there. Good bye" {
+		t.Fatalf("expected spoken text to end with the last played word, got %q", spoken)
+	}
+}
@@ -0,0 +1,106 @@
+package azure
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+const (
+	envVarApiKeyName = "AZURE_SPEECH_KEY"
+	envVarRegionName = "AZURE_SPEECH_REGION"
+)
+
+type TranscriptionClient struct {
+	region   string
+	language string
+
+	accumulatedTranscript string
+	inSpeech              bool
+
+	conn   *websocket.Conn
+	connMu sync.Mutex
+	// requestID identifies the turn audio is sent in, Azure ends turns on
+	// its own and audio after that starts a new one.
+	requestID  string
+	headerSent bool
+	sampleRate int
+	// stopped is set once the stream is stopped on purpose.
+	stopped atomic.Bool
+
+	credentials credentials.Credentials
+	logger      logging.Logger
+
+	// endpoint overrides the regional websocket endpoint, for tests.
+	endpoint string
+}
+
+// NewClient creates a client transcribing with the Speech resource in the
+// region read from AZURE_SPEECH_REGION unless set with [WithRegion].
+func NewClient(ctx context.Context, opts ...ClientOption) *TranscriptionClient {
+	options := ClientOptions{
+		region:      os.Getenv(envVarRegionName),
+		language:    "en-US",
+		credentials: credentials.Default(),
+		logger:      logging.Default(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &TranscriptionClient{
+		region:      options.region,
+		language:    options.language,
+		credentials: options.credentials,
+		logger:      options.logger,
+	}
+}
+
+type ClientOptions struct {
+	region      string
+	language    string
+	credentials credentials.Credentials
+	logger      logging.Logger
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithRegion sets the region of the Speech resource, e.g. "westeurope",
+// defaults to AZURE_SPEECH_REGION.
+func WithRegion(region string) ClientOption {
+	return func(o *ClientOptions) {
+		o.region = region
+	}
+}
+
+// WithLanguage sets the language of the speech, defaults to "en-US".
+func WithLanguage(language string) ClientOption {
+	return func(o *ClientOptions) {
+		o.language = language
+	}
+}
+
+// WithCredentials sets the credentials the AZURE_SPEECH_KEY is resolved from
+// each time a stream is opened, defaults to [credentials.Default].
+func WithCredentials(source credentials.Credentials) ClientOption {
+	return func(o *ClientOptions) {
+		o.credentials = source
+	}
+}
+
+// WithLogger sets the logger for connection and decoding failures, defaults
+// to [logging.Default].
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(o *ClientOptions) {
+		o.logger = logger
+	}
+}
+
+func (s *TranscriptionClient) Close() error {
+	return s.StopStream()
+}
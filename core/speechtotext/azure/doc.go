@@ -0,0 +1,3 @@
+// Package azure provides a speech to text client for Azure AI Speech,
+// streaming audio over Azure's websocket protocol for conversations.
+package azure
@@ -0,0 +1,42 @@
+package azure
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+func validateEncoding(encoding audio.EncodingInfo) error {
+	switch encoding.SampleRate {
+	case 8000, 16000:
+	default:
+		return fmt.Errorf("unsupported sample rate")
+	}
+
+	if encoding.Format != audio.EncodingLinear16 {
+		return fmt.Errorf("unsupported encoding")
+	}
+
+	return nil
+}
+
+// wavHeader describes the mono 16-bit pcm streamed after it. Azure reads the
+// format of a turn from the header of its first audio message.
+func wavHeader(sampleRate int) []byte {
+	const bytesPerSample = 2
+
+	header := make([]byte, 0, 44)
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, 0) // Unknown length of a stream
+	header = append(header, "WAVEfmt "...)
+	header = binary.LittleEndian.AppendUint32(header, 16) // fmt chunk size
+	header = binary.LittleEndian.AppendUint16(header, 1)  // PCM
+	header = binary.LittleEndian.AppendUint16(header, 1)  // mono
+	header = binary.LittleEndian.AppendUint32(header, uint32(sampleRate))
+	header = binary.LittleEndian.AppendUint32(header, uint32(sampleRate*bytesPerSample))
+	header = binary.LittleEndian.AppendUint16(header, bytesPerSample)
+	header = binary.LittleEndian.AppendUint16(header, 8*bytesPerSample)
+	header = append(header, "data"...)
+	return binary.LittleEndian.AppendUint32(header, 0)
+}
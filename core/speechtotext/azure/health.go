@@ -0,0 +1,38 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// HealthCheck issues an access token, which fails if Azure is unreachable or
+// rejects the key, without transcribing anything. It does not touch a stream
+// started with Transcribe.
+func (s *TranscriptionClient) HealthCheck(ctx context.Context) error {
+	apiKey, err := s.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return fmt.Errorf("azure speech key not found: %w", err)
+	}
+	if s.region == "" {
+		return fmt.Errorf("azure speech region neither found (AZURE_SPEECH_REGION) nor provided")
+	}
+
+	url := fmt.Sprintf("https://%s.api.cognitive.microsoft.com/sts/v1.0/issueToken", s.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", apiKey)
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+	}
+	return nil
+}
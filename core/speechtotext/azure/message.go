@@ -0,0 +1,55 @@
+package azure
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Azure's websocket messages carry HTTP-like headers, "Path" naming the kind
+// of message, before their body. Text messages separate them with an empty
+// line, binary ones prefix them with their big-endian uint16 length.
+
+func textMessage(path string, requestID string, contentType string, body string) string {
+	return fmt.Sprintf("Path: %s\r\nX-RequestId: %s\r\nX-Timestamp: %s\r\nContent-Type: %s\r\n\r\n%s",
+		path, requestID, timestamp(), contentType, body)
+}
+
+func binaryMessage(path string, requestID string, contentType string, body []byte) []byte {
+	header := fmt.Sprintf("Path: %s\r\nX-RequestId: %s\r\nX-Timestamp: %s\r\nContent-Type: %s\r\n",
+		path, requestID, timestamp(), contentType)
+	msg := make([]byte, 0, 2+len(header)+len(body))
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(header)))
+	msg = append(msg, header...)
+	return append(msg, body...)
+}
+
+func parseTextMessage(msg []byte) (textproto.MIMEHeader, []byte) {
+	header, body, _ := bytes.Cut(msg, []byte("\r\n\r\n"))
+	return parseHeader(header), body
+}
+
+func parseHeader(header []byte) textproto.MIMEHeader {
+	parsed := textproto.MIMEHeader{}
+	for _, line := range strings.Split(string(header), "\r\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			parsed.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+	}
+	return parsed
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id) // Never fails
+	return hex.EncodeToString(id)
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+}
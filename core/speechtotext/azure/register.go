@@ -0,0 +1,25 @@
+package azure
+
+import (
+	"context"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "azure" speech to text of [config], so
+// configs can select it once the package is imported. The "region" and
+// "language" options set the region of the Speech resource and the language
+// of the speech.
+func init() {
+	config.RegisterSpeechToText("azure", func(ctx context.Context, provider config.Provider) (orchestration.SpeechToText, error) {
+		opts := []ClientOption{}
+		if region := provider.Options["region"]; region != "" {
+			opts = append(opts, WithRegion(region))
+		}
+		if language := provider.Options["language"]; language != "" {
+			opts = append(opts, WithLanguage(language))
+		}
+		return NewClient(ctx, opts...), nil
+	})
+}
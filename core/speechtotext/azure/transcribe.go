@@ -0,0 +1,263 @@
+package azure
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var errNotConnected = errors.New("azure stream is not connected")
+
+// Transcribe opens the transcription stream. Azure ends an utterance after a
+// short silence, which is reported as the end of speech.
+func (s *TranscriptionClient) Transcribe(ctx context.Context, opts ...speechtotext.TranscriptionOption) error {
+	options := speechtotext.TranscriptionOptions{EncodingInfo: audio.GetDefaultEncodingInfo()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if err := validateEncoding(options.EncodingInfo); err != nil {
+		return fmt.Errorf("invalid encoding: %w", err)
+	}
+
+	apiKey, err := s.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return fmt.Errorf("azure speech key not found: %w", err)
+	}
+
+	endpoint := s.endpoint
+	if endpoint == "" {
+		if s.region == "" {
+			return fmt.Errorf("azure speech region neither found (AZURE_SPEECH_REGION) nor provided")
+		}
+		endpoint = fmt.Sprintf("wss://%s.stt.speech.microsoft.com/speech/recognition/conversation/cognitiveservices/v1", s.region)
+	}
+
+	conn, err := connectWebsocket(ctx, endpoint, apiKey, cmp.Or(options.Language, s.language))
+	if err != nil {
+		return fmt.Errorf("failed to open websocket: %w", err)
+	}
+
+	speechConfig := `{"context":{"system":{"name":"ema-core"}}}`
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(textMessage("speech.config", newRequestID(), "application/json", speechConfig))); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to send speech config: %w", err)
+	}
+
+	s.stopped.Store(false)
+	s.connMu.Lock()
+	s.conn = conn
+	s.requestID = newRequestID()
+	s.headerSent = false
+	s.sampleRate = options.EncodingInfo.SampleRate
+	s.connMu.Unlock()
+	go s.readAndProcessMessages(ctx, conn, withNoopCallbacks(options))
+
+	return nil
+}
+
+func connectWebsocket(ctx context.Context, endpoint string, apiKey string, language string) (*websocket.Conn, error) {
+	ctx, span := tracer.Start(ctx, "connect azure transcription websocket")
+	defer span.End()
+
+	listenUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+	queryParams := listenUrl.Query()
+	queryParams.Set("language", language)
+	queryParams.Set("format", "simple")
+	listenUrl.RawQuery = queryParams.Encode()
+
+	header := http.Header{
+		"Ocp-Apim-Subscription-Key": {apiKey},
+		"X-ConnectionId":            {newRequestID()},
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, listenUrl.String(), header)
+	if err != nil {
+		err = fmt.Errorf("failed to open socket connection to azure: %w", err)
+		errorreport.Record(ctx, span, err, "provider", "azure")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func withNoopCallbacks(options speechtotext.TranscriptionOptions) speechtotext.TranscriptionOptions {
+	for _, callback := range []*func(string){
+		&options.PartialInterimTranscriptionCallback,
+		&options.InterimTranscriptionCallback,
+		&options.PartialTranscriptionCallback,
+		&options.TranscriptionCallback,
+	} {
+		if *callback == nil {
+			*callback = func(string) {}
+		}
+	}
+	for _, callback := range []*func(){&options.SpeechStartedCallback, &options.SpeechEndedCallback} {
+		if *callback == nil {
+			*callback = func() {}
+		}
+	}
+	return options
+}
+
+func (s *TranscriptionClient) SendAudio(audio []byte) error {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	if s.conn == nil {
+		return errNotConnected
+	}
+	if !s.headerSent {
+		audio = append(wavHeader(s.sampleRate), audio...)
+		s.headerSent = true
+	}
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, binaryMessage("audio", s.requestID, "audio/x-wav", audio)); err != nil {
+		return fmt.Errorf("failed to write to azure client: %w", err)
+	}
+	return nil
+}
+
+func (s *TranscriptionClient) StopStream() error {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+
+	s.stopped.Store(true)
+	if s.conn != nil {
+		// An empty audio message ends the audio of the turn
+		if err := s.conn.WriteMessage(websocket.BinaryMessage, binaryMessage("audio", s.requestID, "audio/x-wav", nil)); err != nil {
+			return fmt.Errorf("failed to end azure audio through websocket: %w", err)
+		}
+		_ = s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")) // Ignored on purpose, the connection is closed regardless
+	}
+	return nil
+}
+
+func (s *TranscriptionClient) readAndProcessMessages(ctx context.Context, conn *websocket.Conn, callbacks speechtotext.TranscriptionOptions) {
+	ctx, span := tracer.Start(ctx, "read azure transcription websocket")
+	defer span.End()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			errorreport.ReportPanic(ctx, "azure transcription read loop", recovered, debug.Stack(), "provider", "azure")
+		}
+		s.clearConn(conn)
+		conn.Close()
+	}()
+
+	for {
+		msgType, msg, err := conn.ReadMessage()
+		if err != nil {
+			if !s.stopped.Load() && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				s.logger.Error("failed to read azure websocket message", "error", err)
+				errorreport.Record(ctx, span, err, "provider", "azure")
+				span.SetStatus(codes.Error, "failed to read azure websocket message")
+			}
+			if s.inSpeech {
+				s.onSpeechEnded(callbacks)
+			}
+			return
+		}
+		if msgType == websocket.TextMessage {
+			s.processMessage(msg, callbacks)
+		}
+	}
+}
+
+// clearConn forgets conn unless it was already replaced.
+func (s *TranscriptionClient) clearConn(conn *websocket.Conn) {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.conn == conn {
+		s.conn = nil
+	}
+}
+
+func (s *TranscriptionClient) processMessage(msg []byte, callbacks speechtotext.TranscriptionOptions) {
+	header, body := parseTextMessage(msg)
+
+	switch header.Get("Path") {
+	case "speech.startDetected":
+		s.onSpeechStarted(callbacks)
+	case "speech.hypothesis":
+		var hypothesis struct {
+			Text string `json:"Text"`
+		}
+		if err := json.Unmarshal(body, &hypothesis); err != nil {
+			s.logger.Error("failed to unmarshal azure message", "error", err)
+			return
+		}
+		transcript := strings.TrimSpace(hypothesis.Text)
+		if transcript == "" {
+			return
+		}
+		s.onSpeechStarted(callbacks)
+		callbacks.PartialInterimTranscriptionCallback(transcript)
+		callbacks.InterimTranscriptionCallback(strings.TrimSpace(s.accumulatedTranscript + " " + transcript))
+	case "speech.phrase":
+		var phrase struct {
+			RecognitionStatus string `json:"RecognitionStatus"`
+			DisplayText       string `json:"DisplayText"`
+		}
+		if err := json.Unmarshal(body, &phrase); err != nil {
+			s.logger.Error("failed to unmarshal azure message", "error", err)
+			return
+		}
+		transcript := strings.TrimSpace(phrase.DisplayText)
+		if phrase.RecognitionStatus == "Success" && transcript != "" {
+			s.onSpeechStarted(callbacks)
+			s.accumulatedTranscript += " " + transcript
+			callbacks.PartialTranscriptionCallback(transcript)
+		}
+		if s.inSpeech {
+			s.onSpeechEnded(callbacks)
+		}
+	case "speech.endDetected":
+		if s.inSpeech {
+			s.onSpeechEnded(callbacks)
+		}
+	case "turn.end":
+		// Audio sent from now on starts a new turn, which needs its own
+		// request ID and audio header
+		s.connMu.Lock()
+		s.requestID = newRequestID()
+		s.headerSent = false
+		s.connMu.Unlock()
+	}
+}
+
+// onSpeechStarted reports the start of speech unless it was already
+// reported, as Azure only detects the start of the first utterance of a turn.
+func (s *TranscriptionClient) onSpeechStarted(callbacks speechtotext.TranscriptionOptions) {
+	if s.inSpeech {
+		return
+	}
+	s.inSpeech = true
+	callbacks.SpeechStartedCallback()
+}
+
+func (s *TranscriptionClient) onSpeechEnded(callbacks speechtotext.TranscriptionOptions) {
+	s.inSpeech = false
+	fullTranscript := strings.TrimSpace(s.accumulatedTranscript)
+	s.accumulatedTranscript = ""
+	if len(fullTranscript) > 0 {
+		callbacks.TranscriptionCallback(fullTranscript)
+	}
+	callbacks.SpeechEndedCallback()
+}
@@ -0,0 +1,149 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+)
+
+type audioMessage struct {
+	requestID string
+	hasHeader bool
+}
+
+// newFakeAzure serves Azure's transcription protocol, recognizing "Hello
+// there." once two audio messages arrived and ending the turn after it.
+func newFakeAzure(t *testing.T, received chan<- audioMessage) string {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("language") != "en-US" {
+			t.Errorf("expected the language in the query, got %q", r.URL.RawQuery)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		send := func(path string, requestID string, body string) {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(textMessage(path, requestID, "application/json", body)))
+		}
+
+		audioMessages := 0
+		for {
+			msgType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msgType != websocket.BinaryMessage {
+				continue
+			}
+
+			headerLength := int(binary.BigEndian.Uint16(msg))
+			header := parseHeader(msg[2 : 2+headerLength])
+			payload := msg[2+headerLength:]
+			if header.Get("Path") != "audio" || len(payload) == 0 {
+				continue
+			}
+			requestID := header.Get("X-RequestId")
+			received <- audioMessage{requestID: requestID, hasHeader: bytes.HasPrefix(payload, []byte("RIFF"))}
+
+			if audioMessages++; audioMessages == 2 {
+				send("speech.startDetected", requestID, `{"Offset":0}`)
+				send("speech.hypothesis", requestID, `{"Text":"hello"}`)
+				send("speech.phrase", requestID, `{"RecognitionStatus":"Success","DisplayText":"Hello there."}`)
+				send("speech.endDetected", requestID, `{"Offset":0}`)
+				send("turn.end", requestID, `{}`)
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestTranscribeReportsUtterances(t *testing.T) {
+	received := make(chan audioMessage, 10)
+	client := NewClient(context.Background(),
+		WithCredentials(credentials.Static{envVarApiKeyName: "test"}),
+		WithLogger(logging.Discard()))
+	client.endpoint = newFakeAzure(t, received)
+
+	mu := sync.Mutex{}
+	got := []string{}
+	record := func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, entry)
+	}
+	ended := make(chan struct{})
+	err := client.Transcribe(context.Background(),
+		speechtotext.WithSpeechStartedCallback(func() { record("started") }),
+		speechtotext.WithInterimTranscriptionCallback(func(transcript string) { record("interim: " + transcript) }),
+		speechtotext.WithPartialTranscriptionCallback(func(transcript string) { record("partial: " + transcript) }),
+		speechtotext.WithTranscriptionCallback(func(transcript string) { record("final: " + transcript) }),
+		speechtotext.WithSpeechEndedCallback(func() {
+			record("ended")
+			close(ended)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to start transcription: %v", err)
+	}
+	defer client.Close()
+
+	for range 2 {
+		if err := client.SendAudio(make([]byte, 320)); err != nil {
+			t.Fatalf("failed to send audio: %v", err)
+		}
+	}
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("expected speech to end")
+	}
+
+	mu.Lock()
+	want := []string{"started", "interim: hello", "partial: Hello there.", "final: Hello there.", "ended"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	mu.Unlock()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		client.connMu.Lock()
+		turnEnded := !client.headerSent
+		client.connMu.Unlock()
+		if turnEnded {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("expected the turn to end")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := client.SendAudio(make([]byte, 320)); err != nil {
+		t.Fatalf("failed to send audio: %v", err)
+	}
+
+	messages := []audioMessage{<-received, <-received, <-received}
+	if !messages[0].hasHeader || messages[1].hasHeader || messages[1].requestID != messages[0].requestID {
+		t.Fatalf("expected the turn to start with an audio header, got %+v", messages[:2])
+	}
+	if !messages[2].hasHeader || messages[2].requestID == messages[0].requestID {
+		t.Fatalf("expected audio after the turn ended to start a new turn, got %+v", messages[2])
+	}
+}
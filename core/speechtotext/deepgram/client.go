@@ -3,9 +3,18 @@ package deepgram
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/clock"
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+const (
+	envVarApiKeyName = "DEEPGRAM_API_KEY"
+	defaultListenURL = "wss://api.deepgram.com/v1/listen"
 )
 
 type TranscriptionClient struct {
@@ -16,10 +25,120 @@ type TranscriptionClient struct {
 
 	conn   *websocket.Conn
 	connMu sync.Mutex
+	// stopped is set once the stream is stopped on purpose, a dropped
+	// connection is only reopened while it is not.
+	stopped atomic.Bool
+
+	listenURL string
+
+	credentials credentials.Credentials
+	logger      logging.Logger
+	clock       clock.Clock
+	silence     silenceOptions
+}
+
+func NewClient(ctx context.Context, opts ...ClientOption) *TranscriptionClient {
+	options := ClientOptions{
+		credentials: credentials.Default(),
+		logger:      logging.Default(),
+		clock:       clock.Real(),
+		silence: silenceOptions{
+			gap:               50 * time.Millisecond,
+			window:            time.Second,
+			keepAliveInterval: 5 * time.Second,
+		},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &TranscriptionClient{
+		credentials: options.credentials,
+		logger:      options.logger,
+		clock:       options.clock,
+		silence:     options.silence,
+		listenURL:   defaultListenURL,
+	}
+}
+
+type ClientOptions struct {
+	credentials credentials.Credentials
+	logger      logging.Logger
+	clock       clock.Clock
+	silence     silenceOptions
+}
+
+// silenceOptions configure how gaps in the sent audio are bridged, so
+// Deepgram finalises the speech before them and keeps the stream open.
+type silenceOptions struct {
+	// gap is how long no audio is sent before silence is generated.
+	gap time.Duration
+	// window is how long silence is generated before switching to
+	// keep-alives, zero disables generated silence.
+	window time.Duration
+	// keepAliveInterval is the time between keep-alives.
+	keepAliveInterval time.Duration
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithCredentials sets the credentials the DEEPGRAM_API_KEY is resolved from
+// each time a stream is opened, defaults to [credentials.Default].
+func WithCredentials(source credentials.Credentials) ClientOption {
+	return func(o *ClientOptions) {
+		o.credentials = source
+	}
+}
+
+// WithLogger sets the logger for connection and decoding failures, defaults
+// to [logging.Default].
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(o *ClientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithClock sets the time source silence generation and keep-alives are
+// scheduled on, defaults to [clock.Real].
+func WithClock(c clock.Clock) ClientOption {
+	return func(o *ClientOptions) {
+		o.clock = c
+	}
+}
+
+// WithSilenceAfter sets how long no audio is sent before silence is
+// generated in its place, 50ms by default.
+func WithSilenceAfter(d time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.silence.gap = d
+	}
+}
+
+// WithSilenceWindow sets how long silence is generated once audio stops
+// before only keep-alives are sent, 1s by default. Deepgram finalises the
+// speech before the gap within it.
+func WithSilenceWindow(d time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.silence.window = d
+	}
+}
+
+// WithoutGeneratedSilence sends only keep-alives when audio stops, for
+// transports that already send continuous audio, e.g. paced telephony
+// streams.
+func WithoutGeneratedSilence() ClientOption {
+	return func(o *ClientOptions) {
+		o.silence.window = 0
+	}
 }
 
-func NewClient(ctx context.Context) *TranscriptionClient {
-	return &TranscriptionClient{}
+// WithKeepAliveInterval sets the time between keep-alives sent while no
+// audio is sent, 5s by default. Deepgram closes streams receiving nothing for
+// 10s.
+func WithKeepAliveInterval(d time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.silence.keepAliveInterval = d
+	}
 }
 
 func (s *TranscriptionClient) Close() error {
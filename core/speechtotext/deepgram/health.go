@@ -0,0 +1,38 @@
+package deepgram
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+// HealthCheck opens and immediately closes a transcription stream, which
+// fails if Deepgram is unreachable or rejects the API key. It does not touch
+// a stream started with Transcribe.
+func (s *TranscriptionClient) HealthCheck(ctx context.Context) error {
+	apiKey, err := s.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return fmt.Errorf("deepgram api key not found: %w", err)
+	}
+
+	encoding, err := convertEncoding(audio.GetDefaultEncodingInfo())
+	if err != nil {
+		return fmt.Errorf("invalid encoding: %w", err)
+	}
+
+	conn, err := connectWebsocket(ctx, connectionOptions{
+		listenURL:  s.listenURL,
+		apiKey:     apiKey,
+		sampleRate: encoding.SampleRate,
+		encoding:   encoding.Format.Name(),
+	})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")) // Ignored on purpose, the check already passed
+	return nil
+}
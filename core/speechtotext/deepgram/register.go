@@ -0,0 +1,16 @@
+package deepgram
+
+import (
+	"context"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "deepgram" speech to text of [config], so
+// configs can select it once the package is imported.
+func init() {
+	config.RegisterSpeechToText("deepgram", func(ctx context.Context, _ config.Provider) (orchestration.SpeechToText, error) {
+		return NewClient(ctx), nil
+	})
+}
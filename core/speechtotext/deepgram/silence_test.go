@@ -0,0 +1,92 @@
+package deepgram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
+)
+
+func TestGenerateSilenceFollowsSilenceOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		opts            []ClientOption
+		expectSilence   bool
+		expectKeepAlive bool
+	}{
+		{name: "default", expectSilence: true},
+		{name: "without silence", opts: []ClientOption{WithoutGeneratedSilence(), WithKeepAliveInterval(200 * time.Millisecond)}, expectKeepAlive: true},
+		{name: "short window", opts: []ClientOption{WithSilenceWindow(100 * time.Millisecond), WithKeepAliveInterval(200 * time.Millisecond)}, expectSilence: true, expectKeepAlive: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var binary, text int
+			upgrader := websocket.Upgrader{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				for {
+					msgType, _, err := conn.ReadMessage()
+					if err != nil {
+						return
+					}
+					mu.Lock()
+					if msgType == websocket.BinaryMessage {
+						binary++
+					} else {
+						text++
+					}
+					mu.Unlock()
+				}
+			}))
+			defer server.Close()
+
+			fakeClock := clock.NewFake(time.Unix(0, 0).Add(time.Hour))
+			client := NewClient(context.Background(), append(tc.opts, WithClock(fakeClock))...)
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			if err != nil {
+				t.Fatalf("failed to dial: %v", err)
+			}
+			client.conn = conn
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				client.generateSilence(ctx, audio.GetDefaultEncodingInfo())
+			}()
+
+			for range 10 {
+				fakeClock.BlockUntil(1)
+				fakeClock.Advance(50 * time.Millisecond)
+			}
+			fakeClock.BlockUntil(1)
+			cancel()
+			<-done
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+			deadline := time.Now().Add(time.Second)
+			for {
+				mu.Lock()
+				gotSilence, gotKeepAlive := binary > 0, text > 0
+				mu.Unlock()
+				if (gotSilence == tc.expectSilence && gotKeepAlive == tc.expectKeepAlive) || time.Now().After(deadline) {
+					if gotSilence != tc.expectSilence || gotKeepAlive != tc.expectKeepAlive {
+						t.Fatalf("expected silence %t and keep-alive %t, got %t and %t", tc.expectSilence, tc.expectKeepAlive, gotSilence, gotKeepAlive)
+					}
+					return
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
@@ -1,13 +1,14 @@
 package deepgram
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
-	"os"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
@@ -15,10 +16,19 @@ import (
 	api "github.com/deepgram/deepgram-go-sdk/pkg/api/listen/v1/websocket/interfaces"
 	"github.com/gorilla/websocket"
 	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/errorreport"
 	"github.com/koscakluka/ema-core/core/speechtotext"
 	"github.com/koscakluka/ema-core/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 )
 
+var errNotConnected = errors.New("deepgram stream is not connected")
+
+// Transcribe opens the transcription stream. A connection the server drops
+// is reopened with the same options, backing off between attempts, and
+// reported to the disconnected and reconnected callbacks.
 func (s *TranscriptionClient) Transcribe(ctx context.Context, opts ...speechtotext.TranscriptionOption) error {
 	options := &speechtotext.TranscriptionOptions{EncodingInfo: audio.GetDefaultEncodingInfo()}
 	for _, opt := range opts {
@@ -32,79 +42,169 @@ func (s *TranscriptionClient) Transcribe(ctx context.Context, opts ...speechtote
 		return fmt.Errorf("invalid encoding: %w", err)
 	}
 
-	conn, err := connectWebsocket(connectionOptions{
+	connOptions := connectionOptions{
 		sampleRate: encoding.SampleRate,
 		encoding:   encoding.Format.Name(),
 
 		detectSpeechStart:            websocketConfig.shouldDetectSpeechStart,
 		enhanceSpeechEndingDetection: websocketConfig.shouldEnhanceSpeechEndingDetection,
 		interimResults:               websocketConfig.shouldRequestInterimResults,
-	})
+
+		model:          options.Model,
+		language:       options.Language,
+		endpointingMs:  options.EndpointingMs,
+		utteranceEndMs: options.UtteranceEndMs,
+	}
+	conn, err := s.connect(ctx, connOptions)
 	if err != nil {
-		return fmt.Errorf("failed to open websocket: %w", err)
+		return err
 	}
 
+	s.stopped.Store(false)
+	s.connMu.Lock()
 	s.conn = conn
-	go s.readAndProcessMessages(ctx, conn, options.EncodingInfo, callbacks)
+	s.connMu.Unlock()
+	go s.readAndProcessMessages(ctx, conn, connOptions, options.EncodingInfo, callbacks)
 
 	return nil
 }
 
+// connect opens a stream with options, resolving the API key anew so
+// rotated keys are picked up on reconnects.
+func (s *TranscriptionClient) connect(ctx context.Context, options connectionOptions) (*websocket.Conn, error) {
+	apiKey, err := s.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("deepgram api key not found: %w", err)
+	}
+
+	options.apiKey = apiKey
+	options.listenURL = s.listenURL
+	conn, err := connectWebsocket(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open websocket: %w", err)
+	}
+	return conn, nil
+}
+
+const (
+	reconnectMinDelay = 250 * time.Millisecond
+	reconnectMaxDelay = 10 * time.Second
+)
+
+// reconnect reopens the stream with options after its connection dropped,
+// backing off between attempts. It returns nil if the stream was stopped or
+// ctx is done first.
+func (s *TranscriptionClient) reconnect(ctx context.Context, options connectionOptions) *websocket.Conn {
+	delay := reconnectMinDelay
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.clock.After(delay):
+		}
+		if s.stopped.Load() {
+			return nil
+		}
+
+		conn, err := s.connect(ctx, options)
+		if err != nil {
+			s.logger.Warn("failed to reconnect to deepgram", "error", err, "retry_in", delay)
+			delay = min(2*delay, reconnectMaxDelay)
+			continue
+		}
+
+		s.connMu.Lock()
+		defer s.connMu.Unlock()
+		if s.stopped.Load() {
+			conn.Close()
+			return nil
+		}
+		s.conn = conn
+		return conn
+	}
+}
+
 type connectionOptions struct {
+	listenURL  string
+	apiKey     string
 	sampleRate int
 	encoding   string
 
 	detectSpeechStart            bool
 	enhanceSpeechEndingDetection bool
 	interimResults               bool
+
+	// model, language, endpointingMs and utteranceEndMs fall back to the
+	// defaults below when unset.
+	model          string
+	language       string
+	endpointingMs  int
+	utteranceEndMs int
 }
 
-func connectWebsocket(options connectionOptions) (*websocket.Conn, error) {
-	apiKey, ok := os.LookupEnv("DEEPGRAM_API_KEY")
-	if !ok {
-		return nil, fmt.Errorf("deepgram api key not found")
+const (
+	defaultModel          = "nova-3"
+	defaultLanguage       = "en-US"
+	defaultEndpointingMs  = 300
+	defaultUtteranceEndMs = 1000
+)
+
+func connectWebsocket(ctx context.Context, options connectionOptions) (*websocket.Conn, error) {
+	ctx, span := tracer.Start(ctx, "connect deepgram transcription websocket")
+	defer span.End()
+
+	header := http.Header{"Authorization": {"Token " + options.apiKey}}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, listenURL(options), header)
+	if err != nil {
+		err = fmt.Errorf("failed to open socket connection to deepgram: %w", err)
+		errorreport.Record(ctx, span, err, "provider", "deepgram")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
-	listenUrl, _ := url.Parse("wss://api.deepgram.com/v1/listen")
+	return conn, err
+}
+
+// listenURL returns the URL of the stream with the query configuring it.
+func listenURL(options connectionOptions) string {
+	listenUrl, _ := url.Parse(options.listenURL)
 	queryParams := listenUrl.Query()
 	queryParams.Set("encoding", options.encoding)
 	queryParams.Set("sample_rate", strconv.Itoa(options.sampleRate))
 	queryParams.Set("channels", "1")
-	queryParams.Set("model", "nova-3")
-	queryParams.Set("language", "en-US")
+	queryParams.Set("model", cmp.Or(options.model, defaultModel))
+	queryParams.Set("language", cmp.Or(options.language, defaultLanguage))
 	queryParams.Set("smart_format", "true")
 	if options.enhanceSpeechEndingDetection {
-		queryParams.Set("utterance_end_ms", "1000")
+		queryParams.Set("utterance_end_ms", strconv.Itoa(cmp.Or(options.utteranceEndMs, defaultUtteranceEndMs)))
 		queryParams.Set("interim_results", "true")
 	} else if options.interimResults {
 		queryParams.Set("interim_results", "true")
 	}
-	queryParams.Set("endpointing", "300")
+	queryParams.Set("endpointing", strconv.Itoa(cmp.Or(options.endpointingMs, defaultEndpointingMs)))
 	if options.detectSpeechStart || options.enhanceSpeechEndingDetection {
 		queryParams.Set("vad_events", "true")
 	}
 
 	listenUrl.RawQuery = queryParams.Encode()
-	conn, _, err := websocket.DefaultDialer.Dial(listenUrl.String(),
-		http.Header{"Authorization": {"Token " + apiKey}})
-	if err != nil {
-		return nil, fmt.Errorf("failed to open socket connection to deepgram: %w", err)
-	}
-
-	return conn, err
+	return listenUrl.String()
 }
 
 func (s *TranscriptionClient) sendKeepAlive() {
 	s.connMu.Lock()
 	defer s.connMu.Unlock()
 
+	if s.conn == nil {
+		return
+	}
 	if err := s.conn.WriteJSON(
 		struct {
 			Type string `json:"type"`
 		}{
 			Type: "KeepAlive",
 		}); err != nil {
-		log.Println("Failed to write to deepgram client", "error", err)
+		s.logger.Error("failed to write to deepgram client", "error", err)
 	}
 }
 
@@ -112,7 +212,10 @@ func (s *TranscriptionClient) SendAudio(audio []byte) error {
 	s.connMu.Lock()
 	defer s.connMu.Unlock()
 
-	s.lastMsgTs = time.Now()
+	s.lastMsgTs = s.clock.Now()
+	if s.conn == nil {
+		return errNotConnected
+	}
 	if err := s.conn.WriteMessage(websocket.BinaryMessage, audio); err != nil {
 		return fmt.Errorf("failed to write to deepgram client: %w", err)
 	}
@@ -123,6 +226,9 @@ func (s *TranscriptionClient) sendSilence(audio []byte) error {
 	s.connMu.Lock()
 	defer s.connMu.Unlock()
 
+	if s.conn == nil {
+		return nil
+	}
 	if err := s.conn.WriteMessage(websocket.BinaryMessage, audio); err != nil {
 		return fmt.Errorf("failed to write to deepgram client: %w", err)
 	}
@@ -133,6 +239,7 @@ func (s *TranscriptionClient) StopStream() error {
 	s.connMu.Lock()
 	defer s.connMu.Unlock()
 
+	s.stopped.Store(true)
 	if s.conn != nil {
 		if err := s.conn.WriteJSON(struct {
 			Type string `json:"type"`
@@ -143,7 +250,17 @@ func (s *TranscriptionClient) StopStream() error {
 	return nil
 }
 
-func (s *TranscriptionClient) readAndProcessMessages(ctx context.Context, conn *websocket.Conn, encodingInfo audio.EncodingInfo, callbacks callbackConfig) {
+func (s *TranscriptionClient) readAndProcessMessages(ctx context.Context, conn *websocket.Conn, options connectionOptions, encodingInfo audio.EncodingInfo, callbacks callbackConfig) {
+	ctx, span := tracer.Start(ctx, "read deepgram transcription websocket")
+	defer span.End()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			errorreport.ReportPanic(ctx, "deepgram transcription read loop", recovered, debug.Stack(), "provider", "deepgram")
+			s.clearConn(conn)
+			conn.Close()
+		}
+	}()
+
 	silenceCtx, silenceCancel := context.WithCancel(ctx)
 	defer silenceCancel()
 
@@ -153,26 +270,51 @@ func (s *TranscriptionClient) readAndProcessMessages(ctx context.Context, conn *
 		msgType, msg, err := conn.ReadMessage()
 		if err != nil {
 			if err.Error() != "websocket: close 1000 (normal)" {
-				log.Println("Failed to read deepgram websocket message", "error")
+				s.logger.Error("failed to read deepgram websocket message", "error", err)
+				errorreport.Record(ctx, span, err, "provider", "deepgram")
+				span.SetStatus(codes.Error, "failed to read deepgram websocket message")
 			}
 
-			s.conn = nil
+			s.clearConn(conn)
 			conn.Close()
-			return
+			if s.stopped.Load() || ctx.Err() != nil {
+				return
+			}
+
+			if s.unendedSegment {
+				s.onSpeechEnded(callbacks)
+			}
+			callbacks.disconnectedCallback(err)
+			if conn = s.reconnect(ctx, options); conn == nil {
+				return
+			}
+			callbacks.reconnectedCallback()
+			continue
 		}
 		if msgType != websocket.BinaryMessage {
-			go s.processMessage(msg, callbacks)
+			go s.processMessage(ctx, msg, callbacks)
 		}
 	}
 }
 
-func (s *TranscriptionClient) processMessage(msg []byte, callbacks callbackConfig) {
+// clearConn forgets conn unless it was already replaced.
+func (s *TranscriptionClient) clearConn(conn *websocket.Conn) {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.conn == conn {
+		s.conn = nil
+	}
+}
+
+func (s *TranscriptionClient) processMessage(ctx context.Context, msg []byte, callbacks callbackConfig) {
+	defer errorreport.RecoverPanic(ctx, "deepgram transcription message processing", "provider", "deepgram")
+
 	var parsedMsg struct {
 		Type string `json:"type"`
 	}
 	err := json.Unmarshal(msg, &parsedMsg)
 	if err != nil {
-		log.Println("Failed to unmarshal deepgram message", "error", err)
+		s.logger.Error("failed to unmarshal deepgram message", "error", err)
 		return
 	}
 
@@ -180,7 +322,7 @@ func (s *TranscriptionClient) processMessage(msg []byte, callbacks callbackConfi
 	case api.TypeMessageResponse:
 		var msgResp api.MessageResponse
 		if err := json.Unmarshal(msg, &msgResp); err != nil {
-			log.Println("Failed to unmarshal deepgram message", err)
+			s.logger.Error("failed to unmarshal deepgram message", "error", err)
 			return
 		}
 		if msgResp.IsFinal {
@@ -208,7 +350,7 @@ func (s *TranscriptionClient) processMessage(msg []byte, callbacks callbackConfi
 	case api.TypeUtteranceEndResponse:
 		var msgResp api.UtteranceEndResponse
 		if err := json.Unmarshal(msg, &msgResp); err != nil {
-			log.Println("Failed to unmarshal deepgram message", err)
+			s.logger.Error("failed to unmarshal deepgram message", "error", err)
 			return
 		}
 
@@ -218,7 +360,7 @@ func (s *TranscriptionClient) processMessage(msg []byte, callbacks callbackConfi
 	case api.TypeSpeechStartedResponse:
 		var msgResp api.SpeechStartedResponse
 		if err := json.Unmarshal(msg, &msgResp); err != nil {
-			log.Println("Failed to unmarshal deepgram message", err)
+			s.logger.Error("failed to unmarshal deepgram message", "error", err)
 			return
 		}
 
@@ -248,55 +390,59 @@ func (s *TranscriptionClient) generateSilence(ctx context.Context, encoding audi
 
 	const durationMs = 50
 	const milisecondsPerSecond = 1000
-	ticker := time.NewTicker(durationMs * time.Millisecond)
 
 	chunk := make([]byte, encoding.SampleRate*encoding.Format.ByteSize()*durationMs/milisecondsPerSecond)
 	for i := range chunk {
 		chunk[i] = encoding.SilenceValue()
 	}
 
+	options := s.silence
 	var state = silenceGeneratorStateWaiting
 	var firstSilenceTime *time.Time
 	var lastKeepAliveTime *time.Time
 	for {
 		select {
 		case <-ctx.Done():
-			ticker.Stop()
 			return
-		case <-ticker.C:
+		case <-s.clock.After(durationMs * time.Millisecond):
 			switch state {
 			case silenceGeneratorStateWaiting:
-				if time.Since(s.lastMsgTs).Milliseconds() > 50 {
+				if s.clock.Now().Sub(s.lastMsgTs) > options.gap {
+					if options.window <= 0 {
+						state = silenceGeneratorStateKeepAlive
+						lastKeepAliveTime = utils.Ptr(s.clock.Now())
+						continue
+					}
 					state = silenceGeneratorStateSilence
-					firstSilenceTime = utils.Ptr(time.Now())
+					firstSilenceTime = utils.Ptr(s.clock.Now())
 					continue
 				}
 
 			case silenceGeneratorStateSilence:
-				if time.Since(s.lastMsgTs).Milliseconds() < 50 {
+				if s.clock.Now().Sub(s.lastMsgTs) < options.gap {
 					state = silenceGeneratorStateWaiting
 					firstSilenceTime = nil
 					continue
 				}
-				if time.Since(*firstSilenceTime).Milliseconds() >= 1000 {
+				if s.clock.Now().Sub(*firstSilenceTime) >= options.window {
 					state = silenceGeneratorStateKeepAlive
-					lastKeepAliveTime = utils.Ptr(time.Now())
+					lastKeepAliveTime = utils.Ptr(s.clock.Now())
 					firstSilenceTime = nil
 					continue
 				}
 
 				if err := s.sendSilence(chunk); err != nil {
-					log.Println("Sending silence audio error", err)
+					s.logger.Error("failed to send silence audio", "error", err)
 				}
 
 			case silenceGeneratorStateKeepAlive:
-				if time.Since(s.lastMsgTs).Milliseconds() < 50 {
+				if s.clock.Now().Sub(s.lastMsgTs) < options.gap {
 					state = silenceGeneratorStateWaiting
 					continue
 				}
 
-				if time.Since(*lastKeepAliveTime).Seconds() >= 5 {
-					lastKeepAliveTime = utils.Ptr(time.Now())
+				if s.clock.Now().Sub(*lastKeepAliveTime) >= options.keepAliveInterval {
+					lastKeepAliveTime = utils.Ptr(s.clock.Now())
 					s.sendKeepAlive()
 				}
 			}
@@ -311,6 +457,8 @@ type callbackConfig struct {
 	transcriptionCallback               func(string)
 	startSpeechCallback                 func()
 	endSpeechCallback                   func()
+	disconnectedCallback                func(error)
+	reconnectedCallback                 func()
 }
 
 type websocketConfig struct {
@@ -327,6 +475,8 @@ func newCallbackConfig(options speechtotext.TranscriptionOptions) (callbackConfi
 		transcriptionCallback:               options.TranscriptionCallback,
 		startSpeechCallback:                 options.SpeechStartedCallback,
 		endSpeechCallback:                   options.SpeechEndedCallback,
+		disconnectedCallback:                options.DisconnectedCallback,
+		reconnectedCallback:                 options.ReconnectedCallback,
 	}
 	websocketConfig := websocketConfig{}
 
@@ -356,6 +506,12 @@ func newCallbackConfig(options speechtotext.TranscriptionOptions) (callbackConfi
 	if callbacks.endSpeechCallback == nil {
 		callbacks.endSpeechCallback = func() {}
 	}
+	if callbacks.disconnectedCallback == nil {
+		callbacks.disconnectedCallback = func(error) {}
+	}
+	if callbacks.reconnectedCallback == nil {
+		callbacks.reconnectedCallback = func() {}
+	}
 
 	return callbacks, websocketConfig
 }
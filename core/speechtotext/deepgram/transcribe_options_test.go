@@ -0,0 +1,49 @@
+package deepgram
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestConnectionOptionsTuneEndpointing(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  connectionOptions
+		expected map[string]string
+	}{
+		{
+			name:    "defaults",
+			options: connectionOptions{enhanceSpeechEndingDetection: true},
+			expected: map[string]string{
+				"model": "nova-3", "language": "en-US", "endpointing": "300", "utterance_end_ms": "1000",
+			},
+		},
+		{
+			name: "configured",
+			options: connectionOptions{
+				enhanceSpeechEndingDetection: true,
+				model:                        "nova-2",
+				language:                     "de",
+				endpointingMs:                500,
+				utteranceEndMs:               1500,
+			},
+			expected: map[string]string{
+				"model": "nova-2", "language": "de", "endpointing": "500", "utterance_end_ms": "1500",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listenURL, err := url.Parse(listenURL(tt.options))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			query := listenURL.Query()
+			for key, value := range tt.expected {
+				if query.Get(key) != value {
+					t.Fatalf("expected %s=%s, got %q", key, value, query.Get(key))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package deepgram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+)
+
+func TestTranscribeReconnectsWithTheSameOptions(t *testing.T) {
+	var connections atomic.Int32
+	queries := make(chan string, 4)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		queries <- r.URL.RawQuery
+
+		if connections.Add(1) == 1 {
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "idle"))
+			return
+		}
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"Results","is_final":true,"speech_final":true,"channel":{"alternatives":[{"transcript":"hello again"}]}}`))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(context.Background(), WithCredentials(credentials.Static{envVarApiKeyName: "key"}))
+	client.listenURL = "ws" + strings.TrimPrefix(server.URL, "http")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	disconnected := make(chan error, 1)
+	reconnected := make(chan struct{}, 1)
+	transcripts := make(chan string, 1)
+	if err := client.Transcribe(ctx,
+		speechtotext.WithTranscriptionCallback(func(transcript string) { transcripts <- transcript }),
+		speechtotext.WithDisconnectedCallback(func(err error) { disconnected <- err }),
+		speechtotext.WithReconnectedCallback(func() { reconnected <- struct{}{} }),
+	); err != nil {
+		t.Fatalf("failed to transcribe: %v", err)
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the disconnect")
+	}
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the reconnect")
+	}
+	select {
+	case transcript := <-transcripts:
+		if transcript != "hello again" {
+			t.Fatalf("unexpected transcript %q", transcript)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the transcript of the reopened stream")
+	}
+
+	if first, second := <-queries, <-queries; first != second {
+		t.Fatalf("expected the stream to be reopened with the same options, got %q and %q", first, second)
+	}
+	if err := client.SendAudio([]byte{0, 0}); err != nil {
+		t.Fatalf("expected audio to be sent over the reopened stream: %v", err)
+	}
+	if err := client.StopStream(); err != nil {
+		t.Fatalf("failed to stop stream: %v", err)
+	}
+}
@@ -8,10 +8,27 @@ type TranscriptionOptions struct {
 	PartialTranscriptionCallback        func(transcript string)
 	TranscriptionCallback               func(transcript string)
 
-	SpeechStartedCallback func()
-	SpeechEndedCallback   func()
+	SpeechStartedCallback  func()
+	SpeechEndedCallback    func()
+	SpeakerChangedCallback func(speakerID string)
+
+	DisconnectedCallback func(err error)
+	ReconnectedCallback  func()
 
 	EncodingInfo audio.EncodingInfo
+
+	// EndpointingMs is the silence after which speech is finalized, zero
+	// keeps the default of the implementation.
+	EndpointingMs int
+	// UtteranceEndMs is the gap between words after which speech ends, zero
+	// keeps the default of the implementation.
+	UtteranceEndMs int
+	// Model is the model transcribing, empty keeps the default of the
+	// implementation.
+	Model string
+	// Language is the language of the speech, empty keeps the default of the
+	// implementation.
+	Language string
 }
 
 type TranscriptionOption func(*TranscriptionOptions)
@@ -58,6 +75,36 @@ func WithSpeechEndedCallback(callback func()) TranscriptionOption {
 	}
 }
 
+// WithSpeakerChangedCallback sets the callback to be invoked by diarizing
+// speech-to-text implementations when a different speaker starts speaking.
+//
+// The callback will be invoked before any other callback attributed to the
+// new speaker.
+func WithSpeakerChangedCallback(callback func(speakerID string)) TranscriptionOption {
+	return func(o *TranscriptionOptions) {
+		o.SpeakerChangedCallback = callback
+	}
+}
+
+// WithDisconnectedCallback sets the callback to be invoked by reconnecting
+// speech-to-text implementations when their connection drops unexpectedly.
+//
+// Speech in progress ends with what was transcribed before the connection
+// dropped.
+func WithDisconnectedCallback(callback func(err error)) TranscriptionOption {
+	return func(o *TranscriptionOptions) {
+		o.DisconnectedCallback = callback
+	}
+}
+
+// WithReconnectedCallback sets the callback to be invoked once a dropped
+// connection was reopened with the original options.
+func WithReconnectedCallback(callback func()) TranscriptionOption {
+	return func(o *TranscriptionOptions) {
+		o.ReconnectedCallback = callback
+	}
+}
+
 // WithPartialInterimTranscriptionCallback sets the callback to be invoked when
 // a non-finalized part of the transcription is available. Future invocations
 // might include the same (but possibly updated) part of the transcription.
@@ -91,3 +138,45 @@ func WithEncodingInfo(encodingInfo audio.EncodingInfo) TranscriptionOption {
 		o.EncodingInfo = encodingInfo
 	}
 }
+
+// WithEndpointingMs sets how long the silence after speech is before the
+// transcription is finalized, in milliseconds. Lower values respond faster
+// but cut off users pausing mid-sentence.
+//
+// Implementations without configurable endpointing ignore it.
+func WithEndpointingMs(endpointingMs int) TranscriptionOption {
+	return func(o *TranscriptionOptions) {
+		o.EndpointingMs = endpointingMs
+	}
+}
+
+// WithUtteranceEndMs sets how long the gap after the last word is before
+// speech is considered ended, in milliseconds. Unlike endpointing it is
+// robust to background noise.
+//
+// Implementations without configurable utterance ends ignore it.
+func WithUtteranceEndMs(utteranceEndMs int) TranscriptionOption {
+	return func(o *TranscriptionOptions) {
+		o.UtteranceEndMs = utteranceEndMs
+	}
+}
+
+// WithModel sets the model transcribing the speech, in the naming of the
+// implementation, e.g. "nova-3". It takes precedence over the model the
+// client was created with, implementations choosing the model per client
+// ignore it.
+func WithModel(model string) TranscriptionOption {
+	return func(o *TranscriptionOptions) {
+		o.Model = model
+	}
+}
+
+// WithLanguage sets the language of the speech, in the naming of the
+// implementation, e.g. "en-US". It takes precedence over the language the
+// client was created with, implementations choosing the language per client
+// ignore it.
+func WithLanguage(language string) TranscriptionOption {
+	return func(o *TranscriptionOptions) {
+		o.Language = language
+	}
+}
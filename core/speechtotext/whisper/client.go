@@ -0,0 +1,190 @@
+package whisper
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+const (
+	envVarApiKeyName = "OPENAI_API_KEY"
+
+	hostedTranscriptionsURL = "https://api.openai.com/v1/audio/transcriptions"
+	defaultModel            = "whisper-1"
+)
+
+type TranscriptionClient struct {
+	backend  backend
+	model    string
+	language string
+
+	detection detectionOptions
+
+	credentials credentials.Credentials
+	logger      logging.Logger
+	clock       clock.Clock
+
+	// stream is the transcription started with Transcribe, nil while none is.
+	stream   *stream
+	streamMu sync.Mutex
+}
+
+func NewClient(ctx context.Context, opts ...ClientOption) *TranscriptionClient {
+	options := ClientOptions{
+		backend:     backend{url: hostedTranscriptionsURL, hosted: true},
+		model:       defaultModel,
+		credentials: credentials.Default(),
+		logger:      logging.Default(),
+		clock:       clock.Real(),
+		detection: detectionOptions{
+			threshold:       500,
+			preRoll:         300 * time.Millisecond,
+			endAfter:        700 * time.Millisecond,
+			interimInterval: time.Second,
+			maxSegment:      25 * time.Second,
+		},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &TranscriptionClient{
+		backend:     options.backend,
+		model:       options.model,
+		language:    options.language,
+		detection:   options.detection,
+		credentials: options.credentials,
+		logger:      options.logger,
+		clock:       options.clock,
+	}
+}
+
+type ClientOptions struct {
+	backend     backend
+	model       string
+	language    string
+	detection   detectionOptions
+	credentials credentials.Credentials
+	logger      logging.Logger
+	clock       clock.Clock
+}
+
+// backend is the server audio is sent to for transcription.
+type backend struct {
+	url string
+	// hosted is set for OpenAI's API, which requires an API key and a model.
+	hosted bool
+}
+
+// detectionOptions configure how speech is told apart from silence and how
+// the buffered speech is transcribed.
+type detectionOptions struct {
+	// threshold is the RMS amplitude of 16-bit samples above which audio
+	// counts as speech.
+	threshold float64
+	// preRoll is how much audio before the detected start of speech is kept,
+	// so the first syllable is not cut off.
+	preRoll time.Duration
+	// endAfter is how long audio stays quiet before speech ends.
+	endAfter time.Duration
+	// interimInterval is how much speech is buffered between interim
+	// transcriptions, zero disables them.
+	interimInterval time.Duration
+	// maxSegment is how much speech is buffered before it is transcribed as
+	// a final part, as Whisper only transcribes 30s at a time.
+	maxSegment time.Duration
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithLocalServer transcribes with the whisper.cpp server at url, e.g.
+// "http://localhost:8080", instead of OpenAI's API. The server needs no API
+// key and transcribes with the model it was started with.
+func WithLocalServer(url string) ClientOption {
+	return func(o *ClientOptions) {
+		o.backend = backend{url: strings.TrimSuffix(url, "/") + "/inference"}
+	}
+}
+
+// WithModel sets the model OpenAI transcribes with, defaults to "whisper-1".
+// A local server ignores it.
+func WithModel(model string) ClientOption {
+	return func(o *ClientOptions) {
+		o.model = model
+	}
+}
+
+// WithLanguage sets the ISO-639-1 language of the speech, e.g. "en", which
+// improves accuracy and latency. Whisper detects the language by default.
+func WithLanguage(language string) ClientOption {
+	return func(o *ClientOptions) {
+		o.language = language
+	}
+}
+
+// WithCredentials sets the credentials the OPENAI_API_KEY is resolved from
+// for each transcription, defaults to [credentials.Default]. A local server
+// does not need credentials.
+func WithCredentials(source credentials.Credentials) ClientOption {
+	return func(o *ClientOptions) {
+		o.credentials = source
+	}
+}
+
+// WithLogger sets the logger for transcription failures, defaults to
+// [logging.Default].
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(o *ClientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithClock sets the time source pauses in the sent audio are measured on,
+// defaults to [clock.Real].
+func WithClock(c clock.Clock) ClientOption {
+	return func(o *ClientOptions) {
+		o.clock = c
+	}
+}
+
+// WithSpeechThreshold sets the RMS amplitude of 16-bit samples above which
+// audio counts as speech, 500 by default. Raise it for noisy microphones.
+func WithSpeechThreshold(rms float64) ClientOption {
+	return func(o *ClientOptions) {
+		o.detection.threshold = rms
+	}
+}
+
+// WithSpeechEndAfter sets how long audio stays quiet, or stops arriving,
+// before speech ends and is transcribed, 700ms by default.
+func WithSpeechEndAfter(d time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.detection.endAfter = d
+	}
+}
+
+// WithInterimInterval sets how much speech is buffered between interim
+// transcriptions, 1s by default. Each interim result transcribes all speech
+// so far again, so shorter intervals cost more.
+func WithInterimInterval(d time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.detection.interimInterval = d
+	}
+}
+
+// WithoutInterimResults transcribes speech only once it ends, even if
+// interim callbacks are set.
+func WithoutInterimResults() ClientOption {
+	return func(o *ClientOptions) {
+		o.detection.interimInterval = 0
+	}
+}
+
+func (c *TranscriptionClient) Close() error {
+	return c.StopStream()
+}
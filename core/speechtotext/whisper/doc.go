@@ -0,0 +1,8 @@
+// Package whisper provides a speech to text client for Whisper, hosted by
+// OpenAI or served locally by a whisper.cpp server.
+//
+// Whisper transcribes recordings rather than streams, so the client detects
+// speech by the loudness of the audio, buffers it and transcribes it once
+// speech ends. While speech goes on, the buffered audio is transcribed again
+// at an interval to approximate interim results.
+package whisper
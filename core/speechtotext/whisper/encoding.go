@@ -0,0 +1,66 @@
+package whisper
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+func validateEncoding(encoding audio.EncodingInfo) error {
+	switch encoding.SampleRate {
+	case 8000, 16000, 24000, 32000, 48000:
+	default:
+		return fmt.Errorf("unsupported sample rate")
+	}
+
+	if encoding.Format != audio.EncodingLinear16 {
+		return fmt.Errorf("unsupported encoding")
+	}
+
+	return nil
+}
+
+// bytesOf returns how many bytes of mono 16-bit audio at sampleRate last d.
+func bytesOf(d time.Duration, sampleRate int) int {
+	return int(d.Seconds()*float64(sampleRate)) * 2
+}
+
+// rms returns the root mean square amplitude of 16-bit little-endian pcm.
+func rms(pcm []byte) float64 {
+	samples := len(pcm) / 2
+	if samples == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for i := 0; i+1 < len(pcm); i += 2 {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcm[i:])))
+		sum += sample * sample
+	}
+	return math.Sqrt(sum / float64(samples))
+}
+
+// wav wraps mono 16-bit pcm in a WAV container, which both OpenAI and
+// whisper.cpp accept.
+func wav(pcm []byte, sampleRate int) []byte {
+	const headerSize = 44
+	const bytesPerSample = 2
+
+	out := make([]byte, 0, headerSize+len(pcm))
+	out = append(out, "RIFF"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(headerSize-8+len(pcm)))
+	out = append(out, "WAVEfmt "...)
+	out = binary.LittleEndian.AppendUint32(out, 16) // fmt chunk size
+	out = binary.LittleEndian.AppendUint16(out, 1)  // PCM
+	out = binary.LittleEndian.AppendUint16(out, 1)  // mono
+	out = binary.LittleEndian.AppendUint32(out, uint32(sampleRate))
+	out = binary.LittleEndian.AppendUint32(out, uint32(sampleRate*bytesPerSample))
+	out = binary.LittleEndian.AppendUint16(out, bytesPerSample)
+	out = binary.LittleEndian.AppendUint16(out, 8*bytesPerSample)
+	out = append(out, "data"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(pcm)))
+	return append(out, pcm...)
+}
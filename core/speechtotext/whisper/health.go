@@ -0,0 +1,43 @@
+package whisper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const modelsURL = "https://api.openai.com/v1/models"
+
+// HealthCheck lists OpenAI's models, or loads the page of a local server,
+// which fails if the server is unreachable or rejects the API key, without
+// transcribing anything.
+func (c *TranscriptionClient) HealthCheck(ctx context.Context) error {
+	url := strings.TrimSuffix(c.backend.url, "/inference")
+	if c.backend.hosted {
+		url = modelsURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	if c.backend.hosted {
+		apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+		if err != nil {
+			return fmt.Errorf("openai api key not found: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package whisper
+
+import (
+	"context"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "whisper" speech to text of [config], so
+// configs can select it once the package is imported. The "server_url"
+// option selects a local whisper.cpp server, and "language" the language of
+// the speech.
+func init() {
+	config.RegisterSpeechToText("whisper", func(ctx context.Context, provider config.Provider) (orchestration.SpeechToText, error) {
+		opts := []ClientOption{}
+		if provider.Model != "" {
+			opts = append(opts, WithModel(provider.Model))
+		}
+		if url := provider.Options["server_url"]; url != "" {
+			opts = append(opts, WithLocalServer(url))
+		}
+		if language := provider.Options["language"]; language != "" {
+			opts = append(opts, WithLanguage(language))
+		}
+		return NewClient(ctx, opts...), nil
+	})
+}
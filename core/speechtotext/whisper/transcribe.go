@@ -0,0 +1,324 @@
+package whisper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+)
+
+var errNotStarted = errors.New("whisper transcription is not started")
+
+type jobType int
+
+const (
+	jobTypeSpeechStarted jobType = iota
+	jobTypeInterim
+	jobTypePart
+	jobTypeSpeechEnded
+)
+
+// job is a unit of work of the transcription loop. Jobs run in the order the
+// audio arrived, so callbacks are never reordered by slow transcriptions.
+type job struct {
+	Type  jobType
+	Audio []byte
+}
+
+type stream struct {
+	client     *TranscriptionClient
+	options    speechtotext.TranscriptionOptions
+	sampleRate int
+	interim    bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu            sync.Mutex
+	preRoll       []byte
+	speech        []byte // speech not transcribed as a part yet
+	speaking      bool
+	quiet         int // bytes of quiet audio since the last speech
+	sinceInterim  int // bytes of speech since the last interim transcription
+	interimQueued bool
+	lastAudio     time.Time
+	jobs          []job
+	wake          chan struct{}
+
+	// accumulatedTranscript is the transcript of the parts of the current
+	// speech, only touched by the transcription loop.
+	accumulatedTranscript string
+}
+
+// Transcribe starts transcribing the audio sent with SendAudio, replacing the
+// transcription started before. Transcripts are reported once speech ends,
+// and, when interim callbacks are set, at an interval while it goes on.
+func (c *TranscriptionClient) Transcribe(ctx context.Context, opts ...speechtotext.TranscriptionOption) error {
+	options := speechtotext.TranscriptionOptions{EncodingInfo: audio.GetDefaultEncodingInfo()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if err := validateEncoding(options.EncodingInfo); err != nil {
+		return fmt.Errorf("invalid encoding: %w", err)
+	}
+	if c.backend.hosted {
+		if _, err := c.credentials.Credential(ctx, envVarApiKeyName); err != nil {
+			return fmt.Errorf("openai api key not found: %w", err)
+		}
+	}
+
+	s := &stream{
+		client:     c,
+		sampleRate: options.EncodingInfo.SampleRate,
+		interim: c.detection.interimInterval > 0 &&
+			(options.InterimTranscriptionCallback != nil || options.PartialInterimTranscriptionCallback != nil),
+		options:   withNoopCallbacks(options),
+		lastAudio: c.clock.Now(),
+		wake:      make(chan struct{}, 1),
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	c.streamMu.Lock()
+	if c.stream != nil {
+		c.stream.cancel()
+	}
+	c.stream = s
+	c.streamMu.Unlock()
+
+	go s.transcribeQueued()
+	go s.endStalledSpeech()
+
+	return nil
+}
+
+func withNoopCallbacks(options speechtotext.TranscriptionOptions) speechtotext.TranscriptionOptions {
+	for _, callback := range []*func(string){
+		&options.PartialInterimTranscriptionCallback,
+		&options.InterimTranscriptionCallback,
+		&options.PartialTranscriptionCallback,
+		&options.TranscriptionCallback,
+	} {
+		if *callback == nil {
+			*callback = func(string) {}
+		}
+	}
+	for _, callback := range []*func(){&options.SpeechStartedCallback, &options.SpeechEndedCallback} {
+		if *callback == nil {
+			*callback = func() {}
+		}
+	}
+	return options
+}
+
+func (c *TranscriptionClient) SendAudio(audio []byte) error {
+	c.streamMu.Lock()
+	s := c.stream
+	c.streamMu.Unlock()
+
+	if s == nil {
+		return errNotStarted
+	}
+	s.receive(audio)
+	return nil
+}
+
+// StopStream stops the transcription started with Transcribe. Speech that
+// was not transcribed yet is dropped.
+func (c *TranscriptionClient) StopStream() error {
+	c.streamMu.Lock()
+	defer c.streamMu.Unlock()
+
+	if c.stream != nil {
+		c.stream.cancel()
+		c.stream = nil
+	}
+	return nil
+}
+
+func (s *stream) receive(pcm []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	detection := s.client.detection
+	s.lastAudio = s.client.clock.Now()
+	voiced := rms(pcm) > detection.threshold
+
+	if !s.speaking {
+		if !voiced {
+			s.preRoll = append(s.preRoll, pcm...)
+			if excess := len(s.preRoll) - bytesOf(detection.preRoll, s.sampleRate); excess > 0 {
+				s.preRoll = s.preRoll[excess+excess%2:]
+			}
+			return
+		}
+
+		s.speaking = true
+		s.speech = append(s.preRoll, pcm...)
+		s.preRoll = nil
+		s.quiet = 0
+		s.sinceInterim = len(pcm)
+		s.enqueue(job{Type: jobTypeSpeechStarted})
+		return
+	}
+
+	s.speech = append(s.speech, pcm...)
+	s.sinceInterim += len(pcm)
+	if voiced {
+		s.quiet = 0
+	} else {
+		s.quiet += len(pcm)
+	}
+
+	switch {
+	case s.quiet >= bytesOf(detection.endAfter, s.sampleRate):
+		s.endSpeech()
+	case len(s.speech) >= bytesOf(detection.maxSegment, s.sampleRate):
+		s.enqueue(job{Type: jobTypePart, Audio: s.speech})
+		s.speech = nil
+		s.sinceInterim = 0
+	case s.interim && !s.interimQueued && s.sinceInterim >= bytesOf(detection.interimInterval, s.sampleRate):
+		s.enqueue(job{Type: jobTypeInterim, Audio: slices.Clone(s.speech)})
+		s.interimQueued = true
+		s.sinceInterim = 0
+	}
+}
+
+// endSpeech must be called with mu held.
+func (s *stream) endSpeech() {
+	s.enqueue(job{Type: jobTypeSpeechEnded, Audio: s.speech})
+	s.speaking = false
+	s.speech = nil
+	s.quiet = 0
+}
+
+// enqueue must be called with mu held.
+func (s *stream) enqueue(j job) {
+	s.jobs = append(s.jobs, j)
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// endStalledSpeech ends speech once no audio arrived for the end-of-speech
+// delay, e.g. because the microphone was muted mid-sentence.
+func (s *stream) endStalledSpeech() {
+	endAfter := s.client.detection.endAfter
+	interval := min(100*time.Millisecond, endAfter)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.client.clock.After(interval):
+		}
+
+		s.mu.Lock()
+		if s.speaking && s.client.clock.Now().Sub(s.lastAudio) >= endAfter {
+			s.endSpeech()
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *stream) next() (job, bool) {
+	for {
+		s.mu.Lock()
+		if len(s.jobs) > 0 {
+			j := s.jobs[0]
+			s.jobs = s.jobs[1:]
+			// An interim result is stale once later audio is queued.
+			if j.Type == jobTypeInterim && len(s.jobs) > 0 {
+				s.interimQueued = false
+				s.mu.Unlock()
+				continue
+			}
+			s.mu.Unlock()
+			return j, true
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.wake:
+		case <-s.ctx.Done():
+			return job{}, false
+		}
+	}
+}
+
+// transcribeQueued runs the queued jobs in order until the stream stops.
+func (s *stream) transcribeQueued() {
+	defer errorreport.RecoverPanic(s.ctx, "whisper transcription loop", "provider", "whisper")
+
+	for {
+		j, ok := s.next()
+		if !ok {
+			return
+		}
+
+		switch j.Type {
+		case jobTypeSpeechStarted:
+			s.options.SpeechStartedCallback()
+		case jobTypeInterim:
+			transcript := s.transcribe(j.Audio)
+			s.mu.Lock()
+			s.interimQueued = false
+			s.mu.Unlock()
+			if transcript != "" && s.ctx.Err() == nil {
+				s.options.PartialInterimTranscriptionCallback(transcript)
+				s.options.InterimTranscriptionCallback(joinTranscripts(s.accumulatedTranscript, transcript))
+			}
+		case jobTypePart:
+			s.addPart(s.transcribe(j.Audio))
+		case jobTypeSpeechEnded:
+			s.addPart(s.transcribe(j.Audio))
+			if s.ctx.Err() != nil {
+				return
+			}
+			transcript := s.accumulatedTranscript
+			s.accumulatedTranscript = ""
+			if transcript != "" {
+				s.options.TranscriptionCallback(transcript)
+			}
+			s.options.SpeechEndedCallback()
+		}
+	}
+}
+
+func (s *stream) addPart(transcript string) {
+	if transcript == "" || s.ctx.Err() != nil {
+		return
+	}
+	s.accumulatedTranscript = joinTranscripts(s.accumulatedTranscript, transcript)
+	s.options.PartialTranscriptionCallback(transcript)
+}
+
+// transcribe returns the transcript of pcm, or an empty one if the
+// transcription failed, so speech still ends when the server is unavailable.
+func (s *stream) transcribe(pcm []byte) string {
+	if len(pcm) == 0 {
+		return ""
+	}
+
+	transcript, err := s.client.transcribe(s.ctx, pcm, s.sampleRate)
+	if err != nil {
+		if s.ctx.Err() == nil {
+			s.client.logger.Error("failed to transcribe whisper speech", "error", err)
+		}
+		return ""
+	}
+	return transcript
+}
+
+func joinTranscripts(accumulated, transcript string) string {
+	if accumulated == "" {
+		return transcript
+	}
+	return accumulated + " " + transcript
+}
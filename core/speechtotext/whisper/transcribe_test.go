@@ -0,0 +1,174 @@
+package whisper
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+)
+
+// chunk returns 100ms of 16kHz audio, a square wave if loud or silence.
+func chunk(loud bool) []byte {
+	pcm := make([]byte, 0, 3200)
+	for i := range 1600 {
+		sample := int16(0)
+		if loud {
+			sample = 3000
+			if i%2 == 1 {
+				sample = -3000
+			}
+		}
+		pcm = binary.LittleEndian.AppendUint16(pcm, uint16(sample))
+	}
+	return pcm
+}
+
+func newLocalServer(t *testing.T, transcript string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/inference" {
+			t.Errorf("expected the inference endpoint, got %q", r.URL.Path)
+		}
+		if _, _, err := r.FormFile("file"); err != nil {
+			t.Errorf("expected the audio file in the form: %v", err)
+		}
+		if r.FormValue("model") != "" {
+			t.Errorf("expected no model for a local server, got %q", r.FormValue("model"))
+		}
+		_, _ = w.Write([]byte(`{"text": " ` + transcript + `\n"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	entries []string
+	changed chan struct{}
+}
+
+func newRecorder() *recorder { return &recorder{changed: make(chan struct{}, 100)} }
+
+func (r *recorder) record(entry string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	r.changed <- struct{}{}
+}
+
+func (r *recorder) waitFor(t *testing.T, entry string) []string {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for {
+		r.mu.Lock()
+		entries := append([]string{}, r.entries...)
+		r.mu.Unlock()
+		if len(entries) > 0 && entries[len(entries)-1] == entry {
+			return entries
+		}
+		select {
+		case <-r.changed:
+		case <-deadline:
+			t.Fatalf("expected %q, got %q", entry, entries)
+		}
+	}
+}
+
+func (r *recorder) options() []speechtotext.TranscriptionOption {
+	return []speechtotext.TranscriptionOption{
+		speechtotext.WithSpeechStartedCallback(func() { r.record("started") }),
+		speechtotext.WithPartialTranscriptionCallback(func(transcript string) { r.record("partial: " + transcript) }),
+		speechtotext.WithTranscriptionCallback(func(transcript string) { r.record("final: " + transcript) }),
+		speechtotext.WithSpeechEndedCallback(func() { r.record("ended") }),
+	}
+}
+
+func TestTranscribeReportsSpeechOnceItEnds(t *testing.T) {
+	client := NewClient(context.Background(), WithLocalServer(newLocalServer(t, "hello there")), WithLogger(logging.Discard()))
+	recorder := newRecorder()
+	if err := client.Transcribe(context.Background(), recorder.options()...); err != nil {
+		t.Fatalf("failed to start transcription: %v", err)
+	}
+	defer client.Close()
+
+	for _, loud := range []bool{false, false, true, true, true, false, false, false, false, false, false, false} {
+		if err := client.SendAudio(chunk(loud)); err != nil {
+			t.Fatalf("failed to send audio: %v", err)
+		}
+	}
+
+	got := recorder.waitFor(t, "ended")
+	want := []string{"started", "partial: hello there", "final: hello there", "ended"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestTranscribeApproximatesInterimResults(t *testing.T) {
+	client := NewClient(context.Background(),
+		WithLocalServer(newLocalServer(t, "hello")),
+		WithInterimInterval(200*time.Millisecond),
+		WithLogger(logging.Discard()))
+	recorder := newRecorder()
+	opts := append(recorder.options(),
+		speechtotext.WithInterimTranscriptionCallback(func(transcript string) { recorder.record("interim: " + transcript) }))
+	if err := client.Transcribe(context.Background(), opts...); err != nil {
+		t.Fatalf("failed to start transcription: %v", err)
+	}
+	defer client.Close()
+
+	for range 3 {
+		if err := client.SendAudio(chunk(true)); err != nil {
+			t.Fatalf("failed to send audio: %v", err)
+		}
+	}
+
+	got := recorder.waitFor(t, "interim: hello")
+	if want := []string{"started", "interim: hello"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestTranscribeEndsSpeechWhenAudioStops(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	client := NewClient(context.Background(),
+		WithLocalServer(newLocalServer(t, "hello")),
+		WithClock(fakeClock),
+		WithLogger(logging.Discard()))
+	recorder := newRecorder()
+	if err := client.Transcribe(context.Background(), recorder.options()...); err != nil {
+		t.Fatalf("failed to start transcription: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SendAudio(chunk(true)); err != nil {
+		t.Fatalf("failed to send audio: %v", err)
+	}
+	recorder.waitFor(t, "started")
+
+	for range 7 {
+		fakeClock.BlockUntil(1)
+		fakeClock.Advance(100 * time.Millisecond)
+	}
+
+	got := recorder.waitFor(t, "ended")
+	if want := []string{"started", "partial: hello", "final: hello", "ended"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSendAudioFailsBeforeTranscribe(t *testing.T) {
+	client := NewClient(context.Background(), WithLocalServer("http://localhost:0"))
+	if err := client.SendAudio(chunk(true)); err != errNotStarted {
+		t.Fatalf("expected %v, got %v", errNotStarted, err)
+	}
+}
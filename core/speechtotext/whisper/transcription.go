@@ -0,0 +1,94 @@
+package whisper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// transcribe sends pcm to the backend and returns its transcript.
+func (c *TranscriptionClient) transcribe(ctx context.Context, pcm []byte, sampleRate int) (string, error) {
+	ctx, span := tracer.Start(ctx, "transcribe whisper speech", trace.WithAttributes(
+		attribute.Bool("stt.hosted", c.backend.hosted),
+		attribute.Int("stt.audio_bytes", len(pcm)),
+	))
+	defer span.End()
+
+	transcript, err := func() (string, error) {
+		body := &bytes.Buffer{}
+		form := multipart.NewWriter(body)
+		file, err := form.CreateFormFile("file", "speech.wav")
+		if err != nil {
+			return "", fmt.Errorf("error creating form: %w", err)
+		}
+		if _, err := file.Write(wav(pcm, sampleRate)); err != nil {
+			return "", fmt.Errorf("error creating form: %w", err)
+		}
+		fields := map[string]string{"response_format": "json"}
+		if c.backend.hosted {
+			fields["model"] = c.model
+		}
+		if c.language != "" {
+			fields["language"] = c.language
+		}
+		for name, value := range fields {
+			if err := form.WriteField(name, value); err != nil {
+				return "", fmt.Errorf("error creating form: %w", err)
+			}
+		}
+		if err := form.Close(); err != nil {
+			return "", fmt.Errorf("error creating form: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.backend.url, body)
+		if err != nil {
+			return "", fmt.Errorf("error creating HTTP request: %w", err)
+		}
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		if c.backend.hosted {
+			apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+			if err != nil {
+				return "", fmt.Errorf("openai api key not found: %w", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+
+		resp, err := newHTTPClient().Do(req)
+		if err != nil {
+			return "", fmt.Errorf("error sending request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			return "", fmt.Errorf("non-OK HTTP status: %s: %s", resp.Status, bytes.TrimSpace(message))
+		}
+
+		var response struct {
+			Text  string `json:"text"`
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return "", fmt.Errorf("error decoding response: %w", err)
+		}
+		if response.Error != "" {
+			return "", fmt.Errorf("transcription failed: %s", response.Error)
+		}
+		return strings.TrimSpace(response.Text), nil
+	}()
+	if err != nil && ctx.Err() == nil {
+		errorreport.Record(ctx, span, err, "provider", "whisper")
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return transcript, err
+}
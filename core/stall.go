@@ -0,0 +1,168 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+const (
+	workerLLM          = "llm"
+	workerTextToSpeech = "text_to_speech"
+	workerAudioOutput  = "audio_output"
+)
+
+type StallDetectionOptions struct {
+	cancel bool
+}
+
+type StallDetectionOption func(*StallDetectionOptions)
+
+// WithStallCancellation cancels turns once they are reported as stalled.
+func WithStallCancellation() StallDetectionOption {
+	return func(o *StallDetectionOptions) {
+		o.cancel = true
+	}
+}
+
+// WithStallDetection reports turns that make no progress for after with a
+// [events.TurnStalled] event describing what each worker of the response
+// pipeline was last doing.
+//
+// Progress is a streamed response chunk, a tool call, text passed to
+// text-to-speech or audio and marks passed to the audio output. Paused
+// speech and tool calls awaiting approval are not considered a stall, a slow
+// tool call is, so after should be longer than the slowest tool.
+func WithStallDetection(after time.Duration, opts ...StallDetectionOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		detection := &stallDetection{after: after}
+		for _, opt := range opts {
+			opt(&detection.StallDetectionOptions)
+		}
+		o.stallDetection = detection
+	}
+}
+
+type stallDetection struct {
+	StallDetectionOptions
+	after time.Duration
+}
+
+// watch reports the turn of pipeline once it makes no progress for long
+// enough, until ctx is done. A turn is reported once per stall.
+func (d *stallDetection) watch(ctx context.Context, pipeline *responsePipeline, turnID string) {
+	progress := pipeline.progress
+	for {
+		wait := d.after
+		if idle, paused := progress.idle(); !paused && idle < d.after {
+			wait = d.after - idle
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-progress.clock.After(wait):
+		}
+
+		workers, stalled := progress.stalled(d.after)
+		if !stalled {
+			continue
+		}
+
+		event := events.NewTurnStalled(turnID, workers)
+		event.Cancelled = d.cancel
+		pipeline.emitEvent(event)
+		if d.cancel {
+			pipeline.Cancel()
+			return
+		}
+	}
+}
+
+// pipelineProgress tracks what the workers of a response pipeline are doing.
+type pipelineProgress struct {
+	clock clock.Clock
+
+	mu           sync.Mutex
+	workers      []workerProgress
+	lastProgress time.Time
+	paused       bool
+	reported     bool
+}
+
+type workerProgress struct {
+	name         string
+	state        string
+	lastProgress time.Time
+}
+
+func newPipelineProgress(clock clock.Clock, workers ...string) *pipelineProgress {
+	now := clock.Now()
+	p := &pipelineProgress{clock: clock, lastProgress: now}
+	for _, name := range workers {
+		p.workers = append(p.workers, workerProgress{name: name, state: "starting", lastProgress: now})
+	}
+	return p
+}
+
+// report records progress of worker, state describes what it is doing now.
+func (p *pipelineProgress) report(worker, state string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.clock.Now()
+	for i := range p.workers {
+		if p.workers[i].name == worker {
+			p.workers[i].state = state
+			p.workers[i].lastProgress = now
+		}
+	}
+	p.lastProgress = now
+	p.reported = false
+}
+
+// setPaused stops stall detection while speech is paused or a tool call
+// awaits approval, resuming counts as progress.
+func (p *pipelineProgress) setPaused(paused bool) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = paused
+	p.lastProgress = p.clock.Now()
+	p.reported = false
+}
+
+// idle returns how long ago any worker made progress.
+func (p *pipelineProgress) idle() (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.clock.Now().Sub(p.lastProgress), p.paused
+}
+
+// stalled returns the state of the workers if none made progress for after
+// and the stall was not reported yet.
+func (p *pipelineProgress) stalled(after time.Duration) ([]events.WorkerState, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock.Now()
+	if p.paused || p.reported || now.Sub(p.lastProgress) < after {
+		return nil, false
+	}
+	p.reported = true
+
+	workers := make([]events.WorkerState, 0, len(p.workers))
+	for _, worker := range p.workers {
+		workers = append(workers, events.WorkerState{Name: worker.name, State: worker.state, Idle: now.Sub(worker.lastProgress)})
+	}
+	return workers, true
+}
@@ -0,0 +1,64 @@
+package orchestration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestStallDetectionReportsWorkersOncePerStall(t *testing.T) {
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	stalls := make(chan events.TurnStalled, 2)
+	pipeline := &responsePipeline{
+		emitEvent: func(event events.Event) {
+			if event, ok := event.(events.TurnStalled); ok {
+				stalls <- event
+			}
+		},
+		progress: newPipelineProgress(fakeClock, workerLLM, workerTextToSpeech, workerAudioOutput),
+	}
+	detection := &stallDetection{after: 5 * time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go detection.watch(ctx, pipeline, "turn-id")
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(3 * time.Second)
+	pipeline.progress.report(workerLLM, "calling tool lookup")
+	fakeClock.Advance(2 * time.Second)
+	fakeClock.BlockUntil(1)
+	select {
+	case stall := <-stalls:
+		t.Fatalf("expected progress to postpone the stall, got %+v", stall)
+	default:
+	}
+
+	fakeClock.Advance(3 * time.Second)
+	var stall events.TurnStalled
+	select {
+	case stall = <-stalls:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for the stall")
+	}
+	if stall.TurnID != "turn-id" || len(stall.Workers) != 3 || stall.Cancelled {
+		t.Fatalf("unexpected stall %+v", stall)
+	}
+	if llm := stall.Workers[0]; llm.Name != workerLLM || llm.State != "calling tool lookup" || llm.Idle != 5*time.Second {
+		t.Fatalf("unexpected llm state %+v", llm)
+	}
+	if tts := stall.Workers[1]; tts.State != "starting" || tts.Idle != 8*time.Second {
+		t.Fatalf("unexpected text-to-speech state %+v", tts)
+	}
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(5 * time.Second)
+	fakeClock.BlockUntil(1)
+	select {
+	case stall := <-stalls:
+		t.Fatalf("expected the stall to be reported once, got %+v", stall)
+	default:
+	}
+}
@@ -0,0 +1,122 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithWarmStandby prepares the response pipeline of the next turn while the
+// current turn runs, connecting its speech generator and setting up its
+// playback buffers, so back-to-back turns do not wait for the text-to-speech
+// connection. Standbys older than maxAge are closed instead of used, as
+// providers drop idle connections; zero keeps them until used.
+//
+// Only [TextToSpeechV1] clients, creating a generator per turn, are warmed
+// up. The standby pipeline becomes the current one only once its turn
+// starts, cancelling or pausing the current turn does not affect it.
+func WithWarmStandby(maxAge time.Duration) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.warmStandby = &warmStandby{maxAge: maxAge}
+	}
+}
+
+type warmStandby struct {
+	maxAge time.Duration
+
+	mu       sync.Mutex
+	pipeline *responsePipeline
+	// readyAt is when pipeline was connected.
+	readyAt time.Time
+	warming bool
+	closed  bool
+}
+
+// newTurnPipeline creates the response pipeline of a turn, or takes the warm
+// standby if there is a fresh one.
+func (o *Orchestrator) newTurnPipeline(emitEvent eventEmitter) *responsePipeline {
+	if pipeline := o.warmStandby.take(o.baseContext, o.clock.Now()); pipeline != nil {
+		pipeline.llm = o.llm.snapshot()
+		pipeline.textToSpeech.isMuted.Store(o.textToSpeech.isMuted.Load())
+		return pipeline
+	}
+	return newResponsePipeline(o.llm.snapshot(), o.textToSpeech.Snapshot(), o.speechPlayer.Snapshot(), o.audioOutput.Snapshot(),
+		emitEvent,
+	)
+}
+
+// prepareStandby warms up the pipeline of the next turn in the background,
+// unless there already is one.
+func (o *Orchestrator) prepareStandby(emitEvent eventEmitter) {
+	s := o.warmStandby
+	if s == nil {
+		return
+	}
+	if _, ok := o.textToSpeech.base.(TextToSpeechV1); !ok {
+		return
+	}
+
+	s.mu.Lock()
+	if s.pipeline != nil || s.warming || s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.warming = true
+	s.mu.Unlock()
+
+	go func() {
+		pipeline := newResponsePipeline(o.llm.snapshot(), o.textToSpeech.Snapshot(), o.speechPlayer.Snapshot(), o.audioOutput.Snapshot(),
+			emitEvent,
+		)
+		pipeline.textToSpeech.SetEventEmitter(pipeline.composeTTSEventEmitter())
+		err := pipeline.textToSpeech.init(o.baseContext, pipeline.audioOutput.EncodingInfo())
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.warming = false
+		if err != nil || !pipeline.textToSpeech.connected.Load() || s.closed {
+			// A failed warm up is left to the turn, which connects again and
+			// degrades if that fails too.
+			_ = pipeline.textToSpeech.Close(o.baseContext)
+			return
+		}
+		s.pipeline = pipeline
+		s.readyAt = o.clock.Now()
+	}()
+}
+
+// take returns the standby pipeline if it is fresh, closing a stale one.
+func (s *warmStandby) take(ctx context.Context, now time.Time) *responsePipeline {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	pipeline := s.pipeline
+	s.pipeline = nil
+	stale := s.maxAge > 0 && now.Sub(s.readyAt) > s.maxAge
+	s.mu.Unlock()
+
+	if pipeline != nil && stale {
+		_ = pipeline.textToSpeech.Close(ctx)
+		return nil
+	}
+	return pipeline
+}
+
+// close closes the standby pipeline and stops preparing new ones.
+func (s *warmStandby) close(ctx context.Context) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	pipeline := s.pipeline
+	s.pipeline = nil
+	s.closed = true
+	s.mu.Unlock()
+
+	if pipeline != nil {
+		_ = pipeline.textToSpeech.Close(ctx)
+	}
+}
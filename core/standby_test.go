@@ -0,0 +1,116 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+func TestWarmStandbyPipelineServesTheNextTurn(t *testing.T) {
+	tts := &recordingGeneratorTTSStub{}
+	o := NewOrchestrator(
+		WithLLM(promptLLMStub{response: "standby coverage"}),
+		WithTextToSpeechClientV1(tts),
+		WithAudioOutputV1(&bridgeAudioOutputStub{}),
+		WithWarmStandby(0),
+	)
+	defer o.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx)
+
+	standbyReady := func() bool {
+		o.warmStandby.mu.Lock()
+		defer o.warmStandby.mu.Unlock()
+		return o.warmStandby.pipeline != nil
+	}
+	for i := range 2 {
+		waitForCondition(t, 2*time.Second, "standby pipeline to connect", standbyReady)
+		standby := tts.created() - 1
+
+		o.SendPrompt("prompt")
+		waitForCondition(t, 2*time.Second, "turn to complete", func() bool {
+			return len(o.conversation.History()) == i+1 && o.currentResponsePipeline() == nil
+		})
+		if !tts.receivedText(standby) {
+			t.Fatalf("expected turn %d to speak through the standby generator", i+1)
+		}
+	}
+
+	waitForCondition(t, 2*time.Second, "standby pipeline to connect", standbyReady)
+	o.Close()
+	if standby := tts.created() - 1; !tts.closed(standby) {
+		t.Fatalf("expected closing the orchestrator to close the standby generator")
+	}
+}
+
+type recordingGeneratorTTSStub struct {
+	bridgeTTSV1Stub
+
+	mu         sync.Mutex
+	generators []*recordingSpeechGenerator
+}
+
+func (stub *recordingGeneratorTTSStub) NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
+	generator, err := stub.bridgeTTSV1Stub.NewSpeechGeneratorV0(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	recording := &recordingSpeechGenerator{SpeechGeneratorV0: generator}
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	stub.generators = append(stub.generators, recording)
+	return recording, nil
+}
+
+func (stub *recordingGeneratorTTSStub) created() int {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	return len(stub.generators)
+}
+
+func (stub *recordingGeneratorTTSStub) receivedText(i int) bool {
+	stub.mu.Lock()
+	generator := stub.generators[i]
+	stub.mu.Unlock()
+
+	generator.mu.Lock()
+	defer generator.mu.Unlock()
+	return generator.text != ""
+}
+
+func (stub *recordingGeneratorTTSStub) closed(i int) bool {
+	stub.mu.Lock()
+	generator := stub.generators[i]
+	stub.mu.Unlock()
+
+	generator.mu.Lock()
+	defer generator.mu.Unlock()
+	return generator.isClosed
+}
+
+type recordingSpeechGenerator struct {
+	texttospeech.SpeechGeneratorV0
+
+	mu       sync.Mutex
+	text     string
+	isClosed bool
+}
+
+func (g *recordingSpeechGenerator) SendText(text string) error {
+	g.mu.Lock()
+	g.text += text
+	g.mu.Unlock()
+	return g.SpeechGeneratorV0.SendText(text)
+}
+
+func (g *recordingSpeechGenerator) Close() error {
+	g.mu.Lock()
+	g.isClosed = true
+	g.mu.Unlock()
+	return g.SpeechGeneratorV0.Close()
+}
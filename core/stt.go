@@ -3,6 +3,8 @@ package orchestration
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 
 	"github.com/koscakluka/ema-core/core/audio"
 	events "github.com/koscakluka/ema-core/core/events"
@@ -12,8 +14,15 @@ import (
 type speechToText struct {
 	// client stores the configured speech-to-text implementation.
 	client SpeechToText
+	// speakerID attributes the events of a per-speaker stream, see
+	// [WithSpeakerSpeechToText].
+	speakerID string
 
 	emitEvent eventEmitter
+
+	speakerMu sync.Mutex
+	// diarizedSpeakerID is the speaker last reported by a diarizing client.
+	diarizedSpeakerID string
 }
 
 func newSpeechToText(client SpeechToText) *speechToText {
@@ -29,20 +38,25 @@ func (s *speechToText) set(client SpeechToText) {
 	}
 }
 
-func (s *speechToText) Start(ctx context.Context, encodingInfo *audio.EncodingInfo) error {
+// Start starts transcribing, opts configure the transcription but cannot
+// replace the callbacks and encoding the orchestrator sets.
+func (s *speechToText) Start(ctx context.Context, encodingInfo *audio.EncodingInfo, opts ...speechtotext.TranscriptionOption) error {
 	if !s.isConfigured() {
 		return nil
 	}
 
-	sttOptions := []speechtotext.TranscriptionOption{
+	sttOptions := append(slices.Clone(opts),
 		speechtotext.WithSpeechStartedCallback(s.invokeSpeechStarted),
 		speechtotext.WithSpeechEndedCallback(s.invokeSpeechEnded),
+		speechtotext.WithSpeakerChangedCallback(s.invokeSpeakerChanged),
+		speechtotext.WithDisconnectedCallback(s.invokeDisconnected),
+		speechtotext.WithReconnectedCallback(s.invokeReconnected),
 		speechtotext.WithPartialInterimTranscriptionCallback(s.invokePartialInterimTranscription),
 		speechtotext.WithInterimTranscriptionCallback(s.invokeInterimTranscription),
 		speechtotext.WithPartialTranscriptionCallback(s.invokePartialTranscription),
 		speechtotext.WithTranscriptionCallback(s.invokeTranscription),
 		speechtotext.WithEncodingInfo(*encodingInfo),
-	}
+	)
 
 	if err := s.Transcribe(ctx, sttOptions...); err != nil {
 		return fmt.Errorf("failed to start transcribing: %w", err)
@@ -104,28 +118,57 @@ func (s *speechToText) isConfigured() bool {
 	return s != nil && s.client != nil
 }
 
+// emit emits event attributed to the speaker of the stream, or the one the
+// client reported last.
+func (s *speechToText) emit(event events.Event) {
+	speakerID := s.speakerID
+	if speakerID == "" {
+		s.speakerMu.Lock()
+		speakerID = s.diarizedSpeakerID
+		s.speakerMu.Unlock()
+	}
+	if speakerID != "" {
+		event = events.WithSpeaker(event, speakerID)
+	}
+	s.emitEvent(event)
+}
+
+func (s *speechToText) invokeSpeakerChanged(speakerID string) {
+	s.speakerMu.Lock()
+	defer s.speakerMu.Unlock()
+	s.diarizedSpeakerID = speakerID
+}
+
+func (s *speechToText) invokeDisconnected(err error) {
+	s.emitEvent(events.NewConnectionLost(string(ComponentSpeechToText), err.Error()))
+}
+
+func (s *speechToText) invokeReconnected() {
+	s.emitEvent(events.NewConnectionRestored(string(ComponentSpeechToText)))
+}
+
 func (s *speechToText) invokeSpeechStarted() {
-	s.emitEvent(events.NewUserSpeechStarted())
+	s.emit(events.NewUserSpeechStarted())
 }
 
 func (s *speechToText) invokeSpeechEnded() {
-	s.emitEvent(events.NewUserSpeechEnded())
+	s.emit(events.NewUserSpeechEnded())
 }
 
 func (s *speechToText) invokeInterimTranscription(transcript string) {
-	s.emitEvent(events.NewUserTranscriptInterimUpdated(transcript))
+	s.emit(events.NewUserTranscriptInterimUpdated(transcript))
 }
 
 func (s *speechToText) invokePartialInterimTranscription(transcript string) {
-	s.emitEvent(events.NewUserTranscriptInterimSegmentUpdated(transcript))
+	s.emit(events.NewUserTranscriptInterimSegmentUpdated(transcript))
 }
 
 func (s *speechToText) invokePartialTranscription(transcript string) {
-	s.emitEvent(events.NewUserTranscriptSegment(transcript))
+	s.emit(events.NewUserTranscriptSegment(transcript))
 }
 
 func (s *speechToText) invokeTranscription(transcript string) {
-	s.emitEvent(events.NewUserTranscriptInterimSegmentUpdated(""))
-	s.emitEvent(events.NewUserTranscriptInterimUpdated(""))
-	s.emitEvent(events.NewUserTranscriptFinal(transcript))
+	s.emit(events.NewUserTranscriptInterimSegmentUpdated(""))
+	s.emit(events.NewUserTranscriptInterimUpdated(""))
+	s.emit(events.NewUserTranscriptFinal(transcript))
 }
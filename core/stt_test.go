@@ -147,3 +147,29 @@ func (stub *speechToTextClientStub) Transcribe(_ context.Context, opts ...speech
 func (stub *speechToTextClientStub) SendAudio([]byte) error {
 	return nil
 }
+
+func TestSpeechToTextStartPassesTranscriptionOptions(t *testing.T) {
+	var configured speechtotext.TranscriptionOptions
+	sttClient := &speechToTextClientStub{
+		transcribe: func(opts speechtotext.TranscriptionOptions) { configured = opts },
+	}
+	o := NewOrchestrator(WithSpeechToTextClient(sttClient), WithTranscriptionOptions(
+		speechtotext.WithEndpointingMs(500),
+		speechtotext.WithUtteranceEndMs(1500),
+		speechtotext.WithModel("nova-2"),
+		speechtotext.WithLanguage("de"),
+		speechtotext.WithTranscriptionCallback(nil),
+	))
+	t.Cleanup(o.Close)
+
+	encodingInfo := audio.GetDefaultEncodingInfo()
+	if err := o.speechToText.Start(context.Background(), &encodingInfo, o.transcriptionOptions...); err != nil {
+		t.Fatalf("expected start to succeed, got %v", err)
+	}
+	if configured.EndpointingMs != 500 || configured.UtteranceEndMs != 1500 || configured.Model != "nova-2" || configured.Language != "de" {
+		t.Fatalf("expected the transcription options to be passed, got %+v", configured)
+	}
+	if configured.TranscriptionCallback == nil {
+		t.Fatal("expected the transcription callback of the orchestrator to be kept")
+	}
+}
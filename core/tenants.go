@@ -0,0 +1,150 @@
+package orchestration
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+var (
+	// ErrTenantConversationLimit is the error of sessions refused because
+	// their tenant has as many conversations as it is allowed to.
+	ErrTenantConversationLimit = errors.New("tenant conversation limit reached")
+	// ErrTenantTurnRateLimit is the error of turns refused because their
+	// tenant started as many turns in the last minute as it is allowed to.
+	ErrTenantTurnRateLimit = errors.New("tenant turn rate limit reached")
+)
+
+// TenantLimits are the limits of a tenant of a shared deployment, a zero
+// limit is not enforced.
+type TenantLimits struct {
+	// MaxConversations is how many sessions of the tenant the manager serves
+	// at once.
+	MaxConversations int
+	// MaxTurnsPerMinute is how many turns all sessions of the tenant start
+	// within a minute.
+	MaxTurnsPerMinute int
+}
+
+// WithTenantLimits limits the sessions of each tenant, see [WithTenant], so
+// one tenant cannot starve a shared deployment. limits returns the limits of
+// a tenant, sessions without a tenant share the limits of the empty tenant.
+//
+// Sessions over the conversation limit are refused by [Manager.Add], turns
+// over the turn limit fail with [ErrTenantTurnRateLimit] and are left out of
+// the history. Refusals are reported with a [events.TenantLimitExceeded]
+// event, see [WithManagerEventCallback]. A session counts against the limit
+// until it is removed from the manager.
+func WithTenantLimits(limits func(tenant string) TenantLimits) ManagerOption {
+	return func(m *Manager) {
+		m.tenants = &tenantLimiter{
+			limits:    limits,
+			clock:     clock.Real(),
+			emitEvent: noopEventEmitter,
+			sessions:  map[string]string{},
+			turns:     map[string][]time.Time{},
+		}
+	}
+}
+
+type tenantLimiter struct {
+	limits    func(tenant string) TenantLimits
+	clock     clock.Clock
+	emitEvent eventEmitter
+
+	mu sync.Mutex
+	// sessions maps session ids to their tenants.
+	sessions map[string]string
+	// turns are the start times of the turns of each tenant within the last
+	// minute.
+	turns map[string][]time.Time
+}
+
+// admitSession counts the session id against the conversation limit of
+// tenant.
+func (l *tenantLimiter) admitSession(tenant, id string) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	max := l.limits(tenant).MaxConversations
+	conversations := 0
+	for _, sessionTenant := range l.sessions {
+		if sessionTenant == tenant {
+			conversations++
+		}
+	}
+	if max > 0 && conversations >= max {
+		l.mu.Unlock()
+		l.emitEvent(events.NewTenantLimitExceeded(tenant, id, events.TenantLimitConversations, max))
+		return fmt.Errorf("%w: tenant %q has %d conversations", ErrTenantConversationLimit, tenant, conversations)
+	}
+	l.sessions[id] = tenant
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *tenantLimiter) releaseSession(id string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.sessions, id)
+}
+
+// admitTurn counts a turn of the session id against the turn limit of
+// tenant. The returned release takes the turn back, e.g. when it is refused
+// by the budget after all.
+func (l *tenantLimiter) admitTurn(tenant, id string) (release func(), err error) {
+	l.mu.Lock()
+	max := l.limits(tenant).MaxTurnsPerMinute
+	now := l.clock.Now()
+	turns := l.turns[tenant]
+	for len(turns) > 0 && now.Sub(turns[0]) >= time.Minute {
+		turns = turns[1:]
+	}
+	if max > 0 && len(turns) >= max {
+		l.turns[tenant] = turns
+		l.mu.Unlock()
+		l.emitEvent(events.NewTenantLimitExceeded(tenant, id, events.TenantLimitTurnsPerMinute, max))
+		return nil, fmt.Errorf("%w: tenant %q started %d turns in the last minute", ErrTenantTurnRateLimit, tenant, len(turns))
+	}
+	l.turns[tenant] = append(turns, now)
+	l.mu.Unlock()
+	return func() { l.releaseTurn(tenant, now) }, nil
+}
+
+// releaseTurn takes back a turn of tenant admitted at start.
+func (l *tenantLimiter) releaseTurn(tenant string, start time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	turns := l.turns[tenant]
+	for i := len(turns) - 1; i >= 0; i-- {
+		if turns[i].Equal(start) {
+			l.turns[tenant] = append(turns[:i:i], turns[i+1:]...)
+			return
+		}
+	}
+}
+
+// turnAdmission decides whether the turns of a managed session may start.
+type turnAdmission struct {
+	admit func() (release func(), err error)
+}
+
+// admitManagedTurn checks the limits of the manager serving the session, if
+// any, before a turn. The returned release takes the turn back if it does
+// not start after all.
+func (o *Orchestrator) admitManagedTurn() (release func(), err error) {
+	if admission := o.admitTurn.Load(); admission != nil {
+		return admission.admit()
+	}
+	return func() {}, nil
+}
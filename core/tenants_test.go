@@ -0,0 +1,118 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestManagerEnforcesTenantLimits(t *testing.T) {
+	var mu sync.Mutex
+	var exceeded []events.TenantLimitExceeded
+	manager := NewManager(
+		WithTenantLimits(func(tenant string) TenantLimits {
+			if tenant == "acme" {
+				return TenantLimits{MaxConversations: 1, MaxTurnsPerMinute: 1}
+			}
+			return TenantLimits{}
+		}),
+		WithManagerEventCallback(func(event events.Event) {
+			if event, ok := event.(events.TenantLimitExceeded); ok {
+				mu.Lock()
+				defer mu.Unlock()
+				exceeded = append(exceeded, event)
+			}
+		}),
+	)
+	defer manager.Close()
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	manager.tenants.clock = fakeClock
+
+	llm := &recordingPromptLLMStub{}
+	o := NewOrchestrator(WithLLM(llm))
+	if err := manager.Add("a", o, WithTenant("acme")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := manager.Add("b", NewOrchestrator(), WithTenant("acme")); !errors.Is(err, ErrTenantConversationLimit) {
+		t.Fatalf("expected ErrTenantConversationLimit, got %v", err)
+	}
+	if err := manager.Add("c", NewOrchestrator(), WithTenant("globex")); err != nil {
+		t.Fatalf("expected other tenants to be unaffected, got %v", err)
+	}
+
+	var completed, failed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		switch event.(type) {
+		case events.TurnCompleted:
+			completed.Add(1)
+		case events.TurnFailed:
+			failed.Add(1)
+		}
+	}))
+
+	o.SendPrompt("first")
+	waitForCondition(t, 2*time.Second, "first turn completed", func() bool { return completed.Load() == 1 })
+	o.SendPrompt("second")
+	waitForCondition(t, 2*time.Second, "second turn refused", func() bool { return failed.Load() == 1 })
+	fakeClock.Advance(time.Minute)
+	o.SendPrompt("third")
+	waitForCondition(t, 2*time.Second, "third turn completed", func() bool { return completed.Load() == 2 })
+
+	if history := o.conversation.History(); len(history) != 2 {
+		t.Fatalf("expected the refused turn to be left out of the history, got %d turns", len(history))
+	}
+	mu.Lock()
+	if len(exceeded) != 2 || exceeded[0].Limit != events.TenantLimitConversations || exceeded[0].SessionID != "b" ||
+		exceeded[1].Limit != events.TenantLimitTurnsPerMinute || exceeded[1].SessionID != "a" || exceeded[1].Max != 1 {
+		t.Fatalf("unexpected limit events %+v", exceeded)
+	}
+	mu.Unlock()
+
+	manager.Remove("a")
+	if err := manager.Add("b", NewOrchestrator(), WithTenant("acme")); err != nil {
+		t.Fatalf("expected removed sessions to free their slot, got %v", err)
+	}
+}
+
+func TestTurnsRefusedByTheBudgetDoNotCountAgainstTenantLimits(t *testing.T) {
+	manager := NewManager(WithTenantLimits(func(string) TenantLimits {
+		return TenantLimits{MaxTurnsPerMinute: 2}
+	}))
+	defer manager.Close()
+
+	o := NewOrchestrator(WithLLM(promptLLMStub{response: "response"}), WithBudget(0, 0, 1))
+	if err := manager.Add("a", o, WithTenant("acme")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var completed, failed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		switch event.(type) {
+		case events.TurnCompleted:
+			completed.Add(1)
+		case events.TurnFailed:
+			failed.Add(1)
+		}
+	}))
+
+	o.SendPrompt("first")
+	waitForCondition(t, 2*time.Second, "first turn completed", func() bool { return completed.Load() == 1 })
+	o.SendPrompt("second")
+	waitForCondition(t, 2*time.Second, "second turn refused", func() bool { return failed.Load() == 1 })
+
+	manager.tenants.mu.Lock()
+	defer manager.tenants.mu.Unlock()
+	if turns := len(manager.tenants.turns["acme"]); turns != 1 {
+		t.Fatalf("expected only the admitted turn to be counted, got %d", turns)
+	}
+}
@@ -67,6 +67,25 @@ func (b *textBuffer) Chunks(yield func(string) bool) {
 	}
 }
 
+// ReplaceTail replaces the end of the text matching replaced with
+// replacement, as far as it was not consumed yet. A consumed start of
+// replaced that replacement repeats is not added again.
+func (b *textBuffer) ReplaceTail(replaced, replacement string) {
+	b.mu.Lock()
+	unconsumed := strings.Join(b.chunks[b.chunksConsumed:], "")
+	dropped := min(len(replaced), len(unconsumed))
+	if !strings.HasSuffix(unconsumed, replaced[len(replaced)-dropped:]) {
+		dropped = 0
+	}
+	replacement = strings.TrimPrefix(replacement, replaced[:len(replaced)-dropped])
+	b.chunks = b.chunks[:b.chunksConsumed]
+	if kept := unconsumed[:len(unconsumed)-dropped] + replacement; kept != "" {
+		b.chunks = append(b.chunks, kept)
+	}
+	b.mu.Unlock()
+	b.signalUpdate()
+}
+
 func (b *textBuffer) String() string {
 	b.mu.Lock()
 	defer b.mu.Unlock()
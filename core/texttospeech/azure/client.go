@@ -0,0 +1,105 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+const (
+	envVarApiKeyName = "AZURE_SPEECH_KEY"
+	envVarRegionName = "AZURE_SPEECH_REGION"
+
+	DefaultVoice = "en-US-AvaMultilingualNeural"
+)
+
+type TextToSpeechClient struct {
+	voice    string
+	language string
+	region   string
+
+	credentials credentials.Credentials
+	logger      logging.Logger
+
+	// endpoint overrides the regional websocket endpoint, for tests.
+	endpoint string
+}
+
+// NewTextToSpeechClient creates a client speaking with voice, one of Azure's
+// voice names, e.g. [DefaultVoice]. The region of the Speech resource is read
+// from AZURE_SPEECH_REGION unless set with [WithRegion].
+func NewTextToSpeechClient(ctx context.Context, voice string, opts ...ClientOption) (*TextToSpeechClient, error) {
+	options := ClientOptions{
+		region:      os.Getenv(envVarRegionName),
+		language:    "en-US",
+		credentials: credentials.Default(),
+		logger:      logging.Default(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if voice == "" {
+		return nil, fmt.Errorf("invalid voice")
+	}
+	if options.region == "" {
+		return nil, fmt.Errorf("azure speech region neither found (AZURE_SPEECH_REGION) nor provided")
+	}
+
+	return &TextToSpeechClient{
+		voice:       voice,
+		language:    options.language,
+		region:      options.region,
+		credentials: options.credentials,
+		logger:      options.logger,
+		endpoint:    fmt.Sprintf("wss://%s.tts.speech.microsoft.com/cognitiveservices/websocket/v1", options.region),
+	}, nil
+}
+
+type ClientOptions struct {
+	region      string
+	language    string
+	credentials credentials.Credentials
+	logger      logging.Logger
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithRegion sets the region of the Speech resource, e.g. "westeurope",
+// defaults to AZURE_SPEECH_REGION.
+func WithRegion(region string) ClientOption {
+	return func(o *ClientOptions) {
+		o.region = region
+	}
+}
+
+// WithLanguage sets the language of the synthesized text, defaults to
+// "en-US". Multilingual voices speak other languages regardless.
+func WithLanguage(language string) ClientOption {
+	return func(o *ClientOptions) {
+		o.language = language
+	}
+}
+
+// WithCredentials sets the credentials the AZURE_SPEECH_KEY is resolved from
+// each time a speech generator is created, defaults to [credentials.Default].
+func WithCredentials(source credentials.Credentials) ClientOption {
+	return func(o *ClientOptions) {
+		o.credentials = source
+	}
+}
+
+// WithLogger sets the logger for websocket failures, defaults to
+// [logging.Default].
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(o *ClientOptions) {
+		o.logger = logger
+	}
+}
+
+func (c *TextToSpeechClient) SetVoice(voice string) {
+	c.voice = voice
+}
@@ -0,0 +1,6 @@
+// Package azure provides a text to speech client for Azure AI Speech.
+//
+// Text between marks is synthesized as one request over Azure's websocket
+// protocol, and marks are reported once the audio of the last word before
+// them was generated, as timed by Azure's word boundary events.
+package azure
@@ -0,0 +1,38 @@
+package azure
+
+import (
+	"fmt"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+type encodingInfo struct {
+	// OutputFormat is the name of the raw audio format in Azure's terms.
+	OutputFormat   string
+	BytesPerSecond int
+}
+
+func convertEncoding(encoding audio.EncodingInfo) (*encodingInfo, error) {
+	switch encoding.Format {
+	case audio.EncodingLinear16:
+		switch encoding.SampleRate {
+		case 8000, 16000, 24000, 48000:
+		default:
+			return nil, fmt.Errorf("unsupported sample rate")
+		}
+		return &encodingInfo{
+			OutputFormat:   fmt.Sprintf("raw-%dkhz-16bit-mono-pcm", encoding.SampleRate/1000),
+			BytesPerSecond: encoding.SampleRate * 2,
+		}, nil
+	case audio.EncodingMulaw, audio.EncodingALaw:
+		if encoding.SampleRate != 8000 {
+			return nil, fmt.Errorf("unsupported sample rate for %s encoding", encoding.Format.Name())
+		}
+		return &encodingInfo{
+			OutputFormat:   "raw-8khz-8bit-mono-" + encoding.Format.Name(),
+			BytesPerSecond: encoding.SampleRate,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding")
+	}
+}
@@ -0,0 +1,34 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// HealthCheck lists the voices of the region, which fails if Azure is
+// unreachable or rejects the key, without synthesizing anything.
+func (c *TextToSpeechClient) HealthCheck(ctx context.Context) error {
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return fmt.Errorf("azure speech key not found: %w", err)
+	}
+
+	url := fmt.Sprintf("https://%s.tts.speech.microsoft.com/cognitiveservices/voices/list", c.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", apiKey)
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package azure
+
+import (
+	"context"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "azure" text to speech of [config], so
+// configs can select it once the package is imported. The "region" and
+// "language" options set the region of the Speech resource and the language
+// of the text.
+func init() {
+	config.RegisterTextToSpeech("azure", newConfiguredTextToSpeech)
+}
+
+func newConfiguredTextToSpeech(ctx context.Context, provider config.Provider) (orchestration.TextToSpeechV1, error) {
+	opts := []ClientOption{}
+	if region := provider.Options["region"]; region != "" {
+		opts = append(opts, WithRegion(region))
+	}
+	if language := provider.Options["language"]; language != "" {
+		opts = append(opts, WithLanguage(language))
+	}
+
+	voice := provider.Voice
+	if voice == "" {
+		voice = DefaultVoice
+	}
+	return NewTextToSpeechClient(ctx, voice, opts...)
+}
@@ -0,0 +1,478 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ticksPerSecond is the resolution of the audio offsets of Azure's events.
+const ticksPerSecond = 10_000_000
+
+type speechGenerator struct {
+	ws   *websocket.Conn
+	wsMu sync.Mutex
+
+	voice    string
+	language string
+	encoding encodingInfo
+	options  texttospeech.TextToSpeechOptions
+	logger   logging.Logger
+
+	mu      sync.Mutex
+	pending string    // text sent since the last mark
+	queued  []segment // marked text waiting for a synthesis
+	turn    *turn     // synthesis in progress, nil while idle
+
+	textComplete bool
+	cancelled    bool
+	closed       bool
+
+	report texttospeech.SpeechEndedReport
+}
+
+// segment is the text between two marks.
+type segment struct {
+	Text string
+	// Marked is unset for text left after the last mark, which is spoken
+	// but not reported.
+	Marked bool
+	// words is how many word boundaries Azure reports for the text.
+	words int
+	// end is the audio offset in ticks the last word ends at, negative until
+	// its word boundary arrives.
+	end int64
+}
+
+// turn is a synthesis of the segments queued while the previous one was in
+// progress, which Azure calls a turn.
+type turn struct {
+	requestID string
+	segments  []segment
+	// boundaries is how many word boundaries arrived.
+	boundaries int
+	// generated is the audio offset in ticks the audio was generated up to.
+	generated int64
+	// reported is how many segments were reported.
+	reported int
+}
+
+func (c *TextToSpeechClient) NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
+	options := texttospeech.TextToSpeechOptions{
+		AudioCallback:         func([]byte) {},
+		AudioEnded:            func(string) {},
+		SpeechAudioCallback:   func([]byte) {},
+		SpeechMarkCallback:    func(string) {},
+		SpeechEndedCallbackV0: func(texttospeech.SpeechEndedReport) {},
+		ErrorCallback:         func(error) {},
+		EncodingInfo:          audio.GetDefaultEncodingInfo(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	encoding, err := convertEncoding(options.EncodingInfo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encoding: %w", err)
+	}
+
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("azure speech key not found: %w", err)
+	}
+
+	ws, err := connectWebsocket(ctx, c.endpoint, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open websocket: %w", err)
+	}
+
+	generator := &speechGenerator{
+		ws:       ws,
+		voice:    c.voice,
+		language: c.language,
+		encoding: *encoding,
+		options:  options,
+		logger:   c.logger,
+	}
+	go generator.processIncomingMessages(ctx)
+
+	return generator, nil
+}
+
+func connectWebsocket(ctx context.Context, endpoint string, apiKey string) (*websocket.Conn, error) {
+	ctx, span := tracer.Start(ctx, "connect azure speech websocket")
+	defer span.End()
+
+	header := http.Header{
+		"Ocp-Apim-Subscription-Key": {apiKey},
+		"X-ConnectionId":            {newRequestID()},
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, endpoint, header)
+	if err != nil {
+		err = fmt.Errorf("failed to open socket connection to azure: %w", err)
+		errorreport.Record(ctx, span, err, "provider", "azure")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (g *speechGenerator) SendText(text string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		return fmt.Errorf("speech generator cancelled")
+	} else if g.textComplete {
+		return fmt.Errorf("speech generator text already completed")
+	}
+
+	g.pending += text
+	return nil
+}
+
+func (g *speechGenerator) Mark() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		return fmt.Errorf("speech generator cancelled")
+	} else if g.textComplete {
+		return fmt.Errorf("speech generator text already completed")
+	}
+
+	if g.pending == "" {
+		return nil
+	}
+	g.queued = append(g.queued, newSegment(g.pending, true))
+	g.pending = ""
+	if err := g.startTurn(); err != nil {
+		return fmt.Errorf("failed to start synthesis: %w", err)
+	}
+	return nil
+}
+
+func (g *speechGenerator) EndOfText() error {
+	g.mu.Lock()
+
+	if g.closed {
+		g.mu.Unlock()
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		g.mu.Unlock()
+		return fmt.Errorf("speech generator cancelled")
+	} else if g.textComplete {
+		g.mu.Unlock()
+		return nil
+	}
+
+	g.textComplete = true
+	if strings.TrimSpace(g.pending) != "" {
+		g.queued = append(g.queued, newSegment(g.pending, false))
+	}
+	g.pending = ""
+	if err := g.startTurn(); err != nil {
+		g.mu.Unlock()
+		return fmt.Errorf("failed to start synthesis: %w", err)
+	}
+	ended := g.turn == nil
+	g.mu.Unlock()
+
+	if ended {
+		g.options.SpeechEndedCallbackV0(g.report)
+		_ = g.Close() // TODO: See if we need to react on this error
+	}
+	return nil
+}
+
+func (g *speechGenerator) Cancel() error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		g.mu.Unlock()
+		return nil
+	}
+	g.cancelled = true
+	g.mu.Unlock()
+
+	return g.Close()
+}
+
+func (g *speechGenerator) Close() error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return nil
+	}
+	g.closed = true
+	g.queued = nil
+	g.turn = nil
+	g.mu.Unlock()
+
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+	_ = g.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")) // Ignored on purpose, the connection is closed regardless
+	if err := g.ws.Close(); err != nil {
+		return fmt.Errorf("failed to close websocket: %w", err)
+	}
+	return nil
+}
+
+// startTurn synthesizes the queued segments unless a synthesis is already in
+// progress, the queued ones are synthesized together once it ends.
+//
+// Must be called with mu held.
+func (g *speechGenerator) startTurn() error {
+	if g.turn != nil || len(g.queued) == 0 {
+		return nil
+	}
+
+	t := &turn{requestID: newRequestID(), segments: g.queued}
+	g.queued = nil
+	g.turn = t
+
+	text := strings.Builder{}
+	for _, segment := range t.segments {
+		text.WriteString(segment.Text)
+	}
+
+	synthesisContext, _ := json.Marshal(map[string]any{ // Never fails
+		"synthesis": map[string]any{
+			"audio": map[string]any{
+				"metadataOptions": map[string]bool{
+					"wordBoundaryEnabled":        true,
+					"sentenceBoundaryEnabled":    false,
+					"punctuationBoundaryEnabled": false,
+					"bookmarkEnabled":            false,
+					"visemeEnabled":              false,
+					"sessionEndEnabled":          true,
+				},
+				"outputFormat": g.encoding.OutputFormat,
+			},
+			"language": map[string]bool{"autoDetection": false},
+		},
+	})
+	ssml := fmt.Sprintf(`<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="%s"><voice name="%s">%s</voice></speak>`,
+		html.EscapeString(g.language), html.EscapeString(g.voice), html.EscapeString(strings.TrimSpace(text.String())))
+
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+	if err := g.ws.WriteMessage(websocket.TextMessage, []byte(textMessage("synthesis.context", t.requestID, "application/json", string(synthesisContext)))); err != nil {
+		return fmt.Errorf("failed to send synthesis context: %w", err)
+	}
+	if err := g.ws.WriteMessage(websocket.TextMessage, []byte(textMessage("ssml", t.requestID, "application/ssml+xml", ssml))); err != nil {
+		return fmt.Errorf("failed to send ssml: %w", err)
+	}
+	return nil
+}
+
+func newSegment(text string, marked bool) segment {
+	words := 0
+	for _, word := range strings.Fields(text) {
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) >= 0 {
+			words++
+		}
+	}
+	return segment{Text: text, Marked: marked, words: words, end: -1}
+}
+
+func (g *speechGenerator) processIncomingMessages(ctx context.Context) {
+	_, span := tracer.Start(ctx, "read azure speech websocket")
+	defer span.End()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			errorreport.ReportPanic(ctx, "azure speech read loop", recovered, debug.Stack(), "provider", "azure")
+			_ = g.Close() // Ignored on purpose
+		}
+	}()
+
+	for {
+		msgType, msg, err := g.ws.ReadMessage()
+		if err != nil {
+			g.mu.Lock()
+			closed := g.closed
+			g.mu.Unlock()
+			if !closed && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				g.logger.Error("azure websocket read error", "error", err)
+				errorreport.Record(ctx, span, err, "provider", "azure")
+				span.SetStatus(codes.Error, "azure websocket read error")
+				g.options.ErrorCallback(err)
+			}
+			_ = g.Close() // Ignored on purpose
+			return
+		}
+
+		switch msgType {
+		case websocket.BinaryMessage:
+			header, payload, err := parseBinaryMessage(msg)
+			if err != nil {
+				g.logger.Error("failed to parse azure message", "error", err)
+				continue
+			}
+			if header.Get("Path") != "audio" || len(payload) == 0 {
+				continue
+			}
+			if !g.isCurrentTurn(header.Get("X-RequestId")) {
+				continue
+			}
+			g.options.SpeechAudioCallback(payload)
+			g.reportMarks(func(t *turn) {
+				t.generated += int64(len(payload)) * ticksPerSecond / int64(g.encoding.BytesPerSecond)
+			})
+		case websocket.TextMessage:
+			header, body := parseTextMessage(msg)
+			if !g.isCurrentTurn(header.Get("X-RequestId")) {
+				continue
+			}
+
+			switch header.Get("Path") {
+			case "audio.metadata":
+				var metadata struct {
+					Metadata []struct {
+						Type string `json:"Type"`
+						Data struct {
+							Offset   int64 `json:"Offset"`
+							Duration int64 `json:"Duration"`
+						} `json:"Data"`
+					} `json:"Metadata"`
+				}
+				if err := json.Unmarshal(body, &metadata); err != nil {
+					g.logger.Error("failed to unmarshal azure metadata", "error", err)
+					continue
+				}
+				g.reportMarks(func(t *turn) {
+					for _, item := range metadata.Metadata {
+						if item.Type == "WordBoundary" {
+							t.addBoundary(item.Data.Offset + item.Data.Duration)
+						}
+					}
+				})
+			case "turn.end":
+				if done := g.endTurn(); done {
+					g.options.SpeechEndedCallbackV0(g.report)
+					_ = g.Close() // Ignored on purpose
+					return
+				}
+			}
+		}
+	}
+}
+
+// isCurrentTurn reports whether a message with requestID belongs to the
+// synthesis in progress.
+func (g *speechGenerator) isCurrentTurn(requestID string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.turn != nil && strings.EqualFold(g.turn.requestID, requestID)
+}
+
+// reportMarks updates the synthesis in progress and reports the marks whose
+// text was spoken since.
+func (g *speechGenerator) reportMarks(update func(*turn)) {
+	g.mu.Lock()
+	if g.turn == nil {
+		g.mu.Unlock()
+		return
+	}
+	update(g.turn)
+	marks := g.turn.spokenMarks()
+	g.mu.Unlock()
+
+	for _, mark := range marks {
+		g.options.SpeechMarkCallback(mark)
+	}
+}
+
+// endTurn reports the marks left in the finished synthesis and starts the
+// next one. It returns true once all text was spoken.
+func (g *speechGenerator) endTurn() bool {
+	g.mu.Lock()
+	marks := []string{}
+	if g.turn != nil {
+		g.turn.generated = max(g.turn.generated, g.turn.lastEnd())
+		for i := range g.turn.segments {
+			if g.turn.segments[i].end < 0 {
+				g.turn.segments[i].end = g.turn.generated
+			}
+		}
+		marks = g.turn.spokenMarks()
+	}
+	g.turn = nil
+	err := g.startTurn()
+	done := err == nil && g.turn == nil && g.textComplete
+	g.mu.Unlock()
+
+	for _, mark := range marks {
+		g.options.SpeechMarkCallback(mark)
+	}
+	if err != nil {
+		g.logger.Error("failed to start azure synthesis", "error", err)
+		g.options.ErrorCallback(err)
+		_ = g.Close() // Ignored on purpose
+	}
+	return done
+}
+
+// addBoundary records a word boundary ending at end, which ends a segment if
+// it is the boundary of its last word.
+func (t *turn) addBoundary(end int64) {
+	t.boundaries++
+	words := 0
+	for i := range t.segments {
+		words += t.segments[i].words
+		if words == t.boundaries && t.segments[i].words > 0 {
+			t.segments[i].end = end
+			return
+		}
+		if words > t.boundaries {
+			return
+		}
+	}
+}
+
+// spokenMarks returns the text of the marked segments whose audio was
+// generated and that were not reported yet, in order.
+func (t *turn) spokenMarks() []string {
+	marks := []string{}
+	for ; t.reported < len(t.segments); t.reported++ {
+		segment := t.segments[t.reported]
+		if segment.words > 0 && (segment.end < 0 || segment.end > t.generated) {
+			break
+		}
+		if segment.Marked {
+			marks = append(marks, segment.Text)
+		}
+	}
+	return marks
+}
+
+func (t *turn) lastEnd() int64 {
+	end := int64(0)
+	for _, segment := range t.segments {
+		end = max(end, segment.end)
+	}
+	return end
+}
@@ -0,0 +1,175 @@
+package azure
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+var voiceText = regexp.MustCompile(`<voice name="[^"]*">(.*)</voice>`)
+
+// newFakeAzure serves Azure's synthesis protocol, speaking each word of a
+// request for a second with its word boundary halfway through, followed by
+// half a second of silence. The first request is answered once release is
+// closed.
+func newFakeAzure(t *testing.T, release <-chan struct{}, requests chan<- string) string {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Ocp-Apim-Subscription-Key") != "test" {
+			t.Errorf("expected the subscription key header")
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		second := make([]byte, 32000)
+		sendAudio := func(requestID string, audio []byte) {
+			header := "Path: audio\r\nX-RequestId: " + requestID + "\r\n"
+			msg := binary.BigEndian.AppendUint16(nil, uint16(len(header)))
+			_ = conn.WriteMessage(websocket.BinaryMessage, append(append(msg, header...), audio...))
+		}
+
+		first := true
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			header, body := parseTextMessage(msg)
+			if header.Get("Path") != "ssml" {
+				continue
+			}
+			if first {
+				<-release
+				first = false
+			}
+			requestID := header.Get("X-RequestId")
+			text := voiceText.FindStringSubmatch(string(body))[1]
+			requests <- text
+
+			for i := range strings.Fields(text) {
+				metadata := fmt.Sprintf(`{"Metadata":[{"Type":"WordBoundary","Data":{"Offset":%d,"Duration":%d}}]}`, i*ticksPerSecond, ticksPerSecond/2)
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(textMessage("audio.metadata", requestID, "application/json", metadata)))
+				sendAudio(requestID, second)
+			}
+			sendAudio(requestID, second[:16000])
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(textMessage("turn.end", requestID, "application/json", "{}")))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestSpeechGeneratorReportsMarksAtTheirLastWord(t *testing.T) {
+	release := make(chan struct{})
+	requests := make(chan string, 10)
+	client, err := NewTextToSpeechClient(context.Background(), DefaultVoice,
+		WithRegion("westeurope"),
+		WithCredentials(credentials.Static{envVarApiKeyName: "test"}),
+		WithLogger(logging.Discard()))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.endpoint = newFakeAzure(t, release, requests)
+
+	mu := sync.Mutex{}
+	got := []string{}
+	record := func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, entry)
+	}
+	ended := make(chan struct{})
+	generator, err := client.NewSpeechGeneratorV0(context.Background(),
+		texttospeech.WithEncodingInfo(audio.EncodingInfo{SampleRate: 16000, Format: audio.EncodingLinear16}),
+		texttospeech.WithSpeechAudioCallback(func(audio []byte) { record(fmt.Sprintf("audio %d", len(audio))) }),
+		texttospeech.WithSpeechMarkCallback(func(text string) { record("mark: " + text) }),
+		texttospeech.WithSpeechEndedCallbackV0(func(texttospeech.SpeechEndedReport) {
+			record("ended")
+			close(ended)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create speech generator: %v", err)
+	}
+
+	for _, text := range []string{"Hi.", " Hello there.", " How are you?"} {
+		if err := generator.SendText(text); err != nil {
+			t.Fatalf("failed to send text: %v", err)
+		}
+		if err := generator.Mark(); err != nil {
+			t.Fatalf("failed to mark: %v", err)
+		}
+	}
+	if err := generator.EndOfText(); err != nil {
+		t.Fatalf("failed to end text: %v", err)
+	}
+	close(release)
+
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("expected speech to end")
+	}
+
+	close(requests)
+	gotRequests := []string{}
+	for request := range requests {
+		gotRequests = append(gotRequests, request)
+	}
+	if want := []string{"Hi.", "Hello there. How are you?"}; !reflect.DeepEqual(gotRequests, want) {
+		t.Fatalf("expected the marks queued during a synthesis to be synthesized together, expected %q, got %q", want, gotRequests)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"audio 32000", "mark: Hi.", "audio 16000",
+		"audio 32000", "audio 32000", "mark:  Hello there.",
+		"audio 32000", "audio 32000", "audio 32000", "mark:  How are you?", "audio 16000",
+		"ended",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestConvertEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		encoding audio.EncodingInfo
+		want     string
+	}{
+		{encoding: audio.EncodingInfo{SampleRate: 24000, Format: audio.EncodingLinear16}, want: "raw-24khz-16bit-mono-pcm"},
+		{encoding: audio.EncodingInfo{SampleRate: 8000, Format: audio.EncodingMulaw}, want: "raw-8khz-8bit-mono-mulaw"},
+		{encoding: audio.EncodingInfo{SampleRate: 16000, Format: audio.EncodingALaw}},
+	} {
+		got, err := convertEncoding(tc.encoding)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("expected %v to be unsupported", tc.encoding)
+			}
+			continue
+		}
+		if err != nil || got.OutputFormat != tc.want {
+			t.Errorf("expected %v to convert to %q, got %v, %v", tc.encoding, tc.want, got, err)
+		}
+	}
+}
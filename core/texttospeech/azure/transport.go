@@ -0,0 +1,17 @@
+package azure
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// newHTTPClient returns a client that records a span per request and
+// propagates the trace context of the request to Azure.
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport,
+		otelhttp.WithSpanNameFormatter(func(operationName string, request *http.Request) string {
+			return operationName + " " + request.URL.Path
+		}),
+	)}
+}
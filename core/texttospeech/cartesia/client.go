@@ -0,0 +1,101 @@
+package cartesia
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+const (
+	envVarApiKeyName = "CARTESIA_API_KEY"
+
+	// apiVersion is the version of Cartesia's API the client speaks.
+	apiVersion = "2025-04-16"
+
+	DefaultModel = "sonic-2"
+)
+
+type TextToSpeechClient struct {
+	voice    string
+	model    string
+	language string
+
+	credentials credentials.Credentials
+	logger      logging.Logger
+
+	// endpoint overrides the websocket endpoint, for tests.
+	endpoint string
+}
+
+// NewTextToSpeechClient creates a client speaking with voice, the ID of one
+// of Cartesia's voices.
+func NewTextToSpeechClient(ctx context.Context, voice string, opts ...ClientOption) (*TextToSpeechClient, error) {
+	options := ClientOptions{
+		model:       DefaultModel,
+		language:    "en",
+		credentials: credentials.Default(),
+		logger:      logging.Default(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if voice == "" {
+		return nil, fmt.Errorf("invalid voice")
+	}
+
+	return &TextToSpeechClient{
+		voice:       voice,
+		model:       options.model,
+		language:    options.language,
+		credentials: options.credentials,
+		logger:      options.logger,
+		endpoint:    "wss://api.cartesia.ai/tts/websocket",
+	}, nil
+}
+
+type ClientOptions struct {
+	model       string
+	language    string
+	credentials credentials.Credentials
+	logger      logging.Logger
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithModel sets the model speech is generated with, defaults to
+// [DefaultModel].
+func WithModel(model string) ClientOption {
+	return func(o *ClientOptions) {
+		o.model = model
+	}
+}
+
+// WithLanguage sets the language of the text, e.g. "de", defaults to "en".
+func WithLanguage(language string) ClientOption {
+	return func(o *ClientOptions) {
+		o.language = language
+	}
+}
+
+// WithCredentials sets the credentials the CARTESIA_API_KEY is resolved from
+// each time a speech generator is created, defaults to [credentials.Default].
+func WithCredentials(source credentials.Credentials) ClientOption {
+	return func(o *ClientOptions) {
+		o.credentials = source
+	}
+}
+
+// WithLogger sets the logger for websocket failures, defaults to
+// [logging.Default].
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(o *ClientOptions) {
+		o.logger = logger
+	}
+}
+
+func (c *TextToSpeechClient) SetVoice(voice string) {
+	c.voice = voice
+}
@@ -0,0 +1,9 @@
+// Package cartesia provides a text to speech client for Cartesia's Sonic
+// models.
+//
+// Text is streamed over Cartesia's websocket API as continuations of a single
+// context, so the speech flows naturally across marks. Cartesia reports when
+// each word is spoken, which is passed on with
+// [texttospeech.WithSpeechTimestampsCallback] and times the marks, reported
+// once the audio of the last word before them was generated.
+package cartesia
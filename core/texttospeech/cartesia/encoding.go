@@ -0,0 +1,41 @@
+package cartesia
+
+import (
+	"fmt"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+type encodingInfo struct {
+	// Encoding is the name of the raw audio encoding in Cartesia's terms.
+	Encoding       string
+	SampleRate     int
+	BytesPerSecond int
+}
+
+func convertEncoding(encoding audio.EncodingInfo) (*encodingInfo, error) {
+	switch encoding.Format {
+	case audio.EncodingLinear16:
+		switch encoding.SampleRate {
+		case 8000, 16000, 22050, 24000, 44100, 48000:
+		default:
+			return nil, fmt.Errorf("unsupported sample rate")
+		}
+		return &encodingInfo{
+			Encoding:       "pcm_s16le",
+			SampleRate:     encoding.SampleRate,
+			BytesPerSecond: encoding.SampleRate * 2,
+		}, nil
+	case audio.EncodingMulaw, audio.EncodingALaw:
+		if encoding.SampleRate != 8000 {
+			return nil, fmt.Errorf("unsupported sample rate for %s encoding", encoding.Format.Name())
+		}
+		return &encodingInfo{
+			Encoding:       "pcm_" + encoding.Format.Name(),
+			SampleRate:     encoding.SampleRate,
+			BytesPerSecond: encoding.SampleRate,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding")
+	}
+}
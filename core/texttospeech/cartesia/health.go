@@ -0,0 +1,34 @@
+package cartesia
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// HealthCheck lists the voices, which fails if Cartesia is unreachable or
+// rejects the key, without generating any speech.
+func (c *TextToSpeechClient) HealthCheck(ctx context.Context) error {
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return fmt.Errorf("cartesia api key not found: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.cartesia.ai/voices", nil)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %w", err)
+	}
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("Cartesia-Version", apiVersion)
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("non-OK HTTP status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package cartesia
+
+import (
+	"context"
+	"fmt"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "cartesia" text to speech of [config], so
+// configs can select it once the package is imported. The voice is required,
+// the "language" option sets the language of the text.
+func init() {
+	config.RegisterTextToSpeech("cartesia", newConfiguredTextToSpeech)
+}
+
+func newConfiguredTextToSpeech(ctx context.Context, provider config.Provider) (orchestration.TextToSpeechV1, error) {
+	if provider.Voice == "" {
+		return nil, fmt.Errorf("cartesia requires the id of a voice")
+	}
+
+	opts := []ClientOption{}
+	if provider.Model != "" {
+		opts = append(opts, WithModel(provider.Model))
+	}
+	if language := provider.Options["language"]; language != "" {
+		opts = append(opts, WithLanguage(language))
+	}
+	return NewTextToSpeechClient(ctx, provider.Voice, opts...)
+}
@@ -0,0 +1,445 @@
+package cartesia
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+type speechGenerator struct {
+	ws   *websocket.Conn
+	wsMu sync.Mutex
+
+	// contextID identifies the context the text is sent as continuations of.
+	contextID string
+	voice     string
+	model     string
+	language  string
+	encoding  encodingInfo
+	options   texttospeech.TextToSpeechOptions
+	logger    logging.Logger
+
+	mu       sync.Mutex
+	pending  string    // text sent since the last mark
+	segments []segment // text sent to Cartesia, in order
+	// timestamps is how many word timestamps arrived.
+	timestamps int
+	// generated is how much audio was generated.
+	generated time.Duration
+	// reported is how many segments were reported.
+	reported int
+
+	textComplete bool
+	cancelled    bool
+	closed       bool
+
+	report texttospeech.SpeechEndedReport
+}
+
+// segment is the text between two marks.
+type segment struct {
+	Text string
+	// Marked is unset for text left after the last mark, which is spoken
+	// but not reported.
+	Marked bool
+	// words is how many word timestamps Cartesia reports for the text.
+	words int
+	// end is when the last word ends in the audio, negative until its
+	// timestamp arrives.
+	end time.Duration
+}
+
+type generationRequest struct {
+	ModelID       string       `json:"model_id"`
+	Transcript    string       `json:"transcript"`
+	Voice         voice        `json:"voice"`
+	Language      string       `json:"language"`
+	ContextID     string       `json:"context_id"`
+	OutputFormat  outputFormat `json:"output_format"`
+	AddTimestamps bool         `json:"add_timestamps"`
+	Continue      bool         `json:"continue"`
+}
+
+type voice struct {
+	Mode string `json:"mode"`
+	ID   string `json:"id"`
+}
+
+type outputFormat struct {
+	Container  string `json:"container"`
+	Encoding   string `json:"encoding"`
+	SampleRate int    `json:"sample_rate"`
+}
+
+type generationResponse struct {
+	Type      string `json:"type"`
+	ContextID string `json:"context_id"`
+	// Data is the audio of chunk responses.
+	Data           []byte `json:"data"`
+	Error          string `json:"error"`
+	WordTimestamps *struct {
+		Words []string  `json:"words"`
+		Start []float64 `json:"start"`
+		End   []float64 `json:"end"`
+	} `json:"word_timestamps"`
+}
+
+func (c *TextToSpeechClient) NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
+	options := texttospeech.TextToSpeechOptions{
+		AudioCallback:            func([]byte) {},
+		AudioEnded:               func(string) {},
+		SpeechAudioCallback:      func([]byte) {},
+		SpeechMarkCallback:       func(string) {},
+		SpeechEndedCallbackV0:    func(texttospeech.SpeechEndedReport) {},
+		SpeechTimestampsCallback: func([]texttospeech.WordTimestamp) {},
+		ErrorCallback:            func(error) {},
+		EncodingInfo:             audio.GetDefaultEncodingInfo(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	encoding, err := convertEncoding(options.EncodingInfo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encoding: %w", err)
+	}
+
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("cartesia api key not found: %w", err)
+	}
+
+	ws, err := connectWebsocket(ctx, c.endpoint, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open websocket: %w", err)
+	}
+
+	generator := &speechGenerator{
+		ws:        ws,
+		contextID: uuid.NewString(),
+		voice:     c.voice,
+		model:     c.model,
+		language:  c.language,
+		encoding:  *encoding,
+		options:   options,
+		logger:    c.logger,
+	}
+	go generator.processIncomingMessages(ctx)
+
+	return generator, nil
+}
+
+func connectWebsocket(ctx context.Context, endpoint string, apiKey string) (*websocket.Conn, error) {
+	ctx, span := tracer.Start(ctx, "connect cartesia websocket")
+	defer span.End()
+
+	header := http.Header{
+		"X-API-Key":        {apiKey},
+		"Cartesia-Version": {apiVersion},
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, endpoint, header)
+	if err != nil {
+		err = fmt.Errorf("failed to open socket connection to cartesia: %w", err)
+		errorreport.Record(ctx, span, err, "provider", "cartesia")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (g *speechGenerator) SendText(text string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		return fmt.Errorf("speech generator cancelled")
+	} else if g.textComplete {
+		return fmt.Errorf("speech generator text already completed")
+	}
+
+	g.pending += text
+	return nil
+}
+
+func (g *speechGenerator) Mark() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		return fmt.Errorf("speech generator cancelled")
+	} else if g.textComplete {
+		return fmt.Errorf("speech generator text already completed")
+	}
+
+	if g.pending == "" {
+		return nil
+	}
+	g.segments = append(g.segments, newSegment(g.pending, true))
+	if err := g.sendTranscript(g.pending, true); err != nil {
+		return fmt.Errorf("failed to send text: %w", err)
+	}
+	g.pending = ""
+	return nil
+}
+
+func (g *speechGenerator) EndOfText() error {
+	g.mu.Lock()
+
+	if g.closed {
+		g.mu.Unlock()
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		g.mu.Unlock()
+		return fmt.Errorf("speech generator cancelled")
+	} else if g.textComplete {
+		g.mu.Unlock()
+		return nil
+	}
+
+	g.textComplete = true
+	if strings.TrimSpace(g.pending) != "" {
+		g.segments = append(g.segments, newSegment(g.pending, false))
+	}
+	ended := len(g.segments) == 0
+	if !ended {
+		if err := g.sendTranscript(g.pending, false); err != nil {
+			g.mu.Unlock()
+			return fmt.Errorf("failed to send end of text: %w", err)
+		}
+	}
+	g.pending = ""
+	g.mu.Unlock()
+
+	if ended {
+		g.options.SpeechEndedCallbackV0(g.report)
+		_ = g.Close() // TODO: See if we need to react on this error
+	}
+	return nil
+}
+
+func (g *speechGenerator) Cancel() error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		g.mu.Unlock()
+		return nil
+	}
+	g.cancelled = true
+	g.mu.Unlock()
+
+	return g.Close()
+}
+
+func (g *speechGenerator) Close() error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return nil
+	}
+	g.closed = true
+	g.mu.Unlock()
+
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+	_ = g.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")) // Ignored on purpose, the connection is closed regardless
+	if err := g.ws.Close(); err != nil {
+		return fmt.Errorf("failed to close websocket: %w", err)
+	}
+	return nil
+}
+
+// sendTranscript sends text as a continuation of the context, the last one
+// unless more follows.
+//
+// Must be called with mu held.
+func (g *speechGenerator) sendTranscript(text string, more bool) error {
+	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+	return g.ws.WriteJSON(generationRequest{
+		ModelID:    g.model,
+		Transcript: text,
+		Voice:      voice{Mode: "id", ID: g.voice},
+		Language:   g.language,
+		ContextID:  g.contextID,
+		OutputFormat: outputFormat{
+			Container:  "raw",
+			Encoding:   g.encoding.Encoding,
+			SampleRate: g.encoding.SampleRate,
+		},
+		AddTimestamps: true,
+		Continue:      more,
+	})
+}
+
+func newSegment(text string, marked bool) segment {
+	words := 0
+	for _, word := range strings.Fields(text) {
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) >= 0 {
+			words++
+		}
+	}
+	return segment{Text: text, Marked: marked, words: words, end: -1}
+}
+
+func (g *speechGenerator) processIncomingMessages(ctx context.Context) {
+	_, span := tracer.Start(ctx, "read cartesia websocket")
+	defer span.End()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			errorreport.ReportPanic(ctx, "cartesia read loop", recovered, debug.Stack(), "provider", "cartesia")
+			_ = g.Close() // Ignored on purpose
+		}
+	}()
+
+	for {
+		var response generationResponse
+		if err := g.ws.ReadJSON(&response); err != nil {
+			g.mu.Lock()
+			closed := g.closed
+			g.mu.Unlock()
+			if !closed && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				g.logger.Error("cartesia websocket read error", "error", err)
+				errorreport.Record(ctx, span, err, "provider", "cartesia")
+				span.SetStatus(codes.Error, "cartesia websocket read error")
+				g.options.ErrorCallback(err)
+			}
+			_ = g.Close() // Ignored on purpose
+			return
+		}
+		if response.ContextID != "" && response.ContextID != g.contextID {
+			continue
+		}
+
+		switch response.Type {
+		case "chunk":
+			if len(response.Data) == 0 {
+				continue
+			}
+			g.options.SpeechAudioCallback(response.Data)
+			g.reportMarks(func() {
+				g.generated += time.Duration(len(response.Data)) * time.Second / time.Duration(g.encoding.BytesPerSecond)
+			})
+		case "timestamps":
+			if response.WordTimestamps == nil {
+				continue
+			}
+			words := response.WordTimestamps
+			timestamps := make([]texttospeech.WordTimestamp, 0, len(words.Words))
+			for i, word := range words.Words {
+				if i >= len(words.Start) || i >= len(words.End) {
+					break
+				}
+				timestamps = append(timestamps, texttospeech.WordTimestamp{
+					Word:  word,
+					Start: secondsToDuration(words.Start[i]),
+					End:   secondsToDuration(words.End[i]),
+				})
+			}
+			g.options.SpeechTimestampsCallback(timestamps)
+			g.reportMarks(func() {
+				for _, timestamp := range timestamps {
+					g.addTimestamp(timestamp.End)
+				}
+			})
+		case "done":
+			g.reportMarks(func() {
+				for i := range g.segments {
+					g.generated = max(g.generated, g.segments[i].end)
+				}
+				for i := range g.segments {
+					if g.segments[i].end < 0 {
+						g.segments[i].end = g.generated
+					}
+				}
+			})
+			g.options.SpeechEndedCallbackV0(g.report)
+			_ = g.Close() // Ignored on purpose
+			return
+		case "error":
+			err := fmt.Errorf("cartesia error: %s", response.Error)
+			g.logger.Error("cartesia generation failed", "error", err)
+			errorreport.Record(ctx, span, err, "provider", "cartesia")
+			span.SetStatus(codes.Error, "cartesia generation failed")
+			g.options.ErrorCallback(err)
+			_ = g.Close() // Ignored on purpose
+			return
+		}
+	}
+}
+
+// reportMarks updates the generation progress and reports the marks whose
+// text was spoken since.
+func (g *speechGenerator) reportMarks(update func()) {
+	g.mu.Lock()
+	update()
+	marks := g.spokenMarks()
+	g.mu.Unlock()
+
+	for _, mark := range marks {
+		g.options.SpeechMarkCallback(mark)
+	}
+}
+
+// addTimestamp records a word ending at end, which ends a segment if it is
+// its last word.
+//
+// Must be called with mu held.
+func (g *speechGenerator) addTimestamp(end time.Duration) {
+	g.timestamps++
+	words := 0
+	for i := range g.segments {
+		words += g.segments[i].words
+		if words == g.timestamps && g.segments[i].words > 0 {
+			g.segments[i].end = end
+			return
+		}
+		if words > g.timestamps {
+			return
+		}
+	}
+}
+
+// spokenMarks returns the text of the marked segments whose audio was
+// generated and that were not reported yet, in order.
+//
+// Must be called with mu held.
+func (g *speechGenerator) spokenMarks() []string {
+	marks := []string{}
+	for ; g.reported < len(g.segments); g.reported++ {
+		segment := g.segments[g.reported]
+		if segment.words > 0 && (segment.end < 0 || segment.end > g.generated) {
+			break
+		}
+		if segment.Marked {
+			marks = append(marks, segment.Text)
+		}
+	}
+	return marks
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
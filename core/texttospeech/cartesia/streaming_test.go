@@ -0,0 +1,176 @@
+package cartesia
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+// newFakeCartesia serves Cartesia's websocket protocol, speaking each word of
+// a transcript for a second and ending its timestamp halfway through. A
+// request is answered with an error instead when fail is set.
+func newFakeCartesia(t *testing.T, fail string) string {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "test" || r.Header.Get("Cartesia-Version") != apiVersion {
+			t.Errorf("expected the api key and version headers")
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		second := make([]byte, 32000)
+		offset := 0.0
+		for {
+			var request generationRequest
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			if fail != "" {
+				_ = conn.WriteJSON(map[string]any{"type": "error", "context_id": request.ContextID, "error": fail})
+				continue
+			}
+
+			words := strings.Fields(request.Transcript)
+			starts, ends := []float64{}, []float64{}
+			for i := range words {
+				starts = append(starts, offset+float64(i))
+				ends = append(ends, offset+float64(i)+0.5)
+			}
+			if len(words) > 0 {
+				_ = conn.WriteJSON(map[string]any{"type": "timestamps", "context_id": request.ContextID,
+					"word_timestamps": map[string]any{"words": words, "start": starts, "end": ends}})
+			}
+			for range words {
+				_ = conn.WriteJSON(map[string]any{"type": "chunk", "context_id": request.ContextID, "data": second})
+			}
+			offset += float64(len(words))
+			if !request.Continue {
+				_ = conn.WriteJSON(map[string]any{"type": "done", "context_id": request.ContextID})
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func newTestClient(t *testing.T, fail string) *TextToSpeechClient {
+	t.Helper()
+
+	client, err := NewTextToSpeechClient(context.Background(), "voice-id",
+		WithCredentials(credentials.Static{envVarApiKeyName: "test"}),
+		WithLogger(logging.Discard()))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.endpoint = newFakeCartesia(t, fail)
+	return client
+}
+
+func TestSpeechGeneratorReportsTimestampsAndMarksAtTheirLastWord(t *testing.T) {
+	client := newTestClient(t, "")
+
+	mu := sync.Mutex{}
+	got := []string{}
+	record := func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, entry)
+	}
+	ended := make(chan struct{})
+	generator, err := client.NewSpeechGeneratorV0(context.Background(),
+		texttospeech.WithEncodingInfo(audio.EncodingInfo{SampleRate: 16000, Format: audio.EncodingLinear16}),
+		texttospeech.WithSpeechAudioCallback(func(audio []byte) { record(fmt.Sprintf("audio %d", len(audio))) }),
+		texttospeech.WithSpeechMarkCallback(func(text string) { record("mark: " + text) }),
+		texttospeech.WithSpeechTimestampsCallback(func(words []texttospeech.WordTimestamp) {
+			for _, word := range words {
+				record(fmt.Sprintf("word %s %v-%v", word.Word, word.Start, word.End))
+			}
+		}),
+		texttospeech.WithSpeechEndedCallbackV0(func(texttospeech.SpeechEndedReport) {
+			record("ended")
+			close(ended)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create speech generator: %v", err)
+	}
+
+	for _, text := range []string{"Hi.", " Hello there."} {
+		if err := generator.SendText(text); err != nil {
+			t.Fatalf("failed to send text: %v", err)
+		}
+		if err := generator.Mark(); err != nil {
+			t.Fatalf("failed to mark: %v", err)
+		}
+	}
+	if err := generator.SendText(" Bye."); err != nil {
+		t.Fatalf("failed to send text: %v", err)
+	}
+	if err := generator.EndOfText(); err != nil {
+		t.Fatalf("failed to end text: %v", err)
+	}
+
+	select {
+	case <-ended:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the speech to end")
+	}
+
+	expected := []string{
+		"word Hi. 0s-500ms", "audio 32000", "mark: Hi.",
+		"word Hello 1s-1.5s", "word there. 2s-2.5s", "audio 32000", "audio 32000", "mark:  Hello there.",
+		"word Bye. 3s-3.5s", "audio 32000",
+		"ended",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected callbacks:\n got %q\nwant %q", got, expected)
+	}
+}
+
+func TestSpeechGeneratorReportsCartesiaErrors(t *testing.T) {
+	client := newTestClient(t, "invalid voice")
+
+	failed := make(chan error, 1)
+	generator, err := client.NewSpeechGeneratorV0(context.Background(),
+		texttospeech.WithEncodingInfo(audio.EncodingInfo{SampleRate: 16000, Format: audio.EncodingLinear16}),
+		texttospeech.WithErrorCallback(func(err error) { failed <- err }),
+	)
+	if err != nil {
+		t.Fatalf("failed to create speech generator: %v", err)
+	}
+
+	if err := generator.SendText("Hi."); err != nil {
+		t.Fatalf("failed to send text: %v", err)
+	}
+	if err := generator.Mark(); err != nil {
+		t.Fatalf("failed to mark: %v", err)
+	}
+
+	select {
+	case err := <-failed:
+		if !strings.Contains(err.Error(), "invalid voice") {
+			t.Fatalf("expected the cartesia error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for the error")
+	}
+}
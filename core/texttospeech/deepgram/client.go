@@ -7,21 +7,32 @@ import (
 	"sync"
 
 	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
 	"github.com/koscakluka/ema-core/core/texttospeech"
 )
 
+const envVarApiKeyName = "DEEPGRAM_API_KEY"
+
 type TextToSpeechClient struct {
 	wsConn            *websocket.Conn
 	transcriptBuffer  []string
 	postRestartBuffer []string
 	options           texttospeech.TextToSpeechOptions
 
-	voice deepgramVoice
-	mu    sync.Mutex
+	voice       deepgramVoice
+	credentials credentials.Credentials
+	logger      logging.Logger
+	mu          sync.Mutex
 }
 
-func NewTextToSpeechClient(ctx context.Context, voice deepgramVoice) (*TextToSpeechClient, error) {
-	client := &TextToSpeechClient{voice: defaultVoice}
+func NewTextToSpeechClient(ctx context.Context, voice deepgramVoice, opts ...ClientOption) (*TextToSpeechClient, error) {
+	options := ClientOptions{credentials: credentials.Default(), logger: logging.Default()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	client := &TextToSpeechClient{voice: defaultVoice, credentials: options.credentials, logger: options.logger}
 
 	if !slices.Contains(GetAvailableVoices(), voice) {
 		return nil, fmt.Errorf("invalid voice")
@@ -32,6 +43,29 @@ func NewTextToSpeechClient(ctx context.Context, voice deepgramVoice) (*TextToSpe
 	return client, nil
 }
 
+type ClientOptions struct {
+	credentials credentials.Credentials
+	logger      logging.Logger
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithCredentials sets the credentials the DEEPGRAM_API_KEY is resolved from
+// each time a stream is opened, defaults to [credentials.Default].
+func WithCredentials(source credentials.Credentials) ClientOption {
+	return func(o *ClientOptions) {
+		o.credentials = source
+	}
+}
+
+// WithLogger sets the logger for websocket failures, defaults to
+// [logging.Default].
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(o *ClientOptions) {
+		o.logger = logger
+	}
+}
+
 func (c *TextToSpeechClient) Close(ctx context.Context) {
 	c.CloseStream(ctx)
 }
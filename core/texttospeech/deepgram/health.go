@@ -0,0 +1,33 @@
+package deepgram
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gorilla/websocket"
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+// HealthCheck opens and immediately closes a speech stream, which fails if
+// Deepgram is unreachable or rejects the API key, without synthesizing
+// anything.
+func (c *TextToSpeechClient) HealthCheck(ctx context.Context) error {
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return fmt.Errorf("deepgram api key not found: %w", err)
+	}
+
+	encodingInfo, err := convertEncoding(audio.GetDefaultEncodingInfo())
+	if err != nil {
+		return fmt.Errorf("invalid encoding: %w", err)
+	}
+
+	conn, err := connectWebsocket(ctx, apiKey, c.voice, *encodingInfo)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")) // Ignored on purpose, the check already passed
+	return nil
+}
@@ -0,0 +1,27 @@
+package deepgram
+
+import (
+	"context"
+	"fmt"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "deepgram" text to speech of [config], so
+// configs can select it once the package is imported.
+func init() {
+	config.RegisterTextToSpeech("deepgram", newConfiguredTextToSpeech)
+}
+
+func newConfiguredTextToSpeech(ctx context.Context, provider config.Provider) (orchestration.TextToSpeechV1, error) {
+	if provider.Voice == "" {
+		return NewTextToSpeechClient(ctx, VoiceAura2Asteria)
+	}
+	for _, voice := range GetAvailableVoices() {
+		if string(voice) == provider.Voice {
+			return NewTextToSpeechClient(ctx, voice)
+		}
+	}
+	return nil, fmt.Errorf("unknown deepgram voice %q", provider.Voice)
+}
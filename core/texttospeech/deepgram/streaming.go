@@ -5,16 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
-	"os"
+	"runtime/debug"
 	"strconv"
 	"sync"
 
 	"github.com/gorilla/websocket"
 	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"github.com/koscakluka/ema-core/core/logging"
 	"github.com/koscakluka/ema-core/core/texttospeech"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 type streamingRequest struct {
@@ -31,6 +35,7 @@ type streamingRequest struct {
 	closed       bool
 
 	report texttospeech.SpeechEndedReport
+	logger logging.Logger
 }
 
 type streamingRequestOptions struct {
@@ -40,6 +45,7 @@ type streamingRequestOptions struct {
 
 func (c *TextToSpeechClient) NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
 	req := &streamingRequest{
+		logger: c.logger,
 		options: streamingRequestOptions{
 			TextToSpeechOptions: texttospeech.TextToSpeechOptions{
 				AudioCallback:         func([]byte) {},
@@ -62,7 +68,12 @@ func (c *TextToSpeechClient) NewSpeechGeneratorV0(ctx context.Context, opts ...t
 		return nil, fmt.Errorf("invalid encoding: %w", err)
 	}
 
-	if req.ws, err = connectWebsocket(c.voice, *encodingInfo); err != nil {
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("deepgram api key not found: %w", err)
+	}
+
+	if req.ws, err = connectWebsocket(ctx, apiKey, c.voice, *encodingInfo); err != nil {
 		return nil, fmt.Errorf("failed to open websocket: %w", err)
 	}
 
@@ -71,12 +82,9 @@ func (c *TextToSpeechClient) NewSpeechGeneratorV0(ctx context.Context, opts ...t
 	return req, nil
 }
 
-func connectWebsocket(voice deepgramVoice, encodingInfo encodingInfo) (*websocket.Conn, error) {
-	// TODO: Allow passing API key in constructor
-	apiKey, ok := os.LookupEnv("DEEPGRAM_API_KEY")
-	if !ok {
-		return nil, fmt.Errorf("deepgram api key not found")
-	}
+func connectWebsocket(ctx context.Context, apiKey string, voice deepgramVoice, encodingInfo encodingInfo) (*websocket.Conn, error) {
+	ctx, span := tracer.Start(ctx, "connect deepgram speech websocket")
+	defer span.End()
 
 	urlValues := url.Values{}
 	urlValues.Set("encoding", encodingInfo.Format.Name())
@@ -84,22 +92,36 @@ func connectWebsocket(voice deepgramVoice, encodingInfo encodingInfo) (*websocke
 	urlValues.Set("model", string(voice))
 	urlValues.Set("container", "none")
 
-	// TODO: Use DialContext
-	conn, _, err := websocket.DefaultDialer.Dial(
+	header := http.Header{"Authorization": {"token " + apiKey}}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx,
 		(&url.URL{
 			Scheme: "wss",
 			Host:   "api.deepgram.com", Path: "/v1/speak",
 			RawQuery: urlValues.Encode(),
 		}).String(),
-		http.Header{"Authorization": {"token " + apiKey}})
+		header)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open socket connection to deepgram: %w", err)
+		err = fmt.Errorf("failed to open socket connection to deepgram: %w", err)
+		errorreport.Record(ctx, span, err, "provider", "deepgram")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
 
 	return conn, nil
 }
 
 func (r *streamingRequest) processIncomingMessages(ctx context.Context) {
+	_, span := tracer.Start(ctx, "read deepgram speech websocket")
+	defer span.End()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			errorreport.ReportPanic(ctx, "deepgram speech read loop", recovered, debug.Stack(), "provider", "deepgram")
+			if err := r.Cancel(); err != nil {
+				_ = r.Close() // Ignored on purpose
+			}
+		}
+	}()
 
 	// TODO: We can probably stop once we close or cancel
 	for {
@@ -107,8 +129,9 @@ func (r *streamingRequest) processIncomingMessages(ctx context.Context) {
 		if err != nil {
 			// TODO: Actually figure out this message instead of comparing to a string
 			if err.Error() != "websocket: close 1000 (normal)" {
-				// TODO: Instrument
-				log.Printf("Websocket read error: %v", err)
+				r.logger.Error("deepgram websocket read error", "error", err)
+				errorreport.Record(ctx, span, err, "provider", "deepgram")
+				span.SetStatus(codes.Error, "deepgram websocket read error")
 				if err := r.Cancel(); err != nil {
 					_ = r.Close() // Ignored on purpose
 					return
@@ -344,7 +367,12 @@ func (c *TextToSpeechClient) OpenStream(ctx context.Context, opts ...texttospeec
 		return fmt.Errorf("invalid encoding: %w", err)
 	}
 
-	conn, err := connectWebsocket(c.voice, *encodingInfo)
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return fmt.Errorf("deepgram api key not found: %w", err)
+	}
+
+	conn, err := connectWebsocket(ctx, apiKey, c.voice, *encodingInfo)
 	if err != nil {
 		return fmt.Errorf("failed to open websocket: %w", err)
 	}
@@ -466,7 +494,7 @@ func (c *TextToSpeechClient) CloseStream(ctx context.Context) error {
 		}{
 			Type: "Close",
 		}); err != nil {
-			log.Printf("Failed to send close message to deepgram websocket: %v", err)
+			c.logger.Error("failed to send close message to deepgram websocket", "error", err)
 		}
 
 	}
@@ -474,12 +502,24 @@ func (c *TextToSpeechClient) CloseStream(ctx context.Context) error {
 	return nil
 }
 
-func (c *TextToSpeechClient) readAndProcessMessages(_ context.Context, conn *websocket.Conn, options texttospeech.TextToSpeechOptions) {
+func (c *TextToSpeechClient) readAndProcessMessages(ctx context.Context, conn *websocket.Conn, options texttospeech.TextToSpeechOptions) {
+	_, span := tracer.Start(ctx, "read deepgram speech websocket")
+	defer span.End()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			errorreport.ReportPanic(ctx, "deepgram speech read loop", recovered, debug.Stack(), "provider", "deepgram")
+			conn.Close()
+			c.wsConn = nil
+		}
+	}()
+
 	for {
 		msgType, msg, err := conn.ReadMessage()
 		if err != nil {
 			if err.Error() != "websocket: close 1000 (normal)" {
-				log.Printf("Websocket read error: %v", err)
+				c.logger.Error("deepgram websocket read error", "error", err)
+				errorreport.Record(ctx, span, err, "provider", "deepgram")
+				span.SetStatus(codes.Error, "deepgram websocket read error")
 			}
 
 			c.wsConn.Close()
@@ -499,7 +539,7 @@ func (c *TextToSpeechClient) readAndProcessMessages(_ context.Context, conn *web
 			}
 			err := json.Unmarshal(msg, &parsedMsg)
 			if err != nil {
-				log.Printf("Failed to unmarshal deepgram message: %v", err)
+				c.logger.Error("failed to unmarshal deepgram message", "error", err)
 				continue
 			}
 
@@ -513,13 +553,13 @@ func (c *TextToSpeechClient) readAndProcessMessages(_ context.Context, conn *web
 				}
 				if len(c.transcriptBuffer) > 0 {
 					if err := c.speak(c.transcriptBuffer[0]); err != nil {
-						log.Printf("Failed to speak deepgram text: %v", err)
+						c.logger.Error("failed to speak deepgram text", "error", err)
 						continue
 					}
 				}
 				if len(c.transcriptBuffer) > 1 {
 					if err := c.flush(); err != nil {
-						log.Printf("Failed to flush deepgram buffer: %v", err)
+						c.logger.Error("failed to flush deepgram buffer", "error", err)
 						continue
 					}
 				}
@@ -0,0 +1,95 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+const (
+	envVarApiKeyName = "OPENAI_API_KEY"
+
+	speechUrl = "https://api.openai.com/v1/audio/speech"
+	modelsUrl = "https://api.openai.com/v1/models"
+)
+
+type TextToSpeechClient struct {
+	voice        openaiVoice
+	model        SpeechModel
+	instructions string
+
+	credentials credentials.Credentials
+	logger      logging.Logger
+
+	speechUrl string
+}
+
+func NewTextToSpeechClient(ctx context.Context, voice openaiVoice, opts ...ClientOption) (*TextToSpeechClient, error) {
+	options := ClientOptions{credentials: credentials.Default(), logger: logging.Default(), model: defaultModel}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if !slices.Contains(GetAvailableVoices(), voice) {
+		return nil, fmt.Errorf("invalid voice")
+	}
+	if !slices.Contains(GetAvailableModels(), options.model) {
+		return nil, fmt.Errorf("invalid model")
+	}
+
+	return &TextToSpeechClient{
+		voice:        voice,
+		model:        options.model,
+		instructions: options.instructions,
+		credentials:  options.credentials,
+		logger:       options.logger,
+		speechUrl:    speechUrl,
+	}, nil
+}
+
+type ClientOptions struct {
+	credentials  credentials.Credentials
+	logger       logging.Logger
+	model        SpeechModel
+	instructions string
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithCredentials sets the credentials the OPENAI_API_KEY is resolved from
+// each time a speech generator is created, defaults to [credentials.Default].
+func WithCredentials(source credentials.Credentials) ClientOption {
+	return func(o *ClientOptions) {
+		o.credentials = source
+	}
+}
+
+// WithLogger sets the logger for synthesis failures, defaults to
+// [logging.Default].
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(o *ClientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithModel sets the speech model, defaults to [ModelGPT4oMiniTTS].
+func WithModel(model SpeechModel) ClientOption {
+	return func(o *ClientOptions) {
+		o.model = model
+	}
+}
+
+// WithInstructions sets how the voice should sound, e.g. its tone or accent.
+// Only [ModelGPT4oMiniTTS] follows instructions, other models ignore them.
+func WithInstructions(instructions string) ClientOption {
+	return func(o *ClientOptions) {
+		o.instructions = instructions
+	}
+}
+
+func (c *TextToSpeechClient) SetVoice(voice openaiVoice) {
+	c.voice = voice
+}
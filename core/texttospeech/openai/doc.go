@@ -0,0 +1,6 @@
+// Package openai provides a text to speech client for OpenAI's audio API.
+//
+// OpenAI synthesizes each request in full, so the client splits the text into
+// sentences and synthesizes them one after another, reporting marks once all
+// the text up to them has been spoken.
+package openai
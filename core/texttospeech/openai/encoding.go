@@ -0,0 +1,71 @@
+package openai
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
+
+// sourceSampleRate is the sample rate of the raw 16-bit PCM OpenAI returns
+// for the "pcm" response format.
+const sourceSampleRate = 24000
+
+func validateEncoding(encoding audio.EncodingInfo) error {
+	switch encoding.SampleRate {
+	case 8000, 16000, 24000, 32000, 48000:
+	default:
+		return fmt.Errorf("unsupported sample rate")
+	}
+
+	if encoding.Format != audio.EncodingLinear16 {
+		return fmt.Errorf("unsupported encoding")
+	}
+
+	return nil
+}
+
+// resampler converts the 24 kHz PCM stream of OpenAI to the requested sample
+// rate by linear interpolation. Its state carries over between calls, so audio
+// can be converted as it arrives, split at arbitrary bytes.
+type resampler struct {
+	step float64 // source samples per output sample
+	pos  float64 // position of the next output sample after prev
+
+	prev    int16
+	hasPrev bool
+	odd     []byte
+}
+
+func newResampler(sampleRate int) *resampler {
+	return &resampler{step: float64(sourceSampleRate) / float64(sampleRate)}
+}
+
+func (r *resampler) convert(pcm []byte) []byte {
+	if len(r.odd) > 0 {
+		pcm = append(r.odd, pcm...)
+		r.odd = nil
+	}
+	if len(pcm)%2 == 1 {
+		r.odd = []byte{pcm[len(pcm)-1]}
+		pcm = pcm[:len(pcm)-1]
+	}
+
+	out := make([]byte, 0, int(float64(len(pcm))/r.step)+2)
+	for i := 0; i < len(pcm); i += 2 {
+		sample := int16(binary.LittleEndian.Uint16(pcm[i:]))
+		if !r.hasPrev {
+			r.prev, r.hasPrev = sample, true
+			continue
+		}
+
+		for ; r.pos < 1; r.pos += r.step {
+			interpolated := float64(r.prev) + float64(int(sample)-int(r.prev))*r.pos
+			out = binary.LittleEndian.AppendUint16(out, uint16(int16(interpolated)))
+		}
+		r.pos--
+		r.prev = sample
+	}
+
+	return out
+}
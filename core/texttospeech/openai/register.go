@@ -0,0 +1,35 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/config"
+)
+
+// init registers the client as the "openai" text to speech of [config], so
+// configs can select it once the package is imported.
+func init() {
+	config.RegisterTextToSpeech("openai", newConfiguredTextToSpeech)
+}
+
+func newConfiguredTextToSpeech(ctx context.Context, provider config.Provider) (orchestration.TextToSpeechV1, error) {
+	opts := []ClientOption{}
+	if provider.Model != "" {
+		opts = append(opts, WithModel(SpeechModel(provider.Model)))
+	}
+	if instructions := provider.Options["instructions"]; instructions != "" {
+		opts = append(opts, WithInstructions(instructions))
+	}
+
+	if provider.Voice == "" {
+		return NewTextToSpeechClient(ctx, VoiceAlloy, opts...)
+	}
+	for _, voice := range GetAvailableVoices() {
+		if string(voice) == provider.Voice {
+			return NewTextToSpeechClient(ctx, voice, opts...)
+		}
+	}
+	return nil, fmt.Errorf("unknown openai voice %q", provider.Voice)
+}
@@ -0,0 +1,370 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type speechChunkType int
+
+const (
+	speechChunkTypeText speechChunkType = iota
+	speechChunkTypeMark
+	speechChunkTypeEnd
+)
+
+// speechChunk is a unit of work of the synthesis loop, either a sentence to
+// synthesize or a point to report once everything before it was synthesized.
+type speechChunk struct {
+	Type speechChunkType
+	Text string
+}
+
+type speechGenerator struct {
+	client  *TextToSpeechClient
+	apiKey  string
+	options texttospeech.TextToSpeechOptions
+	logger  logging.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pending string // text not yet split into sentences
+	marked  string // text sent since the last mark
+	queue   []speechChunk
+	wake    chan struct{}
+
+	textComplete bool
+	cancelled    bool
+	closed       bool
+
+	report texttospeech.SpeechEndedReport
+}
+
+func (c *TextToSpeechClient) NewSpeechGeneratorV0(ctx context.Context, opts ...texttospeech.TextToSpeechOption) (texttospeech.SpeechGeneratorV0, error) {
+	options := texttospeech.TextToSpeechOptions{
+		AudioCallback:         func([]byte) {},
+		AudioEnded:            func(string) {},
+		SpeechAudioCallback:   func([]byte) {},
+		SpeechMarkCallback:    func(string) {},
+		SpeechEndedCallbackV0: func(texttospeech.SpeechEndedReport) {},
+		ErrorCallback:         func(error) {},
+		EncodingInfo:          audio.GetDefaultEncodingInfo(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if err := validateEncoding(options.EncodingInfo); err != nil {
+		return nil, fmt.Errorf("invalid encoding: %w", err)
+	}
+
+	apiKey, err := c.credentials.Credential(ctx, envVarApiKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("openai api key not found: %w", err)
+	}
+
+	generator := &speechGenerator{
+		client:  c,
+		apiKey:  apiKey,
+		options: options,
+		logger:  c.logger,
+		wake:    make(chan struct{}, 1),
+	}
+	generator.ctx, generator.cancel = context.WithCancel(ctx)
+
+	go generator.synthesizeQueued(generator.ctx)
+
+	return generator, nil
+}
+
+func (g *speechGenerator) SendText(text string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		return fmt.Errorf("speech generator cancelled")
+	} else if g.textComplete {
+		return fmt.Errorf("speech generator text already completed")
+	}
+
+	g.marked += text
+	sentences, rest := splitSentences(g.pending + text)
+	g.pending = rest
+	for _, sentence := range sentences {
+		g.enqueue(speechChunk{Type: speechChunkTypeText, Text: sentence})
+	}
+	return nil
+}
+
+func (g *speechGenerator) Mark() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		return fmt.Errorf("speech generator cancelled")
+	} else if g.textComplete {
+		return fmt.Errorf("speech generator text already completed")
+	}
+
+	g.flushPending()
+	if g.marked != "" {
+		g.enqueue(speechChunk{Type: speechChunkTypeMark, Text: g.marked})
+		g.marked = ""
+	}
+	return nil
+}
+
+func (g *speechGenerator) EndOfText() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		return fmt.Errorf("speech generator cancelled")
+	} else if g.textComplete {
+		return nil
+	}
+
+	g.textComplete = true
+	g.flushPending()
+	g.enqueue(speechChunk{Type: speechChunkTypeEnd})
+	return nil
+}
+
+func (g *speechGenerator) Cancel() error {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return fmt.Errorf("speech generator closed")
+	} else if g.cancelled {
+		g.mu.Unlock()
+		return nil
+	}
+	g.cancelled = true
+	g.mu.Unlock()
+
+	return g.Close()
+}
+
+func (g *speechGenerator) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return nil
+	}
+	g.closed = true
+	g.queue = nil
+	g.cancel()
+	return nil
+}
+
+// flushPending queues the text that did not end a sentence yet, for marks and
+// the end of text, which complete the sentence.
+//
+// Must be called with mu held.
+func (g *speechGenerator) flushPending() {
+	if strings.TrimSpace(g.pending) != "" {
+		g.enqueue(speechChunk{Type: speechChunkTypeText, Text: g.pending})
+	}
+	g.pending = ""
+}
+
+// enqueue must be called with mu held.
+func (g *speechGenerator) enqueue(chunk speechChunk) {
+	g.queue = append(g.queue, chunk)
+	select {
+	case g.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (g *speechGenerator) next() (speechChunk, bool) {
+	for {
+		g.mu.Lock()
+		if g.closed {
+			g.mu.Unlock()
+			return speechChunk{}, false
+		}
+		if len(g.queue) > 0 {
+			chunk := g.queue[0]
+			g.queue = g.queue[1:]
+			g.mu.Unlock()
+			return chunk, true
+		}
+		g.mu.Unlock()
+
+		select {
+		case <-g.wake:
+		case <-g.ctx.Done():
+			return speechChunk{}, false
+		}
+	}
+}
+
+// synthesizeQueued synthesizes the queued sentences in order, reporting marks
+// and the end of speech as the synthesis reaches them. The generator closes
+// once the loop stops, be it at the end of text, on an error or because ctx
+// is done.
+func (g *speechGenerator) synthesizeQueued(ctx context.Context) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			errorreport.ReportPanic(ctx, "openai speech synthesis loop", recovered, debug.Stack(), "provider", "openai")
+		}
+		_ = g.Close() // Ignored on purpose
+	}()
+
+	resampler := newResampler(g.options.EncodingInfo.SampleRate)
+	for {
+		chunk, ok := g.next()
+		if !ok {
+			return
+		}
+
+		switch chunk.Type {
+		case speechChunkTypeText:
+			if err := g.synthesize(ctx, chunk.Text, resampler); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				g.logger.Error("openai speech synthesis failed", "error", err)
+				g.options.ErrorCallback(err)
+				return
+			}
+		case speechChunkTypeMark:
+			if ctx.Err() != nil {
+				return
+			}
+			g.options.SpeechMarkCallback(chunk.Text)
+		case speechChunkTypeEnd:
+			if ctx.Err() != nil {
+				return
+			}
+			g.options.SpeechEndedCallbackV0(g.report)
+			return
+		}
+	}
+}
+
+func (g *speechGenerator) synthesize(ctx context.Context, text string, resampler *resampler) error {
+	ctx, span := tracer.Start(ctx, "synthesize openai speech", trace.WithAttributes(
+		attribute.String("tts.model", string(g.client.model)),
+		attribute.Int("tts.text_length", len(text)),
+	))
+	defer span.End()
+
+	err := func() error {
+		body, err := json.Marshal(speechRequest{
+			Model:          g.client.model,
+			Input:          strings.TrimSpace(text),
+			Voice:          g.client.voice,
+			Instructions:   g.client.instructions,
+			ResponseFormat: "pcm",
+		})
+		if err != nil {
+			return fmt.Errorf("error marshalling JSON: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.client.speechUrl, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("error creating HTTP request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+
+		resp, err := newHTTPClient().Do(req)
+		if err != nil {
+			return fmt.Errorf("error sending request: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			return fmt.Errorf("non-OK HTTP status: %s: %s", resp.Status, bytes.TrimSpace(message))
+		}
+
+		buffer := make([]byte, 4096)
+		for {
+			n, err := resp.Body.Read(buffer)
+			if n > 0 {
+				if converted := resampler.convert(buffer[:n]); len(converted) > 0 && ctx.Err() == nil {
+					g.options.SpeechAudioCallback(converted)
+				}
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("error reading audio: %w", err)
+			}
+		}
+	}()
+	if err != nil && ctx.Err() == nil {
+		errorreport.Record(ctx, span, err, "provider", "openai")
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+type speechRequest struct {
+	Model          SpeechModel `json:"model"`
+	Input          string      `json:"input"`
+	Voice          openaiVoice `json:"voice"`
+	Instructions   string      `json:"instructions,omitempty"`
+	ResponseFormat string      `json:"response_format"`
+}
+
+// splitSentences splits off the complete sentences of text, leaving the rest
+// until more text shows whether it ends a sentence. A sentence ends with a
+// line break or with terminal punctuation followed by whitespace, so that
+// numbers such as "3.5" are not split.
+func splitSentences(text string) (sentences []string, rest string) {
+	start := 0
+	for i, r := range text {
+		end := -1
+		switch r {
+		case '\n':
+			end = i + 1
+		case '.', '!', '?':
+			next, size := utf8.DecodeRuneInString(text[i+1:])
+			if size > 0 && unicode.IsSpace(next) {
+				end = i + 1 + size
+			}
+		}
+		if end <= start {
+			continue
+		}
+
+		if sentence := text[start:end]; strings.TrimSpace(sentence) != "" {
+			sentences = append(sentences, sentence)
+		} else if len(sentences) > 0 {
+			sentences[len(sentences)-1] += sentence
+		}
+		start = end
+	}
+	return sentences, text[start:]
+}
@@ -0,0 +1,166 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/credentials"
+	"github.com/koscakluka/ema-core/core/logging"
+	"github.com/koscakluka/ema-core/core/texttospeech"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *TextToSpeechClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewTextToSpeechClient(context.Background(), VoiceAlloy,
+		WithCredentials(credentials.Static{envVarApiKeyName: "test"}),
+		WithLogger(logging.Discard()))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.speechUrl = server.URL
+	return client
+}
+
+func TestSpeechGeneratorSynthesizesSentencesBeforeTheirMarks(t *testing.T) {
+	mu := sync.Mutex{}
+	got := []string{}
+	record := func(entry string) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, entry)
+	}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req speechRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.ResponseFormat != "pcm" {
+			t.Errorf("expected pcm response format, got %q", req.ResponseFormat)
+		}
+		record("synthesize: " + req.Input)
+		_, _ = w.Write([]byte{0, 0, 0, 0, 0, 0})
+	})
+
+	ended := make(chan struct{})
+	generator, err := client.NewSpeechGeneratorV0(context.Background(),
+		texttospeech.WithEncodingInfo(audio.EncodingInfo{SampleRate: 24000, Format: audio.EncodingLinear16}),
+		texttospeech.WithSpeechAudioCallback(func([]byte) { record("audio") }),
+		texttospeech.WithSpeechMarkCallback(func(text string) { record("mark: " + text) }),
+		texttospeech.WithSpeechEndedCallbackV0(func(texttospeech.SpeechEndedReport) { close(ended) }),
+	)
+	if err != nil {
+		t.Fatalf("failed to create speech generator: %v", err)
+	}
+
+	for _, text := range []string{"Hello there. It is ", "3.5 degrees", " out!"} {
+		if err := generator.SendText(text); err != nil {
+			t.Fatalf("failed to send text: %v", err)
+		}
+	}
+	if err := generator.Mark(); err != nil {
+		t.Fatalf("failed to mark: %v", err)
+	}
+	if err := generator.EndOfText(); err != nil {
+		t.Fatalf("failed to end text: %v", err)
+	}
+
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("expected speech to end")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"synthesize: Hello there.",
+		"audio",
+		"synthesize: It is 3.5 degrees out!",
+		"audio",
+		"mark: Hello there. It is 3.5 degrees out!",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSpeechGeneratorReportsFailedSynthesis(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid voice", http.StatusBadRequest)
+	})
+
+	failed := make(chan error, 1)
+	generator, err := client.NewSpeechGeneratorV0(context.Background(),
+		texttospeech.WithEncodingInfo(audio.EncodingInfo{SampleRate: 16000, Format: audio.EncodingLinear16}),
+		texttospeech.WithErrorCallback(func(err error) { failed <- err }),
+	)
+	if err != nil {
+		t.Fatalf("failed to create speech generator: %v", err)
+	}
+	if err := generator.SendText("Hello."); err != nil {
+		t.Fatalf("failed to send text: %v", err)
+	}
+	if err := generator.Mark(); err != nil {
+		t.Fatalf("failed to mark: %v", err)
+	}
+
+	select {
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("expected the synthesis error to be reported")
+	}
+	if err := generator.SendText("Anyone?"); err == nil {
+		t.Fatal("expected the generator to close after a failed synthesis")
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text      string
+		sentences []string
+		rest      string
+	}{
+		{text: "Hello", rest: "Hello"},
+		{text: "Hello.", rest: "Hello."},
+		{text: "Hello. How", sentences: []string{"Hello. "}, rest: "How"},
+		{text: "It is 3.5 degrees. Nice!\nRight?", sentences: []string{"It is 3.5 degrees. ", "Nice!\n"}, rest: "Right?"},
+		{text: "Wait...  what? ", sentences: []string{"Wait... ", " what? "}},
+	}
+
+	for _, test := range tests {
+		sentences, rest := splitSentences(test.text)
+		if !reflect.DeepEqual(sentences, test.sentences) || rest != test.rest {
+			t.Errorf("splitSentences(%q) = %q, %q, expected %q, %q", test.text, sentences, rest, test.sentences, test.rest)
+		}
+	}
+}
+
+func TestResamplerConvertsAcrossChunks(t *testing.T) {
+	r := newResampler(8000)
+	pcm := []byte{}
+	for i := range 12 {
+		pcm = append(pcm, byte(i*10), 0)
+	}
+
+	out := []byte{}
+	for _, chunk := range [][]byte{pcm[:3], pcm[3:10], pcm[10:]} {
+		out = append(out, r.convert(chunk)...)
+	}
+
+	want := []byte{0, 0, 30, 0, 60, 0, 90, 0}
+	if !reflect.DeepEqual(out, want) {
+		t.Fatalf("expected %v, got %v", want, out)
+	}
+}
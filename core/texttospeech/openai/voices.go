@@ -0,0 +1,47 @@
+package openai
+
+type openaiVoice string
+
+const (
+	VoiceAlloy   openaiVoice = "alloy"
+	VoiceAsh     openaiVoice = "ash"
+	VoiceBallad  openaiVoice = "ballad"
+	VoiceCoral   openaiVoice = "coral"
+	VoiceEcho    openaiVoice = "echo"
+	VoiceFable   openaiVoice = "fable"
+	VoiceNova    openaiVoice = "nova"
+	VoiceOnyx    openaiVoice = "onyx"
+	VoiceSage    openaiVoice = "sage"
+	VoiceShimmer openaiVoice = "shimmer"
+	VoiceVerse   openaiVoice = "verse"
+)
+
+func GetAvailableVoices() []openaiVoice {
+	return []openaiVoice{
+		VoiceAlloy,
+		VoiceAsh,
+		VoiceBallad,
+		VoiceCoral,
+		VoiceEcho,
+		VoiceFable,
+		VoiceNova,
+		VoiceOnyx,
+		VoiceSage,
+		VoiceShimmer,
+		VoiceVerse,
+	}
+}
+
+type SpeechModel string
+
+const (
+	ModelGPT4oMiniTTS SpeechModel = "gpt-4o-mini-tts"
+	ModelTTS1         SpeechModel = "tts-1"
+	ModelTTS1HD       SpeechModel = "tts-1-hd"
+)
+
+const defaultModel = ModelGPT4oMiniTTS
+
+func GetAvailableModels() []SpeechModel {
+	return []SpeechModel{ModelGPT4oMiniTTS, ModelTTS1, ModelTTS1HD}
+}
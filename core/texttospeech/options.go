@@ -1,6 +1,10 @@
 package texttospeech
 
-import "github.com/koscakluka/ema-core/core/audio"
+import (
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+)
 
 type TextToSpeechOptions struct {
 	// AudioCallback is called when the TTS client produces audio
@@ -22,6 +26,9 @@ type TextToSpeechOptions struct {
 	// ErrorCallback is called when the TTS client encounters an error, this usually
 	// means the TTS client has been cancelled
 	ErrorCallback func(error)
+	// SpeechTimestampsCallback is called with the timing of the words in the
+	// produced speech
+	SpeechTimestampsCallback func([]WordTimestamp)
 
 	EncodingInfo audio.EncodingInfo
 }
@@ -70,6 +77,23 @@ func WithSpeechEndedCallbackV0(callback func(SpeechEndedReport)) TextToSpeechOpt
 	return func(o *TextToSpeechOptions) { o.SpeechEndedCallbackV0 = callback }
 }
 
+// WithSpeechTimestampsCallback sets the callback for the timing of the words
+// in the produced speech, called as the timing becomes known, usually ahead
+// of the audio of the words
+//
+// Not supported by all TTS clients
+func WithSpeechTimestampsCallback(callback func([]WordTimestamp)) TextToSpeechOption {
+	return func(o *TextToSpeechOptions) { o.SpeechTimestampsCallback = callback }
+}
+
+// WordTimestamp is the timing of a word in produced speech. Start and End are
+// offsets from the start of the audio produced by the [SpeechGeneratorV0].
+type WordTimestamp struct {
+	Word  string
+	Start time.Duration
+	End   time.Duration
+}
+
 func WithErrorCallback(callback func(error)) TextToSpeechOption {
 	return func(o *TextToSpeechOptions) { o.ErrorCallback = callback }
 }
@@ -0,0 +1,85 @@
+package orchestration
+
+import (
+	"sync"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// TokenUsageV0 is the running token usage of a conversation, e.g. to enforce
+// a budget or end a call before its prompts outgrow the context window of
+// the model.
+type TokenUsageV0 struct {
+	// InputTokens, OutputTokens and TotalTokens add up the usage streaming
+	// LLMs reported for the calls of the conversation.
+	InputTokens  int
+	OutputTokens int
+	TotalTokens  int
+	// HistoryTokens is the number of tokens the history takes in the prompt
+	// of the next turn, see [WithTokenCounter].
+	HistoryTokens int
+}
+
+// WithTokenCounter counts the tokens of the history reported in
+// [ConversationV1.TokenUsage] with counter. It defaults to the LLM client if
+// it is a [llms.TokenCounter], like the clients of the openai, groq and
+// anthropic packages, and an [llms.ApproximateTokenCounter] otherwise.
+func WithTokenCounter(counter llms.TokenCounter) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.tokenCounter = counter
+	}
+}
+
+// usageMeter adds up the usage of the LLM calls of a conversation.
+type usageMeter struct {
+	mu    sync.Mutex
+	usage TokenUsageV0
+}
+
+func (m *usageMeter) record(usage llms.Usage) {
+	if m == nil {
+		return
+	}
+
+	total := usage.TotalTokens
+	if total == 0 {
+		total = usage.InputTokens + usage.OutputTokens
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.InputTokens += usage.InputTokens
+	m.usage.OutputTokens += usage.OutputTokens
+	m.usage.TotalTokens += total
+}
+
+func (m *usageMeter) total() TokenUsageV0 {
+	if m == nil {
+		return TokenUsageV0{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// recordUsage adds the usage of an LLM call to the conversation usage and the
+// budget.
+func (runtime *llm) recordUsage(usage llms.Usage) {
+	runtime.usage.record(usage)
+	runtime.budget.recordUsage(usage)
+}
+
+// tokenUsage returns the token usage of the conversation with history.
+func (o *Orchestrator) tokenUsage(history []llms.TurnV1) TokenUsageV0 {
+	usage := o.llm.usage.total()
+	counter := o.tokenCounter
+	if counter == nil {
+		if client, ok := o.llm.client.(llms.TokenCounter); ok {
+			counter = client
+		} else {
+			counter = llms.ApproximateTokenCounter{}
+		}
+	}
+	usage.HistoryTokens = llms.CountTurnTokens(counter, history...)
+	return usage
+}
@@ -0,0 +1,73 @@
+package orchestration
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// usageStreamLLMStub responds with content followed by its usage.
+type usageStreamLLMStub struct {
+	content string
+	usage   llms.Usage
+}
+
+func (stub usageStreamLLMStub) PromptWithStream(context.Context, *string, ...llms.StreamingPromptOption) llms.Stream {
+	return stub
+}
+
+func (stub usageStreamLLMStub) Chunks(context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		if yield(streamContentChunkStub{content: stub.content}, nil) {
+			yield(streamUsageChunkStub{usage: stub.usage}, nil)
+		}
+	}
+}
+
+// CountTokens counts every character as a token.
+func (stub usageStreamLLMStub) CountTokens(text string) int { return len(text) }
+
+type streamUsageChunkStub struct {
+	usage llms.Usage
+}
+
+func (chunk streamUsageChunkStub) FinishReason() *string { return nil }
+func (chunk streamUsageChunkStub) Usage() llms.Usage     { return chunk.usage }
+
+func TestConversationSnapshotReportsTokenUsage(t *testing.T) {
+	llm := usageStreamLLMStub{content: "hi there", usage: llms.Usage{InputTokens: 30, OutputTokens: 2}}
+	var completed atomic.Int32
+	o := NewOrchestrator(WithStreamingLLM(llm))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Add(1)
+		}
+	}))
+
+	o.SendPrompt("hello")
+	waitForCondition(t, 2*time.Second, "first turn completed", func() bool { return completed.Load() == 1 })
+	o.SendPrompt("bye")
+	waitForCondition(t, 2*time.Second, "second turn completed", func() bool { return completed.Load() == 2 })
+
+	usage := o.ConversationV1().TokenUsage
+	if usage.InputTokens != 60 || usage.OutputTokens != 4 || usage.TotalTokens != 64 {
+		t.Fatalf("expected the usage of both turns to add up, got %+v", usage)
+	}
+	// Two turns of a prompt and the response "hi there", counted by the LLM.
+	if want := 4*llms.MessageTokenOverhead + len("hello") + len("bye") + 2*len("hi there"); usage.HistoryTokens != want {
+		t.Fatalf("expected %d history tokens counted by the LLM, got %d", want, usage.HistoryTokens)
+	}
+
+	counted := NewOrchestrator(WithStreamingLLM(llm), WithTokenCounter(llms.ApproximateTokenCounter{CharsPerToken: 1000}), WithInitialHistory(o.ConversationV1().History...))
+	t.Cleanup(counted.Close)
+	if tokens := counted.ConversationV1().TokenUsage.HistoryTokens; tokens != 4*llms.MessageTokenOverhead+4 {
+		t.Fatalf("expected the configured counter to count the history, got %d", tokens)
+	}
+}
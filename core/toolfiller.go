@@ -0,0 +1,155 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/clock"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ToolFillerV0 returns the holding phrase spoken while the tool toolName is
+// called with arguments, e.g. "Let me check the weather in Paris for you."
+type ToolFillerV0 func(ctx context.Context, toolName, arguments string) (string, error)
+
+// NewLLMToolFiller creates a [ToolFillerV0] prompting llm for a phrase
+// fitting the tool call, preferably a small and fast model.
+func NewLLMToolFiller(llm LLMWithGeneralPrompt) ToolFillerV0 {
+	return func(ctx context.Context, toolName, arguments string) (string, error) {
+		response, err := llm.Prompt(ctx, fmt.Sprintf("Tool: %s\nArguments: %s", toolName, arguments), llms.WithSystemPrompt(
+			"A voice assistant is calling the tool below and it takes a while. "+
+				"Write one short, natural sentence the assistant says meanwhile so the caller knows it is working on it, "+
+				"e.g. \"Let me look that up for you.\" Do not mention tools or arguments by name. Reply with the sentence only.",
+		))
+		if err != nil {
+			return "", err
+		} else if response == nil {
+			return "", fmt.Errorf("no phrase returned")
+		}
+		return strings.TrimSpace(response.Content), nil
+	}
+}
+
+type ToolFillerOptions struct {
+	threshold time.Duration
+}
+
+type ToolFillerOption func(*ToolFillerOptions)
+
+// WithToolFillerThreshold sets how long a tool call runs before the holding
+// phrase is spoken, 2s by default.
+func WithToolFillerThreshold(d time.Duration) ToolFillerOption {
+	return func(o *ToolFillerOptions) {
+		o.threshold = d
+	}
+}
+
+// WithToolCallFiller speaks a holding phrase from filler when a tool call of
+// a turn runs longer than the threshold, so callers do not think the call
+// dropped. Phrases are cached per tool, filler is called once per tool even
+// if its arguments change.
+//
+// The phrase is spoken as part of the response, but is not kept in the
+// history. Each spoken phrase is reported with a [events.ToolCallProgress]
+// event.
+func WithToolCallFiller(filler ToolFillerV0, opts ...ToolFillerOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		options := ToolFillerOptions{threshold: 2 * time.Second}
+		for _, opt := range opts {
+			opt(&options)
+		}
+		o.llm.toolFiller = &toolFiller{
+			ToolFillerOptions: options,
+			generate:          filler,
+			clock:             clock.Real(),
+			phrases:           map[string]string{},
+		}
+	}
+}
+
+type toolFiller struct {
+	ToolFillerOptions
+	generate ToolFillerV0
+	clock    clock.Clock
+
+	mu      sync.Mutex
+	phrases map[string]string
+}
+
+// watch speaks the holding phrase of the tool call with speak once it runs
+// longer than the threshold. The returned stop ends watching and waits until
+// a phrase being spoken is passed on.
+func (f *toolFiller) watch(ctx context.Context, id, name, arguments string, speak func(string), emitEvent eventEmitter) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	phrase := make(chan string, 1)
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		defer close(phrase)
+		if p, ok := f.phrase(ctx, name, arguments); ok {
+			phrase <- p
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		select {
+		case <-f.clock.After(f.threshold):
+		case <-done:
+			return
+		}
+
+		select {
+		case p, ok := <-phrase:
+			if !ok {
+				return
+			}
+			select {
+			case <-done:
+				return
+			default:
+			}
+			speak(" " + p + " ")
+			emitEvent(events.NewToolCallProgress(id, name, p))
+		case <-done:
+		}
+	}()
+
+	return func() {
+		close(done)
+		cancel()
+		wg.Wait()
+	}
+}
+
+func (f *toolFiller) phrase(ctx context.Context, name, arguments string) (string, bool) {
+	f.mu.Lock()
+	phrase, ok := f.phrases[name]
+	f.mu.Unlock()
+	if ok {
+		return phrase, true
+	}
+
+	phrase, err := f.generate(ctx, name, arguments)
+	if err != nil {
+		if ctx.Err() == nil {
+			errorreport.Record(ctx, trace.SpanFromContext(ctx), fmt.Errorf("failed to generate holding phrase for tool %q: %w", name, err), "component", "tool", "tool_name", name)
+		}
+		return "", false
+	} else if phrase == "" {
+		return "", false
+	}
+
+	f.mu.Lock()
+	f.phrases[name] = phrase
+	f.mu.Unlock()
+	return phrase, true
+}
@@ -0,0 +1,145 @@
+package orchestration
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestToolCallFillerSpeaksHoldingPhraseDuringSlowToolCalls(t *testing.T) {
+	release := make(chan struct{})
+	var generated atomic.Int32
+	llm := &toolCallingLLMStub{
+		toolCalls: []llms.ToolCall{{ID: "call_1", Name: "lookup", Arguments: "{}"}},
+		response:  "It is sunny.",
+	}
+	o := NewOrchestrator(
+		WithStreamingLLM(llm),
+		WithTools(llms.NewTool("lookup", "looks up the weather", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+			select {
+			case <-release:
+			case <-time.After(time.Second):
+			}
+			return "sunny", nil
+		})),
+		WithToolCallFiller(func(_ context.Context, toolName, _ string) (string, error) {
+			generated.Add(1)
+			return "Let me check that for you.", nil
+		}, WithToolFillerThreshold(10*time.Millisecond)),
+		WithTextToSpeechClientV1(&bridgeTTSV1Stub{}),
+		WithAudioOutputV1(&bridgeAudioOutputStub{}),
+	)
+	t.Cleanup(o.Close)
+
+	// The speech stub passes the text it is sent on as audio.
+	var spoken lockedBuffer
+	var mu sync.Mutex
+	var progress []events.ToolCallProgress
+	var completed atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx,
+		WithAudioCallback(func(audio []byte) { spoken.Write(audio) }),
+		WithEventCallback(func(event events.Event) {
+			switch event := event.(type) {
+			case events.ToolCallProgress:
+				mu.Lock()
+				defer mu.Unlock()
+				progress = append(progress, event)
+				close(release)
+			case events.TurnCompleted:
+				completed.Store(true)
+			}
+		}),
+	)
+
+	o.HandleTrigger(triggers.NewUserPromptTrigger("what is the weather?"))
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	if !strings.Contains(spoken.String(), "Let me check that for you.") || !strings.Contains(spoken.String(), "It is sunny.") {
+		t.Fatalf("expected the holding phrase and the response to be spoken, got %q", spoken.String())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progress) != 1 || progress[0].ID != "call_1" || progress[0].Message != "Let me check that for you." {
+		t.Fatalf("unexpected progress events %+v", progress)
+	}
+	if generated.Load() != 1 {
+		t.Fatalf("expected the phrase to be generated once, got %d", generated.Load())
+	}
+	history := o.conversation.History()
+	if len(history) != 1 || len(history[0].Responses) != 1 || history[0].Responses[0].Message != "It is sunny." {
+		t.Fatalf("expected the holding phrase to be left out of the history, got %+v", history)
+	}
+}
+
+// toolCallingLLMStub calls toolCalls and answers with response, or "done",
+// once their results are sent back, which it records.
+type toolCallingLLMStub struct {
+	toolCalls []llms.ToolCall
+	response  string
+	prompts   atomic.Int32
+
+	mu       sync.Mutex
+	returned []llms.ToolCall
+}
+
+func (stub *toolCallingLLMStub) PromptWithStream(_ context.Context, _ *string, opts ...llms.StreamingPromptOption) llms.Stream {
+	options := llms.StreamingPromptOptions{}
+	for _, opt := range opts {
+		opt.ApplyToStreaming(&options)
+	}
+
+	if stub.prompts.Add(1) > 1 {
+		if turns := options.BaseOptions.TurnsV1; len(turns) > 0 {
+			stub.mu.Lock()
+			stub.returned = append(stub.returned, turns[len(turns)-1].ToolCalls...)
+			stub.mu.Unlock()
+		}
+		response := stub.response
+		if response == "" {
+			response = "done"
+		}
+		return scriptedStreamStub{chunks: []string{response}}
+	}
+	return toolCallStreamStub{toolCalls: stub.toolCalls}
+}
+
+func (stub *toolCallingLLMStub) returnedToolCalls() []llms.ToolCall {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	return append([]llms.ToolCall(nil), stub.returned...)
+}
+
+type toolCallStreamStub struct {
+	toolCalls []llms.ToolCall
+}
+
+func (stub toolCallStreamStub) Chunks(context.Context) func(func(llms.StreamChunk, error) bool) {
+	return func(yield func(llms.StreamChunk, error) bool) {
+		for _, toolCall := range stub.toolCalls {
+			if !yield(toolCallChunkStub{toolCall: toolCall}, nil) {
+				return
+			}
+		}
+	}
+}
+
+type toolCallChunkStub struct {
+	toolCall llms.ToolCall
+}
+
+func (chunk toolCallChunkStub) FinishReason() *string {
+	return nil
+}
+
+func (chunk toolCallChunkStub) ToolCall() llms.ToolCall {
+	return chunk.toolCall
+}
@@ -0,0 +1,198 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+const (
+	defaultToolPoolWorkers   = 8
+	defaultToolPoolQueueSize = 64
+)
+
+// ErrToolPoolClosed is returned for tool calls submitted to a closed
+// [ToolPool].
+var ErrToolPoolClosed = errors.New("tool pool closed")
+
+// ToolPool executes tool calls on a fixed number of workers.
+//
+// A single pool is meant to be shared by all orchestrators of a process (see
+// [WithToolPool]), so slow tools can only ever occupy the pool's workers
+// instead of a goroutine per call. Calls wait in a bounded queue for a free
+// worker, and tools can be limited to a number of concurrent executions so
+// one tool cannot take over all workers.
+type ToolPool struct {
+	queue  chan toolJob
+	limits map[string]chan struct{}
+
+	closeMu   sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+	done      chan struct{}
+	workers   sync.WaitGroup
+}
+
+type ToolPoolOptions struct {
+	workers   int
+	queueSize int
+	limits    map[string]int
+}
+
+type ToolPoolOption func(*ToolPoolOptions)
+
+// WithToolWorkers sets how many tool calls are executed at once, defaults
+// to 8.
+func WithToolWorkers(workers int) ToolPoolOption {
+	return func(o *ToolPoolOptions) {
+		o.workers = workers
+	}
+}
+
+// WithToolQueueSize sets how many tool calls can wait for a worker, defaults
+// to 64. Calls beyond that wait to be queued until their context is done.
+func WithToolQueueSize(size int) ToolPoolOption {
+	return func(o *ToolPoolOptions) {
+		o.queueSize = size
+	}
+}
+
+// WithToolConcurrency limits how many calls of the tool name run at once
+// across the whole pool.
+func WithToolConcurrency(name string, limit int) ToolPoolOption {
+	return func(o *ToolPoolOptions) {
+		if o.limits == nil {
+			o.limits = map[string]int{}
+		}
+		o.limits[name] = limit
+	}
+}
+
+type toolJob struct {
+	execute func() (string, error)
+	release func()
+	result  chan toolResult
+}
+
+type toolResult struct {
+	response  string
+	err       error
+	startedAt time.Time
+}
+
+// NewToolPool creates a pool and starts its workers.
+func NewToolPool(opts ...ToolPoolOption) *ToolPool {
+	options := ToolPoolOptions{
+		workers:   defaultToolPoolWorkers,
+		queueSize: defaultToolPoolQueueSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.workers = max(options.workers, 1)
+	options.queueSize = max(options.queueSize, 0)
+
+	p := &ToolPool{
+		queue:  make(chan toolJob, options.queueSize),
+		limits: map[string]chan struct{}{},
+		done:   make(chan struct{}),
+	}
+	for name, limit := range options.limits {
+		p.limits[name] = make(chan struct{}, max(limit, 1))
+	}
+
+	p.workers.Add(options.workers)
+	for range options.workers {
+		go p.work()
+	}
+
+	return p
+}
+
+// Close stops accepting tool calls and waits until the queued ones are
+// executed or ctx is done.
+func (p *ToolPool) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		p.closeMu.Lock()
+		p.closed = true
+		close(p.queue)
+		p.closeMu.Unlock()
+
+		go func() {
+			p.workers.Wait()
+			close(p.done)
+		}()
+	})
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// execute runs tool with arguments on the pool and waits for its response,
+// it also returns how long the call waited before it started executing.
+//
+// The wait for a concurrency slot and a queue slot is bounded by ctx. Once a
+// call is queued it runs to completion even if ctx is done, since tools can't
+// be interrupted, but its result is dropped.
+func (p *ToolPool) execute(ctx context.Context, tool llms.Tool, arguments string) (string, time.Duration, error) {
+	queuedAt := time.Now()
+
+	release := func() {}
+	if limit, ok := p.limits[tool.Function.Name]; ok {
+		select {
+		case limit <- struct{}{}:
+			release = func() { <-limit }
+		case <-ctx.Done():
+			return "", time.Since(queuedAt), ctx.Err()
+		}
+	}
+
+	job := toolJob{
+		execute: func() (string, error) { return tool.Execute(arguments) },
+		release: release,
+		result:  make(chan toolResult, 1),
+	}
+	if err := p.enqueue(ctx, job); err != nil {
+		release()
+		return "", time.Since(queuedAt), err
+	}
+
+	select {
+	case result := <-job.result:
+		return result.response, result.startedAt.Sub(queuedAt), result.err
+	case <-ctx.Done():
+		return "", time.Since(queuedAt), ctx.Err()
+	}
+}
+
+func (p *ToolPool) enqueue(ctx context.Context, job toolJob) error {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.closed {
+		return ErrToolPoolClosed
+	}
+
+	select {
+	case p.queue <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *ToolPool) work() {
+	defer p.workers.Done()
+	for job := range p.queue {
+		startedAt := time.Now()
+		response, err := job.execute()
+		job.release()
+		job.result <- toolResult{response: response, err: err, startedAt: startedAt}
+	}
+}
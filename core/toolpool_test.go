@@ -0,0 +1,146 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// blockingTool counts concurrent executions and blocks until released.
+type blockingTool struct {
+	running atomic.Int32
+	peak    atomic.Int32
+	release chan struct{}
+}
+
+func (b *blockingTool) tool(name string) llms.Tool {
+	return llms.NewTool(name, "blocking test tool", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+		running := b.running.Add(1)
+		for {
+			peak := b.peak.Load()
+			if running <= peak || b.peak.CompareAndSwap(peak, running) {
+				break
+			}
+		}
+		<-b.release
+		b.running.Add(-1)
+		return name, nil
+	})
+}
+
+func TestToolPoolBoundsConcurrentExecutions(t *testing.T) {
+	pool := NewToolPool(WithToolWorkers(2))
+	defer pool.Close(context.Background())
+
+	blocking := &blockingTool{release: make(chan struct{})}
+	tool := blocking.tool("slow")
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp, _, err := pool.execute(context.Background(), tool, "{}"); err != nil || resp != "slow" {
+				t.Errorf("expected tool response, got %q, %v", resp, err)
+			}
+		}()
+	}
+
+	waitForCondition(t, time.Second, "two tool calls running", func() bool { return blocking.running.Load() == 2 })
+	close(blocking.release)
+	wg.Wait()
+
+	if peak := blocking.peak.Load(); peak != 2 {
+		t.Fatalf("expected at most 2 concurrent tool calls, got %d", peak)
+	}
+}
+
+func TestToolPoolLimitsPerToolConcurrency(t *testing.T) {
+	pool := NewToolPool(WithToolWorkers(4), WithToolConcurrency("slow", 1))
+	defer pool.Close(context.Background())
+
+	slow := &blockingTool{release: make(chan struct{})}
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _ = pool.execute(context.Background(), slow.tool("slow"), "{}")
+		}()
+	}
+	waitForCondition(t, time.Second, "slow tool running", func() bool { return slow.running.Load() == 1 })
+
+	if resp, _, err := pool.execute(context.Background(), testTool("fast"), "{}"); err != nil || resp != "ok" {
+		t.Fatalf("expected other tools to run while the slow tool is limited, got %q, %v", resp, err)
+	}
+
+	close(slow.release)
+	wg.Wait()
+	if peak := slow.peak.Load(); peak != 1 {
+		t.Fatalf("expected slow tool to run at most once at a time, got %d", peak)
+	}
+}
+
+func TestToolPoolStopsWaitingWhenContextIsDone(t *testing.T) {
+	pool := NewToolPool(WithToolWorkers(1), WithToolQueueSize(0))
+	blocking := &blockingTool{release: make(chan struct{})}
+	defer func() {
+		close(blocking.release)
+		pool.Close(context.Background())
+	}()
+
+	go func() { _, _, _ = pool.execute(context.Background(), blocking.tool("slow"), "{}") }()
+	waitForCondition(t, time.Second, "worker busy", func() bool { return blocking.running.Load() == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := pool.execute(ctx, testTool("fast"), "{}"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected queued call to give up with the context, got %v", err)
+	}
+}
+
+func TestToolPoolRejectsCallsAfterClose(t *testing.T) {
+	pool := NewToolPool()
+	if err := pool.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	if _, _, err := pool.execute(context.Background(), testTool("fast"), "{}"); !errors.Is(err, ErrToolPoolClosed) {
+		t.Fatalf("expected closed pool error, got %v", err)
+	}
+}
+
+func TestWithToolPoolExecutesToolCallsOnPool(t *testing.T) {
+	pool := NewToolPool(WithToolWorkers(1))
+	defer pool.Close(context.Background())
+
+	occupying := &blockingTool{release: make(chan struct{})}
+	go func() { _, _, _ = pool.execute(context.Background(), occupying.tool("occupying"), "{}") }()
+	waitForCondition(t, time.Second, "pool worker busy", func() bool { return occupying.running.Load() == 1 })
+
+	pooled := &blockingTool{release: make(chan struct{})}
+	close(pooled.release)
+	o := NewOrchestrator(WithToolPool(pool), WithTools(pooled.tool("pooled")))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := o.callTool(context.Background(), llms.ToolCall{ID: "call-1", Name: "pooled", Arguments: "{}"})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected tool call to wait for the busy pool, returned %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(occupying.release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected tool error: %v", err)
+	}
+}
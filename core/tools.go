@@ -2,12 +2,17 @@ package orchestration
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
+	"time"
 
+	"github.com/koscakluka/ema-core/core/errorreport"
 	events "github.com/koscakluka/ema-core/core/events"
 	"github.com/koscakluka/ema-core/core/llms"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func orchestrationTools(o *Orchestrator) []llms.Tool {
@@ -51,31 +56,145 @@ func (runtime *llm) callTool(ctx context.Context, toolCall llms.ToolCall) (*llms
 	}
 
 	runtime.emitEvent(events.NewToolCallStarted(toolCall.ID, toolName, toolArguments))
-
 	ctx, span := tracer.Start(ctx, "execute tool")
 	defer span.End()
 	span.SetAttributes(attribute.String("tool.name", toolName))
-	for _, tool := range runtime.tools {
-		if tool.Function.Name == toolName {
-			resp, err := tool.Execute(toolArguments)
-			if err != nil {
-				err = fmt.Errorf("failed to execute tool %q: %w", toolName, err)
-				runtime.emitEvent(events.NewToolCallFailed(toolCall.ID, toolName, err.Error()))
-				span.RecordError(err)
-				span.SetStatus(codes.Error, err.Error())
-				return nil, err
-			}
-			runtime.emitEvent(events.NewToolCallCompleted(toolCall.ID, toolName, resp))
-			return &llms.ToolCall{
-				ID:       toolCall.ID,
-				Response: resp,
-			}, nil
+
+	tool, found := runtime.findTool(toolName)
+	if !found {
+		err := fmt.Errorf("tool not found: %s", toolName)
+		runtime.emitEvent(events.NewToolCallFailed(toolCall.ID, toolName, err.Error()))
+		errorreport.Record(ctx, span, err, "component", "tool", "tool_name", toolName)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	if err := runtime.awaitApproval(ctx, tool, llms.ToolCall{ID: toolCall.ID, Name: toolName, Arguments: toolArguments}); errors.Is(err, ErrToolCallRejected) {
+		runtime.emitEvent(events.NewToolCallFailed(toolCall.ID, toolName, err.Error()))
+		return &llms.ToolCall{ID: toolCall.ID, Response: "The call was rejected by the user and not executed."}, nil
+	} else if err != nil {
+		return runtime.failToolCall(ctx, span, tool, toolCall.ID, err)
+	}
+
+	if runtime.reportProgress != nil {
+		runtime.reportProgress("calling tool " + toolName)
+		defer runtime.reportProgress("generating")
+	}
+	if runtime.toolFiller != nil && runtime.speakFiller != nil {
+		stop := runtime.toolFiller.watch(ctx, toolCall.ID, toolName, toolArguments, runtime.speakFiller, runtime.emitEvent)
+		defer stop()
+	}
+
+	var finished atomic.Bool
+	progress := func(status string) {
+		// Statuses reported after the tool returned are dropped.
+		if !finished.Load() {
+			runtime.emitEvent(events.NewToolCallStatus(toolCall.ID, toolName, status))
 		}
 	}
+	resp, err := runtime.executeWithRetries(ctx, tool, toolArguments, progress)
+	finished.Store(true)
+	if err != nil {
+		return runtime.failToolCall(ctx, span, tool, toolCall.ID, fmt.Errorf("failed to execute tool %q: %w", toolName, err))
+	}
+	runtime.emitEvent(events.NewToolCallCompleted(toolCall.ID, toolName, resp))
+	if runtime.toolSanitizer != nil {
+		resp = runtime.toolSanitizer.sanitize(ctx, toolCall.ID, toolName, resp, runtime.emitEvent)
+	}
+	return &llms.ToolCall{
+		ID:       toolCall.ID,
+		Response: resp,
+	}, nil
+}
 
-	err := fmt.Errorf("tool not found: %s", toolName)
-	runtime.emitEvent(events.NewToolCallFailed(toolCall.ID, toolName, err.Error()))
-	span.RecordError(err)
+func (runtime *llm) findTool(name string) (llms.Tool, bool) {
+	for _, tool := range runtime.availableTools() {
+		if tool.Function.Name == name {
+			return tool, true
+		}
+	}
+	return llms.Tool{}, false
+}
+
+// failToolCall reports the failed call of tool and applies the error policy
+// of the tool, see [llms.WithErrorPolicy].
+func (runtime *llm) failToolCall(ctx context.Context, span trace.Span, tool llms.Tool, id string, err error) (*llms.ToolCall, error) {
+	toolName := tool.Function.Name
+	runtime.emitEvent(events.NewToolCallFailed(id, toolName, err.Error()))
+	errorreport.Record(ctx, span, err, "component", "tool", "tool_name", toolName)
 	span.SetStatus(codes.Error, err.Error())
+	switch tool.ErrorPolicy {
+	case llms.ToolErrorReportToLLM:
+		return &llms.ToolCall{ID: id, Response: "Error: " + err.Error()}, nil
+	case llms.ToolErrorSkip:
+		return &llms.ToolCall{ID: id}, nil
+	}
 	return nil, err
 }
+
+// executeWithRetries runs tool with arguments within its timeout, retrying
+// failed executions of idempotent tools as configured, see
+// [llms.WithRetries].
+func (runtime *llm) executeWithRetries(ctx context.Context, tool llms.Tool, arguments string, progress llms.ProgressFunc) (string, error) {
+	backoff := tool.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := runtime.executeWithTimeout(ctx, tool, arguments, progress)
+		if err == nil || attempt > tool.Retries || ctx.Err() != nil {
+			return resp, err
+		}
+		if !tool.Idempotent {
+			runtime.logger.Warn("tool execution failed, not retrying a tool not marked idempotent", "tool_name", tool.Function.Name, "error", err)
+			return resp, err
+		}
+
+		runtime.logger.Warn("tool execution failed, retrying", "tool_name", tool.Function.Name, "attempt", attempt, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return resp, err
+		}
+		backoff *= 2
+	}
+}
+
+// executeWithTimeout runs tool with arguments, giving up once its timeout
+// passes, see [llms.WithTimeout].
+func (runtime *llm) executeWithTimeout(ctx context.Context, tool llms.Tool, arguments string, progress llms.ProgressFunc) (string, error) {
+	if tool.Timeout <= 0 {
+		return runtime.executeTool(ctx, tool, arguments, progress)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tool.Timeout)
+	defer cancel()
+	result := make(chan toolResult, 1)
+	go func() {
+		response, err := runtime.executeTool(ctx, tool, arguments, progress)
+		result <- toolResult{response: response, err: err}
+	}()
+	select {
+	case result := <-result:
+		return result.response, result.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// executeTool runs tool with arguments, streaming tools report their status
+// with progress.
+func (runtime *llm) executeTool(ctx context.Context, tool llms.Tool, arguments string, progress llms.ProgressFunc) (string, error) {
+	if runtime.dryRun != nil {
+		return runtime.dryRun.mockResponse(tool.Function.Name), nil
+	}
+	if executeStreaming := tool.ExecuteStreaming; executeStreaming != nil {
+		tool.Execute = func(arguments string) (string, error) {
+			return executeStreaming(ctx, arguments, progress)
+		}
+	}
+	if runtime.toolPool == nil {
+		return tool.Execute(arguments)
+	}
+
+	resp, queueWait, err := runtime.toolPool.execute(ctx, tool, arguments)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Float64("tool.queue_wait", queueWait.Seconds()))
+	return resp, err
+}
@@ -0,0 +1,255 @@
+// Package mcp exposes the tools of Model Context Protocol servers as
+// [llms.Tool] values.
+//
+// A [Client] connects to a server over a [Transport], stdio for servers run
+// as a subprocess or SSE for remote servers, and discovers its tools. They
+// can be passed to orchestration.WithTools once, or the client can be passed
+// to orchestration.WithToolSource to follow changes of the tool list the
+// server announces mid-conversation.
+//
+//	client, err := mcp.NewClient(ctx, mcp.NewStdioTransport(exec.Command("mcp-server-time")))
+//	o := orchestration.NewOrchestrator(orchestration.WithToolSource(client))
+//
+// Tools are streaming tools, progress the server reports while a tool runs
+// is emitted as tool call progress events.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+const protocolVersion = "2024-11-05"
+
+// ErrClosed is the error of requests to a closed client.
+var ErrClosed = errors.New("mcp client closed")
+
+// Transport carries JSON-RPC messages between the client and a server, see
+// [NewStdioTransport] and [NewSSETransport].
+type Transport interface {
+	// Start connects to the server and passes every message it sends to
+	// handle until ctx is done or the transport is closed.
+	Start(ctx context.Context, handle func(message []byte)) error
+	// Send sends message to the server.
+	Send(ctx context.Context, message []byte) error
+	Close() error
+}
+
+type ClientOptions struct {
+	name           string
+	version        string
+	logger         logging.Logger
+	onToolsChanged func(tools []llms.Tool)
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithClientInfo sets the name and version the client introduces itself with
+// to the server, defaults to "ema".
+func WithClientInfo(name, version string) ClientOption {
+	return func(o *ClientOptions) {
+		o.name = name
+		o.version = version
+	}
+}
+
+// WithLogger sets the logger of the client, defaults to [logging.Default].
+func WithLogger(logger logging.Logger) ClientOption {
+	return func(o *ClientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithToolsChangedCallback calls onToolsChanged with the new tool list
+// whenever the server announced a change and the list was refreshed.
+func WithToolsChangedCallback(onToolsChanged func(tools []llms.Tool)) ClientOption {
+	return func(o *ClientOptions) {
+		o.onToolsChanged = onToolsChanged
+	}
+}
+
+// Client is a connection to an MCP server.
+type Client struct {
+	ClientOptions
+	transport Transport
+
+	// ctx lives until the client is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	nextID   atomic.Int64
+	mu       sync.Mutex
+	pending  map[int64]chan message
+	progress map[string]llms.ProgressFunc
+
+	toolsMu sync.RWMutex
+	tools   []llms.Tool
+}
+
+// NewClient connects to the server over transport, initializes the session
+// and lists the tools of the server. ctx bounds the setup only.
+func NewClient(ctx context.Context, transport Transport, opts ...ClientOption) (*Client, error) {
+	options := ClientOptions{name: "ema", version: "0.0.0", logger: logging.Default()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if transport == nil {
+		return nil, errors.New("mcp client requires a transport")
+	}
+
+	clientCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c := &Client{
+		ClientOptions: options,
+		transport:     transport,
+		ctx:           clientCtx,
+		cancel:        cancel,
+		pending:       map[int64]chan message{},
+		progress:      map[string]llms.ProgressFunc{},
+	}
+
+	// The setup is given up once ctx is done.
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+	if err := c.initialize(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) initialize(ctx context.Context) error {
+	if err := c.transport.Start(c.ctx, c.handle); err != nil {
+		return fmt.Errorf("failed to connect to mcp server: %w", err)
+	}
+
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := c.call(ctx, "initialize", map[string]any{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]string{"name": c.name, "version": c.version},
+	}, &result); err != nil {
+		return fmt.Errorf("failed to initialize mcp session: %w", err)
+	}
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
+		return fmt.Errorf("failed to initialize mcp session: %w", err)
+	}
+
+	if _, err := c.refresh(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Tools returns the tools of the server, as of the latest refresh. It makes
+// the client a tool source of the orchestrator.
+func (c *Client) Tools() []llms.Tool {
+	c.toolsMu.RLock()
+	defer c.toolsMu.RUnlock()
+	return append([]llms.Tool(nil), c.tools...)
+}
+
+// Refresh lists the tools of the server again. The client refreshes the
+// list on its own when the server announces a change.
+func (c *Client) Refresh(ctx context.Context) error {
+	tools, err := c.refresh(ctx)
+	if err != nil {
+		return err
+	}
+	if c.onToolsChanged != nil {
+		c.onToolsChanged(tools)
+	}
+	return nil
+}
+
+func (c *Client) refresh(ctx context.Context) ([]llms.Tool, error) {
+	var tools []llms.Tool
+	cursor := ""
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var result struct {
+			Tools      []toolDefinition `json:"tools"`
+			NextCursor string           `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &result); err != nil {
+			return nil, fmt.Errorf("failed to list mcp tools: %w", err)
+		}
+		for _, definition := range result.Tools {
+			tools = append(tools, c.newTool(definition))
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	c.toolsMu.Lock()
+	c.tools = tools
+	c.toolsMu.Unlock()
+	return append([]llms.Tool(nil), tools...), nil
+}
+
+// Close ends the session and closes the transport.
+func (c *Client) Close() error {
+	c.cancel()
+	return c.transport.Close()
+}
+
+// callTool calls the tool name of the server with arguments, passing on
+// the progress the server reports.
+func (c *Client) callTool(ctx context.Context, name string, arguments json.RawMessage, progress llms.ProgressFunc) (string, error) {
+	if len(arguments) == 0 || string(arguments) == "null" {
+		arguments = json.RawMessage("{}")
+	}
+	token := "ema-" + strconv.FormatInt(c.nextID.Add(1), 10)
+	c.mu.Lock()
+	c.progress[token] = progress
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.progress, token)
+		c.mu.Unlock()
+	}()
+
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := c.call(ctx, "tools/call", map[string]any{
+		"name":      name,
+		"arguments": arguments,
+		"_meta":     map[string]string{"progressToken": token},
+	}, &result); err != nil {
+		return "", err
+	}
+
+	parts := make([]string, 0, len(result.Content))
+	for _, content := range result.Content {
+		if content.Type == "text" {
+			parts = append(parts, content.Text)
+		} else {
+			parts = append(parts, "["+content.Type+" content]")
+		}
+	}
+	response := strings.Join(parts, "\n")
+	if result.IsError {
+		return "", fmt.Errorf("mcp tool %s failed: %s", name, response)
+	}
+	return response, nil
+}
@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+// fakeServer answers requests like an MCP server offering a "weather" tool,
+// and a "time" tool once changed.
+type fakeServer struct {
+	mu      sync.Mutex
+	changed bool
+	send    func(message []byte)
+}
+
+func (s *fakeServer) receive(data []byte) {
+	var request message
+	if err := json.Unmarshal(data, &request); err != nil || len(request.ID) == 0 {
+		return
+	}
+
+	var result any
+	switch request.Method {
+	case "initialize":
+		result = map[string]any{"protocolVersion": protocolVersion, "capabilities": map[string]any{"tools": map[string]any{"listChanged": true}}}
+	case "tools/list":
+		tools := []map[string]any{{
+			"name":        "weather",
+			"description": "Gets the weather",
+			"inputSchema": map[string]any{"type": "object", "properties": map[string]any{
+				"city": map[string]any{"type": []string{"null", "string"}, "description": "The city"},
+			}},
+		}}
+		s.mu.Lock()
+		if s.changed {
+			tools = append(tools, map[string]any{"name": "time", "description": "Gets the time", "inputSchema": map[string]any{"type": "object"}})
+		}
+		s.mu.Unlock()
+		result = map[string]any{"tools": tools}
+	case "tools/call":
+		var params struct {
+			Name      string            `json:"name"`
+			Arguments map[string]string `json:"arguments"`
+			Meta      struct {
+				ProgressToken string `json:"progressToken"`
+			} `json:"_meta"`
+		}
+		json.Unmarshal(request.Params, &params)
+		s.notify("notifications/progress", map[string]any{"progressToken": params.Meta.ProgressToken, "progress": 1, "total": 2})
+		if params.Arguments["city"] == "" {
+			result = map[string]any{"content": []map[string]string{{"type": "text", "text": "city is required"}}, "isError": true}
+			break
+		}
+		result = map[string]any{"content": []map[string]string{
+			{"type": "text", "text": "Sunny in " + params.Arguments["city"]},
+			{"type": "text", "text": "21 degrees"},
+		}}
+	default:
+		s.respond(message{JSONRPC: "2.0", ID: request.ID, Error: &rpcError{Code: errCodeMethodNotFound, Message: "not found"}})
+		return
+	}
+	encoded, _ := json.Marshal(result)
+	s.respond(message{JSONRPC: "2.0", ID: request.ID, Result: encoded})
+}
+
+func (s *fakeServer) changeTools() {
+	s.mu.Lock()
+	s.changed = true
+	s.mu.Unlock()
+	s.notify("notifications/tools/list_changed", nil)
+}
+
+func (s *fakeServer) notify(method string, params any) {
+	notification := message{JSONRPC: "2.0", Method: method}
+	if params != nil {
+		notification.Params, _ = json.Marshal(params)
+	}
+	s.respond(notification)
+}
+
+func (s *fakeServer) respond(response message) {
+	encoded, _ := json.Marshal(response)
+	s.send(encoded)
+}
+
+// fakeTransport connects the client to a fakeServer in memory.
+type fakeTransport struct {
+	server *fakeServer
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{server: &fakeServer{}}
+}
+
+func (t *fakeTransport) Start(ctx context.Context, handle func(message []byte)) error {
+	// Messages are handled in order, like a transport reading a stream.
+	messages := make(chan []byte, 16)
+	t.server.send = func(message []byte) { messages <- message }
+	go func() {
+		for {
+			select {
+			case message := <-messages:
+				handle(message)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (t *fakeTransport) Send(_ context.Context, message []byte) error {
+	go t.server.receive(message)
+	return nil
+}
+
+func (t *fakeTransport) Close() error { return nil }
+
+func TestClientDiscoversAndCallsTools(t *testing.T) {
+	client, err := NewClient(context.Background(), newFakeTransport(), WithLogger(logging.Discard()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	tools := client.Tools()
+	if len(tools) != 1 || tools[0].Function.Name != "weather" || tools[0].Function.Description != "Gets the weather" {
+		t.Fatalf("unexpected tools %+v", tools)
+	}
+	if city := tools[0].Function.Parameters["city"]; city.Type != "string" || city.Description != "The city" {
+		t.Fatalf("unexpected city parameter %+v", city)
+	}
+
+	var statuses []string
+	response, err := tools[0].ExecuteStreaming(context.Background(), `{"city":"Zagreb"}`, func(status string) {
+		statuses = append(statuses, status)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "Sunny in Zagreb\n21 degrees" {
+		t.Fatalf("unexpected response %q", response)
+	}
+	if len(statuses) != 1 || statuses[0] != "1 of 2" {
+		t.Fatalf("unexpected progress %v", statuses)
+	}
+
+	if _, err := tools[0].ExecuteStreaming(context.Background(), `{}`, func(string) {}); err == nil || !strings.Contains(err.Error(), "city is required") {
+		t.Fatalf("expected the tool error to be returned, got %v", err)
+	}
+}
+
+func TestClientRefreshesToolsWhenTheListChanges(t *testing.T) {
+	transport := newFakeTransport()
+	changed := make(chan []llms.Tool, 1)
+	client, err := NewClient(context.Background(), transport, WithLogger(logging.Discard()), WithToolsChangedCallback(func(tools []llms.Tool) {
+		changed <- tools
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	transport.server.changeTools()
+	select {
+	case tools := <-changed:
+		if len(tools) != 2 || tools[1].Function.Name != "time" {
+			t.Fatalf("unexpected refreshed tools %+v", tools)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("tools were not refreshed")
+	}
+	if len(client.Tools()) != 2 {
+		t.Fatalf("expected the client to offer the refreshed tools, got %d", len(client.Tools()))
+	}
+}
+
+func TestSSETransportExchangesMessages(t *testing.T) {
+	server := &fakeServer{}
+	events := make(chan []byte, 16)
+	server.send = func(message []byte) { events <- message }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sse", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /messages?session=1\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case event := <-events:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", event)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("POST /messages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("session") != "1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body json.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		server.receive(body)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	httpServer := httptest.NewServer(mux)
+	t.Cleanup(httpServer.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	client, err := NewClient(ctx, NewSSETransport(httpServer.URL+"/sse", WithHeader("Authorization", "Bearer token")), WithLogger(logging.Discard()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	tools := client.Tools()
+	if len(tools) != 1 {
+		t.Fatalf("unexpected tools %+v", tools)
+	}
+	response, err := tools[0].Execute(`{"city":"Split"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "Sunny in Split\n21 degrees" {
+		t.Fatalf("unexpected response %q", response)
+	}
+}
@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// message is a JSON-RPC 2.0 request, notification or response.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message)
+}
+
+const errCodeMethodNotFound = -32601
+
+// call sends a request and decodes the result of its response into result.
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	encodedParams, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s params: %w", method, err)
+	}
+	id := c.nextID.Add(1)
+	request, err := json.Marshal(message{JSONRPC: "2.0", ID: json.RawMessage(strconv.FormatInt(id, 10)), Method: method, Params: encodedParams})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	responses := make(chan message, 1)
+	c.mu.Lock()
+	c.pending[id] = responses
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.transport.Send(ctx, request); err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+	select {
+	case response := <-responses:
+		if response.Error != nil {
+			return response.Error
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.ctx.Done():
+		return ErrClosed
+	}
+}
+
+// notify sends a notification.
+func (c *Client) notify(ctx context.Context, method string, params any) error {
+	notification := message{JSONRPC: "2.0", Method: method}
+	if params != nil {
+		encodedParams, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s params: %w", method, err)
+		}
+		notification.Params = encodedParams
+	}
+	encoded, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s notification: %w", method, err)
+	}
+	return c.transport.Send(ctx, encoded)
+}
+
+// handle dispatches a message of the server.
+func (c *Client) handle(data []byte) {
+	var received message
+	if err := json.Unmarshal(data, &received); err != nil {
+		c.logger.Warn("failed to decode mcp message", "error", err)
+		return
+	}
+
+	switch {
+	case received.Method == "":
+		id, err := strconv.ParseInt(string(received.ID), 10, 64)
+		if err != nil {
+			return
+		}
+		c.mu.Lock()
+		responses, ok := c.pending[id]
+		c.mu.Unlock()
+		if ok {
+			responses <- received
+		}
+
+	case len(received.ID) == 0:
+		c.handleNotification(received)
+
+	default:
+		c.handleRequest(received)
+	}
+}
+
+func (c *Client) handleNotification(notification message) {
+	switch notification.Method {
+	case "notifications/tools/list_changed":
+		go func() {
+			if err := c.Refresh(c.ctx); err != nil && c.ctx.Err() == nil {
+				c.logger.Warn("failed to refresh mcp tools", "error", err)
+			}
+		}()
+
+	case "notifications/progress":
+		var params struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+			Progress      float64         `json:"progress"`
+			Total         float64         `json:"total"`
+			Message       string          `json:"message"`
+		}
+		if err := json.Unmarshal(notification.Params, &params); err != nil {
+			return
+		}
+		var token string
+		if err := json.Unmarshal(params.ProgressToken, &token); err != nil {
+			return
+		}
+		c.mu.Lock()
+		progress := c.progress[token]
+		c.mu.Unlock()
+		if progress == nil {
+			return
+		}
+
+		status := params.Message
+		if status == "" && params.Total > 0 {
+			status = fmt.Sprintf("%g of %g", params.Progress, params.Total)
+		} else if status == "" {
+			status = fmt.Sprintf("%g", params.Progress)
+		}
+		progress(status)
+	}
+}
+
+// handleRequest answers requests of the server, only pings are supported.
+func (c *Client) handleRequest(request message) {
+	response := message{JSONRPC: "2.0", ID: request.ID}
+	if request.Method == "ping" {
+		response.Result = json.RawMessage("{}")
+	} else {
+		response.Error = &rpcError{Code: errCodeMethodNotFound, Message: "method not found: " + request.Method}
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	if err := c.transport.Send(c.ctx, encoded); err != nil && c.ctx.Err() == nil {
+		c.logger.Warn("failed to answer mcp request", "method", request.Method, "error", err)
+	}
+}
+
+// toolDefinition is a tool as listed by the server.
+type toolDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InputSchema struct {
+		Properties map[string]struct {
+			Type        json.RawMessage `json:"type"`
+			Description string          `json:"description"`
+		} `json:"properties"`
+	} `json:"inputSchema"`
+}
+
+func (c *Client) newTool(definition toolDefinition) llms.Tool {
+	params := map[string]llms.ParameterBase{}
+	for name, property := range definition.InputSchema.Properties {
+		params[name] = llms.ParameterBase{Type: schemaType(property.Type), Description: property.Description}
+	}
+
+	name := definition.Name
+	return llms.NewStreamingTool(name, definition.Description, params, func(ctx context.Context, arguments json.RawMessage, progress llms.ProgressFunc) (string, error) {
+		return c.callTool(ctx, name, arguments, progress)
+	})
+}
+
+// schemaType returns the JSON schema type of a property, the first non-null
+// one if it has several.
+func schemaType(raw json.RawMessage) string {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single
+	}
+	var several []string
+	if err := json.Unmarshal(raw, &several); err == nil {
+		for _, t := range several {
+			if !strings.EqualFold(t, "null") {
+				return t
+			}
+		}
+	}
+	return "string"
+}
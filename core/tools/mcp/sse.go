@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+type SSETransportOptions struct {
+	httpClient *http.Client
+	header     http.Header
+}
+
+type SSETransportOption func(*SSETransportOptions)
+
+// WithHTTPClient sets the HTTP client of the transport, defaults to
+// [http.DefaultClient].
+func WithHTTPClient(client *http.Client) SSETransportOption {
+	return func(o *SSETransportOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithHeader adds a header to every request of the transport, e.g. for
+// authorization.
+func WithHeader(key, value string) SSETransportOption {
+	return func(o *SSETransportOptions) {
+		o.header.Add(key, value)
+	}
+}
+
+// SSETransport connects to a remote MCP server, receiving its messages as
+// server-sent events and posting messages to the endpoint the server
+// announces.
+type SSETransport struct {
+	SSETransportOptions
+	url string
+
+	mu       sync.Mutex
+	endpoint string
+	cancel   context.CancelFunc
+}
+
+// NewSSETransport creates a transport for the server with the SSE endpoint
+// at url.
+func NewSSETransport(url string, opts ...SSETransportOption) *SSETransport {
+	options := SSETransportOptions{httpClient: http.DefaultClient, header: http.Header{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &SSETransport{SSETransportOptions: options, url: url}
+}
+
+func (t *SSETransport) Start(ctx context.Context, handle func(message []byte)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, t.url, nil)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	t.setHeaders(request)
+	request.Header.Set("Accept", "text/event-stream")
+
+	response, err := t.httpClient.Do(request)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to connect to server: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		cancel()
+		return fmt.Errorf("failed to connect to server: unexpected status %s", response.Status)
+	}
+	t.mu.Lock()
+	t.cancel = cancel
+	t.mu.Unlock()
+
+	endpoints := make(chan string, 1)
+	go func() {
+		defer response.Body.Close()
+		readEvents(response, func(event, data string) {
+			switch event {
+			case "endpoint":
+				select {
+				case endpoints <- data:
+				default:
+				}
+			case "", "message":
+				handle([]byte(data))
+			}
+		})
+		cancel()
+	}()
+
+	select {
+	case endpoint := <-endpoints:
+		resolved, err := resolveEndpoint(t.url, endpoint)
+		if err != nil {
+			cancel()
+			return err
+		}
+		t.mu.Lock()
+		t.endpoint = resolved
+		t.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		return errors.New("server closed the event stream before announcing its endpoint")
+	}
+}
+
+func (t *SSETransport) Send(ctx context.Context, message []byte) error {
+	t.mu.Lock()
+	endpoint := t.endpoint
+	t.mu.Unlock()
+	if endpoint == "" {
+		return ErrClosed
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	t.setHeaders(request)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := t.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("failed to send message: unexpected status %s", response.Status)
+	}
+	return nil
+}
+
+func (t *SSETransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.endpoint = ""
+	return nil
+}
+
+func (t *SSETransport) setHeaders(request *http.Request) {
+	for key, values := range t.header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+}
+
+// readEvents calls onEvent for every event of the stream until it ends.
+func readEvents(response *http.Response, onEvent func(event, data string)) {
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	event := ""
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				onEvent(event, strings.Join(data, "\n"))
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
+
+func resolveEndpoint(base, endpoint string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid server url: %w", err)
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint announced by server: %w", err)
+	}
+	return baseURL.ResolveReference(endpointURL).String(), nil
+}
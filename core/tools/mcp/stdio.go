@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// StdioTransport runs an MCP server as a subprocess and exchanges newline
+// delimited messages over its stdin and stdout.
+type StdioTransport struct {
+	cmd *exec.Cmd
+
+	mu     sync.Mutex
+	stdin  io.WriteCloser
+	closed bool
+	done   chan struct{}
+}
+
+// NewStdioTransport creates a transport running cmd once the client starts
+// it, cmd must not be started yet.
+func NewStdioTransport(cmd *exec.Cmd) *StdioTransport {
+	return &StdioTransport{cmd: cmd, done: make(chan struct{})}
+}
+
+func (t *StdioTransport) Start(ctx context.Context, handle func(message []byte)) error {
+	if t.cmd == nil {
+		return errors.New("stdio transport requires a command")
+	}
+	stdin, err := t.cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open server stdin: %w", err)
+	}
+	stdout, err := t.cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open server stdout: %w", err)
+	}
+	if err := t.cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+	t.mu.Lock()
+	t.stdin = stdin
+	t.mu.Unlock()
+
+	go func() {
+		defer close(t.done)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			handle(append([]byte(nil), scanner.Bytes()...))
+		}
+	}()
+	context.AfterFunc(ctx, func() { t.Close() })
+	return nil
+}
+
+func (t *StdioTransport) Send(_ context.Context, message []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed || t.stdin == nil {
+		return ErrClosed
+	}
+	if _, err := t.stdin.Write(append(message, '\n')); err != nil {
+		return fmt.Errorf("failed to write to server: %w", err)
+	}
+	return nil
+}
+
+// Close closes the stdin of the server and waits for it to exit, killing it
+// if it does not exit in time.
+func (t *StdioTransport) Close() error {
+	t.mu.Lock()
+	if t.closed || t.stdin == nil {
+		t.closed = true
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.stdin.Close()
+	t.mu.Unlock()
+
+	select {
+	case <-t.done:
+	case <-time.After(5 * time.Second):
+		t.cmd.Process.Kill()
+	}
+	t.cmd.Wait()
+	return nil
+}
@@ -0,0 +1,189 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestStreamingToolsReportProgressEvents(t *testing.T) {
+	llm := &toolCallingLLMStub{
+		toolCalls: []llms.ToolCall{{ID: "call_1", Name: "search", Arguments: `{"query":"shoes"}`}},
+		response:  "Found two stores.",
+	}
+	var turnCtx atomic.Bool
+	search := llms.NewStreamingTool("search", "searches stores", map[string]llms.ParameterBase{
+		"query": {Type: "string", Description: "what to search for"},
+	}, func(ctx context.Context, params struct {
+		Query string `json:"query"`
+	}, progress llms.ProgressFunc) (string, error) {
+		turnCtx.Store(ctx.Done() != nil)
+		progress("Searching for " + params.Query)
+		progress("Searched 2 of 2 stores")
+		return "2 stores", nil
+	})
+	o := NewOrchestrator(WithStreamingLLM(llm), WithTools(search))
+	t.Cleanup(o.Close)
+
+	var mu sync.Mutex
+	var statuses []string
+	var completed atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		switch event := event.(type) {
+		case events.ToolCallProgress:
+			mu.Lock()
+			defer mu.Unlock()
+			if event.ID == "call_1" && event.Name == "search" && event.Message == "" {
+				statuses = append(statuses, event.Status)
+			}
+		case events.TurnCompleted:
+			completed.Store(true)
+		}
+	}))
+
+	o.HandleTrigger(triggers.NewUserPromptTrigger("where can I buy shoes?"))
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(statuses) != 2 || statuses[0] != "Searching for shoes" || statuses[1] != "Searched 2 of 2 stores" {
+		t.Fatalf("unexpected statuses %v", statuses)
+	}
+	if !turnCtx.Load() {
+		t.Fatalf("expected the tool to get the cancellable context of the turn")
+	}
+	if history := o.conversation.History(); len(history) != 1 || history[0].ToolCalls[0].Response != "2 stores" {
+		t.Fatalf("expected the tool response in the history, got %+v", history)
+	}
+}
+
+func TestToolPoliciesRetryAndRecoverFromFailures(t *testing.T) {
+	var flakyCalls atomic.Int32
+	flaky := llms.NewTool("flaky", "fails twice", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+		if flakyCalls.Add(1) <= 2 {
+			return "", errors.New("connection reset")
+		}
+		return "ok", nil
+	}, llms.WithRetries(2, time.Millisecond), llms.WithIdempotent())
+	var chargeCalls atomic.Int32
+	charge := llms.NewTool("charge", "charges the card", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+		chargeCalls.Add(1)
+		return "", errors.New("connection reset")
+	}, llms.WithRetries(2, time.Millisecond), llms.WithErrorPolicy(llms.ToolErrorReportToLLM))
+	slow := llms.NewTool("slow", "never answers in time", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+		time.Sleep(time.Second)
+		return "late", nil
+	}, llms.WithTimeout(10*time.Millisecond), llms.WithErrorPolicy(llms.ToolErrorReportToLLM))
+	broken := llms.NewTool("broken", "always fails", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+		return "", errors.New("broken")
+	}, llms.WithErrorPolicy(llms.ToolErrorSkip))
+
+	llm := &toolCallingLLMStub{toolCalls: []llms.ToolCall{
+		{ID: "call_1", Name: "flaky", Arguments: "{}"},
+		{ID: "call_2", Name: "slow", Arguments: "{}"},
+		{ID: "call_3", Name: "broken", Arguments: "{}"},
+		{ID: "call_4", Name: "charge", Arguments: "{}"},
+	}}
+	o := NewOrchestrator(WithStreamingLLM(llm), WithTools(flaky, slow, broken, charge))
+	t.Cleanup(o.Close)
+	var failed atomic.Int32
+	var completed atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		switch event.(type) {
+		case events.ToolCallFailed:
+			failed.Add(1)
+		case events.TurnCompleted:
+			completed.Store(true)
+		}
+	}))
+
+	o.HandleTrigger(triggers.NewUserPromptTrigger("go"))
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	if flakyCalls.Load() != 3 {
+		t.Fatalf("expected the flaky tool to succeed on its second retry, got %d calls", flakyCalls.Load())
+	}
+	if chargeCalls.Load() != 1 {
+		t.Fatalf("expected the tool not marked idempotent not to be retried, got %d calls", chargeCalls.Load())
+	}
+	if failed.Load() != 3 {
+		t.Fatalf("expected a failure event for the slow, the broken and the charge tool, got %d", failed.Load())
+	}
+	returned := llm.returnedToolCalls()
+	if len(returned) != 4 || returned[0].Response != "ok" || returned[2].Response != "" {
+		t.Fatalf("unexpected tool calls returned to the LLM %+v", returned)
+	}
+	if !strings.HasPrefix(returned[1].Response, "Error: ") || !strings.Contains(returned[1].Response, context.DeadlineExceeded.Error()) {
+		t.Fatalf("expected the timeout to be reported to the LLM, got %q", returned[1].Response)
+	}
+}
+
+type toolSourceStub struct {
+	mu    sync.Mutex
+	tools []llms.Tool
+}
+
+func (s *toolSourceStub) Tools() []llms.Tool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]llms.Tool(nil), s.tools...)
+}
+
+func TestToolSourceToolsAreCalledAsOfTheTurn(t *testing.T) {
+	var lookups atomic.Int32
+	source := &toolSourceStub{}
+	llm := &toolCallingLLMStub{toolCalls: []llms.ToolCall{{ID: "call_1", Name: "lookup", Arguments: "{}"}}}
+	o := NewOrchestrator(WithStreamingLLM(llm), WithToolSource(source))
+	t.Cleanup(o.Close)
+	if tools := o.conversation.AvailableTools(); len(tools) != 0 {
+		t.Fatalf("expected no tools before the source offers any, got %d", len(tools))
+	}
+
+	// The source offers the tool only after the orchestrator was created.
+	source.mu.Lock()
+	source.tools = []llms.Tool{llms.NewTool("lookup", "looks up", map[string]llms.ParameterBase{}, func(struct{}) (string, error) {
+		lookups.Add(1)
+		return "found", nil
+	})}
+	source.mu.Unlock()
+
+	var completed atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+	o.HandleTrigger(triggers.NewUserPromptTrigger("go"))
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	if lookups.Load() != 1 {
+		t.Fatalf("expected the source tool to be called once, got %d", lookups.Load())
+	}
+	if returned := llm.returnedToolCalls(); len(returned) != 1 || returned[0].Response != "found" {
+		t.Fatalf("unexpected tool calls returned to the LLM %+v", returned)
+	}
+	if len(o.conversation.AvailableTools()) != 1 {
+		t.Fatal("expected the source tool to be available")
+	}
+}
+
+func TestWithToolSourceRejectsNilSource(t *testing.T) {
+	_, err := NewOrchestratorE(WithToolSource(nil))
+	if !errors.Is(err, ErrNilClient) {
+		t.Fatalf("expected nil client error, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/koscakluka/ema-core/core/errorreport"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Translator translates text between languages, e.g. a dedicated translation
+// API or an [LLMTranslator].
+type Translator interface {
+	Translate(ctx context.Context, text, sourceLanguage, targetLanguage string) (string, error)
+}
+
+// LLMTranslator is a [Translator] prompting an LLM.
+type LLMTranslator struct {
+	llm LLMWithGeneralPrompt
+}
+
+// NewLLMTranslator creates a translator prompting llm, preferably a small
+// and fast model as it is prompted for every sentence of the responses.
+func NewLLMTranslator(llm LLMWithGeneralPrompt) *LLMTranslator {
+	return &LLMTranslator{llm: llm}
+}
+
+func (t *LLMTranslator) Translate(ctx context.Context, text, sourceLanguage, targetLanguage string) (string, error) {
+	response, err := t.llm.Prompt(ctx, text, llms.WithSystemPrompt(fmt.Sprintf(
+		"Translate the text from %s to %s. Reply with the translation only, without quotes or notes.",
+		sourceLanguage, targetLanguage,
+	)))
+	if err != nil {
+		return "", err
+	} else if response == nil {
+		return "", fmt.Errorf("no translation returned")
+	}
+	return strings.TrimSpace(response.Content), nil
+}
+
+// WithTranslation translates the conversation between userLanguage, spoken
+// by the user, and assistantLanguage, used with the LLM. User prompts are
+// translated before the turn middlewares see them, responses sentence by
+// sentence before they are spoken.
+//
+// The history keeps prompts and responses in assistantLanguage, typed and
+// spoken responses are in userLanguage. Each translation is reported with
+// a [events.TextTranslated] event. Text that fails to translate is used as
+// is.
+func WithTranslation(translator Translator, userLanguage, assistantLanguage string) OrchestratorOption {
+	return func(o *Orchestrator) {
+		o.translation = &translation{
+			translator:        translator,
+			userLanguage:      userLanguage,
+			assistantLanguage: assistantLanguage,
+			emitEvent:         noopEventEmitter,
+		}
+	}
+}
+
+type translation struct {
+	translator        Translator
+	userLanguage      string
+	assistantLanguage string
+
+	emitEvent eventEmitter
+}
+
+// WrapTurn translates user prompts for the LLM.
+func (t *translation) WrapTurn(next TurnHandler) TurnHandler {
+	return func(ctx context.Context, req TurnRequest) (llms.TurnV1, error) {
+		if prompt, ok := req.Trigger.(triggers.UserPromptTrigger); ok {
+			prompt.Prompt = t.translate(ctx, events.TranslationDirectionUser, prompt.Prompt, t.userLanguage, t.assistantLanguage)
+			req.Trigger = prompt
+		}
+		return next(ctx, req)
+	}
+}
+
+func (t *translation) translate(ctx context.Context, direction, text, sourceLanguage, targetLanguage string) string {
+	translated, err := t.translator.Translate(ctx, text, sourceLanguage, targetLanguage)
+	if err != nil {
+		errorreport.Record(ctx, trace.SpanFromContext(ctx), fmt.Errorf("failed to translate %s text: %w", direction, err), "component", "translation")
+		return text
+	}
+
+	t.emitEvent(events.NewTextTranslated(direction, text, translated, sourceLanguage, targetLanguage))
+	return translated
+}
+
+// responseTranslator translates the response of a turn sentence by
+// sentence, passing the translations to send.
+type responseTranslator struct {
+	ctx         context.Context
+	translation *translation
+	send        func(string)
+
+	pending string
+}
+
+func (t *translation) newResponseTranslator(ctx context.Context, send func(string)) *responseTranslator {
+	return &responseTranslator{ctx: ctx, translation: t, send: send}
+}
+
+// add translates the sentences chunk completes.
+func (r *responseTranslator) add(chunk string) {
+	r.pending += chunk
+
+	end := -1
+	for i := 0; i < len(r.pending)-1; i++ {
+		if strings.ContainsRune(defaultSpeechPlayerSegmentationBoundaries, rune(r.pending[i])) && isSpace(r.pending[i+1]) {
+			end = i + 1
+		}
+	}
+	if end < 0 {
+		return
+	}
+
+	sentences := r.pending[:end]
+	r.pending = r.pending[end:]
+	r.translate(sentences)
+}
+
+// revise replaces the end of the response matching replaced with
+// replacement, as far as it is not translated yet.
+func (r *responseTranslator) revise(replaced, replacement string) {
+	dropped := min(len(replaced), len(r.pending))
+	if strings.HasSuffix(r.pending, replaced[len(replaced)-dropped:]) {
+		r.pending = r.pending[:len(r.pending)-dropped]
+	}
+	r.add(replacement)
+}
+
+// flush translates the rest of the response.
+func (r *responseTranslator) flush() {
+	sentences := r.pending
+	r.pending = ""
+	r.translate(sentences)
+}
+
+func (r *responseTranslator) translate(text string) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		if text != "" {
+			r.send(text)
+		}
+		return
+	}
+
+	leading := text[:strings.Index(text, trimmed)]
+	r.send(leading + r.translation.translate(r.ctx, events.TranslationDirectionAssistant, trimmed, r.translation.assistantLanguage, r.translation.userLanguage))
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r'
+}
@@ -0,0 +1,107 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// taggingTranslator "translates" by tagging text with the target language.
+type taggingTranslator struct{}
+
+func (taggingTranslator) Translate(_ context.Context, text, _, targetLanguage string) (string, error) {
+	return "[" + targetLanguage + "] " + text, nil
+}
+
+// streamingEchoLLMStub streams the replies of [recordingPromptLLMStub].
+type streamingEchoLLMStub struct {
+	recordingPromptLLMStub
+}
+
+func (stub *streamingEchoLLMStub) Prompt(ctx context.Context, prompt string, opts ...llms.PromptOption) ([]llms.Message, error) {
+	promptOptions := llms.PromptOptions{}
+	for _, opt := range opts {
+		opt(&promptOptions)
+	}
+
+	response, err := stub.recordingPromptLLMStub.Prompt(ctx, prompt, opts...)
+	if err == nil && promptOptions.Stream != nil {
+		promptOptions.Stream(response[0].Content)
+	}
+	return response, err
+}
+
+func TestTranslationTranslatesPromptsAndResponses(t *testing.T) {
+	llm := &streamingEchoLLMStub{}
+	var mu sync.Mutex
+	var translated []events.TextTranslated
+	var completed atomic.Bool
+	o := NewOrchestrator(WithLLM(llm), WithTranslation(taggingTranslator{}, "es", "en"))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		switch typedEvent := event.(type) {
+		case events.TextTranslated:
+			mu.Lock()
+			translated = append(translated, typedEvent)
+			mu.Unlock()
+		case events.TurnCompleted:
+			completed.Store(true)
+		}
+	}))
+
+	o.HandleTrigger(triggers.NewUserPromptTrigger("hola"))
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	llm.mu.Lock()
+	if len(llm.prompts) != 1 || llm.prompts[0] != "[en] hola" {
+		t.Fatalf("expected the translated prompt, got %v", llm.prompts)
+	}
+	llm.mu.Unlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(translated) != 2 {
+		t.Fatalf("expected a translation per direction, got %+v", translated)
+	}
+	if user := translated[0]; user.Direction != events.TranslationDirectionUser || user.Original != "hola" || user.Translation != "[en] hola" {
+		t.Fatalf("unexpected user translation %+v", user)
+	}
+	if assistant := translated[1]; assistant.Direction != events.TranslationDirectionAssistant ||
+		assistant.Original != "echo: [en] hola" || assistant.Translation != "[es] echo: [en] hola" ||
+		assistant.SourceLanguage != "en" || assistant.TargetLanguage != "es" {
+		t.Fatalf("unexpected assistant translation %+v", assistant)
+	}
+
+	history := o.conversation.History()
+	if len(history) != 1 || len(history[0].Responses) != 1 || history[0].Responses[0].Message != "echo: [en] hola" {
+		t.Fatalf("expected the history to keep the assistant language, got %+v", history)
+	}
+}
+
+func TestResponseTranslatorTranslatesSentences(t *testing.T) {
+	var sent []string
+	translator := (&translation{translator: taggingTranslator{}, userLanguage: "es", assistantLanguage: "en", emitEvent: noopEventEmitter}).
+		newResponseTranslator(context.Background(), func(text string) { sent = append(sent, text) })
+
+	translator.add("Hello there. How")
+	translator.add(" are you? Pi is 3.14")
+	translator.flush()
+
+	want := []string{"[es] Hello there.", " [es] How are you?", " [es] Pi is 3.14"}
+	if len(sent) != len(want) {
+		t.Fatalf("expected %q, got %q", want, sent)
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Fatalf("expected %q, got %q", want, sent)
+		}
+	}
+}
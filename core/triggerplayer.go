@@ -7,7 +7,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/koscakluka/ema-core/core/errorreport"
 	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/logging"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -22,12 +24,25 @@ type triggerPlayer struct {
 	closeCh chan struct{}
 	done    chan struct{}
 
+	// external, when set, replaces queue as the source of queued triggers.
+	external TriggerQueueV0
+
+	// pending holds triggers that are processed before anything in queue,
+	// i.e. triggers restored from a drained session, or the trigger taken
+	// out of queue when draining started.
+	pending   []triggerQueueItem
+	pendingMu sync.Mutex
+
+	drainCh   chan struct{}
+	drainOnce sync.Once
+
 	startOnce sync.Once
 	endOnce   sync.Once
 
 	started atomic.Bool
 
 	onCancel func()
+	logger   logging.Logger
 }
 
 func newTriggerPlayer() *triggerPlayer {
@@ -35,8 +50,10 @@ func newTriggerPlayer() *triggerPlayer {
 		queue:   make(chan triggerQueueItem, conversationTriggerQueueCapacity), // TODO: Figure out good values for this.
 		closeCh: make(chan struct{}),
 		done:    make(chan struct{}),
+		drainCh: make(chan struct{}),
 
 		onCancel: func() {},
+		logger:   logging.Default(),
 	}
 }
 
@@ -50,6 +67,14 @@ func (b *triggerPlayer) SetOnCancel(onCancel func()) {
 	}
 }
 
+func (b *triggerPlayer) SetQueue(queue TriggerQueueV0) {
+	if b == nil || b.started.Load() {
+		return
+	}
+
+	b.external = queue
+}
+
 func (b *triggerPlayer) CanIngest() bool {
 	if b == nil {
 		return false
@@ -63,7 +88,7 @@ func (b *triggerPlayer) CanIngest() bool {
 	}
 }
 
-func (loop *triggerPlayer) StartLoop(baseCtx context.Context, startNewTurn func(context.Context, llms.TriggerV0) error) (started bool) {
+func (loop *triggerPlayer) StartLoop(baseCtx context.Context, startNewTurn func(ctx context.Context, trigger llms.TriggerV0, queuedAt time.Time) error) (started bool) {
 	if loop == nil || startNewTurn == nil || !loop.CanIngest() {
 		return false
 	}
@@ -75,17 +100,37 @@ func (loop *triggerPlayer) StartLoop(baseCtx context.Context, startNewTurn func(
 
 		started = true
 		loop.started.Store(true)
+		if loop.external != nil {
+			go loop.externalLoop(baseCtx, startNewTurn)
+			return
+		}
+
 		go func() {
 			defer close(loop.done)
 
 			for {
+				if queuedTrigger, ok := loop.popPending(); ok {
+					if !loop.CanIngest() || loop.isDraining() {
+						loop.pushPendingFront(queuedTrigger)
+						return
+					}
+					loop.processQueuedTrigger(baseCtx, queuedTrigger, startNewTurn)
+					continue
+				}
+
 				select {
 				case <-loop.closeCh:
 					return
+				case <-loop.drainCh:
+					return
 				case queuedTrigger := <-loop.queue:
 					if !loop.CanIngest() {
 						return
 					}
+					if loop.isDraining() {
+						loop.pushPendingFront(queuedTrigger)
+						return
+					}
 					loop.processQueuedTrigger(baseCtx, queuedTrigger, startNewTurn)
 				}
 			}
@@ -95,6 +140,136 @@ func (loop *triggerPlayer) StartLoop(baseCtx context.Context, startNewTurn func(
 	return started
 }
 
+func (loop *triggerPlayer) externalLoop(baseCtx context.Context, startNewTurn func(ctx context.Context, trigger llms.TriggerV0, queuedAt time.Time) error) {
+	defer close(loop.done)
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(baseCtx))
+	defer cancel()
+	go func() {
+		select {
+		case <-loop.closeCh:
+			cancel()
+		case <-loop.drainCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		if queuedTrigger, ok := loop.popPending(); ok {
+			if !loop.CanIngest() || loop.isDraining() {
+				loop.pushPendingFront(queuedTrigger)
+				return
+			}
+			loop.processQueuedTrigger(baseCtx, queuedTrigger, startNewTurn)
+			continue
+		}
+
+		queued, err := loop.external.Dequeue(ctx)
+		if !loop.CanIngest() || loop.isDraining() {
+			// Anything dequeued here stays unacknowledged in the external
+			// queue and is delivered again.
+			return
+		}
+		if err != nil {
+			loop.logger.Warn("failed to dequeue trigger", "error", err)
+			select {
+			case <-loop.closeCh:
+				return
+			case <-time.After(externalQueueRetryDelay):
+			}
+			continue
+		}
+
+		loop.processQueuedTrigger(baseCtx, triggerQueueItem{trigger: queued.Trigger, queuedAt: queued.QueuedAt}, startNewTurn)
+		if !loop.CanIngest() {
+			// The turn was interrupted by shutdown, leave it unacknowledged
+			// so it is picked up again when the conversation resumes.
+			return
+		}
+		if err := loop.external.Ack(ctx, queued.ID); err != nil {
+			loop.logger.Warn("failed to acknowledge trigger", "trigger_id", queued.ID, "error", err)
+		}
+	}
+}
+
+// StopTaking stops the loop from taking further triggers out of the queue
+// without cancelling the turn in progress. Triggers can still be ingested and
+// are kept queued, see [triggerPlayer.TakeQueued].
+func (loop *triggerPlayer) StopTaking() {
+	if loop == nil {
+		return
+	}
+
+	loop.drainOnce.Do(func() { close(loop.drainCh) })
+}
+
+func (loop *triggerPlayer) isDraining() bool {
+	select {
+	case <-loop.drainCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Preload queues triggers ahead of anything ingested later.
+func (loop *triggerPlayer) Preload(triggers ...llms.TriggerV0) {
+	if loop == nil {
+		return
+	}
+
+	loop.pendingMu.Lock()
+	defer loop.pendingMu.Unlock()
+	for _, trigger := range triggers {
+		loop.pending = append(loop.pending, triggerQueueItem{trigger: trigger, queuedAt: time.Now()})
+	}
+}
+
+// TakeQueued removes and returns all triggers still waiting in the in-memory
+// queue, in processing order. It should only be called once the loop has
+// stopped.
+func (loop *triggerPlayer) TakeQueued() []llms.TriggerV0 {
+	if loop == nil {
+		return nil
+	}
+
+	loop.pendingMu.Lock()
+	queued := make([]llms.TriggerV0, 0, len(loop.pending)+len(loop.queue))
+	for _, item := range loop.pending {
+		queued = append(queued, item.trigger)
+	}
+	loop.pending = nil
+	loop.pendingMu.Unlock()
+
+	for {
+		select {
+		case item := <-loop.queue:
+			queued = append(queued, item.trigger)
+		default:
+			return queued
+		}
+	}
+}
+
+func (loop *triggerPlayer) popPending() (triggerQueueItem, bool) {
+	loop.pendingMu.Lock()
+	defer loop.pendingMu.Unlock()
+	if len(loop.pending) == 0 {
+		return triggerQueueItem{}, false
+	}
+
+	item := loop.pending[0]
+	loop.pending = loop.pending[1:]
+	return item, true
+}
+
+func (loop *triggerPlayer) pushPendingFront(item triggerQueueItem) {
+	loop.pendingMu.Lock()
+	defer loop.pendingMu.Unlock()
+	loop.pending = append([]triggerQueueItem{item}, loop.pending...)
+}
+
 func (loop *triggerPlayer) Stop() {
 	if loop == nil {
 		return
@@ -108,6 +283,10 @@ func (loop *triggerPlayer) Clear() {
 		return
 	}
 
+	loop.pendingMu.Lock()
+	loop.pending = nil
+	loop.pendingMu.Unlock()
+
 	for {
 		select {
 		case <-loop.queue:
@@ -127,6 +306,10 @@ func (loop *triggerPlayer) AwaitDone() {
 	}
 }
 
+// externalQueueRetryDelay is how long the loop waits before retrying a
+// failed dequeue from an external queue.
+const externalQueueRetryDelay = time.Second
+
 type triggerQueueItem struct {
 	trigger  llms.TriggerV0
 	queuedAt time.Time
@@ -137,6 +320,14 @@ func (loop *triggerPlayer) Ingest(trigger llms.TriggerV0) bool {
 		return false
 	}
 
+	if loop.external != nil {
+		if err := loop.external.Enqueue(context.Background(), trigger); err != nil {
+			loop.logger.Warn("failed to enqueue trigger to external queue", "error", err)
+			return false
+		}
+		return true
+	}
+
 	queueItem := triggerQueueItem{trigger: trigger, queuedAt: time.Now()}
 	select {
 	case <-loop.closeCh:
@@ -149,7 +340,7 @@ func (loop *triggerPlayer) Ingest(trigger llms.TriggerV0) bool {
 func (loop *triggerPlayer) processQueuedTrigger(
 	baseContext context.Context,
 	queuedTrigger triggerQueueItem,
-	startNewTurn func(context.Context, llms.TriggerV0) error,
+	startNewTurn func(ctx context.Context, trigger llms.TriggerV0, queuedAt time.Time) error,
 ) {
 	if loop == nil || startNewTurn == nil {
 		return
@@ -175,9 +366,9 @@ func (loop *triggerPlayer) processQueuedTrigger(
 
 	trigger := queuedTrigger.trigger
 
-	if err := startNewTurn(ctx, trigger); err != nil {
+	if err := startNewTurn(ctx, trigger, queuedTrigger.queuedAt); err != nil {
 		err := fmt.Errorf("failed to start new turn: %v", err)
-		span.RecordError(err)
+		errorreport.Record(ctx, span, err)
 		span.SetStatus(codes.Error, err.Error())
 		// TODO: Probably should be able to requeue the prompt or something
 		// here
@@ -189,7 +380,14 @@ func (loop *triggerPlayer) queuedTriggerCount() int {
 		return 0
 	}
 
-	return len(loop.queue)
+	loop.pendingMu.Lock()
+	pending := len(loop.pending)
+	loop.pendingMu.Unlock()
+
+	if counter, ok := loop.external.(interface{ Len() int }); ok {
+		return pending + counter.Len()
+	}
+	return pending + len(loop.queue)
 }
 
 func (loop *triggerPlayer) OnCancel() {
@@ -0,0 +1,86 @@
+package orchestration
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestExternalTriggerQueueProcessesAndAcksTriggers(t *testing.T) {
+	queue := newMemoryTriggerQueue()
+	o := NewOrchestrator(
+		WithStreamingLLM(scriptedStreamLLMStub{chunks: []string{"hello"}, interval: time.Millisecond}),
+		WithTriggerQueueV0(queue),
+	)
+	defer o.Close()
+
+	// Simulates a trigger left behind by a previous instance.
+	if err := queue.Enqueue(context.Background(), triggers.NewUserPromptTrigger("resumed prompt")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx)
+	o.SendPrompt("new prompt")
+
+	waitForCondition(t, 2*time.Second, "both triggers acknowledged", func() bool {
+		return len(queue.acked()) == 2
+	})
+
+	history := o.ConversationV1().History
+	if len(history) != 2 {
+		t.Fatalf("expected 2 turns in history, got %d", len(history))
+	}
+	if got := history[0].Trigger.String(); got != "resumed prompt" {
+		t.Fatalf("expected resumed prompt to be processed first, got %q", got)
+	}
+}
+
+type memoryTriggerQueue struct {
+	mu       sync.Mutex
+	items    chan QueuedTriggerV0
+	nextID   int
+	ackedIDs []string
+}
+
+func newMemoryTriggerQueue() *memoryTriggerQueue {
+	return &memoryTriggerQueue{items: make(chan QueuedTriggerV0, 10)}
+}
+
+func (q *memoryTriggerQueue) Enqueue(_ context.Context, trigger llms.TriggerV0) error {
+	q.mu.Lock()
+	q.nextID++
+	id := strconv.Itoa(q.nextID)
+	q.mu.Unlock()
+
+	q.items <- QueuedTriggerV0{ID: id, Trigger: trigger, QueuedAt: time.Now()}
+	return nil
+}
+
+func (q *memoryTriggerQueue) Dequeue(ctx context.Context) (QueuedTriggerV0, error) {
+	select {
+	case <-ctx.Done():
+		return QueuedTriggerV0{}, ctx.Err()
+	case item := <-q.items:
+		return item, nil
+	}
+}
+
+func (q *memoryTriggerQueue) Ack(_ context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.ackedIDs = append(q.ackedIDs, id)
+	return nil
+}
+
+func (q *memoryTriggerQueue) acked() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string(nil), q.ackedIDs...)
+}
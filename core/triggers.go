@@ -4,21 +4,88 @@ import (
 	"context"
 	"fmt"
 	"iter"
-	"log"
+	"reflect"
 	"time"
 
 	"github.com/koscakluka/ema-core/core/conversations"
+	"github.com/koscakluka/ema-core/core/errorreport"
 	"github.com/koscakluka/ema-core/core/llms"
 	"github.com/koscakluka/ema-core/core/triggers"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// WithCustomTrigger handles triggers of the application defined type T,
+// e.g. an event of another system the assistant should speak up about, with
+// handle instead of the trigger handler. handle yields the triggers to
+// process in its place, e.g. a user prompt for a proactive response. Yielded
+// triggers of type T go to the trigger handler, which starts a turn
+// responding to their String.
+//
+// Register T with [triggers.Register] to keep it in session states and
+// persistent trigger queues.
+func WithCustomTrigger[T llms.TriggerV0](handle func(ctx context.Context, trigger T, conversation conversations.ActiveContextV0) iter.Seq2[llms.TriggerV0, error]) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if o.customTriggerHandlers == nil {
+			o.customTriggerHandlers = map[reflect.Type]TriggerHandlerV0{}
+		}
+		o.customTriggerHandlers[reflect.TypeFor[T]()] = customTriggerHandler[T](handle)
+	}
+}
+
+type customTriggerHandler[T llms.TriggerV0] func(ctx context.Context, trigger T, conversation conversations.ActiveContextV0) iter.Seq2[llms.TriggerV0, error]
+
+func (handle customTriggerHandler[T]) HandleTriggerV0(ctx context.Context, trigger llms.TriggerV0, conversation conversations.ActiveContextV0) iter.Seq2[llms.TriggerV0, error] {
+	return handle(ctx, trigger.(T), conversation)
+}
+
 func (o *Orchestrator) ingestTrigger(trigger llms.TriggerV0) {
+	if o.isLikelyEcho(trigger) {
+		return
+	}
+	o.markActive()
+	if transcription, ok := trigger.(triggers.TranscriptionTrigger); ok && o.turnTaking != nil {
+		o.turnTaking.transcribed(o.baseContext, transcription, o.conversation.History())
+		return
+	}
+	o.dispatchTrigger(trigger)
+}
+
+// dispatchTrigger passes trigger to its custom handler or to the trigger
+// handler, unless the addressing policy declines it.
+func (o *Orchestrator) dispatchTrigger(trigger llms.TriggerV0) {
+	if !o.isAddressed(o.currentActiveContext(), trigger) {
+		return
+	}
+
+	handler, ok := o.customTriggerHandlers[reflect.TypeOf(trigger)]
+	if !ok {
+		o.handleTrigger(trigger)
+		return
+	}
+
+	ctx := o.currentActiveContext()
+	for handled, err := range handler.HandleTriggerV0(ctx, trigger, &o.conversation) {
+		if err != nil {
+			errorreport.Record(ctx, trace.SpanFromContext(ctx), err, "component", "trigger_handler")
+			return
+		}
+		if handled == nil {
+			continue
+		}
+		if reflect.TypeOf(handled) == reflect.TypeOf(trigger) {
+			o.handleTrigger(handled)
+		} else {
+			o.ingestTrigger(handled)
+		}
+	}
+}
+
+func (o *Orchestrator) handleTrigger(trigger llms.TriggerV0) {
 	ctx := o.currentActiveContext()
 	for trigger, err := range o.triggerHandler.HandleTriggerV0(ctx, trigger, &o.conversation) {
 		if err != nil {
-			span := trace.SpanFromContext(o.currentActiveContext())
-			span.RecordError(err)
+			span := trace.SpanFromContext(ctx)
+			errorreport.Record(ctx, span, err, "component", "trigger_handler")
 			return
 		}
 		if trigger == nil {
@@ -32,6 +99,11 @@ func (o *Orchestrator) ingestTrigger(trigger llms.TriggerV0) {
 		// effects separately for easier testing;
 		// 3) middleware pipeline: small chained handlers when we need logging,
 		// retries, metrics, or other cross-cutting behavior around event handling.
+		if t, ok := trigger.(triggers.ContextTrigger); ok && !t.Respond {
+			o.saveTurn(ctx, o.conversation.appendContextTurn(t))
+			continue
+		}
+
 		switch t := trigger.(type) {
 		case triggers.CancelTurnTrigger:
 			o.currentResponsePipeline().Cancel()
@@ -46,6 +118,9 @@ func (o *Orchestrator) ingestTrigger(trigger llms.TriggerV0) {
 				update.Type = t.Type
 				update.Resolved = t.Resolved
 			})
+			if o.interruptionResume.interrupted(o.conversation.ActiveTurn(), t.ID, t.Type) {
+				o.currentResponsePipeline().Cancel()
+			}
 		case triggers.CallToolTrigger:
 			if t.Tool != nil {
 				_, err := o.callTool(ctx, *t.Tool)
@@ -59,8 +134,8 @@ func (o *Orchestrator) ingestTrigger(trigger llms.TriggerV0) {
 			}
 			return
 		default:
-			if ok := o.triggerPlayer.Ingest(trigger); !ok {
-				log.Printf("Warning: failed to enqueue trigger %T", trigger)
+			if ok := o.triggerPlayer.Load().Ingest(trigger); !ok {
+				o.logger.Warn("failed to enqueue trigger", "trigger", fmt.Sprintf("%T", trigger))
 			}
 		}
 	}
@@ -81,7 +156,10 @@ func (h *internalTriggerHandler) HandleTriggerV0(ctx context.Context, trigger ll
 		}
 
 		switch trigger.(type) {
-		case triggers.CallToolTrigger, triggers.CancelTurnTrigger, triggers.PauseTurnTrigger, triggers.UnpauseTurnTrigger:
+		case triggers.CallToolTrigger, triggers.CancelTurnTrigger, triggers.PauseTurnTrigger, triggers.UnpauseTurnTrigger,
+			// Assistant initiated turns queue after the active turn instead
+			// of interrupting it, as do context facts.
+			triggers.AssistantInitiatedTrigger, triggers.ContextTrigger:
 			yield(trigger, nil)
 			return
 		}
@@ -0,0 +1,28 @@
+package triggers
+
+// AssistantInitiatedTrigger starts a turn in which the assistant speaks
+// without being prompted by the user, e.g. opening an outbound call or
+// delivering a reminder.
+type AssistantInitiatedTrigger struct {
+	BaseTrigger
+	// Instruction is what the assistant should say or do, e.g. "Greet the
+	// user and remind them of their appointment at 3pm."
+	Instruction string
+}
+
+// String is what the LLM receives in place of a user message.
+func (t AssistantInitiatedTrigger) String() string {
+	return "[No user input, the assistant speaks first: " + t.Instruction + "]"
+}
+
+func NewAssistantInitiatedTrigger(instruction string, opts ...RebaseOption) AssistantInitiatedTrigger {
+	base := NewBaseTrigger()
+	for _, opt := range opts {
+		opt(&base)
+	}
+
+	return AssistantInitiatedTrigger{
+		BaseTrigger: base,
+		Instruction: instruction,
+	}
+}
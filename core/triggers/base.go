@@ -4,6 +4,7 @@ import "time"
 
 type BaseTrigger struct {
 	timestamp time.Time
+	speakerID string
 }
 
 func NewBaseTrigger() BaseTrigger {
@@ -14,6 +15,12 @@ func (t BaseTrigger) Timestamp() time.Time {
 	return t.timestamp
 }
 
+// SpeakerID identifies the user who caused the trigger in conversations with
+// several users, it is empty if unknown.
+func (t BaseTrigger) SpeakerID() string {
+	return t.speakerID
+}
+
 type RebaseOption func(*BaseTrigger)
 
 func WithBase(base BaseTrigger) RebaseOption {
@@ -21,3 +28,10 @@ func WithBase(base BaseTrigger) RebaseOption {
 		*o = base
 	}
 }
+
+// WithSpeaker attributes the trigger to the user identified by speakerID.
+func WithSpeaker(speakerID string) RebaseOption {
+	return func(o *BaseTrigger) {
+		o.speakerID = speakerID
+	}
+}
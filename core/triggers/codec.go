@@ -0,0 +1,186 @@
+package triggers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// Trigger type names used by [Marshal] and [Unmarshal].
+const (
+	TypeUserPrompt           = "user_prompt"
+	TypeTranscription        = "transcription"
+	TypeInterimTranscription = "interim_transcription"
+	TypeSpeechStarted        = "speech_started"
+	TypeSpeechEnded          = "speech_ended"
+	TypeCancelTurn           = "cancel_turn"
+	TypePauseTurn            = "pause_turn"
+	TypeUnpauseTurn          = "unpause_turn"
+	TypeCallTool             = "call_tool"
+	TypeRecordInterruption   = "record_interruption"
+	TypeResolveInterruption  = "resolve_interruption"
+	TypeAssistantInitiated   = "assistant_initiated"
+	TypeContext              = "context"
+)
+
+// WireVersion is the version of the format of [Marshal]. Within a version
+// trigger types and data fields are only added, never removed, renamed or
+// retyped, and [Unmarshal] keeps accepting all older versions.
+const WireVersion = 1
+
+// ErrUnsupportedWireVersion is the error of triggers encoded with a newer
+// [WireVersion] than the decoder knows.
+var ErrUnsupportedWireVersion = errors.New("unsupported wire version")
+
+type encodedTrigger struct {
+	Version   int             `json:"version"`
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Speaker   string          `json:"speaker,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+type transcriptData struct {
+	Transcript     string `json:"transcript"`
+	DuringPlayback bool   `json:"during_playback,omitempty"`
+}
+
+type speechStartedData struct {
+	DuringPlayback bool `json:"during_playback,omitempty"`
+}
+
+// Marshal encodes a trigger from this package, or one registered with
+// [Register], as JSON so it can be stored
+// outside the process, e.g. in a persistent trigger queue.
+func Marshal(trigger llms.TriggerV0) ([]byte, error) {
+	var typ string
+	var data any
+	switch t := trigger.(type) {
+	case UserPromptTrigger:
+		typ, data = TypeUserPrompt, t
+	case TranscriptionTrigger:
+		typ, data = TypeTranscription, transcriptData{Transcript: t.transcript, DuringPlayback: t.DuringPlayback}
+	case InterimTranscriptionTrigger:
+		typ, data = TypeInterimTranscription, transcriptData{Transcript: t.transcript}
+	case SpeechStartedTrigger:
+		typ = TypeSpeechStarted
+		if t.DuringPlayback {
+			data = speechStartedData{DuringPlayback: true}
+		}
+	case SpeechEndedTrigger:
+		typ = TypeSpeechEnded
+	case CancelTurnTrigger:
+		typ = TypeCancelTurn
+	case PauseTurnTrigger:
+		typ = TypePauseTurn
+	case UnpauseTurnTrigger:
+		typ = TypeUnpauseTurn
+	case CallToolTrigger:
+		typ, data = TypeCallTool, t
+	case RecordInterruptionTrigger:
+		typ, data = TypeRecordInterruption, t
+	case ResolveInterruptionTrigger:
+		typ, data = TypeResolveInterruption, t
+	case AssistantInitiatedTrigger:
+		typ, data = TypeAssistantInitiated, t
+	case ContextTrigger:
+		typ, data = TypeContext, t
+	default:
+		registered, ok := registeredTypeName(trigger)
+		if !ok {
+			return nil, fmt.Errorf("unsupported trigger type %T", trigger)
+		}
+		typ, data = registered, trigger
+	}
+
+	encoded := encodedTrigger{Version: WireVersion, Type: typ}
+	if timestamped, ok := trigger.(interface{ Timestamp() time.Time }); ok {
+		encoded.Timestamp = timestamped.Timestamp()
+	}
+	if attributed, ok := trigger.(interface{ SpeakerID() string }); ok {
+		encoded.Speaker = attributed.SpeakerID()
+	}
+	if data != nil {
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s trigger: %w", typ, err)
+		}
+		encoded.Data = dataBytes
+	}
+
+	return json.Marshal(encoded)
+}
+
+// Unmarshal decodes a trigger encoded with [Marshal], preserving its
+// original timestamp. Triggers without a version predate versioning and
+// decode as version 0, which has the same layout as version 1.
+func Unmarshal(data []byte) (llms.TriggerV0, error) {
+	var encoded encodedTrigger
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trigger: %w", err)
+	}
+	if encoded.Version > WireVersion {
+		return nil, fmt.Errorf("%w: %d, newest known is %d", ErrUnsupportedWireVersion, encoded.Version, WireVersion)
+	}
+
+	base := BaseTrigger{timestamp: encoded.Timestamp, speakerID: encoded.Speaker}
+	switch encoded.Type {
+	case TypeUserPrompt:
+		return decodeTriggerData(encoded, func(t *UserPromptTrigger) { t.BaseTrigger = base })
+	case TypeTranscription, TypeInterimTranscription:
+		var decoded transcriptData
+		if err := json.Unmarshal(encoded.Data, &decoded); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s trigger: %w", encoded.Type, err)
+		}
+		if encoded.Type == TypeInterimTranscription {
+			return InterimTranscriptionTrigger{BaseTrigger: base, transcript: decoded.Transcript}, nil
+		}
+		return TranscriptionTrigger{BaseTrigger: base, transcript: decoded.Transcript, DuringPlayback: decoded.DuringPlayback}, nil
+	case TypeSpeechStarted:
+		var decoded speechStartedData
+		if len(encoded.Data) > 0 {
+			if err := json.Unmarshal(encoded.Data, &decoded); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal %s trigger: %w", encoded.Type, err)
+			}
+		}
+		return SpeechStartedTrigger{BaseTrigger: base, DuringPlayback: decoded.DuringPlayback}, nil
+	case TypeSpeechEnded:
+		return SpeechEndedTrigger{BaseTrigger: base}, nil
+	case TypeCancelTurn:
+		return CancelTurnTrigger{BaseTrigger: base}, nil
+	case TypePauseTurn:
+		return PauseTurnTrigger{BaseTrigger: base}, nil
+	case TypeUnpauseTurn:
+		return UnpauseTurnTrigger{BaseTrigger: base}, nil
+	case TypeCallTool:
+		return decodeTriggerData(encoded, func(t *CallToolTrigger) { t.BaseTrigger = base })
+	case TypeRecordInterruption:
+		return decodeTriggerData(encoded, func(t *RecordInterruptionTrigger) { t.BaseTrigger = base })
+	case TypeResolveInterruption:
+		return decodeTriggerData(encoded, func(t *ResolveInterruptionTrigger) { t.BaseTrigger = base })
+	case TypeAssistantInitiated:
+		return decodeTriggerData(encoded, func(t *AssistantInitiatedTrigger) { t.BaseTrigger = base })
+	case TypeContext:
+		return decodeTriggerData(encoded, func(t *ContextTrigger) { t.BaseTrigger = base })
+	default:
+		custom, ok := registeredTrigger(encoded.Type)
+		if !ok {
+			return nil, fmt.Errorf("unsupported trigger type %q", encoded.Type)
+		}
+		return custom.decode(encoded.Data, base)
+	}
+}
+
+func decodeTriggerData[T llms.TriggerV0](encoded encodedTrigger, rebase func(*T)) (llms.TriggerV0, error) {
+	var decoded T
+	if len(encoded.Data) > 0 {
+		if err := json.Unmarshal(encoded.Data, &decoded); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s trigger: %w", encoded.Type, err)
+		}
+	}
+	rebase(&decoded)
+	return decoded, nil
+}
@@ -0,0 +1,113 @@
+package triggers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+func TestMarshalRoundTripPreservesTriggers(t *testing.T) {
+	base := BaseTrigger{timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	testCases := []struct {
+		name    string
+		trigger llms.TriggerV0
+	}{
+		{name: "user prompt", trigger: NewTranscribedUserPromptTrigger("hello", WithBase(base))},
+		{name: "transcription", trigger: NewTranscriptionTrigger("hello there", WithBase(base))},
+		{name: "attributed transcription", trigger: NewTranscriptionTrigger("hello there", WithBase(base), WithSpeaker("alice"))},
+		{name: "transcription during playback", trigger: func() llms.TriggerV0 {
+			trigger := NewTranscriptionTrigger("okay", WithBase(base))
+			trigger.DuringPlayback = true
+			return trigger
+		}()},
+		{name: "interim transcription", trigger: NewInterimTranscriptionTrigger("hello", WithBase(base))},
+		{name: "speech started", trigger: NewSpeechStartedTrigger(WithBase(base))},
+		{name: "cancel turn", trigger: NewCancelTurnTrigger(WithBase(base))},
+		{name: "call tool", trigger: NewCallToolTrigger(llms.ToolCall{ID: "1", Name: "lookup", Arguments: "{}"}, WithBase(base))},
+		{name: "resolve interruption", trigger: NewResolveInterruptionTrigger(7, "clarification", true, WithBase(base))},
+		{name: "assistant initiated", trigger: NewAssistantInitiatedTrigger("greet the user", WithBase(base))},
+		{name: "context", trigger: NewContextTrigger("payment webhook", "payment confirmed", WithBase(base))},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			encoded, err := Marshal(testCase.trigger)
+			if err != nil {
+				t.Fatalf("unexpected marshal error: %v", err)
+			}
+
+			decoded, err := Unmarshal(encoded)
+			if err != nil {
+				t.Fatalf("unexpected unmarshal error: %v", err)
+			}
+
+			if decoded.String() != testCase.trigger.String() {
+				t.Fatalf("expected %q, got %q", testCase.trigger.String(), decoded.String())
+			}
+			if got := decoded.(interface{ Timestamp() time.Time }).Timestamp(); !got.Equal(base.Timestamp()) {
+				t.Fatalf("expected timestamp %v, got %v", base.Timestamp(), got)
+			}
+			if expected, got := testCase.trigger.(interface{ SpeakerID() string }).SpeakerID(), decoded.(interface{ SpeakerID() string }).SpeakerID(); got != expected {
+				t.Fatalf("expected speaker %q, got %q", expected, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalRejectsUnknownType(t *testing.T) {
+	if _, err := Unmarshal([]byte(`{"type":"unknown"}`)); err == nil {
+		t.Fatalf("expected error for unknown trigger type")
+	}
+}
+
+func TestUnmarshalAcceptsUnversionedTriggers(t *testing.T) {
+	decoded, err := Unmarshal([]byte(`{"type":"transcription","timestamp":"2026-01-02T03:04:05Z","data":{"transcript":"hello"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transcription, ok := decoded.(TranscriptionTrigger); !ok || transcription.transcript != "hello" {
+		t.Fatalf("expected transcription trigger, got %#v", decoded)
+	}
+}
+
+func TestUnmarshalRejectsNewerVersions(t *testing.T) {
+	if _, err := Unmarshal([]byte(`{"version":2,"type":"cancel_turn"}`)); !errors.Is(err, ErrUnsupportedWireVersion) {
+		t.Fatalf("expected unsupported wire version error, got %v", err)
+	}
+}
+
+type orderShippedTrigger struct {
+	BaseTrigger
+	OrderID string
+}
+
+func (t orderShippedTrigger) String() string { return "order " + t.OrderID + " shipped" }
+
+func TestMarshalRoundTripPreservesRegisteredTriggers(t *testing.T) {
+	Register[orderShippedTrigger]("test_order_shipped")
+	trigger := orderShippedTrigger{BaseTrigger: BaseTrigger{timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}, OrderID: "42"}
+
+	encoded, err := Marshal(trigger)
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+
+	if decoded != trigger {
+		t.Fatalf("expected %#v, got %#v", trigger, decoded)
+	}
+}
+
+func TestRegisterRejectsBuiltinTypeNames(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registering a built-in type name to panic")
+		}
+	}()
+	Register[orderShippedTrigger](TypeUserPrompt)
+}
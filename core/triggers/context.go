@@ -0,0 +1,35 @@
+package triggers
+
+// ContextTrigger adds a fact to the conversation that did not come from the
+// user, e.g. a payment webhook confirming a payment or the result of a tool
+// that finished in the background.
+type ContextTrigger struct {
+	BaseTrigger
+	// Source is where the fact comes from, e.g. "payment webhook".
+	Source  string
+	Content string
+	// Respond starts a turn the assistant responds to the fact in, without it
+	// the fact is only added to the history for later turns.
+	Respond bool
+}
+
+// String is what the LLM receives in place of a user message.
+func (t ContextTrigger) String() string {
+	if t.Source == "" {
+		return "[Context, not said by the user: " + t.Content + "]"
+	}
+	return "[Context from " + t.Source + ", not said by the user: " + t.Content + "]"
+}
+
+func NewContextTrigger(source, content string, opts ...RebaseOption) ContextTrigger {
+	base := NewBaseTrigger()
+	for _, opt := range opts {
+		opt(&base)
+	}
+
+	return ContextTrigger{
+		BaseTrigger: base,
+		Source:      source,
+		Content:     content,
+	}
+}
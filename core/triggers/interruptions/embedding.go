@@ -0,0 +1,270 @@
+package interruptions
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/koscakluka/ema-core/core/conversations"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+// Embedder turns texts into embedding vectors, one per text in the same
+// order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// DefaultExemplars are the phrases interruptions are compared against when
+// no exemplars are set with [WithExemplars]. Interruptions similar to none
+// of them are new prompts.
+var DefaultExemplars = map[string][]string{
+	string(InterruptionTypeCancellation): {
+		"stop", "stop talking", "cancel that", "never mind", "forget it", "be quiet", "that's enough",
+	},
+	string(InterruptionTypeClarification): {
+		"what do you mean", "can you explain that", "sorry, what was that", "I don't understand", "say that again",
+	},
+	string(InterruptionTypeContinuation): {
+		"and also", "I mean", "and another thing", "oh and", "also make it",
+	},
+	string(InterruptionTypeIgnorable): {
+		"okay", "uh huh", "yeah", "right", "go on", "I see", "mhm", "got it",
+	},
+}
+
+// EmbeddingTriggerHandler resolves interruptions like [TriggerHandler], but
+// classifies them by their similarity to exemplar phrases instead of
+// prompting an LLM, so barge-ins are resolved without a round trip to one.
+//
+// Before embedding, short fillers are classified as noise and interruptions
+// repeating the sentence the assistant is saying, e.g. the assistant heard
+// through the microphone, as repetitions.
+type EmbeddingTriggerHandler struct {
+	embedder Embedder
+	options  EmbeddingOptions
+
+	exemplarsMu sync.Mutex
+	exemplars   []embeddedExemplar
+}
+
+type embeddedExemplar struct {
+	classification interruptionType
+	embedding      []float32
+}
+
+// NewEmbeddingTriggerHandler creates a handler classifying interruptions with
+// embeddings of embedder. Exemplars are embedded on the first interruption.
+func NewEmbeddingTriggerHandler(embedder Embedder, opts ...EmbeddingOption) *EmbeddingTriggerHandler {
+	options := EmbeddingOptions{
+		exemplars:           DefaultExemplars,
+		similarityThreshold: 0.75,
+		repetitionOverlap:   0.8,
+		maxNoiseWords:       1,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &EmbeddingTriggerHandler{embedder: embedder, options: options}
+}
+
+func (h *EmbeddingTriggerHandler) HandleTriggerV0(ctx context.Context, trigger llms.TriggerV0, conversation conversations.ActiveContextV0) iter.Seq2[llms.TriggerV0, error] {
+	var classifier interruptionClassifier
+	if h != nil && h.embedder != nil {
+		classifier = h.classify
+	}
+	return handleTrigger(ctx, trigger, conversation, classifier)
+}
+
+func (h *EmbeddingTriggerHandler) classify(ctx context.Context, interruption llms.InterruptionV0, conversation conversations.ActiveContextV0) (*llms.InterruptionV0, error) {
+	words := tokenize(interruption.Source)
+	if len(words) == 0 || (len(words) <= h.options.maxNoiseWords && isFiller(words)) {
+		interruption.Type = string(InterruptionTypeNoise)
+		return &interruption, nil
+	}
+
+	if sentence := currentSentence(conversation.ActiveTurn()); sentence != "" {
+		if overlap(words, tokenize(sentence)) >= h.options.repetitionOverlap {
+			interruption.Type = string(InterruptionTypeRepetition)
+			return &interruption, nil
+		}
+	}
+
+	exemplars, err := h.embeddedExemplars(ctx)
+	if err != nil {
+		return &interruption, err
+	}
+
+	embeddings, err := h.embedder.Embed(ctx, []string{interruption.Source})
+	if err != nil {
+		return &interruption, fmt.Errorf("failed to embed interruption: %w", err)
+	} else if len(embeddings) != 1 {
+		return &interruption, fmt.Errorf("expected 1 interruption embedding, got %d", len(embeddings))
+	}
+
+	interruption.Type = string(InterruptionTypeNewPrompt)
+	bestSimilarity := 0.0
+	for _, exemplar := range exemplars {
+		similarity := cosineSimilarity(embeddings[0], exemplar.embedding)
+		if similarity >= h.options.similarityThreshold && similarity > bestSimilarity {
+			bestSimilarity = similarity
+			interruption.Type = string(exemplar.classification)
+		}
+	}
+	return &interruption, nil
+}
+
+// embeddedExemplars embeds the exemplars once, retrying on later
+// interruptions if embedding them fails.
+func (h *EmbeddingTriggerHandler) embeddedExemplars(ctx context.Context) ([]embeddedExemplar, error) {
+	h.exemplarsMu.Lock()
+	defer h.exemplarsMu.Unlock()
+
+	if h.exemplars != nil {
+		return h.exemplars, nil
+	}
+
+	var phrases []string
+	var classifications []interruptionType
+	for classification, exemplars := range h.options.exemplars {
+		for _, phrase := range exemplars {
+			phrases = append(phrases, phrase)
+			classifications = append(classifications, interruptionType(classification))
+		}
+	}
+	if len(phrases) == 0 {
+		h.exemplars = []embeddedExemplar{}
+		return h.exemplars, nil
+	}
+
+	embeddings, err := h.embedder.Embed(ctx, phrases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed exemplars: %w", err)
+	} else if len(embeddings) != len(phrases) {
+		return nil, fmt.Errorf("expected %d exemplar embeddings, got %d", len(phrases), len(embeddings))
+	}
+
+	exemplars := make([]embeddedExemplar, len(phrases))
+	for i := range phrases {
+		exemplars[i] = embeddedExemplar{classification: classifications[i], embedding: embeddings[i]}
+	}
+	h.exemplars = exemplars
+	return h.exemplars, nil
+}
+
+type EmbeddingOptions struct {
+	exemplars           map[string][]string
+	similarityThreshold float64
+	repetitionOverlap   float64
+	maxNoiseWords       int
+}
+
+type EmbeddingOption func(*EmbeddingOptions)
+
+// WithExemplars replaces [DefaultExemplars] with phrases per interruption
+// type, e.g. "action" phrases matching the tools of the assistant.
+func WithExemplars(exemplars map[string][]string) EmbeddingOption {
+	return func(o *EmbeddingOptions) {
+		o.exemplars = exemplars
+	}
+}
+
+// WithSimilarityThreshold sets the cosine similarity an interruption needs
+// to an exemplar to be classified like it, 0.75 by default.
+func WithSimilarityThreshold(threshold float64) EmbeddingOption {
+	return func(o *EmbeddingOptions) {
+		o.similarityThreshold = threshold
+	}
+}
+
+// WithRepetitionOverlap sets the share of words of an interruption that have
+// to be in the sentence the assistant is saying for it to be classified as a
+// repetition, 0.8 by default.
+func WithRepetitionOverlap(overlap float64) EmbeddingOption {
+	return func(o *EmbeddingOptions) {
+		o.repetitionOverlap = overlap
+	}
+}
+
+// WithMaxNoiseWords sets up to how many filler words, e.g. "um", an
+// interruption is classified as noise, 1 by default.
+func WithMaxNoiseWords(words int) EmbeddingOption {
+	return func(o *EmbeddingOptions) {
+		o.maxNoiseWords = words
+	}
+}
+
+var fillerWords = map[string]bool{
+	"uh": true, "um": true, "uhm": true, "erm": true, "hmm": true, "hm": true, "ah": true, "eh": true, "oh": true,
+}
+
+func isFiller(words []string) bool {
+	for _, word := range words {
+		if !fillerWords[word] {
+			return false
+		}
+	}
+	return true
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+}
+
+// currentSentence returns the last sentence of the latest response of turn.
+func currentSentence(turn *llms.TurnV1) string {
+	if turn == nil || len(turn.Responses) == 0 {
+		return ""
+	}
+
+	response := turn.Responses[len(turn.Responses)-1]
+	text := response.SpokenResponse
+	if text == "" {
+		text = response.Message
+	}
+	text = strings.TrimRight(strings.TrimSpace(text), ".!?")
+	if i := strings.LastIndexAny(text, ".!?"); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(text)
+}
+
+// overlap returns the share of words found in sentence.
+func overlap(words, sentence []string) float64 {
+	inSentence := make(map[string]bool, len(sentence))
+	for _, word := range sentence {
+		inSentence[word] = true
+	}
+
+	found := 0
+	for _, word := range words {
+		if inSentence[word] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(words))
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
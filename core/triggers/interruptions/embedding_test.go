@@ -0,0 +1,93 @@
+package interruptions
+
+import (
+	"context"
+	"hash/fnv"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/llms"
+	coretriggers "github.com/koscakluka/ema-core/core/triggers"
+)
+
+// wordEmbedder embeds texts as bags of hashed words.
+type wordEmbedder struct {
+	calls int
+}
+
+func (e *wordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = make([]float32, 1024)
+		for _, word := range tokenize(text) {
+			hash := fnv.New32a()
+			hash.Write([]byte(word))
+			embeddings[i][hash.Sum32()%1024]++
+		}
+	}
+	return embeddings, nil
+}
+
+type conversationStub struct {
+	activeTurn *llms.TurnV1
+}
+
+func (c conversationStub) History() []llms.TurnV1      { return nil }
+func (c conversationStub) ActiveTurn() *llms.TurnV1    { return c.activeTurn }
+func (c conversationStub) AvailableTools() []llms.Tool { return nil }
+
+func TestEmbeddingClassification(t *testing.T) {
+	embedder := &wordEmbedder{}
+	handler := NewEmbeddingTriggerHandler(embedder)
+	conversation := conversationStub{activeTurn: &llms.TurnV1{
+		Trigger:   coretriggers.NewUserPromptTrigger("what's the weather like"),
+		Responses: []llms.TurnResponseV0{{SpokenResponse: "Let me check. The weather in Paris is sunny today"}},
+	}}
+
+	for source, expected := range map[string]interruptionType{
+		"um":                         InterruptionTypeNoise,
+		"Paris is sunny today.":      InterruptionTypeRepetition,
+		"Stop!":                      InterruptionTypeCancellation,
+		"uh huh":                     InterruptionTypeIgnorable,
+		"What do you mean?":          InterruptionTypeClarification,
+		"book me a flight to Lisbon": InterruptionTypeNewPrompt,
+	} {
+		classified, err := handler.classify(context.Background(), llms.InterruptionV0{Source: source}, conversation)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", source, err)
+		}
+		if classified.Type != string(expected) {
+			t.Errorf("%q: expected %q, got %q", source, expected, classified.Type)
+		}
+	}
+
+	// exemplars and one interruption per classification past the heuristics
+	if embedder.calls != 5 {
+		t.Fatalf("expected exemplars to be embedded once, got %d embed calls", embedder.calls)
+	}
+}
+
+func TestEmbeddingHandlerResolvesInterruptions(t *testing.T) {
+	handler := NewEmbeddingTriggerHandler(&wordEmbedder{})
+	conversation := conversationStub{activeTurn: &llms.TurnV1{
+		Trigger: coretriggers.NewUserPromptTrigger("tell me a story"),
+	}}
+
+	var handled []llms.TriggerV0
+	for trigger, err := range handler.HandleTriggerV0(context.Background(), coretriggers.NewUserPromptTrigger("never mind"), conversation) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		handled = append(handled, trigger)
+	}
+
+	if len(handled) != 3 {
+		t.Fatalf("expected record, cancel and resolve triggers, got %#v", handled)
+	}
+	if _, ok := handled[1].(coretriggers.CancelTurnTrigger); !ok {
+		t.Fatalf("expected the turn to be cancelled, got %#v", handled[1])
+	}
+	if resolved, ok := handled[2].(coretriggers.ResolveInterruptionTrigger); !ok || resolved.Type != string(InterruptionTypeCancellation) {
+		t.Fatalf("expected a resolved cancellation, got %#v", handled[2])
+	}
+}
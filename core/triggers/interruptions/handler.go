@@ -24,6 +24,31 @@ func NewTriggerHandlerWithGeneralPrompt(classificationLLM LLMWithGeneralPrompt)
 }
 
 func (h *TriggerHandler) HandleTriggerV0(ctx context.Context, trigger llms.TriggerV0, conversation conversations.ActiveContextV0) iter.Seq2[llms.TriggerV0, error] {
+	var classifier interruptionClassifier
+	if h != nil && h.llm != nil {
+		classifier = func(ctx context.Context, interruption llms.InterruptionV0, conversation conversations.ActiveContextV0) (*llms.InterruptionV0, error) {
+			history := conversation.History()
+			if activeTurn := conversation.ActiveTurn(); activeTurn != nil {
+				history = append(history, *activeTurn)
+			}
+
+			return classify(ctx, interruption, h.llm,
+				WithHistory(history),
+				WithTools(conversation.AvailableTools()),
+			)
+		}
+	}
+	return handleTrigger(ctx, trigger, conversation, classifier)
+}
+
+// interruptionClassifier sets the type of interruption made during the active
+// turn of conversation.
+type interruptionClassifier func(ctx context.Context, interruption llms.InterruptionV0, conversation conversations.ActiveContextV0) (*llms.InterruptionV0, error)
+
+// handleTrigger records triggers arriving during an active turn as
+// interruptions and resolves them by the type classify gives them. Without a
+// classifier triggers are passed through.
+func handleTrigger(ctx context.Context, trigger llms.TriggerV0, conversation conversations.ActiveContextV0, classify interruptionClassifier) iter.Seq2[llms.TriggerV0, error] {
 	return func(yield func(llms.TriggerV0, error) bool) {
 		trigger = normalizeTrigger(trigger)
 		if shouldIgnoreTrigger(trigger) {
@@ -36,7 +61,7 @@ func (h *TriggerHandler) HandleTriggerV0(ctx context.Context, trigger llms.Trigg
 			return
 		}
 
-		if h == nil || h.llm == nil {
+		if classify == nil {
 			yield(trigger, nil)
 			return
 		}
@@ -55,15 +80,7 @@ func (h *TriggerHandler) HandleTriggerV0(ctx context.Context, trigger llms.Trigg
 			return
 		}
 
-		history := conversation.History()
-		if activeTurn := conversation.ActiveTurn(); activeTurn != nil {
-			history = append(history, *activeTurn)
-		}
-
-		classified, err := classify(ctx, interruption, h.llm,
-			WithHistory(history),
-			WithTools(conversation.AvailableTools()),
-		)
+		classified, err := classify(ctx, interruption, conversation)
 		if err != nil {
 			if !yield(trigger, nil) {
 				return
@@ -0,0 +1,228 @@
+// Package redisqueue provides a trigger queue backed by a Redis stream.
+//
+// Triggers are appended to the stream with XADD and consumed through a
+// consumer group, so a trigger stays pending until the orchestrator
+// acknowledges it after processing its turn. When an instance crashes, any
+// trigger it did not acknowledge is claimed (XAUTOCLAIM) by the next
+// consumer of the group once it has been idle for the claim timeout, which
+// lets another instance resume the conversation.
+//
+// The package does not depend on a Redis client library, the application
+// adapts its client to [StreamsClient]. With go-redis that is a thin wrapper
+// around XAdd, XGroupCreateMkStream, XReadGroup, XAutoClaim and XAck.
+//
+//	queue, err := redisqueue.NewQueue(ctx, client, "ema:conversation:"+id)
+//	o := orchestration.NewOrchestrator(orchestration.WithTriggerQueueV0(queue))
+package redisqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	orchestration "github.com/koscakluka/ema-core/core"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+const (
+	triggerField  = "trigger"
+	queuedAtField = "queued_at"
+
+	defaultGroup        = "ema"
+	defaultConsumer     = "ema"
+	defaultBlock        = 5 * time.Second
+	defaultClaimTimeout = 30 * time.Second
+)
+
+// StreamMessage is a single Redis stream entry.
+type StreamMessage struct {
+	ID     string
+	Fields map[string]string
+}
+
+// StreamsClient is the subset of Redis stream commands used by the queue.
+type StreamsClient interface {
+	// XAdd appends an entry to stream and returns its ID.
+	XAdd(ctx context.Context, stream string, fields map[string]string) (string, error)
+	// XGroupCreate creates the consumer group reading the stream from start,
+	// creating the stream if needed. It must not fail when the group already
+	// exists.
+	XGroupCreate(ctx context.Context, stream, group, start string) error
+	// XReadGroup reads at most one new entry (">") for consumer, blocking up
+	// to block. It returns no messages when the block times out.
+	XReadGroup(ctx context.Context, stream, group, consumer string, block time.Duration) ([]StreamMessage, error)
+	// XAutoClaim claims at most one pending entry idle for at least minIdle
+	// for consumer.
+	XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration) ([]StreamMessage, error)
+	// XAck acknowledges entries of the group.
+	XAck(ctx context.Context, stream, group string, ids ...string) error
+}
+
+// Queue is a [orchestration.TriggerQueueV0] backed by a Redis stream.
+type Queue struct {
+	client       StreamsClient
+	stream       string
+	group        string
+	consumer     string
+	block        time.Duration
+	claimTimeout time.Duration
+
+	// shouldClaim is set whenever the stream runs dry so that abandoned
+	// pending entries are picked up before blocking again.
+	shouldClaim bool
+	mu          sync.Mutex
+}
+
+type QueueOptions struct {
+	group        string
+	consumer     string
+	block        time.Duration
+	claimTimeout time.Duration
+}
+
+type QueueOption func(*QueueOptions)
+
+// WithGroup sets the consumer group name, defaults to "ema".
+func WithGroup(group string) QueueOption {
+	return func(o *QueueOptions) {
+		o.group = group
+	}
+}
+
+// WithConsumer sets the consumer name of this instance within the group,
+// defaults to "ema". Instances sharing a stream should use distinct names.
+func WithConsumer(consumer string) QueueOption {
+	return func(o *QueueOptions) {
+		o.consumer = consumer
+	}
+}
+
+// WithBlock sets how long a single read blocks waiting for new entries.
+func WithBlock(block time.Duration) QueueOption {
+	return func(o *QueueOptions) {
+		o.block = block
+	}
+}
+
+// WithClaimTimeout sets how long an entry has to stay unacknowledged before
+// it is considered abandoned and claimed by this instance.
+func WithClaimTimeout(timeout time.Duration) QueueOption {
+	return func(o *QueueOptions) {
+		o.claimTimeout = timeout
+	}
+}
+
+// NewQueue creates a queue on stream, creating the consumer group if needed.
+func NewQueue(ctx context.Context, client StreamsClient, stream string, opts ...QueueOption) (*Queue, error) {
+	options := QueueOptions{
+		group:        defaultGroup,
+		consumer:     defaultConsumer,
+		block:        defaultBlock,
+		claimTimeout: defaultClaimTimeout,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if client == nil {
+		return nil, fmt.Errorf("redis streams client is required")
+	}
+	if stream == "" {
+		return nil, fmt.Errorf("stream name is required")
+	}
+
+	if err := client.XGroupCreate(ctx, stream, options.group, "0"); err != nil {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	return &Queue{
+		client:       client,
+		stream:       stream,
+		group:        options.group,
+		consumer:     options.consumer,
+		block:        options.block,
+		claimTimeout: options.claimTimeout,
+		shouldClaim:  true,
+	}, nil
+}
+
+func (q *Queue) Enqueue(ctx context.Context, trigger llms.TriggerV0) error {
+	encoded, err := triggers.Marshal(trigger)
+	if err != nil {
+		return err
+	}
+
+	if _, err := q.client.XAdd(ctx, q.stream, map[string]string{
+		triggerField:  string(encoded),
+		queuedAtField: time.Now().UTC().Format(time.RFC3339Nano),
+	}); err != nil {
+		return fmt.Errorf("failed to add trigger to stream: %w", err)
+	}
+	return nil
+}
+
+func (q *Queue) Dequeue(ctx context.Context) (orchestration.QueuedTriggerV0, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return orchestration.QueuedTriggerV0{}, err
+		}
+
+		q.mu.Lock()
+		shouldClaim := q.shouldClaim
+		q.shouldClaim = false
+		q.mu.Unlock()
+
+		var messages []StreamMessage
+		var err error
+		if shouldClaim {
+			messages, err = q.client.XAutoClaim(ctx, q.stream, q.group, q.consumer, q.claimTimeout)
+			if err != nil {
+				return orchestration.QueuedTriggerV0{}, fmt.Errorf("failed to claim pending triggers: %w", err)
+			}
+		}
+		if len(messages) == 0 {
+			messages, err = q.client.XReadGroup(ctx, q.stream, q.group, q.consumer, q.block)
+			if err != nil {
+				return orchestration.QueuedTriggerV0{}, fmt.Errorf("failed to read triggers: %w", err)
+			}
+		}
+		if len(messages) == 0 {
+			q.mu.Lock()
+			q.shouldClaim = true
+			q.mu.Unlock()
+			continue
+		}
+
+		queued, err := decodeMessage(messages[0])
+		if err != nil {
+			// An undecodable entry would be redelivered forever, drop it.
+			ackErr := q.client.XAck(ctx, q.stream, q.group, messages[0].ID)
+			return orchestration.QueuedTriggerV0{}, errors.Join(err, ackErr)
+		}
+		return queued, nil
+	}
+}
+
+func (q *Queue) Ack(ctx context.Context, id string) error {
+	if err := q.client.XAck(ctx, q.stream, q.group, id); err != nil {
+		return fmt.Errorf("failed to acknowledge trigger: %w", err)
+	}
+	return nil
+}
+
+func decodeMessage(message StreamMessage) (orchestration.QueuedTriggerV0, error) {
+	trigger, err := triggers.Unmarshal([]byte(message.Fields[triggerField]))
+	if err != nil {
+		return orchestration.QueuedTriggerV0{}, fmt.Errorf("failed to decode stream entry %s: %w", message.ID, err)
+	}
+
+	queuedAt, err := time.Parse(time.RFC3339Nano, message.Fields[queuedAtField])
+	if err != nil {
+		queuedAt = time.Now()
+	}
+
+	return orchestration.QueuedTriggerV0{ID: message.ID, Trigger: trigger, QueuedAt: queuedAt}, nil
+}
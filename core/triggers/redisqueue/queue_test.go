@@ -0,0 +1,134 @@
+package redisqueue
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestQueueDeliversEnqueuedTriggers(t *testing.T) {
+	client := newFakeStreamsClient()
+	queue, err := NewQueue(context.Background(), client, "conversation", WithBlock(time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := queue.Enqueue(context.Background(), triggers.NewUserPromptTrigger("hello")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	queued, err := queue.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queued.Trigger.String() != "hello" {
+		t.Fatalf("expected hello prompt, got %q", queued.Trigger.String())
+	}
+
+	if err := queue.Ack(ctx, queued.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pending := client.pendingCount(); pending != 0 {
+		t.Fatalf("expected no pending entries after ack, got %d", pending)
+	}
+}
+
+func TestQueueClaimsTriggersAbandonedByCrashedConsumer(t *testing.T) {
+	client := newFakeStreamsClient()
+	crashed, err := NewQueue(context.Background(), client, "conversation", WithConsumer("a"), WithBlock(time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = crashed.Enqueue(context.Background(), triggers.NewUserPromptTrigger("unfinished"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := crashed.Dequeue(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resumed, err := NewQueue(context.Background(), client, "conversation", WithConsumer("b"),
+		WithBlock(time.Millisecond), WithClaimTimeout(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	queued, err := resumed.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queued.Trigger.String() != "unfinished" {
+		t.Fatalf("expected abandoned trigger to be claimed, got %q", queued.Trigger.String())
+	}
+}
+
+// fakeStreamsClient implements the stream commands for a single consumer
+// group in memory.
+type fakeStreamsClient struct {
+	mu      sync.Mutex
+	entries []StreamMessage
+	next    int
+	pending map[string]string // id -> consumer
+	lastID  int
+}
+
+func newFakeStreamsClient() *fakeStreamsClient {
+	return &fakeStreamsClient{pending: map[string]string{}}
+}
+
+func (c *fakeStreamsClient) XAdd(_ context.Context, _ string, fields map[string]string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastID++
+	id := strconv.Itoa(c.lastID) + "-0"
+	c.entries = append(c.entries, StreamMessage{ID: id, Fields: fields})
+	return id, nil
+}
+
+func (c *fakeStreamsClient) XGroupCreate(context.Context, string, string, string) error {
+	return nil
+}
+
+func (c *fakeStreamsClient) XReadGroup(_ context.Context, _, _, consumer string, _ time.Duration) ([]StreamMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.next >= len(c.entries) {
+		return nil, nil
+	}
+	message := c.entries[c.next]
+	c.next++
+	c.pending[message.ID] = consumer
+	return []StreamMessage{message}, nil
+}
+
+func (c *fakeStreamsClient) XAutoClaim(_ context.Context, _, _, consumer string, _ time.Duration) ([]StreamMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, message := range c.entries {
+		if owner, ok := c.pending[message.ID]; ok && owner != consumer {
+			c.pending[message.ID] = consumer
+			return []StreamMessage{message}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *fakeStreamsClient) XAck(_ context.Context, _, _ string, ids ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		delete(c.pending, id)
+	}
+	return nil
+}
+
+func (c *fakeStreamsClient) pendingCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
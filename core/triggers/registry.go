@@ -0,0 +1,80 @@
+package triggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+type customTrigger struct {
+	decode func(data json.RawMessage, base BaseTrigger) (llms.TriggerV0, error)
+}
+
+var (
+	customTriggersMu sync.RWMutex
+	customTriggers   = map[string]customTrigger{}
+	customTypeNames  = map[reflect.Type]string{}
+)
+
+var builtinTypeNames = []string{
+	TypeUserPrompt, TypeTranscription, TypeInterimTranscription, TypeSpeechStarted, TypeSpeechEnded,
+	TypeCancelTurn, TypePauseTurn, TypeUnpauseTurn, TypeCallTool, TypeRecordInterruption, TypeResolveInterruption,
+}
+
+// Register makes triggers of type T, defined outside this package,
+// serializable by [Marshal] and [Unmarshal] under the type name typ. The
+// exported fields of T are encoded as JSON, the timestamp is kept when T
+// embeds [BaseTrigger].
+//
+// Register is meant to be called from init, it panics when typ or T are
+// already registered.
+func Register[T llms.TriggerV0](typ string) {
+	customTriggersMu.Lock()
+	defer customTriggersMu.Unlock()
+
+	triggerType := reflect.TypeFor[T]()
+	if _, ok := customTriggers[typ]; ok || slices.Contains(builtinTypeNames, typ) {
+		panic(fmt.Sprintf("triggers: type name %q registered twice", typ))
+	}
+	if previous, ok := customTypeNames[triggerType]; ok {
+		panic(fmt.Sprintf("triggers: %v already registered as %q", triggerType, previous))
+	}
+
+	customTypeNames[triggerType] = typ
+	customTriggers[typ] = customTrigger{
+		decode: func(data json.RawMessage, base BaseTrigger) (llms.TriggerV0, error) {
+			var decoded T
+			if len(data) > 0 {
+				if err := json.Unmarshal(data, &decoded); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal %s trigger: %w", typ, err)
+				}
+			}
+			if field := reflect.ValueOf(&decoded).Elem(); field.Kind() == reflect.Struct {
+				if baseField := field.FieldByName("BaseTrigger"); baseField.IsValid() && baseField.Type() == reflect.TypeFor[BaseTrigger]() {
+					baseField.Set(reflect.ValueOf(base))
+				}
+			}
+			return decoded, nil
+		},
+	}
+}
+
+func registeredTypeName(trigger llms.TriggerV0) (string, bool) {
+	customTriggersMu.RLock()
+	defer customTriggersMu.RUnlock()
+
+	typ, ok := customTypeNames[reflect.TypeOf(trigger)]
+	return typ, ok
+}
+
+func registeredTrigger(typ string) (customTrigger, bool) {
+	customTriggersMu.RLock()
+	defer customTriggersMu.RUnlock()
+
+	custom, ok := customTriggers[typ]
+	return custom, ok
+}
@@ -1,6 +1,11 @@
 package triggers
 
-type SpeechStartedTrigger struct{ BaseTrigger }
+type SpeechStartedTrigger struct {
+	BaseTrigger
+	// DuringPlayback marks speech that started while the assistant was
+	// playing audio, it might be the assistant hearing itself.
+	DuringPlayback bool
+}
 
 func (t SpeechStartedTrigger) String() string { return "Speech Started" }
 
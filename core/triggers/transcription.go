@@ -20,6 +20,10 @@ func NewInterimTranscriptionTrigger(transcript string, opts ...RebaseOption) Int
 type TranscriptionTrigger struct {
 	BaseTrigger
 	transcript string
+	// DuringPlayback marks transcripts of speech that started while the
+	// assistant was playing audio, they might be the assistant hearing
+	// itself.
+	DuringPlayback bool
 }
 
 func (t TranscriptionTrigger) String() string     { return t.transcript }
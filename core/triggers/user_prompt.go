@@ -4,6 +4,11 @@ type UserPromptTrigger struct {
 	BaseTrigger
 	Prompt        string
 	IsTranscribed bool
+	// IsTyped marks prompts the user typed, in conversations where they
+	// also speak.
+	IsTyped bool
+	// IsTextOnly requests the response in text only, without speech.
+	IsTextOnly bool
 }
 
 func (t UserPromptTrigger) String() string {
@@ -23,6 +28,19 @@ func NewUserPromptTrigger(prompt string, opts ...RebaseOption) UserPromptTrigger
 	}
 }
 
+func NewTypedUserPromptTrigger(prompt string, opts ...RebaseOption) UserPromptTrigger {
+	base := NewBaseTrigger()
+	for _, opt := range opts {
+		opt(&base)
+	}
+
+	return UserPromptTrigger{
+		BaseTrigger: base,
+		Prompt:      prompt,
+		IsTyped:     true,
+	}
+}
+
 func NewTranscribedUserPromptTrigger(prompt string, opts ...RebaseOption) UserPromptTrigger {
 	base := NewBaseTrigger()
 	for _, opt := range opts {
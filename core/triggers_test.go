@@ -0,0 +1,81 @@
+package orchestration
+
+import (
+	"context"
+	"iter"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/conversations"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+type orderShippedTrigger struct {
+	triggers.BaseTrigger
+	OrderID string
+}
+
+func (t orderShippedTrigger) String() string { return "order " + t.OrderID + " shipped" }
+
+func TestCustomTriggerHandlerReplacesTrigger(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	var completed atomic.Bool
+	o := NewOrchestrator(
+		WithLLM(llm),
+		WithCustomTrigger(func(_ context.Context, trigger orderShippedTrigger, _ conversations.ActiveContextV0) iter.Seq2[llms.TriggerV0, error] {
+			return func(yield func(llms.TriggerV0, error) bool) {
+				yield(triggers.NewUserPromptTrigger("Tell the user that order "+trigger.OrderID+" is on its way."), nil)
+			}
+		}),
+	)
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+
+	o.HandleTrigger(orderShippedTrigger{BaseTrigger: triggers.NewBaseTrigger(), OrderID: "42"})
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	if len(llm.prompts) != 1 || llm.prompts[0] != "Tell the user that order 42 is on its way." {
+		t.Fatalf("expected the handled prompt, got %v", llm.prompts)
+	}
+}
+
+func TestCustomTriggerYieldingItselfStartsTurn(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	var completed atomic.Bool
+	o := NewOrchestrator(
+		WithLLM(llm),
+		WithCustomTrigger(func(_ context.Context, trigger orderShippedTrigger, _ conversations.ActiveContextV0) iter.Seq2[llms.TriggerV0, error] {
+			return func(yield func(llms.TriggerV0, error) bool) { yield(trigger, nil) }
+		}),
+	)
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+
+	o.HandleTrigger(orderShippedTrigger{BaseTrigger: triggers.NewBaseTrigger(), OrderID: "7"})
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	history := o.conversation.History()
+	if len(history) != 1 {
+		t.Fatalf("expected a single turn, got %+v", history)
+	}
+	if _, ok := history[0].Trigger.(orderShippedTrigger); !ok {
+		t.Fatalf("expected the custom trigger in the history, got %#v", history[0].Trigger)
+	}
+}
@@ -108,6 +108,13 @@ func (t *textToSpeech) init(ctx context.Context, encodingInfo audio.EncodingInfo
 			texttospeech.WithSpeechMarkCallback(func(transcript string) {
 				emitEvent(events.NewAssistantSpeechMarkGenerated(transcript))
 			}),
+			texttospeech.WithSpeechTimestampsCallback(func(timestamps []texttospeech.WordTimestamp) {
+				words := make([]events.SpeechWordTimestamp, len(timestamps))
+				for i, timestamp := range timestamps {
+					words[i] = events.SpeechWordTimestamp(timestamp)
+				}
+				emitEvent(events.NewAssistantSpeechTimestamps(words))
+			}),
 			texttospeech.WithEncodingInfo(encodingInfo),
 		}
 
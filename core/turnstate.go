@@ -0,0 +1,84 @@
+package orchestration
+
+import (
+	"slices"
+	"sync"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// TurnState is a stage of the turn lifecycle, see [events.TurnState].
+type TurnState = events.TurnState
+
+const (
+	TurnStateQueued     = events.TurnStateQueued
+	TurnStateGenerating = events.TurnStateGenerating
+	TurnStateSpeaking   = events.TurnStateSpeaking
+	TurnStateFinalizing = events.TurnStateFinalizing
+	TurnStateCompleted  = events.TurnStateCompleted
+	TurnStateFailed     = events.TurnStateFailed
+	TurnStateCancelled  = events.TurnStateCancelled
+)
+
+// turnTransitions lists the states a turn can move to from each state.
+// Terminal states have no transitions.
+var turnTransitions = map[TurnState][]TurnState{
+	TurnStateQueued:     {TurnStateGenerating, TurnStateFailed, TurnStateCancelled},
+	TurnStateGenerating: {TurnStateSpeaking, TurnStateFinalizing, TurnStateFailed, TurnStateCancelled},
+	TurnStateSpeaking:   {TurnStateFinalizing, TurnStateFailed, TurnStateCancelled},
+	TurnStateFinalizing: {TurnStateCompleted, TurnStateFailed, TurnStateCancelled},
+}
+
+// turnStateMachine tracks the lifecycle of a single turn and emits a
+// [events.TurnStateChanged] for every transition.
+type turnStateMachine struct {
+	mu     sync.RWMutex
+	turnID string
+	state  TurnState
+
+	emitEvent eventEmitter
+}
+
+func newTurnStateMachine(emitEvent eventEmitter) *turnStateMachine {
+	if emitEvent == nil {
+		emitEvent = noopEventEmitter
+	}
+	return &turnStateMachine{state: TurnStateQueued, emitEvent: emitEvent}
+}
+
+// SetTurnID sets the turn reported in transition events, the ID is only
+// known once the turn is started.
+func (m *turnStateMachine) SetTurnID(turnID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.turnID = turnID
+}
+
+func (m *turnStateMachine) TurnID() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.turnID
+}
+
+func (m *turnStateMachine) Current() TurnState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Transition moves the turn to state and reports whether the transition was
+// valid. Invalid transitions, e.g. completing a cancelled turn, are ignored.
+func (m *turnStateMachine) Transition(to TurnState) bool {
+	m.mu.Lock()
+	from := m.state
+	if !slices.Contains(turnTransitions[from], to) {
+		m.mu.Unlock()
+		return false
+	}
+	m.state = to
+	turnID := m.turnID
+	m.mu.Unlock()
+
+	m.emitEvent(events.NewTurnStateChanged(turnID, from, to))
+	return true
+}
@@ -0,0 +1,99 @@
+package orchestration
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+func TestTurnStateMachineRejectsInvalidTransitions(t *testing.T) {
+	var emitted []events.TurnStateChanged
+	machine := newTurnStateMachine(func(event events.Event) {
+		emitted = append(emitted, event.(events.TurnStateChanged))
+	})
+	machine.SetTurnID("turn-1")
+
+	if machine.Transition(TurnStateCompleted) {
+		t.Fatalf("expected queued turn not to complete")
+	}
+	for _, state := range []TurnState{TurnStateGenerating, TurnStateSpeaking, TurnStateCancelled} {
+		if !machine.Transition(state) {
+			t.Fatalf("expected transition to %s", state)
+		}
+	}
+	if machine.Transition(TurnStateCompleted) || machine.Transition(TurnStateCancelled) {
+		t.Fatalf("expected cancelled turn to stay cancelled")
+	}
+
+	if got := machine.Current(); got != TurnStateCancelled {
+		t.Fatalf("expected cancelled state, got %s", got)
+	}
+	expected := []events.TurnStateChanged{
+		events.NewTurnStateChanged("turn-1", TurnStateQueued, TurnStateGenerating),
+		events.NewTurnStateChanged("turn-1", TurnStateGenerating, TurnStateSpeaking),
+		events.NewTurnStateChanged("turn-1", TurnStateSpeaking, TurnStateCancelled),
+	}
+	if len(emitted) != len(expected) {
+		t.Fatalf("expected %d transition events, got %d", len(expected), len(emitted))
+	}
+	for i, event := range emitted {
+		if event.TurnID != expected[i].TurnID || event.From != expected[i].From || event.To != expected[i].To {
+			t.Fatalf("expected transition %d to be %+v, got %+v", i, expected[i], event)
+		}
+	}
+}
+
+func TestTurnEmitsStateTransitions(t *testing.T) {
+	o := NewOrchestrator(WithLLM(promptLLMStub{response: "response"}))
+	defer o.Close()
+
+	var mu sync.Mutex
+	var states []TurnState
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if changed, ok := event.(events.TurnStateChanged); ok {
+			mu.Lock()
+			states = append(states, changed.To)
+			mu.Unlock()
+		}
+	}))
+
+	o.SendPrompt("hello")
+
+	expected := []TurnState{TurnStateGenerating, TurnStateFinalizing, TurnStateCompleted}
+	waitForCondition(t, 2*time.Second, "turn completed", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Equal(states, expected)
+	})
+	waitForCondition(t, time.Second, "turn state cleared", func() bool { return o.TurnState() == "" })
+}
+
+func TestCancelTurnMovesTurnToCancelled(t *testing.T) {
+	o := NewOrchestrator(WithStreamingLLM(repeatingStreamLLMStub{chunk: "chunk", interval: 10 * time.Millisecond}))
+	defer o.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelled := make(chan struct{})
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if changed, ok := event.(events.TurnStateChanged); ok && changed.To == TurnStateCancelled {
+			close(cancelled)
+		}
+	}))
+
+	o.SendPrompt("please start")
+	waitForCondition(t, 2*time.Second, "turn generating", func() bool { return o.TurnState() == TurnStateGenerating })
+
+	o.CancelTurn()
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for cancelled state")
+	}
+}
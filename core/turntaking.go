@@ -0,0 +1,231 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
+	"github.com/koscakluka/ema-core/core/errorreport"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TurnTakingFeaturesV0 is what a [TurnTakingModelV0] decides the end of the
+// user turn from.
+type TurnTakingFeaturesV0 struct {
+	// Transcript is the final transcript of the user turn so far, the
+	// transcripts the model did not end the turn on joined.
+	Transcript string
+	// Audio is the latest user input audio, see
+	// [WithTurnTakingAudioWindow].
+	Audio    []byte
+	Encoding audio.EncodingInfo
+	// History is the conversation before the user turn.
+	History []llms.TurnV1
+}
+
+// TurnTakingDecisionV0 is the decision of a [TurnTakingModelV0].
+type TurnTakingDecisionV0 struct {
+	// EndOfTurn commits to responding to the transcript.
+	EndOfTurn bool
+	// Wait is how long to wait for the user to continue before responding
+	// anyway when it is not the end of turn, zero waits as long as set with
+	// [WithTurnTakingMaxWait].
+	Wait time.Duration
+}
+
+// TurnTakingModelV0 decides whether the user finished their turn, e.g. an
+// end-of-utterance model telling a pause for thought from a finished
+// question. It is consulted on every final transcript before the trigger
+// handler commits to a response.
+type TurnTakingModelV0 interface {
+	DecideTurnV0(ctx context.Context, features TurnTakingFeaturesV0) (TurnTakingDecisionV0, error)
+}
+
+// EndpointingTurnTakingV0 ends the user turn on every final transcript, i.e.
+// wherever the voice activity detection and endpointing of the speech-to-text
+// client put it. Orchestrators behave like this without a turn-taking model.
+type EndpointingTurnTakingV0 struct{}
+
+func (EndpointingTurnTakingV0) DecideTurnV0(context.Context, TurnTakingFeaturesV0) (TurnTakingDecisionV0, error) {
+	return TurnTakingDecisionV0{EndOfTurn: true}, nil
+}
+
+type TurnTakingOptions struct {
+	maxWait     time.Duration
+	audioWindow time.Duration
+}
+
+type TurnTakingOption func(*TurnTakingOptions)
+
+// WithTurnTakingMaxWait sets how long the user can stay silent after a
+// transcript the model did not end the turn on before it is responded to
+// anyway, 2s by default.
+func WithTurnTakingMaxWait(d time.Duration) TurnTakingOption {
+	return func(o *TurnTakingOptions) {
+		o.maxWait = d
+	}
+}
+
+// WithTurnTakingAudioWindow sets how much of the latest user audio is passed
+// to the model, 5s by default. Zero passes no audio.
+func WithTurnTakingAudioWindow(d time.Duration) TurnTakingOption {
+	return func(o *TurnTakingOptions) {
+		o.audioWindow = d
+	}
+}
+
+// WithTurnTakingModel makes the orchestrator consult model before responding
+// to final transcripts. Transcripts the model does not end the turn on are
+// held and joined with the following ones, until the model ends the turn or
+// the user stays silent for the wait of the decision.
+//
+// If the model fails, the turn ends as with [EndpointingTurnTakingV0].
+func WithTurnTakingModel(model TurnTakingModelV0, opts ...TurnTakingOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if model == nil {
+			o.turnTaking = nil
+			return
+		}
+
+		options := TurnTakingOptions{maxWait: 2 * time.Second, audioWindow: 5 * time.Second}
+		for _, opt := range opts {
+			opt(&options)
+		}
+		o.turnTaking = &turnTaking{TurnTakingOptions: options, model: model}
+	}
+}
+
+// turnTaking holds final transcripts until the model ends the user turn.
+type turnTaking struct {
+	TurnTakingOptions
+	model TurnTakingModelV0
+
+	clock clock.Clock
+	// commit handles the transcription ending the user turn.
+	commit func(llms.TriggerV0)
+
+	mu          sync.Mutex
+	pending     []string
+	pendingBase triggers.BaseTrigger
+	// pendingDuringPlayback is whether the user turn started while the
+	// assistant was playing audio.
+	pendingDuringPlayback bool
+	// transcripts counts the transcripts, so only the decision on the
+	// latest one is acted on.
+	transcripts int
+	// generation changes on any user activity, cancelling waits.
+	generation int
+	audio      []byte
+	encoding   audio.EncodingInfo
+}
+
+// transcribed consults the model on the user turn so far ending with
+// transcription.
+func (t *turnTaking) transcribed(ctx context.Context, transcription triggers.TranscriptionTrigger, history []llms.TurnV1) {
+	t.mu.Lock()
+	if len(t.pending) == 0 {
+		t.pendingBase = transcription.BaseTrigger
+		t.pendingDuringPlayback = transcription.DuringPlayback
+	}
+	t.pending = append(t.pending, transcription.Transcript())
+	t.transcripts++
+	t.generation++
+	id := t.transcripts
+	features := TurnTakingFeaturesV0{
+		Transcript: strings.Join(t.pending, " "),
+		Audio:      append([]byte(nil), t.audio...),
+		Encoding:   t.encoding,
+		History:    history,
+	}
+	t.mu.Unlock()
+
+	decision, err := t.model.DecideTurnV0(ctx, features)
+	if err != nil {
+		errorreport.Record(ctx, trace.SpanFromContext(ctx), fmt.Errorf("turn-taking model failed: %w", err), "component", "turn_taking")
+		decision = TurnTakingDecisionV0{EndOfTurn: true}
+	}
+
+	t.mu.Lock()
+	if id != t.transcripts || len(t.pending) == 0 {
+		// A newer transcript arrived, its decision replaces this one, or the
+		// wait of an earlier decision already responded.
+		t.mu.Unlock()
+		return
+	}
+	if !decision.EndOfTurn {
+		wait := decision.Wait
+		if wait <= 0 {
+			wait = t.maxWait
+		}
+		t.wait(wait, t.generation)
+		t.mu.Unlock()
+		return
+	}
+	trigger := t.takePending()
+	t.mu.Unlock()
+
+	t.commit(trigger)
+}
+
+// speechStarted postpones responding to held transcripts while the user
+// speaks.
+func (t *turnTaking) speechStarted() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.generation++
+	if len(t.pending) > 0 {
+		t.wait(t.maxWait, t.generation)
+	}
+}
+
+// observeAudio keeps the latest user audio for the model.
+func (t *turnTaking) observeAudio(frame []byte, encoding audio.EncodingInfo) {
+	if t.audioWindow <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.encoding = encoding
+	t.audio = append(t.audio, frame...)
+	window := int(t.audioWindow.Seconds() * float64(encoding.SampleRate*max(encoding.Format.ByteSize(), 1)))
+	if excess := len(t.audio) - window; excess > 0 {
+		t.audio = append(t.audio[:0], t.audio[excess:]...)
+	}
+}
+
+// wait responds to the held transcripts after d, unless the user does
+// anything in the meantime. t.mu must be held.
+func (t *turnTaking) wait(d time.Duration, generation int) {
+	go func() {
+		<-t.clock.After(d)
+
+		t.mu.Lock()
+		if generation != t.generation || len(t.pending) == 0 {
+			t.mu.Unlock()
+			return
+		}
+		trigger := t.takePending()
+		t.mu.Unlock()
+
+		t.commit(trigger)
+	}()
+}
+
+// takePending joins the held transcripts into one transcription. t.mu must
+// be held.
+func (t *turnTaking) takePending() triggers.TranscriptionTrigger {
+	trigger := triggers.NewTranscriptionTrigger(strings.Join(t.pending, " "), triggers.WithBase(t.pendingBase))
+	trigger.DuringPlayback = t.pendingDuringPlayback
+	t.pending = nil
+	t.generation++
+	return trigger
+}
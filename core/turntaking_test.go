@@ -0,0 +1,79 @@
+package orchestration
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+// punctuationTurnTaking ends the user turn on transcripts ending a sentence.
+type punctuationTurnTaking struct {
+	mu          sync.Mutex
+	transcripts []string
+}
+
+func (model *punctuationTurnTaking) DecideTurnV0(_ context.Context, features TurnTakingFeaturesV0) (TurnTakingDecisionV0, error) {
+	model.mu.Lock()
+	defer model.mu.Unlock()
+	model.transcripts = append(model.transcripts, features.Transcript)
+	return TurnTakingDecisionV0{EndOfTurn: strings.HasSuffix(features.Transcript, ".")}, nil
+}
+
+func TestTurnTakingModelHoldsUnfinishedTranscripts(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	model := &punctuationTurnTaking{}
+	var completed atomic.Bool
+	o := NewOrchestrator(WithLLM(llm), WithTurnTakingModel(model))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+
+	o.HandleTrigger(triggers.NewTranscriptionTrigger("I'd like to book"))
+	o.HandleTrigger(triggers.NewTranscriptionTrigger("a table for two."))
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	if len(llm.prompts) != 1 || llm.prompts[0] != "I'd like to book a table for two." {
+		t.Fatalf("expected a single prompt with the joined transcripts, got %v", llm.prompts)
+	}
+	model.mu.Lock()
+	defer model.mu.Unlock()
+	if strings.Join(model.transcripts, "|") != "I'd like to book|I'd like to book a table for two." {
+		t.Fatalf("unexpected transcripts passed to the model %v", model.transcripts)
+	}
+}
+
+func TestTurnTakingRespondsAfterMaxWait(t *testing.T) {
+	llm := &recordingPromptLLMStub{}
+	var completed atomic.Bool
+	o := NewOrchestrator(WithLLM(llm), WithTurnTakingModel(&punctuationTurnTaking{}, WithTurnTakingMaxWait(20*time.Millisecond)))
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+
+	o.HandleTrigger(triggers.NewTranscriptionTrigger("so um"))
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	if len(llm.prompts) != 1 || llm.prompts[0] != "so um" {
+		t.Fatalf("expected the held transcript to be responded to, got %v", llm.prompts)
+	}
+}
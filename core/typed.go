@@ -0,0 +1,61 @@
+package orchestration
+
+import (
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+type TypedMessageOptions struct {
+	textOnly  bool
+	speakerID string
+}
+
+type TypedMessageOption func(*TypedMessageOptions)
+
+// WithTextOnlyResponse responds to the message in text only, e.g. when the
+// user switched to typing because they cannot listen.
+func WithTextOnlyResponse() TypedMessageOption {
+	return func(o *TypedMessageOptions) {
+		o.textOnly = true
+	}
+}
+
+// WithTypedBy attributes the message to the user identified by speakerID in
+// conversations with several users.
+func WithTypedBy(speakerID string) TypedMessageOption {
+	return func(o *TypedMessageOptions) {
+		o.speakerID = speakerID
+	}
+}
+
+// SendTypedMessage sends a message the user typed, in conversations where
+// they also speak. It is handled like a spoken prompt, but marked as typed in
+// the [events.UserMessageTyped] event, the [events.TurnStarted] event and the
+// turn, see [llms.TurnV1.InputModality].
+func (o *Orchestrator) SendTypedMessage(message string, opts ...TypedMessageOption) {
+	options := TypedMessageOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	trigger := triggers.NewTypedUserPromptTrigger(message, triggers.WithSpeaker(options.speakerID))
+	trigger.IsTextOnly = options.textOnly
+	o.emitEvent(events.WithSpeaker(events.NewUserMessageTyped(message), options.speakerID))
+	o.ingestTrigger(trigger)
+}
+
+// inputModalityOf returns how the user gave trigger.
+func inputModalityOf(trigger llms.TriggerV0) string {
+	switch t := trigger.(type) {
+	case triggers.UserPromptTrigger:
+		if t.IsTyped {
+			return llms.InputModalityText
+		} else if t.IsTranscribed {
+			return llms.InputModalitySpeech
+		}
+	case triggers.TranscriptionTrigger:
+		return llms.InputModalitySpeech
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package orchestration
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+func TestTypedMessagesAreMarkedAndCanSkipSpeech(t *testing.T) {
+	output := &bridgeAudioOutputStub{}
+	o := NewOrchestrator(
+		WithLLM(promptLLMStub{response: "Hello there."}),
+		WithTextToSpeechClientV1(&bridgeTTSV1Stub{}),
+		WithAudioOutputV1(output),
+	)
+	t.Cleanup(o.Close)
+	var mu sync.Mutex
+	var typed []events.UserMessageTyped
+	var started []events.TurnStarted
+	var completed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch typedEvent := event.(type) {
+		case events.UserMessageTyped:
+			typed = append(typed, typedEvent)
+		case events.TurnStarted:
+			started = append(started, typedEvent)
+		case events.TurnCompleted:
+			completed.Add(1)
+		}
+	}))
+
+	o.SendTypedMessage("hi, in text please", WithTextOnlyResponse(), WithTypedBy("alice"))
+	waitForCondition(t, 2*time.Second, "text-only turn completed", func() bool { return completed.Load() == 1 })
+	if chunks := output.nonEmptyAudioChunks(); chunks != 0 {
+		t.Fatalf("expected no speech for the text-only response, got %d audio chunks", chunks)
+	}
+
+	o.SendTypedMessage("now say it")
+	waitForCondition(t, 2*time.Second, "spoken turn completed", func() bool { return completed.Load() == 2 })
+	if output.nonEmptyAudioChunks() == 0 {
+		t.Fatal("expected the response to the typed message to be spoken")
+	}
+
+	history := o.conversation.History()
+	if len(history) != 2 {
+		t.Fatalf("expected two turns, got %+v", history)
+	}
+	for _, turn := range history {
+		if turn.InputModality != llms.InputModalityText || len(turn.Responses) == 0 || turn.Responses[0].TypedMessage != "Hello there." {
+			t.Fatalf("expected typed turns with text responses, got %+v", turn)
+		}
+	}
+	if history[0].SpeakerID != "alice" {
+		t.Fatalf("expected the typed message to be attributed, got %+v", history[0])
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(typed) != 2 || typed[0].Message != "hi, in text please" || typed[0].SpeakerID != "alice" {
+		t.Fatalf("unexpected typed message events %+v", typed)
+	}
+	if len(started) != 2 || started[0].InputModality != llms.InputModalityText {
+		t.Fatalf("expected turn started events marked as typed, got %+v", started)
+	}
+}
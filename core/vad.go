@@ -0,0 +1,49 @@
+package orchestration
+
+import (
+	"fmt"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// VoiceActivityDetectorV0 detects speech in the user audio locally, e.g. a
+// detector of the audio/vad package.
+type VoiceActivityDetectorV0 interface {
+	// ProcessFrame consumes a frame of user audio and returns the
+	// [events.UserSpeechStarted] and [events.UserSpeechEnded] events it
+	// completes, if any.
+	ProcessFrame(frame events.UserAudioFrame, encoding audio.EncodingInfo) []events.Event
+}
+
+// WithVoiceActivityDetector detects when the user starts and stops speaking
+// with detector instead of the speech-to-text client, whose speech started
+// and ended events are dropped. Barge-in then reacts as soon as the detector
+// hears speech, even when the speech-to-text provider reports it late or not
+// at all. The speech-to-text clients of single speakers, see
+// [WithSpeakerSpeechToText], keep reporting speech of their own streams.
+func WithVoiceActivityDetector(detector VoiceActivityDetectorV0) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if isNilClient(detector) {
+			o.validation.errs = append(o.validation.errs, fmt.Errorf("WithVoiceActivityDetector: %w", ErrNilClient))
+			return
+		}
+		o.voiceActivityDetector = detector
+	}
+}
+
+// withoutSpeechActivity drops the speech started and ended events of the
+// speech-to-text client once a voice activity detector reports them instead.
+func (o *Orchestrator) withoutSpeechActivity(emitEvent eventEmitter) eventEmitter {
+	if o.voiceActivityDetector == nil {
+		return emitEvent
+	}
+
+	return func(event events.Event) {
+		switch event.(type) {
+		case events.UserSpeechStarted, events.UserSpeechEnded:
+			return
+		}
+		emitEvent(event)
+	}
+}
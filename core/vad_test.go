@@ -0,0 +1,76 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/speechtotext"
+)
+
+// voiceActivityDetectorStub reports speech for frames starting with 1 and
+// silence for frames starting with 0.
+type voiceActivityDetectorStub struct {
+	speaking bool
+}
+
+func (d *voiceActivityDetectorStub) ProcessFrame(frame events.UserAudioFrame, _ audio.EncodingInfo) []events.Event {
+	switch speech := frame.Audio[0] == 1; {
+	case speech && !d.speaking:
+		d.speaking = true
+		return []events.Event{events.NewUserSpeechStarted()}
+	case !speech && d.speaking:
+		d.speaking = false
+		return []events.Event{events.NewUserSpeechEnded()}
+	}
+	return nil
+}
+
+func TestVoiceActivityDetectorReplacesSpeechToTextSpeechEvents(t *testing.T) {
+	var sttOptions speechtotext.TranscriptionOptions
+	sttClient := &speechToTextClientStub{transcribe: func(opts speechtotext.TranscriptionOptions) { sttOptions = opts }}
+	o := NewOrchestrator(WithSpeechToTextClient(sttClient), WithVoiceActivityDetector(&voiceActivityDetectorStub{}))
+	defer o.Close()
+
+	var mu sync.Mutex
+	var speech []events.Event
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch event.(type) {
+		case events.UserSpeechStarted, events.UserSpeechEnded:
+			speech = append(speech, event)
+		}
+	}))
+	audioEmitter := o.composeAudioInputEventEmitter(o.emitEvent)
+
+	audioEmitter(events.NewUserAudioFrame([]byte{1}))
+	sttOptions.SpeechStartedCallback()
+	audioEmitter(events.NewUserAudioFrame([]byte{1}))
+	sttOptions.SpeechEndedCallback()
+	audioEmitter(events.NewUserAudioFrame([]byte{0}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(speech) != 2 {
+		t.Fatalf("expected only the speech detected locally to be reported, got %+v", speech)
+	}
+	if _, ok := speech[0].(events.UserSpeechStarted); !ok {
+		t.Fatalf("expected speech to start first, got %+v", speech[0])
+	}
+	if _, ok := speech[1].(events.UserSpeechEnded); !ok {
+		t.Fatalf("expected speech to end second, got %+v", speech[1])
+	}
+}
+
+func TestWithVoiceActivityDetectorRejectsNilDetector(t *testing.T) {
+	_, err := NewOrchestratorE(WithVoiceActivityDetector(nil))
+	if !errors.Is(err, ErrNilClient) {
+		t.Fatalf("expected nil client error, got %v", err)
+	}
+}
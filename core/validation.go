@@ -0,0 +1,85 @@
+package orchestration
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrNilClient is the error of options given a nil client.
+var ErrNilClient = errors.New("nil client")
+
+// NewOrchestratorE is like [NewOrchestrator] but validates the options and
+// fails with a descriptive error up front instead of in the first turn. It
+// rejects nil clients, several clients for the same component (e.g. both
+// [WithAudioOutputV0] and [WithAudioOutputV1]), history restored by both
+// [WithSessionStateV0] and [WithInitialHistory] and text to speech without an
+// audio output to take the encoding from.
+func NewOrchestratorE(opts ...OrchestratorOption) (*Orchestrator, error) {
+	o := NewOrchestrator(opts...)
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("invalid orchestrator options: %w", err)
+	}
+	return o, nil
+}
+
+// optionValidation collects problems found while applying options, they
+// are only reported by [NewOrchestratorE].
+type optionValidation struct {
+	errs []error
+	// configuredBy is the option that configured each component.
+	configuredBy map[Component]string
+	// historyRestoredBy is the option that restored the history, if any.
+	historyRestoredBy string
+}
+
+// configure records option setting the client of component.
+func (v *optionValidation) configure(option string, component Component, client any) {
+	if isNilClient(client) {
+		v.errs = append(v.errs, fmt.Errorf("%s: %w", option, ErrNilClient))
+	}
+	if previous, ok := v.configuredBy[component]; ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %s already configured by %s", option, component, previous))
+	}
+	if v.configuredBy == nil {
+		v.configuredBy = map[Component]string{}
+	}
+	v.configuredBy[component] = option
+}
+
+// restoreHistory records option restoring the history of the conversation,
+// which replaces the history restored by any earlier option.
+func (v *optionValidation) restoreHistory(option string) {
+	if v.historyRestoredBy != "" {
+		v.errs = append(v.errs, fmt.Errorf("%s: history already restored by %s", option, v.historyRestoredBy))
+	}
+	v.historyRestoredBy = option
+}
+
+func (o *Orchestrator) validate() error {
+	errs := append([]error(nil), o.validation.errs...)
+
+	if o.textToSpeech.base != nil && !o.audioOutput.isConfigured() {
+		errs = append(errs, fmt.Errorf("%s: requires an audio output to take the encoding from", ComponentTextToSpeech))
+	}
+	if o.audioOutput.isConfigured() && o.audioOutput.EncodingInfo().IsZero() {
+		errs = append(errs, fmt.Errorf("%s: reports no encoding", ComponentAudioOutput))
+	}
+
+	return errors.Join(errs...)
+}
+
+// isNilClient detects nil and typed-nil clients.
+func isNilClient(client any) bool {
+	if client == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(client)
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
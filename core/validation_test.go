@@ -0,0 +1,102 @@
+package orchestration
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/llms"
+)
+
+type zeroEncodingAudioOutputStub struct {
+	bridgeAudioOutputStub
+}
+
+func (output *zeroEncodingAudioOutputStub) EncodingInfo() audio.EncodingInfo {
+	return audio.EncodingInfo{}
+}
+
+func TestNewOrchestratorEValidatesOptions(t *testing.T) {
+	var nilTTS *bridgeTTSV1Stub
+
+	testCases := []struct {
+		name     string
+		opts     []OrchestratorOption
+		expected string
+	}{
+		{
+			name: "valid",
+			opts: []OrchestratorOption{
+				WithLLM(promptLLMStub{}),
+				WithTextToSpeechClientV1(&bridgeTTSV1Stub{}),
+				WithAudioOutputV1(&bridgeAudioOutputStub{}),
+			},
+		},
+		{
+			name:     "nil client",
+			opts:     []OrchestratorOption{WithStreamingLLM(nil)},
+			expected: "WithStreamingLLM: nil client",
+		},
+		{
+			name: "typed nil client",
+			opts: []OrchestratorOption{
+				WithTextToSpeechClientV1(nilTTS),
+				WithAudioOutputV1(&bridgeAudioOutputStub{}),
+			},
+			expected: "WithTextToSpeechClientV1: nil client",
+		},
+		{
+			name: "audio output configured twice",
+			opts: []OrchestratorOption{
+				WithAudioOutputV0(&snapshotAudioOutputV0{}),
+				WithAudioOutputV1(&bridgeAudioOutputStub{}),
+			},
+			expected: "WithAudioOutputV1: audio_output already configured by WithAudioOutputV0",
+		},
+		{
+			name: "history restored twice",
+			opts: []OrchestratorOption{
+				WithSessionStateV0(SessionStateV0{History: []llms.TurnV1{{ID: "turn-1"}}}),
+				WithInitialHistory(llms.TurnV1{ID: "turn-2"}),
+			},
+			expected: "WithInitialHistory: history already restored by WithSessionStateV0",
+		},
+		{
+			name:     "text to speech without audio output",
+			opts:     []OrchestratorOption{WithTextToSpeechClientV1(&bridgeTTSV1Stub{})},
+			expected: "text_to_speech: requires an audio output",
+		},
+		{
+			name:     "audio output without encoding",
+			opts:     []OrchestratorOption{WithAudioOutputV1(&zeroEncodingAudioOutputStub{})},
+			expected: "audio_output: reports no encoding",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			o, err := NewOrchestratorE(testCase.opts...)
+			if testCase.expected == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				o.Close()
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Fatalf("expected error containing %q, got %v", testCase.expected, err)
+			}
+			if o != nil {
+				t.Fatalf("expected no orchestrator on error")
+			}
+		})
+	}
+}
+
+func TestNewOrchestratorEWrapsNilClientError(t *testing.T) {
+	if _, err := NewOrchestratorE(WithSpeechToTextClient(nil)); !errors.Is(err, ErrNilClient) {
+		t.Fatalf("expected ErrNilClient, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package orchestration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type VoiceShapingOptions struct {
+	maxSentences    int
+	maxLength       int
+	guidance        string
+	defaultGuidance bool
+}
+
+type VoiceShapingOption func(*VoiceShapingOptions)
+
+// WithMaxSpokenSentences sets how many sentences of a response are spoken,
+// 3 by default. Zero speaks all of them.
+func WithMaxSpokenSentences(n int) VoiceShapingOption {
+	return func(o *VoiceShapingOptions) {
+		o.maxSentences = n
+	}
+}
+
+// WithMaxSpokenLength sets how many characters of a response are spoken, no
+// limit by default. The sentence reaching the limit is still finished.
+func WithMaxSpokenLength(n int) VoiceShapingOption {
+	return func(o *VoiceShapingOptions) {
+		o.maxLength = n
+	}
+}
+
+// WithVoicePromptGuidance replaces the guidance appended to the system prompt
+// of turns, empty guidance leaves the system prompt as is.
+func WithVoicePromptGuidance(guidance string) VoiceShapingOption {
+	return func(o *VoiceShapingOptions) {
+		o.guidance = guidance
+		o.defaultGuidance = false
+	}
+}
+
+// WithVoiceShaping keeps responses fit to be spoken. Lists are read without
+// their bullets, code blocks and markdown are left out, and generation stops
+// once the response reaches the sentence or length limit.
+//
+// The history keeps the response as generated up to that point. Guidance
+// asking for short, unformatted answers is appended to the system prompt of
+// turns, the one set with [WithSystemPromptProvider] or else the one the LLM
+// client was configured with.
+func WithVoiceShaping(opts ...VoiceShapingOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		options := VoiceShapingOptions{maxSentences: 3, defaultGuidance: true}
+		for _, opt := range opts {
+			opt(&options)
+		}
+		if options.defaultGuidance {
+			options.guidance = "Your responses are spoken aloud, answer briefly without lists, code blocks or other formatting."
+			if options.maxSentences > 0 {
+				options.guidance = fmt.Sprintf("Your responses are spoken aloud, answer in at most %d short sentences without lists, code blocks or other formatting.", options.maxSentences)
+			}
+		}
+		o.voiceShaping = &options
+	}
+}
+
+var (
+	voiceMarkupPrefixRunes = " \t-*+•#.)`0123456789"
+	voiceListMarker        = regexp.MustCompile(`^\s*(?:[-*+•]|#+|\d+[.)])\s+`)
+)
+
+// voiceShaper shapes the response of a turn as it streams, passing the
+// text to speak to send.
+type voiceShaper struct {
+	*VoiceShapingOptions
+	send func(string)
+
+	// line holds the start of a line until it is known whether it starts
+	// with markup.
+	line        string
+	lineDecided bool
+	skipLine    bool
+	inCodeBlock bool
+
+	last      rune
+	sentences int
+	length    int
+	done      bool
+}
+
+func (o *VoiceShapingOptions) newShaper(send func(string)) *voiceShaper {
+	return &voiceShaper{VoiceShapingOptions: o, send: send}
+}
+
+// add speaks the part of chunk fit to be spoken.
+func (s *voiceShaper) add(chunk string) {
+	var shaped strings.Builder
+	for _, r := range chunk {
+		if s.done {
+			break
+		}
+
+		if !s.lineDecided {
+			s.line += string(r)
+			if r == '\n' || !strings.ContainsRune(voiceMarkupPrefixRunes, r) {
+				s.decideLine(&shaped)
+			}
+			continue
+		}
+
+		if r == '\n' {
+			if !s.skipLine {
+				s.write(&shaped, r)
+			}
+			s.lineDecided, s.skipLine = false, false
+			continue
+		}
+		if !s.skipLine {
+			s.write(&shaped, r)
+		}
+	}
+	s.sendShaped(shaped.String())
+}
+
+// flush speaks the rest of the response.
+func (s *voiceShaper) flush() {
+	var shaped strings.Builder
+	if !s.lineDecided && s.line != "" && !s.done {
+		s.decideLine(&shaped)
+	}
+	s.sendShaped(shaped.String())
+}
+
+func (s *voiceShaper) sendShaped(text string) {
+	if text != "" {
+		s.send(text)
+	}
+}
+
+// stopped reports whether the response reached its limits.
+func (s *voiceShaper) stopped() bool {
+	return s.done
+}
+
+// decideLine strips the markup the buffered line starts with.
+func (s *voiceShaper) decideLine(shaped *strings.Builder) {
+	line := s.line
+	s.line = ""
+	s.lineDecided = true
+
+	if strings.HasPrefix(strings.TrimLeft(line, " \t"), "```") {
+		s.inCodeBlock = !s.inCodeBlock
+		s.skipLine = true
+	} else if s.inCodeBlock {
+		s.skipLine = true
+	}
+	if s.skipLine {
+		if strings.HasSuffix(line, "\n") {
+			s.lineDecided, s.skipLine = false, false
+		}
+		return
+	}
+
+	line = voiceListMarker.ReplaceAllString(line, "")
+	for _, r := range line {
+		if s.done {
+			return
+		}
+		s.write(shaped, r)
+	}
+	if strings.HasSuffix(line, "\n") {
+		s.lineDecided = false
+	}
+}
+
+// write speaks r unless it is markup, stopping at the end of the sentence
+// reaching the limits.
+func (s *voiceShaper) write(shaped *strings.Builder, r rune) {
+	if r == '*' || r == '`' {
+		return
+	}
+
+	if strings.ContainsRune(defaultSpeechPlayerSegmentationBoundaries, s.last) && (r == ' ' || r == '\n' || r == '\t') {
+		s.sentences++
+		if (s.maxSentences > 0 && s.sentences >= s.maxSentences) || (s.maxLength > 0 && s.length >= s.maxLength) {
+			s.done = true
+			return
+		}
+	}
+
+	shaped.WriteRune(r)
+	s.last = r
+	s.length++
+}
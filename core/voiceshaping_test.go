@@ -0,0 +1,113 @@
+package orchestration
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/llms"
+	"github.com/koscakluka/ema-core/core/triggers"
+)
+
+func TestVoiceShaperStripsMarkupAndStops(t *testing.T) {
+	var spoken strings.Builder
+	shaper := (&VoiceShapingOptions{maxSentences: 3}).newShaper(func(text string) { spoken.WriteString(text) })
+
+	for _, chunk := range []string{"You can **either**:\n", "- restart it.\n1", ". reinstall `it`.\n```", "sh\nrm -rf /\n```\nOr call", " us. Bye."} {
+		shaper.add(chunk)
+	}
+	shaper.flush()
+
+	if want := "You can either:\nrestart it.\nreinstall it.\nOr call us."; spoken.String() != want {
+		t.Fatalf("expected %q, got %q", want, spoken.String())
+	}
+	if !shaper.stopped() {
+		t.Fatal("expected the shaper to stop after the sentence limit")
+	}
+}
+
+// instructionsLLMStub resolves the system prompt like the provider clients,
+// from the one it is configured with and the prompt options, and records it.
+type instructionsLLMStub struct {
+	systemPrompt string
+
+	mu           sync.Mutex
+	instructions []string
+}
+
+func (stub *instructionsLLMStub) PromptWithStream(_ context.Context, _ *string, opts ...llms.StreamingPromptOption) llms.Stream {
+	options := llms.StreamingPromptOptions{GeneralPromptOptions: llms.GeneralPromptOptions{BaseOptions: llms.BaseOptions{Instructions: stub.systemPrompt}}}
+	for _, opt := range opts {
+		opt.ApplyToStreaming(&options)
+	}
+	stub.mu.Lock()
+	stub.instructions = append(stub.instructions, options.BaseOptions.Instructions)
+	stub.mu.Unlock()
+	return scriptedStreamStub{chunks: []string{"Sure."}}
+}
+
+func (stub *instructionsLLMStub) recorded() []string {
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	return append([]string(nil), stub.instructions...)
+}
+
+func TestVoiceShapingGuidanceKeepsTheSystemPrompt(t *testing.T) {
+	llm := &instructionsLLMStub{systemPrompt: "You are a receptionist."}
+	var prompts atomic.Int32
+	o := NewOrchestrator(
+		WithStreamingLLM(llm),
+		WithVoiceShaping(WithVoicePromptGuidance("Answer briefly.")),
+		WithSystemPromptProvider(func(_ ConversationV1, _ llms.TriggerV0) string {
+			if prompts.Add(1) == 1 {
+				return ""
+			}
+			return "You are a concierge."
+		}),
+	)
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx)
+
+	o.HandleTrigger(triggers.NewUserPromptTrigger("hello"))
+	waitForCondition(t, 2*time.Second, "first turn", func() bool { return len(llm.recorded()) == 1 })
+	o.HandleTrigger(triggers.NewUserPromptTrigger("hello again"))
+	waitForCondition(t, 2*time.Second, "second turn", func() bool { return len(llm.recorded()) == 2 })
+
+	instructions := llm.recorded()
+	if instructions[0] != "You are a receptionist.\n\nAnswer briefly." {
+		t.Fatalf("expected the guidance appended to the system prompt of the client, got %q", instructions[0])
+	}
+	if instructions[1] != "You are a concierge.\n\nAnswer briefly." {
+		t.Fatalf("expected the guidance appended to the system prompt of the turn, got %q", instructions[1])
+	}
+}
+
+func TestVoiceShapingStopsGeneration(t *testing.T) {
+	var completed atomic.Bool
+	o := NewOrchestrator(
+		WithStreamingLLM(repeatingStreamLLMStub{chunk: "Sure thing. ", interval: time.Millisecond}),
+		WithVoiceShaping(WithMaxSpokenSentences(2)),
+	)
+	t.Cleanup(o.Close)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if _, ok := event.(events.TurnCompleted); ok {
+			completed.Store(true)
+		}
+	}))
+
+	o.HandleTrigger(triggers.NewUserPromptTrigger("can you help?"))
+	waitForCondition(t, 2*time.Second, "turn completed", completed.Load)
+
+	history := o.conversation.History()
+	if len(history) != 1 || len(history[0].Responses) != 1 || history[0].Responses[0].Message != "Sure thing. Sure thing. " {
+		t.Fatalf("expected the response to stop after two sentences, got %+v", history)
+	}
+}
@@ -0,0 +1,118 @@
+package orchestration
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// WakeWordDetectorV0 spots the wake word in the user audio, e.g. a detector
+// of the wakeword package.
+type WakeWordDetectorV0 interface {
+	// ProcessFrame consumes a frame of user audio and returns an
+	// [events.UserWakeWordDetected] event for each wake word heard in it.
+	ProcessFrame(frame events.UserAudioFrame, encoding audio.EncodingInfo) []events.Event
+}
+
+type WakeWordOptions struct {
+	timeout time.Duration
+}
+
+type WakeWordOption func(*WakeWordOptions)
+
+// WithWakeWordTimeout sets how long the assistant listens after the wake
+// word for the user to start speaking, 5s by default.
+func WithWakeWordTimeout(timeout time.Duration) WakeWordOption {
+	return func(o *WakeWordOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithWakeWord only sends the audio input to speech-to-text once detector
+// hears the wake word, reported with an [events.UserWakeWordDetected] event.
+// The assistant then listens to the utterance that follows, until its final
+// transcript, or stops listening if the user does not start speaking in
+// time, see [WithWakeWordTimeout].
+//
+// Audio frames are still emitted while the assistant is not listening, and
+// the speech-to-text clients of single speakers, see
+// [WithSpeakerSpeechToText], are not gated.
+func WithWakeWord(detector WakeWordDetectorV0, opts ...WakeWordOption) OrchestratorOption {
+	return func(o *Orchestrator) {
+		if isNilClient(detector) {
+			o.validation.errs = append(o.validation.errs, fmt.Errorf("WithWakeWord: %w", ErrNilClient))
+			return
+		}
+		gate := &wakeWordGate{WakeWordOptions: WakeWordOptions{timeout: 5 * time.Second}, detector: detector}
+		for _, opt := range opts {
+			opt(&gate.WakeWordOptions)
+		}
+		o.wakeWord = gate
+	}
+}
+
+// wakeWordGate holds the audio input back from speech-to-text until the wake
+// word is heard.
+type wakeWordGate struct {
+	WakeWordOptions
+	detector WakeWordDetectorV0
+
+	mu        sync.Mutex
+	listening bool
+	openedAt  time.Time
+	// heard is set once the user started speaking after the wake word.
+	heard bool
+}
+
+// admits reports whether frame is passed on to speech-to-text. While not
+// listening the frame is passed to the detector instead, and detected wake
+// words are emitted with emitEvent.
+func (g *wakeWordGate) admits(frame events.UserAudioFrame, encoding audio.EncodingInfo, clock clock.Clock, emitEvent eventEmitter) bool {
+	g.mu.Lock()
+	if g.listening && !g.heard && g.timeout > 0 && clock.Now().Sub(g.openedAt) >= g.timeout {
+		g.listening = false
+	}
+	if g.listening {
+		g.mu.Unlock()
+		return true
+	}
+	g.mu.Unlock()
+
+	detected := g.detector.ProcessFrame(frame, encoding)
+	if len(detected) == 0 {
+		return false
+	}
+
+	g.mu.Lock()
+	g.listening = true
+	g.openedAt = clock.Now()
+	g.heard = false
+	g.mu.Unlock()
+	for _, event := range detected {
+		emitEvent(event)
+	}
+	// The frame with the wake word is not transcribed.
+	return false
+}
+
+// speechStarted keeps the assistant listening until the utterance is
+// transcribed.
+func (g *wakeWordGate) speechStarted() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.listening {
+		g.heard = true
+	}
+}
+
+// transcribed stops listening once the utterance after the wake word was
+// transcribed.
+func (g *wakeWordGate) transcribed() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.listening = false
+}
@@ -0,0 +1,120 @@
+// Package wakeword spots a wake phrase in user audio, so the assistant only
+// listens once it is addressed.
+//
+// A [Detector] runs a wake word [Engine], e.g. a binding of Porcupine or
+// openWakeWord, on user audio frames and reports the wake word with
+// [events.UserWakeWordDetected] events. Passed to the orchestrator, it gates
+// the audio sent to speech-to-text until the wake word is heard:
+//
+//	o := orchestration.NewOrchestrator(
+//		orchestration.WithSpeechToTextClient(stt),
+//		orchestration.WithWakeWord(wakeword.NewDetector(porcupine)),
+//	)
+package wakeword
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+// Engine spots wake words in frames of 16-bit mono audio.
+type Engine interface {
+	// SampleRate is the sample rate of the audio the engine expects, e.g.
+	// 16000 for Porcupine and openWakeWord.
+	SampleRate() int
+	// FrameLength is how many samples each processed frame has, e.g. 512 for
+	// Porcupine or 1280 for openWakeWord.
+	FrameLength() int
+	// Process returns the wake word heard in frame, or an empty string if
+	// none was heard.
+	Process(frame []int16) (keyword string, err error)
+}
+
+type DetectorOptions struct {
+	logger logging.Logger
+}
+
+type DetectorOption func(*DetectorOptions)
+
+// WithLogger sets the logger of the detector, defaults to
+// [logging.Default].
+func WithLogger(logger logging.Logger) DetectorOption {
+	return func(o *DetectorOptions) {
+		o.logger = logger
+	}
+}
+
+// Detector runs an engine on user audio frames of any size.
+type Detector struct {
+	DetectorOptions
+	engine Engine
+
+	mu      sync.Mutex
+	pending []int16
+	// failing is set once the audio could not be processed, so a stream of
+	// unsupported audio is only logged once.
+	failing bool
+}
+
+// NewDetector creates a detector spotting wake words with engine.
+func NewDetector(engine Engine, opts ...DetectorOption) *Detector {
+	options := DetectorOptions{logger: logging.Default()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Detector{DetectorOptions: options, engine: engine}
+}
+
+// ProcessFrame consumes frame of audio in encoding and returns a
+// [events.UserWakeWordDetected] event for each wake word the engine heard.
+// The audio must be linear16 at the sample rate of the engine, other audio
+// is ignored.
+func (d *Detector) ProcessFrame(frame events.UserAudioFrame, encoding audio.EncodingInfo) []events.Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if encoding.Format != audio.EncodingLinear16 || encoding.SampleRate != d.engine.SampleRate() {
+		d.fail(fmt.Errorf("unsupported encoding %s at %dHz, the engine expects linear16 at %dHz",
+			encoding.Format.Name(), encoding.SampleRate, d.engine.SampleRate()))
+		return nil
+	}
+	for i := 0; i+1 < len(frame.Audio); i += 2 {
+		d.pending = append(d.pending, int16(binary.LittleEndian.Uint16(frame.Audio[i:])))
+	}
+
+	var detected []events.Event
+	length := d.engine.FrameLength()
+	for length > 0 && len(d.pending) >= length {
+		keyword, err := d.engine.Process(d.pending[:length])
+		d.pending = d.pending[length:]
+		if err != nil {
+			d.fail(fmt.Errorf("failed to process audio: %w", err))
+			continue
+		}
+		d.failing = false
+		if keyword != "" {
+			detected = append(detected, events.NewUserWakeWordDetected(keyword))
+		}
+	}
+	d.pending = append(d.pending[:0:0], d.pending...)
+	return detected
+}
+
+// Reset drops the audio not processed yet.
+func (d *Detector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending = nil
+}
+
+func (d *Detector) fail(err error) {
+	if !d.failing {
+		d.logger.Warn("failed to detect wake word", "error", err)
+	}
+	d.failing = true
+}
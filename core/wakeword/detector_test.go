@@ -0,0 +1,71 @@
+package wakeword
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	events "github.com/koscakluka/ema-core/core/events"
+	"github.com/koscakluka/ema-core/core/logging"
+)
+
+// engineStub hears "hey ema" in frames with a sample of 1000.
+type engineStub struct {
+	frames []int
+	err    error
+}
+
+func (e *engineStub) SampleRate() int  { return 16000 }
+func (e *engineStub) FrameLength() int { return 4 }
+
+func (e *engineStub) Process(frame []int16) (string, error) {
+	if e.err != nil {
+		return "", e.err
+	}
+	e.frames = append(e.frames, len(frame))
+	for _, sample := range frame {
+		if sample == 1000 {
+			return "hey ema", nil
+		}
+	}
+	return "", nil
+}
+
+func pcm(samples ...int16) []byte {
+	out := make([]byte, 0, 2*len(samples))
+	for _, sample := range samples {
+		out = binary.LittleEndian.AppendUint16(out, uint16(sample))
+	}
+	return out
+}
+
+func TestDetectorReportsWakeWordsInEngineFrames(t *testing.T) {
+	engine := &engineStub{}
+	d := NewDetector(engine, WithLogger(logging.Discard()))
+	encoding := audio.GetDefaultEncodingInfo()
+
+	if detected := d.ProcessFrame(events.NewUserAudioFrame(pcm(0, 0, 0)), encoding); len(detected) != 0 {
+		t.Fatalf("expected no wake word, got %+v", detected)
+	}
+	detected := d.ProcessFrame(events.NewUserAudioFrame(pcm(0, 0, 1000, 0, 0, 0)), encoding)
+	if len(detected) != 1 || detected[0].(events.UserWakeWordDetected).Keyword != "hey ema" {
+		t.Fatalf("expected the wake word, got %+v", detected)
+	}
+	if len(engine.frames) != 2 || engine.frames[0] != 4 || engine.frames[1] != 4 {
+		t.Fatalf("expected two frames of four samples, got %v", engine.frames)
+	}
+}
+
+func TestDetectorIgnoresAudioItCannotProcess(t *testing.T) {
+	engine := &engineStub{}
+	d := NewDetector(engine, WithLogger(logging.Discard()))
+	if detected := d.ProcessFrame(events.NewUserAudioFrame(pcm(1000, 1000, 1000, 1000)), audio.EncodingInfo{SampleRate: 8000, Format: audio.EncodingLinear16}); len(detected) != 0 {
+		t.Fatalf("expected audio at another sample rate to be ignored, got %+v", detected)
+	}
+
+	engine.err = errors.New("engine failed")
+	if detected := d.ProcessFrame(events.NewUserAudioFrame(pcm(1000, 1000, 1000, 1000)), audio.GetDefaultEncodingInfo()); len(detected) != 0 {
+		t.Fatalf("expected no wake word from a failing engine, got %+v", detected)
+	}
+}
@@ -0,0 +1,78 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koscakluka/ema-core/core/audio"
+	"github.com/koscakluka/ema-core/core/clock"
+	events "github.com/koscakluka/ema-core/core/events"
+)
+
+// wakeWordDetectorStub hears the wake word in frames starting with 9.
+type wakeWordDetectorStub struct{}
+
+func (wakeWordDetectorStub) ProcessFrame(frame events.UserAudioFrame, _ audio.EncodingInfo) []events.Event {
+	if frame.Audio[0] == 9 {
+		return []events.Event{events.NewUserWakeWordDetected("hey ema")}
+	}
+	return nil
+}
+
+func TestWakeWordGatesAudioToSpeechToText(t *testing.T) {
+	sttClient := &recordingSpeechToTextClient{}
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	o := NewOrchestrator(WithSpeechToTextClient(sttClient), WithClock(fakeClock), WithWakeWord(wakeWordDetectorStub{}, WithWakeWordTimeout(5*time.Second)))
+	defer o.Close()
+
+	var mu sync.Mutex
+	var wakeWords []string
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o.Orchestrate(ctx, WithEventCallback(func(event events.Event) {
+		if event, ok := event.(events.UserWakeWordDetected); ok {
+			mu.Lock()
+			wakeWords = append(wakeWords, event.Keyword)
+			mu.Unlock()
+		}
+	}))
+	audioEmitter := o.composeAudioInputEventEmitter(o.emitEvent)
+
+	audioEmitter(events.NewUserAudioFrame([]byte{1}))
+	audioEmitter(events.NewUserAudioFrame([]byte{9}))
+	audioEmitter(events.NewUserAudioFrame([]byte{2}))
+	o.wakeWord.speechStarted()
+	fakeClock.Advance(10 * time.Second)
+	audioEmitter(events.NewUserAudioFrame([]byte{3}))
+	o.wakeWord.transcribed()
+	audioEmitter(events.NewUserAudioFrame([]byte{4}))
+
+	// Without speech after the wake word the assistant stops listening.
+	audioEmitter(events.NewUserAudioFrame([]byte{9}))
+	audioEmitter(events.NewUserAudioFrame([]byte{5}))
+	fakeClock.Advance(5 * time.Second)
+	audioEmitter(events.NewUserAudioFrame([]byte{6}))
+
+	var sent []byte
+	for _, frame := range sttClient.snapshot() {
+		sent = append(sent, frame[0])
+	}
+	if string(sent) != string([]byte{2, 3, 5}) {
+		t.Fatalf("expected only the audio after the wake word to be transcribed, got %v", sent)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(wakeWords) != 2 || wakeWords[0] != "hey ema" {
+		t.Fatalf("expected two wake word events, got %v", wakeWords)
+	}
+}
+
+func TestWithWakeWordRejectsNilDetector(t *testing.T) {
+	_, err := NewOrchestratorE(WithWakeWord(nil))
+	if !errors.Is(err, ErrNilClient) {
+		t.Fatalf("expected nil client error, got %v", err)
+	}
+}